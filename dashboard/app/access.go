@@ -113,9 +113,33 @@ func checkTextAccess(c context.Context, r *http.Request, tag string, id int64) (
 		// But since machine info is usually the same for all bugs and is not secret,
 		// it's fine to check based on the namespace.
 		return nil, nil, nil
+	case textBugNote:
+		return checkBugNoteAccess(c, r, id)
+	case textAttachment:
+		return checkCrashTextAccess(c, r, "Attachments.Data", id)
 	}
 }
 
+func checkBugNoteAccess(c context.Context, r *http.Request, id int64) (*Bug, *Crash, error) {
+	var bugs []*Bug
+	_, err := db.NewQuery("Bug").
+		Filter("Notes.Text=", id).
+		GetAll(c, &bugs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query bugs: %w", err)
+	}
+	if len(bugs) != 1 {
+		err := fmt.Errorf("checkBugNoteAccess: found %v bugs for note %v", len(bugs), id)
+		if len(bugs) == 0 {
+			err = fmt.Errorf("%w: %w", ErrClientNotFound, err)
+		}
+		return nil, nil, err
+	}
+	bug := bugs[0]
+	bugLevel := bug.sanitizeAccess(c, accessLevel(c, r))
+	return bug, nil, checkAccessLevel(c, r, bugLevel)
+}
+
 func checkCrashTextAccess(c context.Context, r *http.Request, field string, id int64) (*Bug, *Crash, error) {
 	var crashes []*Crash
 	keys, err := db.NewQuery("Crash").
@@ -183,6 +207,9 @@ func (bug *Bug) sanitizeAccess(c context.Context, currentLevel AccessLevel) Acce
 					}
 				}
 			}
+			if bug.embargoed(c) {
+				bugLevel = max(bugLevel, AccessUser)
+			}
 			return bugLevel
 		}
 	}
@@ -287,7 +287,7 @@ func TestAccess(t *testing.T) {
 		client := c.makeClient(clientName, clientKey, true)
 		build := testBuild(1)
 		build.KernelConfig = []byte(namespaceAccessPrefix + "build")
-		client.UploadBuild(build)
+		client.UploadBuild(context.Background(), build)
 		noteBuildAccessLevel(ns, build.ID)
 
 		for reportingIdx := 0; reportingIdx < 2; reportingIdx++ {
@@ -295,7 +295,7 @@ func TestAccess(t *testing.T) {
 			accessPrefix := accessLevelPrefix(accessLevel)
 
 			crashInvalid := testCrashWithRepro(build, reportingIdx*10+0)
-			client.ReportCrash(crashInvalid)
+			client.ReportCrash(context.Background(), crashInvalid)
 			repInvalid := client.pollBug()
 			if reportingIdx != 0 {
 				client.updateBug(repInvalid.ID, dashapi.BugStatusUpstream, "")
@@ -308,13 +308,13 @@ func TestAccess(t *testing.T) {
 			noteBugAccessLevel(repInvalid.ID, finalLevel, nsLevel)
 
 			crashFixed := testCrashWithRepro(build, reportingIdx*10+0)
-			client.ReportCrash(crashFixed)
+			client.ReportCrash(context.Background(), crashFixed)
 			repFixed := client.pollBug()
 			if reportingIdx != 0 {
 				client.updateBug(repFixed.ID, dashapi.BugStatusUpstream, "")
 				repFixed = client.pollBug()
 			}
-			reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+			reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 				ID:         repFixed.ID,
 				Status:     dashapi.BugStatusOpen,
 				FixCommits: []string{ns + "-patch0"},
@@ -325,7 +325,7 @@ func TestAccess(t *testing.T) {
 			buildFixing := testBuild(reportingIdx*10 + 2)
 			buildFixing.Manager = build.Manager
 			buildFixing.Commits = []string{ns + "-patch0"}
-			client.UploadBuild(buildFixing)
+			client.UploadBuild(context.Background(), buildFixing)
 			noteBuildAccessLevel(ns, buildFixing.ID)
 			// Fixed bugs are also visible up to the last reporting.
 			noteBugAccessLevel(repFixed.ID, finalLevel, nsLevel)
@@ -337,7 +337,7 @@ func TestAccess(t *testing.T) {
 			crashOpen.ReproSyz = []byte(accessPrefix + "repro syz")
 			crashOpen.ReproLog = []byte(accessPrefix + "repro log")
 			crashOpen.MachineInfo = []byte(ns + "machine info")
-			client.ReportCrash(crashOpen)
+			client.ReportCrash(context.Background(), crashOpen)
 			repOpen := client.pollBug()
 			if reportingIdx != 0 {
 				client.updateBug(repOpen.ID, dashapi.BugStatusUpstream, "")
@@ -346,13 +346,13 @@ func TestAccess(t *testing.T) {
 			noteBugAccessLevel(repOpen.ID, accessLevel, nsLevel)
 
 			crashPatched := testCrashWithRepro(build, reportingIdx*10+1)
-			client.ReportCrash(crashPatched)
+			client.ReportCrash(context.Background(), crashPatched)
 			repPatched := client.pollBug()
 			if reportingIdx != 0 {
 				client.updateBug(repPatched.ID, dashapi.BugStatusUpstream, "")
 				repPatched = client.pollBug()
 			}
-			reply, _ = client.ReportingUpdate(&dashapi.BugUpdate{
+			reply, _ = client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 				ID:         repPatched.ID,
 				Status:     dashapi.BugStatusOpen,
 				FixCommits: []string{ns + "-patch0"},
@@ -364,7 +364,7 @@ func TestAccess(t *testing.T) {
 			noteBugAccessLevel(repPatched.ID, finalLevel, nsLevel)
 
 			crashDup := testCrashWithRepro(build, reportingIdx*10+2)
-			client.ReportCrash(crashDup)
+			client.ReportCrash(context.Background(), crashDup)
 			repDup := client.pollBug()
 			if reportingIdx != 0 {
 				client.updateBug(repDup.ID, dashapi.BugStatusUpstream, "")
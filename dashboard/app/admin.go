@@ -67,6 +67,9 @@ func dropNamespace(c context.Context, w http.ResponseWriter, r *http.Request) er
 		{textCrashLog, ""},
 		{textCrashReport, ""},
 		{"Build", ""},
+		{textCoverage, ""},
+		{"ManagerCoverage", ""},
+		{"CorpusProg", ""},
 		{"Manager", "ManagerStats"},
 		{"Bug", "Crash"},
 	}
@@ -7,7 +7,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,11 +35,21 @@ import (
 	"google.golang.org/appengine/v2"
 	db "google.golang.org/appengine/v2/datastore"
 	"google.golang.org/appengine/v2/log"
+	"google.golang.org/appengine/v2/memcache"
 	"google.golang.org/appengine/v2/user"
 )
 
 func initAPIHandlers() {
-	http.Handle("/api", handleJSON(handleAPI))
+	// Registered here because the handler lists the other handlers.
+	apiHandlers["capabilities"] = apiCapabilities
+	api := handleJSON(handleAPI)
+	http.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		// Let clients know they can send zstd-compressed and small uncompressed payloads.
+		w.Header().Set(dashapi.CompressionHeader, "gzip, zstd, none")
+		w.Header().Set(dashapi.CredentialsHeader, dashapi.HeaderCredentials)
+		api.ServeHTTP(w, r)
+	})
+	http.Handle("/api/blob", handleContext(handleBlob))
 }
 
 var apiHandlers = map[string]APIHandler{
@@ -49,28 +61,72 @@ var apiHandlers = map[string]APIHandler{
 	"reporting_poll_notifs": apiReportingPollNotifications,
 	"reporting_poll_closed": apiReportingPollClosed,
 	"reporting_update":      apiReportingUpdate,
+	"update_bugs":           apiUpdateBugs,
 	"new_test_job":          apiNewTestJob,
 	"needed_assets":         apiNeededAssetsList,
 	"load_full_bug":         apiLoadFullBug,
 	"save_discussion":       apiSaveDiscussion,
+	"incoming_email":        apiIncomingEmail,
 	"save_coverage":         apiSaveCoverage,
+	"set_ext_id":            apiSetExtID,
+	"lookup_ext_id":         apiLookupExtID,
 }
 
 var apiNamespaceHandlers = map[string]APINamespaceHandler{
 	"upload_build":        apiUploadBuild,
 	"builder_poll":        apiBuilderPoll,
+	"build_commits":       apiBuildCommits,
 	"report_build_error":  apiReportBuildError,
 	"report_crash":        apiReportCrash,
+	"report_crashes":      apiReportCrashes,
+	"update_crash":        apiUpdateCrash,
+	"upload_chunk":        apiUploadChunk,
+	"count_crash":         apiCountCrash,
 	"report_failed_repro": apiReportFailedRepro,
 	"need_repro":          apiNeedRepro,
 	"manager_stats":       apiManagerStats,
+	"manager_syscalls":    apiManagerSyscalls,
+	"repro_progress":      apiReproProgress,
+	"upload_stats_series": apiUploadStatsSeries,
+	"stats_series":        apiStatsSeries,
+	"manager_config":      apiManagerConfig,
+	"repos_poll":          apiReposPoll,
 	"commit_poll":         apiCommitPoll,
 	"upload_commits":      apiUploadCommits,
+	"report_fix_commits":  apiReportFixCommits,
 	"bug_list":            apiBugList,
 	"load_bug":            apiLoadBug,
+	"get_repro":           apiGetRepro,
+	"add_bug_note":        apiAddBugNote,
+	"queue_bisect":        apiQueueBisect,
+	"bug_status":          apiBugStatus,
+	"open_bugs":           apiOpenBugs,
+	"manager_notifs":      apiManagerNotifs,
+	"manager_commands":    apiManagerCommands,
+	"ack_manager_command": apiAckManagerCommand,
+	"retest_result":       apiRetestResult,
 	"update_report":       apiUpdateReport,
 	"add_build_assets":    apiAddBuildAssets,
 	"log_to_repro":        apiLogToReproduce,
+	"repro_task_poll":     apiReproTaskPoll,
+	"repro_task_done":     apiReproTaskDone,
+	"claim_repro":         apiClaimRepro,
+	"release_repro_claim": apiReleaseReproClaim,
+	"report_tool_bug":     apiReportToolBug,
+	"upload_coverage":     apiUploadCoverage,
+	"has_blobs":           apiHasBlobs,
+	"upload_corpus":       apiUploadCorpus,
+	"download_corpus":     apiDownloadCorpus,
+	"asset_upload_urls":   apiAssetUploadURLs,
+}
+
+// apiReadOnlyMethods can be called by Config.ReadOnlyClients.
+var apiReadOnlyMethods = map[string]bool{
+	"capabilities": true,
+	"bug_list":     true,
+	"load_bug":     true,
+	"get_repro":    true,
+	"repos_poll":   true,
 }
 
 type JSONHandler func(c context.Context, r *http.Request) (interface{}, error)
@@ -109,16 +165,48 @@ func handleJSON(fn JSONHandler) http.Handler {
 			http.Error(w, err.Error(), status)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
+		contentType, encode := "application/json", func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(reply)
+		}
+		if r.Header.Get("Accept") == dashapi.ProtoContentType {
+			// Replies that can't be encoded in protobuf are sent as JSON, the client handles both.
+			if data, err := dashapi.MarshalProto(reply); err == nil {
+				contentType, encode = dashapi.ProtoContentType, func(w io.Writer) error {
+					_, err := w.Write(data)
+					return err
+				}
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		if dashapi.HasETag(r.PostFormValue("method")) {
+			// Poll replies are encoded upfront to tag them, see dashapi.HasETag.
+			buf := new(bytes.Buffer)
+			if err := encode(buf); err != nil {
+				// Don't tag and send a partially encoded reply.
+				err = fmt.Errorf("failed to encode reply: %w", err)
+				http.Error(w, err.Error(), logErrorPrepareStatus(c, err))
+				return
+			}
+			etag := fmt.Sprintf("%q", hash.String(buf.Bytes()))
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			encode = func(w io.Writer) error {
+				_, err := w.Write(buf.Bytes())
+				return err
+			}
+		}
 		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
 			w.Header().Set("Content-Encoding", "gzip")
 			gz := gzip.NewWriter(w)
-			if err := json.NewEncoder(gz).Encode(reply); err != nil {
+			if err := encode(gz); err != nil {
 				log.Errorf(c, "failed to encode reply: %v", err)
 			}
 			gz.Close()
 		} else {
-			if err := json.NewEncoder(w).Encode(reply); err != nil {
+			if err := encode(w); err != nil {
 				log.Errorf(c, "failed to encode reply: %v", err)
 			}
 		}
@@ -126,7 +214,16 @@ func handleJSON(fn JSONHandler) http.Handler {
 }
 
 func handleAPI(c context.Context, r *http.Request) (reply interface{}, err error) {
-	client := r.PostFormValue("client")
+	sig := r.Header.Get(dashapi.SignatureHeader)
+	var body []byte
+	if sig != "" {
+		// The signature covers the raw body, so it needs to be read before the form is parsed.
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	client := credential(r, dashapi.ClientHeader, "client")
 	method := r.PostFormValue("method")
 	log.Infof(c, "api %q from %q", method, client)
 	if client == "" {
@@ -138,23 +235,27 @@ func handleAPI(c context.Context, r *http.Request) (reply interface{}, err error
 	if err != nil {
 		return nil, fmt.Errorf("failed to auth.DetermineAuthSubj(): %w", err)
 	}
-	password := r.PostFormValue("key")
-	ns, err := checkClient(getConfig(c), client, password, subj)
+	// Set only by clients that explicitly choose the namespace (see dashapi.Namespace).
+	namespace := r.PostFormValue("namespace")
+	password := credential(r, dashapi.KeyHeader, "key")
+	if sig != "" {
+		if password, err = checkSignature(c, client, namespace, method, sig, body); err != nil {
+			return nil, fmt.Errorf("checkSignature('%s') error: %w: %w", client, ErrClientForbidden, err)
+		}
+	}
+	ns, err := checkClient(getConfig(c), client, namespace, password, subj)
 	if err != nil {
-		return nil, fmt.Errorf("checkClient('%s') error: %w", client, err)
+		return nil, fmt.Errorf("checkClient('%s') error: %w: %w", client, ErrClientForbidden, err)
+	}
+	if ns != "" && !apiReadOnlyMethods[method] {
+		if _, ok := getNsConfig(c, ns).ReadOnlyClients[client]; ok {
+			return nil, fmt.Errorf("%w: client %q is read-only and can't call %q", ErrClientForbidden, client, method)
+		}
 	}
 	var payload []byte
 	if str := r.PostFormValue("payload"); str != "" {
-		gr, err := gzip.NewReader(strings.NewReader(str))
-		if err != nil {
-			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
-		}
-		payload, err = io.ReadAll(gr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
-		}
-		if err := gr.Close(); err != nil {
-			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
+		if payload, err = dashapi.DecompressPayload(r.PostFormValue("compression"), []byte(str)); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrClientBadRequest, err)
 		}
 	}
 	handler := apiHandlers[method]
@@ -163,27 +264,87 @@ func handleAPI(c context.Context, r *http.Request) (reply interface{}, err error
 	}
 	nsHandler := apiNamespaceHandlers[method]
 	if nsHandler == nil {
-		return nil, fmt.Errorf("unknown api method %q", method)
+		return nil, fmt.Errorf("%w: unknown api method %q", ErrClientBadRequest, method)
 	}
 	if ns == "" {
-		return nil, fmt.Errorf("method %q must be called within a namespace", method)
+		return nil, fmt.Errorf("%w: method %q must be called within a namespace", ErrClientBadRequest, method)
 	}
 	return nsHandler(c, ns, r, payload)
 }
 
+// credential returns the credential from the header, or from the form field for older clients.
+// URL query parameters are never consulted, so that keys don't end up in request logs.
+func credential(r *http.Request, header, field string) string {
+	if val := r.Header.Get(header); val != "" {
+		return val
+	}
+	return r.PostFormValue(field)
+}
+
+// unmarshalPayload decodes the request payload in the encoding chosen by the client
+// (see dashapi.ProtoEncoding).
+func unmarshalPayload(r *http.Request, payload []byte, req interface{}) error {
+	if r.PostFormValue("encoding") == "proto" {
+		return dashapi.UnmarshalProto(payload, req)
+	}
+	return json.Unmarshal(payload, req)
+}
+
 func apiLogError(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.LogEntry)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
-	}
-	log.Errorf(c, "%v: %v", req.Name, req.Text)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	var fields []string
+	for key, val := range req.Fields {
+		fields = append(fields, fmt.Sprintf(" %v=%q", key, val))
+	}
+	sort.Strings(fields)
+	logf := log.Errorf
+	switch req.Level {
+	case dashapi.LogLevelDebug:
+		logf = log.Debugf
+	case dashapi.LogLevelInfo:
+		logf = log.Infof
+	case dashapi.LogLevelWarn:
+		logf = log.Warningf
+	}
+	logf(c, "%v: %v%v", req.Name, req.Text, strings.Join(fields, ""))
 	return nil, nil
 }
 
+func apiCapabilities(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.CapabilitiesReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	log.Infof(c, "client API version %v", req.Version)
+	resp := &dashapi.CapabilitiesResp{
+		Version: dashapi.APIVersion,
+		Features: []string{
+			dashapi.FeatureZstd,
+			dashapi.FeatureUncompressed,
+			dashapi.FeatureProto,
+			dashapi.FeatureSignatures,
+			dashapi.FeatureIdempotency,
+			dashapi.FeatureBlobDedup,
+			dashapi.FeatureLongPoll,
+		},
+	}
+	for method := range apiHandlers {
+		resp.Methods = append(resp.Methods, method)
+	}
+	for method := range apiNamespaceHandlers {
+		resp.Methods = append(resp.Methods, method)
+	}
+	sort.Strings(resp.Methods)
+	return resp, nil
+}
+
 func apiBuilderPoll(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.BuilderPollReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	bugs, _, err := loadAllBugs(c, func(query *db.Query) *db.Query {
 		return query.Filter("Namespace=", ns).
@@ -297,8 +458,8 @@ func pollBackportCommits(c context.Context, ns string, count int) ([]string, err
 
 func apiUploadCommits(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.CommitPollResultReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	// This adds fixing commits to bugs.
 	err := addCommitsToBugs(c, ns, "", nil, req.Commits)
@@ -323,6 +484,59 @@ func apiUploadCommits(c context.Context, ns string, r *http.Request, payload []b
 	return nil, nil
 }
 
+func apiReportFixCommits(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReportFixCommitsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Title == "" || len(req.Commits) == 0 {
+		return nil, fmt.Errorf("%w: no bug title or commits", ErrClientBadRequest)
+	}
+	var titles []string
+	for _, com := range req.Commits {
+		if com.Title == "" {
+			return nil, fmt.Errorf("%w: commit without title", ErrClientBadRequest)
+		}
+		titles = append(titles, com.Title)
+	}
+	sort.Strings(titles)
+	bug, err := findExistingBugForCrash(c, ns, []string{req.Title})
+	if err != nil {
+		return nil, err
+	}
+	if bug == nil || bug.Status != BugStatusOpen {
+		return nil, fmt.Errorf("%w: no open bug %q", ErrClientNotFound, req.Title)
+	}
+	now := timeNow(c)
+	bugKey := bug.key(c)
+	tx := func(c context.Context) error {
+		bug = new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to get bug %v: %w", bugKey.StringID(), err)
+		}
+		if reflect.DeepEqual(bug.Commits, titles) {
+			return nil
+		}
+		bug.updateCommits(titles, now)
+		if _, err := db.Put(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to put bug: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		return nil, err
+	}
+	for _, com := range req.Commits {
+		if com.Hash == "" {
+			continue
+		}
+		if err := addCommitInfoToBug(c, bug, bugKey, com); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 func addCommitInfo(c context.Context, ns string, com dashapi.Commit) error {
 	var bugs []*Bug
 	keys, err := db.NewQuery("Bug").
@@ -406,8 +620,8 @@ func apiJobPoll(c context.Context, r *http.Request, payload []byte) (interface{}
 		return &dashapi.JobPollResp{}, err
 	}
 	req := new(dashapi.JobPollReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	if len(req.Managers) == 0 {
 		return nil, fmt.Errorf("no managers")
@@ -418,8 +632,8 @@ func apiJobPoll(c context.Context, r *http.Request, payload []byte) (interface{}
 // nolint: dupl
 func apiJobDone(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.JobDoneReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	err := doneJob(c, req)
 	return nil, err
@@ -428,8 +642,8 @@ func apiJobDone(c context.Context, r *http.Request, payload []byte) (interface{}
 // nolint: dupl
 func apiJobReset(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.JobResetReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	err := resetJobs(c, req)
 	return nil, err
@@ -437,14 +651,29 @@ func apiJobReset(c context.Context, r *http.Request, payload []byte) (interface{
 
 func apiUploadBuild(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.Build)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if err := checkRetired(c, ns, req.Manager); err != nil {
+		return nil, err
+	}
+	typ := BuildNormal
+	switch req.Purpose {
+	case dashapi.BuildPurposeManager:
+	case dashapi.BuildPurposeBisection, dashapi.BuildPurposePatchTest:
+		// Job builds must not affect the manager state and the build history.
+		typ = BuildJob
+	default:
+		return nil, fmt.Errorf("%w: unknown build purpose %q", ErrClientBadRequest, req.Purpose)
 	}
 	now := timeNow(c)
-	_, isNewBuild, err := uploadBuild(c, now, ns, req, BuildNormal)
+	_, isNewBuild, err := uploadBuild(c, now, ns, req, typ)
 	if err != nil {
 		return nil, err
 	}
+	if typ == BuildJob {
+		return nil, nil
+	}
 	if isNewBuild {
 		err := updateManager(c, ns, req.Manager, func(mgr *Manager, stats *ManagerStats) error {
 			prevKernel, prevSyzkaller := "", ""
@@ -471,22 +700,59 @@ func apiUploadBuild(c context.Context, ns string, r *http.Request, payload []byt
 			return nil, err
 		}
 	}
-	if len(req.Commits) != 0 || len(req.FixCommits) != 0 {
-		for i := range req.FixCommits {
-			// Reset hashes just to make sure,
-			// the build does not necessary come from the master repo, so we must not remember hashes.
-			req.FixCommits[i].Hash = ""
-		}
-		if err := addCommitsToBugs(c, ns, req.Manager, req.Commits, req.FixCommits); err != nil {
-			// We've already uploaded the build successfully and manager can use it.
-			// Moreover, addCommitsToBugs scans all bugs and can take long time.
-			// So just log the error.
-			log.Errorf(c, "failed to add commits to bugs: %v", err)
-		}
+	if err := addBuildCommits(c, ns, req.Manager, req.Commits, req.FixCommits); err != nil {
+		// We've already uploaded the build successfully and manager can use it.
+		// Moreover, addCommitsToBugs scans all bugs and can take long time.
+		// So just log the error.
+		log.Errorf(c, "failed to add commits to bugs: %v", err)
 	}
 	return nil, nil
 }
 
+func apiBuildCommits(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.BuildCommitsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if err := checkRetired(c, ns, req.Manager); err != nil {
+		return nil, err
+	}
+	build := new(Build)
+	if err := db.Get(c, buildKey(c, ns, req.BuildID), build); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown build %q", ErrClientNotFound, req.BuildID)
+		}
+		return nil, fmt.Errorf("failed to get build %v: %w", req.BuildID, err)
+	}
+	if build.Manager != req.Manager {
+		return nil, fmt.Errorf("%w: build %q belongs to manager %v", ErrClientBadRequest,
+			req.BuildID, build.Manager)
+	}
+	mgr, err := loadManager(c, ns, req.Manager)
+	if err != nil {
+		return nil, err
+	}
+	if mgr.CurrentBuild != req.BuildID {
+		// The manager has switched to a newer build, the commits will be reported along with it.
+		log.Infof(c, "ignoring commits of old build %v of %v", req.BuildID, req.Manager)
+		return nil, nil
+	}
+	return nil, addBuildCommits(c, ns, req.Manager, req.Commits, req.FixCommits)
+}
+
+// addBuildCommits records that the commits are present in the current build of the manager.
+func addBuildCommits(c context.Context, ns, manager string, commits []string, fixCommits []dashapi.Commit) error {
+	if len(commits) == 0 && len(fixCommits) == 0 {
+		return nil
+	}
+	for i := range fixCommits {
+		// Reset hashes just to make sure,
+		// the build does not necessary come from the master repo, so we must not remember hashes.
+		fixCommits[i].Hash = ""
+	}
+	return addCommitsToBugs(c, ns, manager, commits, fixCommits)
+}
+
 func uploadBuild(c context.Context, now time.Time, ns string, req *dashapi.Build, typ BuildType) (
 	*Build, bool, error) {
 	newAssets := []Asset{}
@@ -500,12 +766,16 @@ func uploadBuild(c context.Context, now time.Time, ns string, req *dashapi.Build
 	if build, err := loadBuild(c, ns, req.ID); err == nil {
 		return build, false, nil
 	}
+	err := resolveBlobHashes(c, ns, req.BlobHashes, map[string]*[]byte{"KernelConfig": &req.KernelConfig})
+	if err != nil {
+		return nil, false, err
+	}
 	checkStrLen := func(str, name string, maxLen int) error {
 		if str == "" {
 			return fmt.Errorf("%v is empty", name)
 		}
 		if len(str) > maxLen {
-			return fmt.Errorf("%v is too long (%v)", name, len(str))
+			return fmt.Errorf("%w: %v is too long (%v)", ErrClientTooLarge, name, len(str))
 		}
 		return nil
 	}
@@ -519,16 +789,19 @@ func uploadBuild(c context.Context, now time.Time, ns string, req *dashapi.Build
 		return nil, false, err
 	}
 	if len(req.KernelBranch) > MaxStringLen {
-		return nil, false, fmt.Errorf("Build.KernelBranch is too long (%v)", len(req.KernelBranch))
+		return nil, false, fmt.Errorf("%w: Build.KernelBranch is too long (%v)", ErrClientTooLarge, len(req.KernelBranch))
 	}
 	if err := checkStrLen(req.SyzkallerCommit, "Build.SyzkallerCommit", MaxStringLen); err != nil {
 		return nil, false, err
 	}
 	if len(req.CompilerID) > MaxStringLen {
-		return nil, false, fmt.Errorf("Build.CompilerID is too long (%v)", len(req.CompilerID))
+		return nil, false, fmt.Errorf("%w: Build.CompilerID is too long (%v)", ErrClientTooLarge, len(req.CompilerID))
 	}
 	if len(req.KernelCommit) > MaxStringLen {
-		return nil, false, fmt.Errorf("Build.KernelCommit is too long (%v)", len(req.KernelCommit))
+		return nil, false, fmt.Errorf("%w: Build.KernelCommit is too long (%v)", ErrClientTooLarge, len(req.KernelCommit))
+	}
+	if err := checkBuildTarget(c, ns, req); err != nil {
+		return nil, false, err
 	}
 	configID, err := putText(c, ns, textKernelConfig, req.KernelConfig)
 	if err != nil {
@@ -560,6 +833,22 @@ func uploadBuild(c context.Context, now time.Time, ns string, req *dashapi.Build
 	return build, true, nil
 }
 
+// checkBuildTarget checks that the build is for a known target the namespace accepts.
+func checkBuildTarget(c context.Context, ns string, req *dashapi.Build) error {
+	if targets.List[req.OS][req.Arch] == nil {
+		return fmt.Errorf("%w: unknown build target %v/%v", ErrClientBadRequest, req.OS, req.Arch)
+	}
+	if req.VMArch != "" && targets.List[req.OS][req.VMArch] == nil {
+		return fmt.Errorf("%w: unknown build VM arch %v/%v", ErrClientBadRequest, req.OS, req.VMArch)
+	}
+	oses := getNsConfig(c, ns).OSes
+	if len(oses) != 0 && !stringInList(oses, req.OS) {
+		return fmt.Errorf("%w: namespace %v does not accept %v builds (accepted: %v)",
+			ErrClientBadRequest, ns, req.OS, strings.Join(oses, ", "))
+	}
+	return nil
+}
+
 func addCommitsToBugs(c context.Context, ns, manager string, titles []string, fixCommits []dashapi.Commit) error {
 	presentCommits := make(map[string]bool)
 	bugFixedBy := make(map[string][]string)
@@ -630,6 +919,7 @@ func addCommitsToBug(c context.Context, bug *Bug, manager string, managers, fixC
 	}
 	now := timeNow(c)
 	bugKey := bug.key(c)
+	patched := false
 	tx := func(c context.Context) error {
 		bug := new(Bug)
 		if err := db.Get(c, bugKey, bug); err != nil {
@@ -642,7 +932,9 @@ func addCommitsToBug(c context.Context, bug *Bug, manager string, managers, fixC
 			bug.updateCommits(fixCommits, now)
 		}
 		if manager != "" {
+			patched = true
 			bug.PatchedOn = append(bug.PatchedOn, manager)
+			bug.PatchedTime = now
 			if bug.Status == BugStatusOpen {
 				fixed := true
 				for _, mgr := range managers {
@@ -662,7 +954,17 @@ func addCommitsToBug(c context.Context, bug *Bug, manager string, managers, fixC
 		}
 		return nil
 	}
-	return db.RunInTransaction(c, tx, nil)
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		return err
+	}
+	if patched && bug.ReproLevel != ReproLevelNone {
+		// Ask the manager to confirm the fix, failing to do so doesn't affect the bug.
+		err := saveManagerCommand(c, bug.Namespace, manager, dashapi.ManagerCommandRetest, bug.keyHash(c), "")
+		if err != nil {
+			log.Warningf(c, "failed to queue retest of %q on %v: %v", bug.Title, manager, err)
+		}
+	}
+	return nil
 }
 
 func bugNeedsCommitUpdate(c context.Context, bug *Bug, manager string, fixCommits []string,
@@ -706,10 +1008,18 @@ func managerList(c context.Context, ns string) ([]string, error) {
 	return managers, nil
 }
 
+func apiQueueBisect(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.QueueBisectReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	return queueBisectJob(c, ns, req)
+}
+
 func apiReportBuildError(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.BuildErrorReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	now := timeNow(c)
 	build, _, err := uploadBuild(c, now, ns, &req.Build, BuildFailed)
@@ -717,7 +1027,11 @@ func apiReportBuildError(c context.Context, ns string, r *http.Request, payload
 		return nil, fmt.Errorf("failed to store build: %w", err)
 	}
 	req.Crash.BuildID = req.Build.ID
-	bug, err := reportCrash(c, build, &req.Crash)
+	err = resolveBlobHashes(c, ns, req.Crash.BlobHashes, map[string]*[]byte{"Log": &req.Crash.Log})
+	if err != nil {
+		return nil, err
+	}
+	bug, _, err := reportCrash(c, build, &req.Crash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store crash: %w", err)
 	}
@@ -746,13 +1060,61 @@ func apiReportCrash(c context.Context, ns string, r *http.Request, payload []byt
 		return &dashapi.ReportCrashResp{}, err
 	}
 	req := new(dashapi.Crash)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	return reportCrashReq(c, ns, req)
+}
+
+func apiReportCrashes(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReportCrashesReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if len(req.Crashes) > dashapi.MaxCrashBatch {
+		return nil, fmt.Errorf("%w: too many crashes: %v, max %v",
+			ErrClientBadRequest, len(req.Crashes), dashapi.MaxCrashBatch)
+	}
+	resp := new(dashapi.ReportCrashesResp)
+	stop, err := emergentlyStopped(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, crash := range req.Crashes {
+		res := new(dashapi.ReportCrashResult)
+		resp.Results = append(resp.Results, res)
+		if stop {
+			// The bot's operation was aborted. Don't accept new crash reports.
+			continue
+		}
+		crashResp, err := reportCrashReq(c, ns, crash)
+		if err != nil {
+			log.Errorf(c, "failed to report crash %q: %v", crash.Title, err)
+			res.Error = err.Error()
+			continue
+		}
+		res.ReportCrashResp = *crashResp
+	}
+	return resp, nil
+}
+
+func reportCrashReq(c context.Context, ns string, req *dashapi.Crash) (*dashapi.ReportCrashResp, error) {
+	return idempotent(c, ns, "report_crash", req.IdempotencyKey, func() (*dashapi.ReportCrashResp, error) {
+		return reportCrashNew(c, ns, req)
+	})
+}
+
+func reportCrashNew(c context.Context, ns string, req *dashapi.Crash) (*dashapi.ReportCrashResp, error) {
+	if err := resolveChunkRefs(c, ns, req); err != nil {
+		return nil, err
 	}
 	build, err := loadBuild(c, ns, req.BuildID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRetired(c, ns, build.Manager); err != nil {
+		return nil, err
+	}
 	if !getNsConfig(c, ns).TransformCrash(build, req) {
 		return new(dashapi.ReportCrashResp), nil
 	}
@@ -763,29 +1125,31 @@ func apiReportCrash(c context.Context, ns string, r *http.Request, payload []byt
 			return nil, fmt.Errorf("original bug query failed: %w", err)
 		}
 	}
-	bug, err := reportCrash(c, build, req)
+	bug, crashID, err := reportCrash(c, build, req)
 	if err != nil {
 		return nil, err
 	}
 	if bug2 != nil && bug2.Title != bug.Title && len(req.ReproLog) > 0 {
 		// During bug reproduction, we have diverted to another bug.
 		// Let's remember this.
-		err = saveFailedReproLog(c, bug2, build, req.ReproLog)
+		err = saveFailedReproLog(c, bug2, build, req.ReproLog, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to save failed repro log: %w", err)
 		}
 	}
 	resp := &dashapi.ReportCrashResp{
-		NeedRepro: needRepro(c, bug),
+		NeedRepro:  needRepro(c, bug),
+		CrashID:    crashID,
+		ReproLevel: bug.ReproLevel,
 	}
 	return resp, nil
 }
 
 // nolint: gocyclo
-func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, error) {
+func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, int64, error) {
 	assets, err := parseCrashAssets(c, req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	req.Title = canonicalizeCrashTitle(req.Title, req.Corrupted, req.Suppressed)
 	if req.Corrupted || req.Suppressed {
@@ -796,17 +1160,41 @@ func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, err
 		}
 		req.AltTitles = mergeStringList([]string{req.Title}, req.AltTitles) // dedup
 	}
+	switch req.Kind {
+	case dashapi.CrashOops:
+	case dashapi.CrashLeak:
+		// Leaks are reported by an unrelated task, only the allocation stack identifies the bug.
+		req.Signature = req.Leak.Signature()
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown crash kind %q", ErrClientBadRequest, req.Kind)
+	}
+	if req.Corrupted || req.Suppressed || len(req.Signature) > MaxStringLen {
+		req.Signature = ""
+	}
+	if err := checkCrashAttachments(req.Attachments); err != nil {
+		return nil, 0, err
+	}
+	if !req.Type.Known() {
+		// Newer managers may know more types, that's not a reason to drop the crash.
+		log.Warningf(c, "unknown crash type %q of %q", req.Type, req.Title)
+		req.Type = dashapi.CrashTypeUnknown
+	}
 	req.Maintainers = email.MergeEmailLists(req.Maintainers)
 
 	ns := build.Namespace
 	bug, err := findBugForCrash(c, ns, req.AltTitles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find bug for the crash: %w", err)
+		return nil, 0, fmt.Errorf("failed to find bug for the crash: %w", err)
+	}
+	if bug == nil && req.Signature != "" {
+		if bug, err = findBugForSignature(c, ns, req.Signature); err != nil {
+			return nil, 0, fmt.Errorf("failed to find bug for the crash signature: %w", err)
+		}
 	}
 	if bug == nil {
 		bug, err = createBugForCrash(c, ns, req)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create a bug: %w", err)
+			return nil, 0, fmt.Errorf("failed to create a bug: %w", err)
 		}
 	}
 
@@ -823,10 +1211,13 @@ func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, err
 		now.Sub(bug.LastSavedCrash) > time.Hour ||
 		bug.NumCrashes%20 == 0 ||
 		!stringInList(bug.MergedTitles, req.Title)
+	var crashID int64
 	if save {
-		if err := saveCrash(c, ns, req, bug, bugKey, build, assets); err != nil {
-			return nil, fmt.Errorf("failed to save the crash: %w", err)
+		crashKey, err := saveCrash(c, ns, req, bug, bugKey, build, assets)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to save the crash: %w", err)
 		}
+		crashID = crashKey.IntID()
 	} else {
 		log.Infof(c, "not saving crash for %q", bug.Title)
 	}
@@ -842,10 +1233,12 @@ func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, err
 		newSubsystems, err = inferSubsystems(c, bug, bugKey, &debugtracer.NullTracer{})
 		if err != nil {
 			log.Errorf(c, "%q: failed to extract subsystems: %s", bug.Title, err)
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
+	// Only new bugs and better reproducers may be reported right away.
+	reportable := save && (bug.NumCrashes == 0 || bug.ReproLevel < reproLevel)
 	tx := func(c context.Context) error {
 		bug = new(Bug)
 		if err := db.Get(c, bugKey, bug); err != nil {
@@ -877,18 +1270,133 @@ func reportCrash(c context.Context, build *Build, req *dashapi.Crash) (*Bug, err
 		bug.MergedTitles = mergeString(bug.MergedTitles, bug.Title)
 		bug.MergedTitles = mergeString(bug.MergedTitles, req.Title)
 		bug.AltTitles = mergeStringList(bug.AltTitles, req.AltTitles)
+		if bug.CrashType == dashapi.CrashTypeUnknown && !req.Corrupted {
+			bug.CrashType = req.Type
+		}
+		// Once the embargo has passed or was cleared, crashes don't embargo the bug again.
+		if req.EmbargoUntil.After(bug.EmbargoUntil) && (bug.EmbargoUntil.IsZero() || bug.embargoed(c)) {
+			bug.EmbargoUntil = req.EmbargoUntil
+		}
+		if req.Signature != "" && len(bug.Signatures) < maxBugSignatures {
+			bug.Signatures = mergeString(bug.Signatures, req.Signature)
+		}
 		if _, err = db.Put(c, bugKey, bug); err != nil {
 			return fmt.Errorf("failed to put bug: %w", err)
 		}
 		return nil
 	}
 	if err := db.RunInTransaction(c, tx, &db.TransactionOptions{XG: true}); err != nil {
-		return nil, fmt.Errorf("bug updating failed: %w", err)
+		return nil, 0, fmt.Errorf("bug updating failed: %w", err)
 	}
 	if save {
 		purgeOldCrashes(c, bug, bugKey)
 	}
-	return bug, nil
+	if reportable {
+		bumpReportingGeneration(c)
+	}
+	return bug, crashID, nil
+}
+
+func apiUpdateCrash(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UpdateCrashReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if len(req.ReproSyz) == 0 {
+		return nil, fmt.Errorf("%w: no reproducer", ErrClientBadRequest)
+	}
+	var assets []Asset
+	for i, toAdd := range req.Assets {
+		asset, err := parseIncomingAsset(c, toAdd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse asset #%d: %w", i, err)
+		}
+		assets = append(assets, asset)
+	}
+	build, err := loadBuild(c, ns, req.BuildID)
+	if err != nil {
+		return nil, err
+	}
+	title := canonicalizeCrashTitle(req.Title, false, false)
+	bug, err := findBugForCrash(c, ns, []string{title})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bug for the crash: %w", err)
+	}
+	if bug == nil {
+		return nil, fmt.Errorf("%w: no bug for %q", ErrClientNotFound, title)
+	}
+	bugKey := bug.key(c)
+	crashKey := db.NewKey(c, "Crash", "", req.CrashID, bugKey)
+	crash := new(Crash)
+	if err := db.Get(c, crashKey, crash); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown crash %v", ErrClientNotFound, req.CrashID)
+		}
+		return nil, fmt.Errorf("failed to get crash: %w", err)
+	}
+	if crash.BuildID != req.BuildID {
+		return nil, fmt.Errorf("%w: crash %v is not from build %v", ErrClientNotFound, req.CrashID, req.BuildID)
+	}
+	if crash.ReproSyz != 0 {
+		// The request is retried.
+		return nil, nil
+	}
+	reproLevel := ReproLevelSyz
+	if len(req.ReproC) != 0 {
+		reproLevel = ReproLevelC
+	}
+	crash.ReproOpts = req.ReproOpts
+	crash.Assets = append(crash.Assets, assets...)
+	if crash.ReproSyz, err = putText(c, ns, textReproSyz, req.ReproSyz); err != nil {
+		return nil, err
+	}
+	if crash.ReproC, err = putText(c, ns, textReproC, req.ReproC); err != nil {
+		return nil, err
+	}
+	if crash.ReproLog, err = putText(c, ns, textReproLog, req.ReproLog); err != nil {
+		return nil, err
+	}
+	crash.UpdateReportingPriority(c, build, bug)
+	if _, err := db.Put(c, crashKey, crash); err != nil {
+		return nil, fmt.Errorf("failed to put crash: %w", err)
+	}
+	// Recalculate subsystems on the first repro, same as reportCrash does.
+	var newSubsystems []*subsystem.Subsystem
+	calculateSubsystems := !bug.hasUserSubsystems() && bug.ReproLevel == ReproLevelNone
+	if calculateSubsystems {
+		newSubsystems, err = inferSubsystems(c, bug, bugKey, &debugtracer.NullTracer{})
+		if err != nil {
+			log.Errorf(c, "%q: failed to extract subsystems: %s", bug.Title, err)
+			return nil, err
+		}
+	}
+	now := timeNow(c)
+	tx := func(c context.Context) error {
+		bug := new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to get bug: %w", err)
+		}
+		bug.NumRepro++
+		bug.LastReproTime = now
+		if bug.ReproLevel < reproLevel {
+			bug.ReproLevel = reproLevel
+		}
+		if bug.HeadReproLevel < reproLevel {
+			bug.HeadReproLevel = reproLevel
+		}
+		if calculateSubsystems {
+			bug.SetAutoSubsystems(c, newSubsystems, now, getNsConfig(c, ns).Subsystems.Revision)
+		}
+		if _, err := db.Put(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to put bug: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		return nil, fmt.Errorf("bug updating failed: %w", err)
+	}
+	bumpReportingGeneration(c)
+	return nil, nil
 }
 
 func parseCrashAssets(c context.Context, req *dashapi.Crash) ([]Asset, error) {
@@ -925,7 +1433,7 @@ func (crash *Crash) UpdateReportingPriority(c context.Context, build *Build, bug
 }
 
 func saveCrash(c context.Context, ns string, req *dashapi.Crash, bug *Bug, bugKey *db.Key,
-	build *Build, assets []Asset) error {
+	build *Build, assets []Asset) (*db.Key, error) {
 	crash := &Crash{
 		Title:   req.Title,
 		Manager: build.Manager,
@@ -939,33 +1447,57 @@ func saveCrash(c context.Context, ns string, req *dashapi.Crash, bug *Bug, bugKe
 		Assets:    assets,
 		ReportElements: CrashReportElements{
 			GuiltyFiles: req.GuiltyFiles,
+			Subsystems:  req.Subsystems,
 		},
 	}
+	if req.Machine != nil {
+		crash.Machine = *req.Machine
+	}
+	if req.Leak != nil {
+		crash.Leak = *req.Leak
+	}
+	crash.Type = req.Type
+	if cfg := getNsConfig(c, ns).Subsystems; cfg.GuiltyFileMaintainers &&
+		cfg.Service != nil && len(req.GuiltyFiles) != 0 {
+		crash.Maintainers = email.MergeEmailLists(crash.Maintainers,
+			cfg.Service.PathMaintainers(req.GuiltyFiles[0]))
+	}
 	var err error
 	if crash.Log, err = putText(c, ns, textCrashLog, req.Log); err != nil {
-		return err
+		return nil, err
 	}
 	if crash.Report, err = putText(c, ns, textCrashReport, req.Report); err != nil {
-		return err
+		return nil, err
 	}
 	if crash.ReproSyz, err = putText(c, ns, textReproSyz, req.ReproSyz); err != nil {
-		return err
+		return nil, err
 	}
 	if crash.ReproC, err = putText(c, ns, textReproC, req.ReproC); err != nil {
-		return err
+		return nil, err
 	}
 	if crash.MachineInfo, err = putText(c, ns, textMachineInfo, req.MachineInfo); err != nil {
-		return err
+		return nil, err
 	}
 	if crash.ReproLog, err = putText(c, ns, textReproLog, req.ReproLog); err != nil {
-		return err
+		return nil, err
+	}
+	for _, att := range req.Attachments {
+		data, err := putText(c, ns, textAttachment, att.Data)
+		if err != nil {
+			return nil, err
+		}
+		crash.Attachments = append(crash.Attachments, CrashAttachment{
+			Name:     att.Name,
+			MIMEType: att.MIMEType,
+			Data:     data,
+		})
 	}
 	crash.UpdateReportingPriority(c, build, bug)
 	crashKey := db.NewIncompleteKey(c, "Crash", bugKey)
-	if _, err = db.Put(c, crashKey, crash); err != nil {
-		return fmt.Errorf("failed to put crash: %w", err)
+	if crashKey, err = db.Put(c, crashKey, crash); err != nil {
+		return nil, fmt.Errorf("failed to put crash: %w", err)
 	}
-	return nil
+	return crashKey, nil
 }
 
 func purgeOldCrashes(c context.Context, bug *Bug, bugKey *db.Key) {
@@ -1031,6 +1563,11 @@ func purgeOldCrashes(c context.Context, bug *Bug, bugKey *db.Key) {
 		if crash.ReproC != 0 {
 			toDelete = append(toDelete, db.NewKey(c, textReproC, "", crash.ReproC, nil))
 		}
+		for _, att := range crash.Attachments {
+			if att.Data != 0 {
+				toDelete = append(toDelete, db.NewKey(c, textAttachment, "", att.Data, nil))
+			}
+		}
 		deleted++
 		if deleted == 2*purgeEvery {
 			break
@@ -1046,10 +1583,57 @@ func purgeOldCrashes(c context.Context, bug *Bug, bugKey *db.Key) {
 	log.Infof(c, "deleted %v crashes for bug %q", deleted, bug.Title)
 }
 
-func apiReportFailedRepro(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
-	req := new(dashapi.CrashID)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+func apiCountCrash(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	if stop, err := emergentlyStopped(c); err != nil || stop {
+		return &dashapi.CountCrashResp{}, err
+	}
+	req := new(dashapi.CrashID)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	req.Title = canonicalizeCrashTitle(req.Title, req.Corrupted, req.Suppressed)
+	build, err := loadBuild(c, ns, req.BuildID)
+	if err != nil {
+		return nil, err
+	}
+	bug, err := findExistingBugForCrash(c, ns, []string{req.Title})
+	if err != nil {
+		return nil, err
+	}
+	if bug == nil {
+		// The client needs to upload the full crash.
+		return &dashapi.CountCrashResp{}, nil
+	}
+	now := timeNow(c)
+	bugKey := bug.key(c)
+	tx := func(c context.Context) error {
+		bug = new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to get bug: %w", err)
+		}
+		bug.LastTime = now
+		bug.increaseCrashStats(now)
+		bug.HappenedOn = mergeString(bug.HappenedOn, build.Manager)
+		if _, err := db.Put(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to put bug: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10}); err != nil {
+		return nil, fmt.Errorf("bug updating failed: %w", err)
+	}
+	resp := &dashapi.CountCrashResp{
+		Found:      true,
+		NeedRepro:  needRepro(c, bug),
+		ReproLevel: bug.ReproLevel,
+	}
+	return resp, nil
+}
+
+func apiReportFailedRepro(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.CrashID)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	req.Title = canonicalizeCrashTitle(req.Title, req.Corrupted, req.Suppressed)
 
@@ -1064,10 +1648,12 @@ func apiReportFailedRepro(c context.Context, ns string, r *http.Request, payload
 	if err != nil {
 		return nil, err
 	}
-	return nil, saveFailedReproLog(c, bug, build, req.ReproLog)
+	return nil, saveFailedReproLog(c, bug, build, req.ReproLog, req.FailedRepro)
 }
 
-func saveFailedReproLog(c context.Context, bug *Bug, build *Build, log []byte) error {
+// saveFailedReproLog records a failed repro attempt, info is nil if the manager
+// did not describe the attempt.
+func saveFailedReproLog(c context.Context, bug *Bug, build *Build, log []byte, info *dashapi.FailedRepro) error {
 	now := timeNow(c)
 	bugKey := bug.key(c)
 	tx := func(c context.Context) error {
@@ -1075,10 +1661,14 @@ func saveFailedReproLog(c context.Context, bug *Bug, build *Build, log []byte) e
 		if err := db.Get(c, bugKey, bug); err != nil {
 			return fmt.Errorf("failed to get bug: %w", err)
 		}
-		bug.NumRepro++
+		// If no programs were run (e.g. the manager was shutting down), the attempt says nothing
+		// about the bug reproducibility and does not count against maxReproPerBug.
+		if info == nil || info.Attempts != 0 {
+			bug.NumRepro++
+		}
 		bug.LastReproTime = now
 		if len(log) > 0 {
-			err := saveReproAttempt(c, bug, build, log)
+			err := saveReproAttempt(c, bug, build, log, info)
 			if err != nil {
 				return fmt.Errorf("failed to save repro log: %w", err)
 			}
@@ -1096,7 +1686,7 @@ func saveFailedReproLog(c context.Context, bug *Bug, build *Build, log []byte) e
 
 const maxReproLogs = 5
 
-func saveReproAttempt(c context.Context, bug *Bug, build *Build, log []byte) error {
+func saveReproAttempt(c context.Context, bug *Bug, build *Build, log []byte, info *dashapi.FailedRepro) error {
 	var deleteKeys []*db.Key
 	for len(bug.ReproAttempts)+1 > maxReproLogs {
 		deleteKeys = append(deleteKeys,
@@ -1107,12 +1697,23 @@ func saveReproAttempt(c context.Context, bug *Bug, build *Build, log []byte) err
 		Time:    timeNow(c),
 		Manager: build.Manager,
 	}
+	if info != nil {
+		entry.Attempts = info.Attempts
+		entry.Duration = info.Duration
+		var strategies []string
+		for _, strategy := range info.Strategies {
+			strategies = append(strategies, string(strategy))
+		}
+		entry.Strategies = strings.Join(strategies, ",")
+	}
 	var err error
 	if entry.Log, err = putText(c, bug.Namespace, textReproLog, log); err != nil {
 		return err
 	}
 	if len(deleteKeys) > 0 {
-		return db.DeleteMulti(c, deleteKeys)
+		if err := db.DeleteMulti(c, deleteKeys); err != nil {
+			return err
+		}
 	}
 	bug.ReproAttempts = append(bug.ReproAttempts, entry)
 	return nil
@@ -1120,8 +1721,8 @@ func saveReproAttempt(c context.Context, bug *Bug, build *Build, log []byte) err
 
 func apiNeedRepro(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.CrashID)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	if req.Corrupted {
 		resp := &dashapi.NeedReproResp{
@@ -1146,11 +1747,76 @@ func apiNeedRepro(c context.Context, ns string, r *http.Request, payload []byte)
 		return nil, fmt.Errorf("%v: can't find bug for crash %q", ns, req.Title)
 	}
 	resp := &dashapi.NeedReproResp{
-		NeedRepro: needRepro(c, bug),
+		NeedRepro:  needRepro(c, bug),
+		ReproLevel: bug.ReproLevel,
+	}
+	return resp, nil
+}
+
+// Don't let a single request scan too many bugs.
+const maxBugStatusTitles = 1000
+
+func apiBugStatus(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.BugStatusReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if len(req.Titles) > maxBugStatusTitles {
+		return nil, fmt.Errorf("%w: too many titles (%v)", ErrClientBadRequest, len(req.Titles))
+	}
+	resp := &dashapi.BugStatusResp{}
+	for _, title := range req.Titles {
+		info, err := bugStatusForTitle(c, ns, title)
+		if err != nil {
+			return nil, err
+		}
+		resp.Bugs = append(resp.Bugs, info)
 	}
 	return resp, nil
 }
 
+func bugStatusForTitle(c context.Context, ns, title string) (*dashapi.BugStatusInfo, error) {
+	info := &dashapi.BugStatusInfo{Title: title}
+	bug, err := findExistingBugForCrash(c, ns, []string{normalizeCrashTitle(title)})
+	if err != nil {
+		return nil, err
+	}
+	if bug != nil {
+		info.Active = true
+	} else {
+		// There is no active bug, find the latest closed one.
+		var bugs []*Bug
+		_, err := db.NewQuery("Bug").
+			Filter("Namespace=", ns).
+			Filter("MergedTitles=", normalizeCrashTitle(title)).
+			GetAll(c, &bugs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query bugs: %w", err)
+		}
+		for _, b := range bugs {
+			if bug == nil || b.Seq > bug.Seq {
+				bug = b
+			}
+		}
+		if bug == nil {
+			return info, nil
+		}
+	}
+	info.Found = true
+	info.ReproLevel = bug.ReproLevel
+	if info.Status, err = bug.dashapiStatus(); err != nil {
+		return nil, err
+	}
+	info.Link = appURL(c) + bugExtLink(c, bug)
+	for i := len(bug.Reporting) - 1; i >= 0; i-- {
+		if link := bug.Reporting[i].Link; link != "" {
+			info.ExtLink = link
+			break
+		}
+	}
+	return info, nil
+}
+
 func canonicalizeCrashTitle(title string, corrupted, suppressed bool) string {
 	if corrupted {
 		// The report is corrupted and the title is most likely invalid.
@@ -1172,14 +1838,17 @@ func normalizeCrashTitle(title string) string {
 
 func apiManagerStats(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.ManagerStatsReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	now := timeNow(c)
 	err := updateManager(c, ns, req.Name, func(mgr *Manager, stats *ManagerStats) error {
 		mgr.Link = req.Addr
 		mgr.LastAlive = now
 		mgr.CurrentUpTime = req.UpTime
+		mgr.CurrentVMs = int64(req.VMs)
+		mgr.TotalVMs = int64(req.TotalVMs)
+		mgr.ConfigVersion = req.ConfigVersion
 		if cur := int64(req.Corpus); cur > stats.MaxCorpus {
 			stats.MaxCorpus = cur
 		}
@@ -1207,6 +1876,70 @@ func apiManagerStats(c context.Context, ns string, r *http.Request, payload []by
 	return nil, err
 }
 
+func apiManagerConfig(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ManagerConfigReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	cfg := getNsConfig(c, ns)
+	nsOverrides := &cfg.ManagerOverrides
+	mgrOverrides := cfg.Managers[req.Manager].Overrides
+	resp := &dashapi.ManagerConfigResp{
+		DisabledSyscalls: append(append([]string{}, nsOverrides.DisabledSyscalls...),
+			mgrOverrides.DisabledSyscalls...),
+		SuppressedTitles: append(append([]string{}, nsOverrides.SuppressedTitles...),
+			mgrOverrides.SuppressedTitles...),
+		MaxReproAttempts: nsOverrides.MaxReproAttempts,
+		ReportingPaused:  nsOverrides.ReportingPaused || mgrOverrides.ReportingPaused,
+	}
+	if mgrOverrides.MaxReproAttempts != 0 {
+		resp.MaxReproAttempts = mgrOverrides.MaxReproAttempts
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp.Version = hash.String(data)
+	if resp.Version == req.CurrentVersion {
+		return &dashapi.ManagerConfigResp{
+			Version:   resp.Version,
+			Unchanged: true,
+		}, nil
+	}
+	return resp, nil
+}
+
+func apiReposPoll(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReposPollReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	resp := new(dashapi.ReposResp)
+	for _, repo := range getNsConfig(c, ns).Repos {
+		resp.Repos = append(resp.Repos, &dashapi.KernelTree{
+			Alias:    repo.Alias,
+			URL:      repo.URL,
+			Branch:   repo.Branch,
+			OS:       repo.OS,
+			Arch:     repo.Arch,
+			Priority: repo.ReportingPriority,
+			NoPoll:   repo.NoPoll,
+		})
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp.Version = hash.String(data)
+	if resp.Version == req.CurrentVersion {
+		return &dashapi.ReposResp{
+			Version:   resp.Version,
+			Unchanged: true,
+		}, nil
+	}
+	return resp, nil
+}
+
 func apiBugList(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	keys, err := db.NewQuery("Bug").
 		Filter("Namespace=", ns).
@@ -1222,18 +1955,138 @@ func apiBugList(c context.Context, ns string, r *http.Request, payload []byte) (
 	return resp, nil
 }
 
+func apiOpenBugs(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.OpenBugsReq)
+	// Older clients don't send the request.
+	if len(payload) != 0 {
+		if err := unmarshalPayload(r, payload, req); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+		}
+	}
+	bugs, _, err := loadAllBugs(c, func(query *db.Query) *db.Query {
+		query = query.Filter("Namespace=", ns).
+			Filter("Status=", BugStatusOpen)
+		if req.Subsystem != "" {
+			query = query.Filter("Labels.Label=", SubsystemLabel).
+				Filter("Labels.Value=", req.Subsystem)
+		}
+		return query
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(bugs, func(i, j int) bool {
+		return bugs[i].Title < bugs[j].Title
+	})
+	resp := &dashapi.OpenBugsResp{}
+	for _, bug := range bugs {
+		info := &dashapi.OpenBug{
+			ID:         bug.keyHash(c),
+			Title:      bug.Title,
+			Namespace:  bug.Namespace,
+			ReproLevel: bug.ReproLevel,
+			FixCommits: bug.Commits,
+		}
+		for _, item := range bug.LabelValues(SubsystemLabel) {
+			info.Subsystems = append(info.Subsystems, item.Value)
+		}
+		resp.Bugs = append(resp.Bugs, info)
+	}
+	return resp, nil
+}
+
+// managerNotifsMaxAge limits how far back in the past apiManagerNotifs looks up bug changes.
+const managerNotifsMaxAge = 30 * 24 * time.Hour
+
+func apiManagerNotifs(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ManagerNotifsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	now := timeNow(c)
+	since := req.Since
+	if oldest := now.Add(-managerNotifsMaxAge); since.Before(oldest) {
+		since = oldest
+	}
+	openBugs, _, err := loadAllBugs(c, func(query *db.Query) *db.Query {
+		return query.Filter("Namespace=", ns).
+			Filter("Status=", BugStatusOpen).
+			Filter("HappenedOn=", req.Manager)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open bugs: %w", err)
+	}
+	closedBugs, _, err := loadAllBugs(c, func(query *db.Query) *db.Query {
+		return query.Filter("Namespace=", ns).
+			Filter("HappenedOn=", req.Manager).
+			Filter("Closed>", since)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed bugs: %w", err)
+	}
+	resp := &dashapi.ManagerNotifsResp{Until: now}
+	for _, bug := range openBugs {
+		if len(bug.Commits) == 0 {
+			continue
+		}
+		notif := &dashapi.ManagerNotif{
+			Title:      bug.Title,
+			FixCommits: bug.Commits,
+		}
+		if stringInList(bug.PatchedOn, req.Manager) {
+			notif.Type, notif.Time = dashapi.ManagerNotifRetest, bug.PatchedTime
+		} else {
+			notif.Type, notif.Time = dashapi.ManagerNotifFixPending, bug.FixTime
+		}
+		if notif.Time.After(since) {
+			resp.Notifs = append(resp.Notifs, notif)
+		}
+	}
+	for _, bug := range closedBugs {
+		notif := &dashapi.ManagerNotif{
+			Title:      bug.Title,
+			Time:       bug.Closed,
+			FixCommits: bug.Commits,
+		}
+		switch bug.Status {
+		case BugStatusInvalid:
+			notif.Type = dashapi.ManagerNotifInvalid
+		case BugStatusFixed:
+			notif.Type = dashapi.ManagerNotifFixed
+		case BugStatusDup:
+			canon, err := canonicalBug(c, bug)
+			if err != nil {
+				return nil, err
+			}
+			notif.Type = dashapi.ManagerNotifDup
+			notif.DupOf = canon.Title
+		default:
+			// Bugs can be reopened after they were closed.
+			continue
+		}
+		resp.Notifs = append(resp.Notifs, notif)
+	}
+	sort.SliceStable(resp.Notifs, func(i, j int) bool {
+		return resp.Notifs[i].Time.Before(resp.Notifs[j].Time)
+	})
+	return resp, nil
+}
+
 func apiUpdateReport(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.UpdateReportReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	bug := new(Bug)
 	bugKey := db.NewKey(c, "Bug", req.BugID, 0, nil)
 	if err := db.Get(c, bugKey, bug); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown bug %q", ErrClientNotFound, req.BugID)
+		}
 		return nil, fmt.Errorf("failed to get bug: %w", err)
 	}
 	if bug.Namespace != ns {
-		return nil, fmt.Errorf("no such bug")
+		return nil, fmt.Errorf("%w: no such bug", ErrClientNotFound)
 	}
 	tx := func(c context.Context) error {
 		crash := new(Crash)
@@ -1254,24 +2107,154 @@ func apiUpdateReport(c context.Context, ns string, r *http.Request, payload []by
 
 func apiLoadBug(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.LoadBugReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	bug := new(Bug)
 	bugKey := db.NewKey(c, "Bug", req.ID, 0, nil)
 	if err := db.Get(c, bugKey, bug); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown bug %q", ErrClientNotFound, req.ID)
+		}
+		return nil, fmt.Errorf("failed to get bug: %w", err)
+	}
+	if bug.Namespace != ns {
+		return nil, fmt.Errorf("%w: no such bug", ErrClientNotFound)
+	}
+	rep, err := loadBugReport(c, bug)
+	if err != nil || !req.PreferURLs {
+		return rep, err
+	}
+	crash := new(Crash)
+	if err := db.Get(c, db.NewKey(c, "Crash", "", rep.CrashID, bugKey), crash); err != nil {
+		return nil, fmt.Errorf("failed to get crash: %w", err)
+	}
+	build, err := loadBuild(c, ns, crash.BuildID)
+	if err != nil {
+		return nil, err
+	}
+	// Report and ReproSyz are not returned verbatim, so they are always inline.
+	blobs := []replyBlob{
+		{"Log", textCrashLog, crash.Log, &rep.Log},
+		{"MachineInfo", textMachineInfo, crash.MachineInfo, &rep.MachineInfo},
+		{"KernelConfig", textKernelConfig, build.KernelConfig, &rep.KernelConfig},
+	}
+	if !crash.ReproIsRevoked {
+		blobs = append(blobs, replyBlob{"ReproC", textReproC, crash.ReproC, &rep.ReproC})
+	}
+	rep.BlobRefs = blobRefs(c, ns, blobs)
+	return rep, nil
+}
+
+func apiGetRepro(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.GetReproReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	bug := new(Bug)
+	bugKey := db.NewKey(c, "Bug", req.BugID, 0, nil)
+	if err := db.Get(c, bugKey, bug); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown bug %q", ErrClientNotFound, req.BugID)
+		}
 		return nil, fmt.Errorf("failed to get bug: %w", err)
 	}
 	if bug.Namespace != ns {
-		return nil, fmt.Errorf("no such bug")
+		return nil, fmt.Errorf("%w: no such bug", ErrClientNotFound)
+	}
+	resp := &dashapi.Repro{}
+	if bug.ReproLevel == ReproLevelNone {
+		return resp, nil
+	}
+	crash, _, err := findCrashForBug(c, bug)
+	if err != nil {
+		return nil, err
+	}
+	if crash.ReproSyz == 0 {
+		// We expect the best crash to have a repro (see findCrashForBug),
+		// but report it as a missing repro if it does not.
+		return resp, nil
+	}
+	if resp.Syz, _, err = getText(c, textReproSyz, crash.ReproSyz); err != nil {
+		return nil, err
+	}
+	if resp.C, _, err = getText(c, textReproC, crash.ReproC); err != nil {
+		return nil, err
+	}
+	resp.Level = ReproLevelSyz
+	if len(resp.C) != 0 {
+		resp.Level = ReproLevelC
+	}
+	resp.Opts = crash.ReproOpts
+	resp.Options = reproOptions(crash.ReproOpts)
+	resp.BuildID = crash.BuildID
+	var kernelConfig int64
+	if !req.NoKernelConfig {
+		build, err := loadBuild(c, ns, crash.BuildID)
+		if err != nil {
+			return nil, err
+		}
+		if resp.KernelConfig, _, err = getText(c, textKernelConfig, build.KernelConfig); err != nil {
+			return nil, err
+		}
+		kernelConfig = build.KernelConfig
+	}
+	if req.PreferURLs {
+		resp.BlobRefs = blobRefs(c, ns, []replyBlob{
+			{"Syz", textReproSyz, crash.ReproSyz, &resp.Syz},
+			{"C", textReproC, crash.ReproC, &resp.C},
+			{"KernelConfig", textKernelConfig, kernelConfig, &resp.KernelConfig},
+		})
+	}
+	return resp, nil
+}
+
+func apiAddBugNote(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.AddBugNoteReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	title := strings.TrimSpace(limitLength(req.Title, maxTextLen))
+	if title == "" || len(req.Text) == 0 {
+		return nil, fmt.Errorf("%w: the note has no title or text", ErrClientBadRequest)
+	}
+	bugKey := db.NewKey(c, "Bug", req.BugID, 0, nil)
+	note := BugNote{
+		Time:   timeNow(c),
+		Author: credential(r, dashapi.ClientHeader, "client"),
+		Title:  title,
+	}
+	tx := func(c context.Context) error {
+		bug := new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			if err == db.ErrNoSuchEntity {
+				return fmt.Errorf("%w: unknown bug %q", ErrClientNotFound, req.BugID)
+			}
+			return fmt.Errorf("failed to get bug: %w", err)
+		}
+		if bug.Namespace != ns {
+			return fmt.Errorf("%w: unknown bug %q", ErrClientNotFound, req.BugID)
+		}
+		if len(bug.Notes) >= dashapi.MaxBugNotes {
+			return fmt.Errorf("%w: the bug already has %v notes", ErrClientBadRequest, len(bug.Notes))
+		}
+		var err error
+		if note.Text, err = putText(c, ns, textBugNote, req.Text); err != nil {
+			return err
+		}
+		bug.Notes = append(bug.Notes, note)
+		if _, err := db.Put(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to put bug: %w", err)
+		}
+		return nil
 	}
-	return loadBugReport(c, bug)
+	return nil, db.RunInTransaction(c, tx, &db.TransactionOptions{XG: true, Attempts: 10})
 }
 
 func apiLoadFullBug(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.LoadFullBugReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	bug, bugKey, err := findBugByReportingID(c, req.BugID)
 	if err != nil {
@@ -1300,8 +2283,8 @@ func loadBugReport(c context.Context, bug *Bug) (*dashapi.BugReport, error) {
 
 func apiAddBuildAssets(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.AddBuildAssetsReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	assets := []Asset{}
 	for i, toAdd := range req.Assets {
@@ -1327,10 +2310,17 @@ func parseIncomingAsset(c context.Context, newAsset dashapi.NewAsset) (Asset, er
 	if err != nil {
 		return Asset{}, fmt.Errorf("invalid URL: %w", err)
 	}
+	if newAsset.SHA256 != "" {
+		if hash, err := hex.DecodeString(newAsset.SHA256); err != nil || len(hash) != sha256.Size {
+			return Asset{}, fmt.Errorf("invalid SHA256 %q", newAsset.SHA256)
+		}
+	}
 	return Asset{
 		Type:        newAsset.Type,
 		DownloadURL: newAsset.DownloadURL,
 		CreateDate:  timeNow(c),
+		Size:        newAsset.Size,
+		SHA256:      newAsset.SHA256,
 	}, nil
 }
 
@@ -1446,6 +2436,51 @@ func findBugForCrash(c context.Context, ns string, titles []string) (*Bug, error
 	return best, nil
 }
 
+// Crashes of some bugs have wildly different stacks, so the number of signatures
+// per bug is limited to keep the bug entity small.
+func checkCrashAttachments(attachments []dashapi.CrashAttachment) error {
+	if len(attachments) > dashapi.MaxCrashAttachments {
+		return fmt.Errorf("%w: too many crash attachments: %v, max %v",
+			ErrClientBadRequest, len(attachments), dashapi.MaxCrashAttachments)
+	}
+	names := make(map[string]bool)
+	for _, att := range attachments {
+		if att.Name == "" || len(att.Name) > MaxStringLen || len(att.MIMEType) > MaxStringLen || names[att.Name] {
+			return fmt.Errorf("%w: bad crash attachment %q %q", ErrClientBadRequest, att.Name, att.MIMEType)
+		}
+		names[att.Name] = true
+	}
+	return nil
+}
+
+const maxBugSignatures = 20
+
+// findBugForSignature returns the active bug that already has crashes with the signature.
+func findBugForSignature(c context.Context, ns, signature string) (*Bug, error) {
+	var bugs []*Bug
+	_, err := db.NewQuery("Bug").
+		Filter("Namespace=", ns).
+		Filter("Signatures=", signature).
+		GetAll(c, &bugs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bugs: %w", err)
+	}
+	sort.Slice(bugs, func(i, j int) bool {
+		if bugs[i].Title != bugs[j].Title {
+			return bugs[i].Title < bugs[j].Title
+		}
+		return bugs[i].Seq > bugs[j].Seq
+	})
+	for _, bug := range bugs {
+		if active, err := isActiveBug(c, bug); err != nil {
+			return nil, err
+		} else if active {
+			return bug, nil
+		}
+	}
+	return nil, nil
+}
+
 func createBugForCrash(c context.Context, ns string, req *dashapi.Crash) (*Bug, error) {
 	var bug *Bug
 	now := timeNow(c)
@@ -1465,6 +2500,7 @@ func createBugForCrash(c context.Context, ns string, req *dashapi.Crash) (*Bug,
 					MergedTitles:   []string{req.Title},
 					AltTitles:      req.AltTitles,
 					Status:         BugStatusOpen,
+					Kind:           req.Kind,
 					NumCrashes:     0,
 					NumRepro:       0,
 					ReproLevel:     ReproLevelNone,
@@ -1563,6 +2599,7 @@ func putText(c context.Context, ns, tag string, data []byte) (int64, error) {
 	if len(data) == 0 {
 		return 0, nil
 	}
+	orig := data
 	const (
 		// Kernel crash log is capped at ~1MB, but vm.Diagnose can add more.
 		// These text files usually compress very well.
@@ -1601,6 +2638,7 @@ func putText(c context.Context, ns, tag string, data []byte) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	saveBlobHash(c, ns, tag, key.IntID(), orig)
 	return key.IntID(), nil
 }
 
@@ -1652,9 +2690,22 @@ func GetEmails(r dashapi.Recipients, filter dashapi.RecipientType) []string {
 	return emails
 }
 
-// Verifies that the given credentials are acceptable and returns the
-// corresponding namespace.
-func checkClient(conf *GlobalConfig, name0, secretPassword, oauthSubject string) (string, error) {
+// checkRetired fails requests from managers that should not be reporting anymore.
+func checkRetired(c context.Context, ns, manager string) error {
+	cfg := getNsConfig(c, ns)
+	if cfg.Decommissioned {
+		return fmt.Errorf("%w: namespace %v is decommissioned", ErrClientRetired, ns)
+	}
+	if cfg.Managers[manager].Decommissioned {
+		return fmt.Errorf("%w: manager %v is decommissioned", ErrClientRetired, manager)
+	}
+	return nil
+}
+
+// checkClient authenticates the client and returns its namespace ("" for global clients).
+// Clients that are configured in several namespaces need to name the namespace in ns0.
+// Only the clients of the namespace may name it, global clients are rejected if ns0 is set.
+func checkClient(conf *GlobalConfig, name0, ns0, secretPassword, oauthSubject string) (string, error) {
 	checkAuth := func(ns, a string) (string, error) {
 		if strings.HasPrefix(a, auth.OauthMagic) &&
 			subtle.ConstantTimeCompare([]byte(a), []byte(oauthSubject)) == 1 {
@@ -1665,21 +2716,77 @@ func checkClient(conf *GlobalConfig, name0, secretPassword, oauthSubject string)
 		}
 		return ns, nil
 	}
+	if ns0 != "" && conf.Namespaces[ns0] == nil {
+		return "", fmt.Errorf("%w: unknown namespace %q", ErrAccess, ns0)
+	}
+	if ns0 != "" {
+		if authenticator, ok := conf.Namespaces[ns0].clientKey(name0); ok {
+			return checkAuth(ns0, authenticator)
+		}
+		return "", ErrAccess
+	}
 	for name, authenticator := range conf.Clients {
 		if name == name0 {
 			return checkAuth("", authenticator)
 		}
 	}
+	var namespaces []string
 	for ns, cfg := range conf.Namespaces {
-		for name, authenticator := range cfg.Clients {
-			if name == name0 {
-				return checkAuth(ns, authenticator)
-			}
+		if _, ok := cfg.clientKey(name0); ok {
+			namespaces = append(namespaces, ns)
 		}
 	}
+	if len(namespaces) > 1 {
+		sort.Strings(namespaces)
+		return "", fmt.Errorf("%w: client is configured in namespaces %v, the namespace must be specified",
+			ErrAccess, strings.Join(namespaces, ", "))
+	}
+	if len(namespaces) == 1 {
+		authenticator, _ := conf.Namespaces[namespaces[0]].clientKey(name0)
+		return checkAuth(namespaces[0], authenticator)
+	}
 	return "", ErrAccess
 }
 
+// checkSignature verifies a request signature (see dashapi.SignRequests) and returns the client key.
+func checkSignature(c context.Context, client, ns, method, header string, body []byte) (string, error) {
+	key := clientKey(getConfig(c), client, ns)
+	if key == "" || strings.HasPrefix(key, auth.OauthMagic) {
+		return "", ErrAccess
+	}
+	if err := dashapi.CheckSignature(header, key, method, timeNow(c), body); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAccess, err)
+	}
+	// Remember the signature until it expires to reject replayed requests.
+	err := memcache.Add(c, &memcache.Item{
+		Key:        "signature-" + header,
+		Value:      []byte{1},
+		Expiration: 2 * dashapi.MaxSignatureAge,
+	})
+	if err == memcache.ErrNotStored {
+		return "", fmt.Errorf("%w: replayed request", ErrAccess)
+	} else if err != nil {
+		log.Errorf(c, "failed to remember request signature: %v", err)
+	}
+	return key, nil
+}
+
+func clientKey(conf *GlobalConfig, name, ns string) string {
+	if key, ok := conf.Clients[name]; ok {
+		return key
+	}
+	if cfg := conf.Namespaces[ns]; cfg != nil {
+		key, _ := cfg.clientKey(name)
+		return key
+	}
+	for _, cfg := range conf.Namespaces {
+		if key, ok := cfg.clientKey(name); ok {
+			return key
+		}
+	}
+	return ""
+}
+
 func handleRefreshSubsystems(w http.ResponseWriter, r *http.Request) {
 	c := appengine.NewContext(r)
 	const updateBugsCount = 25
@@ -1693,8 +2800,8 @@ func handleRefreshSubsystems(w http.ResponseWriter, r *http.Request) {
 
 func apiSaveDiscussion(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.SaveDiscussionReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	d := req.Discussion
 	newBugIDs := []string{}
@@ -1736,8 +2843,8 @@ func recordEmergencyStop(c context.Context) error {
 // in the place of syz-hub.
 func apiLogToReproduce(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.LogToReproReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	build, err := loadBuild(c, ns, req.BuildID)
 	if err != nil {
@@ -1858,10 +2965,369 @@ func takeReproTask(c context.Context, ns, manager string) ([]byte, error) {
 	return log, err
 }
 
+const (
+	reproTaskLease = 3 * time.Hour
+	// Let's limit the load on the DB.
+	reproTaskCandidates = 10
+)
+
+func apiReproTaskPoll(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReproTaskPollReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	resp := new(dashapi.ReproTaskPollResp)
+	if stop, err := emergentlyStopped(c); err != nil || stop {
+		return resp, err
+	}
+	bugs, err := loadReproTaskCandidates(c, ns, req.Manager)
+	if err != nil {
+		return nil, err
+	}
+	leased, err := activeReproLeases(c, ns)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*Bug
+	for _, bug := range bugs {
+		if !leased[bug.keyHash(c)] && crashNeedsRepro(bug.Title) && needRepro(c, bug) {
+			candidates = append(candidates, bug)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return reproTaskPriority(candidates[i]) > reproTaskPriority(candidates[j])
+	})
+	for i, bug := range candidates {
+		if i >= reproTaskCandidates {
+			break
+		}
+		logResp, err := logToReproForBug(c, bug, req.Manager)
+		if err != nil {
+			return nil, err
+		}
+		if logResp == nil || len(logResp.CrashLog) == 0 {
+			continue
+		}
+		task, err := leaseReproTask(c, bug, req.Manager)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			// Somebody has leased the bug concurrently.
+			continue
+		}
+		task.CrashLog = logResp.CrashLog
+		resp.Task = task
+		break
+	}
+	return resp, nil
+}
+
+// loadReproTaskCandidates queries only the open bugs of the manager that may need a repro
+// (see needReproForBug): the ones without a C repro and the ones that were not reproduced
+// for reproStalePeriod.
+func loadReproTaskCandidates(c context.Context, ns, manager string) ([]*Bug, error) {
+	filter := func(query *db.Query) *db.Query {
+		return query.Filter("Namespace=", ns).
+			Filter("HappenedOn=", manager).
+			Filter("Status=", BugStatusOpen)
+	}
+	bugs, keys, err := loadAllBugs(c, func(query *db.Query) *db.Query {
+		return filter(query).Filter("HeadReproLevel<", ReproLevelC)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bugs: %w", err)
+	}
+	stale, staleKeys, err := loadAllBugs(c, func(query *db.Query) *db.Query {
+		return filter(query).Filter("LastReproTime<", timeNow(c).Add(-reproStalePeriod))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bugs: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		seen[key.StringID()] = true
+	}
+	for i, bug := range stale {
+		if !seen[staleKeys[i].StringID()] {
+			bugs = append(bugs, bug)
+		}
+	}
+	return bugs, nil
+}
+
+// reproTaskPriority prefers bugs without any reproducer and bugs that happen more often.
+func reproTaskPriority(bug *Bug) int {
+	prio := int(min(bug.NumCrashes, 1000))
+	if bug.HeadReproLevel == ReproLevelNone {
+		prio += 1000
+	}
+	return prio
+}
+
+func activeReproLeases(c context.Context, ns string) (map[string]bool, error) {
+	var leases []*ReproLease
+	keys, err := db.NewQuery("ReproLease").
+		Filter("Namespace=", ns).
+		Filter("Done=", false).
+		GetAll(c, &leases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repro leases: %w", err)
+	}
+	now := timeNow(c)
+	res := make(map[string]bool)
+	for i, lease := range leases {
+		if now.Before(lease.Deadline) {
+			res[keys[i].StringID()] = true
+		}
+	}
+	return res, nil
+}
+
+func leaseReproTask(c context.Context, bug *Bug, manager string) (*dashapi.ReproTask, error) {
+	now := timeNow(c)
+	bugHash := bug.keyHash(c)
+	task := &dashapi.ReproTask{
+		TaskID:   fmt.Sprintf("%v-%v", bugHash, now.UnixNano()),
+		Title:    bug.Title,
+		Priority: reproTaskPriority(bug),
+		Deadline: now.Add(reproTaskLease),
+	}
+	tx := func(c context.Context) error {
+		key := db.NewKey(c, "ReproLease", bugHash, 0, nil)
+		lease := new(ReproLease)
+		if err := db.Get(c, key, lease); err != nil && err != db.ErrNoSuchEntity {
+			return fmt.Errorf("failed to get repro lease: %w", err)
+		}
+		if !lease.Done && now.Before(lease.Deadline) {
+			task = nil
+			return nil
+		}
+		lease = &ReproLease{
+			Namespace: bug.Namespace,
+			Manager:   manager,
+			TaskID:    task.TaskID,
+			Deadline:  task.Deadline,
+		}
+		if _, err := db.Put(c, key, lease); err != nil {
+			return fmt.Errorf("failed to put repro lease: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10}); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func apiReproTaskDone(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReproTaskDoneReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	res := req.Result
+	if res == nil {
+		return nil, fmt.Errorf("%w: no result", ErrClientBadRequest)
+	}
+	bugHash, _, _ := strings.Cut(req.TaskID, "-")
+	leaseKey := db.NewKey(c, "ReproLease", bugHash, 0, nil)
+	lease := new(ReproLease)
+	if err := db.Get(c, leaseKey, lease); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown repro task %q", ErrClientNotFound, req.TaskID)
+		}
+		return nil, fmt.Errorf("failed to get repro lease: %w", err)
+	}
+	if lease.Namespace != ns {
+		return nil, fmt.Errorf("%w: unknown repro task %q", ErrClientNotFound, req.TaskID)
+	}
+	// Only the manager the bug was leased to may report the results, this is checked before
+	// the crash or the repro log is saved.
+	if lease.Manager != req.Manager {
+		return nil, fmt.Errorf("%w: repro task %q is leased by another manager", ErrClientForbidden, req.TaskID)
+	}
+	// Stale results are rejected before anything is saved.
+	if lease.TaskID != req.TaskID {
+		// The lease has expired and the bug was given to another manager.
+		return nil, fmt.Errorf("%w: repro task %q has expired", ErrClientNotFound, req.TaskID)
+	}
+	if lease.Done {
+		// The result was already reported (e.g. the request was retried).
+		return nil, nil
+	}
+	switch res.Status {
+	case dashapi.ReproTaskSucceeded:
+		if res.Crash == nil {
+			return nil, fmt.Errorf("%w: no crash for a successful repro", ErrClientBadRequest)
+		}
+		build, err := loadBuild(c, ns, res.Crash.BuildID)
+		if err != nil {
+			return nil, err
+		}
+		if getNsConfig(c, ns).TransformCrash(build, res.Crash) {
+			if _, _, err := reportCrash(c, build, res.Crash); err != nil {
+				return nil, err
+			}
+		}
+	case dashapi.ReproTaskFailed:
+		bug := new(Bug)
+		if err := db.Get(c, db.NewKey(c, "Bug", bugHash, 0, nil), bug); err != nil {
+			return nil, fmt.Errorf("failed to get bug: %w", err)
+		}
+		build, err := loadBuild(c, ns, res.BuildID)
+		if err != nil {
+			return nil, err
+		}
+		if err := saveFailedReproLog(c, bug, build, res.ReproLog, nil); err != nil {
+			return nil, err
+		}
+	case dashapi.ReproTaskAbandoned:
+	default:
+		return nil, fmt.Errorf("%w: unknown repro task status %q", ErrClientBadRequest, res.Status)
+	}
+	tx := func(c context.Context) error {
+		lease := new(ReproLease)
+		if err := db.Get(c, leaseKey, lease); err != nil {
+			return fmt.Errorf("failed to get repro lease: %w", err)
+		}
+		if lease.TaskID != req.TaskID {
+			// The lease has expired and the bug was given to another manager.
+			return nil
+		}
+		lease.Done = true
+		lease.Status = string(res.Status)
+		lease.Reasons = res.Reasons
+		if _, err := db.Put(c, leaseKey, lease); err != nil {
+			return fmt.Errorf("failed to put repro lease: %w", err)
+		}
+		return nil
+	}
+	return nil, db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10})
+}
+
+func apiReportToolBug(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ToolBugReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	title := normalizeCrashTitle(req.Title)
+	if title == "" || req.Component == "" {
+		return nil, fmt.Errorf("%w: empty tool bug title or component", ErrClientBadRequest)
+	}
+	if len(req.Component) > MaxStringLen || len(req.SyzkallerCommit) > MaxStringLen {
+		return nil, fmt.Errorf("%w: too long tool bug component or commit", ErrClientTooLarge)
+	}
+	now := timeNow(c)
+	key := db.NewKey(c, "ToolBug", toolBugKeyHash(ns, req.Component, title, req.SyzkallerCommit), 0, nil)
+	bug := new(ToolBug)
+	if err := db.Get(c, key, bug); err != nil && err != db.ErrNoSuchEntity {
+		return nil, fmt.Errorf("failed to get tool bug: %w", err)
+	}
+	var logID, reportID int64
+	if bug.Title == "" {
+		// Store the texts only for the first occurrence, they are not too useful for the rest.
+		var err error
+		if logID, err = putText(c, ns, textCrashLog, req.Log); err != nil {
+			return nil, err
+		}
+		if reportID, err = putText(c, ns, textCrashReport, req.Report); err != nil {
+			return nil, err
+		}
+	}
+	tx := func(c context.Context) error {
+		bug := new(ToolBug)
+		if err := db.Get(c, key, bug); err != nil {
+			if err != db.ErrNoSuchEntity {
+				return fmt.Errorf("failed to get tool bug: %w", err)
+			}
+			bug = &ToolBug{
+				Namespace:       ns,
+				Component:       req.Component,
+				Title:           title,
+				SyzkallerCommit: req.SyzkallerCommit,
+				FirstTime:       now,
+				Log:             logID,
+				Report:          reportID,
+			}
+		}
+		bug.NumOccurrences += int64(max(req.Count, 1))
+		bug.LastTime = now
+		if _, err := db.Put(c, key, bug); err != nil {
+			return fmt.Errorf("failed to put tool bug: %w", err)
+		}
+		return nil
+	}
+	return nil, db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10})
+}
+
+func apiUploadCoverage(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UploadCoverageReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" || len(req.Files) == 0 {
+		return nil, fmt.Errorf("%w: no manager or coverage", ErrClientBadRequest)
+	}
+	if err := checkRetired(c, ns, req.Manager); err != nil {
+		return nil, err
+	}
+	now := timeNow(c)
+	cov := &ManagerCoverage{
+		Namespace:    ns,
+		Manager:      req.Manager,
+		Date:         timeDate(now),
+		Time:         now,
+		BuildID:      req.BuildID,
+		KernelRepo:   req.KernelRepo,
+		KernelBranch: req.KernelBranch,
+		KernelCommit: req.KernelCommit,
+	}
+	for file, fileCov := range req.Files {
+		if fileCov == nil || fileCov.Covered < 0 || fileCov.Covered > fileCov.Instrumented {
+			return nil, fmt.Errorf("%w: bad coverage of %v", ErrClientBadRequest, file)
+		}
+		cov.Instrumented += fileCov.Instrumented
+		cov.Covered += fileCov.Covered
+	}
+	files, err := json.Marshal(req.Files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal coverage: %w", err)
+	}
+	if cov.Files, err = putText(c, ns, textCoverage, files); err != nil {
+		return nil, err
+	}
+	key := db.NewKey(c, "ManagerCoverage", "", int64(cov.Date), mgrKey(c, ns, req.Manager))
+	// The day's previous upload is replaced, so its per-file coverage is not needed anymore.
+	var prevFiles int64
+	tx := func(c context.Context) error {
+		prev := new(ManagerCoverage)
+		if err := db.Get(c, key, prev); err == nil {
+			prevFiles = prev.Files
+		} else if err != db.ErrNoSuchEntity {
+			return fmt.Errorf("failed to get manager coverage: %w", err)
+		}
+		if _, err := db.Put(c, key, cov); err != nil {
+			return fmt.Errorf("failed to put manager coverage: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		db.Delete(c, db.NewKey(c, textCoverage, "", cov.Files, nil))
+		return nil, err
+	}
+	if prevFiles != 0 {
+		if err := db.Delete(c, db.NewKey(c, textCoverage, "", prevFiles, nil)); err != nil {
+			log.Errorf(c, "failed to delete previous coverage: %v", err)
+		}
+	}
+	log.Infof(c, "coverage on %v: %v/%v lines", req.Manager, cov.Covered, cov.Instrumented)
+	return nil, nil
+}
+
 func apiSaveCoverage(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.SaveCoverageReq)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	coverage := req.Coverage
 	var sss []*subsystem.Subsystem
@@ -4,15 +4,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/coveragedb"
 	"github.com/google/syzkaller/sys/targets"
 	"github.com/stretchr/testify/assert"
+	db "google.golang.org/appengine/v2/datastore"
 )
 
 func TestClientSecretOK(t *testing.T) {
@@ -20,7 +30,7 @@ func TestClientSecretOK(t *testing.T) {
 		Clients: map[string]string{
 			"user": "secr1t",
 		},
-	}, "user", "secr1t", "")
+	}, "user", "", "secr1t", "")
 	if err != nil || got != "" {
 		t.Errorf("unexpected error %v %v", got, err)
 	}
@@ -31,7 +41,7 @@ func TestClientOauthOK(t *testing.T) {
 		Clients: map[string]string{
 			"user": "OauthSubject:public",
 		},
-	}, "user", "", "OauthSubject:public")
+	}, "user", "", "", "OauthSubject:public")
 	if err != nil || got != "" {
 		t.Errorf("unexpected error %v %v", got, err)
 	}
@@ -42,7 +52,7 @@ func TestClientSecretFail(t *testing.T) {
 		Clients: map[string]string{
 			"user": "secr1t",
 		},
-	}, "user", "wrong", "")
+	}, "user", "", "wrong", "")
 	if err != ErrAccess || got != "" {
 		t.Errorf("unexpected error %v %v", got, err)
 	}
@@ -51,7 +61,7 @@ func TestClientSecretFail(t *testing.T) {
 func TestClientSecretMissing(t *testing.T) {
 	got, err := checkClient(&GlobalConfig{
 		Clients: map[string]string{},
-	}, "user", "ignored", "")
+	}, "user", "", "ignored", "")
 	if err != ErrAccess || got != "" {
 		t.Errorf("unexpected error %v %v", got, err)
 	}
@@ -66,22 +76,89 @@ func TestClientNamespaceOK(t *testing.T) {
 				},
 			},
 		},
-	}, "user", "secr1t", "")
+	}, "user", "", "secr1t", "")
 	if err != nil || got != "ns1" {
 		t.Errorf("unexpected error %v %v", got, err)
 	}
 }
 
+func TestClientSeveralNamespaces(t *testing.T) {
+	conf := &GlobalConfig{
+		Clients: map[string]string{
+			"global": "secr1t",
+		},
+		Namespaces: map[string]*Config{
+			"ns1": {
+				Clients: map[string]string{
+					"user": "secr1t",
+				},
+			},
+			"ns2": {
+				Clients: map[string]string{
+					"user": "secr2t",
+				},
+			},
+		},
+	}
+	got, err := checkClient(conf, "user", "ns2", "secr2t", "")
+	if err != nil || got != "ns2" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+	_, err = checkClient(conf, "user", "ns2", "secr1t", "")
+	if err != ErrAccess {
+		t.Errorf("unexpected error %v", err)
+	}
+	got, err = checkClient(conf, "user", "", "secr1t", "")
+	if !errors.Is(err, ErrAccess) || !strings.Contains(fmt.Sprint(err), "ns1, ns2") || got != "" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+	got, err = checkClient(conf, "user", "ns3", "secr1t", "")
+	if !errors.Is(err, ErrAccess) || got != "" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+	// Global clients can't pick a namespace.
+	got, err = checkClient(conf, "global", "ns1", "secr1t", "")
+	if err != ErrAccess || got != "" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+	got, err = checkClient(conf, "global", "", "secr1t", "")
+	if err != nil || got != "" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+}
+
+func TestClientReadOnly(t *testing.T) {
+	conf := &GlobalConfig{
+		Namespaces: map[string]*Config{
+			"ns1": {
+				ReadOnlyClients: map[string]string{
+					"reader": "secr1t",
+				},
+			},
+		},
+	}
+	got, err := checkClient(conf, "reader", "", "secr1t", "")
+	if err != nil || got != "ns1" {
+		t.Errorf("unexpected error %v %v", got, err)
+	}
+	if _, err := checkClient(conf, "reader", "ns1", "wrong", ""); err != ErrAccess {
+		t.Errorf("unexpected error %v", err)
+	}
+	if key := clientKey(conf, "reader", ""); key != "secr1t" {
+		t.Errorf("unexpected key %q", key)
+	}
+}
+
 func TestEmergentlyStoppedEmail(t *testing.T) {
 	c := NewCtx(t)
 	defer c.Close()
 
 	client := c.publicClient
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	c.advanceTime(time.Hour)
 	_, err := c.AuthGET(AccessAdmin, "/admin?action=emergency_stop")
@@ -98,16 +175,16 @@ func TestEmergentlyStoppedReproEmail(t *testing.T) {
 
 	client := c.publicClient
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.pollEmailBug()
 
 	crash2 := testCrash(build, 1)
 	crash2.ReproOpts = []byte("repro opts")
 	crash2.ReproSyz = []byte("getpid()")
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 
 	c.advanceTime(time.Hour)
 	_, err := c.AuthGET(AccessAdmin, "/admin?action=emergency_stop")
@@ -124,10 +201,10 @@ func TestEmergentlyStoppedExternalReport(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	c.advanceTime(time.Hour)
 	_, err := c.AuthGET(AccessAdmin, "/admin?action=emergency_stop")
@@ -144,12 +221,12 @@ func TestEmergentlyStoppedEmailJob(t *testing.T) {
 
 	client := c.publicClient
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("getpid()")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	sender := c.pollEmailBug().Sender
 	c.incomingEmail(sender, "#syz upstream\n")
 	sender = c.pollEmailBug().Sender
@@ -173,7 +250,7 @@ func TestEmergentlyStoppedEmailJob(t *testing.T) {
 		CrashLog:    []byte("test crash log"),
 		CrashReport: []byte("test crash report"),
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 
 	// Now we emergently stop syzbot.
 	c.advanceTime(time.Hour)
@@ -191,7 +268,7 @@ func TestEmergentlyStoppedCrashReport(t *testing.T) {
 
 	client := c.publicClient
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Now we emergently stop syzbot.
 	c.advanceTime(time.Hour)
@@ -201,9 +278,9 @@ func TestEmergentlyStoppedCrashReport(t *testing.T) {
 	crash := testCrash(build, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("getpid()")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
-	listResp, err := client.BugList()
+	listResp, err := client.BugList(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(listResp.List), 0)
 }
@@ -302,3 +379,1030 @@ func TestUpdateReportingPriority(t *testing.T) {
 	// "0-3", "4-5" have the same priority (repro revoked as no repro).
 	assert.Equal(t, len(slices.Compact(prios)), len(prios)-4)
 }
+
+func TestCountCrash(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.publicClient
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+
+	// There's no bug yet.
+	resp, err := client.CountCrash(context.Background(), testCrashID(crash))
+	c.expectOK(err)
+	c.expectEQ(resp.Found, false)
+
+	reportResp, err := client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	c.expectNE(reportResp.CrashID, int64(0))
+
+	resp, err = client.CountCrash(context.Background(), testCrashID(crash))
+	c.expectOK(err)
+	c.expectEQ(resp.Found, true)
+	c.expectEQ(resp.NeedRepro, true)
+
+	bug, _ := c.loadSingleBug()
+	c.expectEQ(bug.NumCrashes, int64(2))
+}
+
+func TestBugStatus(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.publicClient
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+	client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
+	client.ReportCrash(context.Background(), testCrash(build, 2))
+
+	resp, err := client.BugStatus(context.Background(), []string{"title1", "title2", "title3"})
+	c.expectOK(err)
+	for _, info := range resp.Bugs[:2] {
+		c.expectTrue(strings.Contains(info.Link, "/bug?"))
+		info.Link = ""
+	}
+	c.expectEQ(resp.Bugs, []*dashapi.BugStatusInfo{
+		{
+			Title:      "title1",
+			Found:      true,
+			Active:     true,
+			Status:     dashapi.BugStatusOpen,
+			ReproLevel: dashapi.ReproLevelC,
+		},
+		{
+			Title:  "title2",
+			Found:  true,
+			Active: true,
+			Status: dashapi.BugStatusOpen,
+		},
+		{
+			Title: "title3",
+		},
+	})
+}
+
+func TestBugStatusLinks(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	rep := c.client.pollBug()
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:     rep.ID,
+		Status: dashapi.BugStatusOpen,
+		Link:   "https://lore.kernel.org/bug",
+	})
+
+	info, err := c.client.BugStatusByTitle(context.Background(), rep.Title)
+	c.expectOK(err)
+	c.expectTrue(info.Found)
+	c.expectEQ(info.Link, rep.Link)
+	c.expectEQ(info.ExtLink, "https://lore.kernel.org/bug")
+
+	info, err = c.client.BugStatusByTitle(context.Background(), "unknown title")
+	c.expectOK(err)
+	c.expectTrue(!info.Found)
+}
+
+func TestOpenBugs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	rep := c.client.pollBug()
+	c.client.updateBug(rep.ID, dashapi.BugStatusInvalid, "")
+	c.client.ReportCrash(context.Background(), testCrashWithRepro(build, 3))
+	c.client.ReportCrash(context.Background(), testCrash(build, 2))
+
+	resp, err := c.client.OpenBugs(context.Background())
+	c.expectOK(err)
+	listResp, err := c.client.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(resp.Bugs), 2)
+	for _, bug := range resp.Bugs {
+		c.expectTrue(slices.Contains(listResp.List, bug.ID))
+		bug.ID = ""
+	}
+	c.expectEQ(resp.Bugs, []*dashapi.OpenBug{
+		{
+			Title:     "title2",
+			Namespace: "test1",
+		},
+		{
+			Title:      "title3",
+			Namespace:  "test1",
+			ReproLevel: dashapi.ReproLevelC,
+		},
+	})
+
+	// Bugs of other namespaces are not returned.
+	resp, err = c.client2.OpenBugs(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(resp.Bugs), 0)
+}
+
+func TestManagerConfig(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+	client := c.makeClient(clientManagerConfig, keyManagerConfig, true)
+
+	// Namespace-wide overrides.
+	resp, err := client.PollManagerConfig(context.Background(), "some-manager", "")
+	c.expectOK(err)
+	c.expectNE(resp.Version, "")
+	version := resp.Version
+	resp.Version = ""
+	c.expectEQ(resp, &dashapi.ManagerConfigResp{
+		DisabledSyscalls: []string{},
+		SuppressedTitles: []string{"^INFO: task hung"},
+		MaxReproAttempts: 2,
+	})
+	resp, err = client.PollManagerConfig(context.Background(), "some-manager", version)
+	c.expectOK(err)
+	c.expectEQ(resp, &dashapi.ManagerConfigResp{Version: version, Unchanged: true})
+
+	// Manager overrides are combined with the namespace ones.
+	resp, err = client.PollManagerConfig(context.Background(), overridesManager, version)
+	c.expectOK(err)
+	c.expectNE(resp.Version, version)
+	resp.Version = ""
+	c.expectEQ(resp, &dashapi.ManagerConfigResp{
+		DisabledSyscalls: []string{"io_uring_setup"},
+		SuppressedTitles: []string{"^INFO: task hung"},
+		MaxReproAttempts: 5,
+		ReportingPaused:  true,
+	})
+
+	// The applied version is reported back with the stats.
+	c.expectOK(client.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
+		Name:          "some-manager",
+		ConfigVersion: version,
+		VMs:           3,
+		TotalVMs:      4,
+	}))
+	mgr, err := loadManager(c.ctx, "manager-config", "some-manager")
+	c.expectOK(err)
+	c.expectEQ(mgr.ConfigVersion, version)
+	c.expectEQ(mgr.CurrentVMs, int64(3))
+	c.expectEQ(mgr.TotalVMs, int64(4))
+}
+
+func TestReposPoll(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	resp, err := c.client2.ReposPoll(context.Background())
+	c.expectOK(err)
+	c.expectNE(resp.Version, "")
+	c.expectEQ(resp.Repos, []*dashapi.KernelTree{
+		{
+			Alias:  "repo10alias",
+			URL:    "git://syzkaller.org",
+			Branch: "branch10",
+		},
+		{
+			Alias:  "repo20",
+			URL:    "git://syzkaller.org",
+			Branch: "branch20",
+			OS:     targets.Linux,
+			Arch:   targets.AMD64,
+		},
+	})
+
+	// The list did not change, so the cached reply is returned.
+	resp1, err := c.client2.ReposPoll(context.Background())
+	c.expectOK(err)
+	c.expectEQ(resp1, resp)
+
+	// The method is available to read-only clients.
+	resp, err = c.makeClient(clientReadOnly, keyReadOnly, true).ReposPoll(context.Background())
+	c.expectOK(err)
+	c.expectNE(resp.Version, "")
+}
+
+func TestReportToolBug(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	req := &dashapi.ToolBugReq{
+		Component:       "executor",
+		Title:           "SYZFAIL: failed to recv rpc",
+		SyzkallerCommit: "commit1",
+		Log:             []byte("log"),
+		Report:          []byte("report"),
+		Count:           100,
+	}
+	c.expectOK(c.client.Query(context.Background(), "report_tool_bug", req, nil))
+	c.advanceTime(time.Hour)
+	req.Count = 0
+	c.expectOK(c.client.Query(context.Background(), "report_tool_bug", req, nil))
+	req.SyzkallerCommit = "commit2"
+	c.expectOK(c.client.Query(context.Background(), "report_tool_bug", req, nil))
+
+	var bugs []*ToolBug
+	_, err := db.NewQuery("ToolBug").Order("SyzkallerCommit").GetAll(c.ctx, &bugs)
+	c.expectOK(err)
+	c.expectEQ(len(bugs), 2)
+	c.expectEQ(bugs[0].NumOccurrences, int64(101))
+	c.expectEQ(bugs[0].LastTime.Sub(bugs[0].FirstTime), time.Hour)
+	c.expectEQ(bugs[1].NumOccurrences, int64(1))
+	log, _, err := getText(c.ctx, textCrashLog, bugs[0].Log)
+	c.expectOK(err)
+	c.expectEQ(log, req.Log)
+
+	// Tool bugs don't show up as kernel bugs.
+	listResp, err := c.client.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(listResp.List), 0)
+}
+
+func TestReportCrashes(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	badCrash := testCrash(build, 3)
+	badCrash.BuildID = "unknown"
+	results, err := c.client.ReportCrashes(context.Background(), []*dashapi.Crash{
+		testCrash(build, 1),
+		badCrash,
+		testCrash(build, 2),
+	})
+	c.expectOK(err)
+	c.expectEQ(len(results), 3)
+	c.expectEQ(results[0].Error, "")
+	c.expectNE(results[0].CrashID, int64(0))
+	c.expectNE(results[1].Error, "")
+	c.expectEQ(results[2].Error, "")
+
+	listResp, err := c.client.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(listResp.List), 2)
+}
+
+func TestChunkedUpload(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	log := bytes.Repeat([]byte("big crash log\n"), 1000)
+	sum := sha256.Sum256(log)
+	req := &dashapi.UploadChunkReq{
+		UploadID: hex.EncodeToString(sum[:]),
+		Size:     int64(len(log)),
+	}
+	for offset := 0; offset < len(log); offset += 5000 {
+		req.Offset = int64(offset)
+		req.Data = log[offset:min(offset+5000, len(log))]
+		resp := new(dashapi.UploadChunkResp)
+		c.expectOK(c.client.Query(context.Background(), "upload_chunk", req, resp))
+		c.expectEQ(resp.Received, int64(offset+len(req.Data)))
+		// Retries of stored chunks are ignored.
+		c.expectOK(c.client.Query(context.Background(), "upload_chunk", req, resp))
+		c.expectEQ(resp.Received, int64(offset+len(req.Data)))
+	}
+
+	crash := testCrash(build, 1)
+	crash.Log = nil
+	crash.ChunkRefs = []dashapi.ChunkRef{{Field: "Log", UploadID: req.UploadID}}
+	c.client.ReportCrash(context.Background(), crash)
+	var crashes []*Crash
+	_, err := db.NewQuery("Crash").GetAll(c.ctx, &crashes)
+	c.expectOK(err)
+	c.expectEQ(len(crashes), 1)
+	got, _, err := getText(c.ctx, textCrashLog, crashes[0].Log)
+	c.expectOK(err)
+	c.expectEQ(got, log)
+
+	// Stale uploads are garbage collected.
+	c.advanceTime(uploadExpiration + time.Hour)
+	_, err = c.GET("/cron/clean_uploads")
+	c.expectOK(err)
+	crash.Title = "title2"
+	client := c.makeClient(client1, password1, false)
+	_, err = client.ReportCrash(context.Background(), crash)
+	c.expectFail("unknown upload", err)
+}
+
+func TestIdempotentReportCrash(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	crash.IdempotencyKey = "key1"
+	resp1, err := c.client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	// The repeated request is not executed again.
+	resp2, err := c.client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	c.expectEQ(resp2, resp1)
+	var crashes []*Crash
+	_, err = db.NewQuery("Crash").GetAll(c.ctx, &crashes)
+	c.expectOK(err)
+	c.expectEQ(len(crashes), 1)
+
+	crash.IdempotencyKey = "key2"
+	_, err = c.client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	n, err := db.NewQuery("Crash").Count(c.ctx)
+	c.expectOK(err)
+	c.expectEQ(n, 2)
+
+	// Requests with the key of a running request are rejected until it finishes or times out.
+	pending := &IdempotentReply{Namespace: "test1", Method: "report_crash", Created: timeNow(c.ctx), Pending: true}
+	_, err = db.Put(c.ctx, db.NewKey(c.ctx, "IdempotentReply", "test1|report_crash|key3", 0, nil), pending)
+	c.expectOK(err)
+	crash.IdempotencyKey = "key3"
+	_, err = c.makeClient(client1, password1, false).ReportCrash(context.Background(), crash)
+	c.expectTrue(dashapi.IsTemporary(err))
+	c.advanceTime(idempotencyPendingTimeout + time.Minute)
+	_, err = c.client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	n, err = db.NewQuery("Crash").Count(c.ctx)
+	c.expectOK(err)
+	c.expectEQ(n, 3)
+
+	// Old replies are garbage collected.
+	c.advanceTime(idempotencyExpiration + time.Hour)
+	_, err = c.GET("/cron/clean_idempotency")
+	c.expectOK(err)
+	n, err = db.NewQuery("IdempotentReply").Count(c.ctx)
+	c.expectOK(err)
+	c.expectEQ(n, 0)
+}
+
+func TestErrorKinds(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.makeClient(client1, "wrongkey", false)
+	err := client.UploadBuild(context.Background(), testBuild(1))
+	c.expectTrue(errors.Is(err, dashapi.ErrAccessDenied))
+
+	client = c.makeClient(client1, password1, false)
+	build := testBuild(1)
+	build.KernelCommit = strings.Repeat("x", MaxStringLen+1)
+	err = client.UploadBuild(context.Background(), build)
+	c.expectTrue(errors.Is(err, dashapi.ErrTooLarge))
+
+	c.decommission("test1")
+	err = client.UploadBuild(context.Background(), testBuild(2))
+	c.expectTrue(errors.Is(err, dashapi.ErrClientRetired))
+}
+
+func TestGlobalClientNamespace(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	// Global clients can't call namespace methods by naming a namespace.
+	dash, err := dashapi.New("reporting", "", "reportingkeyreportingkeyreportingkey", &testTransport{c},
+		dashapi.Namespace("test1"), dashapi.RetryPolicy{}, dashapi.RateLimits{}, dashapi.RequestTimeout(0))
+	c.expectOK(err)
+	err = dash.UploadBuild(context.Background(), testBuild(1))
+	c.expectTrue(errors.Is(err, dashapi.ErrAccessDenied))
+
+	// The namespace clients can.
+	dash, err = dashapi.New(client1, "", password1, &testTransport{c},
+		dashapi.Namespace("test1"), dashapi.RetryPolicy{}, dashapi.RateLimits{}, dashapi.RequestTimeout(0))
+	c.expectOK(err)
+	c.expectOK(dash.UploadBuild(context.Background(), testBuild(1)))
+}
+
+func TestCapabilities(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	caps, err := c.client.Capabilities(context.Background())
+	c.expectOK(err)
+	c.expectEQ(caps.Version, dashapi.APIVersion)
+	c.expectTrue(caps.Supports(dashapi.FeatureZstd))
+	c.expectTrue(caps.HasMethod("report_crashes"))
+	c.expectTrue(caps.HasMethod("capabilities"))
+	c.expectTrue(!caps.HasMethod("no_such_method"))
+}
+
+func TestRequestSignature(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	body := []byte("body")
+	sig := dashapi.Signature(password1, "report_crash", timeNow(c.ctx), body)
+	key, err := checkSignature(c.ctx, client1, "", "report_crash", sig, body)
+	c.expectOK(err)
+	c.expectEQ(key, password1)
+	_, err = checkSignature(c.ctx, client1, "", "report_crash", sig, body)
+	c.expectFail("replayed request", err)
+
+	sig = dashapi.Signature(password1, "report_crash", timeNow(c.ctx), body)
+	_, err = checkSignature(c.ctx, client2, "", "report_crash", sig, body)
+	c.expectFail("wrong signature", err)
+	_, err = checkSignature(c.ctx, client1, "", "upload_build", sig, body)
+	c.expectFail("wrong signature", err)
+	_, err = checkSignature(c.ctx, client1, "", "report_crash", sig, []byte("other body"))
+	c.expectFail("wrong signature", err)
+
+	c.advanceTime(dashapi.MaxSignatureAge + time.Minute)
+	_, err = checkSignature(c.ctx, client1, "", "report_crash", sig, body)
+	c.expectFail("expired", err)
+}
+
+func TestBlobDedup(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	dash, err := dashapi.New(client1, "", password1, &testTransport{c}, dashapi.BlobDedup{},
+		dashapi.RetryPolicy{}, dashapi.RateLimits{}, dashapi.RequestTimeout(0))
+	c.expectOK(err)
+	config := bytes.Repeat([]byte("CONFIG_KASAN=y\n"), 10000)
+	sum := sha256.Sum256(config)
+	hash := hex.EncodeToString(sum[:])
+	known, err := dash.HasBlobs(c.ctx, []string{hash})
+	c.expectOK(err)
+	c.expectEQ(len(known), 0)
+
+	build1 := testBuild(1)
+	build1.KernelConfig = config
+	c.expectOK(dash.UploadBuild(c.ctx, build1))
+	known, err = dash.HasBlobs(c.ctx, []string{hash, "unknown"})
+	c.expectOK(err)
+	c.expectEQ(known, []string{hash})
+
+	// The second build refers to the config by hash, but the dashboard stores the same config.
+	build2 := testBuild(2)
+	build2.KernelConfig = config
+	c.expectOK(dash.UploadBuild(c.ctx, build2))
+	for _, id := range []string{build1.ID, build2.ID} {
+		build, err := loadBuild(c.ctx, "test1", id)
+		c.expectOK(err)
+		data, _, err := getText(c.ctx, textKernelConfig, build.KernelConfig)
+		c.expectOK(err)
+		c.expectTrue(bytes.Equal(data, config))
+	}
+
+	// Builds that refer to unknown blobs are rejected.
+	build3 := testBuild(3)
+	build3.BlobHashes = []dashapi.BlobHash{{Field: "KernelConfig", SHA256: "unknown"}}
+	err = dash.Query(c.ctx, "upload_build", build3, nil)
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
+
+func TestUploadCoverage(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	req := &dashapi.UploadCoverageReq{
+		Manager:      build.Manager,
+		BuildID:      build.ID,
+		KernelRepo:   build.KernelRepo,
+		KernelBranch: build.KernelBranch,
+		KernelCommit: build.KernelCommit,
+		Files: map[string]*coveragedb.Coverage{
+			"mm/slab.c":  {Instrumented: 100, Covered: 10},
+			"fs/namei.c": {Instrumented: 50, Covered: 5},
+		},
+	}
+	c.expectOK(c.client.UploadCoverage(c.ctx, req))
+	// The second upload on the same day replaces the first one.
+	req.Files["mm/slab.c"].Covered = 20
+	c.expectOK(c.client.UploadCoverage(c.ctx, req))
+
+	var covs []*ManagerCoverage
+	_, err := db.NewQuery("ManagerCoverage").GetAll(c.ctx, &covs)
+	c.expectOK(err)
+	c.expectEQ(len(covs), 1)
+	c.expectEQ(covs[0].Manager, build.Manager)
+	c.expectEQ(covs[0].KernelCommit, build.KernelCommit)
+	c.expectEQ(covs[0].Instrumented, int64(150))
+	c.expectEQ(covs[0].Covered, int64(25))
+	data, _, err := getText(c.ctx, textCoverage, covs[0].Files)
+	c.expectOK(err)
+	files := make(map[string]*coveragedb.Coverage)
+	c.expectOK(json.Unmarshal(data, &files))
+	c.expectEQ(files, req.Files)
+	texts, err := db.NewQuery(textCoverage).KeysOnly().GetAll(c.ctx, nil)
+	c.expectOK(err)
+	c.expectEQ(len(texts), 1)
+
+	req.Files["mm/slab.c"].Covered = 1000
+	err = c.makeClient(client1, password1, false).UploadCoverage(c.ctx, req)
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+}
+
+func TestManagerNotifs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build1 := testBuild(1)
+	c.client.UploadBuild(context.Background(), build1)
+	// Fixes need to reach both managers to close bugs.
+	c.client.UploadBuild(context.Background(), testBuild(2))
+	reps := make(map[string]*dashapi.BugReport)
+	for i := 1; i <= 4; i++ {
+		c.client.ReportCrash(context.Background(), testCrash(build1, i))
+		rep := c.client.pollBug()
+		reps[rep.Title] = rep
+	}
+	poll := func(since time.Time) (map[string]*dashapi.ManagerNotif, time.Time) {
+		resp, err := c.client.ManagerNotifs(context.Background(), &dashapi.ManagerNotifsReq{
+			Manager: build1.Manager,
+			Since:   since,
+		})
+		c.expectOK(err)
+		notifs := make(map[string]*dashapi.ManagerNotif)
+		for _, notif := range resp.Notifs {
+			notifs[notif.Title] = notif
+		}
+		return notifs, resp.Until
+	}
+	notifs, since := poll(time.Time{})
+	c.expectEQ(len(notifs), 0)
+
+	c.advanceTime(time.Hour)
+	c.client.updateBug(reps["title1"].ID, dashapi.BugStatusInvalid, "")
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:         reps["title2"].ID,
+		Status:     dashapi.BugStatusOpen,
+		FixCommits: []string{"foo: fix the crash"},
+	})
+	c.expectTrue(reply.OK)
+	c.client.updateBug(reps["title3"].ID, dashapi.BugStatusDup, reps["title4"].ID)
+	notifs, since = poll(since)
+	c.expectEQ(len(notifs), 3)
+	c.expectEQ(notifs["title1"].Type, dashapi.ManagerNotifInvalid)
+	c.expectEQ(notifs["title2"].Type, dashapi.ManagerNotifFixPending)
+	c.expectEQ(notifs["title2"].FixCommits, []string{"foo: fix the crash"})
+	c.expectEQ(notifs["title3"].Type, dashapi.ManagerNotifDup)
+	c.expectEQ(notifs["title3"].DupOf, "title4")
+
+	// Nothing has changed since the last poll.
+	notifs, since = poll(since)
+	c.expectEQ(len(notifs), 0)
+
+	c.advanceTime(time.Hour)
+	build3 := testBuild(3)
+	build3.Manager = build1.Manager
+	build3.Commits = []string{"foo: fix the crash"}
+	c.client.UploadBuild(context.Background(), build3)
+	notifs, _ = poll(since)
+	c.expectEQ(len(notifs), 1)
+	c.expectEQ(notifs["title2"].Type, dashapi.ManagerNotifRetest)
+}
+
+func TestAddBugNote(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	c.client.pollBug()
+	listResp, err := c.client.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(listResp.List), 1)
+	bugID := listResp.List[0]
+
+	noFail := c.makeClient(client1, password1, false)
+	err = noFail.AddBugNote(context.Background(), &dashapi.AddBugNoteReq{
+		BugID: "unknown",
+		Title: "decoded stack",
+		Text:  []byte("stack"),
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	err = noFail.AddBugNote(context.Background(), &dashapi.AddBugNoteReq{
+		BugID: bugID,
+		Title: "decoded stack",
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+
+	text := []byte("decoded stack trace")
+	c.expectOK(c.client.AddBugNote(context.Background(), &dashapi.AddBugNoteReq{
+		BugID: bugID,
+		Title: "decoded stack",
+		Text:  text,
+	}))
+	rep, err := c.client.LoadBug(context.Background(), bugID)
+	c.expectOK(err)
+	c.expectEQ(len(rep.Notes), 1)
+	c.expectEQ(rep.Notes[0].Title, "decoded stack")
+	c.expectEQ(rep.Notes[0].Author, client1)
+	c.checkURLContents(rep.Notes[0].Link, text)
+
+	for i := 1; i < dashapi.MaxBugNotes; i++ {
+		c.expectOK(c.client.AddBugNote(context.Background(), &dashapi.AddBugNoteReq{
+			BugID: bugID,
+			Title: fmt.Sprintf("note %v", i),
+			Text:  text,
+		}))
+	}
+	err = noFail.AddBugNote(context.Background(), &dashapi.AddBugNoteReq{
+		BugID: bugID,
+		Title: "one too many",
+		Text:  text,
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+}
+
+func TestCrashMachine(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	crash.Machine = &dashapi.Machine{
+		Arch:     "amd64",
+		CPUModel: "Intel(R) Xeon(R) CPU @ 2.20GHz",
+		NumCPUs:  2,
+		MemoryMB: 4096,
+		VMType:   "qemu",
+		QemuArgs: "-enable-kvm",
+		Cmdline:  "root=/dev/sda1",
+	}
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+	c.expectEQ(rep.Machine, crash.Machine)
+
+	// Crashes without the machine description don't get an empty one.
+	c.client.ReportCrash(context.Background(), testCrash(build, 2))
+	rep = c.client.pollBug()
+	c.expectEQ(rep.Machine, (*dashapi.Machine)(nil))
+}
+
+func TestManagerCommands(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	c.client.pollBug()
+
+	page := "/test1/manager/" + build.Manager
+	for _, cmd := range []dashapi.ManagerCommandType{dashapi.ManagerCommandRerunRepro, dashapi.ManagerCommandStopFuzzing} {
+		arg := ""
+		if cmd == dashapi.ManagerCommandRerunRepro {
+			arg = crash.Title
+		}
+		_, err := c.POSTForm(page, url.Values{"command": {string(cmd)}, "command-arg": {arg}})
+		c.expectOK(err)
+		c.advanceTime(time.Minute)
+	}
+	_, err := c.POSTForm(page, url.Values{"command": {"foo"}})
+	c.expectBadReqest(err)
+	_, err = c.POSTForm(page, url.Values{"command": {string(dashapi.ManagerCommandRerunRepro)},
+		"command-arg": {"unknown title"}})
+	c.expectBadReqest(err)
+
+	cmds, err := c.client.ManagerCommands(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(len(cmds), 2)
+	c.expectEQ(cmds[0].Type, dashapi.ManagerCommandRerunRepro)
+	c.expectEQ(cmds[0].Arg, crash.Title)
+	c.expectEQ(cmds[0].CrashLog, crash.Log)
+	c.expectEQ(cmds[1].Type, dashapi.ManagerCommandStopFuzzing)
+
+	// Other managers don't get the commands.
+	other, err := c.client.ManagerCommands(context.Background(), "other-manager")
+	c.expectOK(err)
+	c.expectEQ(len(other), 0)
+	err = c.makeClient(client1, password1, false).AckManagerCommand(context.Background(), &dashapi.AckManagerCommandReq{
+		Manager: "other-manager",
+		ID:      cmds[0].ID,
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+
+	// Retried acks are fine.
+	for i := 0; i < 2; i++ {
+		c.expectOK(c.client.AckManagerCommand(context.Background(), &dashapi.AckManagerCommandReq{
+			Manager: build.Manager,
+			ID:      cmds[0].ID,
+			Error:   "no VMs",
+		}))
+	}
+	cmds, err = c.client.ManagerCommands(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(len(cmds), 1)
+	c.expectEQ(cmds[0].Type, dashapi.ManagerCommandStopFuzzing)
+
+	reply, err := c.AuthGET(AccessAdmin, page)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("failed: no VMs")))
+	reply, err = c.AuthGET(AccessUser, page)
+	c.expectOK(err)
+	c.expectTrue(!bytes.Contains(reply, []byte("Send a command")))
+}
+
+func TestRetestFixedBug(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build1 := testBuild(1)
+	c.client.UploadBuild(context.Background(), build1)
+	crash := testCrashWithRepro(build1, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:         rep.ID,
+		Status:     dashapi.BugStatusOpen,
+		FixCommits: []string{"foo: fix the crash"},
+	})
+	c.expectTrue(reply.OK)
+
+	// The manager gets the retest once its build contains the fix.
+	build2 := testBuild(2)
+	build2.Manager = build1.Manager
+	build2.Commits = []string{"foo: fix the crash"}
+	c.client.UploadBuild(context.Background(), build2)
+	cmds, err := c.client.ManagerCommands(context.Background(), build1.Manager)
+	c.expectOK(err)
+	c.expectEQ(len(cmds), 1)
+	c.expectEQ(cmds[0].Type, dashapi.ManagerCommandRetest)
+	c.expectEQ(cmds[0].ReproSyz, crash.ReproSyz)
+	c.expectEQ(cmds[0].ReproOpts, crash.ReproOpts)
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(cmds[0].Arg, bug.keyHash(c.ctx))
+	c.expectOK(c.client.AckManagerCommand(context.Background(), &dashapi.AckManagerCommandReq{
+		Manager: build1.Manager,
+		ID:      cmds[0].ID,
+	}))
+
+	result := &dashapi.RetestResult{
+		Manager:   "other-manager",
+		CommandID: cmds[0].ID,
+		BuildID:   build2.ID,
+	}
+	err = c.makeClient(client1, password1, false).UploadRetestResult(context.Background(), result)
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	// Retried uploads are fine.
+	result.Manager = build1.Manager
+	for i := 0; i < 2; i++ {
+		c.expectOK(c.client.UploadRetestResult(context.Background(), result))
+	}
+	bug, _, _ = c.loadBug(rep.ID)
+	c.expectEQ(len(bug.Retests), 1)
+	c.expectEQ(bug.Retests[0].Crashed, false)
+	page, err := c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte("did not crash")))
+
+	// Admins can retest bugs with reproducers manually.
+	mgrPage := "/test1/manager/" + build1.Manager
+	_, err = c.POSTForm(mgrPage, url.Values{"command": {string(dashapi.ManagerCommandRetest)},
+		"command-arg": {bug.keyHash(c.ctx)}})
+	c.expectOK(err)
+	_, err = c.POSTForm(mgrPage, url.Values{"command": {string(dashapi.ManagerCommandRetest)},
+		"command-arg": {"unknown"}})
+	c.expectBadReqest(err)
+}
+
+func TestUpdateCrash(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	resp, err := c.client.ReportCrash(context.Background(), crash)
+	c.expectOK(err)
+	c.expectTrue(resp.NeedRepro)
+	c.expectNE(resp.CrashID, int64(0))
+
+	repro := testCrashWithRepro(build, 1)
+	req := &dashapi.UpdateCrashReq{
+		BuildID:   build.ID,
+		Title:     crash.Title,
+		CrashID:   resp.CrashID,
+		ReproOpts: repro.ReproOpts,
+		ReproSyz:  repro.ReproSyz,
+		ReproC:    repro.ReproC,
+		ReproLog:  repro.ReproLog,
+	}
+	c.expectOK(c.client.UpdateCrash(context.Background(), req))
+
+	rep := c.client.pollBug()
+	c.expectEQ(rep.ReproSyz, repro.ReproSyz)
+	c.expectEQ(rep.ReproC, repro.ReproC)
+	c.expectEQ(rep.Log, crash.Log)
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.NumCrashes, int64(1))
+	c.expectEQ(bug.NumRepro, int64(1))
+	c.expectEQ(bug.ReproLevel, ReproLevelC)
+
+	// Retried requests don't count the repro twice.
+	c.expectOK(c.client.UpdateCrash(context.Background(), req))
+	bug, _, _ = c.loadBug(rep.ID)
+	c.expectEQ(bug.NumRepro, int64(1))
+
+	// Unknown crashes are reported with ReportCrash instead.
+	noFail := c.makeClient(client1, password1, false)
+	req.CrashID++
+	err = noFail.UpdateCrash(context.Background(), req)
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	req.CrashID, req.Title = resp.CrashID, "unknown title"
+	err = noFail.UpdateCrash(context.Background(), req)
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
+
+func TestManagerSyscalls(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	// The reproducer is syncfs(1).
+	c.client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
+	rep := c.client.pollBug()
+
+	c.expectOK(c.client.UploadManagerSyscalls(context.Background(), &dashapi.ManagerSyscallsReq{
+		Manager:     build.Manager,
+		BuildID:     build.ID,
+		Enabled:     []string{"syncfs", "openat"},
+		Unsupported: map[string]string{"mount": "no permission"},
+	}))
+	c.expectOK(c.client.UploadManagerSyscalls(context.Background(), &dashapi.ManagerSyscallsReq{
+		Manager:     "other-manager",
+		Enabled:     []string{"openat"},
+		Unsupported: map[string]string{"syncfs": "ENOSYS"},
+	}))
+	err := c.makeClient(client1, password1, false).UploadManagerSyscalls(context.Background(), &dashapi.ManagerSyscallsReq{
+		Enabled: []string{"openat"},
+	})
+	c.expectBadReqest(err)
+
+	syscalls, err := loadManagerSyscalls(c.ctx, "test1", build.Manager)
+	c.expectOK(err)
+	c.expectEQ(syscalls.Enabled, []string{"openat", "syncfs"})
+	c.expectEQ(syscalls.Unsupported, []UnsupportedSyscall{{"mount", "no permission"}})
+
+	reply, err := c.AuthGET(AccessAdmin, "/test1/manager/"+build.Manager)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("2 enabled, 1 unsupported")))
+	c.expectTrue(bytes.Contains(reply, []byte("no permission")))
+
+	reply, err = c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("Managers that can run the reproducer (1/2)")))
+}
+
+func TestStatsSeries(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	start := c.mockedTime
+	point := func(d time.Duration, execs int64) dashapi.StatsPoint {
+		return dashapi.StatsPoint{
+			Time:   start.Add(d),
+			Values: map[string]int64{dashapi.StatExecs: execs},
+		}
+	}
+	c.advanceTime(time.Hour)
+	c.expectOK(c.client.UploadStatsSeries(context.Background(), &dashapi.UploadStatsSeriesReq{
+		Manager: "manager",
+		Points:  []dashapi.StatsPoint{point(time.Minute, 10), point(2*time.Minute, 20)},
+	}))
+	c.expectOK(c.client.UploadStatsSeries(context.Background(), &dashapi.UploadStatsSeriesReq{
+		Manager: "manager",
+		// The first point is a retry, it must not override the later one.
+		Points: []dashapi.StatsPoint{point(time.Minute, 10), point(12*time.Minute, 30)},
+	}))
+	c.expectOK(c.client.UploadStatsSeries(context.Background(), &dashapi.UploadStatsSeriesReq{
+		Manager: "other-manager",
+		Points:  []dashapi.StatsPoint{point(3*time.Minute, 5)},
+	}))
+	err := c.makeClient(client1, password1, false).UploadStatsSeries(context.Background(), &dashapi.UploadStatsSeriesReq{
+		Points: []dashapi.StatsPoint{point(time.Minute, 10)},
+	})
+	c.expectBadReqest(err)
+
+	resp, err := c.client.StatsSeries(context.Background(), &dashapi.StatsSeriesReq{
+		Manager: "manager",
+		From:    start,
+	})
+	c.expectOK(err)
+	c.expectEQ(resp.Period, 10*time.Minute)
+	c.expectEQ(resp.Series, []*dashapi.StatsSeries{{
+		Manager: "manager",
+		Points:  []dashapi.StatsPoint{point(2*time.Minute, 20), point(12*time.Minute, 30)},
+	}})
+
+	// The 10 minute samples are not kept for that long.
+	c.advanceTime(5 * 24 * time.Hour)
+	resp, err = c.client.StatsSeries(context.Background(), &dashapi.StatsSeriesReq{
+		From: start,
+	})
+	c.expectOK(err)
+	c.expectEQ(resp.Period, time.Hour)
+	c.expectEQ(resp.Series, []*dashapi.StatsSeries{
+		{Manager: "manager", Points: []dashapi.StatsPoint{point(12*time.Minute, 30)}},
+		{Manager: "other-manager", Points: []dashapi.StatsPoint{point(3*time.Minute, 5)}},
+	})
+}
+
+func TestBuildTarget(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	c.transformContext = func(c context.Context) context.Context {
+		newConfig := replaceNamespaceConfig(c, "test1", func(cfg *Config) *Config {
+			ret := *cfg
+			ret.OSes = []string{targets.Linux}
+			return &ret
+		})
+		return contextWithConfig(c, newConfig)
+	}
+
+	build := testBuild(1)
+	c.expectOK(c.client.UploadBuild(context.Background(), build))
+
+	build2 := testBuild(2)
+	build2.OS = targets.FreeBSD
+	err := c.makeClient(client1, password1, false).UploadBuild(context.Background(), build2)
+	c.expectBadReqest(err)
+	c.expectTrue(strings.Contains(err.Error(), "does not accept freebsd builds"))
+
+	build3 := testBuild(3)
+	build3.Arch = "unknown"
+	c.expectBadReqest(c.makeClient(client1, password1, false).UploadBuild(context.Background(), build3))
+
+	build4 := testBuild(4)
+	build4.VMArch = "unknown"
+	c.expectBadReqest(c.makeClient(client1, password1, false).UploadBuild(context.Background(), build4))
+
+	// Other namespaces accept builds for any OS.
+	c.expectOK(c.client2.UploadBuild(context.Background(), build2))
+}
+
+func TestCrashSignatureMerge(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+
+	crash1 := testCrash(build, 1)
+	crash1.Signature = "signature1"
+	c.client.ReportCrash(context.Background(), crash1)
+	rep := c.client.pollBug()
+
+	// The title differs only by the line number, but the signature is the same.
+	crash2 := testCrash(build, 2)
+	crash2.Signature = "signature1"
+	c.client.ReportCrash(context.Background(), crash2)
+	c.client.pollBugs(0)
+
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.NumCrashes, int64(2))
+	c.expectEQ(bug.MergedTitles, []string{crash1.Title, crash2.Title})
+	c.expectEQ(bug.Signatures, []string{"signature1"})
+
+	// Crashes with other signatures are separate bugs.
+	crash3 := testCrash(build, 3)
+	crash3.Signature = "signature3"
+	c.client.ReportCrash(context.Background(), crash3)
+	rep3 := c.client.pollBug()
+	c.expectNE(rep3.ID, rep.ID)
+}
+
+func TestCrashAttachments(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+
+	crash := testCrash(build, 1)
+	crash.Attachments = []dashapi.CrashAttachment{
+		{Name: "netconsole.log", Data: []byte("netconsole output")},
+		{Name: "vm1-console.log", MIMEType: "text/plain", Data: []byte("other VM output")},
+		{Name: "vmcore.gz", MIMEType: "application/gzip", Data: []byte("gzip")},
+	}
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+	c.expectEQ(len(rep.Attachments), 3)
+	c.expectEQ(rep.Attachments[0].Name, "netconsole.log")
+	c.expectEQ(rep.Attachments[0].MIMEType, dashapi.DefaultMIMEType)
+	c.expectEQ(rep.Attachments[2].MIMEType, "application/gzip")
+	for i, att := range rep.Attachments {
+		c.checkURLContents(att.Link, crash.Attachments[i].Data)
+	}
+	reply, err := c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("vm1-console.log")))
+
+	crash.Attachments = append(crash.Attachments, dashapi.CrashAttachment{Name: "netconsole.log"})
+	_, err = c.makeClient(client1, password1, false).ReportCrash(context.Background(), crash)
+	c.expectBadReqest(err)
+}
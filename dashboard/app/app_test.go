@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -66,10 +67,14 @@ var testConfig = &GlobalConfig{
 			Key:                   "test1keytest1keytest1key",
 			FixBisectionAutoClose: true,
 			SimilarityDomain:      testDomain,
+			AssetUploadBucket:     "syzkaller-assets/uploads",
 			Clients: map[string]string{
 				client1: password1,
 				"oauth": auth.OauthMagic + "111111122222222",
 			},
+			ReadOnlyClients: map[string]string{
+				clientReadOnly: keyReadOnly,
+			},
 			Repos: []KernelRepo{
 				{
 					URL:    "git://syzkaller.org",
@@ -144,6 +149,8 @@ var testConfig = &GlobalConfig{
 					URL:    "git://syzkaller.org",
 					Branch: "branch20",
 					Alias:  "repo20",
+					OS:     targets.Linux,
+					Arch:   targets.AMD64,
 					CC: CCConfig{
 						Maintainers: []string{"maintainers@repo20.org", "bugs@repo20.org"},
 					},
@@ -570,6 +577,42 @@ var testConfig = &GlobalConfig{
 			FindBugOriginTrees:     true,
 			RetestMissingBackports: true,
 		},
+		"manager-config": {
+			AccessLevel: AccessAdmin,
+			Key:         "managerconfigmanagerconfigmanagerconfig",
+			Clients: map[string]string{
+				clientManagerConfig: keyManagerConfig,
+			},
+			Repos: []KernelRepo{
+				{
+					URL:    "git://syzkaller.org",
+					Branch: "branch10",
+					Alias:  "repo10alias",
+				},
+			},
+			Managers: map[string]ConfigManager{
+				overridesManager: {
+					Overrides: ManagerOverrides{
+						DisabledSyscalls: []string{"io_uring_setup"},
+						MaxReproAttempts: 5,
+						ReportingPaused:  true,
+					},
+				},
+			},
+			ManagerOverrides: ManagerOverrides{
+				SuppressedTitles: []string{"^INFO: task hung"},
+				MaxReproAttempts: 2,
+			},
+			Reporting: []Reporting{
+				{
+					Name:       "reporting1",
+					DailyLimit: 3,
+					Config: &TestConfig{
+						Index: 1,
+					},
+				},
+			},
+		},
 	},
 }
 
@@ -620,10 +663,15 @@ const (
 	keySubsystemRemind    = "keySubsystemRemindkeySubsystemRemind"
 	clientTreeTests       = "clientTreeTestsclientTreeTests"
 	keyTreeTests          = "keyTreeTestskeyTreeTestskeyTreeTests"
+	clientReadOnly        = "client-read-only"
+	keyReadOnly           = "keyReadOnlykeyReadOnlykeyReadOnly"
+	clientManagerConfig   = "client-manager-config"
+	keyManagerConfig      = "keyManagerConfigkeyManagerConfig"
 
 	restrictedManager     = "restricted-manager"
 	noFixBisectionManager = "no-fix-bisection-manager"
 	specialCCManager      = "special-cc-manager"
+	overridesManager      = "overrides-manager"
 	notYetDecommManger    = "not-yet-decomm-manager"
 	delegateToManager     = "delegate-to-manager"
 
@@ -713,28 +761,42 @@ func TestApp(t *testing.T) {
 
 	apiClient1 := c.makeClient(client1, password1, false)
 	apiClient2 := c.makeClient(client2, password2, false)
-	c.expectFail("unknown api method", apiClient1.Query("unsupported_method", nil, nil))
-	c.client.LogError("name", "msg %s", "arg")
+	err = apiClient1.Query(context.Background(), "unsupported_method", nil, nil)
+	c.expectFail("unknown api method", err)
+	// Such requests will fail again, they must not be retried.
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest) && !dashapi.IsTemporary(err))
+	c.client.LogError(context.Background(), "name", "msg %s", "arg")
+	c.client.LogWarn(context.Background(), "name", "msg %s", "arg")
+	c.client.Log(context.Background(), &dashapi.LogEntry{
+		Name:   "name",
+		Text:   "msg",
+		Level:  dashapi.LogLevelInfo,
+		Fields: map[string]string{"key": "val"},
+	})
+	c.expectOK(c.client.FlushLogErrors(context.Background()))
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	// Uploading the same build must be OK.
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Some bad combinations of client/key.
-	c.expectFail("unauthorized", c.makeClient(client1, "borked", false).Query("upload_build", build, nil))
-	c.expectFail("unauthorized", c.makeClient("unknown", password1, false).Query("upload_build", build, nil))
-	c.expectFail("unauthorized", c.makeClient(client1, password2, false).Query("upload_build", build, nil))
+	c.expectFail("unauthorized", c.makeClient(client1, "borked", false).Query(context.Background(),
+		"upload_build", build, nil))
+	c.expectFail("unauthorized", c.makeClient("unknown", password1, false).Query(context.Background(),
+		"upload_build", build, nil))
+	c.expectFail("unauthorized", c.makeClient(client1, password2, false).Query(context.Background(),
+		"upload_build", build, nil))
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBug()
 
 	// Test that namespace isolation works.
-	c.expectFail("unknown build", apiClient2.Query("report_crash", crash1, nil))
+	c.expectFail("unknown build", apiClient2.Query(context.Background(), "report_crash", crash1, nil))
 
 	crash2 := testCrashWithRepro(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBug()
 
 	// Provoke purgeOldCrashes.
@@ -747,7 +809,7 @@ func TestApp(t *testing.T) {
 		crash := testCrash(build, 3)
 		crash.Log = []byte(fmt.Sprintf("log%v", i))
 		crash.Report = []byte(fmt.Sprintf("report%v", i))
-		c.client.ReportCrash(crash)
+		c.client.ReportCrash(context.Background(), crash)
 	}
 	rep := c.client.pollBug()
 	bug, _, _ := c.loadBug(rep.ID)
@@ -761,11 +823,11 @@ func TestApp(t *testing.T) {
 		BuildID: "build1",
 		Title:   "title1",
 	}
-	c.client.ReportFailedRepro(cid)
+	c.client.ReportFailedRepro(context.Background(), cid)
 
-	c.client.ReportingPollBugs("test")
+	c.client.ReportingPollBugs(context.Background(), "test")
 
-	c.client.ReportingUpdate(&dashapi.BugUpdate{
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         "id",
 		Status:     dashapi.BugStatusOpen,
 		ReproLevel: dashapi.ReproLevelC,
@@ -865,22 +927,22 @@ func TestPurgeOldCrashes(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// First, send 3 crashes that are reported. These need to be preserved regardless.
 	crash := testCrash(build, 1)
 	crash.ReproOpts = []byte("no repro")
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep := c.client.pollBug()
 
 	crash.ReproSyz = []byte("getpid()")
 	crash.ReproOpts = []byte("syz repro")
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	c.client.pollBug()
 
 	crash.ReproC = []byte("int main() {}")
 	crash.ReproOpts = []byte("C repro")
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	c.client.pollBug()
 
 	// Now report lots of bugs with/without repros. Some of the older ones should be purged.
@@ -890,12 +952,12 @@ func TestPurgeOldCrashes(t *testing.T) {
 		crash.ReproSyz = nil
 		crash.ReproC = nil
 		crash.ReproOpts = []byte(fmt.Sprintf("%v", i))
-		c.client.ReportCrash(crash)
+		c.client.ReportCrash(context.Background(), crash)
 
 		crash.ReproSyz = []byte("syz repro")
 		crash.ReproC = []byte("C repro")
 		crash.ReproOpts = []byte(fmt.Sprintf("%v", i))
-		c.client.ReportCrash(crash)
+		c.client.ReportCrash(context.Background(), crash)
 	}
 	bug, _, _ := c.loadBug(rep.ID)
 	crashes, _, err := queryCrashesForBug(c.ctx, bug.key(c.ctx), 10*totalReported)
@@ -952,7 +1014,7 @@ func TestPurgeOldCrashes(t *testing.T) {
 	}
 
 	// Unreport the first crash.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:               rep.ID,
 		Status:           dashapi.BugStatusUpdate,
 		ReproLevel:       dashapi.ReproLevelC,
@@ -967,7 +1029,7 @@ func TestPurgeOldCrashes(t *testing.T) {
 		crash.ReproSyz = nil
 		crash.ReproC = nil
 		crash.ReproOpts = []byte(fmt.Sprintf("%v", i))
-		c.client.ReportCrash(crash)
+		c.client.ReportCrash(context.Background(), crash)
 	}
 	// Check that the unreported crash was purged.
 	if firstCrashExists() {
@@ -981,14 +1043,14 @@ func TestManagerFailedBuild(t *testing.T) {
 
 	// Upload and check first build.
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, nil, nil)
 
 	// Upload and check second build.
 	build.ID = "id1"
 	build.KernelCommit = "kern1"
 	build.SyzkallerCommit = "syz1"
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, nil, nil)
 
 	// Upload failed kernel build.
@@ -998,7 +1060,7 @@ func TestManagerFailedBuild(t *testing.T) {
 	failedBuild.KernelCommit = "kern2"
 	failedBuild.KernelCommitTitle = "failed build 1"
 	failedBuild.SyzkallerCommit = "syz2"
-	c.expectOK(c.client.ReportBuildError(&dashapi.BuildErrorReq{
+	c.expectOK(c.client.ReportBuildError(context.Background(), &dashapi.BuildErrorReq{
 		Build: *failedBuild,
 		Crash: dashapi.Crash{
 			Title: "failed build 1",
@@ -1007,13 +1069,13 @@ func TestManagerFailedBuild(t *testing.T) {
 	checkManagerBuild(c, build, failedBuild, nil)
 
 	// Now the old good build again, nothing should change.
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, failedBuild, nil)
 
 	// New good kernel build, failed build must reset.
 	build.ID = "id3"
 	build.KernelCommit = "kern3"
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, nil, nil)
 
 	// Now more complex scenario: OK -> failed kernel -> failed kernel+syzkaller -> failed syzkaller -> OK.
@@ -1021,7 +1083,7 @@ func TestManagerFailedBuild(t *testing.T) {
 	failedBuild.KernelCommit = "kern4"
 	failedBuild.KernelCommitTitle = "failed build 4"
 	failedBuild.SyzkallerCommit = "syz4"
-	c.expectOK(c.client.ReportBuildError(&dashapi.BuildErrorReq{
+	c.expectOK(c.client.ReportBuildError(context.Background(), &dashapi.BuildErrorReq{
 		Build: *failedBuild,
 		Crash: dashapi.Crash{
 			Title: "failed build 4",
@@ -1035,7 +1097,7 @@ func TestManagerFailedBuild(t *testing.T) {
 	failedBuild2.KernelCommit = ""
 	failedBuild2.KernelCommitTitle = "failed build 5"
 	failedBuild2.SyzkallerCommit = "syz5"
-	c.expectOK(c.client.ReportBuildError(&dashapi.BuildErrorReq{
+	c.expectOK(c.client.ReportBuildError(context.Background(), &dashapi.BuildErrorReq{
 		Build: *failedBuild2,
 		Crash: dashapi.Crash{
 			Title: "failed build 5",
@@ -1045,14 +1107,37 @@ func TestManagerFailedBuild(t *testing.T) {
 
 	build.ID = "id6"
 	build.KernelCommit = "kern6"
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, nil, failedBuild2)
 
 	build.ID = "id7"
 	build.KernelCommit = "kern6"
 	build.SyzkallerCommit = "syz7"
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
+	checkManagerBuild(c, build, nil, nil)
+}
+
+func TestJobBuildPurpose(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
 	checkManagerBuild(c, build, nil, nil)
+
+	// Bisection and patch testing builds don't replace the current manager build.
+	for i, purpose := range []dashapi.BuildPurpose{dashapi.BuildPurposeBisection, dashapi.BuildPurposePatchTest} {
+		jobBuild := testBuild(2 + i)
+		jobBuild.Purpose = purpose
+		c.expectOK(c.client.UploadBuild(context.Background(), jobBuild))
+		checkManagerBuild(c, build, nil, nil)
+		c.expectEQ(c.loadBuild("test1", jobBuild.ID).Type, BuildJob)
+	}
+
+	badBuild := testBuild(4)
+	badBuild.Purpose = "foo"
+	err := c.makeClient(client1, password1, false).UploadBuild(context.Background(), badBuild)
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
 }
 
 func checkManagerBuild(c *Ctx, build, failedKernelBuild, failedSyzBuild *dashapi.Build) {
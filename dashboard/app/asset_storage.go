@@ -511,6 +511,8 @@ func createAssetList(build *Build, crash *Crash, forReport bool) []dashapi.Asset
 			Title:       typeDescr.GetTitle(targets.Get(build.OS, build.Arch)),
 			DownloadURL: reportAsset.DownloadURL,
 			Type:        reportAsset.Type,
+			Size:        reportAsset.Size,
+			SHA256:      reportAsset.SHA256,
 		})
 	}
 	sort.SliceStable(assetList, func(i, j int) bool {
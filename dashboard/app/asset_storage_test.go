@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"testing"
@@ -26,16 +27,16 @@ func TestBuildAssetLifetime(t *testing.T) {
 			DownloadURL: "http://google.com/vmlinux",
 		},
 	}
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	// Add one more build, so that the assets of the previous one could be deprecated.
 	c.advanceTime(time.Minute)
 	build2 := testBuild(2)
 	build2.Manager = "test_manager"
-	c.client2.UploadBuild(build2)
+	c.client2.UploadBuild(context.Background(), build2)
 
 	// "Upload" several more assets.
-	c.expectOK(c.client2.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+	c.expectOK(c.client2.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 		BuildID: build.ID,
 		Assets: []dashapi.NewAsset{
 			{
@@ -44,7 +45,7 @@ func TestBuildAssetLifetime(t *testing.T) {
 			},
 		},
 	}))
-	c.expectOK(c.client2.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+	c.expectOK(c.client2.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 		BuildID: build.ID,
 		Assets: []dashapi.NewAsset{
 			{
@@ -56,7 +57,7 @@ func TestBuildAssetLifetime(t *testing.T) {
 
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{`"Foo Bar" <foo@bar.com>`, `bar@foo.com`, `idont@want.EMAILS`}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	// Test that the reporting email is correct.
 	msg := c.pollEmailBug()
@@ -118,7 +119,7 @@ If you want to undo deduplication, reply with:
 	c.checkURLContents(kernelConfigLink, build.KernelConfig)
 
 	// We query the needed assets. We need all 3.
-	needed, err := c.client2.NeededAssetsList()
+	needed, err := c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	sort.Strings(needed.DownloadURLs)
 	allDownloadURLs := []string{
@@ -134,7 +135,7 @@ If you want to undo deduplication, reply with:
 	c.expectOK(err)
 
 	// Query the needed assets once more, so far there should be no change.
-	needed, err = c.client2.NeededAssetsList()
+	needed, err = c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	sort.Strings(needed.DownloadURLs)
 	c.expectEQ(needed.DownloadURLs, allDownloadURLs)
@@ -145,7 +146,7 @@ If you want to undo deduplication, reply with:
 	c.expectOK(err)
 
 	// Only the html asset should have persisted.
-	needed, err = c.client2.NeededAssetsList()
+	needed, err = c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	c.expectEQ(needed.DownloadURLs, []string{"http://google.com/coverage.html"})
 }
@@ -155,10 +156,10 @@ func TestCoverReportDisplay(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Upload the second build to just make sure coverage reports are assigned per-manager.
-	c.client.UploadBuild(testBuild(2))
+	c.client.UploadBuild(context.Background(), testBuild(2))
 
 	// We expect no coverage reports to be present.
 	uiManagers, err := loadManagers(c.ctx, AccessAdmin, "test1", nil)
@@ -169,7 +170,7 @@ func TestCoverReportDisplay(t *testing.T) {
 
 	// Upload an asset.
 	origHTMLAsset := "http://google.com/coverage0.html"
-	c.expectOK(c.client.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+	c.expectOK(c.client.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 		BuildID: build.ID,
 		Assets: []dashapi.NewAsset{
 			{
@@ -186,7 +187,7 @@ func TestCoverReportDisplay(t *testing.T) {
 
 	// Upload a newer coverage.
 	newHTMLAsset := "http://google.com/coverage1.html"
-	c.expectOK(c.client.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+	c.expectOK(c.client.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 		BuildID: build.ID,
 		Assets: []dashapi.NewAsset{
 			{
@@ -209,7 +210,7 @@ func TestCoverReportDeprecation(t *testing.T) {
 	ensureNeeded := func(needed []string) {
 		_, err := c.GET("/cron/deprecate_assets")
 		c.expectOK(err)
-		neededResp, err := c.client.NeededAssetsList()
+		neededResp, err := c.client.NeededAssetsList(context.Background())
 		c.expectOK(err)
 		sort.Strings(neededResp.DownloadURLs)
 		sort.Strings(needed)
@@ -217,10 +218,10 @@ func TestCoverReportDeprecation(t *testing.T) {
 	}
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	uploadReport := func(url string) {
-		c.expectOK(c.client.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+		c.expectOK(c.client.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 			BuildID: build.ID,
 			Assets: []dashapi.NewAsset{
 				{
@@ -285,7 +286,7 @@ func TestFreshBuildAssets(t *testing.T) {
 	ensureNeeded := func(needed []string) {
 		_, err := c.GET("/cron/deprecate_assets")
 		c.expectOK(err)
-		neededResp, err := c.client.NeededAssetsList()
+		neededResp, err := c.client.NeededAssetsList(context.Background())
 		c.expectOK(err)
 		sort.Strings(neededResp.DownloadURLs)
 		sort.Strings(needed)
@@ -300,7 +301,7 @@ func TestFreshBuildAssets(t *testing.T) {
 			DownloadURL: "http://google.com/vmlinux",
 		},
 	}
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// No crashes yet, but it's the latest build, so the assets must be preserved.
 	ensureNeeded([]string{"http://google.com/vmlinux"})
@@ -315,7 +316,7 @@ func TestFreshBuildAssets(t *testing.T) {
 			DownloadURL: "http://google.com/vmlinux2",
 		},
 	}
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// The assets of the previous build are reasonably new, so they must be kept.
 	ensureNeeded([]string{"http://google.com/vmlinux", "http://google.com/vmlinux2"})
@@ -334,7 +335,7 @@ func TestCrashAssetLifetime(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{`"Foo Bar" <foo@bar.com>`, `bar@foo.com`, `idont@want.EMAILS`}
@@ -348,7 +349,7 @@ func TestCrashAssetLifetime(t *testing.T) {
 			DownloadURL: "http://google.com/disk_image2",
 		},
 	}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	// Test that the reported email is correct.
 	msg := c.pollEmailBug()
@@ -410,7 +411,7 @@ If you want to undo deduplication, reply with:
 	c.checkURLContents(kernelConfigLink, build.KernelConfig)
 
 	// We query the needed assets. We need all 2.
-	needed, err := c.client2.NeededAssetsList()
+	needed, err := c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	sort.Strings(needed.DownloadURLs)
 	allDownloadURLs := []string{
@@ -425,7 +426,7 @@ If you want to undo deduplication, reply with:
 	c.expectOK(err)
 
 	// Query the needed assets once more, so far there should be no change.
-	needed, err = c.client2.NeededAssetsList()
+	needed, err = c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	sort.Strings(needed.DownloadURLs)
 	c.expectEQ(needed.DownloadURLs, allDownloadURLs)
@@ -436,7 +437,44 @@ If you want to undo deduplication, reply with:
 	c.expectOK(err)
 
 	// Nothing should have been persisted.
-	needed, err = c.client2.NeededAssetsList()
+	needed, err = c.client2.NeededAssetsList(context.Background())
 	c.expectOK(err)
 	c.expectEQ(needed.DownloadURLs, []string{})
 }
+
+func TestBuildAssetHash(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	build.Assets = []dashapi.NewAsset{
+		{
+			Type:        dashapi.KernelObject,
+			DownloadURL: "http://google.com/vmlinux",
+			Size:        1000,
+			SHA256:      "dbb1d5c3b3ab2a7e1e2a1ed2f0e0d2e1ac56c1f9d13a4b5d1b5b0c1e9e1f2a3b",
+		},
+	}
+	c.expectOK(c.client.UploadBuild(context.Background(), build))
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	rep := c.client.pollBug()
+	c.expectEQ(rep.Assets, []dashapi.Asset{
+		{
+			Title:       "vmlinux",
+			DownloadURL: "http://google.com/vmlinux",
+			Type:        dashapi.KernelObject,
+			Size:        1000,
+			SHA256:      "dbb1d5c3b3ab2a7e1e2a1ed2f0e0d2e1ac56c1f9d13a4b5d1b5b0c1e9e1f2a3b",
+		},
+	})
+
+	build2 := testBuild(2)
+	build2.Assets = []dashapi.NewAsset{
+		{
+			Type:        dashapi.KernelObject,
+			DownloadURL: "http://google.com/vmlinux2",
+			SHA256:      "bad",
+		},
+	}
+	c.expectNE(c.client.UploadBuild(context.Background(), build2), nil)
+}
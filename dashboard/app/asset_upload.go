@@ -0,0 +1,95 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/asset"
+	"github.com/google/syzkaller/pkg/gcs"
+	"google.golang.org/appengine/v2"
+)
+
+// This file implements signed upload URLs for large assets (see dashapi.AssetUploadURLs).
+// The files are uploaded directly to Config.AssetUploadBucket, the dashboard only hands out
+// the URLs. The bucket is expected to have a lifecycle policy that removes old files.
+
+const (
+	assetUploadExpiration = time.Hour
+	maxAssetUploads       = 10
+)
+
+var assetNameRe = regexp.MustCompile(`^[a-zA-Z0-9_.\-]{1,100}$`)
+
+func apiAssetUploadURLs(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.AssetUploadReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	bucket := getNsConfig(c, ns).AssetUploadBucket
+	if bucket == "" {
+		return nil, fmt.Errorf("%w: asset uploads are not configured", ErrClientNotFound)
+	}
+	if len(req.Assets) > maxAssetUploads {
+		return nil, fmt.Errorf("%w: too many assets (%v)", ErrClientBadRequest, len(req.Assets))
+	}
+	bucket, prefix, _ := strings.Cut(bucket, "/")
+	now := timeNow(c)
+	expires := now.Add(assetUploadExpiration)
+	resp := new(dashapi.AssetUploadResp)
+	for i, upload := range req.Assets {
+		if asset.GetTypeDescription(upload.Type) == nil {
+			return nil, fmt.Errorf("%w: unknown asset type %q", ErrClientBadRequest, upload.Type)
+		}
+		if !assetNameRe.MatchString(upload.Name) {
+			return nil, fmt.Errorf("%w: bad asset name %q", ErrClientBadRequest, upload.Name)
+		}
+		// The timestamp and the index make the paths unique.
+		object := fmt.Sprintf("%v/%v/%x-%v-%v", ns, upload.Type, now.UnixNano(), i, upload.Name)
+		if prefix != "" {
+			object = prefix + "/" + object
+		}
+		uploadURL, err := signAssetUploadURL(c, bucket, object, upload.ContentType, expires)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign upload url: %w", err)
+		}
+		url := &dashapi.AssetUploadURL{
+			UploadURL:   uploadURL,
+			Expires:     expires,
+			DownloadURL: gcs.PublicPrefix + bucket + "/" + object,
+		}
+		if upload.ContentType != "" {
+			url.Headers = map[string]string{"Content-Type": upload.ContentType}
+		}
+		resp.URLs = append(resp.URLs, url)
+	}
+	return resp, nil
+}
+
+// Overridable for testing.
+var signAssetUploadURL = func(c context.Context, bucket, object, contentType string, expires time.Time) (
+	string, error) {
+	account, err := appengine.ServiceAccount(c)
+	if err != nil {
+		return "", err
+	}
+	return storage.SignedURL(bucket, object, &storage.SignedURLOptions{
+		GoogleAccessID: account,
+		SignBytes: func(data []byte) ([]byte, error) {
+			_, sig, err := appengine.SignBytes(c, data)
+			return sig, err
+		},
+		Method:      "PUT",
+		ContentType: contentType,
+		Expires:     expires,
+		Scheme:      storage.SigningSchemeV4,
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+func TestAssetUploadURLs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	resp, err := c.client.AssetUploadURLs(context.Background(), &dashapi.AssetUploadReq{
+		Assets: []*dashapi.AssetUpload{
+			{Type: dashapi.BootableDisk, Name: "disk.raw.xz"},
+			{Type: dashapi.MountInRepro, Name: "mount_0.gz", ContentType: "application/gzip"},
+		},
+	})
+	c.expectOK(err)
+	c.expectEQ(len(resp.URLs), 2)
+	expires := c.mockedTime.Add(assetUploadExpiration)
+	for i, name := range []string{"bootable_disk/%x-0-disk.raw.xz", "mount_in_repro/%x-1-mount_0.gz"} {
+		object := "uploads/test1/" + fmt.Sprintf(name, c.mockedTime.UnixNano())
+		url := resp.URLs[i]
+		c.expectEQ(url.UploadURL, fmt.Sprintf("https://upload.test/syzkaller-assets/%v?expires=%v",
+			object, expires.Unix()))
+		c.expectEQ(url.DownloadURL, "https://storage.googleapis.com/syzkaller-assets/"+object)
+		c.expectTrue(url.Expires.Equal(expires))
+	}
+	c.expectEQ(resp.URLs[1].Headers, map[string]string{"Content-Type": "application/gzip"})
+
+	for _, upload := range []*dashapi.AssetUpload{
+		{Type: "unknown", Name: "disk.raw"},
+		{Type: dashapi.BootableDisk, Name: "../disk.raw"},
+	} {
+		_, err := c.makeClient(client1, password1, false).AssetUploadURLs(context.Background(),
+			&dashapi.AssetUploadReq{Assets: []*dashapi.AssetUpload{upload}})
+		c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+	}
+	// The namespace has no storage configured.
+	_, err = c.makeClient(client2, password2, false).AssetUploadURLs(context.Background(), &dashapi.AssetUploadReq{
+		Assets: []*dashapi.AssetUpload{{Type: dashapi.BootableDisk, Name: "disk.raw"}},
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -23,9 +24,9 @@ func TestBisectCause(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrash(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// No repro - no bisection.
@@ -34,13 +35,13 @@ func TestBisectCause(t *testing.T) {
 
 	// Now upload 4 crashes with repros.
 	crash2 := testCrashWithRepro(build, 2)
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	msg2 := c.client2.pollEmailBug()
 
 	// This is later, so will be bisected before the previous crash.
 	c.advanceTime(time.Hour)
 	crash3 := testCrashWithRepro(build, 3)
-	c.client2.ReportCrash(crash3)
+	c.client2.ReportCrash(context.Background(), crash3)
 	c.client2.pollEmailBug()
 
 	// This does not have C repro, so will be bisected after the previous ones.
@@ -48,15 +49,15 @@ func TestBisectCause(t *testing.T) {
 	crash4 := testCrashWithRepro(build, 4)
 	crash4.Title = "skip reporting2 with repro"
 	crash4.ReproC = nil
-	c.client2.ReportCrash(crash4)
+	c.client2.ReportCrash(context.Background(), crash4)
 	msg4 := c.client2.pollEmailBug()
 
 	// This is from a different manager, so won't be bisected.
 	c.advanceTime(time.Hour)
 	build2 := testBuild(2)
-	c.client2.UploadBuild(build2)
+	c.client2.UploadBuild(context.Background(), build2)
 	crash5 := testCrashWithRepro(build2, 5)
-	c.client2.ReportCrash(crash5)
+	c.client2.ReportCrash(context.Background(), crash5)
 	c.client2.pollEmailBug()
 
 	// When polling for jobs the expected order is as follows :=
@@ -88,7 +89,7 @@ func TestBisectCause(t *testing.T) {
 		Log:   []byte("bisect log 3"),
 		Error: []byte("bisect error 3"),
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 	c.expectNoEmail()
 
 	// BisectCause #2
@@ -125,7 +126,7 @@ func TestBisectCause(t *testing.T) {
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	_, extBugID, err := email.RemoveAddrContext(msg2.Sender)
 	c.expectOK(err)
@@ -296,7 +297,7 @@ If you want to undo deduplication, reply with:
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	{
 		msg := c.pollEmailBug()
@@ -337,7 +338,7 @@ If you want to undo deduplication, reply with:
 		Log:   []byte("bisect log 2"),
 		Error: []byte("bisect error 2"),
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// BisectFix #3
 	c.advanceTime(time.Minute)
@@ -350,7 +351,7 @@ If you want to undo deduplication, reply with:
 		Log:   []byte("bisect log 3"),
 		Error: []byte("bisect error 3"),
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// BisectFix #4
 	c.advanceTime(time.Minute)
@@ -384,7 +385,7 @@ If you want to undo deduplication, reply with:
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	_, extBugID, err = email.RemoveAddrContext(msg4.Sender)
 	c.expectOK(err)
@@ -446,9 +447,9 @@ func TestBisectCauseInconclusive(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	msg := c.client2.pollEmailBug()
 
 	pollResp := c.client2.pollJobs(build.Manager)
@@ -477,7 +478,7 @@ func TestBisectCauseInconclusive(t *testing.T) {
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -581,11 +582,11 @@ func TestUnreliableBisect(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	// Upload a crash that has only a syz repro.
 	crash := testCrashWithRepro(build, 1)
 	crash.ReproC = nil
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	_ = c.client2.pollEmailBug()
 
 	pollResp := c.client2.pollJobs(build.Manager)
@@ -607,14 +608,14 @@ func TestUnreliableBisect(t *testing.T) {
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// The bisection result is unreliable - it shouldn't be reported.
 	c.expectNoEmail()
 
 	// Upload a crash with a C repro.
 	crash2 := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 
 	// Make sure it doesn't mention bisection and doesn't include the emails from it.
 	msg := c.pollEmailBug()
@@ -635,7 +636,7 @@ func TestBisectWrong(t *testing.T) {
 	c.setNoObsoletions()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	for i := 0; i < 6; i++ {
 		var flags dashapi.JobDoneFlags
 		switch i {
@@ -656,7 +657,7 @@ func TestBisectWrong(t *testing.T) {
 		t.Logf("iteration %v: flags=%v", i, flags)
 
 		crash := testCrashWithRepro(build, i)
-		c.client2.ReportCrash(crash)
+		c.client2.ReportCrash(context.Background(), crash)
 		c.client2.pollEmailBug()
 
 		{
@@ -677,7 +678,7 @@ func TestBisectWrong(t *testing.T) {
 				},
 			}
 			done.Build.ID = pollResp.ID
-			c.expectOK(c.client2.JobDone(done))
+			c.expectOK(c.client2.JobDone(context.Background(), done))
 			if i == 0 {
 				msg := c.pollEmailBug()
 				c.expectTrue(strings.Contains(msg.Body, "syzbot has bisected this issue to:"))
@@ -707,7 +708,7 @@ func TestBisectWrong(t *testing.T) {
 				},
 			}
 			done.Build.ID = pollResp.ID
-			c.expectOK(c.client2.JobDone(done))
+			c.expectOK(c.client2.JobDone(context.Background(), done))
 			if i == 0 {
 				msg := c.pollEmailBug()
 				c.expectTrue(strings.Contains(msg.Body, "syzbot suspects this issue was fixed by commit:"))
@@ -744,9 +745,9 @@ func TestBisectCauseAncient(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	msg := c.client2.pollEmailBug()
 
 	pollResp := c.client2.pollJobs(build.Manager)
@@ -760,7 +761,7 @@ func TestBisectCauseAncient(t *testing.T) {
 		CrashReport: []byte("bisect crash report"),
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -866,9 +867,9 @@ func TestBisectCauseExternal(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep := c.client.pollBug()
 
 	pollResp := c.client.pollJobs(build.Manager)
@@ -890,9 +891,9 @@ func TestBisectCauseExternal(t *testing.T) {
 		},
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client.JobDone(done))
+	c.expectOK(c.client.JobDone(context.Background(), done))
 
-	resp, _ := c.client.ReportingPollBugs("test")
+	resp, _ := c.client.ReportingPollBugs(context.Background(), "test")
 	c.expectEQ(len(resp.Reports), 1)
 	// Still reported because we did not ack.
 	bisect := c.client.pollBug()
@@ -908,9 +909,9 @@ func TestBisectFixExternal(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep := c.client.pollBug()
 	{
 		// Cause bisection fails.
@@ -920,7 +921,7 @@ func TestBisectFixExternal(t *testing.T) {
 			Log:   []byte("bisect log"),
 			Error: []byte("bisect error"),
 		}
-		c.expectOK(c.client.JobDone(done))
+		c.expectOK(c.client.JobDone(context.Background(), done))
 	}
 	c.advanceTime(31 * 24 * time.Hour)
 	{
@@ -944,7 +945,7 @@ func TestBisectFixExternal(t *testing.T) {
 			},
 		}
 		done.Build.ID = pollResp.ID
-		c.expectOK(c.client.JobDone(done))
+		c.expectOK(c.client.JobDone(context.Background(), done))
 		rep := c.client.pollBug()
 		c.expectEQ(rep.Type, dashapi.ReportBisectFix)
 	}
@@ -962,10 +963,10 @@ func TestBisectCauseReproSyz(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
 	crash.ReproC = nil
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	pollResp := c.client2.pollJobs(build.Manager)
 	jobID := pollResp.ID
@@ -977,10 +978,10 @@ func TestBisectCauseReproSyz(t *testing.T) {
 		CrashLog:   []byte("bisect crash log"),
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	crash.ReproC = []byte("int main")
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	msg := c.client2.pollEmailBug()
 	if !strings.Contains(msg.Body, "syzbot found the following issue") {
@@ -996,10 +997,10 @@ func TestBisectCauseReproSyz2(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
 	crash.ReproC = nil
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	pollResp := c.client2.pollJobs(build.Manager)
 	jobID := pollResp.ID
@@ -1011,7 +1012,7 @@ func TestBisectCauseReproSyz2(t *testing.T) {
 		CrashLog:   []byte("bisect crash log"),
 	}
 	done.Build.ID = jobID
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	msg := c.client2.pollEmailBug()
 	if !strings.Contains(msg.Body, "syzbot found the following issue") {
@@ -1022,7 +1023,7 @@ func TestBisectCauseReproSyz2(t *testing.T) {
 	}
 
 	crash.ReproC = []byte("int main")
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	msg = c.client2.pollEmailBug()
 	if !strings.Contains(msg.Body, "syzbot has found a reproducer for the following issue") {
@@ -1143,9 +1144,9 @@ func TestBugBisectionInvalidation(t *testing.T) {
 // Upload a build, a crash report and poll bug emails.
 func addBuildAndCrash(c *Ctx) (*dashapi.Build, *dashapi.Crash) {
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	c.advanceTime(30 * 24 * time.Hour)
@@ -1187,7 +1188,7 @@ func addBisectCauseJob(c *Ctx, build *dashapi.Build) (*dashapi.JobPollResp, *das
 			},
 		},
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	c.advanceTime(24 * time.Hour)
 	msg := c.client2.pollEmailBug()
@@ -1226,7 +1227,7 @@ func addBisectFixJob(c *Ctx, build *dashapi.Build) (*dashapi.JobPollResp, *dasha
 			},
 		},
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 	msg := c.client2.pollEmailBug()
 	c.expectTrue(strings.Contains(msg.Body, "syzbot suspects this issue was fixed by commit:"))
 
@@ -1238,3 +1239,74 @@ func addBisectFixJob(c *Ctx, build *dashapi.Build) (*dashapi.JobPollResp, *dasha
 	c.expectTrue(len(dbBug.Commits) == 0)
 	return resp, done, jobID
 }
+
+func TestQueueBisect(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client2.UploadBuild(context.Background(), build)
+	c.client2.ReportCrash(context.Background(), testCrash(build, 1))
+	c.client2.pollEmailBug()
+
+	listResp, err := c.client2.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(listResp.List), 1)
+	bugID := listResp.List[0]
+
+	// Unknown bugs and bugs of other namespaces are not found.
+	_, err = c.makeClient(client2, password2, false).QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID: "foobar",
+		Type:  dashapi.JobBisectCause,
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	_, err = c.makeClient(client1, password1, false).QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID: bugID,
+		Type:  dashapi.JobBisectCause,
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+
+	// No repro - no bisection.
+	resp, err := c.client2.QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID: bugID,
+		Type:  dashapi.JobBisectCause,
+	})
+	c.expectOK(err)
+	c.expectEQ(resp.JobID, "")
+	c.expectEQ(resp.Refusal, dashapi.BisectRefusedNoRepro)
+
+	c.client2.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
+
+	// The repro is there, but not on the requested manager.
+	resp, err = c.client2.QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID:   bugID,
+		Type:    dashapi.JobBisectCause,
+		Manager: "other-manager",
+	})
+	c.expectOK(err)
+	c.expectEQ(resp.Refusal, dashapi.BisectRefusedNoRepro)
+
+	resp, err = c.client2.QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID:   bugID,
+		Type:    dashapi.JobBisectCause,
+		Manager: build.Manager,
+	})
+	c.expectOK(err)
+	c.expectNE(resp.JobID, "")
+	c.expectEQ(resp.Refusal, dashapi.BisectRefusal(""))
+	jobID := resp.JobID
+
+	// The second request is refused.
+	resp, err = c.client2.QueueBisect(context.Background(), &dashapi.QueueBisectReq{
+		BugID: bugID,
+		Type:  dashapi.JobBisectCause,
+	})
+	c.expectOK(err)
+	c.expectEQ(resp.JobID, "")
+	c.expectEQ(resp.Refusal, dashapi.BisectRefusedDone)
+
+	// The queued job is handed out right away.
+	pollResp := c.client2.pollJobs(build.Manager)
+	c.expectEQ(pollResp.ID, jobID)
+	c.expectEQ(pollResp.Type, dashapi.JobBisectCause)
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+// This file implements download URLs for large API reply blobs (see dashapi.PreferURLs).
+// The URLs are signed with the namespace key and expire after blobURLExpiration.
+
+const (
+	blobURLExpiration = 15 * time.Minute
+	// Smaller blobs are always returned inline.
+	minBlobURLSize = 16 << 10
+)
+
+type replyBlob struct {
+	field string
+	tag   string
+	id    int64
+	data  *[]byte
+}
+
+// blobRefs replaces large blobs in the reply with download URLs.
+func blobRefs(c context.Context, ns string, blobs []replyBlob) []dashapi.BlobRef {
+	var refs []dashapi.BlobRef
+	for _, blob := range blobs {
+		if blob.id == 0 || len(*blob.data) < minBlobURLSize {
+			continue
+		}
+		sum := sha256.Sum256(*blob.data)
+		refs = append(refs, dashapi.BlobRef{
+			Field:  blob.field,
+			URL:    blobURL(c, ns, blob.tag, blob.id),
+			Size:   int64(len(*blob.data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+		*blob.data = nil
+	}
+	return refs
+}
+
+func blobURL(c context.Context, ns, tag string, id int64) string {
+	expires := timeNow(c).Add(blobURLExpiration).Unix()
+	params := url.Values{}
+	params.Set("ns", ns)
+	params.Set("tag", tag)
+	params.Set("x", strconv.FormatInt(id, 16))
+	params.Set("expires", strconv.FormatInt(expires, 10))
+	params.Set("sig", blobSignature(c, ns, tag, id, expires))
+	return "/api/blob?" + params.Encode()
+}
+
+func blobSignature(c context.Context, ns, tag string, id, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(getNsConfig(c, ns).Key))
+	fmt.Fprintf(mac, "%v/%v/%x/%v", ns, tag, id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func handleBlob(c context.Context, w http.ResponseWriter, r *http.Request) error {
+	ns := r.FormValue("ns")
+	tag := r.FormValue("tag")
+	if getNsConfig(c, ns) == nil || !isBlobTag(tag) {
+		return fmt.Errorf("%w: unknown blob", ErrClientBadRequest)
+	}
+	id, err := strconv.ParseInt(r.FormValue("x"), 16, 64)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse blob id: %w", ErrClientBadRequest, err)
+	}
+	expires, err := strconv.ParseInt(r.FormValue("expires"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse blob expiration: %w", ErrClientBadRequest, err)
+	}
+	if !hmac.Equal([]byte(r.FormValue("sig")), []byte(blobSignature(c, ns, tag, id, expires))) {
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+		return nil
+	}
+	if timeNow(c).Unix() > expires {
+		http.Error(w, "the URL has expired", http.StatusGone)
+		return nil
+	}
+	data, textNs, err := getText(c, tag, id)
+	if err != nil {
+		return err
+	}
+	if textNs != ns {
+		return fmt.Errorf("%w: unknown blob", ErrClientNotFound)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+	return nil
+}
+
+func isBlobTag(tag string) bool {
+	switch tag {
+	case textCrashLog, textCrashReport, textReproSyz, textReproC, textKernelConfig, textMachineInfo:
+		return true
+	}
+	return false
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
@@ -16,26 +17,26 @@ func TestCachedBugGroups(t *testing.T) {
 
 	client := c.makeClient(clientPublic, keyPublic, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Bug at the first (AccessUser) stage of reporting.
 	crash := testCrash(build, 1)
 	crash.Title = "user-visible bug"
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollBug()
 
 	// Bug at the second (AccessPublic) stage.
 	crash2 := testCrash(build, 2)
 	crash2.Title = "public-visible bug"
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	client.updateBug(client.pollBug().ID, dashapi.BugStatusUpstream, "")
 	client.pollBug()
 
 	// Add a build in a separate namespace (to check it's not mixed in).
 	client2 := c.makeClient(clientPublicEmail2, keyPublicEmail2, true)
 	build2 := testBuild(2)
-	client2.UploadBuild(build2)
-	client2.ReportCrash(testCrash(build2, 1))
+	client2.UploadBuild(context.Background(), build2)
+	client2.ReportCrash(context.Background(), testCrash(build2, 1))
 	client2.pollEmailBug()
 
 	// Output before caching.
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"sort"
 	"testing"
 
@@ -15,18 +16,18 @@ func TestCommitPoll(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 
 	crash2 := testCrash(build1, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep2 := c.client.pollBug()
 
 	// No commits in commit poll.
-	commitPollResp, err := c.client.CommitPoll()
+	commitPollResp, err := c.client.CommitPoll(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(commitPollResp.Repos), 2)
 	c.expectEQ(commitPollResp.Repos[0].URL, testConfig.Namespaces["test1"].Repos[0].URL)
@@ -36,7 +37,7 @@ func TestCommitPoll(t *testing.T) {
 	c.expectEQ(len(commitPollResp.Commits), 0)
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep1.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix1", "foo: fix2"},
@@ -45,7 +46,7 @@ func TestCommitPoll(t *testing.T) {
 
 	// The commit should appear in commit poll.
 	for i := 0; i < 2; i++ {
-		commitPollResp, err = c.client.CommitPoll()
+		commitPollResp, err = c.client.CommitPoll(context.Background())
 		c.expectOK(err)
 		c.expectEQ(len(commitPollResp.Commits), 2)
 		sort.Strings(commitPollResp.Commits)
@@ -54,14 +55,14 @@ func TestCommitPoll(t *testing.T) {
 	}
 
 	// Upload hash for the first commit and fixing commit for the second bug.
-	c.expectOK(c.client.UploadCommits([]dashapi.Commit{
+	c.expectOK(c.client.UploadCommits(context.Background(), []dashapi.Commit{
 		{Hash: "hash1", Title: "foo: fix1"},
 		{Hash: "hash2", Title: "bar: fix3", BugIDs: []string{rep2.ID}},
 		{Hash: "hash3", Title: "some unrelated commit", BugIDs: []string{"does not exist"}},
 		{Hash: "hash4", Title: "another unrelated commit"},
 	}))
 
-	commitPollResp, err = c.client.CommitPoll()
+	commitPollResp, err = c.client.CommitPoll(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(commitPollResp.Commits), 2)
 	sort.Strings(commitPollResp.Commits)
@@ -69,24 +70,24 @@ func TestCommitPoll(t *testing.T) {
 	c.expectEQ(commitPollResp.Commits[1], "foo: fix2")
 
 	// Upload hash for the second commit and a new fixing commit for the second bug.
-	c.expectOK(c.client.UploadCommits([]dashapi.Commit{
+	c.expectOK(c.client.UploadCommits(context.Background(), []dashapi.Commit{
 		{Hash: "hash5", Title: "foo: fix2"},
 		{Title: "bar: fix4", BugIDs: []string{rep2.ID}},
 	}))
 
-	commitPollResp, err = c.client.CommitPoll()
+	commitPollResp, err = c.client.CommitPoll(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(commitPollResp.Commits), 1)
 	c.expectEQ(commitPollResp.Commits[0], "bar: fix4")
 
 	// Upload hash for the second commit and a new fixing commit for the second bug.
-	c.expectOK(c.client.UploadCommits([]dashapi.Commit{
+	c.expectOK(c.client.UploadCommits(context.Background(), []dashapi.Commit{
 		{Hash: "hash1", Title: "foo: fix1"},
 		{Hash: "hash5", Title: "foo: fix2"},
 		{Hash: "hash6", Title: "bar: fix4"},
 	}))
 
-	commitPollResp, err = c.client.CommitPoll()
+	commitPollResp, err = c.client.CommitPoll(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(commitPollResp.Commits), 0)
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/google/syzkaller/pkg/subsystem"
 	"github.com/google/syzkaller/pkg/validator"
 	"github.com/google/syzkaller/pkg/vcs"
+	"github.com/google/syzkaller/sys/targets"
 )
 
 // There are multiple configurable aspects of the app (namespaces, reporting, API clients, etc).
@@ -81,6 +82,9 @@ type Config struct {
 	// Per-namespace clients that act only on a particular namespace.
 	// The keys are client identities (names), the values are their passwords.
 	Clients map[string]string
+	// Per-namespace clients that may only call the read-only API methods (see apiReadOnlyMethods),
+	// e.g. CI systems that download reproducers. The format is the same as for Clients.
+	ReadOnlyClients map[string]string
 	// A random string used for hashing, can be anything, but once fixed it can't
 	// be changed as it becomes a part of persistent bug identifiers.
 	Key string
@@ -101,6 +105,17 @@ type Config struct {
 	FindBugOriginTrees bool
 	// Managers contains some special additional info about syz-manager instances.
 	Managers map[string]ConfigManager
+	// ManagerOverrides are pushed to all managers in the namespace.
+	ManagerOverrides ManagerOverrides
+	// OSes the namespace accepts builds for (e.g. targets.Linux), builds for any OS are accepted if empty.
+	OSes []string
+	// GCS bucket (optionally followed by a path) that clients can upload large assets to
+	// via signed URLs (see dashapi.AssetUploadURLs). If empty, such uploads are not accepted.
+	AssetUploadBucket string
+	// If set, clients of the namespace encrypt crash logs and reports, reproducers and kernel configs
+	// (see dashapi.PayloadKeys). The dashboard keeps them opaque: it doesn't parse or amend them,
+	// and the reports only link them.
+	EncryptedPayloads bool
 	// Reporting config.
 	Reporting []Reporting
 	// TransformCrash hook is called when a manager uploads a crash.
@@ -154,6 +169,9 @@ type SubsystemsConfig struct {
 	Reminder *BugListReportingConfig
 	// Maps old subsystem names to new ones.
 	Redirect map[string]string
+	// If set, maintainers of the subsystems the crash guilty file belongs to are added
+	// to the crash maintainers, so that managers don't need to compute them.
+	GuiltyFileMaintainers bool
 }
 
 // BugListReportingConfig describes how aggregated reminders about open bugs should be processed.
@@ -228,6 +246,22 @@ type ConfigManager struct {
 	// Other parameters being equal, Priority helps to order bug's crashes.
 	// Priority is an integer in the range [-3;3].
 	Priority int
+	// Overrides are combined with the namespace ManagerOverrides.
+	Overrides ManagerOverrides
+}
+
+// ManagerOverrides are manager settings that managers periodically poll
+// from the dashboard (see dashapi.PollManagerConfig), so that they can be changed
+// for many managers at once without editing and restarting them.
+type ManagerOverrides struct {
+	// Syscalls are disabled when managers start, changes take effect after a restart.
+	DisabledSyscalls []string
+	// Regexps of crash titles that managers should treat as suppressed.
+	SuppressedTitles []string
+	// Maximum number of local repro attempts per crash title (0 means the manager default).
+	MaxReproAttempts int
+	// Managers don't report crashes to the dashboard while reporting is paused.
+	ReportingPaused bool
 }
 
 const (
@@ -293,6 +327,9 @@ type KernelRepo struct {
 	DetectMissingBackports bool
 	// Append this string to the config file before running reproducers on this tree.
 	AppendConfig string
+	// OS and Arch are optional hints for CI instances which of them the tree is built for.
+	OS   string
+	Arch string
 }
 
 type KernelRepoLink struct {
@@ -506,10 +543,23 @@ func checkNamespace(ns string, cfg *Config, namespaces, clientNames map[string]b
 	if cfg.SimilarityDomain == "" {
 		cfg.SimilarityDomain = ns
 	}
-	checkClients(clientNames, cfg.Clients)
+	// The same client may work in several namespaces (see dashapi.Namespace),
+	// but namespace clients can't reuse names of global clients.
+	nsClientNames := make(map[string]bool)
+	for name := range clientNames {
+		nsClientNames[name] = true
+	}
+	checkClients(nsClientNames, cfg.Clients)
+	checkClients(nsClientNames, cfg.ReadOnlyClients)
 	for name, mgr := range cfg.Managers {
 		checkManager(ns, name, mgr)
 	}
+	checkManagerOverrides(fmt.Sprintf("namespace %q", ns), &cfg.ManagerOverrides)
+	for _, os := range cfg.OSes {
+		if targets.List[os] == nil {
+			panic(fmt.Sprintf("namespace %q: unknown OS %q", ns, os))
+		}
+	}
 	if !validator.DashClientKey(cfg.Key).Ok {
 		panic(fmt.Sprintf("bad namespace %q key: %q", ns, cfg.Key))
 	}
@@ -714,6 +764,18 @@ func checkManager(ns, name string, mgr ConfigManager) {
 			ns, name, MinManagerPriority, MaxManagerPriority))
 	}
 	checkCC(&mgr.CC)
+	checkManagerOverrides(fmt.Sprintf("manager %v/%v", ns, name), &mgr.Overrides)
+}
+
+func checkManagerOverrides(where string, overrides *ManagerOverrides) {
+	for _, re := range overrides.SuppressedTitles {
+		if _, err := regexp.Compile(re); err != nil {
+			panic(fmt.Sprintf("%v: bad suppressed title %q: %v", where, re, err))
+		}
+	}
+	if overrides.MaxReproAttempts < 0 {
+		panic(fmt.Sprintf("%v: negative MaxReproAttempts", where))
+	}
 }
 
 func checkKcidb(ns string, kcidb *KcidbConfig) {
@@ -757,6 +819,15 @@ func checkClients(clientNames map[string]bool, clients map[string]string) {
 	}
 }
 
+// clientKey returns the key of the namespace client of any class.
+func (cfg *Config) clientKey(name string) (string, bool) {
+	if key, ok := cfg.Clients[name]; ok {
+		return key, true
+	}
+	key, ok := cfg.ReadOnlyClients[name]
+	return key, ok
+}
+
 func (cfg *Config) lastActiveReporting() int {
 	last := len(cfg.Reporting) - 1
 	for last > 0 && cfg.Reporting[last].DailyLimit == 0 {
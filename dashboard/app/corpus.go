@@ -0,0 +1,191 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	db "google.golang.org/appengine/v2/datastore"
+)
+
+// This file implements corpus synchronization between managers of a namespace (see dashapi.UploadCorpus).
+
+const (
+	maxCorpusProgSize     = 64 << 10
+	defaultCorpusDownload = 100
+	maxCorpusDownload     = 1000
+)
+
+func apiUploadCorpus(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UploadCorpusReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" {
+		return nil, fmt.Errorf("%w: empty manager", ErrClientBadRequest)
+	}
+	if len(req.Add) > dashapi.MaxCorpusUpload || len(req.Del) > dashapi.MaxCorpusUpload {
+		return nil, fmt.Errorf("%w: too many programs (%v/%v)", ErrClientBadRequest, len(req.Add), len(req.Del))
+	}
+	for _, prog := range req.Add {
+		if len(prog.Prog) > maxCorpusProgSize {
+			return nil, fmt.Errorf("%w: too large program %v (%v)", ErrClientBadRequest, prog.Hash, len(prog.Prog))
+		}
+		if len(prog.Prog) != 0 && dashapi.CorpusProgHash(prog.Prog) != prog.Hash || prog.Hash == "" {
+			return nil, fmt.Errorf("%w: wrong program hash %q", ErrClientBadRequest, prog.Hash)
+		}
+	}
+	now := timeNow(c)
+	resp := new(dashapi.UploadCorpusResp)
+	for _, prog := range req.Add {
+		missing, err := addCorpusProg(c, ns, req.Manager, prog, now)
+		if err != nil {
+			return nil, err
+		}
+		if missing {
+			resp.Missing = append(resp.Missing, prog.Hash)
+		}
+	}
+	for _, hash := range req.Del {
+		if err := removeCorpusProg(c, ns, req.Manager, hash); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// addCorpusProg adds the program to the manager corpus, it returns true if the program
+// was sent by hash, but the dashboard does not have it.
+func addCorpusProg(c context.Context, ns, manager string, prog *dashapi.CorpusProg, now time.Time) (bool, error) {
+	missing := false
+	tx := func(c context.Context) error {
+		missing = false
+		key := corpusProgKey(c, ns, prog.Hash)
+		ent := new(CorpusProg)
+		if err := db.Get(c, key, ent); err != nil {
+			if err != db.ErrNoSuchEntity {
+				return fmt.Errorf("failed to get corpus program: %w", err)
+			}
+			if len(prog.Prog) == 0 {
+				missing = true
+				return nil
+			}
+			ent = &CorpusProg{
+				Namespace: ns,
+				Hash:      prog.Hash,
+				Prog:      prog.Prog,
+				Manager:   manager,
+				Added:     now,
+			}
+		} else if slices.Contains(ent.Managers, manager) && (ent.Minimized || !prog.Minimized) {
+			return nil
+		}
+		ent.Minimized = ent.Minimized || prog.Minimized
+		if !slices.Contains(ent.Managers, manager) {
+			ent.Managers = append(ent.Managers, manager)
+		}
+		if _, err := db.Put(c, key, ent); err != nil {
+			return fmt.Errorf("failed to put corpus program: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		return false, err
+	}
+	return missing, nil
+}
+
+// removeCorpusProg removes the program from the manager corpus,
+// the program is deleted once it's not in the corpus of any manager.
+func removeCorpusProg(c context.Context, ns, manager, hash string) error {
+	tx := func(c context.Context) error {
+		key := corpusProgKey(c, ns, hash)
+		ent := new(CorpusProg)
+		if err := db.Get(c, key, ent); err != nil {
+			if err == db.ErrNoSuchEntity {
+				return nil
+			}
+			return fmt.Errorf("failed to get corpus program: %w", err)
+		}
+		idx := slices.Index(ent.Managers, manager)
+		if idx == -1 {
+			return nil
+		}
+		ent.Managers = slices.Delete(ent.Managers, idx, idx+1)
+		if len(ent.Managers) == 0 {
+			if err := db.Delete(c, key); err != nil {
+				return fmt.Errorf("failed to delete corpus program: %w", err)
+			}
+			return nil
+		}
+		if _, err := db.Put(c, key, ent); err != nil {
+			return fmt.Errorf("failed to put corpus program: %w", err)
+		}
+		return nil
+	}
+	return db.RunInTransaction(c, tx, nil)
+}
+
+func apiDownloadCorpus(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.DownloadCorpusReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	limit := req.Max
+	if limit <= 0 {
+		limit = defaultCorpusDownload
+	}
+	limit = min(limit, maxCorpusDownload)
+	query := db.NewQuery("CorpusProg").
+		Filter("Namespace=", ns).
+		Order("Added")
+	if req.Cursor != "" {
+		cursor, err := db.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad cursor: %w", ErrClientBadRequest, err)
+		}
+		query = query.Start(cursor)
+	}
+	resp := new(dashapi.DownloadCorpusResp)
+	iter := query.Run(c)
+	// Programs the manager already has are skipped, but they count towards the limit
+	// so that a single request does not scan the whole corpus.
+	for i := 0; ; i++ {
+		if i == limit {
+			resp.More = true
+			break
+		}
+		ent := new(CorpusProg)
+		if _, err := iter.Next(ent); err != nil {
+			if err == db.Done {
+				break
+			}
+			return nil, fmt.Errorf("failed to fetch corpus programs: %w", err)
+		}
+		if slices.Contains(ent.Managers, req.Manager) {
+			continue
+		}
+		resp.Progs = append(resp.Progs, &dashapi.CorpusProg{
+			Hash:      ent.Hash,
+			Prog:      ent.Prog,
+			Minimized: ent.Minimized,
+			Manager:   ent.Manager,
+		})
+	}
+	cursor, err := iter.Cursor()
+	if err != nil {
+		return nil, fmt.Errorf("cursor failed while fetching corpus programs: %w", err)
+	}
+	resp.Cursor = cursor.String()
+	return resp, nil
+}
+
+func corpusProgKey(c context.Context, ns, hash string) *db.Key {
+	return db.NewKey(c, "CorpusProg", ns+"|"+hash, 0, nil)
+}
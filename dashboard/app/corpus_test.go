@@ -0,0 +1,72 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+func TestCorpusSync(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	var progs []*dashapi.CorpusProg
+	for i := 0; i < 5; i++ {
+		progs = append(progs, &dashapi.CorpusProg{Prog: []byte(fmt.Sprintf("getpid%v()", i))})
+	}
+	c.expectOK(c.client.UploadCorpus(context.Background(), &dashapi.UploadCorpusReq{
+		Manager: "mgr1",
+		Add:     progs,
+	}))
+	// The second manager has one of the programs, so it's added by hash.
+	c.expectOK(c.client.UploadCorpus(context.Background(), &dashapi.UploadCorpusReq{
+		Manager: "mgr2",
+		Add:     []*dashapi.CorpusProg{progs[0], {Prog: []byte("getuid()"), Minimized: true}},
+	}))
+
+	download := func(client *apiClient, manager string) []string {
+		var res []string
+		for cursor := ""; ; {
+			resp, err := client.DownloadCorpus(context.Background(), &dashapi.DownloadCorpusReq{
+				Manager: manager,
+				Cursor:  cursor,
+				Max:     2,
+			})
+			c.expectOK(err)
+			for _, prog := range resp.Progs {
+				res = append(res, string(prog.Prog))
+			}
+			if !resp.More {
+				// Programs uploaded at the same time are returned in no particular order.
+				sort.Strings(res)
+				return res
+			}
+			cursor = resp.Cursor
+		}
+	}
+	c.expectEQ(download(c.client, "mgr2"), []string{"getpid1()", "getpid2()", "getpid3()", "getpid4()"})
+	c.expectEQ(download(c.client, "mgr1"), []string{"getuid()"})
+	// Programs of other namespaces are not visible.
+	c.expectEQ(len(download(c.client2, "mgr3")), 0)
+
+	// Programs are deleted once they are removed from the corpus of all managers.
+	c.expectOK(c.client.UploadCorpus(context.Background(), &dashapi.UploadCorpusReq{
+		Manager: "mgr1",
+		Del:     []string{dashapi.CorpusProgHash(progs[0].Prog), dashapi.CorpusProgHash(progs[1].Prog)},
+	}))
+	c.expectEQ(download(c.client, "mgr3"),
+		[]string{"getpid0()", "getpid2()", "getpid3()", "getpid4()", "getuid()"})
+
+	err := c.makeClient(client1, password1, false).Query(context.Background(), "upload_corpus", &dashapi.UploadCorpusReq{
+		Manager: "mgr1",
+		Add:     []*dashapi.CorpusProg{{Hash: "wrong", Prog: []byte("getpid()")}},
+	}, nil)
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"google.golang.org/appengine/v2"
+	db "google.golang.org/appengine/v2/datastore"
+	"google.golang.org/appengine/v2/log"
+)
+
+// This file implements content-addressed deduplication of large uploaded texts (see dashapi.BlobDedup).
+// putText remembers the hashes of large kernel configs and crash logs, so that clients can refer
+// to them by hash instead of uploading them again.
+
+// Smaller texts are not remembered, they are cheaper to upload than to look up.
+const minDedupBlobSize = 16 << 10
+
+var dedupBlobFor = map[string]bool{
+	textKernelConfig: true,
+	textCrashLog:     true,
+}
+
+func apiHasBlobs(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.HasBlobsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	const maxHashes = 100
+	if len(req.Hashes) > maxHashes {
+		return nil, fmt.Errorf("%w: too many hashes (%v)", ErrClientBadRequest, len(req.Hashes))
+	}
+	var keys []*db.Key
+	for _, hash := range req.Hashes {
+		keys = append(keys, blobHashKey(c, ns, hash))
+	}
+	hashes := make([]*BlobHash, len(keys))
+	err := db.GetMulti(c, keys, hashes)
+	var errs appengine.MultiError
+	if err != nil && !errors.As(err, &errs) {
+		return nil, fmt.Errorf("failed to get blob hashes: %w", err)
+	}
+	resp := new(dashapi.HasBlobsResp)
+	for i, hash := range req.Hashes {
+		if errs != nil && errs[i] != nil {
+			if !errors.Is(errs[i], db.ErrNoSuchEntity) {
+				return nil, fmt.Errorf("failed to get blob hash: %w", errs[i])
+			}
+			continue
+		}
+		resp.Known = append(resp.Known, hash)
+	}
+	return resp, nil
+}
+
+// resolveBlobHashes fills in the request fields that were sent as hashes.
+func resolveBlobHashes(c context.Context, ns string, hashes []dashapi.BlobHash, fields map[string]*[]byte) error {
+	for _, hash := range hashes {
+		field := fields[hash.Field]
+		if field == nil {
+			return fmt.Errorf("%w: blob hash for unknown field %q", ErrClientBadRequest, hash.Field)
+		}
+		blob := new(BlobHash)
+		if err := db.Get(c, blobHashKey(c, ns, hash.SHA256), blob); err != nil {
+			if errors.Is(err, db.ErrNoSuchEntity) {
+				return fmt.Errorf("%w: unknown blob %v", ErrClientNotFound, hash.SHA256)
+			}
+			return fmt.Errorf("failed to get blob hash: %w", err)
+		}
+		data, _, err := getText(c, blob.Tag, blob.TextID)
+		if err != nil {
+			if errors.Is(err, db.ErrNoSuchEntity) {
+				return fmt.Errorf("%w: unknown blob %v", ErrClientNotFound, hash.SHA256)
+			}
+			return err
+		}
+		*field = data
+	}
+	return nil
+}
+
+// saveBlobHash remembers the hash of the text data stored as tag/id.
+func saveBlobHash(c context.Context, ns, tag string, id int64, data []byte) {
+	if !dedupBlobFor[tag] || len(data) < minDedupBlobSize {
+		return
+	}
+	sum := sha256.Sum256(data)
+	blob := &BlobHash{
+		Namespace: ns,
+		Tag:       tag,
+		TextID:    id,
+	}
+	if _, err := db.Put(c, blobHashKey(c, ns, hex.EncodeToString(sum[:])), blob); err != nil {
+		// The text is stored, so don't fail the request, the client will upload the text again next time.
+		log.Errorf(c, "failed to save blob hash: %v", err)
+	}
+}
+
+func blobHashKey(c context.Context, ns, hash string) *db.Key {
+	return db.NewKey(c, "BlobHash", ns+"|"+hash, 0, nil)
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -21,16 +22,16 @@ func TestDiscussionAccess(t *testing.T) {
 	client := c.makeClient(clientPublic, keyPublic, true)
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Bug at the first (AccesUser) stage of reporting.
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep1 := client.pollBug()
 
 	// Bug at the second (AccessPublic) stage.
 	crash2 := testCrash(build, 2)
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	rep2user := client.pollBug()
 	client.updateBug(rep2user.ID, dashapi.BugStatusUpstream, "")
 	rep2 := client.pollBug()
@@ -38,7 +39,7 @@ func TestDiscussionAccess(t *testing.T) {
 	// Patch to both bugs.
 	firstTime := timeNow(c.ctx)
 	c.advanceTime(time.Hour)
-	c.expectOK(client.SaveDiscussion(&dashapi.SaveDiscussionReq{
+	c.expectOK(client.SaveDiscussion(context.Background(), &dashapi.SaveDiscussionReq{
 		Discussion: &dashapi.Discussion{
 			ID:      "123",
 			Source:  dashapi.DiscussionLore,
@@ -58,7 +59,7 @@ func TestDiscussionAccess(t *testing.T) {
 	// Discussion about the second bug.
 	secondTime := timeNow(c.ctx)
 	c.advanceTime(time.Hour)
-	c.expectOK(client.SaveDiscussion(&dashapi.SaveDiscussionReq{
+	c.expectOK(client.SaveDiscussion(context.Background(), &dashapi.SaveDiscussionReq{
 		Discussion: &dashapi.Discussion{
 			ID:      "456",
 			Source:  dashapi.DiscussionLore,
@@ -137,10 +138,10 @@ func TestEmailOwnDiscussions(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	msg := client.pollEmailBug()
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -215,10 +216,10 @@ func TestEmailUnrelatedDiscussion(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	msg := client.pollEmailBug()
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -254,10 +255,10 @@ func TestEmailSubdiscussion(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	msg := client.pollEmailBug()
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -292,10 +293,10 @@ func TestEmailPatchWithLink(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	msg := client.pollEmailBug()
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -332,10 +333,10 @@ func TestIgnoreBotReplies(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	msg := client.pollEmailBug()
 	_, extBugID, err := email.RemoveAddrContext(msg.Sender)
 	c.expectOK(err)
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -22,11 +23,11 @@ func TestEmailReport(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{`"Foo Bar" <foo@bar.com>`, `bar@foo.com`, `idont@want.EMAILS`}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	// Report the crash over email and check all fields.
 	var sender0, extBugID0, body0 string
@@ -141,13 +142,13 @@ For more options, visit https://groups.google.com/d/optout.
 	build2.Arch = targets.I386
 	build2.KernelRepo, build2.KernelBranch = testConfig.Namespaces["test2"].mainRepoBranch()
 	build2.KernelCommitTitle = "a really long title, longer than 80 chars, really long-long-long-long-long-long title"
-	c.client2.UploadBuild(build2)
+	c.client2.UploadBuild(context.Background(), build2)
 	crash.BuildID = build2.ID
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("getpid()")
 	syzRepro := []byte(fmt.Sprintf("# https://testapp.appspot.com/bug?id=%v\n%s#%s\n%s",
 		dbBug0.keyHash(c.ctx), syzReproPrefix, crash.ReproOpts, crash.ReproSyz))
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	{
 		msg := c.pollEmailBug()
@@ -293,7 +294,7 @@ Content-Type: text/plain
 	// Now upload a C reproducer.
 	crash.ReproC = []byte("int main() {}")
 	crash.Maintainers = []string{"\"qux\" <qux@qux.com>"}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	cRepro := []byte(fmt.Sprintf("// https://testapp.appspot.com/bug?id=%v\n%s",
 		dbBug0.keyHash(c.ctx), crash.ReproC))
 
@@ -378,22 +379,22 @@ unknown command "bad-command"
 		EmailOptSubject("fix bug title"))
 
 	// Check that the commit is now passed to builders.
-	builderPollResp, _ := c.client2.BuilderPoll(build.Manager)
+	builderPollResp, _ := c.client2.BuilderPoll(context.Background(), build.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "some: commit title")
 
 	build3 := testBuild(3)
 	build3.Manager = build.Manager
 	build3.Commits = []string{"some: commit title"}
-	c.client2.UploadBuild(build3)
+	c.client2.UploadBuild(context.Background(), build3)
 
 	build4 := testBuild(4)
 	build4.Manager = build2.Manager
 	build4.Commits = []string{"some: commit title"}
-	c.client2.UploadBuild(build4)
+	c.client2.UploadBuild(context.Background(), build4)
 
 	// New crash must produce new bug in the first reporting.
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	{
 		msg := c.pollEmailBug()
 		c.expectEQ(msg.Subject, crash.Title+" (2)")
@@ -407,10 +408,10 @@ func TestEmailNoMaintainers(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 
@@ -434,15 +435,15 @@ func TestEmailDup(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "BUG: slightly more elaborate title"
-	c.client2.ReportCrash(crash1)
+	c.client2.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
 	crash2.Title = "KASAN: another title"
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 
 	msg1 := c.pollEmailBug()
 	msg2 := c.pollEmailBug()
@@ -453,7 +454,7 @@ func TestEmailDup(t *testing.T) {
 
 	// Second crash happens again.
 	crash2.ReproC = []byte("int main() {}")
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	c.expectNoEmail()
 
 	// Now close the original bug, and check that new bugs for dup are now created.
@@ -464,7 +465,7 @@ func TestEmailDup(t *testing.T) {
 	c.expectNoEmail()
 
 	// New crash must produce new bug in the first reporting.
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	{
 		msg := c.pollEmailBug()
 		c.expectEQ(msg.Subject, crash2.Title+" (2)")
@@ -478,11 +479,11 @@ func TestEmailDup2(t *testing.T) {
 			c := NewCtx(t)
 			defer c.Close()
 			build := testBuild(1)
-			c.client2.UploadBuild(build)
+			c.client2.UploadBuild(context.Background(), build)
 
 			crash1 := testCrash(build, 1)
 			crash1.Title = "BUG: something bad"
-			c.client2.ReportCrash(crash1)
+			c.client2.ReportCrash(context.Background(), crash1)
 			msg1 := c.pollEmailBug()
 			c.incomingEmail(msg1.Sender, "#syz upstream")
 			msg1 = c.pollEmailBug()
@@ -490,7 +491,7 @@ func TestEmailDup2(t *testing.T) {
 
 			crash2 := testCrash(build, 2)
 			crash2.Title = "KASAN: another bad thing"
-			c.client2.ReportCrash(crash2)
+			c.client2.ReportCrash(context.Background(), crash2)
 			msg2 := c.pollEmailBug()
 			c.incomingEmail(msg2.Sender, "#syz upstream")
 			msg2 = c.pollEmailBug()
@@ -518,15 +519,15 @@ func TestEmailUndup(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "BUG: slightly more elaborate title"
-	c.client2.ReportCrash(crash1)
+	c.client2.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
 	crash1.Title = "KASAN: another title"
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 
 	msg1 := c.pollEmailBug()
 	msg2 := c.pollEmailBug()
@@ -541,7 +542,7 @@ func TestEmailUndup(t *testing.T) {
 
 	// Now close the original bug, and check that new crashes for the dup does not create bugs.
 	c.incomingEmail(msg1.Sender, "#syz invalid")
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	c.expectNoEmail()
 }
 
@@ -550,7 +551,7 @@ func TestEmailCrossReportingDup(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	tests := []struct {
 		bug    int
@@ -572,7 +573,7 @@ func TestEmailCrossReportingDup(t *testing.T) {
 		c.advanceTime(24 * time.Hour) // to not hit email limit per day
 		crash1 := testCrash(build, 1)
 		crash1.Title = fmt.Sprintf("bug_%v", i)
-		c.client2.ReportCrash(crash1)
+		c.client2.ReportCrash(context.Background(), crash1)
 		bugSender := c.pollEmailBug().Sender
 		cc := EmailOptCC([]string{"default@maintainers.com", "test@syzkaller.com",
 			"bugs@syzkaller.com", "default2@maintainers.com", "bugs2@syzkaller.com"})
@@ -583,7 +584,7 @@ func TestEmailCrossReportingDup(t *testing.T) {
 
 		crash2 := testCrash(build, 2)
 		crash2.Title = fmt.Sprintf("dup_%v", i)
-		c.client2.ReportCrash(crash2)
+		c.client2.ReportCrash(context.Background(), crash2)
 		dupSender := c.pollEmailBug().Sender
 		for j := 0; j < test.dup; j++ {
 			c.incomingEmail(dupSender, "#syz upstream", cc)
@@ -640,7 +641,7 @@ func TestEmailFailedBuild(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	failedBuild := testBuild(10)
 	failedBuild.KernelRepo, failedBuild.KernelBranch = testConfig.Namespaces["test2"].mainRepoBranch()
@@ -656,7 +657,7 @@ func TestEmailFailedBuild(t *testing.T) {
 			Maintainers: []string{"maintainer@crash"},
 		},
 	}
-	c.expectOK(c.client2.ReportBuildError(buildErrorReq))
+	c.expectOK(c.client2.ReportBuildError(context.Background(), buildErrorReq))
 
 	msg := c.pollEmailBug()
 	sender, extBugID, err := email.RemoveAddrContext(msg.Sender)
@@ -719,10 +720,10 @@ func TestEmailUnfix(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	msg := c.pollEmailBug()
 
@@ -734,10 +735,10 @@ func TestEmailUnfix(t *testing.T) {
 	build2 := testBuild(2)
 	build2.Manager = build.Manager
 	build2.Commits = []string{"some commit"}
-	c.client2.UploadBuild(build2)
+	c.client2.UploadBuild(context.Background(), build2)
 
 	// The bug should be still unfixed, since we unmarked it.
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.expectNoEmail()
 }
 
@@ -748,10 +749,10 @@ func TestEmailManagerCC(t *testing.T) {
 	// Test that we add manager CC.
 	build1 := testBuild(1)
 	build1.Manager = specialCCManager
-	c.client2.UploadBuild(build1)
+	c.client2.UploadBuild(context.Background(), build1)
 
 	crash := testCrash(build1, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	msg := c.pollEmailBug()
 	c.expectEQ(msg.To, []string{
@@ -780,7 +781,7 @@ func TestEmailManagerCC(t *testing.T) {
 			Log:    []byte("log\n"),
 		},
 	}
-	c.expectOK(c.client2.ReportBuildError(buildErrorReq))
+	c.expectOK(c.client2.ReportBuildError(context.Background(), buildErrorReq))
 	msg = c.pollEmailBug()
 	c.expectEQ(msg.To, []string{
 		"always@manager.org",
@@ -800,14 +801,14 @@ func TestEmailManagerCC(t *testing.T) {
 	// Test that we don't add manager CC when the crash happened on 1+ managers.
 	build3 := testBuild(3)
 	build1.Manager = specialCCManager
-	c.client2.UploadBuild(build3)
+	c.client2.UploadBuild(context.Background(), build3)
 	crash = testCrash(build3, 2)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	build4 := testBuild(4)
-	c.client2.UploadBuild(build4)
+	c.client2.UploadBuild(context.Background(), build4)
 	crash = testCrash(build4, 2)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	msg = c.pollEmailBug()
 	c.expectEQ(msg.To, []string{
@@ -827,12 +828,12 @@ func TestStraceReport(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Flags = dashapi.CrashUnderStrace
 	crash.Maintainers = []string{`"Foo Bar" <foo@bar.com>`, `bar@foo.com`, `idont@want.EMAILS`}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	// Report the crash over email and check all fields.
 	msg := c.pollEmailBug()
@@ -963,10 +964,10 @@ func TestBugFromSubjectInference(t *testing.T) {
 	client2 := c.makeClient(clientPublicEmail2, keyPublicEmail2, true)
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	build2 := testBuild(2)
-	client2.UploadBuild(build2)
+	client2.UploadBuild(context.Background(), build2)
 
 	const crashTitle = "WARNING in corrupted"
 	upstreamCrash := func(client *apiClient, build *dashapi.Build, title string) string {
@@ -975,7 +976,7 @@ func TestBugFromSubjectInference(t *testing.T) {
 		crash.Title = title
 		crash.Log = []byte(fmt.Sprintf("log%v", title))
 		crash.Maintainers = []string{"maintainer@kernel.org"}
-		client.ReportCrash(crash)
+		client.ReportCrash(context.Background(), crash)
 
 		sender := c.pollEmailBug().Sender
 		c.incomingEmail(sender, "#syz upstream\n")
@@ -1069,11 +1070,11 @@ func TestEmailLinks(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{`"Foo Bar" <foo@bar.com>`}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	// Report the crash over email.
 	msg := c.pollEmailBug()
@@ -1127,10 +1128,10 @@ func TestEmailPatchTestingAccess(t *testing.T) {
 	client := c.client2
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 	c.incomingEmail(sender,
@@ -1154,10 +1155,10 @@ func TestEmailSetInvalidSubsystems(t *testing.T) {
 	mailingList := c.config().Namespaces["access-public-email"].Reporting[0].Config.(*EmailConfig).Email
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz upstream\n")
 
 	sender := c.pollEmailBug().Sender
@@ -1186,10 +1187,10 @@ func TestEmailSetSubsystems(t *testing.T) {
 	mailingList := c.config().Namespaces["access-public-email"].Reporting[0].Config.(*EmailConfig).Email
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz upstream\n")
 
 	sender := c.pollEmailBug().Sender
@@ -1218,10 +1219,10 @@ func TestEmailBugLabels(t *testing.T) {
 	mailingList := c.config().Namespaces["access-public-email"].Reporting[0].Config.(*EmailConfig).Email
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 	_, extBugID, err := email.RemoveAddrContext(sender)
@@ -1264,10 +1265,10 @@ func TestInvalidEmailBugLabels(t *testing.T) {
 	mailingList := c.config().Namespaces["access-public-email"].Reporting[0].Config.(*EmailConfig).Email
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz upstream\n")
 
 	sender := c.pollEmailBug().Sender
@@ -1338,10 +1339,10 @@ func TestSingleListForward(t *testing.T) {
 		})
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 
@@ -1377,10 +1378,10 @@ func TestTwoListsForward(t *testing.T) {
 		})
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 
@@ -1403,3 +1404,35 @@ Author: default@sender.com
 #syz fix: some: commit title
 `)
 }
+
+func TestIncomingEmailAPI(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client2.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	c.client2.ReportCrash(context.Background(), crash)
+	msg := c.pollEmailBug()
+	_, bugID, err := email.RemoveAddrContext(msg.Sender)
+	c.expectOK(err)
+
+	c.expectOK(c.client2.IncomingEmail(context.Background(), &dashapi.IncomingEmail{
+		MessageID: "<123>",
+		Subject:   "Re: " + msg.Subject,
+		Author:    "Bob <bob@example.com>",
+		BugIDs:    []string{bugID},
+		Body:      "#syz invalid\n",
+		Commands:  []dashapi.EmailCommand{{Command: "invalid"}},
+	}))
+	c.expectNoEmail()
+
+	// The bug is closed, so a new crash creates a new bug.
+	c.client2.ReportCrash(context.Background(), crash)
+	msg = c.pollEmailBug()
+	c.expectEQ(msg.Subject, crash.Title+" (2)")
+
+	client := c.makeClient(client2, password2, false)
+	err = client.IncomingEmail(context.Background(), &dashapi.IncomingEmail{BugIDs: []string{bugID}})
+	c.expectTrue(errors.Is(err, dashapi.ErrBadRequest))
+}
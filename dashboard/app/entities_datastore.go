@@ -35,7 +35,10 @@ type Manager struct {
 	FailedSyzBuildBug string
 	LastAlive         time.Time
 	CurrentUpTime     time.Duration
+	CurrentVMs        int64 // running VMs, see dashapi.ManagerStatsReq.VMs
+	TotalVMs          int64
 	LastGeneratedJob  time.Time
+	ConfigVersion     string // the last ManagerOverrides version applied by the manager
 }
 
 // ManagerStats holds per-day manager runtime stats.
@@ -55,10 +58,28 @@ type ManagerStats struct {
 	TriagedPCs      int64
 }
 
+// ManagerCoverage holds the latest coverage the manager has uploaded on the day (see dashapi.UploadCoverageReq).
+// Has Manager as parent entity. Keyed by Date.
+type ManagerCoverage struct {
+	Namespace    string
+	Manager      string
+	Date         int // YYYYMMDD
+	Time         time.Time
+	BuildID      string
+	KernelRepo   string
+	KernelBranch string
+	KernelCommit string
+	Instrumented int64 // lines
+	Covered      int64
+	Files        int64 // reference to Text entity with JSON-encoded per-file coverage
+}
+
 type Asset struct {
 	Type        dashapi.AssetType
 	DownloadURL string
 	CreateDate  time.Time
+	Size        int64  `datastore:",noindex"`
+	SHA256      string `datastore:",noindex"`
 }
 
 type Build struct {
@@ -89,9 +110,14 @@ type Bug struct {
 	Title        string
 	MergedTitles []string // crash titles that we already merged into this bug
 	AltTitles    []string // alternative crash titles that we may merge into this bug
+	Signatures   []string // signatures of the merged crashes (see dashapi.Crash.Signature)
 	Status       int
 	StatusReason dashapi.BugStatusReason // e.g. if the bug status is "invalid", here's the reason why
 	DupOf        string
+	Kind         dashapi.CrashKind
+	CrashType    dashapi.CrashType // the type of the first crash that has it
+	Severity     dashapi.BugSeverity
+	EmbargoUntil time.Time // see bug.embargoed()
 	NumCrashes   int64
 	NumRepro     int64
 	// ReproLevel is the best ever found repro level for this bug.
@@ -108,6 +134,7 @@ type Bug struct {
 	LastReproTime   time.Time
 	LastCauseBisect time.Time
 	FixTime         time.Time // when we become aware of the fixing commit
+	PatchedTime     time.Time // the last time a manager was added to PatchedOn
 	LastActivity    time.Time // last time we observed any activity related to the bug
 	Closed          time.Time
 	SubsystemsTime  time.Time // when we have updated subsystems last time
@@ -115,6 +142,8 @@ type Bug struct {
 	Reporting       []BugReporting
 	Commits         []string // titles of fixing commmits
 	CommitInfo      []Commit // additional info for commits (for historical reasons parallel array to Commits)
+	FixRepo         string   // the tree Commits are pending in, empty for the main tree
+	FixBranch       string
 	HappenedOn      []string // list of managers
 	PatchedOn       []string `datastore:",noindex"` // list of managers
 	UNCC            []string // don't CC these emails on this bug
@@ -129,6 +158,9 @@ type Bug struct {
 	// FixCandidateJob holds the key of the latest successful cross-tree fix bisection job.
 	FixCandidateJob string
 	ReproAttempts   []BugReproAttempt
+	Notes           []BugNote // free-form notes attached via the API
+	CVEs            []string  // attached with dashapi.BugUpdate.CVEs
+	Retests         []BugRetest
 }
 
 type BugTreeTestInfo struct {
@@ -178,9 +210,30 @@ func (label BugLabel) String() string {
 
 // BugReproAttempt describes a single attempt to generate a repro for a bug.
 type BugReproAttempt struct {
-	Time    time.Time
-	Manager string
-	Log     int64
+	Time       time.Time
+	Manager    string
+	Log        int64
+	Attempts   int
+	Strategies string // comma-separated, datastore does not support nested slices
+	Duration   time.Duration
+}
+
+type BugNote struct {
+	Time   time.Time
+	Author string // the API client that added the note
+	Title  string
+	Text   int64
+}
+
+// BugRetest is the result of dashapi.ManagerCommandRetest on a build with the fixing commits.
+type BugRetest struct {
+	CommandID  int64
+	Manager    string
+	BuildID    string
+	Time       time.Time
+	Crashed    bool
+	CrashTitle string
+	Error      string `datastore:",noindex"`
 }
 
 func (bug *Bug) SetAutoSubsystems(c context.Context, list []*subsystem.Subsystem, now time.Time, rev int) {
@@ -369,10 +422,24 @@ type Crash struct {
 	ReportLen       int64
 	Assets          []Asset   // crash-related assets
 	AssetsLastCheck time.Time // the last time we checked the assets for deprecation
+	// Structured machine info, the zero value if the manager did not report it.
+	Machine dashapi.Machine `datastore:",noindex"`
+	// The leaked object of dashapi.CrashLeak crashes.
+	Leak dashapi.Leak `datastore:",noindex"`
+	Type dashapi.CrashType
+	// Named crash files besides Log and Report (see dashapi.CrashAttachment).
+	Attachments []CrashAttachment
+}
+
+type CrashAttachment struct {
+	Name     string `datastore:",noindex"`
+	MIMEType string `datastore:",noindex"`
+	Data     int64  // reference to textAttachment text entity
 }
 
 type CrashReportElements struct {
 	GuiltyFiles []string // guilty files as determined during the crash report parsing
+	Subsystems  []string // subsystems suggested by the manager
 }
 
 type CrashReferenceType string
@@ -598,6 +665,7 @@ type Job struct {
 	Log         int64 // reference to Log text entity
 	Error       int64 // reference to Error text entity, if set job failed
 	Flags       dashapi.JobDoneFlags
+	Confidence  float64 // of the bisection result, 0 for jobs done before it was reported
 
 	Reported         bool   // have we reported result back to user?
 	InvalidatedBy    string // user who marked this bug as invalid, empty by default
@@ -655,6 +723,51 @@ type Text struct {
 	Text      []byte `datastore:",noindex"` // gzip-compressed text
 }
 
+// Upload is a payload uploaded in chunks (see dashapi.ChunkedUpload).
+// Keyed by namespace and dashapi.UploadChunkReq.UploadID.
+type Upload struct {
+	Namespace string
+	Size      int64
+	Received  int64
+	Chunks    int64
+	Created   time.Time
+}
+
+// IdempotentReply is the saved reply to a request with an idempotency key.
+// Keyed by namespace, API method and the key.
+type IdempotentReply struct {
+	Namespace string
+	Method    string
+	Reply     []byte `datastore:",noindex"` // JSON-encoded
+	Created   time.Time
+	Pending   bool // the key is reserved by a request that is still running
+}
+
+// BlobHash refers to a large text by the hash of its contents (see dashapi.BlobDedup).
+// Keyed by namespace and hex-encoded SHA256 of the text.
+type BlobHash struct {
+	Namespace string
+	Tag       string
+	TextID    int64
+}
+
+// CorpusProg is a program of the namespace corpus (see dashapi.UploadCorpus).
+// Keyed by namespace and the program hash.
+type CorpusProg struct {
+	Namespace string
+	Hash      string
+	Prog      []byte `datastore:",noindex"`
+	Minimized bool
+	Manager   string   // the manager that has uploaded the program
+	Managers  []string // managers that have the program in the corpus
+	Added     time.Time
+}
+
+// UploadChunk has Upload as parent entity. Keyed by the chunk sequence number starting from 1.
+type UploadChunk struct {
+	Data []byte `datastore:",noindex"`
+}
+
 const (
 	textCrashLog     = "CrashLog"
 	textCrashReport  = "CrashReport"
@@ -666,6 +779,9 @@ const (
 	textLog          = "Log"
 	textError        = "Error"
 	textReproLog     = "ReproLog"
+	textCoverage     = "Coverage"
+	textBugNote      = "BugNote"
+	textAttachment   = "Attachment"
 )
 
 const (
@@ -731,6 +847,90 @@ type ReproTask struct {
 	LastAttempt  time.Time
 }
 
+// ManagerCommand is a command issued on the manager page (see dashapi.ManagerCommands).
+type ManagerCommand struct {
+	Namespace string
+	Manager   string
+	Type      dashapi.ManagerCommandType
+	Arg       string
+	CrashLog  int64 // reference to CrashLog text entity for ManagerCommandRerunRepro
+	Author    string
+	Time      time.Time
+	Pending   bool // until the manager acknowledges the command
+	Acked     time.Time
+	Error     string `datastore:",noindex"`
+}
+
+// ManagerSyscalls holds the syscalls the manager fuzzes (see dashapi.UploadManagerSyscalls).
+// Has Manager as parent entity, there is a single entity per manager.
+type ManagerSyscalls struct {
+	Namespace   string
+	Manager     string
+	BuildID     string
+	Time        time.Time
+	Enabled     []string             `datastore:",noindex"`
+	Unsupported []UnsupportedSyscall `datastore:",noindex"`
+}
+
+// ReproProgress is the latest progress of a reproduction (see dashapi.ReportReproProgress).
+// Has Bug as parent entity, there is a single entity per manager.
+type ReproProgress struct {
+	Manager  string
+	Phase    dashapi.ReproPhase
+	Started  time.Time
+	Updated  time.Time
+	ReproSyz []byte `datastore:",noindex"`
+}
+
+type UnsupportedSyscall struct {
+	Name   string
+	Reason string
+}
+
+// ManagerStatsSample is the last stats point of a manager within a period (see dashapi.UploadStatsSeriesReq).
+// Parent is Manager, keyed by the period and the start of the sample.
+type ManagerStatsSample struct {
+	Namespace string
+	Manager   string
+	Period    time.Duration
+	Time      time.Time // start of the period
+	Last      time.Time `datastore:",noindex"` // time of the point
+	Names     []string  `datastore:",noindex"`
+	Values    []int64   `datastore:",noindex"`
+}
+
+// ToolBug is a bug in syzkaller itself (see dashapi.ReportToolBug).
+// Tool bugs are kept apart from kernel bugs and are deduplicated by component, title and syzkaller commit.
+// Keyed by toolBugKeyHash.
+type ToolBug struct {
+	Namespace       string
+	Component       string
+	Title           string
+	SyzkallerCommit string
+	NumOccurrences  int64
+	FirstTime       time.Time
+	LastTime        time.Time
+	Log             int64 // Reference to CrashLog text entity of the first occurrence.
+	Report          int64 // Reference to CrashReport text entity of the first occurrence.
+}
+
+func toolBugKeyHash(ns, component, title, commit string) string {
+	return hash.String([]byte(fmt.Sprintf("%v-%v-%v-%v", ns, component, title, commit)))
+}
+
+// ReproLease is a repro task leased to a manager (see dashapi.ReproTaskPoll),
+// or a reproduction claimed by a manager (see dashapi.ClaimRepro), then TaskID is empty.
+// Keyed by the bug key hash, so that a bug is given to at most one manager at a time.
+type ReproLease struct {
+	Namespace string
+	Manager   string
+	TaskID    string
+	Deadline  time.Time
+	Done      bool
+	Status    string   // dashapi.ReproTaskStatus
+	Reasons   []string `datastore:",noindex"`
+}
+
 func mgrKey(c context.Context, ns, name string) *db.Key {
 	return db.NewKey(c, "Manager", fmt.Sprintf("%v-%v", ns, name), 0, nil)
 }
@@ -822,7 +1022,7 @@ func loadBuild(c context.Context, ns, id string) (*Build, error) {
 	build := new(Build)
 	if err := db.Get(c, buildKey(c, ns, id), build); err != nil {
 		if err == db.ErrNoSuchEntity {
-			return nil, fmt.Errorf("unknown build %v/%v", ns, id)
+			return nil, fmt.Errorf("%w: unknown build %v/%v", ErrClientNotFound, ns, id)
 		}
 		return nil, fmt.Errorf("failed to get build %v/%v: %w", ns, id, err)
 	}
@@ -951,6 +1151,8 @@ func looksLikeReportingHash(id string) bool {
 func (bug *Bug) updateCommits(commits []string, now time.Time) {
 	bug.Commits = commits
 	bug.CommitInfo = nil
+	bug.FixRepo = ""
+	bug.FixBranch = ""
 	bug.NeedCommitInfo = true
 	bug.FixTime = now
 	bug.PatchedOn = nil
@@ -993,6 +1195,27 @@ func (bug *Bug) dailyStatsTail(from time.Time) []BugDailyStats {
 	return bug.DailyStats[startPos:]
 }
 
+// embargoed says if the bug must not be reported publicly or shown to public users
+// (see dashapi.Crash.EmbargoUntil).
+func (bug *Bug) embargoed(c context.Context) bool {
+	return timeNow(c).Before(bug.EmbargoUntil)
+}
+
+// severity returns the severity set with dashapi.BugUpdate.Severity,
+// or derives it from the crash type for bugs without a set severity.
+func (bug *Bug) severity() dashapi.BugSeverity {
+	if bug.Severity != dashapi.SeverityUnknown {
+		return bug.Severity
+	}
+	if bug.CrashType.MemorySafety() {
+		if bug.ReproLevel != ReproLevelNone {
+			return dashapi.SeverityHigh
+		}
+		return dashapi.SeverityMedium
+	}
+	return dashapi.SeverityUnknown
+}
+
 func (bug *Bug) dashapiStatus() (dashapi.BugStatus, error) {
 	var status dashapi.BugStatus
 	switch bug.Status {
@@ -4,6 +4,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -17,21 +19,21 @@ func TestFixBasic(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	builderPollResp, _ := c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
-	needRepro, _ := c.client.NeedRepro(testCrashID(crash1))
+	needRepro, _ := c.client.NeedRepro(context.Background(), testCrashID(crash1))
 	c.expectEQ(needRepro, true)
 
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix the crash"},
@@ -39,11 +41,11 @@ func TestFixBasic(t *testing.T) {
 	c.expectEQ(reply.OK, true)
 
 	// Don't need repro once there are fixing commits.
-	needRepro, _ = c.client.NeedRepro(testCrashID(crash1))
+	needRepro, _ = c.client.NeedRepro(context.Background(), testCrashID(crash1))
 	c.expectEQ(needRepro, false)
 
 	// Check that the commit is now passed to builders.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "foo: fix the crash")
 
@@ -53,32 +55,32 @@ func TestFixBasic(t *testing.T) {
 	// Upstream commands must fail if patches are already present.
 	// Right course of action is unclear in this situation,
 	// so this test merely documents the current behavior.
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusUpstream,
 	})
 	c.expectEQ(reply.OK, false)
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Upload another build with the commit present.
 	build2 := testBuild(2)
 	build2.Manager = build1.Manager
 	build2.Commits = []string{"foo: fix the crash"}
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Check that the commit is now not passed to this builder.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// Ensure that a new crash creates a new bug (the old one must be marked as fixed).
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, "title1 (2)")
 
 	// Regression test: previously upstreamming failed because the new bug had fixing commits.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.updateBug(rep2.ID, dashapi.BugStatusUpstream, "")
 	c.client.pollBug()
 }
@@ -89,18 +91,18 @@ func TestFixedByTwoCommits(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	builderPollResp, _ := c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"bar: prepare for fixing", "\"foo: fix the crash\""},
@@ -108,7 +110,7 @@ func TestFixedByTwoCommits(t *testing.T) {
 	c.expectEQ(reply.OK, true)
 
 	// Check that the commit is now passed to builders.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 2)
 	c.expectEQ(builderPollResp.PendingCommits[0], "bar: prepare for fixing")
 	c.expectEQ(builderPollResp.PendingCommits[1], "foo: fix the crash")
@@ -117,29 +119,29 @@ func TestFixedByTwoCommits(t *testing.T) {
 	build2 := testBuild(2)
 	build2.Manager = build1.Manager
 	build2.Commits = []string{"bar: prepare for fixing"}
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Check that it has not fixed the bug.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 2)
 	c.expectEQ(builderPollResp.PendingCommits[0], "bar: prepare for fixing")
 	c.expectEQ(builderPollResp.PendingCommits[1], "foo: fix the crash")
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Now upload build with both commits.
 	build3 := testBuild(3)
 	build3.Manager = build1.Manager
 	build3.Commits = []string{"foo: fix the crash", "bar: prepare for fixing"}
-	c.client.UploadBuild(build3)
+	c.client.UploadBuild(context.Background(), build3)
 
 	// Check that the commit is now not passed to this builder.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// Ensure that a new crash creates a new bug (the old one must be marked as fixed).
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, "title1 (2)")
 }
@@ -150,12 +152,12 @@ func TestReFixed(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	builderPollResp, _ := c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	c.advanceTime(time.Hour)
@@ -167,7 +169,7 @@ func TestReFixed(t *testing.T) {
 
 	// Specify fixing commit for the bug.
 	c.advanceTime(time.Hour)
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"a wrong one"},
@@ -179,7 +181,7 @@ func TestReFixed(t *testing.T) {
 	c.expectEQ(bug.FixTime, c.mockedTime)
 
 	c.advanceTime(time.Hour)
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"the right one"},
@@ -193,7 +195,7 @@ func TestReFixed(t *testing.T) {
 	// No updates, just check that LastActivity time is updated, FixTime preserved.
 	fixTime := c.mockedTime
 	c.advanceTime(time.Hour)
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -204,7 +206,7 @@ func TestReFixed(t *testing.T) {
 
 	// Send the same fixing commit, check that LastActivity time is updated, FixTime preserved.
 	c.advanceTime(time.Hour)
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"the right one"},
@@ -214,7 +216,7 @@ func TestReFixed(t *testing.T) {
 	c.expectEQ(bug.LastActivity, c.mockedTime)
 	c.expectEQ(bug.FixTime, fixTime)
 
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "the right one")
 
@@ -222,24 +224,24 @@ func TestReFixed(t *testing.T) {
 	build2 := testBuild(2)
 	build2.Manager = build1.Manager
 	build2.Commits = []string{"a wrong one"}
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Check that it has not fixed the bug.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "the right one")
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Now upload build with the right commit.
 	build3 := testBuild(3)
 	build3.Manager = build1.Manager
 	build3.Commits = []string{"the right one"}
-	c.client.UploadBuild(build3)
+	c.client.UploadBuild(context.Background(), build3)
 
 	// Check that the commit is now not passed to this builder.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 }
 
@@ -249,18 +251,18 @@ func TestFixTwoManagers(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	builderPollResp, _ := c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix the crash"},
@@ -269,14 +271,14 @@ func TestFixTwoManagers(t *testing.T) {
 
 	// Now the second manager appears.
 	build2 := testBuild(2)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Check that the commit is now passed to builders.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "foo: fix the crash")
 
-	builderPollResp, _ = c.client.BuilderPoll(build2.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build2.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "foo: fix the crash")
 
@@ -284,32 +286,32 @@ func TestFixTwoManagers(t *testing.T) {
 	build3 := testBuild(3)
 	build3.Manager = build1.Manager
 	build3.Commits = []string{"foo: fix the crash"}
-	c.client.UploadBuild(build3)
+	c.client.UploadBuild(context.Background(), build3)
 
 	// Check that the commit is now not passed to this builder.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// But still passed to another.
-	builderPollResp, _ = c.client.BuilderPoll(build2.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build2.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "foo: fix the crash")
 
 	// Check that the bug is still open.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Now the second manager picks up the commit.
 	build4 := testBuild(4)
 	build4.Manager = build2.Manager
 	build4.Commits = []string{"foo: fix the crash"}
-	c.client.UploadBuild(build4)
+	c.client.UploadBuild(context.Background(), build4)
 
 	// Now the bug must be fixed.
-	builderPollResp, _ = c.client.BuilderPoll(build2.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build2.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, "title1 (2)")
 }
@@ -319,18 +321,18 @@ func TestReFixedTwoManagers(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	builderPollResp, _ := c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix the crash"},
@@ -339,19 +341,19 @@ func TestReFixedTwoManagers(t *testing.T) {
 
 	// Now the second manager appears.
 	build2 := testBuild(2)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Now first manager picks up the commit.
 	build3 := testBuild(3)
 	build3.Manager = build1.Manager
 	build3.Commits = []string{"foo: fix the crash"}
-	c.client.UploadBuild(build3)
+	c.client.UploadBuild(context.Background(), build3)
 
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// Now we change the fixing commit.
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"the right one"},
@@ -359,11 +361,11 @@ func TestReFixedTwoManagers(t *testing.T) {
 	c.expectEQ(reply.OK, true)
 
 	// Now it must again appear on both managers.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "the right one")
 
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 1)
 	c.expectEQ(builderPollResp.PendingCommits[0], "the right one")
 
@@ -371,14 +373,14 @@ func TestReFixedTwoManagers(t *testing.T) {
 	build4 := testBuild(4)
 	build4.Manager = build2.Manager
 	build4.Commits = []string{"the right one"}
-	c.client.UploadBuild(build4)
+	c.client.UploadBuild(context.Background(), build4)
 
 	// The bug must be still open.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Specify fixing commit again, but it's the same one as before, so nothing changed.
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"the right one"},
@@ -389,13 +391,13 @@ func TestReFixedTwoManagers(t *testing.T) {
 	build5 := testBuild(5)
 	build5.Manager = build1.Manager
 	build5.Commits = []string{"the right one"}
-	c.client.UploadBuild(build5)
+	c.client.UploadBuild(context.Background(), build5)
 
 	// Now the bug must be fixed.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, "title1 (2)")
 }
@@ -406,13 +408,13 @@ func TestFixedWithCommitTags(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	build2 := testBuild(2)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	rep := c.client.pollBug()
 
@@ -421,34 +423,34 @@ func TestFixedWithCommitTags(t *testing.T) {
 		{Title: "fix commit 1", BugIDs: []string{rep.ID}},
 		{Title: "fix commit 2", BugIDs: []string{rep.ID}},
 	}
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	// Now the commits must be associated with the bug and the second
 	// manager must get them as pending.
-	builderPollResp, _ := c.client.BuilderPoll(build2.Manager)
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build2.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 2)
 	c.expectEQ(builderPollResp.PendingCommits[0], "fix commit 1")
 	c.expectEQ(builderPollResp.PendingCommits[1], "fix commit 2")
 
 	// The first manager must not get them.
-	builderPollResp, _ = c.client.BuilderPoll(build1.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// The bug is still not fixed.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 
 	// Now the second manager reports the same commits.
 	// This must close the bug.
 	build2.FixCommits = build1.FixCommits
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	// Commits must not be passed to managers.
-	builderPollResp, _ = c.client.BuilderPoll(build2.Manager)
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build2.Manager)
 	c.expectEQ(len(builderPollResp.PendingCommits), 0)
 
 	// Ensure that a new crash creates a new bug.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, "title1 (2)")
 }
@@ -460,14 +462,14 @@ func TestFixedDup(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 
 	crash2 := testCrash(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep2 := c.client.pollBug()
 
 	// rep2 is a dup of rep1.
@@ -477,10 +479,10 @@ func TestFixedDup(t *testing.T) {
 	build.FixCommits = []dashapi.Commit{
 		{Title: "fix commit 1", BugIDs: []string{rep2.ID}},
 	}
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// This must fix rep1.
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep3 := c.client.pollBug()
 	c.expectEQ(rep3.Title, rep1.Title+" (2)")
 }
@@ -492,17 +494,17 @@ func TestFixedDup2(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	build2 := testBuild(2)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 
 	crash2 := testCrash(build1, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep2 := c.client.pollBug()
 
 	// rep2 is a dup of rep1.
@@ -512,22 +514,22 @@ func TestFixedDup2(t *testing.T) {
 	build1.FixCommits = []dashapi.Commit{
 		{Title: "fix commit 1", BugIDs: []string{rep2.ID}},
 	}
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	// Now undup the bugs. They are still unfixed as only 1 manager uploaded the commit.
 	c.client.updateBug(rep2.ID, dashapi.BugStatusOpen, "")
 
 	// Now the second manager reports the same commits. This must close both bugs.
 	build2.FixCommits = build1.FixCommits
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 	c.client.pollBugs(0)
 
 	c.advanceTime(24 * time.Hour)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep3 := c.client.pollBug()
 	c.expectEQ(rep3.Title, rep1.Title+" (2)")
 
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep4 := c.client.pollBug()
 	c.expectEQ(rep4.Title, rep2.Title+" (2)")
 }
@@ -538,17 +540,17 @@ func TestFixedDup3(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	build2 := testBuild(2)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build2)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 
 	crash2 := testCrash(build1, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep2 := c.client.pollBug()
 
 	// rep2 is a dup of rep1.
@@ -561,12 +563,104 @@ func TestFixedDup3(t *testing.T) {
 		{Title: "fix commit 2", BugIDs: []string{rep2.ID}},
 	}
 	build2.FixCommits = build1.FixCommits
-	c.client.UploadBuild(build1)
-	c.client.UploadBuild(build2)
-	c.client.UploadBuild(build1)
-	c.client.UploadBuild(build2)
+	c.client.UploadBuild(context.Background(), build1)
+	c.client.UploadBuild(context.Background(), build2)
+	c.client.UploadBuild(context.Background(), build1)
+	c.client.UploadBuild(context.Background(), build2)
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep3 := c.client.pollBug()
 	c.expectEQ(rep3.Title, rep1.Title+" (2)")
 }
+
+// Test attaching fixing commits to a bug by its title.
+func TestReportFixCommits(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build1 := testBuild(1)
+	c.client.UploadBuild(context.Background(), build1)
+
+	crash1 := testCrash(build1, 1)
+	c.client.ReportCrash(context.Background(), crash1)
+	rep := c.client.pollBug()
+
+	c.expectOK(c.client.ReportFixCommits(context.Background(), &dashapi.ReportFixCommitsReq{
+		Title: crash1.Title,
+		Commits: []dashapi.Commit{{
+			Hash:  "1111111111111111111111111111111111111111",
+			Title: "foo: fix the crash",
+			Date:  time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	}))
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.Commits, []string{"foo: fix the crash"})
+	c.expectEQ(bug.CommitInfo[0].Hash, "1111111111111111111111111111111111111111")
+
+	// The commit is passed to builders and the bug is fixed once a build has it.
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
+	c.expectEQ(builderPollResp.PendingCommits, []string{"foo: fix the crash"})
+	build2 := testBuild(2)
+	build2.Manager = build1.Manager
+	build2.Commits = []string{"foo: fix the crash"}
+	c.client.UploadBuild(context.Background(), build2)
+	c.client.ReportCrash(context.Background(), crash1)
+	rep = c.client.pollBug()
+	c.expectEQ(rep.Title, "title1 (2)")
+
+	client := c.makeClient(client1, password1, false)
+	err := client.ReportFixCommits(context.Background(), &dashapi.ReportFixCommitsReq{
+		Title:   "no such bug",
+		Commits: []dashapi.Commit{{Title: "foo: fix the crash"}},
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
+
+// Fixing commits that reach the tested tree after the build was uploaded are reported
+// for the current build.
+func TestFixBuildCommits(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build1 := testBuild(1)
+	c.client.UploadBuild(context.Background(), build1)
+	build2 := testBuild(2)
+	build2.Manager = build1.Manager
+	c.client.UploadBuild(context.Background(), build2)
+
+	crash1 := testCrash(build2, 1)
+	c.client.ReportCrash(context.Background(), crash1)
+	rep := c.client.pollBug()
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:         rep.ID,
+		Status:     dashapi.BugStatusOpen,
+		FixCommits: []string{"foo: fix the crash"},
+	})
+
+	// Commits of builds the manager no longer runs are ignored.
+	c.expectOK(c.client.UploadBuildCommits(context.Background(), &dashapi.BuildCommitsReq{
+		Manager: build1.Manager,
+		BuildID: build1.ID,
+		Commits: []string{"foo: fix the crash"},
+	}))
+	builderPollResp, _ := c.client.BuilderPoll(context.Background(), build1.Manager)
+	c.expectEQ(builderPollResp.PendingCommits, []string{"foo: fix the crash"})
+
+	c.expectOK(c.client.UploadBuildCommits(context.Background(), &dashapi.BuildCommitsReq{
+		Manager: build2.Manager,
+		BuildID: build2.ID,
+		Commits: []string{"foo: fix the crash"},
+	}))
+	builderPollResp, _ = c.client.BuilderPoll(context.Background(), build1.Manager)
+	c.expectEQ(len(builderPollResp.PendingCommits), 0)
+	c.client.ReportCrash(context.Background(), crash1)
+	rep = c.client.pollBug()
+	c.expectEQ(rep.Title, "title1 (2)")
+
+	client := c.makeClient(client1, password1, false)
+	err := client.UploadBuildCommits(context.Background(), &dashapi.BuildCommitsReq{
+		Manager: build1.Manager,
+		BuildID: "unknown",
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
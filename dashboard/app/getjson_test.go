@@ -4,6 +4,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -89,21 +91,21 @@ func TestJSONAPIIntegration(t *testing.T) {
 	c.makeClient(client1, password1, false)
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	bugReport1 := c.client.pollBug()
 	checkBugPageJSONIs(c, bugReport1.ID, sampleCrashDescr)
 
 	crash2 := testCrashWithRepro(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	bugReport2 := c.client.pollBug()
 	checkBugPageJSONIs(c, bugReport2.ID, sampleCrashWithReproDescr)
 
 	checkBugGroupPageJSONIs(c, "/test1?json=1", sampleOpenBugGroupDescr)
 
-	c.client.ReportingUpdate(&dashapi.BugUpdate{
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         bugReport2.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix1", "foo: fix2"},
@@ -135,24 +137,24 @@ func TestJSONAPIFixCommits(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	c.client.ReportingUpdate(&dashapi.BugUpdate{
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep1.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix1", "foo: fix2"},
 	})
 
-	c.client.UploadCommits([]dashapi.Commit{
+	c.client.UploadCommits(context.Background(), []dashapi.Commit{
 		{Hash: "hash1", Title: "foo: fix1"},
 	})
 
-	c.client.CommitPoll()
+	c.client.CommitPoll(context.Background())
 
 	want := []byte(`{
 	"version": 1,
@@ -222,3 +224,53 @@ func TestJSONAPICauseBisection(t *testing.T) {
 	]
 }`)
 }
+
+func TestJSONAPICVEs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+
+	crash1 := testCrash(build, 1)
+	c.client.ReportCrash(context.Background(), crash1)
+	rep1 := c.client.pollBug()
+	crash2 := testCrash(build, 2)
+	c.client.ReportCrash(context.Background(), crash2)
+	c.client.pollBug()
+
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:     rep1.ID,
+		Status: dashapi.BugStatusUpdate,
+		CVEs:   []string{"CVE-2024-1234", "not a CVE"},
+	})
+	c.expectEQ(reply.OK, false)
+	for _, cve := range []string{"CVE-2024-1234", "CVE-2024-5678", "CVE-2024-1234"} {
+		reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+			ID:     rep1.ID,
+			Status: dashapi.BugStatusUpdate,
+			CVEs:   []string{cve},
+		})
+		c.expectTrue(reply.OK)
+	}
+	bug, _, _ := c.loadBug(rep1.ID)
+	c.expectEQ(bug.CVEs, []string{"CVE-2024-1234", "CVE-2024-5678"})
+
+	content, err := c.client.GET("/test1?has_cve=1&json=1")
+	c.expectOK(err)
+	var groups publicAPIBugGroup
+	c.expectOK(json.Unmarshal(content, &groups))
+	c.expectEQ(len(groups.Bugs), 1)
+	c.expectEQ(groups.Bugs[0].Title, crash1.Title)
+	c.expectEQ(groups.Bugs[0].CVEs, []string{"CVE-2024-1234", "CVE-2024-5678"})
+
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:        rep1.ID,
+		Status:    dashapi.BugStatusUpdate,
+		CVEs:      []string{"CVE-2024-9999"},
+		ResetCVEs: true,
+	})
+	c.expectTrue(reply.OK)
+	bug, _, _ = c.loadBug(rep1.ID)
+	c.expectEQ(bug.CVEs, []string{"CVE-2024-9999"})
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -15,56 +16,56 @@ func TestManagersGraphs(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client2.UploadBuild(build1)
+	c.client2.UploadBuild(context.Background(), build1)
 	build2 := testBuild(2)
-	c.client2.UploadBuild(build2)
+	c.client2.UploadBuild(context.Background(), build2)
 
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build1.Manager,
 		Corpus: 100,
 		PCs:    1000,
 		Cover:  2000,
 	}))
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build2.Manager,
 		Corpus: 200,
 		PCs:    2000,
 		Cover:  4000,
 	}))
 	c.advanceTime(25 * time.Hour)
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build1.Manager,
 		Corpus: 110,
 		PCs:    1100,
 		Cover:  2200,
 	}))
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build2.Manager,
 		Corpus: 220,
 		PCs:    2200,
 		Cover:  4400,
 	}))
 	c.advanceTime(25 * time.Hour)
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build1.Manager,
 		Corpus: 150,
 		PCs:    1500,
 		Cover:  2900,
 	}))
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build2.Manager,
 		Corpus: 270,
 		PCs:    2700,
 		Cover:  5400,
 	}))
 	c.advanceTime(25 * time.Hour)
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build1.Manager,
 		Corpus: 50,
 		PCs:    500,
 		Cover:  900,
 	}))
-	c.expectOK(c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.expectOK(c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build2.Manager,
 		Corpus: 70,
 		PCs:    700,
@@ -75,7 +76,7 @@ func TestManagersGraphs(t *testing.T) {
 		c.advanceTime(7 * 25 * time.Hour)
 		for j := 0; j <= i; j++ {
 			crash := testCrash(build1, i*i+j)
-			c.client2.ReportCrash(crash)
+			c.client2.ReportCrash(context.Background(), crash)
 		}
 	}
 
@@ -122,9 +123,9 @@ func managersGraphFixture(t *testing.T) *Ctx {
 	t.Cleanup(c.Close)
 
 	build1 := testBuild(1)
-	c.client2.UploadBuild(build1)
+	c.client2.UploadBuild(context.Background(), build1)
 
-	c.client2.UploadManagerStats(&dashapi.ManagerStatsReq{
+	c.client2.UploadManagerStats(context.Background(), &dashapi.ManagerStatsReq{
 		Name:   build1.Manager,
 		Corpus: 100,
 		PCs:    1000,
@@ -171,12 +171,23 @@ type (
 var ErrClientNotFound = &ErrClient{errors.New("resource not found")}
 var ErrClientBadRequest = &ErrClient{errors.New("bad request")}
 
+// These are mapped onto the corresponding dashapi errors (dashapi.ErrAccessDenied etc) on the client side.
+var ErrClientForbidden = &ErrClient{errors.New("access denied")}
+var ErrClientRetired = &ErrClient{errors.New("client is retired")}
+var ErrClientTooLarge = &ErrClient{errors.New("request is too large")}
+
 func (ce *ErrClient) HTTPStatus() int {
 	switch ce {
 	case ErrClientNotFound:
 		return http.StatusNotFound
 	case ErrClientBadRequest:
 		return http.StatusBadRequest
+	case ErrClientForbidden:
+		return http.StatusForbidden
+	case ErrClientRetired:
+		return http.StatusGone
+	case ErrClientTooLarge:
+		return http.StatusRequestEntityTooLarge
 	}
 	return http.StatusInternalServerError
 }
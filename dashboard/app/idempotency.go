@@ -0,0 +1,117 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/appengine/v2"
+	db "google.golang.org/appengine/v2/datastore"
+	"google.golang.org/appengine/v2/log"
+)
+
+// This file implements deduplication of repeated requests with client-generated
+// idempotency keys (see dashapi.Crash.IdempotencyKey).
+// The replies are kept for idempotencyExpiration, which should cover spooled requests
+// of managers that could not reach the dashboard for a while.
+
+const idempotencyExpiration = 7 * 24 * time.Hour
+
+// idempotencyPendingTimeout bounds the time the key is reserved for a running request.
+// After that the request is considered lost (e.g. the instance has died) and a retry is executed.
+const idempotencyPendingTimeout = 10 * time.Minute
+
+// idempotent runs fn once per key, repeated requests get the saved reply of the first request.
+// The key is reserved before fn runs, so concurrent requests with the same key fail with
+// a temporary error while the first one is in progress. Failed requests are not saved
+// and release the key, so they can be retried with the same key.
+func idempotent[T any](c context.Context, ns, method, key string, fn func() (*T, error)) (*T, error) {
+	if key == "" {
+		return fn()
+	}
+	if len(key) > MaxStringLen {
+		return nil, fmt.Errorf("%w: too long idempotency key", ErrClientBadRequest)
+	}
+	dbKey := db.NewKey(c, "IdempotentReply", fmt.Sprintf("%v|%v|%v", ns, method, key), 0, nil)
+	var saved *IdempotentReply
+	tx := func(c context.Context) error {
+		saved = new(IdempotentReply)
+		err := db.Get(c, dbKey, saved)
+		if err == nil && (!saved.Pending || timeNow(c).Sub(saved.Created) < idempotencyPendingTimeout) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, db.ErrNoSuchEntity) {
+			return fmt.Errorf("failed to get saved reply: %w", err)
+		}
+		saved = nil
+		pending := &IdempotentReply{
+			Namespace: ns,
+			Method:    method,
+			Created:   timeNow(c),
+			Pending:   true,
+		}
+		if _, err := db.Put(c, dbKey, pending); err != nil {
+			return fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10}); err != nil {
+		return nil, err
+	}
+	if saved != nil {
+		if saved.Pending {
+			// Not a client error, so that the client retries the request later.
+			return nil, fmt.Errorf("%v request %v is in progress", method, key)
+		}
+		reply := new(T)
+		if err := json.Unmarshal(saved.Reply, reply); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal saved reply: %w", err)
+		}
+		log.Infof(c, "repeated %v request %v", method, key)
+		return reply, nil
+	}
+	reply, err := fn()
+	if err != nil {
+		if err := db.Delete(c, dbKey); err != nil {
+			log.Errorf(c, "failed to release %v idempotency key: %v", method, err)
+		}
+		return nil, err
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reply: %w", err)
+	}
+	saved = &IdempotentReply{
+		Namespace: ns,
+		Method:    method,
+		Reply:     data,
+		Created:   timeNow(c),
+	}
+	if _, err := db.Put(c, dbKey, saved); err != nil {
+		// The request has succeeded, so don't fail it, but a retry may be executed again
+		// once the reservation times out.
+		log.Errorf(c, "failed to save %v reply: %v", method, err)
+	}
+	return reply, nil
+}
+
+func handleCleanIdempotency(w http.ResponseWriter, r *http.Request) {
+	c := appengine.NewContext(r)
+	keys, err := db.NewQuery("IdempotentReply").
+		Filter("Created<", timeNow(c).Add(-idempotencyExpiration)).
+		KeysOnly().
+		GetAll(c, nil)
+	if err != nil {
+		log.Errorf(c, "failed to query saved replies: %v", err)
+		return
+	}
+	if err := db.DeleteMulti(c, keys); err != nil {
+		log.Errorf(c, "failed to delete saved replies: %v", err)
+	}
+}
@@ -109,6 +109,9 @@ func addTestJob(c context.Context, args *testJobArgs) (*Job, *db.Key, error) {
 		args.repo != mgrConfig.RestrictedTestingRepo {
 		return nil, nil, &BadTestRequestError{mgrConfig.RestrictedTestingReason}
 	}
+	if args.configAppend != "" && getNsConfig(c, args.bug.Namespace).EncryptedPayloads {
+		return nil, nil, &BadTestRequestError{"the kernel configs are encrypted and can't be amended"}
+	}
 	patchID, err := putText(c, args.bug.Namespace, textPatch, args.patch)
 	if err != nil {
 		return nil, nil, err
@@ -816,6 +819,89 @@ func createBisectJobForBug(c context.Context, bug0 *Bug, crash *Crash, bugKey, c
 	return job, jobKey, nil
 }
 
+// Maximum number of unfinished bisection jobs in a namespace after which
+// we stop accepting explicit bisection requests.
+const maxQueuedBisections = 20
+
+func queueBisectJob(c context.Context, ns string, req *dashapi.QueueBisectReq) (*dashapi.QueueBisectResp, error) {
+	var jobType JobType
+	switch req.Type {
+	case dashapi.JobBisectCause:
+		jobType = JobBisectCause
+	case dashapi.JobBisectFix:
+		jobType = JobBisectFix
+	default:
+		return nil, fmt.Errorf("%w: bad bisection job type %v", ErrClientBadRequest, req.Type)
+	}
+	bug := new(Bug)
+	bugKey := db.NewKey(c, "Bug", req.BugID, 0, nil)
+	if err := db.Get(c, bugKey, bug); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: no bug %v", ErrClientNotFound, req.BugID)
+		}
+		return nil, fmt.Errorf("failed to get bug: %w", err)
+	}
+	if bug.Namespace != ns {
+		return nil, fmt.Errorf("%w: no bug %v", ErrClientNotFound, req.BugID)
+	}
+	refuse := func(refusal dashapi.BisectRefusal, msg string, args ...interface{}) (*dashapi.QueueBisectResp, error) {
+		return &dashapi.QueueBisectResp{
+			Refusal: refusal,
+			Text:    fmt.Sprintf(msg, args...),
+		}, nil
+	}
+	if jobType == JobBisectCause && bug.BisectCause != BisectNot ||
+		jobType == JobBisectFix && bug.BisectFix != BisectNot {
+		return refuse(dashapi.BisectRefusedDone, "the bisection has already been done")
+	}
+	if getNsConfig(c, ns).Decommissioned {
+		return refuse(dashapi.BisectRefusedDisabled, "the namespace is decommissioned")
+	}
+	if bug.ReproLevel == ReproLevelNone {
+		return refuse(dashapi.BisectRefusedNoRepro, "the bug has no reproducer")
+	}
+	queued := 0
+	for _, typ := range []JobType{JobBisectCause, JobBisectFix} {
+		n, err := db.NewQuery("Job").
+			Filter("Namespace=", ns).
+			Filter("Type=", typ).
+			Filter("Finished=", time.Time{}).
+			KeysOnly().
+			Count(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query jobs: %w", err)
+		}
+		queued += n
+	}
+	if queued >= maxQueuedBisections {
+		return refuse(dashapi.BisectRefusedQuota, "there are already %v unfinished bisections", queued)
+	}
+	managers := make(map[string]bool)
+	for _, mgr := range bug.HappenedOn {
+		if req.Manager == "" || req.Manager == mgr {
+			managers[mgr] = true
+		}
+	}
+	crash, crashKey, err := bisectCrashForBug(c, bug, bugKey, managers, jobType)
+	if err != nil {
+		return nil, err
+	}
+	if crash == nil {
+		if req.Manager != "" {
+			return refuse(dashapi.BisectRefusedNoRepro, "the bug has no reproducer on %v", req.Manager)
+		}
+		return refuse(dashapi.BisectRefusedNoRepro, "the bug has no reproducer suitable for bisection")
+	}
+	job, jobKey, err := createBisectJobForBug(c, bug, crash, bugKey, crashKey, jobType)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return refuse(dashapi.BisectRefusedDone, "the bisection has already been started")
+	}
+	return &dashapi.QueueBisectResp{JobID: extJobID(jobKey)}, nil
+}
+
 func createJobResp(c context.Context, job *Job, jobKey *db.Key) (*dashapi.JobPollResp, bool, error) {
 	jobID := extJobID(jobKey)
 	patch, _, err := getText(c, textPatch, job.Patch)
@@ -847,7 +933,7 @@ func createJobResp(c context.Context, job *Job, jobKey *db.Key) (*dashapi.JobPol
 	if err != nil {
 		return nil, false, err
 	}
-	reproSyz, err := loadReproSyz(c, crash)
+	reproSyz, err := loadReproSyz(c, job.Namespace, crash)
 	if err != nil {
 		return nil, false, err
 	}
@@ -1048,6 +1134,13 @@ func doneJob(c context.Context, req *dashapi.JobDoneReq) error {
 	if err != nil {
 		return err
 	}
+	// Deduplicated blobs belong to other entity groups, so resolve them before the transaction as well.
+	err = resolveBlobHashes(c, job.Namespace, req.Build.BlobHashes,
+		map[string]*[]byte{"KernelConfig": &req.Build.KernelConfig})
+	if err != nil {
+		return fmt.Errorf("job %v: %w", jobID, err)
+	}
+	req.Build.BlobHashes = nil
 	now := timeNow(c)
 	tx := func(c context.Context) error {
 		job = new(Job)
@@ -1103,6 +1196,7 @@ func doneJob(c context.Context, req *dashapi.JobDoneReq) error {
 		job.Finished = now
 		job.IsRunning = false
 		job.Flags = req.Flags
+		job.Confidence = req.Confidence
 		if job.Type == JobBisectCause || job.Type == JobBisectFix {
 			// Update bug.BisectCause/Fix status and also remember current bug reporting to send results.
 			var err error
@@ -1126,6 +1220,7 @@ func doneJob(c context.Context, req *dashapi.JobDoneReq) error {
 	if err != nil {
 		return err
 	}
+	bumpReportingGeneration(c)
 	return postJob(c, jobKey, job)
 }
 
@@ -1356,6 +1451,7 @@ func bisectFromJob(c context.Context, job *Job) (*dashapi.BisectResult, []string
 		CrashReportLink: externalLink(c, textCrashReport, job.CrashReport),
 		Fix:             job.Type == JobBisectFix,
 		CrossTree:       job.IsCrossTree(),
+		Confidence:      job.Confidence,
 	}
 	for _, com := range job.Commits {
 		bisect.Commits = append(bisect.Commits, com.toDashapi())
@@ -1483,9 +1579,10 @@ func loadPendingJob(c context.Context, managers map[string]dashapi.ManagerJobs)
 			// Don't retry bisection jobs too often.
 			// This allows to have several syz-ci's doing bisection
 			// and protects from bisection job crashing syz-ci.
+			// Jobs that were never started (e.g. explicitly queued ones) can be taken right away.
 			const bisectRepeat = 3 * 24 * time.Hour
-			if timeSince(c, job.Created) < bisectRepeat ||
-				timeSince(c, job.LastStarted) < bisectRepeat {
+			if job.Attempts != 0 && (timeSince(c, job.Created) < bisectRepeat ||
+				timeSince(c, job.LastStarted) < bisectRepeat) {
 				continue
 			}
 		default:
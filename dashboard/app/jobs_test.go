@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -31,12 +32,12 @@ func TestJob(t *testing.T) {
 
 	client := c.publicClient
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Report crash without repro, check that test requests are not accepted.
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 	c.incomingEmail(sender, "#syz upstream\n")
@@ -57,7 +58,7 @@ func TestJob(t *testing.T) {
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
 	crash.ReproC = []byte("repro C")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollAndFailBisectJob(build.Manager)
 
 	body = c.pollEmailBug().Body
@@ -123,7 +124,7 @@ func TestJob(t *testing.T) {
 		CrashLog:    []byte("test crash log"),
 		CrashReport: []byte("test crash report"),
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 
 	{
 		dbJob, dbBuild, _ := c.loadJob(pollResp.ID)
@@ -167,7 +168,7 @@ patch:          %[1]v
 		Build: *build,
 		Error: []byte("failed to apply patch"),
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 	{
 		dbJob, dbBuild, _ := c.loadJob(pollResp.ID)
 		patchLink := externalLink(c.ctx, textPatch, dbJob.Patch)
@@ -204,7 +205,7 @@ patch:          %[1]v
 		Build: *build,
 		Error: bytes.Repeat([]byte{'a', 'b', 'c'}, (maxInlineError+100)/3),
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 	{
 		dbJob, dbBuild, _ := c.loadJob(pollResp.ID)
 		patchLink := externalLink(c.ctx, textPatch, dbJob.Patch)
@@ -246,7 +247,7 @@ patch:          %[3]v
 		Build:    *build,
 		CrashLog: []byte("console output"),
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 	{
 		dbJob, dbBuild, _ := c.loadJob(pollResp.ID)
 		patchLink := externalLink(c.ctx, textPatch, dbJob.Patch)
@@ -287,11 +288,11 @@ func TestBootErrorPatch(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = "riscv/fixes boot error: can't ssh into the instance"
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	report := c.pollEmailBug()
 	c.incomingEmail(report.Sender, "#syz upstream\n", EmailOptCC(report.To))
@@ -311,11 +312,11 @@ func TestTestErrorPatch(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 	c.incomingEmail(sender, "#syz upstream\n")
@@ -336,12 +337,12 @@ func TestJobWithoutPatch(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollAndFailBisectJob(build.Manager)
 	sender := c.pollEmailBug().Sender
 	_, extBugID, err := email.RemoveAddrContext(sender)
@@ -363,7 +364,7 @@ func TestJobWithoutPatch(t *testing.T) {
 		ID:    pollResp.ID,
 		Build: *testBuild,
 	}
-	client.JobDone(jobDoneReq)
+	client.JobDone(context.Background(), jobDoneReq)
 	{
 		_, dbBuild, _ := c.loadJob(pollResp.ID)
 		kernelConfigLink := externalLink(c.ctx, textKernelConfig, dbBuild.KernelConfig)
@@ -402,12 +403,12 @@ func TestReproRetestJob(t *testing.T) {
 	oldBuild := testBuild(1)
 	oldBuild.KernelRepo = "git://mygit.com/git.git"
 	oldBuild.KernelBranch = "main"
-	client.UploadBuild(oldBuild)
+	client.UploadBuild(context.Background(), oldBuild)
 
 	crash := testCrash(oldBuild, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	sender := c.pollEmailBug().Sender
 	_, extBugID, err := email.RemoveAddrContext(sender)
 	c.expectOK(err)
@@ -416,7 +417,7 @@ func TestReproRetestJob(t *testing.T) {
 	crash2.ReproOpts = []byte("repro opts")
 	crash2.ReproSyz = []byte("repro syz")
 	crash2.ReproC = []byte("repro C")
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	c.pollEmailBug()
 
 	// Upload a newer build.
@@ -426,7 +427,7 @@ func TestReproRetestJob(t *testing.T) {
 	build.KernelRepo = "git://mygit.com/new-git.git"
 	build.KernelBranch = "new-main"
 	build.KernelConfig = []byte{0xAB, 0xCD, 0xEF}
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	c.advanceTime(time.Hour)
 	bug, _, _ := c.loadBug(extBugID)
@@ -456,7 +457,7 @@ func TestReproRetestJob(t *testing.T) {
 				ID: resp.ID,
 			}
 		}
-		client.expectOK(client.JobDone(done))
+		client.expectOK(client.JobDone(context.Background(), done))
 	}
 	// Expect that the repro level is no longer ReproLevelC.
 	c.expectNoEmail()
@@ -473,7 +474,7 @@ func TestReproRetestJob(t *testing.T) {
 	done := &dashapi.JobDoneReq{
 		ID: resp.ID,
 	}
-	client.expectOK(client.JobDone(done))
+	client.expectOK(client.JobDone(context.Background(), done))
 	// Expect that the repro level is no longer ReproLevelC.
 	bug, _, _ = c.loadBug(extBugID)
 	c.expectEQ(bug.HeadReproLevel, ReproLevelNone)
@@ -500,13 +501,13 @@ func TestDelegatedManagerReproRetest(t *testing.T) {
 	oldBuild.KernelRepo = "git://delegated.repo/git.git"
 	oldBuild.KernelBranch = "main"
 	oldBuild.Manager = oldManager
-	client.UploadBuild(oldBuild)
+	client.UploadBuild(context.Background(), oldBuild)
 
 	crash := testCrash(oldBuild, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
 	crash.ReproC = []byte("repro C")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	sender := c.pollEmailBug().Sender
 	_, extBugID, err := email.RemoveAddrContext(sender)
 	c.expectOK(err)
@@ -522,7 +523,7 @@ func TestDelegatedManagerReproRetest(t *testing.T) {
 	build.KernelBranch = "new-main"
 	build.KernelConfig = []byte{0xAB, 0xCD, 0xEF}
 	build.Manager = newManager
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Wait until the bug is upstreamed.
 	c.advanceTime(20 * 24 * time.Hour)
@@ -544,7 +545,7 @@ func TestDelegatedManagerReproRetest(t *testing.T) {
 		ID: resp.ID,
 	}
 
-	client.expectOK(client.JobDone(done))
+	client.expectOK(client.JobDone(context.Background(), done))
 
 	// If it has worked, the repro is revoked and the bug is obsoleted.
 	c.pollEmailBug()
@@ -561,11 +562,11 @@ func TestJobRestrictedManager(t *testing.T) {
 
 	build := testBuild(1)
 	build.Manager = restrictedManager
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.ReproSyz = []byte("repro syz")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollAndFailBisectJob(build.Manager)
 	sender := c.pollEmailBug().Sender
 
@@ -592,9 +593,9 @@ func TestBisectFixJob(t *testing.T) {
 
 	// Upload a crash report.
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// Receive the JobBisectCause.
@@ -605,7 +606,7 @@ func TestBisectFixJob(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixJob:JobBisectCause"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Ensure no more jobs.
 	resp = c.client2.pollJobs(build.Manager)
@@ -631,7 +632,7 @@ func TestBisectFixJob(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixJob:JobBisectFix"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 }
 
 // Test that JobBisectFix jobs are re-tried if crash occurs on ToT.
@@ -641,9 +642,9 @@ func TestBisectFixRetry(t *testing.T) {
 
 	// Upload a crash report.
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// Receive the JobBisectCause.
@@ -654,7 +655,7 @@ func TestBisectFixRetry(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectCause"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Advance time by 30 days and read out any notification emails.
 	{
@@ -680,7 +681,7 @@ func TestBisectFixRetry(t *testing.T) {
 		CrashLog:    []byte("this is a crashlog"),
 		CrashReport: []byte("this is a crashreport"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Advance time by 30 days. No notification emails.
 	{
@@ -695,7 +696,7 @@ func TestBisectFixRetry(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectFix"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 }
 
 // Test that bisection results are not reported for bugs that are already marked as fixed.
@@ -705,9 +706,9 @@ func TestNotReportingAlreadyFixed(t *testing.T) {
 
 	// Upload a crash report.
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// Receive the JobBisectCause.
@@ -718,7 +719,7 @@ func TestNotReportingAlreadyFixed(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectCause"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	sender := ""
 	// Advance time by 30 days and read out any notification emails.
@@ -773,7 +774,7 @@ func TestNotReportingAlreadyFixed(t *testing.T) {
 			},
 		},
 	}
-	c.expectOK(c.client2.JobDone(done))
+	c.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// No reporting should come in at this point. If there is reporting, c.Close()
 	// will fail.
@@ -787,9 +788,9 @@ func TestFixBisectionsListed(t *testing.T) {
 
 	// Upload a crash report.
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// Receive the JobBisectCause.
@@ -800,7 +801,7 @@ func TestFixBisectionsListed(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectCause"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// At this point, no fix bisections should be listed out.
 	var bugs []*Bug
@@ -839,7 +840,7 @@ func TestFixBisectionsListed(t *testing.T) {
 		CrashReport: []byte("this is a crashreport"),
 		Log:         []byte("this is a log"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Check the bug page and ensure that a bisection is listed out.
 	content, err = c.GET(url)
@@ -859,7 +860,7 @@ func TestFixBisectionsListed(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectFix"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Check the bug page and ensure that no bisections are listed out.
 	content, err = c.GET(url)
@@ -875,9 +876,9 @@ func TestFixBisectionsDisabled(t *testing.T) {
 	// Upload a crash report.
 	build := testBuild(1)
 	build.Manager = noFixBisectionManager
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 20)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.client2.pollEmailBug()
 
 	// Receive the JobBisectCause.
@@ -888,7 +889,7 @@ func TestFixBisectionsDisabled(t *testing.T) {
 		ID:    resp.ID,
 		Error: []byte("testBisectFixRetry:JobBisectCause"),
 	}
-	c.client2.expectOK(c.client2.JobDone(done))
+	c.client2.expectOK(c.client2.JobDone(context.Background(), done))
 
 	// Advance time by 30 days and read out any notification emails.
 	{
@@ -914,19 +915,19 @@ func TestExternalPatchFlow(t *testing.T) {
 	client := c.client
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Confirm the report.
-	reports, err := client.ReportingPollBugs("test")
+	reports, err := client.ReportingPollBugs(context.Background(), "test")
 	origReport := reports.Reports[0]
 	c.expectOK(err)
 	c.expectEQ(len(reports.Reports), 1)
 
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -934,7 +935,7 @@ func TestExternalPatchFlow(t *testing.T) {
 	client.expectEQ(reply.OK, true)
 
 	// Create a new patch testing job.
-	ret, err := client.NewTestJob(&dashapi.TestPatchRequest{
+	ret, err := client.NewTestJob(context.Background(), &dashapi.TestPatchRequest{
 		BugID:  origReport.ID,
 		Link:   "http://some-link.com/",
 		User:   "developer@kernel.org",
@@ -961,11 +962,11 @@ func TestExternalPatchFlow(t *testing.T) {
 		CrashLog:    []byte("test crash log"),
 		CrashReport: []byte("test crash report"),
 	}
-	err = c.client2.JobDone(jobDoneReq)
+	err = c.client2.JobDone(context.Background(), jobDoneReq)
 	c.expectOK(err)
 
 	// Verify that we do get the bug update about the completed request.
-	jobDoneUpdates, err := client.ReportingPollBugs("test")
+	jobDoneUpdates, err := client.ReportingPollBugs(context.Background(), "test")
 	c.expectOK(err)
 	c.expectEQ(len(jobDoneUpdates.Reports), 1)
 
@@ -975,7 +976,7 @@ func TestExternalPatchFlow(t *testing.T) {
 	c.expectEQ(newReport.Report, []byte("test crash report"))
 
 	// Confirm the patch testing result.
-	reply, _ = client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		JobID:  pollResp.ID,
 		Status: dashapi.BugStatusOpen,
@@ -991,19 +992,19 @@ func TestExternalPatchTestError(t *testing.T) {
 	client := c.client
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Confirm the report.
-	reports, err := client.ReportingPollBugs("test")
+	reports, err := client.ReportingPollBugs(context.Background(), "test")
 	origReport := reports.Reports[0]
 	c.expectOK(err)
 	c.expectEQ(len(reports.Reports), 1)
 
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -1011,7 +1012,7 @@ func TestExternalPatchTestError(t *testing.T) {
 	client.expectEQ(reply.OK, true)
 
 	// Create a new patch testing job.
-	ret, err := client.NewTestJob(&dashapi.TestPatchRequest{
+	ret, err := client.NewTestJob(context.Background(), &dashapi.TestPatchRequest{
 		BugID:  origReport.ID,
 		User:   "developer@kernel.org",
 		Branch: "kernel-branch",
@@ -1030,19 +1031,19 @@ func TestExternalPatchCompletion(t *testing.T) {
 
 	build := testBuild(1)
 	build.KernelRepo = "git://git.git/git.git"
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Confirm the report.
-	reports, err := client.ReportingPollBugs("test")
+	reports, err := client.ReportingPollBugs(context.Background(), "test")
 	origReport := reports.Reports[0]
 	c.expectOK(err)
 	c.expectEQ(len(reports.Reports), 1)
 
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -1050,7 +1051,7 @@ func TestExternalPatchCompletion(t *testing.T) {
 	client.expectEQ(reply.OK, true)
 
 	// Create a new patch testing job.
-	ret, err := client.NewTestJob(&dashapi.TestPatchRequest{
+	ret, err := client.NewTestJob(context.Background(), &dashapi.TestPatchRequest{
 		BugID: origReport.ID,
 		User:  "developer@kernel.org",
 		Patch: []byte(sampleGitPatch),
@@ -1071,19 +1072,19 @@ func TestParallelJobs(t *testing.T) {
 	client := c.client
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Confirm the report.
-	reports, err := client.ReportingPollBugs("test")
+	reports, err := client.ReportingPollBugs(context.Background(), "test")
 	origReport := reports.Reports[0]
 	c.expectOK(err)
 	c.expectEQ(len(reports.Reports), 1)
 
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -1103,7 +1104,7 @@ func TestParallelJobs(t *testing.T) {
 		Repo:   repo1,
 		Patch:  []byte(sampleGitPatch),
 	}
-	ret, err := client.NewTestJob(testPatchReq)
+	ret, err := client.NewTestJob(context.Background(), testPatchReq)
 	c.expectOK(err)
 	c.expectEQ(ret.ErrorText, "")
 
@@ -1118,7 +1119,7 @@ func TestParallelJobs(t *testing.T) {
 
 	// Create another job.
 	testPatchReq.Repo = repo2
-	ret, err = client.NewTestJob(testPatchReq)
+	ret, err = client.NewTestJob(context.Background(), testPatchReq)
 	c.expectOK(err)
 	c.expectEQ(ret.ErrorText, "")
 
@@ -1132,7 +1133,7 @@ func TestParallelJobs(t *testing.T) {
 	c.expectEQ(emptyPollResp, &dashapi.JobPollResp{})
 
 	// Emulate a syz-ci restart.
-	client.JobReset(&dashapi.JobResetReq{Managers: []string{build.Manager}})
+	client.JobReset(context.Background(), &dashapi.JobResetReq{Managers: []string{build.Manager}})
 
 	// .. and re-query both jobs.
 	repos := []string{}
@@ -1156,7 +1157,7 @@ func TestParallelJobs(t *testing.T) {
 		CrashLog:    []byte("test crash log"),
 		CrashReport: []byte("test crash report"),
 	}
-	err = client.JobDone(jobDoneReq)
+	err = client.JobDone(context.Background(), jobDoneReq)
 	c.expectOK(err)
 	client.pollBugs(1)
 
@@ -1173,9 +1174,9 @@ func TestJobCauseRetry(t *testing.T) {
 	client := c.client2
 	// Upload a crash report.
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollEmailBug()
 
 	// Release the report to the second stage.
@@ -1194,7 +1195,7 @@ func TestJobCauseRetry(t *testing.T) {
 		Error: []byte("infra problem"),
 		Flags: dashapi.BisectResultInfraError,
 	}
-	client.expectOK(client.JobDone(done))
+	client.expectOK(client.JobDone(context.Background(), done))
 	c.expectNoEmail()
 
 	// Ensure we don't recreate the job right away.
@@ -1230,7 +1231,7 @@ func TestJobCauseRetry(t *testing.T) {
 		},
 	}
 	done.Build.ID = resp.ID
-	c.expectOK(client.JobDone(done))
+	c.expectOK(client.JobDone(context.Background(), done))
 
 	msg := c.pollEmailBug()
 	c.expectTrue(strings.Contains(msg.Body, "syzbot has bisected this issue to:"))
@@ -1245,10 +1246,10 @@ func TestEmailTestCommandNoArgs(t *testing.T) {
 	build := testBuild(1)
 	build.KernelRepo = "git://git.git/git.git"
 	build.KernelBranch = "kernel-branch"
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrashWithRepro(build, 2)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	sender := c.pollEmailBug().Sender
 	mailingList := c.config().Namespaces["access-public-email"].Reporting[0].Config.(*EmailConfig).Email
@@ -1270,18 +1271,18 @@ func TestAliasPatchTestingJob(t *testing.T) {
 	client := c.client
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 2)
 	crash.Title = testErrorTitle
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Confirm the report.
-	reports, err := client.ReportingPollBugs("test")
+	reports, err := client.ReportingPollBugs(context.Background(), "test")
 	origReport := reports.Reports[0]
 	c.expectOK(err)
 
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     origReport.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -1289,7 +1290,7 @@ func TestAliasPatchTestingJob(t *testing.T) {
 	client.expectEQ(reply.OK, true)
 
 	// Create a new patch testing job.
-	_, err = client.NewTestJob(&dashapi.TestPatchRequest{
+	_, err = client.NewTestJob(context.Background(), &dashapi.TestPatchRequest{
 		BugID:  origReport.ID,
 		User:   "developer@kernel.org",
 		Branch: "some-branch",
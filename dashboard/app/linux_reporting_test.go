@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
@@ -20,7 +21,7 @@ func TestFsSubsystemFlow(t *testing.T) {
 
 	client := c.makeClient(clientPublicFs, keyPublicFs, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// A. Make sure non-fs bugs are not affected.
 	// -----------------------------------------
@@ -30,7 +31,7 @@ func TestFsSubsystemFlow(t *testing.T) {
 	crash.Log = []byte("log log log")
 	crash.GuiltyFiles = []string{"kernel/kernel.c"}
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// We skip the first stage and report the bug right away.
 	reply := c.pollEmailBug()
@@ -44,7 +45,7 @@ func TestFsSubsystemFlow(t *testing.T) {
 	crash.GuiltyFiles = []string{"fs/nilfs2/dat.c"}
 	crash.Log = []byte("log log log")
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	reply = c.pollEmailBug()
 	// The subsystem should have been taken from the guilty path.
@@ -65,7 +66,7 @@ func TestFsSubsystemFlow(t *testing.T) {
 	crash.GuiltyFiles = []string{"fs/namei.c"}
 	crash.Log = []byte("log log log")
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// As there's no other information, the bug is left at the first reporting.
 	c.client.pollNotifs(0)
@@ -85,7 +86,7 @@ mkdirat(r0, &(0x7f0000000180)='./bus\x00', 0x0)
 mkdirat(r0, &(0x7f0000000280)='./bus/file0\x00', 0x0)
 renameat2(r0, &(0x7f00000004c0)='./file0\x00', r0, &(0x7f0000000500)='./bus/file0/file0\x00', 0x0)`)
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Check that we're ready for upstreaming.
 	c.client.pollNotifs(1)
@@ -113,7 +114,7 @@ func TestVfsSubsystemFlow(t *testing.T) {
 
 	client := c.makeClient(clientPublicFs, keyPublicFs, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// A. Send a possibly vfs bug without a reproducer.
 	// -----------------------------------------
@@ -123,7 +124,7 @@ func TestVfsSubsystemFlow(t *testing.T) {
 	crash.GuiltyFiles = []string{"fs/namei.c"}
 	crash.Log = []byte("log log log")
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// As there's no other information, the bug is left at the first reporting.
 	c.client.pollNotifs(0)
@@ -141,7 +142,7 @@ mkdirat(r0, &(0x7f0000000180)='./bus\x00', 0x0)
 mkdirat(r0, &(0x7f0000000280)='./bus/file0\x00', 0x0)
 renameat2(r0, &(0x7f00000004c0)='./file0\x00', r0, &(0x7f0000000500)='./bus/file0/file0\x00', 0x0)`)
 	crash.Maintainers = []string{"maintainer@kernel.org"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 
 	// Check that we're ready for upstreaming.
 	c.client.pollNotifs(1)
@@ -55,6 +55,8 @@ func initHTTPHandlers() {
 	http.Handle("/x/bisect.txt", handlerWrapper(handleTextX(textLog)))
 	http.Handle("/x/error.txt", handlerWrapper(handleTextX(textError)))
 	http.Handle("/x/minfo.txt", handlerWrapper(handleTextX(textMachineInfo)))
+	http.Handle("/x/note.txt", handlerWrapper(handleTextX(textBugNote)))
+	http.Handle("/x/attachment", handlerWrapper(handleTextX(textAttachment)))
 	for ns, nsConfig := range getConfig(context.Background()).Namespaces {
 		http.Handle("/"+ns, handlerWrapper(handleMain))
 		http.Handle("/"+ns+"/fixed", handlerWrapper(handleFixed))
@@ -82,6 +84,8 @@ func initHTTPHandlers() {
 	http.HandleFunc("/cron/cache_update", cacheUpdate)
 	http.HandleFunc("/cron/minute_cache_update", handleMinuteCacheUpdate)
 	http.HandleFunc("/cron/deprecate_assets", handleDeprecateAssets)
+	http.HandleFunc("/cron/clean_uploads", handleCleanUploads)
+	http.HandleFunc("/cron/clean_idempotency", handleCleanIdempotency)
 	http.HandleFunc("/cron/refresh_subsystems", handleRefreshSubsystems)
 	http.HandleFunc("/cron/subsystem_reports", handleSubsystemReports)
 }
@@ -223,6 +227,20 @@ type uiManagerPage struct {
 	Message       string
 	ShowReproForm bool
 	Builds        []*uiBuild
+	ShowCmdForm   bool
+	CmdTypes      []dashapi.ManagerCommandType
+	Commands      []*uiManagerCommand
+	Syscalls      *ManagerSyscalls
+}
+
+type uiManagerCommand struct {
+	Type    dashapi.ManagerCommandType
+	Arg     string
+	Author  string
+	Time    time.Time
+	Pending bool
+	Acked   time.Time
+	Error   string
 }
 
 type uiManager struct {
@@ -237,6 +255,8 @@ type uiManager struct {
 	FailedSyzBuildBugLink string
 	LastActive            time.Time
 	CurrentUpTime         time.Duration
+	CurrentVMs            int64
+	TotalVMs              int64
 	MaxCorpus             int64
 	MaxCover              int64
 	TotalFuzzingTime      time.Duration
@@ -275,6 +295,35 @@ type uiReproAttempt struct {
 	LogLink string
 }
 
+type uiReproManager struct {
+	Manager string
+	Missing []string // syscalls of the reproducer that are not enabled on the manager
+}
+
+type uiReproProgress struct {
+	Manager  string
+	Phase    dashapi.ReproPhase
+	Elapsed  time.Duration
+	Updated  time.Time
+	ReproSyz string
+}
+
+type uiBugNote struct {
+	Time   time.Time
+	Author string
+	Title  string
+	Link   string
+}
+
+type uiBugRetest struct {
+	Time       time.Time
+	Manager    string
+	BuildID    string
+	Crashed    bool
+	CrashTitle string
+	Error      string
+}
+
 type uiBugPage struct {
 	Header          *uiHeader
 	Now             time.Time
@@ -301,6 +350,10 @@ const (
 	sectionDiscussionList = "discussion_list"
 	sectionTestResults    = "test_results"
 	sectionReproAttempts  = "repro_attempts"
+	sectionBugNotes       = "bug_notes"
+	sectionReproManagers  = "repro_managers"
+	sectionBugRetests     = "bug_retests"
+	sectionReproProgress  = "repro_progress"
 )
 
 type uiCollapsible struct {
@@ -365,12 +418,15 @@ type uiBug struct {
 	ReportedTime   time.Time
 	ClosedTime     time.Time
 	ReproLevel     dashapi.ReproLevel
+	Severity       dashapi.BugSeverity
+	EmbargoUntil   time.Time // zero if the bug is not embargoed
 	ReportingIndex int
 	Status         string
 	Link           string
 	ExternalLink   string
 	CreditEmail    string
 	Commits        []*uiCommit
+	FixTree        string // the tree Commits are pending in, empty for the main tree
 	PatchedOn      []string
 	MissingOn      []string
 	NumManagers    int
@@ -378,6 +434,7 @@ type uiBug struct {
 	Labels         []*uiBugLabel
 	Discussions    DiscussionSummary
 	ID             string
+	CVEs           []string
 }
 
 type uiBugLabel struct {
@@ -392,6 +449,7 @@ type uiCrash struct {
 	Maintainers     string
 	LogLink         string
 	LogHasStrace    bool
+	FromHub         bool
 	ReportLink      string
 	ReproSyzLink    string
 	ReproCLink      string
@@ -448,6 +506,8 @@ type userBugFilter struct {
 	OnlyManager string // show bugs that happened ONLY on the manager
 	Labels      []string
 	NoSubsystem bool
+	CrashType   dashapi.CrashType // show bugs of the crash type
+	HasCVE      bool              // show bugs with CVE IDs
 }
 
 func MakeBugFilter(r *http.Request) (*userBugFilter, error) {
@@ -459,6 +519,8 @@ func MakeBugFilter(r *http.Request) (*userBugFilter, error) {
 		Manager:     r.FormValue("manager"),
 		OnlyManager: r.FormValue("only_manager"),
 		Labels:      r.Form["label"],
+		CrashType:   dashapi.CrashType(r.FormValue("crash_type")),
+		HasCVE:      r.FormValue("has_cve") != "",
 	}, nil
 }
 
@@ -490,6 +552,12 @@ func (filter *userBugFilter) MatchBug(bug *Bug) bool {
 	if filter.NoSubsystem && len(bug.LabelValues(SubsystemLabel)) > 0 {
 		return false
 	}
+	if filter.CrashType != dashapi.CrashTypeUnknown && bug.CrashType != filter.CrashType {
+		return false
+	}
+	if filter.HasCVE && len(bug.CVEs) == 0 {
+		return false
+	}
 	for _, rawLabel := range filter.Labels {
 		label, value := splitLabel(rawLabel)
 		if !bug.HasLabel(label, value) {
@@ -512,7 +580,8 @@ func (filter *userBugFilter) Any() bool {
 	if filter == nil {
 		return false
 	}
-	return len(filter.Labels) > 0 || filter.OnlyManager != "" || filter.Manager != "" || filter.NoSubsystem
+	return len(filter.Labels) > 0 || filter.OnlyManager != "" || filter.Manager != "" || filter.NoSubsystem ||
+		filter.CrashType != dashapi.CrashTypeUnknown || filter.HasCVE
 }
 
 // handleMain serves main page.
@@ -608,7 +677,31 @@ func handleManagerPage(c context.Context, w http.ResponseWriter, r *http.Request
 			managerPage.Message = "Repro request was saved!"
 		}
 	}
+	if accessLevel == AccessAdmin {
+		managerPage.ShowCmdForm = true
+		managerPage.CmdTypes = []dashapi.ManagerCommandType{
+			dashapi.ManagerCommandStopFuzzing,
+			dashapi.ManagerCommandRerunRepro,
+			dashapi.ManagerCommandRetest,
+		}
+		if typ := r.FormValue("command"); typ != "" && r.Method == http.MethodPost {
+			err := saveManagerCommand(c, hdr.Namespace, manager.Name, dashapi.ManagerCommandType(typ),
+				strings.TrimSpace(r.FormValue("command-arg")), user.Current(c).Email)
+			if err != nil {
+				return fmt.Errorf("failed to save the command: %w", err)
+			}
+			managerPage.Message = "The command was queued!"
+		}
+		managerPage.Commands, err = loadRecentManagerCommands(c, hdr.Namespace, manager.Name)
+		if err != nil {
+			return err
+		}
+	}
 
+	managerPage.Syscalls, err = loadManagerSyscalls(c, hdr.Namespace, manager.Name)
+	if err != nil {
+		return err
+	}
 	for _, build := range builds {
 		managerPage.Builds = append(managerPage.Builds, makeUIBuild(c, build, false))
 	}
@@ -1188,6 +1281,51 @@ func handleBug(c context.Context, w http.ResponseWriter, r *http.Request) error
 			Value: reproAttempts,
 		})
 	}
+	reproManagers, err := loadReproManagers(c, bug)
+	if err != nil {
+		return err
+	}
+	if len(reproManagers) > 0 {
+		canRun := 0
+		for _, item := range reproManagers {
+			if len(item.Missing) == 0 {
+				canRun++
+			}
+		}
+		sections = append(sections, &uiCollapsible{
+			Title: fmt.Sprintf("Managers that can run the reproducer (%d/%d)", canRun, len(reproManagers)),
+			Type:  sectionReproManagers,
+			Value: reproManagers,
+		})
+	}
+	reproProgress, err := loadReproProgress(c, bug)
+	if err != nil {
+		return err
+	}
+	if len(reproProgress) > 0 {
+		sections = append(sections, &uiCollapsible{
+			Title: fmt.Sprintf("Reproduction in progress (%d)", len(reproProgress)),
+			Show:  true,
+			Type:  sectionReproProgress,
+			Value: reproProgress,
+		})
+	}
+	if len(bug.Retests) > 0 {
+		sections = append(sections, &uiCollapsible{
+			Title: fmt.Sprintf("Fix verification (%d)", len(bug.Retests)),
+			Show:  true,
+			Type:  sectionBugRetests,
+			Value: getBugRetests(bug),
+		})
+	}
+	if len(bug.Notes) > 0 {
+		sections = append(sections, &uiCollapsible{
+			Title: fmt.Sprintf("Notes (%d)", len(bug.Notes)),
+			Show:  true,
+			Type:  sectionBugNotes,
+			Value: getBugNotes(bug),
+		})
+	}
 	data := &uiBugPage{
 		Header:       hdr,
 		Now:          timeNow(c),
@@ -1248,6 +1386,34 @@ func getReproAttempts(bug *Bug) []*uiReproAttempt {
 	return ret
 }
 
+func getBugNotes(bug *Bug) []*uiBugNote {
+	var ret []*uiBugNote
+	for _, note := range bug.Notes {
+		ret = append(ret, &uiBugNote{
+			Time:   note.Time,
+			Author: note.Author,
+			Title:  note.Title,
+			Link:   textLink(textBugNote, note.Text),
+		})
+	}
+	return ret
+}
+
+func getBugRetests(bug *Bug) []*uiBugRetest {
+	var ret []*uiBugRetest
+	for _, retest := range bug.Retests {
+		ret = append(ret, &uiBugRetest{
+			Time:       retest.Time,
+			Manager:    retest.Manager,
+			BuildID:    retest.BuildID,
+			Crashed:    retest.Crashed,
+			CrashTitle: retest.CrashTitle,
+			Error:      retest.Error,
+		})
+	}
+	return ret
+}
+
 type labelGroupInfo struct {
 	Label BugLabelType
 	Name  string
@@ -1516,15 +1682,37 @@ func handleTextImpl(c context.Context, w http.ResponseWriter, r *http.Request, t
 	if err := checkAccessLevel(c, r, getNsConfig(c, ns).AccessLevel); err != nil {
 		return err
 	}
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	contentType, filename := "text/plain; charset=utf-8", textFilename(tag)
+	if tag == textAttachment && crash != nil {
+		contentType, filename = attachmentHeaders(crash, id)
+	}
+	w.Header().Set("Content-Type", contentType)
 	// Unfortunately filename does not work in chrome on linux due to:
 	// https://bugs.chromium.org/p/chromium/issues/detail?id=608342
-	w.Header().Set("Content-Disposition", "inline; filename="+textFilename(tag))
-	augmentRepro(c, w, tag, bug, crash)
+	w.Header().Set("Content-Disposition", "inline; filename="+filename)
+	if !getNsConfig(c, ns).EncryptedPayloads {
+		augmentRepro(c, w, tag, bug, crash)
+	}
 	w.Write(data)
 	return nil
 }
 
+// attachmentHeaders returns the Content-Type and the file name to serve the crash attachment with.
+// Only plain text is shown inline, everything else is downloaded (we don't want to serve
+// uploaded HTML or scripts from the dashboard domain).
+func attachmentHeaders(crash *Crash, id int64) (string, string) {
+	for _, att := range crash.Attachments {
+		if att.Data != id {
+			continue
+		}
+		if att.MIMEType == "" || strings.HasPrefix(att.MIMEType, "text/") {
+			return "text/plain; charset=utf-8", att.Name
+		}
+		return "application/octet-stream", att.Name
+	}
+	return "application/octet-stream", textFilename(textAttachment)
+}
+
 func augmentRepro(c context.Context, w http.ResponseWriter, tag string, bug *Bug, crash *Crash) {
 	if tag == textReproSyz || tag == textReproC {
 		// Users asked for the bug link in reproducers (in case you only saved the repro link).
@@ -1577,6 +1765,10 @@ func textFilename(tag string) string {
 		return "minfo.txt"
 	case textReproLog:
 		return "repro.log"
+	case textBugNote:
+		return "note.txt"
+	case textAttachment:
+		return "attachment"
 	default:
 		panic(fmt.Sprintf("unknown tag %v", tag))
 	}
@@ -1933,6 +2125,7 @@ func createUIBug(c context.Context, bug *Bug, state *ReportingState, managers []
 		ReportedTime:   reported,
 		ClosedTime:     bug.Closed,
 		ReproLevel:     bug.ReproLevel,
+		Severity:       bug.severity(),
 		ReportingIndex: reportingIdx,
 		Status:         status,
 		Link:           bugExtLink(c, bug),
@@ -1942,21 +2135,29 @@ func createUIBug(c context.Context, bug *Bug, state *ReportingState, managers []
 		LastActivity:   bug.LastActivity,
 		Discussions:    bug.discussionSummary(),
 		ID:             bug.keyHash(c),
+		CVEs:           bug.CVEs,
 	}
 	for _, entry := range bug.Labels {
 		uiBug.Labels = append(uiBug.Labels, makeBugLabelUI(c, bug, entry))
 	}
+	if bug.embargoed(c) {
+		uiBug.EmbargoUntil = bug.EmbargoUntil
+	}
 	updateBugBadness(c, uiBug)
 	if len(bug.Commits) != 0 {
+		repo, branch := getNsConfig(c, bug.Namespace).mainRepoBranch()
+		if bug.FixRepo != "" {
+			repo, branch = bug.FixRepo, bug.FixBranch
+			uiBug.FixTree = strings.TrimSpace(repo + " " + branch)
+		}
 		for i, com := range bug.Commits {
-			mainNsRepo, mainNsBranch := getNsConfig(c, bug.Namespace).mainRepoBranch()
 			info := bug.getCommitInfo(i)
 			uiBug.Commits = append(uiBug.Commits, &uiCommit{
 				Hash:   info.Hash,
 				Title:  com,
-				Link:   vcs.CommitLink(mainNsRepo, info.Hash),
-				Repo:   mainNsRepo,
-				Branch: mainNsBranch,
+				Link:   vcs.CommitLink(repo, info.Hash),
+				Repo:   repo,
+				Branch: branch,
 			})
 		}
 		for _, mgr := range managers {
@@ -2026,6 +2227,10 @@ func loadCrashesForBug(c context.Context, bug *Bug) ([]*uiCrash, template.HTML,
 		}
 		results = append(results, makeUICrash(c, crash, build))
 	}
+	if getNsConfig(c, bug.Namespace).EncryptedPayloads {
+		// The report is a ciphertext, it's only linked from the crash table.
+		return results, "", nil
+	}
 	sampleReport, _, err := getText(c, textCrashReport, crashes[0].Report)
 	if err != nil {
 		return nil, "", err
@@ -2066,6 +2271,7 @@ func makeUICrash(c context.Context, crash *Crash, build *Build) *uiCrash {
 		Maintainers:     strings.Join(crash.Maintainers, ", "),
 		LogLink:         textLink(textCrashLog, crash.Log),
 		LogHasStrace:    dashapi.CrashFlags(crash.Flags)&dashapi.CrashUnderStrace > 0,
+		FromHub:         dashapi.CrashFlags(crash.Flags)&dashapi.CrashFromHub > 0,
 		ReportLink:      textLink(textCrashReport, crash.Report),
 		ReproSyzLink:    textLink(textReproSyz, crash.ReproSyz),
 		ReproCLink:      textLink(textReproC, crash.ReproC),
@@ -2074,6 +2280,12 @@ func makeUICrash(c context.Context, crash *Crash, build *Build) *uiCrash {
 		MachineInfoLink: textLink(textMachineInfo, crash.MachineInfo),
 		Assets:          makeUIAssets(build, crash, true),
 	}
+	for _, att := range crash.Attachments {
+		ui.Assets = append(ui.Assets, &uiAsset{
+			Title:       att.Name,
+			DownloadURL: textLink(textAttachment, att.Data),
+		})
+	}
 	if build != nil {
 		ui.uiBuild = makeUIBuild(c, build, true)
 	}
@@ -2199,9 +2411,9 @@ func loadManagers(c context.Context, accessLevel AccessLevel, ns string, filter
 		if accessLevel < AccessUser {
 			link = ""
 		}
-		uptime := mgr.CurrentUpTime
+		uptime, vms := mgr.CurrentUpTime, mgr.CurrentVMs
 		if now.Sub(mgr.LastAlive) > 6*time.Hour {
-			uptime = 0
+			uptime, vms = 0, 0
 		}
 		// TODO: also display how fresh the coverage report is (to display it on
 		// the main page -- this will reduce confusion).
@@ -2223,6 +2435,8 @@ func loadManagers(c context.Context, accessLevel AccessLevel, ns string, filter
 			FailedSyzBuildBugLink: bugLink(mgr.FailedSyzBuildBug),
 			LastActive:            mgr.LastAlive,
 			CurrentUpTime:         uptime,
+			CurrentVMs:            vms,
+			TotalVMs:              mgr.TotalVMs,
 			MaxCorpus:             stats.MaxCorpus,
 			MaxCover:              stats.MaxCover,
 			TotalFuzzingTime:      stats.TotalFuzzingTime,
@@ -21,25 +21,25 @@ func TestOnlyManagerFilter(t *testing.T) {
 
 	client := c.client
 	build1 := testBuild(1)
-	client.UploadBuild(build1)
+	client.UploadBuild(context.Background(), build1)
 	build2 := testBuild(2)
-	client.UploadBuild(build2)
+	client.UploadBuild(context.Background(), build2)
 
 	crash1 := testCrash(build1, 1)
 	crash1.Title = "only the first manager"
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build2, 2)
 	crash2.Title = "only the second manager"
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 
 	crashBoth1 := testCrash(build1, 3)
 	crashBoth1.Title = "both managers"
-	client.ReportCrash(crashBoth1)
+	client.ReportCrash(context.Background(), crashBoth1)
 
 	crashBoth2 := testCrash(build2, 4)
 	crashBoth2.Title = "both managers"
-	client.ReportCrash(crashBoth2)
+	client.ReportCrash(context.Background(), crashBoth2)
 
 	// Make sure all those bugs are present on the main page.
 	reply, err := c.AuthGET(AccessAdmin, "/test1")
@@ -92,17 +92,17 @@ func TestSubsystemFilterMain(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "first bug"
 	crash1.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
 	crash2.Title = "second bug"
 	crash2.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 
 	client.pollBugs(2)
 	// Make sure all those bugs are present on the main page.
@@ -132,17 +132,17 @@ func TestSubsystemFilterTerminal(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "first bug"
 	crash1.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
 	crash2.Title = "second bug"
 	crash2.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 
 	// Invalidate all these bugs.
 	polledBugs := client.pollBugs(2)
@@ -170,11 +170,11 @@ func TestMainBugFilters(t *testing.T) {
 	client := c.client
 	build1 := testBuild(1)
 	build1.Manager = "manager-name-123"
-	client.UploadBuild(build1)
+	client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
 	crash1.Title = "my-crash-title"
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 	client.pollBugs(1)
 
 	// The normal main page.
@@ -194,22 +194,60 @@ func TestMainBugFilters(t *testing.T) {
 	assert.Contains(t, string(reply), crash1.Title) // the bug has no subsystems
 }
 
+func TestCrashTypeFilter(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.client
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+
+	crash1 := testCrash(build, 1)
+	crash1.Title = "KASAN: use-after-free Read in foo"
+	crash1.Type = dashapi.CrashTypeKASAN
+	client.ReportCrash(context.Background(), crash1)
+	rep := client.pollBug()
+	c.expectEQ(rep.CrashType, dashapi.CrashTypeKASAN)
+
+	crash2 := testCrash(build, 2)
+	crash2.Title = "WARNING in bar"
+	crash2.Type = dashapi.CrashTypeWarning
+	client.ReportCrash(context.Background(), crash2)
+	rep = client.pollBug()
+	c.expectEQ(rep.CrashType, dashapi.CrashTypeWarning)
+
+	// Types unknown to the dashboard are not an error.
+	crash3 := testCrash(build, 3)
+	crash3.Title = "NEWTYPE in baz"
+	crash3.Type = "NEWTYPE"
+	client.ReportCrash(context.Background(), crash3)
+	rep = client.pollBug()
+	c.expectEQ(rep.CrashType, dashapi.CrashTypeUnknown)
+
+	reply, err := c.AuthGET(AccessAdmin, "/test1?crash_type=KASAN")
+	c.expectOK(err)
+	assert.Contains(t, string(reply), "Applied filters")
+	assert.Contains(t, string(reply), crash1.Title)
+	assert.NotContains(t, string(reply), crash2.Title)
+	assert.NotContains(t, string(reply), crash3.Title)
+}
+
 func TestSubsystemsList(t *testing.T) {
 	c := NewCtx(t)
 	defer c.Close()
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 	client.pollBug()
 
 	crash2 := testCrash(build, 2)
 	crash2.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	client.updateBug(client.pollBug().ID, dashapi.BugStatusInvalid, "")
 
 	_, err := c.AuthGET(AccessUser, "/cron/refresh_subsystems")
@@ -232,17 +270,17 @@ func TestSubsystemPage(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "test crash title"
 	crash1.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 	client.pollBug()
 
 	crash2 := testCrash(build, 2)
 	crash2.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	crash2.Title = "crash that must not be present"
 	client.updateBug(client.pollBug().ID, dashapi.BugStatusInvalid, "")
 
@@ -261,18 +299,18 @@ func TestMultiLabelFilter(t *testing.T) {
 
 	build1 := testBuild(1)
 	build1.Manager = "manager-name-123"
-	client.UploadBuild(build1)
+	client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
 	crash1.GuiltyFiles = []string{"a.c"}
 	crash1.Title = "crash-with-subsystem-A"
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 	c.pollEmailBug()
 
 	crash2 := testCrash(build1, 2)
 	crash2.GuiltyFiles = []string{"a.c"}
 	crash2.Title = "prio-crash-subsystem-A"
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz set prio: low\n",
 		EmailOptFrom("test@requester.com"), EmailOptCC([]string{mailingList}))
@@ -306,7 +344,7 @@ func TestAdminJobList(t *testing.T) {
 
 	client := c.client2
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Title = "some bug title"
@@ -314,7 +352,7 @@ func TestAdminJobList(t *testing.T) {
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
 	crash.ReproC = []byte("repro C")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollEmailBug()
 
 	c.advanceTime(24 * time.Hour)
@@ -416,7 +454,7 @@ func TestManagerPage(t *testing.T) {
 	client := c.makeClient(clientPublicEmail, keyPublicEmail, true)
 	build1 := testBuild(1)
 	build1.Manager = firstManager
-	c.expectOK(client.UploadBuild(build1))
+	c.expectOK(client.UploadBuild(context.Background(), build1))
 
 	c.advanceTime(time.Hour)
 	build2 := testBuild(2)
@@ -429,19 +467,19 @@ func TestManagerPage(t *testing.T) {
 			Log:    []byte("log\n"),
 		},
 	}
-	c.expectOK(client.ReportBuildError(buildErrorReq))
+	c.expectOK(client.ReportBuildError(context.Background(), buildErrorReq))
 	c.pollEmailBug()
 
 	c.advanceTime(time.Hour)
 	build3 := testBuild(3)
 	build3.Manager = firstManager
-	c.expectOK(client.UploadBuild(build3))
+	c.expectOK(client.UploadBuild(context.Background(), build3))
 
 	// And one more build from a different manager.
 	c.advanceTime(time.Hour)
 	build4 := testBuild(4)
 	build4.Manager = secondManager
-	c.expectOK(client.UploadBuild(build4))
+	c.expectOK(client.UploadBuild(context.Background(), build4))
 
 	// Query the first manager.
 	reply, err := c.AuthGET(AccessPublic, "/access-public-email/manager/"+firstManager)
@@ -473,7 +511,7 @@ func TestReproSubmitAccess(t *testing.T) {
 
 	build := testBuild(1)
 	build.Manager = "test-manager"
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	reply, err := c.AuthGET(AccessPublic, "/access-public/manager/test-manager")
 	c.expectOK(err)
@@ -485,3 +523,19 @@ func TestReproSubmitAccess(t *testing.T) {
 		assert.Contains(t, string(reply), "Send a reproducer")
 	}
 }
+
+func TestCrashFromHub(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrashWithRepro(build, 1)
+	crash.Flags = dashapi.CrashFromHub
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+
+	reply, err := c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("(hub)")))
+}
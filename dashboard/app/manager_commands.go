@@ -0,0 +1,268 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	db "google.golang.org/appengine/v2/datastore"
+)
+
+const (
+	// Managers execute commands within minutes, so there is no point in queueing too many.
+	maxPendingManagerCommands = 10
+	recentManagerCommands     = 10
+	maxBugRetests             = 10
+)
+
+func apiManagerCommands(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ManagerCommandsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	cmds, keys, err := loadPendingManagerCommands(c, ns, req.Manager)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dashapi.ManagerCommandsResp{}
+	for i, cmd := range cmds {
+		crashLog, _, err := getText(c, textCrashLog, cmd.CrashLog)
+		if err != nil {
+			return nil, err
+		}
+		mgrCmd := &dashapi.ManagerCommand{
+			ID:       keys[i].IntID(),
+			Type:     cmd.Type,
+			Arg:      cmd.Arg,
+			CrashLog: crashLog,
+			Author:   cmd.Author,
+			Time:     cmd.Time,
+		}
+		if cmd.Type == dashapi.ManagerCommandRetest {
+			// If the bug is gone, the manager fails the command because of the missing reproducer.
+			if bug, err := loadRetestBug(c, ns, cmd.Arg); err == nil {
+				mgrCmd.ReproSyz, mgrCmd.ReproOpts, err = loadRetestRepro(c, bug)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		resp.Commands = append(resp.Commands, mgrCmd)
+	}
+	return resp, nil
+}
+
+func apiAckManagerCommand(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.AckManagerCommandReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	key := db.NewKey(c, "ManagerCommand", "", req.ID, nil)
+	tx := func(c context.Context) error {
+		cmd := new(ManagerCommand)
+		if err := db.Get(c, key, cmd); err != nil {
+			if err == db.ErrNoSuchEntity {
+				return fmt.Errorf("%w: unknown command %v", ErrClientNotFound, req.ID)
+			}
+			return fmt.Errorf("failed to get command: %w", err)
+		}
+		if cmd.Namespace != ns || cmd.Manager != req.Manager {
+			return fmt.Errorf("%w: unknown command %v", ErrClientNotFound, req.ID)
+		}
+		if !cmd.Pending {
+			// The ack is retried.
+			return nil
+		}
+		cmd.Pending = false
+		cmd.Acked = timeNow(c)
+		cmd.Error = req.Error
+		if len(cmd.Error) > MaxStringLen {
+			cmd.Error = cmd.Error[:MaxStringLen]
+		}
+		_, err := db.Put(c, key, cmd)
+		return err
+	}
+	return nil, db.RunInTransaction(c, tx, nil)
+}
+
+// saveManagerCommand queues the command for the manager.
+func saveManagerCommand(c context.Context, ns, manager string, typ dashapi.ManagerCommandType,
+	arg, author string) error {
+	cmd := &ManagerCommand{
+		Namespace: ns,
+		Manager:   manager,
+		Type:      typ,
+		Arg:       arg,
+		Author:    author,
+		Time:      timeNow(c),
+		Pending:   true,
+	}
+	switch typ {
+	case dashapi.ManagerCommandStopFuzzing:
+		if arg != "" {
+			return fmt.Errorf("%w: %v does not take an argument", ErrClientBadRequest, typ)
+		}
+	case dashapi.ManagerCommandRerunRepro:
+		bug, err := findExistingBugForCrash(c, ns, []string{arg})
+		if err != nil {
+			return err
+		}
+		if bug == nil {
+			return fmt.Errorf("%w: no open bug with title %q", ErrClientBadRequest, arg)
+		}
+		crash, _, err := findCrashForBug(c, bug)
+		if err != nil {
+			return err
+		}
+		// Old crashes are purged along with their logs, so the command needs a copy.
+		crashLog, _, err := getText(c, textCrashLog, crash.Log)
+		if err != nil {
+			return err
+		}
+		if cmd.CrashLog, err = putText(c, ns, textCrashLog, crashLog); err != nil {
+			return err
+		}
+	case dashapi.ManagerCommandRetest:
+		bug, err := loadRetestBug(c, ns, arg)
+		if err != nil {
+			return err
+		}
+		if bug.ReproLevel == ReproLevelNone {
+			return fmt.Errorf("%w: bug %v has no reproducer", ErrClientBadRequest, arg)
+		}
+	default:
+		return fmt.Errorf("%w: unknown command %q", ErrClientBadRequest, typ)
+	}
+	pending, _, err := loadPendingManagerCommands(c, ns, manager)
+	if err != nil {
+		return err
+	}
+	if len(pending) >= maxPendingManagerCommands {
+		return fmt.Errorf("%w: manager %v has too many pending commands", ErrClientBadRequest, manager)
+	}
+	_, err = db.Put(c, db.NewIncompleteKey(c, "ManagerCommand", nil), cmd)
+	return err
+}
+
+func apiRetestResult(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.RetestResult)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	cmd := new(ManagerCommand)
+	if err := db.Get(c, db.NewKey(c, "ManagerCommand", "", req.CommandID, nil), cmd); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown command %v", ErrClientNotFound, req.CommandID)
+		}
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	if cmd.Namespace != ns || cmd.Manager != req.Manager || cmd.Type != dashapi.ManagerCommandRetest {
+		return nil, fmt.Errorf("%w: unknown command %v", ErrClientNotFound, req.CommandID)
+	}
+	retest := BugRetest{
+		CommandID:  req.CommandID,
+		Manager:    req.Manager,
+		BuildID:    req.BuildID,
+		Time:       timeNow(c),
+		Crashed:    req.Crashed,
+		CrashTitle: req.CrashTitle,
+		Error:      req.Error,
+	}
+	if len(retest.Error) > MaxStringLen {
+		retest.Error = retest.Error[:MaxStringLen]
+	}
+	bugKey := db.NewKey(c, "Bug", cmd.Arg, 0, nil)
+	tx := func(c context.Context) error {
+		bug := new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to get bug: %w", err)
+		}
+		for _, old := range bug.Retests {
+			if old.CommandID == retest.CommandID {
+				// The upload is retried.
+				return nil
+			}
+		}
+		bug.Retests = append(bug.Retests, retest)
+		if len(bug.Retests) > maxBugRetests {
+			bug.Retests = bug.Retests[len(bug.Retests)-maxBugRetests:]
+		}
+		_, err := db.Put(c, bugKey, bug)
+		return err
+	}
+	return nil, db.RunInTransaction(c, tx, nil)
+}
+
+// loadRetestBug loads the bug with the ID (see bug.keyHash) for dashapi.ManagerCommandRetest.
+func loadRetestBug(c context.Context, ns, id string) (*Bug, error) {
+	bug := new(Bug)
+	if err := db.Get(c, db.NewKey(c, "Bug", id, 0, nil), bug); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, fmt.Errorf("%w: unknown bug %q", ErrClientBadRequest, id)
+		}
+		return nil, fmt.Errorf("failed to get bug: %w", err)
+	}
+	if bug.Namespace != ns {
+		return nil, fmt.Errorf("%w: unknown bug %q", ErrClientBadRequest, id)
+	}
+	return bug, nil
+}
+
+func loadRetestRepro(c context.Context, bug *Bug) ([]byte, []byte, error) {
+	crash, _, err := findCrashForBug(c, bug)
+	if err != nil {
+		return nil, nil, err
+	}
+	if crash.ReproSyz == 0 {
+		return nil, nil, nil
+	}
+	reproSyz, _, err := getText(c, textReproSyz, crash.ReproSyz)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reproSyz, crash.ReproOpts, nil
+}
+
+func loadPendingManagerCommands(c context.Context, ns, manager string) ([]*ManagerCommand, []*db.Key, error) {
+	var cmds []*ManagerCommand
+	keys, err := db.NewQuery("ManagerCommand").
+		Filter("Namespace=", ns).
+		Filter("Manager=", manager).
+		Filter("Pending=", true).
+		Order("Time").
+		GetAll(c, &cmds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query manager commands: %w", err)
+	}
+	return cmds, keys, nil
+}
+
+func loadRecentManagerCommands(c context.Context, ns, manager string) ([]*uiManagerCommand, error) {
+	var cmds []*ManagerCommand
+	_, err := db.NewQuery("ManagerCommand").
+		Filter("Namespace=", ns).
+		Filter("Manager=", manager).
+		Order("-Time").
+		Limit(recentManagerCommands).
+		GetAll(c, &cmds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query manager commands: %w", err)
+	}
+	var ret []*uiManagerCommand
+	for _, cmd := range cmds {
+		ret = append(ret, &uiManagerCommand{
+			Type:    cmd.Type,
+			Arg:     cmd.Arg,
+			Author:  cmd.Author,
+			Time:    cmd.Time,
+			Pending: cmd.Pending,
+			Acked:   cmd.Acked,
+			Error:   cmd.Error,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/prog"
+	db "google.golang.org/appengine/v2/datastore"
+)
+
+// Reasons are only shown on the manager page, and for transitively disabled syscalls they
+// can list lots of other syscalls.
+const maxUnsupportedReasonLen = 200
+
+func apiManagerSyscalls(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ManagerSyscallsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" {
+		return nil, fmt.Errorf("%w: no manager", ErrClientBadRequest)
+	}
+	if len(req.Enabled)+len(req.Unsupported) > dashapi.MaxManagerSyscalls {
+		return nil, fmt.Errorf("%w: too many syscalls (%v)", ErrClientBadRequest,
+			len(req.Enabled)+len(req.Unsupported))
+	}
+	syscalls := &ManagerSyscalls{
+		Namespace: ns,
+		Manager:   req.Manager,
+		BuildID:   req.BuildID,
+		Time:      timeNow(c),
+		Enabled:   append([]string{}, req.Enabled...),
+	}
+	sort.Strings(syscalls.Enabled)
+	for name, reason := range req.Unsupported {
+		if len(reason) > maxUnsupportedReasonLen {
+			reason = reason[:maxUnsupportedReasonLen] + "..."
+		}
+		syscalls.Unsupported = append(syscalls.Unsupported, UnsupportedSyscall{name, reason})
+	}
+	sort.Slice(syscalls.Unsupported, func(i, j int) bool {
+		return syscalls.Unsupported[i].Name < syscalls.Unsupported[j].Name
+	})
+	if _, err := db.Put(c, managerSyscallsKey(c, ns, req.Manager), syscalls); err != nil {
+		return nil, fmt.Errorf("failed to put manager syscalls: %w", err)
+	}
+	return nil, nil
+}
+
+func managerSyscallsKey(c context.Context, ns, manager string) *db.Key {
+	return db.NewKey(c, "ManagerSyscalls", "", 1, mgrKey(c, ns, manager))
+}
+
+// loadManagerSyscalls returns nil if the manager has not uploaded its syscalls.
+func loadManagerSyscalls(c context.Context, ns, manager string) (*ManagerSyscalls, error) {
+	syscalls := new(ManagerSyscalls)
+	if err := db.Get(c, managerSyscallsKey(c, ns, manager), syscalls); err != nil {
+		if err == db.ErrNoSuchEntity {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get manager syscalls: %w", err)
+	}
+	return syscalls, nil
+}
+
+// loadReproManagers describes which managers have all the syscalls of the bug reproducer enabled.
+// Returns nil if the bug has no syz reproducer or no managers have uploaded their syscalls,
+// or if the reproducers of the namespace are encrypted.
+func loadReproManagers(c context.Context, bug *Bug) ([]*uiReproManager, error) {
+	if bug.ReproLevel == ReproLevelNone || getNsConfig(c, bug.Namespace).EncryptedPayloads {
+		return nil, nil
+	}
+	crash, _, err := findCrashForBug(c, bug)
+	if err != nil {
+		return nil, err
+	}
+	if crash.ReproSyz == 0 {
+		return nil, nil
+	}
+	reproSyz, _, err := getText(c, textReproSyz, crash.ReproSyz)
+	if err != nil {
+		return nil, err
+	}
+	calls, _, err := prog.CallSet(reproSyz)
+	if err != nil {
+		// Old reproducers may be unparsable, the section is just not shown for them.
+		return nil, nil
+	}
+	var all []*ManagerSyscalls
+	if _, err := db.NewQuery("ManagerSyscalls").
+		Filter("Namespace=", bug.Namespace).
+		GetAll(c, &all); err != nil {
+		return nil, fmt.Errorf("failed to query manager syscalls: %w", err)
+	}
+	var ret []*uiReproManager
+	for _, syscalls := range all {
+		enabled := make(map[string]bool, len(syscalls.Enabled))
+		for _, call := range syscalls.Enabled {
+			enabled[call] = true
+		}
+		item := &uiReproManager{Manager: syscalls.Manager}
+		for call := range calls {
+			if !enabled[call] {
+				item.Missing = append(item.Missing, call)
+			}
+		}
+		sort.Strings(item.Missing)
+		ret = append(ret, item)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if (len(ret[i].Missing) == 0) != (len(ret[j].Missing) == 0) {
+			return len(ret[i].Missing) == 0
+		}
+		return ret[i].Manager < ret[j].Manager
+	})
+	return ret, nil
+}
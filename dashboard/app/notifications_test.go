@@ -20,10 +20,10 @@ func TestEmailNotifUpstreamEmbargo(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	report := c.pollEmailBug()
 	c.expectEQ(report.To, []string{"test@syzkaller.com"})
 
@@ -48,11 +48,11 @@ func TestEmailNotifUpstreamSkip(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Title = "skip with repro 1"
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	report := c.pollEmailBug()
 	c.expectEQ(report.To, []string{"test@syzkaller.com"})
 
@@ -62,7 +62,7 @@ func TestEmailNotifUpstreamSkip(t *testing.T) {
 	// Now upload repro and it should be auto-upstreamed.
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("getpid()")
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	notifUpstream := c.pollEmailBug()
 	upstreamReport := c.pollEmailBug()
 	c.expectEQ(notifUpstream.Sender, report.Sender)
@@ -78,15 +78,15 @@ func TestEmailNotifBadFix(t *testing.T) {
 	client := c.publicClient
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Fake more active managers.
 	for i := 1; i < 5; i++ {
-		client.UploadBuild(testBuild(i + 1))
+		client.UploadBuild(context.Background(), testBuild(i+1))
 	}
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	report := c.pollEmailBug()
 	c.expectEQ(report.To, []string{"test@syzkaller.com"})
 	_, extBugID, err := email.RemoveAddrContext(report.Sender)
@@ -231,11 +231,11 @@ func TestEmailNotifObsoleted(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.Maintainers = []string{"maintainer@syzkaller.com"}
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	report := c.pollEmailBug()
 	// Need to upstream so that it's not auto-upstreamed before obsoleted.
 	c.incomingEmail(report.Sender, "#syz upstream")
@@ -244,7 +244,7 @@ func TestEmailNotifObsoleted(t *testing.T) {
 	c.incomingEmail(report.Sender, "wow", EmailOptCC([]string{"somebody@else.com"}))
 
 	// Bug is open, new crashes don't create new bug.
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	c.expectNoEmail()
 
 	// Not yet.
@@ -261,7 +261,7 @@ func TestEmailNotifObsoleted(t *testing.T) {
 		"default@sender.com", "somebody@else.com"})
 
 	// New crash must create new bug.
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	report = c.pollEmailBug()
 	c.expectEQ(report.Subject, "title1 (2)")
 	// Now the same, but for the last reporting (must have smaller CC list).
@@ -286,12 +286,12 @@ func TestEmailNotifNotObsoleted(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 
 	// Crashes with repro are not auto-obsoleted.
 	crash1 := testCrash(build, 1)
 	crash1.ReproSyz = []byte("repro")
-	c.client2.ReportCrash(crash1)
+	c.client2.ReportCrash(context.Background(), crash1)
 	report1 := c.pollEmailBug()
 	c.incomingEmail(report1.Sender, "#syz upstream")
 	report1 = c.pollEmailBug()
@@ -299,7 +299,7 @@ func TestEmailNotifNotObsoleted(t *testing.T) {
 
 	// This crash will get another crash later.
 	crash2 := testCrash(build, 2)
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	report2 := c.pollEmailBug()
 	c.incomingEmail(report2.Sender, "#syz upstream")
 	report2 = c.pollEmailBug()
@@ -307,7 +307,7 @@ func TestEmailNotifNotObsoleted(t *testing.T) {
 
 	// This crash will get some activity later.
 	crash3 := testCrash(build, 3)
-	c.client2.ReportCrash(crash3)
+	c.client2.ReportCrash(context.Background(), crash3)
 	report3 := c.pollEmailBug()
 	c.incomingEmail(report3.Sender, "#syz upstream")
 	report3 = c.pollEmailBug()
@@ -315,7 +315,7 @@ func TestEmailNotifNotObsoleted(t *testing.T) {
 	// This will be obsoleted (just to check that we have timings right).
 	c.advanceTime(24 * time.Hour)
 	crash4 := testCrash(build, 4)
-	c.client2.ReportCrash(crash4)
+	c.client2.ReportCrash(context.Background(), crash4)
 	report4 := c.pollEmailBug()
 	c.incomingEmail(report4.Sender, "#syz upstream")
 	report4 = c.pollEmailBug()
@@ -323,7 +323,7 @@ func TestEmailNotifNotObsoleted(t *testing.T) {
 	c.advanceTime(59 * 24 * time.Hour)
 	c.expectNoEmail()
 
-	c.client2.ReportCrash(crash2)
+	c.client2.ReportCrash(context.Background(), crash2)
 	c.incomingEmail(report3.Sender, "I am looking at it")
 
 	c.advanceTime(5 * 24 * time.Hour)
@@ -345,9 +345,9 @@ func TestEmailNotifObsoletedManager(t *testing.T) {
 
 	build := testBuild(1)
 	build.Manager = noFixBisectionManager
-	c.client2.UploadBuild(build)
+	c.client2.UploadBuild(context.Background(), build)
 	crash := testCrashWithRepro(build, 1)
-	c.client2.ReportCrash(crash)
+	c.client2.ReportCrash(context.Background(), crash)
 	report := c.pollEmailBug()
 	c.incomingEmail(report.Sender, "#syz upstream")
 	report = c.pollEmailBug()
@@ -362,14 +362,14 @@ func TestExtNotifUpstreamEmbargo(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -386,14 +386,14 @@ func TestExtNotifUpstreamOnHold(t *testing.T) {
 	defer c.Close()
 
 	build1 := testBuild(1)
-	c.client.UploadBuild(build1)
+	c.client.UploadBuild(context.Background(), build1)
 
 	crash1 := testCrash(build1, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep := c.client.pollBug()
 
 	// Specify fixing commit for the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusOpen,
 		OnHold: true,
@@ -18,6 +18,7 @@ type publicAPIBugDescription struct {
 	// links to the discussions
 	Discussions []string                    `json:"discussions,omitempty"`
 	Crashes     []publicAPICrashDescription `json:"crashes,omitempty"`
+	CVEs        []string                    `json:"cves,omitempty"`
 }
 
 type vcsCommit struct {
@@ -85,6 +86,7 @@ func getExtAPIDescrForBugPage(bugPage *uiBugPage) *publicAPIBugDescription {
 			}
 			return res
 		}(),
+		CVEs: bugPage.Bug.CVEs,
 	}
 }
 
@@ -112,6 +114,7 @@ type publicAPIBug struct {
 	Link        string      `json:"link"`
 	LastUpdated string      `json:"last-updated,omitempty"`
 	FixCommits  []vcsCommit `json:"fix-commits,omitempty"`
+	CVEs        []string    `json:"cves,omitempty"`
 }
 
 func getExtAPIDescrForBugGroups(bugGroups []*uiBugGroup) *publicAPIBugGroup {
@@ -125,6 +128,7 @@ func getExtAPIDescrForBugGroups(bugGroups []*uiBugGroup) *publicAPIBugGroup {
 						Title:      bug.Title,
 						Link:       bug.Link,
 						FixCommits: getBugFixCommits(bug),
+						CVEs:       bug.CVEs,
 					})
 				}
 			}
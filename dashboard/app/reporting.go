@@ -15,8 +15,10 @@ import (
 	"time"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/csource"
 	"github.com/google/syzkaller/pkg/email"
 	"github.com/google/syzkaller/pkg/html"
+	"github.com/google/syzkaller/pkg/vcs"
 	"github.com/google/syzkaller/sys/targets"
 	db "google.golang.org/appengine/v2/datastore"
 	"google.golang.org/appengine/v2/log"
@@ -38,23 +40,38 @@ const (
 	syzReproPrefix = "# See https://goo.gl/kgGztJ for information about syzkaller reproducers.\n"
 )
 
+// Trying to report too many at once is known to cause OOMs.
+// But new bugs appear incrementally and polling is frequent enough,
+// so reporting lots of bugs at once is also not necessary.
+const defaultPollReports = 3
+
 // reportingPoll is called by backends to get list of bugs that need to be reported.
-func reportingPollBugs(c context.Context, typ string) []*dashapi.BugReport {
+// It also returns the cursor to continue polling with if there may be more bugs to report.
+func reportingPollBugs(c context.Context, req *dashapi.PollBugsRequest) ([]*dashapi.BugReport, string) {
 	state, err := loadReportingState(c)
 	if err != nil {
 		log.Errorf(c, "%v", err)
-		return nil
+		return nil, ""
 	}
 	bugs, _, err := loadOpenBugs(c)
 	if err != nil {
 		log.Errorf(c, "%v", err)
-		return nil
+		return nil, ""
 	}
 	log.Infof(c, "fetched %v bugs", len(bugs))
 	sort.Sort(bugReportSorter(bugs))
+	maxReports := min(req.MaxReports, dashapi.MaxPollReports)
+	if maxReports <= 0 {
+		maxReports = defaultPollReports
+	}
+	bugs = bugsAfterCursor(c, bugs, req.Cursor)
 	var reports []*dashapi.BugReport
 	for _, bug := range bugs {
-		rep, err := handleReportBug(c, typ, state, bug)
+		if req.Namespace != "" && bug.Namespace != req.Namespace ||
+			req.Manager != "" && !stringInList(bug.HappenedOn, req.Manager) {
+			continue
+		}
+		rep, err := handleReportBug(c, req.Type, state, bug)
 		if err != nil {
 			log.Errorf(c, "%v: failed to report bug '%v': %v", bug.Namespace, bug.Title, err)
 			continue
@@ -63,14 +80,25 @@ func reportingPollBugs(c context.Context, typ string) []*dashapi.BugReport {
 			continue
 		}
 		reports = append(reports, rep)
-		// Trying to report too many at once is known to cause OOMs.
-		// But new bugs appear incrementally and polling is frequent enough,
-		// so reporting lots of bugs at once is also not necessary.
-		if len(reports) == 3 {
-			break
+		if len(reports) == maxReports {
+			return reports, bug.keyHash(c)
+		}
+	}
+	return reports, ""
+}
+
+// bugsAfterCursor returns the bugs that follow the cursor bug in the reporting order.
+// If the cursor bug is not open anymore, polling starts over.
+func bugsAfterCursor(c context.Context, bugs []*Bug, cursor string) []*Bug {
+	if cursor == "" {
+		return bugs
+	}
+	for i, bug := range bugs {
+		if bug.keyHash(c) == cursor {
+			return bugs[i+1:]
 		}
 	}
-	return reports
+	return bugs
 }
 
 func handleReportBug(c context.Context, typ string, state *ReportingState, bug *Bug) (
@@ -120,6 +148,12 @@ func needReport(c context.Context, typ string, state *ReportingState, bug *Bug)
 		reporting, bugReporting = nil, nil
 		return
 	}
+	if bug.Kind == dashapi.CrashLeak && bug.ReproLevel == ReproLevelNone {
+		// Many leak reports are false positives, only reproducible leaks are reported.
+		status = fmt.Sprintf("%v: waiting for leak repro", reporting.DisplayTitle)
+		reporting, bugReporting = nil, nil
+		return
+	}
 	if crashNeedsRepro(bug.Title) && bug.ReproLevel < ReproLevelC &&
 		timeSince(c, bug.FirstTime) < cfg.WaitForRepro {
 		status = fmt.Sprintf("%v: waiting for C repro", reporting.DisplayTitle)
@@ -136,6 +170,12 @@ func needReport(c context.Context, typ string, state *ReportingState, bug *Bug)
 		reporting, bugReporting = nil, nil
 		return
 	}
+	if bug.embargoed(c) && reporting.AccessLevel == AccessPublic {
+		status = fmt.Sprintf("%v: embargoed until %v", reporting.DisplayTitle,
+			html.FormatTime(bug.EmbargoUntil))
+		reporting, bugReporting = nil, nil
+		return
+	}
 
 	// Limit number of reports sent per day.
 	if ent.Sent >= reporting.DailyLimit {
@@ -415,7 +455,7 @@ func createNotification(c context.Context, typ dashapi.BugNotif, public bool, te
 		Title:     bug.displayTitle(),
 		Text:      text,
 		Public:    public,
-		Link:      fmt.Sprintf("%v/bug?extid=%v", appURL(c), bugReporting.ID),
+		Link:      externalBugLink(c, bugReporting),
 		CC:        kernelRepo.CC.Always,
 	}
 	if public {
@@ -573,7 +613,32 @@ func crashBugReport(c context.Context, bug *Bug, crash *Crash, crashKey *db.Key,
 		HappenedOn:      managersToRepos(c, bug.Namespace, bug.HappenedOn),
 		Manager:         crash.Manager,
 		Assets:          assetList,
-		ReportElements:  &dashapi.ReportElements{GuiltyFiles: crash.ReportElements.GuiltyFiles},
+		ReportElements: &dashapi.ReportElements{
+			GuiltyFiles: crash.ReportElements.GuiltyFiles,
+			Subsystems:  crash.ReportElements.Subsystems,
+		},
+	}
+	if crash.Machine != (dashapi.Machine{}) {
+		machine := crash.Machine
+		rep.Machine = &machine
+	}
+	rep.CrashType = crash.Type
+	for _, att := range crash.Attachments {
+		mimeType := att.MIMEType
+		if mimeType == "" {
+			mimeType = dashapi.DefaultMIMEType
+		}
+		rep.Attachments = append(rep.Attachments, dashapi.CrashAttachmentLink{
+			Name:     att.Name,
+			MIMEType: mimeType,
+			Link:     externalLink(c, textAttachment, att.Data),
+		})
+	}
+	if rep.CrashType == dashapi.CrashTypeUnknown {
+		rep.CrashType = bug.CrashType
+	}
+	if len(crash.ReproOpts) != 0 {
+		rep.ReproOptions = reproOptions(crash.ReproOpts)
 	}
 	if !crash.ReproIsRevoked {
 		rep.ReproCLink = externalLink(c, textReproC, crash.ReproC)
@@ -582,7 +647,8 @@ func crashBugReport(c context.Context, bug *Bug, crash *Crash, crashKey *db.Key,
 			return nil, err
 		}
 		rep.ReproSyzLink = externalLink(c, textReproSyz, crash.ReproSyz)
-		rep.ReproSyz, err = loadReproSyz(c, crash)
+		rep.ReproLogLink = externalLink(c, textReproLog, crash.ReproLog)
+		rep.ReproSyz, err = loadReproSyz(c, bug.Namespace, crash)
 		if err != nil {
 			return nil, err
 		}
@@ -616,10 +682,10 @@ func crashBugReport(c context.Context, bug *Bug, crash *Crash, crashKey *db.Key,
 	return rep, nil
 }
 
-func loadReproSyz(c context.Context, crash *Crash) ([]byte, error) {
+func loadReproSyz(c context.Context, ns string, crash *Crash) ([]byte, error) {
 	reproSyz, _, err := getText(c, textReproSyz, crash.ReproSyz)
-	if err != nil || len(reproSyz) == 0 {
-		return nil, err
+	if err != nil || len(reproSyz) == 0 || getNsConfig(c, ns).EncryptedPayloads {
+		return reproSyz, err
 	}
 	buf := new(bytes.Buffer)
 	buf.WriteString(syzReproPrefix)
@@ -630,6 +696,56 @@ func loadReproSyz(c context.Context, crash *Crash) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// reproOptions parses the serialized csource.Options stored along with the reproducer.
+// It returns nil if the options can't be parsed.
+func reproOptions(data []byte) *dashapi.ReproOptions {
+	if len(data) == 0 {
+		return nil
+	}
+	opts, err := csource.DeserializeOptions(data)
+	if err != nil {
+		return nil
+	}
+	ret := &dashapi.ReproOptions{
+		Threaded:    opts.Threaded,
+		Repeat:      opts.Repeat,
+		RepeatTimes: opts.RepeatTimes,
+		Procs:       opts.Procs,
+		Slowdown:    opts.Slowdown,
+		Sandbox:     opts.Sandbox,
+		SandboxArg:  opts.SandboxArg,
+		Leak:        opts.Leak,
+		Fault:       opts.Fault,
+		FaultCall:   opts.FaultCall,
+		FaultNth:    opts.FaultNth,
+	}
+	// The names are the same as in the syz-execprog -enable flag.
+	for _, feat := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"tun", opts.NetInjection},
+		{"net_dev", opts.NetDevices},
+		{"net_reset", opts.NetReset},
+		{"cgroups", opts.Cgroups},
+		{"binfmt_misc", opts.BinfmtMisc},
+		{"close_fds", opts.CloseFDs},
+		{"devlink_pci", opts.DevlinkPCI},
+		{"nic_vf", opts.NicVF},
+		{"usb", opts.USB},
+		{"vhci", opts.VhciInjection},
+		{"wifi", opts.Wifi},
+		{"ieee802154", opts.IEEE802154},
+		{"sysctl", opts.Sysctl},
+		{"swap", opts.Swap},
+	} {
+		if feat.enabled {
+			ret.Features = append(ret.Features, feat.name)
+		}
+	}
+	return ret
+}
+
 // fillBugReport fills common report fields for bug and job reports.
 func fillBugReport(c context.Context, rep *dashapi.BugReport, bug *Bug, bugReporting *BugReporting,
 	build *Build) error {
@@ -648,8 +764,13 @@ func fillBugReport(c context.Context, rep *dashapi.BugReport, bug *Bug, bugRepor
 	rep.Namespace = bug.Namespace
 	rep.ID = bugReporting.ID
 	rep.Title = bug.displayTitle()
-	rep.Link = fmt.Sprintf("%v/bug?extid=%v", appURL(c), bugReporting.ID)
+	rep.Link = externalBugLink(c, bugReporting)
 	rep.CreditEmail = creditEmail
+	rep.Severity = bug.severity()
+	rep.CVEs = bug.CVEs
+	if bug.embargoed(c) {
+		rep.EmbargoUntil = bug.EmbargoUntil
+	}
 	rep.OS = build.OS
 	rep.Arch = build.Arch
 	rep.VMArch = build.VMArch
@@ -676,10 +797,24 @@ func fillBugReport(c context.Context, rep *dashapi.BugReport, bug *Bug, bugRepor
 		rep.Maintainers = email.MergeEmailLists(rep.Maintainers,
 			subsystemMaintainers(c, rep.Namespace, item.Value))
 	}
+	for _, note := range bug.Notes {
+		rep.Notes = append(rep.Notes, dashapi.BugNote{
+			Time:   note.Time,
+			Author: note.Author,
+			Title:  note.Title,
+			Link:   externalLink(c, textBugNote, note.Text),
+		})
+	}
 	for _, addr := range bug.UNCC {
 		rep.CC = email.RemoveFromEmailList(rep.CC, addr)
 		rep.Maintainers = email.RemoveFromEmailList(rep.Maintainers, addr)
 	}
+	if getNsConfig(c, bug.Namespace).EncryptedPayloads {
+		// The texts are ciphertexts, the reportings only link them.
+		// The reproducers are kept since the reportings determine the repro level by them.
+		rep.EncryptedPayloads = true
+		rep.Log, rep.Report, rep.KernelConfig = nil, nil, nil
+	}
 	return nil
 }
 
@@ -830,6 +965,8 @@ func incomingCommand(c context.Context, cmd *dashapi.BugUpdate) (bool, string, e
 		log.Errorf(c, "%v (%v)", reason, err)
 	} else if !ok && reason != "" {
 		log.Errorf(c, "invalid update: %v", reason)
+	} else if ok {
+		bumpReportingGeneration(c)
 	}
 	return ok, reason, err
 }
@@ -844,6 +981,25 @@ func incomingCommandImpl(c context.Context, cmd *dashapi.BugUpdate) (bool, strin
 			return false, fmt.Sprintf("bad commit title: %q", com), nil
 		}
 	}
+	if cmd.FixRepo != "" || cmd.FixBranch != "" {
+		if len(cmd.FixCommits) == 0 {
+			return false, "fix tree without fix commits", nil
+		}
+		if !vcs.CheckRepoAddress(cmd.FixRepo) || cmd.FixBranch != "" && !vcs.CheckBranch(cmd.FixBranch) {
+			return false, fmt.Sprintf("bad fix tree: %q %q", cmd.FixRepo, cmd.FixBranch), nil
+		}
+	}
+	if !cmd.Severity.Known() {
+		return false, fmt.Sprintf("bad severity: %v", cmd.Severity), nil
+	}
+	if cmd.ClearEmbargo && !cmd.EmbargoUntil.IsZero() {
+		return false, "both EmbargoUntil and ClearEmbargo are set", nil
+	}
+	for _, cve := range cmd.CVEs {
+		if !dashapi.ValidCVE(cve) {
+			return false, fmt.Sprintf("bad CVE ID: %q", cve), nil
+		}
+	}
 	bug, bugKey, err := findBugByReportingID(c, cmd.ID)
 	if err != nil {
 		return false, internalError, err
@@ -1014,6 +1170,11 @@ func incomingCommandUpdate(c context.Context, now time.Time, cmd *dashapi.BugUpd
 		if !reflect.DeepEqual(bug.Commits, cmd.FixCommits) {
 			bug.updateCommits(cmd.FixCommits, now)
 		}
+		// Updates without a tree (e.g. "#syz fix" emails) don't forget the known tree of the same commits.
+		if cmd.FixRepo != "" {
+			bug.FixRepo = cmd.FixRepo
+			bug.FixBranch = cmd.FixBranch
+		}
 	}
 	toReport := append([]int64{}, cmd.ReportCrashIDs...)
 	if cmd.CrashID != 0 {
@@ -1046,6 +1207,22 @@ func incomingCommandUpdate(c context.Context, now time.Time, cmd *dashapi.BugUpd
 	if bugReporting.ReproLevel < cmd.ReproLevel {
 		bugReporting.ReproLevel = cmd.ReproLevel
 	}
+	if cmd.Severity != dashapi.SeverityUnknown {
+		bug.Severity = cmd.Severity
+	}
+	if cmd.ClearEmbargo {
+		// Not reset to zero, otherwise new crashes would embargo the bug again.
+		bug.EmbargoUntil = now
+	} else if cmd.EmbargoUntil.After(bug.EmbargoUntil) {
+		bug.EmbargoUntil = cmd.EmbargoUntil
+	}
+	if cmd.ResetCVEs {
+		bug.CVEs = nil
+	}
+	bug.CVEs = mergeStringList(bug.CVEs, cmd.CVEs)
+	if len(bug.CVEs) > dashapi.MaxBugCVEs {
+		return false, fmt.Sprintf("too many CVE IDs (max %v)", dashapi.MaxBugCVEs), nil
+	}
 	if bug.Status != BugStatusDup {
 		bug.DupOf = ""
 	}
@@ -1396,7 +1573,7 @@ func loadFullBugInfo(c context.Context, bug *Bug, bugKey *db.Key,
 			Status:     status,
 			Namespace:  similarBug.Namespace,
 			ReproLevel: similarBug.ReproLevel,
-			Link:       fmt.Sprintf("%v/bug?extid=%v", appURL(c), bugReporting.ID),
+			Link:       externalBugLink(c, bugReporting),
 			ReportLink: bugReporting.Link,
 			Closed:     similarBug.Closed,
 		})
@@ -1520,6 +1697,9 @@ type bugReportSorter []*Bug
 func (a bugReportSorter) Len() int      { return len(a) }
 func (a bugReportSorter) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a bugReportSorter) Less(i, j int) bool {
+	if sevI, sevJ := a[i].severity(), a[j].severity(); sevI != sevJ {
+		return sevI > sevJ
+	}
 	if a[i].ReproLevel != a[j].ReproLevel {
 		return a[i].ReproLevel > a[j].ReproLevel
 	}
@@ -0,0 +1,36 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/mail"
+)
+
+const bugzillaType = "bugzilla"
+
+// BugzillaConfig is the reporting config for bugs filed by tools/syz-bugzilla.
+// The tool receives it in dashapi.BugReport.Config, so the fields are shared with the tool.
+type BugzillaConfig struct {
+	Product   string
+	Component string
+	Version   string   // "unspecified" if empty
+	CC        []string // emails added to the CC list of every filed bug
+}
+
+func (cfg *BugzillaConfig) Type() string {
+	return bugzillaType
+}
+
+func (cfg *BugzillaConfig) Validate() error {
+	if cfg.Product == "" || cfg.Component == "" {
+		return fmt.Errorf("bugzilla config: empty product or component")
+	}
+	for _, email := range cfg.CC {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("bad email address %q: %w", email, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const chatType = "chat"
+
+// ChatConfig is the reporting config for bugs posted to Slack or Matrix by tools/syz-chat.
+// The tool receives it in dashapi.BugReport.Config, so the fields are shared with the tool.
+// Channels are "slack:<channel ID or name>" or "matrix:<room ID>".
+type ChatConfig struct {
+	Channel string
+	// Bugs in the subsystems are posted to the specified channels instead of Channel
+	// (to all of them if the bug has several such subsystems).
+	Subsystems map[string]string
+}
+
+func (cfg *ChatConfig) Type() string {
+	return chatType
+}
+
+func (cfg *ChatConfig) Validate() error {
+	if err := validateChatChannel(cfg.Channel); err != nil {
+		return err
+	}
+	for subsystem, channel := range cfg.Subsystems {
+		if err := validateChatChannel(channel); err != nil {
+			return fmt.Errorf("subsystem %v: %w", subsystem, err)
+		}
+	}
+	return nil
+}
+
+func validateChatChannel(channel string) error {
+	typ, name, _ := strings.Cut(channel, ":")
+	if (typ != "slack" && typ != "matrix") || name == "" {
+		return fmt.Errorf("chat config: bad channel %q", channel)
+	}
+	return nil
+}
@@ -21,10 +21,10 @@ import (
 	"time"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/dashboard/dashapi/mailer"
 	"github.com/google/syzkaller/pkg/email"
 	"github.com/google/syzkaller/pkg/email/lore"
 	"github.com/google/syzkaller/pkg/html"
-	"github.com/google/syzkaller/sys/targets"
 	"google.golang.org/appengine/v2"
 	db "google.golang.org/appengine/v2/datastore"
 	"google.golang.org/appengine/v2/log"
@@ -50,12 +50,6 @@ func initEmailReporting() {
 
 const (
 	emailType = "email"
-	// This plays an important role at least for job replies.
-	// If we CC a kernel mailing list and it uses Patchwork,
-	// then any emails with a patch attached create a new patch
-	// entry pending for review. The prefix makes Patchwork
-	// treat it as a comment for a previous patch.
-	replySubjectPrefix = "Re: "
 
 	replyNoBugID = "I see the command but can't find the corresponding bug.\n" +
 		"Please resend the email to %[1]v address\n" +
@@ -149,7 +143,7 @@ func emailPollBugLists(c context.Context) error {
 }
 
 func emailPollBugs(c context.Context) error {
-	reports := reportingPollBugs(c, emailType)
+	reports, _ := reportingPollBugs(c, &dashapi.PollBugsRequest{Type: emailType})
 	for _, rep := range reports {
 		if err := emailSendBugReport(c, rep); err != nil {
 			log.Errorf(c, "emailPollBugs: %v", err)
@@ -343,41 +337,21 @@ func emailReport(c context.Context, rep *dashapi.BugReport) error {
 	if err := json.Unmarshal(rep.Config, cfg); err != nil {
 		return fmt.Errorf("failed to unmarshal email config: %w", err)
 	}
-	if rep.UserSpaceArch == targets.AMD64 {
-		// This is default, so don't include the info.
-		rep.UserSpaceArch = ""
+	body, err := mailer.Body(rep)
+	if err != nil {
+		return err
 	}
-	templ := ""
-	switch rep.Type {
-	case dashapi.ReportNew, dashapi.ReportRepro:
-		templ = "mail_bug.txt"
-	case dashapi.ReportTestPatch:
-		templ = "mail_test_result.txt"
+	if rep.Type == dashapi.ReportTestPatch {
 		cfg.MailMaintainers = false
-	case dashapi.ReportBisectCause:
-		templ = "mail_bisect_result.txt"
-	case dashapi.ReportBisectFix:
-		if rep.BisectFix.CrossTree {
-			templ = "mail_fix_candidate.txt"
-			if rep.BisectFix.Commit == nil {
-				return fmt.Errorf("reporting failed fix candidate bisection for %s", rep.ID)
-			}
-		} else {
-			templ = "mail_bisect_result.txt"
-		}
-	default:
-		return fmt.Errorf("unknown report type %v", rep.Type)
 	}
-	return sendMailTemplate(c, &mailSendParams{
-		templateName: templ,
-		templateArg:  rep,
-		cfg:          cfg,
-		title:        generateEmailBugTitle(rep, cfg),
-		reportID:     rep.ID,
-		replyTo:      rep.ExtID,
-		cc:           rep.CC,
-		maintainers:  rep.Maintainers,
-	})
+	return sendMailBody(c, &mailSendParams{
+		cfg:         cfg,
+		title:       mailer.Title(rep),
+		reportID:    rep.ID,
+		replyTo:     rep.ExtID,
+		cc:          rep.CC,
+		maintainers: rep.Maintainers,
+	}, body)
 }
 
 func emailListReport(c context.Context, rep *dashapi.BugListReport, cfg *EmailConfig) error {
@@ -426,6 +400,14 @@ type mailSendParams struct {
 }
 
 func sendMailTemplate(c context.Context, params *mailSendParams) error {
+	body := new(bytes.Buffer)
+	if err := mailTemplates.ExecuteTemplate(body, params.templateName, params.templateArg); err != nil {
+		return fmt.Errorf("failed to execute %v template: %w", params.templateName, err)
+	}
+	return sendMailBody(c, params, body.String())
+}
+
+func sendMailBody(c context.Context, params *mailSendParams, body string) error {
 	cfg := params.cfg
 	to := email.MergeEmailLists([]string{cfg.Email}, params.cc)
 	if cfg.MailMaintainers {
@@ -435,24 +417,8 @@ func sendMailTemplate(c context.Context, params *mailSendParams) error {
 	if err != nil {
 		return err
 	}
-	body := new(bytes.Buffer)
-	if err := mailTemplates.ExecuteTemplate(body, params.templateName, params.templateArg); err != nil {
-		return fmt.Errorf("failed to execute %v template: %w", params.templateName, err)
-	}
 	log.Infof(c, "sending email %q to %q", params.title, to)
-	return sendMailText(c, params.cfg, params.title, from, to, params.replyTo, body.String())
-}
-func generateEmailBugTitle(rep *dashapi.BugReport, emailConfig *EmailConfig) string {
-	title := ""
-	for i := len(rep.Subsystems) - 1; i >= 0; i-- {
-		question := ""
-		if rep.Subsystems[i].SetBy == "" {
-			// Include the question mark for automatically created tags.
-			question = "?"
-		}
-		title = fmt.Sprintf("[%s%s] %s", rep.Subsystems[i].Name, question, title)
-	}
-	return title + rep.Title
+	return sendMailText(c, params.cfg, params.title, from, to, params.replyTo, body)
 }
 
 // handleIncomingMail is the entry point for incoming emails.
@@ -466,14 +432,6 @@ func handleIncomingMail(w http.ResponseWriter, r *http.Request) {
 		log.Errorf(c, "invalid email handler URL: %s", url)
 		return
 	}
-	source := dashapi.NoDiscussion
-	for _, item := range getConfig(c).DiscussionEmails {
-		if item.ReceiveAddress != myEmail {
-			continue
-		}
-		source = item.Source
-		break
-	}
 	msg, err := email.Parse(r.Body, ownEmails(c), ownMailingLists(c), []string{
 		appURL(c),
 	})
@@ -484,21 +442,51 @@ func handleIncomingMail(w http.ResponseWriter, r *http.Request) {
 		log.Warningf(c, "failed to parse email: %v", err)
 		return
 	}
+	if err := handleEmail(c, msg, myEmail); err != nil {
+		log.Errorf(c, "email processing failed: %s", err)
+	}
+}
+
+// apiIncomingEmail handles emails forwarded by external mail receivers.
+func apiIncomingEmail(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.IncomingEmail)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Author == "" {
+		return nil, fmt.Errorf("%w: the email has no author", ErrClientBadRequest)
+	}
+	msg := email.FromIncoming(req)
+	msg.OwnEmail = stringInList(ownEmails(c), msg.Author)
+	if msg.OwnEmail {
+		// The receiver may not know our addresses, commands in our own emails must not be executed.
+		msg.Commands = nil
+		msg.Patch = ""
+	}
+	return nil, handleEmail(c, msg, req.Receiver)
+}
+
+// handleEmail processes an email received at our address myEmail.
+func handleEmail(c context.Context, msg *email.Email, myEmail string) error {
+	source := dashapi.NoDiscussion
+	for _, item := range getConfig(c).DiscussionEmails {
+		if item.ReceiveAddress != myEmail {
+			continue
+		}
+		source = item.Source
+		break
+	}
 	log.Infof(c, "received email at %q, source %q", myEmail, source)
 	if source == dashapi.NoDiscussion {
 		if stop, err := emergentlyStopped(c); err != nil || stop {
 			log.Errorf(c, "abort email processing due to emergency stop (stop %v, err %v)",
 				stop, err)
-			return
+			return nil
 		}
-		err = processIncomingEmail(c, msg)
-	} else {
-		// Discussions are safe to handle even during an emergency stop.
-		err = processDiscussionEmail(c, msg, source)
-	}
-	if err != nil {
-		log.Errorf(c, "email processing failed: %s", err)
+		return processIncomingEmail(c, msg)
 	}
+	// Discussions are safe to handle even during an emergency stop.
+	return processDiscussionEmail(c, msg, source)
 }
 
 // nolint: gocyclo
@@ -510,7 +498,8 @@ func processIncomingEmail(c context.Context, msg *email.Email) error {
 	}
 	log.Infof(c, "received email: subject %q, author %q, cc %q, msg %q, bug %v, %d cmds, link %q, list %q",
 		msg.Subject, msg.Author, msg.Cc, msg.MessageID, msg.BugIDs, len(msg.Commands), msg.Link, msg.MailingList)
-	excludeSampleCommands(msg)
+	// Sometimes it happens that somebody sends us our own text back, ignore it.
+	msg.Commands = mailer.ExcludeSampleCommands(msg.Commands)
 	bugInfo, bugListInfo, emailConfig := identifyEmail(c, msg)
 	if bugInfo == nil && bugListInfo == nil {
 		return nil // error was already logged
@@ -571,30 +560,6 @@ func processIncomingEmail(c context.Context, msg *email.Email) error {
 	return nil
 }
 
-func excludeSampleCommands(msg *email.Email) {
-	// Sometimes it happens that somebody sends us our own text back, ignore it.
-	var newCommands []*email.SingleCommand
-	for _, cmd := range msg.Commands {
-		ok := true
-		switch cmd.Command {
-		case email.CmdFix:
-			ok = cmd.Args != "exact-commit-title"
-		case email.CmdTest:
-			ok = cmd.Args != "git://repo/address.git branch-or-commit-hash"
-		case email.CmdSet:
-			ok = cmd.Args != "subsystems: new-subsystem"
-		case email.CmdUnset:
-			ok = cmd.Args != "some-label"
-		case email.CmdDup:
-			ok = cmd.Args != "exact-subject-of-another-report"
-		}
-		if ok {
-			newCommands = append(newCommands, cmd)
-		}
-	}
-	msg.Commands = newCommands
-}
-
 func groupEmailReplies(replies []string) string {
 	// If there's just one reply, return it.
 	if len(replies) == 1 {
@@ -618,7 +583,7 @@ func handleBugCommand(c context.Context, bugInfo *bugInfoResult, msg *email.Emai
 	command *email.SingleCommand) string {
 	status := dashapi.BugStatusUpdate
 	if command != nil {
-		status = emailCmdToStatus[command.Command]
+		status, _ = mailer.CommandStatus(command.Command)
 	}
 	cmd := &dashapi.BugUpdate{
 		Status: status,
@@ -704,16 +669,6 @@ func processDiscussionEmail(c context.Context, msg *email.Email, source dashapi.
 	return nil
 }
 
-var emailCmdToStatus = map[email.Command]dashapi.BugStatus{
-	email.CmdUpstream: dashapi.BugStatusUpstream,
-	email.CmdInvalid:  dashapi.BugStatusInvalid,
-	email.CmdUnDup:    dashapi.BugStatusOpen,
-	email.CmdFix:      dashapi.BugStatusOpen,
-	email.CmdUnFix:    dashapi.BugStatusUpdate,
-	email.CmdDup:      dashapi.BugStatusDup,
-	email.CmdUnCC:     dashapi.BugStatusUnCC,
-}
-
 func handleTestCommand(c context.Context, info *bugInfoResult,
 	msg *email.Email, command *email.SingleCommand) string {
 	args := strings.Fields(command.Args)
@@ -1182,26 +1137,19 @@ func matchBugFromList(c context.Context, sender, subject string) (*bugInfoResult
 }
 
 type subjectTitleParser struct {
-	pattern *regexp.Regexp
+	titles *mailer.TitleParser
 }
 
 func makeSubjectTitleParser(c context.Context) *subjectTitleParser {
-	stripPrefixes := []string{`R[eE]:`}
+	var prefixes []string
 	for _, ns := range getConfig(c).Namespaces {
 		for _, rep := range ns.Reporting {
-			emailConfig, ok := rep.Config.(*EmailConfig)
-			if !ok {
-				continue
-			}
-			if ok && emailConfig.SubjectPrefix != "" {
-				stripPrefixes = append(stripPrefixes,
-					regexp.QuoteMeta(emailConfig.SubjectPrefix))
+			if emailConfig, ok := rep.Config.(*EmailConfig); ok {
+				prefixes = append(prefixes, emailConfig.SubjectPrefix)
 			}
 		}
 	}
-	rePrefixes := `^(?:(?:` + strings.Join(stripPrefixes, "|") + `)\s*)*`
-	pattern := regexp.MustCompile(rePrefixes + `(?:\[[^\]]+\]\s*)*\s*(.*)$`)
-	return &subjectTitleParser{pattern}
+	return &subjectTitleParser{mailer.NewTitleParser(prefixes...)}
 }
 
 func (p *subjectTitleParser) parseTitle(subject string) (string, int64, error) {
@@ -1213,12 +1161,7 @@ func (p *subjectTitleParser) parseTitle(subject string) (string, int64, error) {
 }
 
 func (p *subjectTitleParser) parseFullTitle(subject string) (string, error) {
-	subject = strings.TrimSpace(subject)
-	parts := p.pattern.FindStringSubmatch(subject)
-	if parts == nil || parts[len(parts)-1] == "" {
-		return "", fmt.Errorf("failed to extract the title")
-	}
-	return parts[len(parts)-1], nil
+	return p.titles.Parse(subject)
 }
 
 func missingMailingLists(c context.Context, msg *email.Email, emailConfig *EmailConfig) []string {
@@ -1272,15 +1215,11 @@ func sendMailText(c context.Context, cfg *EmailConfig, subject, from string, to
 	msg := &aemail.Message{
 		Sender:  from,
 		To:      to,
-		Subject: subject,
+		Subject: mailer.Subject(subject, cfg.SubjectPrefix, replyTo != ""),
 		Body:    body,
 	}
-	if cfg.SubjectPrefix != "" {
-		msg.Subject = cfg.SubjectPrefix + " " + msg.Subject
-	}
 	if replyTo != "" {
 		msg.Headers = mail.Header{"In-Reply-To": []string{replyTo}}
-		msg.Subject = replySubject(msg.Subject)
 	}
 	return sendEmail(c, msg)
 }
@@ -1297,7 +1236,7 @@ func replyTo(c context.Context, msg *email.Email, bugID, reply string) error {
 		Sender:  from,
 		To:      []string{msg.Author},
 		Cc:      msg.Cc,
-		Subject: replySubject(msg.Subject),
+		Subject: mailer.Subject(msg.Subject, "", true),
 		Body:    email.FormReply(msg, reply),
 		Headers: mail.Header{"In-Reply-To": []string{msg.MessageID}},
 	}
@@ -1312,13 +1251,6 @@ var sendEmail = func(c context.Context, msg *aemail.Message) error {
 	return nil
 }
 
-func replySubject(subject string) string {
-	if !strings.HasPrefix(subject, replySubjectPrefix) {
-		return replySubjectPrefix + subject
-	}
-	return subject
-}
-
 func ownEmail(c context.Context) string {
 	if getConfig(c).OwnEmailAddress != "" {
 		return getConfig(c).OwnEmailAddress
@@ -1364,6 +1296,11 @@ func externalLink(c context.Context, tag string, id int64) string {
 	return fmt.Sprintf("%v/x/%v?x=%v", appURL(c), textFilename(tag), strconv.FormatUint(uint64(id), 16))
 }
 
+// externalBugLink is the canonical dashboard link for the bug as it's known in the reporting.
+func externalBugLink(c context.Context, bugReporting *BugReporting) string {
+	return fmt.Sprintf("%v/bug?extid=%v", appURL(c), bugReporting.ID)
+}
+
 func appURL(c context.Context) string {
 	appURL := getConfig(c).AppURL
 	if appURL != "" {
@@ -5,13 +5,16 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
+	db "google.golang.org/appengine/v2/datastore"
 	"google.golang.org/appengine/v2/log"
+	"google.golang.org/appengine/v2/memcache"
 )
 
 // Interface with external reporting systems.
@@ -19,22 +22,80 @@ import (
 // and report back bug status updates with apiReportingUpdate.
 
 func apiReportingPollBugs(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.PollBugsRequest)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	// AppEngine does not let us wait for new bugs, so long polling waits for reportingGeneration
+	// to change and only then polls again. Bugs that become reportable with time (e.g. once
+	// the moderation delay passes) don't bump it, they are reported by the next poll.
+	// The deadline uses the real time, since the waiting is real.
+	deadline := time.Now().Add(min(req.Wait, dashapi.MaxPollWait))
+	for {
+		gen := reportingGeneration(c)
+		resp, err := reportingPollBugsOnce(c, req)
+		if err != nil || len(resp.Reports) != 0 {
+			return resp, err
+		}
+		for changed := false; !changed; changed = reportingGeneration(c) != gen {
+			if time.Now().Add(longPollInterval).After(deadline) {
+				return resp, nil
+			}
+			select {
+			case <-c.Done():
+				return resp, nil
+			case <-time.After(longPollInterval):
+			}
+		}
+	}
+}
+
+const longPollInterval = time.Second
+
+const reportingGenerationKey = "reporting-generation"
+
+// reportingGeneration returns the counter that is bumped by bumpReportingGeneration,
+// it's 0 if the counter was evicted, which also counts as a change.
+func reportingGeneration(c context.Context) uint64 {
+	item, err := memcache.Get(c, reportingGenerationKey)
+	if err != nil {
+		if err != memcache.ErrCacheMiss {
+			log.Errorf(c, "failed to get reporting generation: %v", err)
+		}
+		return 0
+	}
+	gen, _ := strconv.ParseUint(string(item.Value), 10, 64)
+	return gen
+}
+
+// bumpReportingGeneration wakes up long polls of the bugs (see apiReportingPollBugs).
+// It's called on changes that may produce new reports: new bugs and reproducers,
+// bug status updates and finished jobs.
+func bumpReportingGeneration(c context.Context) {
+	if _, err := memcache.Increment(c, reportingGenerationKey, 1, 0); err != nil {
+		log.Errorf(c, "failed to bump reporting generation: %v", err)
+	}
+}
+
+func reportingPollBugsOnce(c context.Context, req *dashapi.PollBugsRequest) (*dashapi.PollBugsResponse, error) {
 	if stop, err := emergentlyStopped(c); err != nil || stop {
 		return &dashapi.PollBugsResponse{}, err
 	}
-	req := new(dashapi.PollBugsRequest)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
-	}
-	reports := reportingPollBugs(c, req.Type)
+	reports, cursor := reportingPollBugs(c, req)
 	resp := &dashapi.PollBugsResponse{
 		Reports: reports,
+		Cursor:  cursor,
 	}
 	jobs, err := pollCompletedJobs(c, req.Type)
 	if err != nil {
 		log.Errorf(c, "failed to poll jobs(bugs): %v", err)
 	}
-	resp.Reports = append(resp.Reports, jobs...)
+	for _, job := range jobs {
+		if (req.Namespace == "" || job.Namespace == req.Namespace) &&
+			(req.Manager == "" || job.Manager == req.Manager) {
+			resp.Reports = append(resp.Reports, job)
+		}
+	}
 	return resp, nil
 }
 
@@ -43,8 +104,8 @@ func apiReportingPollNotifications(c context.Context, r *http.Request, payload [
 		return &dashapi.PollNotificationsResponse{}, err
 	}
 	req := new(dashapi.PollNotificationsRequest)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	notifs := reportingPollNotifications(c, req.Type)
 	resp := &dashapi.PollNotificationsResponse{
@@ -58,8 +119,8 @@ func apiReportingPollClosed(c context.Context, r *http.Request, payload []byte)
 		return &dashapi.PollClosedResponse{}, err
 	}
 	req := new(dashapi.PollClosedRequest)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	ids, err := reportingPollClosed(c, req.IDs)
 	if err != nil {
@@ -73,9 +134,29 @@ func apiReportingPollClosed(c context.Context, r *http.Request, payload []byte)
 
 func apiReportingUpdate(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.BugUpdate)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	return reportingUpdate(c, req), nil
+}
+
+func apiUpdateBugs(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UpdateBugsReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if len(req.Updates) > dashapi.MaxBugUpdateBatch {
+		return nil, fmt.Errorf("%w: too many updates: %v, max %v",
+			ErrClientBadRequest, len(req.Updates), dashapi.MaxBugUpdateBatch)
 	}
+	resp := new(dashapi.UpdateBugsResp)
+	for _, upd := range req.Updates {
+		resp.Replies = append(resp.Replies, reportingUpdate(c, upd))
+	}
+	return resp, nil
+}
+
+func reportingUpdate(c context.Context, req *dashapi.BugUpdate) *dashapi.BugUpdateReply {
 	if req.JobID != "" {
 		resp := &dashapi.BugUpdateReply{
 			OK:    true,
@@ -86,20 +167,20 @@ func apiReportingUpdate(c context.Context, r *http.Request, payload []byte) (int
 			resp.Text = err.Error()
 			resp.Error = true
 		}
-		return resp, nil
+		return resp
 	}
 	ok, reason, err := incomingCommand(c, req)
 	return &dashapi.BugUpdateReply{
 		OK:    ok,
 		Error: err != nil,
 		Text:  reason,
-	}, nil
+	}
 }
 
 func apiNewTestJob(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
 	req := new(dashapi.TestPatchRequest)
-	if err := json.Unmarshal(payload, req); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
 	}
 	resp := &dashapi.TestPatchReply{}
 	err := handleExternalTestRequest(c, req)
@@ -113,3 +194,85 @@ func apiNewTestJob(c context.Context, r *http.Request, payload []byte) (interfac
 	}
 	return resp, nil
 }
+
+func apiSetExtID(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.SetExtIDReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.ExtID == "" || len(req.ExtID) > MaxStringLen {
+		return nil, fmt.Errorf("%w: bad ext id %q", ErrClientBadRequest, req.ExtID)
+	}
+	_, bugKey, err := findBugByReportingID(c, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the bug: %w", err)
+	}
+	tx := func(c context.Context) error {
+		bug := new(Bug)
+		if err := db.Get(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to get bug: %w", err)
+		}
+		bugReporting, _ := bugReportingByID(bug, req.ID)
+		if bugReporting == nil {
+			return fmt.Errorf("%w: no bug reporting %q", ErrClientNotFound, req.ID)
+		}
+		bugReporting.ExtID = req.ExtID
+		if _, err := db.Put(c, bugKey, bug); err != nil {
+			return fmt.Errorf("failed to put bug: %w", err)
+		}
+		return nil
+	}
+	return nil, db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 5})
+}
+
+// The same identifier is not expected to be used by many bugs, e.g. it may be a Bugzilla ID
+// that is unique only within one Bugzilla instance.
+const maxExtIDBugs = 10
+
+func apiLookupExtID(c context.Context, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.LookupExtIDReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.ExtID == "" {
+		return nil, fmt.Errorf("%w: empty ext id", ErrClientBadRequest)
+	}
+	var bugs []*Bug
+	_, err := db.NewQuery("Bug").
+		Filter("Reporting.ExtID=", req.ExtID).
+		Limit(maxExtIDBugs).
+		GetAll(c, &bugs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bugs: %w", err)
+	}
+	resp := new(dashapi.LookupExtIDResp)
+	for _, bug := range bugs {
+		for i := range bug.Reporting {
+			bugReporting := &bug.Reporting[i]
+			if bugReporting.ExtID != req.ExtID {
+				continue
+			}
+			extBug := &dashapi.ExtIDBug{
+				ID:        bugReporting.ID,
+				ExtID:     bugReporting.ExtID,
+				Namespace: bug.Namespace,
+				Title:     bug.displayTitle(),
+				Link:      externalBugLink(c, bugReporting),
+			}
+			if extBug.Status, err = bug.dashapiStatus(); err != nil {
+				return nil, err
+			}
+			if bug.Status == BugStatusDup {
+				canon, err := canonicalBug(c, bug)
+				if err != nil {
+					return nil, err
+				}
+				if canonReporting := bugReportingByName(canon, bugReporting.Name); canonReporting != nil {
+					extBug.DupOf = canonReporting.ExtID
+				}
+			}
+			resp.Bugs = append(resp.Bugs, extBug)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const githubType = "github"
+
+// GitHubConfig is the reporting config for issues filed by tools/syz-github.
+// The tool receives it in dashapi.BugReport.Config, so the fields are shared with the tool.
+type GitHubConfig struct {
+	Owner  string   // the owner of the repository (a user or an organization)
+	Repo   string   // the repository the issues are filed in
+	Labels []string // labels set on every filed issue
+	// If set, reproducers are uploaded as secret gists, otherwise they are only linked.
+	UploadGists bool
+}
+
+var githubNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func (cfg *GitHubConfig) Type() string {
+	return githubType
+}
+
+func (cfg *GitHubConfig) Validate() error {
+	if !githubNameRe.MatchString(cfg.Owner) || !githubNameRe.MatchString(cfg.Repo) {
+		return fmt.Errorf("github config: bad repository %q/%q", cfg.Owner, cfg.Repo)
+	}
+	for _, label := range cfg.Labels {
+		if label == "" {
+			return fmt.Errorf("github config: empty label")
+		}
+	}
+	return nil
+}
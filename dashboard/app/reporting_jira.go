@@ -0,0 +1,43 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const jiraType = "jira"
+
+// JiraConfig is the reporting config for issues filed by tools/syz-jira.
+// The tool receives it in dashapi.BugReport.Config, so the fields are shared with the tool.
+type JiraConfig struct {
+	JiraProject
+	// Bugs in the subsystems are filed in the specified projects instead (the first subsystem
+	// of the bug that has an entry wins), empty IssueType means the IssueType above.
+	Subsystems map[string]JiraProject
+}
+
+type JiraProject struct {
+	Project   string // the project key, e.g. "KERN"
+	IssueType string // "Bug" if empty
+}
+
+var jiraProjectRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+func (cfg *JiraConfig) Type() string {
+	return jiraType
+}
+
+func (cfg *JiraConfig) Validate() error {
+	if !jiraProjectRe.MatchString(cfg.Project) {
+		return fmt.Errorf("jira config: bad project key %q", cfg.Project)
+	}
+	for subsystem, project := range cfg.Subsystems {
+		if !jiraProjectRe.MatchString(project.Project) {
+			return fmt.Errorf("jira config: bad project key %q for subsystem %v", project.Project, subsystem)
+		}
+	}
+	return nil
+}
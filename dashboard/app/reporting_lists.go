@@ -466,7 +466,7 @@ func reportingBugListReport(c context.Context, subsystemReport *SubsystemReport,
 				getNsConfig(c, ns).Subsystems.Reminder.SourceReporting)
 			ret.Bugs = append(ret.Bugs, dashapi.BugListItem{
 				Title:      bug.displayTitle(),
-				Link:       fmt.Sprintf("%v/bug?extid=%v", appURL(c), bugReporting.ID),
+				Link:       externalBugLink(c, bugReporting),
 				ReproLevel: bug.ReproLevel,
 				Hits:       bug.NumCrashes,
 			})
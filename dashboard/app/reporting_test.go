@@ -4,6 +4,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"html"
@@ -24,7 +25,7 @@ func TestReportBug(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := &dashapi.Crash{
 		BuildID:     "build1",
@@ -36,14 +37,14 @@ func TestReportBug(t *testing.T) {
 		MachineInfo: []byte("machine info 1"),
 		GuiltyFiles: []string{"a.c"},
 	}
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// Must get no reports for "unknown" type.
-	resp, _ := c.client.ReportingPollBugs("unknown")
+	resp, _ := c.client.ReportingPollBugs(context.Background(), "unknown")
 	c.expectEQ(len(resp.Reports), 0)
 
 	// Must get a proper report for "test" type.
-	resp, _ = c.client.ReportingPollBugs("test")
+	resp, _ = c.client.ReportingPollBugs(context.Background(), "test")
 	c.expectEQ(len(resp.Reports), 1)
 	rep := resp.Reports[0]
 	c.expectNE(rep.ID, "")
@@ -110,7 +111,7 @@ func TestReportBug(t *testing.T) {
 	want.First = false
 	want.ReproSyz = []byte(syzReproPrefix + "#some opts\ngetpid()")
 	want.ReproOpts = []byte("some opts")
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep1 := c.client.pollBug()
 	c.expectNE(want.CrashID, rep1.CrashID)
 	_, dbCrash, _ = c.loadBug(rep.ID)
@@ -121,7 +122,7 @@ func TestReportBug(t *testing.T) {
 	want.ReportLink = externalLink(c.ctx, textCrashReport, dbCrash.Report)
 	c.expectEQ(want, rep1)
 
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		ReproLevel: dashapi.ReproLevelSyz,
@@ -135,13 +136,16 @@ func TestReportBug(t *testing.T) {
 	c.client.updateBug(rep.ID, dashapi.BugStatusUpstream, "")
 
 	// Check that bug updates for the first reporting fail now.
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{ID: rep.ID, Status: dashapi.BugStatusOpen})
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:     rep.ID,
+		Status: dashapi.BugStatusOpen,
+	})
 	c.expectEQ(reply.OK, false)
 
 	// Report another crash with syz repro for this bug,
 	// ensure that we report the new crash in the next reporting.
 	crash1.Report = []byte("report2")
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// Check that we get the report in the second reporting.
 	rep2 := c.client.pollBug()
@@ -164,7 +168,7 @@ func TestReportBug(t *testing.T) {
 	c.expectEQ(want, rep2)
 
 	// Check that that we can't upstream the bug in the final reporting.
-	reply, _ = c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep2.ID,
 		Status: dashapi.BugStatusUpstream,
 	})
@@ -176,15 +180,15 @@ func TestInvalidBug(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrashWithRepro(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	rep := c.client.pollBug()
 	c.expectEQ(rep.Title, "title1")
 
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		ReproLevel: dashapi.ReproLevelC,
@@ -192,7 +196,7 @@ func TestInvalidBug(t *testing.T) {
 	c.expectEQ(reply.OK, true)
 
 	{
-		closed, _ := c.client.ReportingPollClosed([]string{rep.ID, "foobar"})
+		closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep.ID, "foobar"})
 		c.expectEQ(len(closed), 0)
 	}
 
@@ -200,7 +204,7 @@ func TestInvalidBug(t *testing.T) {
 	c.client.updateBug(rep.ID, dashapi.BugStatusInvalid, "")
 
 	{
-		closed, _ := c.client.ReportingPollClosed([]string{rep.ID, "foobar"})
+		closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep.ID, "foobar"})
 		c.expectEQ(len(closed), 1)
 		c.expectEQ(closed[0], rep.ID)
 	}
@@ -216,7 +220,7 @@ func TestInvalidBug(t *testing.T) {
 		Report:  []byte("report2"),
 		ReproC:  []byte("int main() { return 1; }"),
 	}
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 
 	// Now it should be reported again.
 	rep = c.client.pollBug()
@@ -265,7 +269,7 @@ func TestInvalidBug(t *testing.T) {
 		ReportElements:    &dashapi.ReportElements{},
 	}
 	c.expectEQ(want, rep)
-	c.client.ReportFailedRepro(testCrashID(crash1))
+	c.client.ReportFailedRepro(context.Background(), testCrashID(crash1))
 }
 
 func TestReportingQuota(t *testing.T) {
@@ -280,11 +284,11 @@ func TestReportingQuota(t *testing.T) {
 		})
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	const numReports = 5
 	for i := 0; i < numReports; i++ {
-		c.client.ReportCrash(testCrash(build, i))
+		c.client.ReportCrash(context.Background(), testCrash(build, i))
 	}
 
 	for _, reports := range []int{2, 2, 1, 0, 0} {
@@ -309,22 +313,22 @@ func TestReproReportingQuota(t *testing.T) {
 		})
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// First report of two.
 	c.advanceTime(time.Minute)
-	client.ReportCrash(testCrash(build, 1))
+	client.ReportCrash(context.Background(), testCrash(build, 1))
 	client.pollBug()
 
 	// Second report of two.
 	c.advanceTime(time.Minute)
 	crash := testCrash(build, 2)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollBug()
 
 	// Now we "find" a reproducer.
 	c.advanceTime(time.Minute)
-	client.ReportCrash(testCrashWithRepro(build, 1))
+	client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
 
 	// But there's no quota for it.
 	client.pollBugs(0)
@@ -340,13 +344,13 @@ func TestReportingDup(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 
 	reports := c.client.pollBugs(2)
 	rep1 := reports[0]
@@ -356,7 +360,7 @@ func TestReportingDup(t *testing.T) {
 	c.client.updateBug(rep2.ID, dashapi.BugStatusDup, rep1.ID)
 	{
 		// Both must be reported as open.
-		closed, _ := c.client.ReportingPollClosed([]string{rep1.ID, rep2.ID})
+		closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep1.ID, rep2.ID})
 		c.expectEQ(len(closed), 0)
 	}
 
@@ -367,26 +371,26 @@ func TestReportingDup(t *testing.T) {
 	c.client.updateBug(rep2.ID, dashapi.BugStatusDup, rep1.ID)
 
 	// Dup crash happens again, new bug must not be created.
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBugs(0)
 
 	// Now close the original bug, and check that new bugs for dup are now created.
 	c.client.updateBug(rep1.ID, dashapi.BugStatusInvalid, "")
 	{
 		// Now both must be reported as closed.
-		closed, _ := c.client.ReportingPollClosed([]string{rep1.ID, rep2.ID})
+		closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep1.ID, rep2.ID})
 		c.expectEQ(len(closed), 2)
 		c.expectEQ(closed[0], rep1.ID)
 		c.expectEQ(closed[1], rep2.ID)
 	}
 
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep3 := c.client.pollBug()
 	c.expectEQ(rep3.Title, crash2.Title+" (2)")
 
 	// Unduping after the canonical bugs was closed must not work
 	// (we already created new bug for this report).
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep2.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -400,19 +404,19 @@ func TestReportingDupToClosed(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 
 	reports := c.client.pollBugs(2)
 	c.client.updateBug(reports[0].ID, dashapi.BugStatusInvalid, "")
 	c.client.updateBug(reports[1].ID, dashapi.BugStatusDup, reports[0].ID)
 
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep2 := c.client.pollBug()
 	c.expectEQ(rep2.Title, crash2.Title+" (2)")
 }
@@ -423,13 +427,13 @@ func TestReportingDupCrossReporting(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	crash2 := testCrash(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 
 	reports := c.client.pollBugs(2)
 	rep1 := reports[0]
@@ -440,7 +444,7 @@ func TestReportingDupCrossReporting(t *testing.T) {
 	rep3 := c.client.pollBug()
 
 	{
-		closed, _ := c.client.ReportingPollClosed([]string{rep1.ID, rep2.ID, rep3.ID})
+		closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep1.ID, rep2.ID, rep3.ID})
 		c.expectEQ(len(closed), 1)
 		c.expectEQ(closed[0], rep2.ID)
 	}
@@ -459,7 +463,7 @@ func TestReportingDupCrossReporting(t *testing.T) {
 	for _, cmd := range cmds {
 		t.Logf("duping %v -> %v", cmd.ID, cmd.DupOf)
 		cmd.Status = dashapi.BugStatusDup
-		reply, _ := c.client.ReportingUpdate(cmd)
+		reply, _ := c.client.ReportingUpdate(context.Background(), cmd)
 		c.expectEQ(reply.OK, false)
 	}
 	// Special case of cross-reporting duping:
@@ -469,7 +473,7 @@ func TestReportingDupCrossReporting(t *testing.T) {
 		DupOf:  rep3.ID,
 	}
 	t.Logf("duping %v -> %v", cmd.ID, cmd.DupOf)
-	reply, _ := c.client.ReportingUpdate(cmd)
+	reply, _ := c.client.ReportingUpdate(context.Background(), cmd)
 	c.expectTrue(reply.OK)
 }
 
@@ -480,25 +484,25 @@ func TestReportingDupCycle(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	const N = 4
 	reps := make([]*dashapi.BugReport, N)
 	for i := 0; i < N; i++ {
 		t.Logf("*************** %v ***************", i)
-		c.client.ReportCrash(testCrash(build, i))
+		c.client.ReportCrash(context.Background(), testCrash(build, i))
 		reps[i] = c.client.pollBug()
 		replyError := "Can't dup bug to itself."
 		if i != 0 {
 			replyError = "Setting this dup would lead to a bug cycle, cycles are not allowed."
-			reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+			reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 				Status: dashapi.BugStatusDup,
 				ID:     reps[i-1].ID,
 				DupOf:  reps[i].ID,
 			})
 			c.expectEQ(reply.OK, true)
 		}
-		reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+		reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 			Status: dashapi.BugStatusDup,
 			ID:     reps[i].ID,
 			DupOf:  reps[0].ID,
@@ -515,18 +519,18 @@ func TestReportingFilter(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
 	crash1.Title = "skip with repro 1"
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// This does not skip first reporting, because it does not have repro.
 	rep1 := c.client.pollBug()
 	c.expectEQ(string(rep1.Config), `{"Index":1}`)
 
 	crash1.ReproSyz = []byte("getpid()")
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// This has repro but was already reported to first reporting,
 	// so repro must go to the first reporting as well.
@@ -543,7 +547,7 @@ func TestReportingFilter(t *testing.T) {
 	crash2 := testCrash(build, 2)
 	crash2.Title = "skip with repro 2"
 	crash2.ReproSyz = []byte("getpid()")
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 
 	rep4 := c.client.pollBug()
 	c.expectEQ(string(rep4.Config), `{"Index":2}`)
@@ -554,7 +558,7 @@ func TestMachineInfo(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	machineInfo := []byte("info1")
 
@@ -568,7 +572,7 @@ func TestMachineInfo(t *testing.T) {
 		Report:      []byte("report1"),
 		MachineInfo: machineInfo,
 	}
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep := c.client.pollBug()
 	c.expectEQ(machineInfo, rep.MachineInfo)
 
@@ -598,19 +602,19 @@ func TestAltTitles1(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// crash2.AltTitles matches crash1.Title.
 	crash1 := testCrash(build, 1)
 	crash2 := testCrashWithRepro(build, 2)
 	crash2.AltTitles = []string{crash1.Title}
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep := c.client.pollBug()
 	c.expectEQ(rep.Title, crash1.Title)
 	c.expectEQ(rep.Log, crash1.Log)
 
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep = c.client.pollBug()
 	c.expectEQ(rep.Title, crash1.Title)
 	c.expectEQ(rep.Log, crash2.Log)
@@ -621,19 +625,19 @@ func TestAltTitles2(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// crash2.Title matches crash1.AltTitles, but reported in opposite order.
 	crash1 := testCrash(build, 1)
 	crash2 := testCrash(build, 2)
 	crash2.AltTitles = []string{crash1.Title}
 
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep := c.client.pollBug()
 	c.expectEQ(rep.Title, crash2.Title)
 	c.expectEQ(rep.Log, crash2.Log)
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(0)
 }
 
@@ -642,7 +646,7 @@ func TestAltTitles3(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// crash2.AltTitles matches crash1.AltTitles.
 	crash1 := testCrash(build, 1)
@@ -650,9 +654,9 @@ func TestAltTitles3(t *testing.T) {
 	crash2 := testCrash(build, 2)
 	crash2.AltTitles = crash1.AltTitles
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(1)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBugs(0)
 }
 
@@ -661,7 +665,7 @@ func TestAltTitles4(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// crash1.AltTitles matches crash2.AltTitles which matches crash3.AltTitles.
 	crash1 := testCrash(build, 1)
@@ -671,11 +675,11 @@ func TestAltTitles4(t *testing.T) {
 	crash3 := testCrash(build, 3)
 	crash3.AltTitles = []string{"foobar2"}
 
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(1)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBugs(0)
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(0)
 }
 
@@ -684,30 +688,30 @@ func TestAltTitles5(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Test which of the possible existing bugs we choose for merging.
 	crash1 := testCrash(build, 1)
 	crash1.AltTitles = []string{"foo"}
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBugs(1)
 
 	crash2 := testCrash(build, 2)
 	crash2.Title = "bar"
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBugs(1)
 
 	crash3 := testCrash(build, 3)
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(1)
 	crash3.AltTitles = []string{"bar"}
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(0)
 
 	crash := testCrashWithRepro(build, 10)
 	crash.Title = "foo"
 	crash.AltTitles = []string{"bar"}
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep := c.client.pollBug()
 	c.expectEQ(rep.Title, crash2.Title)
 	c.expectEQ(rep.Log, crash.Log)
@@ -718,35 +722,35 @@ func TestAltTitles6(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Test which of the possible existing bugs we choose for merging in presence of closed bugs.
 	crash1 := testCrash(build, 1)
 	crash1.AltTitles = []string{"foo"}
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	rep := c.client.pollBug()
 	c.client.updateBug(rep.ID, dashapi.BugStatusInvalid, "")
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBug()
 
 	crash2 := testCrash(build, 2)
 	crash2.Title = "bar"
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep = c.client.pollBug()
 	c.client.updateBug(rep.ID, dashapi.BugStatusInvalid, "")
 
 	c.advanceTime(24 * time.Hour)
 	crash3 := testCrash(build, 3)
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(1)
 	crash3.AltTitles = []string{"foo"}
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(0)
 
 	crash := testCrashWithRepro(build, 10)
 	crash.Title = "foo"
 	crash.AltTitles = []string{"bar"}
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	rep = c.client.pollBug()
 	c.expectEQ(rep.Title, crash1.Title+" (2)")
 	c.expectEQ(rep.Log, crash.Log)
@@ -757,34 +761,34 @@ func TestAltTitles7(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Test that bug merging is stable: if we started merging into a bug, we continue merging into that bug
 	// even if a better candidate appears.
 	crash1 := testCrash(build, 1)
 	crash1.AltTitles = []string{"foo"}
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 	c.client.pollBug()
 
 	// This will be merged into crash1.
 	crash2 := testCrash(build, 2)
 	crash2.AltTitles = []string{"foo"}
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	c.client.pollBugs(0)
 
 	// Now report a better candidate.
 	crash3 := testCrash(build, 3)
 	crash3.Title = "aaa"
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBug()
 	crash3.AltTitles = []string{crash2.Title}
-	c.client.ReportCrash(crash3)
+	c.client.ReportCrash(context.Background(), crash3)
 	c.client.pollBugs(0)
 
 	// Now report crash2 with a repro and ensure that it's still merged into crash1.
 	crash2.ReproOpts = []byte("some opts")
 	crash2.ReproSyz = []byte("getpid()")
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	rep := c.client.pollBug()
 	c.expectEQ(rep.Title, crash1.Title)
 	c.expectEQ(rep.Log, crash2.Log)
@@ -795,20 +799,20 @@ func TestDetachExternalTracker(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := testCrash(build, 1)
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// Get single report for "test" type.
-	resp, _ := c.client.ReportingPollBugs("test")
+	resp, _ := c.client.ReportingPollBugs(context.Background(), "test")
 	c.expectEQ(len(resp.Reports), 1)
 	rep1 := resp.Reports[0]
 	c.expectNE(rep1.ID, "")
 	c.expectEQ(string(rep1.Config), `{"Index":1}`)
 
 	// Signal detach_reporting for current bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep1.ID,
 		Status:     dashapi.BugStatusUpstream,
 		ReproLevel: dashapi.ReproLevelNone,
@@ -820,14 +824,14 @@ func TestDetachExternalTracker(t *testing.T) {
 	// Now add syz repro to check it doesn't use first reporting.
 	crash1.ReproOpts = []byte("some opts")
 	crash1.ReproSyz = []byte("getpid()")
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
 	// Fetch bug and check reporting path (Config) is different.
 	rep2 := c.client.pollBug()
 	c.expectNE(rep2.ID, "")
 	c.expectEQ(string(rep2.Config), `{"Index":2}`)
 
-	closed, _ := c.client.ReportingPollClosed([]string{rep1.ID, rep2.ID})
+	closed, _ := c.client.ReportingPollClosed(context.Background(), []string{rep1.ID, rep2.ID})
 	c.expectEQ(len(closed), 1)
 	c.expectEQ(closed[0], rep1.ID)
 }
@@ -981,7 +985,7 @@ func TestFullBugInfo(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	const crashTitle = "WARNING: abcd"
 
@@ -990,7 +994,7 @@ func TestFullBugInfo(t *testing.T) {
 	crashStrace.Title = crashTitle
 	crashStrace.Flags = dashapi.CrashUnderStrace
 	crashStrace.Report = []byte("with strace")
-	c.client.ReportCrash(crashStrace)
+	c.client.ReportCrash(context.Background(), crashStrace)
 	rep := c.client.pollBug()
 
 	// Newer: just with repro.
@@ -998,16 +1002,17 @@ func TestFullBugInfo(t *testing.T) {
 	crashRepro := testCrashWithRepro(build, 1)
 	crashRepro.Title = crashTitle
 	crashRepro.Report = []byte("with repro")
-	c.client.ReportCrash(crashRepro)
+	c.client.ReportCrash(context.Background(), crashRepro)
 
 	// Ensure we have some bisect jobs done.
 	pollResp := c.client.pollJobs(build.Manager)
 	c.expectNE(pollResp.ID, "")
 	jobID := pollResp.ID
 	done := &dashapi.JobDoneReq{
-		ID:    jobID,
-		Build: *testBuild(3),
-		Log:   []byte("bisect log"),
+		ID:         jobID,
+		Build:      *testBuild(3),
+		Log:        []byte("bisect log"),
+		Confidence: 0.9,
 		Commits: []dashapi.Commit{
 			{
 				Hash:   "111111111111111111111111",
@@ -1018,31 +1023,31 @@ func TestFullBugInfo(t *testing.T) {
 			},
 		},
 	}
-	c.client.expectOK(c.client.JobDone(done))
+	c.client.expectOK(c.client.JobDone(context.Background(), done))
 	c.client.pollBug()
 
 	// Yet newer: no repro.
 	c.advanceTime(24 * 7 * time.Hour)
 	crashNew := testCrash(build, 1)
 	crashNew.Title = crashTitle
-	c.client.ReportCrash(crashNew)
+	c.client.ReportCrash(context.Background(), crashNew)
 
 	// And yet newer.
 	c.advanceTime(24 * time.Hour)
 	crashNew2 := testCrash(build, 1)
 	crashNew2.Title = crashTitle
 	crashNew2.Report = []byte("newest")
-	c.client.ReportCrash(crashNew2)
+	c.client.ReportCrash(context.Background(), crashNew2)
 
 	// Also create a bug in another namespace.
 	otherBuild := testBuild(2)
-	c.client2.UploadBuild(otherBuild)
+	c.client2.UploadBuild(context.Background(), otherBuild)
 
 	otherCrash := testCrash(otherBuild, 1)
 	otherCrash.Title = crashTitle
 	otherCrash.ReproOpts = []byte("repro opts")
 	otherCrash.ReproSyz = []byte("repro syz")
-	c.client2.ReportCrash(otherCrash)
+	c.client2.ReportCrash(context.Background(), otherCrash)
 	otherPollMsg := c.client2.pollEmailBug()
 
 	_, err := c.POST("/_ah/mail/", fmt.Sprintf(`Sender: syzkaller@googlegroups.com
@@ -1064,7 +1069,7 @@ For more options, visit https://groups.google.com/d/optout.
 	_, otherExtBugID, _ := email.RemoveAddrContext(otherPollMsg.Sender)
 
 	// Query the full bug info.
-	info, err := c.client.LoadFullBug(&dashapi.LoadFullBugReq{BugID: rep.ID})
+	info, err := c.client.LoadFullBug(context.Background(), &dashapi.LoadFullBugReq{BugID: rep.ID})
 	c.expectOK(err)
 	if info.BisectCause == nil {
 		t.Fatalf("info.BisectCause is empty")
@@ -1072,6 +1077,7 @@ For more options, visit https://groups.google.com/d/optout.
 	if info.BisectCause.BisectCause == nil {
 		t.Fatalf("info.BisectCause.BisectCause is empty")
 	}
+	c.expectEQ(info.BisectCause.BisectCause.Confidence, 0.9)
 	c.expectEQ(info.SimilarBugs, []*dashapi.SimilarBugInfo{{
 		Title:      crashTitle,
 		Namespace:  "test2",
@@ -1094,24 +1100,24 @@ func TestUpdateReportApi(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	// Report a crash.
-	c.client.ReportCrash(testCrashWithRepro(build, 1))
+	c.client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
 	c.client.pollBug()
 
-	listResp, err := c.client.BugList()
+	listResp, err := c.client.BugList(context.Background())
 	c.expectOK(err)
 	c.expectEQ(len(listResp.List), 1)
 
 	// Load the bug info.
 	bugID := listResp.List[0]
-	rep, err := c.client.LoadBug(bugID)
+	rep, err := c.client.LoadBug(context.Background(), bugID)
 	c.expectOK(err)
 
 	// Now update the crash.
 	setGuiltyFiles := []string{"fs/a.c", "net/b.c"}
-	err = c.client.UpdateReport(&dashapi.UpdateReportReq{
+	err = c.client.UpdateReport(context.Background(), &dashapi.UpdateReportReq{
 		BugID:       bugID,
 		CrashID:     rep.CrashID,
 		GuiltyFiles: &setGuiltyFiles,
@@ -1119,7 +1125,7 @@ func TestUpdateReportApi(t *testing.T) {
 	c.expectOK(err)
 
 	// And make sure it's been updated.
-	ret, err := c.client.LoadBug(bugID)
+	ret, err := c.client.LoadBug(context.Background(), bugID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1137,19 +1143,19 @@ func TestReportDecommissionedBugs(t *testing.T) {
 
 	client := c.makeClient(clientTestDecomm, keyTestDecomm, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep := client.pollBug()
 
-	closed, _ := client.ReportingPollClosed([]string{rep.ID})
+	closed, _ := client.ReportingPollClosed(context.Background(), []string{rep.ID})
 	c.expectEQ(len(closed), 0)
 
 	// And now let's decommission the namespace.
 	c.decommission(rep.Namespace)
 
-	closed, _ = client.ReportingPollClosed([]string{rep.ID})
+	closed, _ = client.ReportingPollClosed(context.Background(), []string{rep.ID})
 	c.expectEQ(len(closed), 1)
 	c.expectEQ(closed[0], rep.ID)
 }
@@ -1232,12 +1238,12 @@ func TestReportRevokedRepro(t *testing.T) {
 	build := testBuild(1)
 	build.KernelRepo = "git://mygit.com/git.git"
 	build.KernelBranch = "main"
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	crash.ReproOpts = []byte("repro opts")
 	crash.ReproSyz = []byte("repro syz")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep1 := client.pollBug()
 	client.expectNE(rep1.ReproSyz, nil)
 
@@ -1245,12 +1251,12 @@ func TestReportRevokedRepro(t *testing.T) {
 	c.advanceTime(c.config().Obsoleting.ReproRetestStart + time.Hour)
 	jobResp := client.pollSpecificJobs(build.Manager, dashapi.ManagerJobs{TestPatches: true})
 	c.expectEQ(jobResp.Type, dashapi.JobTestPatch)
-	client.expectOK(client.JobDone(&dashapi.JobDoneReq{
+	client.expectOK(client.JobDone(context.Background(), &dashapi.JobDoneReq{
 		ID: jobResp.ID,
 	}))
 
 	c.advanceTime(time.Hour)
-	client.ReportCrash(testCrash(build, 1))
+	client.ReportCrash(context.Background(), testCrash(build, 1))
 
 	// Upstream the bug.
 	c.advanceTime(time.Hour)
@@ -1273,30 +1279,416 @@ func TestWaitForRepro(t *testing.T) {
 	c.setWaitForRepro("test1", time.Hour*24)
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Normal crash witout repro.
-	client.ReportCrash(testCrash(build, 1))
+	client.ReportCrash(context.Background(), testCrash(build, 1))
 	client.pollBugs(0)
 	c.advanceTime(time.Hour * 24)
 	client.pollBug()
 
 	// A crash first without repro, then with it.
-	client.ReportCrash(testCrash(build, 2))
+	client.ReportCrash(context.Background(), testCrash(build, 2))
 	c.advanceTime(time.Hour * 12)
 	client.pollBugs(0)
-	client.ReportCrash(testCrashWithRepro(build, 2))
+	client.ReportCrash(context.Background(), testCrashWithRepro(build, 2))
 	client.pollBug()
 
 	// A crash with a reproducer.
 	c.advanceTime(time.Minute)
-	client.ReportCrash(testCrashWithRepro(build, 3))
+	client.ReportCrash(context.Background(), testCrashWithRepro(build, 3))
 	client.pollBug()
 
 	// A crahs that will never have a reproducer.
 	c.advanceTime(time.Minute)
 	crash := testCrash(build, 4)
 	crash.Title = "upstream test error: abcd"
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollBug()
 }
+
+func TestUpdateBugs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	c.client.ReportCrash(context.Background(), testCrash(build, 2))
+	reps := c.client.pollBugs(2)
+
+	replies, err := c.client.UpdateBugs(context.Background(), []*dashapi.BugUpdate{
+		{ID: reps[0].ID, Status: dashapi.BugStatusInvalid},
+		{ID: reps[1].ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"a"}},
+		{ID: reps[1].ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"foo: fix the crash"}},
+	})
+	c.expectOK(err)
+	c.expectEQ(len(replies), 3)
+	c.expectTrue(replies[0].OK)
+	c.expectEQ(replies[1].OK, false)
+	c.expectEQ(replies[1].Text, `bad commit title: "a"`)
+	c.expectTrue(replies[2].OK)
+
+	bug, _, _ := c.loadBug(reps[0].ID)
+	c.expectEQ(bug.Status, BugStatusInvalid)
+	bug, _, _ = c.loadBug(reps[1].ID)
+	c.expectEQ(bug.Commits, []string{"foo: fix the crash"})
+}
+
+func TestUpdateBugFixTree(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	rep := c.client.pollBug()
+
+	const repo = "git://git.kernel.org/maintainer.git"
+	replies, err := c.client.UpdateBugs(context.Background(), []*dashapi.BugUpdate{
+		{ID: rep.ID, Status: dashapi.BugStatusOpen, FixRepo: repo, FixBranch: "for-next"},
+		{ID: rep.ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"foo: fix the crash"},
+			FixRepo: "not a repo"},
+		{ID: rep.ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"foo: fix the crash"},
+			FixRepo: repo, FixBranch: "for-next", CC: []string{"maintainer@kernel.org"}},
+		// The same commit without the tree keeps the tree.
+		{ID: rep.ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"foo: fix the crash"}},
+	})
+	c.expectOK(err)
+	c.expectEQ(len(replies), 4)
+	c.expectEQ(replies[0].Text, "fix tree without fix commits")
+	c.expectEQ(replies[1].OK, false)
+	c.expectTrue(replies[2].OK)
+	c.expectTrue(replies[3].OK)
+
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.Commits, []string{"foo: fix the crash"})
+	c.expectEQ(bug.FixRepo, repo)
+	c.expectEQ(bug.FixBranch, "for-next")
+	c.expectEQ(bug.Reporting[0].CC, "maintainer@kernel.org")
+
+	reply, err := c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(reply, []byte("(pending in "+repo+" for-next)")))
+
+	// Other fix commits go to the main tree.
+	replies, err = c.client.UpdateBugs(context.Background(), []*dashapi.BugUpdate{
+		{ID: rep.ID, Status: dashapi.BugStatusOpen, FixCommits: []string{"foo: another fix"}},
+	})
+	c.expectOK(err)
+	c.expectTrue(replies[0].OK)
+	bug, _, _ = c.loadBug(rep.ID)
+	c.expectEQ(bug.FixRepo, "")
+}
+
+func TestLeakReporting(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+
+	leak := &dashapi.Leak{Size: 64, Stack: []string{"kmalloc", "foo_alloc", "do_syscall_64"}}
+	crash1 := testCrash(build, 1)
+	crash1.Title = "memory leak in foo_alloc"
+	crash1.Kind = dashapi.CrashLeak
+	crash1.Leak = leak
+	c.client.ReportCrash(context.Background(), crash1)
+	// Leaks without a reproducer are not reported.
+	c.advanceTime(time.Hour)
+	c.client.pollBugs(0)
+
+	// A leak with another title but the same allocation stack is the same bug.
+	crash2 := testCrashWithRepro(build, 2)
+	crash2.Title = "memory leak in foo_alloc_helper"
+	crash2.Kind = dashapi.CrashLeak
+	crash2.Leak = leak
+	c.client.ReportCrash(context.Background(), crash2)
+	rep := c.client.pollBug()
+	c.expectEQ(rep.Title, crash1.Title)
+	c.expectEQ(rep.NumCrashes, int64(2))
+
+	bug, crash, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.Kind, dashapi.CrashLeak)
+	c.expectEQ(crash.Leak, *leak)
+
+	crash3 := testCrash(build, 3)
+	crash3.Kind = "unknown"
+	_, err := c.makeClient(client1, password1, false).ReportCrash(context.Background(), crash3)
+	c.expectBadReqest(err)
+}
+
+func TestExtIDMapping(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	rep := c.client.pollBug()
+
+	bugs, err := c.client.LookupExtID(context.Background(), "bugzilla-123")
+	c.expectOK(err)
+	c.expectEQ(len(bugs), 0)
+
+	c.expectOK(c.client.SetExtID(context.Background(), &dashapi.SetExtIDReq{ID: rep.ID, ExtID: "bugzilla-1"}))
+	// The identifier is replaced, e.g. if the external issue was recreated.
+	c.expectOK(c.client.SetExtID(context.Background(), &dashapi.SetExtIDReq{ID: rep.ID, ExtID: "bugzilla-123"}))
+	bugs, err = c.client.LookupExtID(context.Background(), "bugzilla-123")
+	c.expectOK(err)
+	c.expectEQ(bugs, []*dashapi.ExtIDBug{{
+		ID:        rep.ID,
+		ExtID:     "bugzilla-123",
+		Namespace: rep.Namespace,
+		Title:     rep.Title,
+		Link:      rep.Link,
+		Status:    dashapi.BugStatusOpen,
+	}})
+	bugs, err = c.client.LookupExtID(context.Background(), "bugzilla-1")
+	c.expectOK(err)
+	c.expectEQ(len(bugs), 0)
+
+	// The identifier is sent back in reports.
+	c.client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
+	rep = c.client.pollBug()
+	c.expectEQ(rep.ExtID, "bugzilla-123")
+
+	// Duplicates refer to the identifier of the canonical bug.
+	c.client.ReportCrash(context.Background(), testCrash(build, 2))
+	rep2 := c.client.pollBug()
+	c.expectOK(c.client.SetExtID(context.Background(), &dashapi.SetExtIDReq{ID: rep2.ID, ExtID: "bugzilla-124"}))
+	c.client.updateBug(rep2.ID, dashapi.BugStatusDup, rep.ID)
+	bugs, err = c.client.LookupExtID(context.Background(), "bugzilla-124")
+	c.expectOK(err)
+	c.expectEQ(len(bugs), 1)
+	c.expectEQ(bugs[0].Status, dashapi.BugStatusDup)
+	c.expectEQ(bugs[0].DupOf, "bugzilla-123")
+
+	noFail := c.makeClient(client1, password1, false)
+	err = noFail.SetExtID(context.Background(), &dashapi.SetExtIDReq{ID: rep.ID})
+	c.expectBadReqest(err)
+	err = noFail.SetExtID(context.Background(), &dashapi.SetExtIDReq{ID: "unknown", ExtID: "bugzilla-1"})
+	c.expectNE(err, nil)
+}
+
+func TestBugSeverity(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+
+	// Memory corruptions with a reproducer are severe.
+	crash1 := testCrashWithRepro(build, 1)
+	crash1.Title = "KASAN: use-after-free Read in foo"
+	crash1.Type = dashapi.CrashTypeKASAN
+	c.client.ReportCrash(context.Background(), crash1)
+	crash2 := testCrash(build, 2)
+	crash2.Title = "WARNING in bar"
+	crash2.Type = dashapi.CrashTypeWarning
+	c.client.ReportCrash(context.Background(), crash2)
+	reps := c.client.pollBugs(2)
+	c.expectEQ(reps[0].Title, crash1.Title)
+	c.expectEQ(reps[0].Severity, dashapi.SeverityHigh)
+	c.expectEQ(reps[1].Severity, dashapi.SeverityUnknown)
+
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:       reps[1].ID,
+		Status:   dashapi.BugStatusUpdate,
+		Severity: dashapi.SeverityCritical,
+	})
+	c.expectTrue(reply.OK)
+	reply, _ = c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:       reps[1].ID,
+		Status:   dashapi.BugStatusUpdate,
+		Severity: 100,
+	})
+	c.expectEQ(reply.OK, false)
+
+	bug, _, _ := c.loadBug(reps[1].ID)
+	c.expectEQ(bug.Severity, dashapi.SeverityCritical)
+	page, err := c.AuthGET(AccessAdmin, "/bug?extid="+reps[1].ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte("Severity: critical")))
+
+	// Reports for the bug carry the set severity.
+	crash3 := testCrashWithRepro(build, 3)
+	crash3.Title = crash2.Title
+	c.client.ReportCrash(context.Background(), crash3)
+	rep := c.client.pollBug()
+	c.expectEQ(rep.Title, crash2.Title)
+	c.expectEQ(rep.Severity, dashapi.SeverityCritical)
+}
+
+func TestReportingPollBugsPage(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build1 := testBuild(1)
+	c.client.UploadBuild(context.Background(), build1)
+	build2 := testBuild(2)
+	c.client.UploadBuild(context.Background(), build2)
+	for i := 1; i <= 3; i++ {
+		c.client.ReportCrash(context.Background(), testCrash(build1, i))
+	}
+	c.client.ReportCrash(context.Background(), testCrash(build2, 4))
+
+	resp, err := c.client.ReportingPollBugsPage(context.Background(), &dashapi.PollBugsRequest{
+		Type:       "test",
+		Manager:    build1.Manager,
+		MaxReports: 2,
+	})
+	c.expectOK(err)
+	c.expectEQ(len(resp.Reports), 2)
+	c.expectNE(resp.Cursor, "")
+	seen := map[string]bool{}
+	for _, rep := range resp.Reports {
+		c.expectEQ(rep.Manager, build1.Manager)
+		seen[rep.ID] = true
+	}
+	resp, err = c.client.ReportingPollBugsPage(context.Background(), &dashapi.PollBugsRequest{
+		Type:       "test",
+		Manager:    build1.Manager,
+		MaxReports: 2,
+		Cursor:     resp.Cursor,
+	})
+	c.expectOK(err)
+	c.expectEQ(len(resp.Reports), 1)
+	c.expectEQ(resp.Cursor, "")
+	c.expectEQ(resp.Reports[0].Manager, build1.Manager)
+	c.expectTrue(!seen[resp.Reports[0].ID])
+
+	resp, err = c.client.ReportingPollBugsPage(context.Background(), &dashapi.PollBugsRequest{
+		Type:      "test",
+		Namespace: "test2",
+	})
+	c.expectOK(err)
+	c.expectEQ(len(resp.Reports), 0)
+
+	// The default page size is still small.
+	c.client.pollBugs(3)
+	c.client.pollBugs(1)
+}
+
+func TestReportingLongPoll(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	caps, err := c.client.Capabilities(context.Background())
+	c.expectOK(err)
+	c.expectTrue(caps.Supports(dashapi.FeatureLongPoll))
+
+	// Nothing to report, the dashboard replies after the wait.
+	resp, err := c.client.ReportingWaitBugs(context.Background(), &dashapi.PollBugsRequest{Type: "test"},
+		time.Second)
+	c.expectOK(err)
+	c.expectEQ(len(resp.Reports), 0)
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	resp, err = c.client.ReportingWaitBugs(context.Background(), &dashapi.PollBugsRequest{Type: "test"},
+		dashapi.MaxPollWait)
+	c.expectOK(err)
+	c.expectEQ(len(resp.Reports), 1)
+	c.client.pollBug()
+}
+
+func TestCrashEmbargo(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.makeClient(clientPublic, keyPublic, true)
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+
+	// A public bug becomes embargoed.
+	crash1 := testCrash(build, 1)
+	crash1.Title = "public bug"
+	client.ReportCrash(context.Background(), crash1)
+	rep := client.pollBug()
+	client.updateBug(rep.ID, dashapi.BugStatusUpstream, "")
+	rep = client.pollBug()
+	c.expectEQ(rep.EmbargoUntil, time.Time{})
+	page, err := c.AuthGET(AccessPublic, "/access-public")
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte(crash1.Title)))
+
+	crash1.EmbargoUntil = c.mockedTime.Add(7 * 24 * time.Hour)
+	client.ReportCrash(context.Background(), crash1)
+	page, err = c.AuthGET(AccessPublic, "/access-public")
+	c.expectOK(err)
+	c.expectTrue(!bytes.Contains(page, []byte(crash1.Title)))
+	_, err = c.AuthGET(AccessPublic, "/bug?extid="+rep.ID)
+	c.expectNE(err, nil)
+	page, err = c.AuthGET(AccessUser, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte("Embargoed until")))
+
+	// The embargo passes and new crashes don't embargo the bug again.
+	c.advanceTime(8 * 24 * time.Hour)
+	crash1.EmbargoUntil = c.mockedTime.Add(7 * 24 * time.Hour)
+	client.ReportCrash(context.Background(), crash1)
+	page, err = c.AuthGET(AccessPublic, "/access-public")
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte(crash1.Title)))
+
+	// Embargoed bugs are not reported to public reportings.
+	crash2 := testCrash(build, 2)
+	crash2.Title = "embargoed bug"
+	crash2.EmbargoUntil = c.mockedTime.Add(7 * 24 * time.Hour)
+	client.ReportCrash(context.Background(), crash2)
+	rep = client.pollBug()
+	c.expectEQ(rep.EmbargoUntil, crash2.EmbargoUntil)
+	client.updateBug(rep.ID, dashapi.BugStatusUpstream, "")
+	client.pollBugs(0)
+
+	// Until the embargo is cleared.
+	crash3 := testCrash(build, 3)
+	crash3.Title = "cleared embargo"
+	crash3.EmbargoUntil = c.mockedTime.Add(7 * 24 * time.Hour)
+	client.ReportCrash(context.Background(), crash3)
+	rep = client.pollBug()
+	c.expectEQ(rep.Title, crash3.Title)
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:           rep.ID,
+		Status:       dashapi.BugStatusUpdate,
+		EmbargoUntil: crash3.EmbargoUntil,
+		ClearEmbargo: true,
+	})
+	c.expectEQ(reply.OK, false)
+	reply, _ = client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
+		ID:           rep.ID,
+		Status:       dashapi.BugStatusUpdate,
+		ClearEmbargo: true,
+	})
+	c.expectTrue(reply.OK)
+	client.updateBug(rep.ID, dashapi.BugStatusUpstream, "")
+	rep = client.pollBug()
+	c.expectEQ(rep.Title, crash3.Title)
+	c.expectEQ(rep.EmbargoUntil, time.Time{})
+}
+
+func TestReportingGeneration(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	gen := reportingGeneration(c.ctx)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	c.expectNE(reportingGeneration(c.ctx), gen)
+	rep := c.client.pollBug()
+
+	// Repeated crashes don't wake up long polls.
+	gen = reportingGeneration(c.ctx)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
+	c.expectEQ(reportingGeneration(c.ctx), gen)
+
+	// Reproducers and bug updates do.
+	c.client.ReportCrash(context.Background(), testCrashWithRepro(build, 1))
+	c.expectNE(reportingGeneration(c.ctx), gen)
+	gen = reportingGeneration(c.ctx)
+	c.client.updateBug(rep.ID, dashapi.BugStatusUpstream, "")
+	c.expectNE(reportingGeneration(c.ctx), gen)
+}
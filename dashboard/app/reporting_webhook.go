@@ -0,0 +1,18 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+const webhookType = "webhook"
+
+// WebhookConfig is the reporting config for bugs delivered to a webhook by tools/syz-webhook.
+// The webhook and its secret are configured in the tool, so that the secret is not stored here.
+type WebhookConfig struct{}
+
+func (cfg *WebhookConfig) Type() string {
+	return webhookType
+}
+
+func (cfg *WebhookConfig) Validate() error {
+	return nil
+}
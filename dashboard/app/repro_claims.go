@@ -0,0 +1,102 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	db "google.golang.org/appengine/v2/datastore"
+)
+
+// Repro claims are ReproLease entities without a TaskID.
+
+func apiClaimRepro(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReproClaimReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" || req.Title == "" {
+		return nil, fmt.Errorf("%w: no manager or title", ErrClientBadRequest)
+	}
+	bug, err := findExistingBugForCrash(c, ns, []string{req.Title})
+	if err != nil {
+		return nil, err
+	}
+	if bug == nil {
+		// Nobody else can claim a crash the dashboard does not know about.
+		return &dashapi.ReproClaimResp{Claimed: true, Manager: req.Manager}, nil
+	}
+	lease := req.Lease
+	if lease <= 0 {
+		lease = dashapi.DefaultReproClaimLease
+	}
+	lease = min(lease, dashapi.MaxReproClaimLease)
+	now := timeNow(c)
+	resp := new(dashapi.ReproClaimResp)
+	key := db.NewKey(c, "ReproLease", bug.keyHash(c), 0, nil)
+	tx := func(c context.Context) error {
+		claim := new(ReproLease)
+		if err := db.Get(c, key, claim); err != nil && err != db.ErrNoSuchEntity {
+			return fmt.Errorf("failed to get repro lease: %w", err)
+		}
+		active := !claim.Done && now.Before(claim.Deadline)
+		if active && claim.Manager != req.Manager {
+			*resp = dashapi.ReproClaimResp{Manager: claim.Manager, Deadline: claim.Deadline}
+			return nil
+		}
+		if !active {
+			claim = &ReproLease{
+				Namespace: ns,
+				Manager:   req.Manager,
+			}
+		}
+		// Renewals don't shorten the leases of repro tasks.
+		if deadline := now.Add(lease); deadline.After(claim.Deadline) {
+			claim.Deadline = deadline
+		}
+		if _, err := db.Put(c, key, claim); err != nil {
+			return fmt.Errorf("failed to put repro lease: %w", err)
+		}
+		*resp = dashapi.ReproClaimResp{Claimed: true, Manager: claim.Manager, Deadline: claim.Deadline}
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func apiReleaseReproClaim(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReproClaimReleaseReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	bug, err := findExistingBugForCrash(c, ns, []string{req.Title})
+	if err != nil || bug == nil {
+		return nil, err
+	}
+	key := db.NewKey(c, "ReproLease", bug.keyHash(c), 0, nil)
+	tx := func(c context.Context) error {
+		claim := new(ReproLease)
+		if err := db.Get(c, key, claim); err != nil {
+			if err == db.ErrNoSuchEntity {
+				return nil
+			}
+			return fmt.Errorf("failed to get repro lease: %w", err)
+		}
+		// Repro tasks are finished with ReproTaskDone.
+		if claim.Done || claim.TaskID != "" || claim.Manager != req.Manager {
+			return nil
+		}
+		claim.Done = true
+		if _, err := db.Put(c, key, claim); err != nil {
+			return fmt.Errorf("failed to put repro lease: %w", err)
+		}
+		return nil
+	}
+	return nil, db.RunInTransaction(c, tx, &db.TransactionOptions{Attempts: 10})
+}
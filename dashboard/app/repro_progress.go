@@ -0,0 +1,80 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	db "google.golang.org/appengine/v2/datastore"
+)
+
+// Managers report the progress every dashapi.ReproProgressPeriod,
+// if they stop, the reproduction has finished or the manager is gone.
+const reproProgressTimeout = 3 * dashapi.ReproProgressPeriod
+
+func apiReproProgress(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.ReproProgress)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" || req.Title == "" {
+		return nil, fmt.Errorf("%w: no manager or title", ErrClientBadRequest)
+	}
+	bug, err := findExistingBugForCrash(c, ns, []string{req.Title})
+	if err != nil {
+		return nil, err
+	}
+	if bug == nil {
+		return nil, fmt.Errorf("%w: no open bug with title %q", ErrClientNotFound, req.Title)
+	}
+	now := timeNow(c)
+	progress := &ReproProgress{
+		Manager: req.Manager,
+		Phase:   req.Phase,
+		Started: now.Add(-req.Elapsed),
+		Updated: now,
+	}
+	if len(req.ReproSyz) <= dashapi.MaxReproProgressProg {
+		progress.ReproSyz = req.ReproSyz
+	}
+	key := db.NewKey(c, "ReproProgress", req.Manager, 0, bug.key(c))
+	if _, err := db.Put(c, key, progress); err != nil {
+		return nil, fmt.Errorf("failed to put repro progress: %w", err)
+	}
+	return nil, nil
+}
+
+// loadReproProgress returns the reproductions of the bug that are still in progress.
+func loadReproProgress(c context.Context, bug *Bug) ([]*uiReproProgress, error) {
+	var all []*ReproProgress
+	if _, err := db.NewQuery("ReproProgress").
+		Ancestor(bug.key(c)).
+		GetAll(c, &all); err != nil {
+		return nil, fmt.Errorf("failed to query repro progress: %w", err)
+	}
+	now := timeNow(c)
+	var ret []*uiReproProgress
+	for _, progress := range all {
+		// A reproduction has finished since the last report, most likely it's this one.
+		if now.Sub(progress.Updated) > reproProgressTimeout || bug.LastReproTime.After(progress.Updated) {
+			continue
+		}
+		ret = append(ret, &uiReproProgress{
+			Manager:  progress.Manager,
+			Phase:    progress.Phase,
+			Elapsed:  now.Sub(progress.Started).Truncate(time.Minute),
+			Updated:  progress.Updated,
+			ReproSyz: string(progress.ReproSyz),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Manager < ret[j].Manager
+	})
+	return ret, nil
+}
@@ -4,13 +4,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/csource"
 )
 
 // Normal workflow:
@@ -22,44 +27,52 @@ func testNeedRepro1(t *testing.T, crashCtor func(c *Ctx) *dashapi.Crash, newBug
 	defer c.Close()
 
 	crash1 := crashCtor(c)
-	resp, _ := c.client.ReportCrash(crash1)
+	resp, _ := c.client.ReportCrash(context.Background(), crash1)
 	c.expectEQ(resp.NeedRepro, true)
 
 	cid := testCrashID(crash1)
-	needRepro, _ := c.client.NeedRepro(cid)
+	needRepro, _ := c.client.NeedRepro(context.Background(), cid)
 	c.expectEQ(needRepro, true)
 
 	// Still need repro for this crash.
-	resp, _ = c.client.ReportCrash(crash1)
+	resp, _ = c.client.ReportCrash(context.Background(), crash1)
 	c.expectEQ(resp.NeedRepro, true)
-	needRepro, _ = c.client.NeedRepro(cid)
+	needRepro, _ = c.client.NeedRepro(context.Background(), cid)
 	c.expectEQ(needRepro, true)
 
 	crash2 := new(dashapi.Crash)
 	*crash2 = *crash1
 	crash2.ReproOpts = []byte("opts")
 	crash2.ReproSyz = []byte("repro syz")
-	resp, _ = c.client.ReportCrash(crash2)
+	resp, _ = c.client.ReportCrash(context.Background(), crash2)
 	c.expectEQ(resp.NeedRepro, true)
-	needRepro, _ = c.client.NeedRepro(cid)
+	c.expectEQ(resp.ReproLevel, dashapi.ReproLevelSyz)
+	needRepro, _ = c.client.NeedRepro(context.Background(), cid)
 	c.expectEQ(needRepro, true)
+	status, err := c.client.ReproStatus(context.Background(), cid)
+	c.expectOK(err)
+	c.expectEQ(status, &dashapi.NeedReproResp{NeedRepro: true, ReproLevel: dashapi.ReproLevelSyz})
 
 	// MayBeMissing flag must not affect bugs that actually exist.
 	cidMissing := testCrashID(crash1)
 	cidMissing.MayBeMissing = true
-	needRepro, _ = c.client.NeedRepro(cidMissing)
+	needRepro, _ = c.client.NeedRepro(context.Background(), cidMissing)
 	c.expectEQ(needRepro, true)
 
 	crash2.ReproC = []byte("repro C")
-	resp, _ = c.client.ReportCrash(crash2)
+	resp, _ = c.client.ReportCrash(context.Background(), crash2)
 	c.expectEQ(resp.NeedRepro, false)
-	needRepro, _ = c.client.NeedRepro(cid)
+	c.expectEQ(resp.ReproLevel, dashapi.ReproLevelC)
+	needRepro, _ = c.client.NeedRepro(context.Background(), cid)
 	c.expectEQ(needRepro, false)
+	status, err = c.client.ReproStatus(context.Background(), cid)
+	c.expectOK(err)
+	c.expectEQ(status, &dashapi.NeedReproResp{ReproLevel: dashapi.ReproLevelC})
 
-	needRepro, _ = c.client.NeedRepro(cidMissing)
+	needRepro, _ = c.client.NeedRepro(context.Background(), cidMissing)
 	c.expectEQ(needRepro, false)
 
-	resp, _ = c.client.ReportCrash(crash2)
+	resp, _ = c.client.ReportCrash(context.Background(), crash2)
 	c.expectEQ(resp.NeedRepro, false)
 	if newBug {
 		c.client.pollBug()
@@ -80,10 +93,10 @@ func testNeedRepro2(t *testing.T, crashCtor func(c *Ctx) *dashapi.Crash, newBug
 	crash1.ReproOpts = []byte("opts")
 	crash1.ReproSyz = []byte("repro syz")
 	crash1.ReproC = []byte("repro C")
-	resp, _ := c.client.ReportCrash(crash1)
+	resp, _ := c.client.ReportCrash(context.Background(), crash1)
 	c.expectEQ(resp.NeedRepro, false)
 
-	needRepro, _ := c.client.NeedRepro(testCrashID(crash1))
+	needRepro, _ := c.client.NeedRepro(context.Background(), testCrashID(crash1))
 	c.expectEQ(needRepro, false)
 	if newBug {
 		c.client.pollBug()
@@ -102,30 +115,30 @@ func testNeedRepro3(t *testing.T, crashCtor func(c *Ctx) *dashapi.Crash) {
 
 	crash1 := crashCtor(c)
 	for i := 0; i < maxReproPerBug; i++ {
-		resp, _ := c.client.ReportCrash(crash1)
+		resp, _ := c.client.ReportCrash(context.Background(), crash1)
 		c.expectEQ(resp.NeedRepro, true)
-		needRepro, _ := c.client.NeedRepro(testCrashID(crash1))
+		needRepro, _ := c.client.NeedRepro(context.Background(), testCrashID(crash1))
 		c.expectEQ(needRepro, true)
-		c.client.ReportFailedRepro(testCrashID(crash1))
+		c.client.ReportFailedRepro(context.Background(), testCrashID(crash1))
 	}
 
 	for i := 0; i < 3; i++ {
 		// No more repros today.
 		c.advanceTime(time.Hour)
-		resp, _ := c.client.ReportCrash(crash1)
+		resp, _ := c.client.ReportCrash(context.Background(), crash1)
 		c.expectEQ(resp.NeedRepro, false)
-		needRepro, _ := c.client.NeedRepro(testCrashID(crash1))
+		needRepro, _ := c.client.NeedRepro(context.Background(), testCrashID(crash1))
 		c.expectEQ(needRepro, false)
 
 		// Then another repro after a day.
 		c.advanceTime(25 * time.Hour)
 		for j := 0; j < 2; j++ {
-			resp, _ := c.client.ReportCrash(crash1)
+			resp, _ := c.client.ReportCrash(context.Background(), crash1)
 			c.expectEQ(resp.NeedRepro, true)
-			needRepro, _ := c.client.NeedRepro(testCrashID(crash1))
+			needRepro, _ := c.client.NeedRepro(context.Background(), testCrashID(crash1))
 			c.expectEQ(needRepro, true)
 		}
-		c.client.ReportFailedRepro(testCrashID(crash1))
+		c.client.ReportFailedRepro(context.Background(), testCrashID(crash1))
 	}
 }
 
@@ -136,19 +149,19 @@ func TestNeedRepro3_closedRepro(t *testing.T) { testNeedRepro3(t, closedWithRepr
 
 func normalCrash(c *Ctx) *dashapi.Crash {
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 	crash := testCrash(build, 1)
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	c.client.pollBug()
 	return crash
 }
 
 func dupCrash(c *Ctx) *dashapi.Crash {
 	build := testBuild(1)
-	c.client.UploadBuild(build)
-	c.client.ReportCrash(testCrash(build, 1))
+	c.client.UploadBuild(context.Background(), build)
+	c.client.ReportCrash(context.Background(), testCrash(build, 1))
 	crash2 := testCrash(build, 2)
-	c.client.ReportCrash(crash2)
+	c.client.ReportCrash(context.Background(), crash2)
 	reports := c.client.pollBugs(2)
 	c.client.updateBug(reports[1].ID, dashapi.BugStatusDup, reports[0].ID)
 	return crash2
@@ -164,20 +177,20 @@ func closedWithReproCrash(c *Ctx) *dashapi.Crash {
 
 func closedCrashImpl(c *Ctx, withRepro bool) *dashapi.Crash {
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash := testCrash(build, 1)
 	if withRepro {
 		crash.ReproC = []byte("repro C")
 	}
-	resp, _ := c.client.ReportCrash(crash)
+	resp, _ := c.client.ReportCrash(context.Background(), crash)
 	c.expectEQ(resp.NeedRepro, !withRepro)
 
 	rep := c.client.pollBug()
 	c.client.updateBug(rep.ID, dashapi.BugStatusInvalid, "")
 
 	crash.ReproC = nil
-	c.client.ReportCrash(crash)
+	c.client.ReportCrash(context.Background(), crash)
 	c.client.pollBug()
 	return crash
 }
@@ -192,12 +205,12 @@ func TestNeedReproMissing(t *testing.T) {
 		BuildID: "some missing build",
 		Title:   "some missing title",
 	}
-	needRepro, err := client.NeedRepro(cid)
+	needRepro, err := client.NeedRepro(context.Background(), cid)
 	c.expectNE(err, nil)
 	c.expectEQ(needRepro, false)
 
 	cid.MayBeMissing = true
-	needRepro, err = client.NeedRepro(cid)
+	needRepro, err = client.NeedRepro(context.Background(), cid)
 	c.expectEQ(err, nil)
 	c.expectEQ(needRepro, true)
 }
@@ -349,7 +362,7 @@ func TestFailedReproLogs(t *testing.T) {
 	defer c.Close()
 
 	build := testBuild(1)
-	c.client.UploadBuild(build)
+	c.client.UploadBuild(context.Background(), build)
 
 	crash1 := &dashapi.Crash{
 		BuildID: "build1",
@@ -357,12 +370,12 @@ func TestFailedReproLogs(t *testing.T) {
 		Log:     []byte("log1"),
 		Report:  []byte("report1"),
 	}
-	c.client.ReportCrash(crash1)
+	c.client.ReportCrash(context.Background(), crash1)
 
-	resp, _ := c.client.ReportingPollBugs("test")
+	resp, _ := c.client.ReportingPollBugs(context.Background(), "test")
 	c.expectEQ(len(resp.Reports), 1)
 	rep := resp.Reports[0]
-	c.client.ReportingUpdate(&dashapi.BugUpdate{
+	c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusOpen,
 	})
@@ -375,7 +388,7 @@ func TestFailedReproLogs(t *testing.T) {
 	for i := 0; i < maxReproLogs; i++ {
 		c.advanceTime(time.Minute)
 		cid.ReproLog = []byte(fmt.Sprintf("report log %#v", i))
-		err := c.client.ReportFailedRepro(cid)
+		err := c.client.ReportFailedRepro(context.Background(), cid)
 		c.expectOK(err)
 	}
 
@@ -385,7 +398,7 @@ func TestFailedReproLogs(t *testing.T) {
 
 	// Report one more.
 	cid.ReproLog = []byte(fmt.Sprintf("report log %#v", maxReproLogs))
-	err := c.client.ReportFailedRepro(cid)
+	err := c.client.ReportFailedRepro(context.Background(), cid)
 	c.expectOK(err)
 
 	dbBug, _, _ = c.loadBug(rep.ID)
@@ -408,19 +421,19 @@ func TestLogToReproduce(t *testing.T) {
 	client := c.client
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Also add some unrelated crash, which should not appear in responses.
 	build2 := testBuild(2)
-	client.UploadBuild(build2)
-	client.ReportCrash(testCrash(build2, 3))
+	client.UploadBuild(context.Background(), build2)
+	client.ReportCrash(context.Background(), testCrash(build2, 3))
 	client.pollBug()
 
 	// Bug with a reproducer.
 	crash1 := testCrashWithRepro(build, 1)
-	client.ReportCrash(crash1)
+	client.ReportCrash(context.Background(), crash1)
 	client.pollBug()
-	resp, err := client.LogToRepro(&dashapi.LogToReproReq{BuildID: "build1"})
+	resp, err := client.LogToRepro(context.Background(), &dashapi.LogToReproReq{BuildID: "build1"})
 	c.expectOK(err)
 	c.expectEQ(resp.CrashLog, []byte(nil))
 
@@ -431,16 +444,16 @@ func TestLogToReproduce(t *testing.T) {
 		Log:     []byte("log2"),
 		Report:  []byte("report2"),
 	}
-	client.ReportCrash(crash2)
+	client.ReportCrash(context.Background(), crash2)
 	client.pollBug()
-	resp, err = client.LogToRepro(&dashapi.LogToReproReq{BuildID: "build1"})
+	resp, err = client.LogToRepro(context.Background(), &dashapi.LogToReproReq{BuildID: "build1"})
 	c.expectOK(err)
 	c.expectEQ(resp.Title, "title2")
 	c.expectEQ(resp.CrashLog, []byte("log2"))
 	c.expectEQ(resp.Type, dashapi.RetryReproLog)
 
 	// Suppose we tried to find a repro, but failed.
-	err = client.ReportFailedRepro(&dashapi.CrashID{
+	err = client.ReportFailedRepro(context.Background(), &dashapi.CrashID{
 		BuildID:  crash2.BuildID,
 		Title:    crash2.Title,
 		ReproLog: []byte("abcd"),
@@ -448,7 +461,7 @@ func TestLogToReproduce(t *testing.T) {
 	c.expectOK(err)
 
 	// Now this crash should not be suggested.
-	resp, err = client.LogToRepro(&dashapi.LogToReproReq{BuildID: "build1"})
+	resp, err = client.LogToRepro(context.Background(), &dashapi.LogToReproReq{BuildID: "build1"})
 	c.expectOK(err)
 	c.expectEQ(resp.CrashLog, []byte(nil))
 }
@@ -462,7 +475,7 @@ func TestReproForDifferentCrash(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Original crash.
 	crash := &dashapi.Crash{
@@ -471,7 +484,7 @@ func TestReproForDifferentCrash(t *testing.T) {
 		Log:     []byte("log1"),
 		Report:  []byte("report1"),
 	}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	oldBug := client.pollBug()
 
 	// Now we have "found" a reproducer with a different title.
@@ -480,7 +493,7 @@ func TestReproForDifferentCrash(t *testing.T) {
 	crash.ReproSyz = []byte("repro syz")
 	crash.ReproLog = []byte("repro log")
 	crash.OriginalTitle = "title1"
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollBug()
 
 	// Ensure that we have saved the reproduction log in this case.
@@ -495,7 +508,7 @@ func TestReproTask(t *testing.T) {
 
 	build := testBuild(1)
 	build.Manager = "test-manager"
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	form := url.Values{}
 	const reproValue = "Some repro text"
@@ -505,14 +518,382 @@ func TestReproTask(t *testing.T) {
 
 	// We run the reproducer request 2 times.
 	for i := 0; i < 2; i++ {
-		resp, err := client.LogToRepro(&dashapi.LogToReproReq{BuildID: build.ID})
+		resp, err := client.LogToRepro(context.Background(), &dashapi.LogToReproReq{BuildID: build.ID})
 		c.expectOK(err)
 		c.expectEQ(string(resp.CrashLog), reproValue)
 		c.expectEQ(resp.Type, dashapi.ManualLog)
 	}
 
 	// But no more.
-	resp, err := client.LogToRepro(&dashapi.LogToReproReq{BuildID: build.ID})
+	resp, err := client.LogToRepro(context.Background(), &dashapi.LogToReproReq{BuildID: build.ID})
 	c.expectOK(err)
 	c.expectEQ(resp.CrashLog, []byte(nil))
 }
+
+func TestGetRepro(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+	client := c.client
+
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+
+	// No repro yet.
+	client.ReportCrash(context.Background(), testCrash(build, 1))
+	client.pollBug()
+	listResp, err := client.BugList(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(listResp.List), 1)
+	bugID := listResp.List[0]
+	_, err = client.GetRepro(context.Background(), bugID)
+	c.expectEQ(err, dashapi.ErrReproNotFound)
+
+	crash := testCrashWithRepro(build, 1)
+	client.ReportCrash(context.Background(), crash)
+	repro, err := client.GetRepro(context.Background(), bugID)
+	c.expectOK(err)
+	c.expectEQ(repro, &dashapi.Repro{
+		Level:        dashapi.ReproLevelC,
+		Syz:          crash.ReproSyz,
+		C:            crash.ReproC,
+		Opts:         crash.ReproOpts,
+		KernelConfig: build.KernelConfig,
+		BuildID:      build.ID,
+	})
+
+	repro, err = client.GetRepro(context.Background(), bugID, dashapi.NoKernelConfig(true))
+	c.expectOK(err)
+	c.expectEQ(repro.KernelConfig, []byte(nil))
+	c.expectEQ(repro.Syz, crash.ReproSyz)
+
+	// Read-only clients can download repros, but nothing else.
+	readOnly := c.makeClient(clientReadOnly, keyReadOnly, false)
+	repro, err = readOnly.GetRepro(context.Background(), bugID, dashapi.NoKernelConfig(true))
+	c.expectOK(err)
+	c.expectEQ(repro.Syz, crash.ReproSyz)
+	err = readOnly.UploadBuild(context.Background(), testBuild(2))
+	c.expectTrue(errors.Is(err, dashapi.ErrAccessDenied))
+
+	_, err = c.makeClient(client1, password1, false).GetRepro(context.Background(), "nonexistent-bug")
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	_, err = c.makeClient(client2, password2, false).GetRepro(context.Background(), bugID)
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+}
+
+func TestGetReproURLs(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+	client := c.client
+
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+	crash := testCrashWithRepro(build, 1)
+	crash.ReproC = bytes.Repeat([]byte("// padding\n"), minBlobURLSize/4)
+	client.ReportCrash(context.Background(), crash)
+	client.pollBug()
+	listResp, err := client.BugList(context.Background())
+	c.expectOK(err)
+	bugID := listResp.List[0]
+
+	// The reply is the same, but the C repro is downloaded separately.
+	repro, err := client.GetRepro(context.Background(), bugID, dashapi.PreferURLs(true))
+	c.expectOK(err)
+	c.expectEQ(repro, &dashapi.Repro{
+		Level:        dashapi.ReproLevelC,
+		Syz:          crash.ReproSyz,
+		C:            crash.ReproC,
+		Opts:         crash.ReproOpts,
+		KernelConfig: build.KernelConfig,
+		BuildID:      build.ID,
+	})
+	rep, err := client.LoadBug(context.Background(), bugID, dashapi.PreferURLs(true))
+	c.expectOK(err)
+	c.expectEQ(rep.ReproC, crash.ReproC)
+	c.expectEQ(rep.BlobRefs, []dashapi.BlobRef(nil))
+
+	bug, _ := c.loadSingleBug()
+	reproCrash, _, err := findCrashForBug(c.ctx, bug)
+	c.expectOK(err)
+	link := blobURL(c.ctx, bug.Namespace, textReproC, reproCrash.ReproC)
+	data, err := c.GET(link)
+	c.expectOK(err)
+	c.expectEQ(data, crash.ReproC)
+
+	// Tampered URLs are rejected.
+	_, err = c.GET(strings.Replace(link, "tag=ReproC", "tag=ReproSyz", 1))
+	c.expectEQ(err.(*HTTPError).Code, http.StatusForbidden)
+
+	// Expired URLs are rejected.
+	c.advanceTime(2 * blobURLExpiration)
+	_, err = c.GET(link)
+	c.expectEQ(err.(*HTTPError).Code, http.StatusGone)
+}
+
+func TestReproTaskQueue(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+	client := c.client
+
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+	crash1 := testCrash(build, 1)
+	client.ReportCrash(context.Background(), crash1)
+	crash2 := testCrash(build, 2)
+	for i := 0; i < 3; i++ {
+		client.ReportCrash(context.Background(), crash2)
+	}
+
+	// More frequent bugs are reproduced first and leased bugs are not handed out again.
+	task1, err := client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task1.Title, crash2.Title)
+	c.expectEQ(task1.CrashLog, crash2.Log)
+	task2, err := client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task2.Title, crash1.Title)
+	task, err := client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task, (*dashapi.ReproTask)(nil))
+	// Other managers don't get the bugs either.
+	task, err = client.ReproTaskPoll(context.Background(), "other-manager")
+	c.expectOK(err)
+	c.expectEQ(task, (*dashapi.ReproTask)(nil))
+
+	// Abandoned tasks are handed out again.
+	c.expectOK(client.ReproTaskDone(context.Background(), build.Manager, task1.TaskID, &dashapi.ReproTaskResult{
+		Status: dashapi.ReproTaskAbandoned,
+	}))
+	task, err = client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task.Title, crash2.Title)
+
+	// Other managers can't complete the task.
+	noFail := c.makeClient(client1, password1, false)
+	err = noFail.ReproTaskDone(context.Background(), "other-manager", task2.TaskID, &dashapi.ReproTaskResult{
+		Status:   dashapi.ReproTaskFailed,
+		BuildID:  build.ID,
+		ReproLog: []byte("repro log"),
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrAccessDenied))
+	bug, err := findExistingBugForCrash(c.ctx, "test1", []string{crash1.Title})
+	c.expectOK(err)
+	c.expectEQ(bug.NumRepro, int64(0))
+
+	// Failures are recorded as repro attempts.
+	c.expectOK(client.ReproTaskDone(context.Background(), build.Manager, task2.TaskID, &dashapi.ReproTaskResult{
+		Status:   dashapi.ReproTaskFailed,
+		BuildID:  build.ID,
+		ReproLog: []byte("repro log"),
+		Reasons:  []string{"no crash"},
+	}))
+	bug, err = findExistingBugForCrash(c.ctx, "test1", []string{crash1.Title})
+	c.expectOK(err)
+	c.expectEQ(bug.NumRepro, int64(1))
+	// Repeated results are not recorded again.
+	c.expectOK(client.ReproTaskDone(context.Background(), build.Manager, task2.TaskID, &dashapi.ReproTaskResult{
+		Status:   dashapi.ReproTaskFailed,
+		BuildID:  build.ID,
+		ReproLog: []byte("repro log"),
+	}))
+	bug, err = findExistingBugForCrash(c.ctx, "test1", []string{crash1.Title})
+	c.expectOK(err)
+	c.expectEQ(bug.NumRepro, int64(1))
+
+	// Leases expire.
+	c.advanceTime(reproTaskLease + time.Minute)
+	task1, err = client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task1.Title, crash2.Title)
+	// The results of the expired lease are rejected.
+	err = noFail.ReproTaskDone(context.Background(), build.Manager, task.TaskID, &dashapi.ReproTaskResult{
+		Status:   dashapi.ReproTaskFailed,
+		BuildID:  build.ID,
+		ReproLog: []byte("repro log"),
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+	bug, err = findExistingBugForCrash(c.ctx, "test1", []string{crash2.Title})
+	c.expectOK(err)
+	c.expectEQ(bug.NumRepro, int64(0))
+
+	// Once the repro is found, the bug is no longer handed out.
+	c.expectOK(client.ReproTaskDone(context.Background(), build.Manager, task1.TaskID, &dashapi.ReproTaskResult{
+		Status: dashapi.ReproTaskSucceeded,
+		Crash:  testCrashWithRepro(build, 2),
+	}))
+	task, err = client.ReproTaskPoll(context.Background(), build.Manager)
+	c.expectOK(err)
+	c.expectEQ(task.Title, crash1.Title)
+}
+
+func TestReproLogLink(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrashWithRepro(build, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+	c.expectNE(rep.ReproLogLink, "")
+	c.checkURLContents(rep.ReproLogLink, crash.ReproLog)
+}
+
+func TestReproOptions(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrashWithRepro(build, 1)
+	crash.ReproOpts = csource.Options{
+		Threaded:     true,
+		Repeat:       true,
+		Procs:        2,
+		Sandbox:      "none",
+		NetInjection: true,
+		LegacyOptions: csource.LegacyOptions{
+			Fault:     true,
+			FaultCall: 1,
+		},
+	}.Serialize()
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+	c.expectEQ(rep.ReproOpts, crash.ReproOpts)
+	c.expectEQ(rep.ReproOptions, &dashapi.ReproOptions{
+		Threaded:  true,
+		Repeat:    true,
+		Procs:     2,
+		Sandbox:   "none",
+		Fault:     true,
+		FaultCall: 1,
+		Features:  []string{"tun"},
+	})
+
+	listResp, err := c.client.BugList(context.Background())
+	c.expectOK(err)
+	repro, err := c.client.GetRepro(context.Background(), listResp.List[0])
+	c.expectOK(err)
+	c.expectEQ(repro.Options, rep.ReproOptions)
+}
+
+func TestFailedReproInfo(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+
+	// The attempt did not run anything, so it does not count.
+	cid := testCrashID(crash)
+	cid.ReproLog = []byte("no VMs")
+	cid.FailedRepro = &dashapi.FailedRepro{}
+	c.expectOK(c.client.ReportFailedRepro(context.Background(), cid))
+	bug, _, _ := c.loadBug(rep.ID)
+	c.expectEQ(bug.NumRepro, int64(0))
+
+	cid.ReproLog = []byte("repro log")
+	cid.FailedRepro = &dashapi.FailedRepro{
+		Attempts:   20,
+		Strategies: []dashapi.ReproStrategy{dashapi.ReproStrategyBisection, dashapi.ReproStrategyMinimization},
+		Duration:   time.Hour,
+	}
+	c.expectOK(c.client.ReportFailedRepro(context.Background(), cid))
+	bug, _, _ = c.loadBug(rep.ID)
+	c.expectEQ(bug.NumRepro, int64(1))
+	c.expectEQ(len(bug.ReproAttempts), 2)
+	attempt := bug.ReproAttempts[1]
+	c.expectEQ(attempt.Attempts, 20)
+	c.expectEQ(attempt.Strategies, "log_bisection,minimization")
+	c.expectEQ(attempt.Duration, time.Hour)
+}
+
+func TestReproProgress(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	rep := c.client.pollBug()
+
+	err := c.makeClient(client1, password1, false).ReportReproProgress(context.Background(), &dashapi.ReproProgress{
+		Manager: build.Manager,
+		Title:   "unknown title",
+		Phase:   dashapi.ReproPhaseExtractProg,
+	})
+	c.expectTrue(errors.Is(err, dashapi.ErrNotFound))
+
+	c.expectOK(c.client.ReportReproProgress(context.Background(), &dashapi.ReproProgress{
+		Manager:  build.Manager,
+		Title:    crash.Title,
+		Phase:    dashapi.ReproPhaseMinimizeProg,
+		Elapsed:  time.Hour,
+		ReproSyz: []byte("getpid()"),
+	}))
+	page, err := c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(bytes.Contains(page, []byte("Reproduction in progress (1)")))
+	c.expectTrue(bytes.Contains(page, []byte(string(dashapi.ReproPhaseMinimizeProg))))
+	c.expectTrue(bytes.Contains(page, []byte("getpid()")))
+
+	// The manager stopped reporting the progress.
+	c.advanceTime(time.Hour)
+	page, err = c.AuthGET(AccessAdmin, "/bug?extid="+rep.ID)
+	c.expectOK(err)
+	c.expectTrue(!bytes.Contains(page, []byte("Reproduction in progress")))
+}
+
+func TestReproClaims(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	build := testBuild(1)
+	c.client.UploadBuild(context.Background(), build)
+	crash := testCrash(build, 1)
+	c.client.ReportCrash(context.Background(), crash)
+	c.client.pollBug()
+
+	claim := func(manager string, lease time.Duration) *dashapi.ReproClaimResp {
+		resp, err := c.client.ClaimRepro(context.Background(), &dashapi.ReproClaimReq{
+			Manager: manager,
+			Title:   crash.Title,
+			Lease:   lease,
+		})
+		c.expectOK(err)
+		return resp
+	}
+	resp := claim("manager1", 0)
+	c.expectTrue(resp.Claimed)
+	c.expectEQ(resp.Deadline, c.mockedTime.Add(dashapi.DefaultReproClaimLease))
+	resp = claim("manager2", 0)
+	c.expectEQ(resp.Claimed, false)
+	c.expectEQ(resp.Manager, "manager1")
+
+	// The holder renews the claim.
+	c.advanceTime(30 * time.Minute)
+	resp = claim("manager1", 24*time.Hour)
+	c.expectTrue(resp.Claimed)
+	c.expectEQ(resp.Deadline, c.mockedTime.Add(dashapi.MaxReproClaimLease))
+
+	// Only the holder releases the claim.
+	c.expectOK(c.client.ReleaseReproClaim(context.Background(), "manager2", crash.Title))
+	c.expectEQ(claim("manager2", 0).Claimed, false)
+	c.expectOK(c.client.ReleaseReproClaim(context.Background(), "manager1", crash.Title))
+	c.expectTrue(claim("manager2", 0).Claimed)
+
+	// The claim expires.
+	c.advanceTime(dashapi.DefaultReproClaimLease + time.Minute)
+	c.expectTrue(claim("manager1", 0).Claimed)
+
+	// There is nothing to coordinate for crashes without bugs.
+	resp, err := c.client.ClaimRepro(context.Background(), &dashapi.ReproClaimReq{
+		Manager: "manager2",
+		Title:   "unknown title",
+	})
+	c.expectOK(err)
+	c.expectTrue(resp.Claimed)
+}
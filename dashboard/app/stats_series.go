@@ -0,0 +1,212 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"google.golang.org/appengine/v2"
+	db "google.golang.org/appengine/v2/datastore"
+	"google.golang.org/appengine/v2/log"
+)
+
+// statsPeriods are the periods the stats series are downsampled to, from the finest one.
+// Samples of a period are kept for Retention, 0 means forever.
+var statsPeriods = []struct {
+	Period    time.Duration
+	Retention time.Duration
+}{
+	{10 * time.Minute, 2 * 24 * time.Hour},
+	{time.Hour, 30 * 24 * time.Hour},
+	{24 * time.Hour, 0},
+}
+
+// StatsSeries requests covering longer ranges get coarser periods.
+const maxStatsSeriesPoints = 1000
+
+func apiUploadStatsSeries(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UploadStatsSeriesReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.Manager == "" {
+		return nil, fmt.Errorf("%w: no manager", ErrClientBadRequest)
+	}
+	if len(req.Points) > dashapi.MaxStatsPoints {
+		return nil, fmt.Errorf("%w: too many points (%v)", ErrClientBadRequest, len(req.Points))
+	}
+	now := timeNow(c)
+	var keys []*db.Key
+	index := make(map[string]int)
+	for _, point := range req.Points {
+		if point.Time.After(now.Add(time.Hour)) {
+			return nil, fmt.Errorf("%w: point %v is in the future", ErrClientBadRequest, point.Time)
+		}
+		for _, p := range statsPeriods {
+			key := statsSampleKey(c, ns, req.Manager, p.Period, point.Time)
+			if _, ok := index[key.StringID()]; !ok {
+				index[key.StringID()] = len(keys)
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	samples := make([]*ManagerStatsSample, len(keys))
+	err := db.GetMulti(c, keys, samples)
+	var errs appengine.MultiError
+	if err != nil && !errors.As(err, &errs) {
+		return nil, fmt.Errorf("failed to get stats samples: %w", err)
+	}
+	newSamples := false
+	for i := range samples {
+		if errs == nil || errs[i] == nil {
+			continue
+		}
+		if !errors.Is(errs[i], db.ErrNoSuchEntity) {
+			return nil, fmt.Errorf("failed to get stats sample: %w", errs[i])
+		}
+		samples[i] = nil
+		newSamples = true
+	}
+	for _, point := range req.Points {
+		for _, p := range statsPeriods {
+			i := index[statsSampleKey(c, ns, req.Manager, p.Period, point.Time).StringID()]
+			if samples[i] == nil {
+				samples[i] = &ManagerStatsSample{
+					Namespace: ns,
+					Manager:   req.Manager,
+					Period:    p.Period,
+					Time:      point.Time.Truncate(p.Period),
+				}
+			}
+			samples[i].update(point)
+		}
+	}
+	if _, err := db.PutMulti(c, keys, samples); err != nil {
+		return nil, fmt.Errorf("failed to put stats samples: %w", err)
+	}
+	if newSamples {
+		// New samples are created at most once per the finest period, which is a good time to purge.
+		if err := purgeStatsSamples(c, ns, req.Manager, now); err != nil {
+			log.Errorf(c, "failed to purge stats samples of %v: %v", req.Manager, err)
+		}
+	}
+	return nil, nil
+}
+
+func apiStatsSeries(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.StatsSeriesReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	now := timeNow(c)
+	to := req.To
+	if to.IsZero() {
+		to = now
+	}
+	if !req.From.Before(to) {
+		return nil, fmt.Errorf("%w: empty time range", ErrClientBadRequest)
+	}
+	period := statsPeriods[len(statsPeriods)-1].Period
+	for _, p := range statsPeriods {
+		if (p.Retention == 0 || now.Sub(req.From) <= p.Retention) &&
+			to.Sub(req.From)/p.Period <= maxStatsSeriesPoints {
+			period = p.Period
+			break
+		}
+	}
+	query := db.NewQuery("ManagerStatsSample").Filter("Namespace=", ns)
+	if req.Manager != "" {
+		query = query.Filter("Manager=", req.Manager)
+	}
+	var samples []*ManagerStatsSample
+	_, err := query.Filter("Period=", period).
+		Filter("Time>=", req.From.Truncate(period)).
+		Filter("Time<", to).
+		Order("Time").
+		Limit(maxStatsSeriesPoints*100).
+		GetAll(c, &samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats samples: %w", err)
+	}
+	resp := &dashapi.StatsSeriesResp{Period: period}
+	series := make(map[string]*dashapi.StatsSeries)
+	for _, sample := range samples {
+		s := series[sample.Manager]
+		if s == nil {
+			s = &dashapi.StatsSeries{Manager: sample.Manager}
+			series[sample.Manager] = s
+			resp.Series = append(resp.Series, s)
+		}
+		s.Points = append(s.Points, sample.point())
+	}
+	sort.Slice(resp.Series, func(i, j int) bool {
+		return resp.Series[i].Manager < resp.Series[j].Manager
+	})
+	return resp, nil
+}
+
+func purgeStatsSamples(c context.Context, ns, manager string, now time.Time) error {
+	for _, p := range statsPeriods {
+		if p.Retention == 0 {
+			continue
+		}
+		keys, err := db.NewQuery("ManagerStatsSample").
+			Filter("Namespace=", ns).
+			Filter("Manager=", manager).
+			Filter("Period=", p.Period).
+			Filter("Time<", now.Add(-p.Retention)).
+			KeysOnly().
+			Limit(500).
+			GetAll(c, nil)
+		if err != nil {
+			return err
+		}
+		if err := db.DeleteMulti(c, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statsSampleKey(c context.Context, ns, manager string, period time.Duration, t time.Time) *db.Key {
+	id := fmt.Sprintf("%v-%v", int64(period/time.Second), t.Truncate(period).Unix())
+	return db.NewKey(c, "ManagerStatsSample", id, 0, mgrKey(c, ns, manager))
+}
+
+// update replaces the sample values with the point values unless the sample already has a later point.
+func (sample *ManagerStatsSample) update(point dashapi.StatsPoint) {
+	if point.Time.Before(sample.Last) {
+		return
+	}
+	sample.Last = point.Time
+	sample.Names = sample.Names[:0]
+	sample.Values = sample.Values[:0]
+	for name := range point.Values {
+		sample.Names = append(sample.Names, name)
+	}
+	sort.Strings(sample.Names)
+	for _, name := range sample.Names {
+		sample.Values = append(sample.Values, point.Values[name])
+	}
+}
+
+func (sample *ManagerStatsSample) point() dashapi.StatsPoint {
+	point := dashapi.StatsPoint{
+		Time:   sample.Last,
+		Values: make(map[string]int64),
+	}
+	for i, name := range sample.Names {
+		point.Values[name] = sample.Values[i]
+	}
+	return point
+}
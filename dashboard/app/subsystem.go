@@ -158,6 +158,7 @@ func inferSubsystems(c context.Context, bug *Bug, bugKey *db.Key,
 	if err != nil {
 		return nil, err
 	}
+	encrypted := getNsConfig(c, bug.Namespace).EncryptedPayloads
 	crashes := []*subsystem.Crash{}
 	for i, dbCrash := range dbCrashes {
 		crash := &subsystem.Crash{}
@@ -165,7 +166,7 @@ func inferSubsystems(c context.Context, bug *Bug, bugKey *db.Key,
 			// For now we anyway only store one.
 			crash.GuiltyPath = dbCrash.ReportElements.GuiltyFiles[0]
 		}
-		if dbCrash.ReproSyz != 0 {
+		if dbCrash.ReproSyz != 0 && !encrypted {
 			crash.SyzRepro, _, err = getText(c, textReproSyz, dbCrash.ReproSyz)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load syz repro for %s: %w",
@@ -174,7 +175,27 @@ func inferSubsystems(c context.Context, bug *Bug, bugKey *db.Key,
 		}
 		crashes = append(crashes, crash)
 	}
-	return service.TracedExtract(crashes, tracer), nil
+	list := service.TracedExtract(crashes, tracer)
+	if len(list) == 0 {
+		// Fall back to the subsystems suggested by the managers.
+		list = suggestedSubsystems(service, dbCrashes)
+	}
+	return list, nil
+}
+
+func suggestedSubsystems(service *subsystem.Service, crashes []*Crash) []*subsystem.Subsystem {
+	var ret []*subsystem.Subsystem
+	dedup := make(map[*subsystem.Subsystem]bool)
+	for _, crash := range crashes {
+		for _, name := range crash.ReportElements.Subsystems {
+			item := service.ByName(name)
+			if item != nil && !dedup[item] {
+				dedup[item] = true
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
 }
 
 // subsystemMaintainers queries the list of emails to send the bug to.
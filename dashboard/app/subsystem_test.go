@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -39,14 +40,14 @@ func TestPeriodicSubsystemRefresh(t *testing.T) {
 	ns := subsystemTestNs
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug without any subsystems.
 	c.setSubsystems(ns, nil, 1)
 	crash := testCrash(build, 1)
 	crash.Title = "WARNING: abcd"
 	crash.GuiltyFiles = []string{"test.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep := client.pollBug()
 	extID := rep.ID
 
@@ -75,6 +76,63 @@ func TestPeriodicSubsystemRefresh(t *testing.T) {
 	expectLabels(t, client, extID, "subsystems:first")
 }
 
+func TestManagerSuggestedSubsystems(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.client
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+
+	// The guilty file does not match any subsystem, so the manager suggestion is used.
+	crash := testCrash(build, 1)
+	crash.GuiltyFiles = []string{"unknown.c"}
+	crash.Subsystems = []string{"subsystemA", "does-not-exist"}
+	client.ReportCrash(context.Background(), crash)
+	rep := client.pollBug()
+	expectLabels(t, client, rep.ID, "subsystems:subsystemA")
+	c.expectEQ(rep.ReportElements.Subsystems, crash.Subsystems)
+
+	crash2 := testCrash(build, 2)
+	client.ReportCrash(context.Background(), crash2)
+	client.pollBug()
+
+	resp, err := client.OpenBugs(context.Background(), dashapi.OnlySubsystem("subsystemA"))
+	c.expectOK(err)
+	c.expectEQ(len(resp.Bugs), 1)
+	c.expectEQ(resp.Bugs[0].Title, crash.Title)
+	c.expectEQ(resp.Bugs[0].Subsystems, []string{"subsystemA"})
+	resp, err = client.OpenBugs(context.Background())
+	c.expectOK(err)
+	c.expectEQ(len(resp.Bugs), 2)
+}
+
+func TestGuiltyFileMaintainers(t *testing.T) {
+	c := NewCtx(t)
+	defer c.Close()
+
+	client := c.client
+	build := testBuild(1)
+	client.UploadBuild(context.Background(), build)
+
+	c.transformContext = func(c context.Context) context.Context {
+		newConfig := replaceNamespaceConfig(c, subsystemTestNs, func(cfg *Config) *Config {
+			ret := *cfg
+			ret.Subsystems.GuiltyFileMaintainers = true
+			return &ret
+		})
+		return contextWithConfig(c, newConfig)
+	}
+	crash := testCrash(build, 1)
+	crash.GuiltyFiles = []string{"a.c"}
+	crash.Maintainers = []string{"manager@person.com"}
+	client.ReportCrash(context.Background(), crash)
+	rep := client.pollBug()
+	_, dbCrash, _ := c.loadBug(rep.ID)
+	assert.ElementsMatch(t, dbCrash.Maintainers,
+		[]string{"manager@person.com", "subsystemA@list.com", "subsystemA@person.com"})
+}
+
 func TestOpenBugRevRefresh(t *testing.T) {
 	c := NewCtx(t)
 	defer c.Close()
@@ -83,13 +141,13 @@ func TestOpenBugRevRefresh(t *testing.T) {
 	ns := subsystemTestNs
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug without any subsystems.
 	c.setSubsystems(ns, nil, 0)
 	crash := testCrash(build, 1)
 	crash.GuiltyFiles = []string{"test.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep := client.pollBug()
 	extID := rep.ID
 
@@ -119,18 +177,18 @@ func TestClosedBugSubsystemRefresh(t *testing.T) {
 	ns := subsystemTestNs
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug without any subsystems.
 	c.setSubsystems(ns, nil, 0)
 	crash := testCrash(build, 1)
 	crash.GuiltyFiles = []string{"test.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep := client.pollBug()
 	extID := rep.ID
 
 	// "Fix" the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         rep.ID,
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix the crash"},
@@ -139,7 +197,7 @@ func TestClosedBugSubsystemRefresh(t *testing.T) {
 	build2 := testBuild(2)
 	build2.Manager = build.Manager
 	build2.Commits = []string{"foo: fix the crash"}
-	client.UploadBuild(build2)
+	client.UploadBuild(context.Background(), build2)
 	client.pollNotifs(0)
 	bug, _, _ := c.loadBug(rep.ID)
 	c.expectEQ(bug.Status, BugStatusFixed)
@@ -168,18 +226,18 @@ func TestInvalidBugSubsystemRefresh(t *testing.T) {
 
 	client := c.client
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug without any subsystems.
 	c.setSubsystems(subsystemTestNs, nil, 0)
 	crash := testCrash(build, 1)
 	crash.GuiltyFiles = []string{"test.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	rep := client.pollBug()
 	extID := rep.ID
 
 	// Invalidate the bug.
-	reply, _ := c.client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := c.client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     rep.ID,
 		Status: dashapi.BugStatusInvalid,
 	})
@@ -213,12 +271,12 @@ func TestUserSubsystemsRefresh(t *testing.T) {
 	ns := "access-public-email"
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug with subsystemA.
 	crash := testCrash(build, 1)
 	crash.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz upstream\n")
 
 	sender := c.pollEmailBug().Sender
@@ -258,11 +316,11 @@ func TestNoUserSubsystemOverwrite(t *testing.T) {
 	client := c.makeClient(clientPublicEmail, keyPublicEmail, true)
 
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// Create a bug without subsystems.
 	crash := testCrash(build, 1)
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.incomingEmail(c.pollEmailBug().Sender, "#syz upstream\n")
 
 	sender := c.pollEmailBug().Sender
@@ -279,7 +337,7 @@ func TestNoUserSubsystemOverwrite(t *testing.T) {
 	crash.GuiltyFiles = []string{"b.c"}
 	crash.ReproOpts = []byte("some opts")
 	crash.ReproSyz = []byte("getpid()")
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	c.pollEmailBug()
 
 	// Make sure subsystem stayed unchanged.
@@ -293,7 +351,7 @@ func TestPeriodicSubsystemReminders(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	bugToExtID := map[string]string{}
 
@@ -301,10 +359,10 @@ func TestPeriodicSubsystemReminders(t *testing.T) {
 	aFirst := testCrash(build, 1)
 	aFirst.Title = `WARNING: a first`
 	aFirst.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFirst)
+	client.ReportCrash(context.Background(), aFirst)
 	bugToExtID[aFirst.Title] = client.pollEmailExtID()
 	for i := 0; i < 2; i++ {
-		client.ReportCrash(aFirst)
+		client.ReportCrash(context.Background(), aFirst)
 		c.advanceTime(time.Hour)
 	}
 
@@ -312,7 +370,7 @@ func TestPeriodicSubsystemReminders(t *testing.T) {
 	aSecond := testCrash(build, 1)
 	aSecond.Title = `WARNING: a second`
 	aSecond.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aSecond)
 	bugToExtID[aSecond.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -320,7 +378,7 @@ func TestPeriodicSubsystemReminders(t *testing.T) {
 	bFirst := testCrash(build, 1)
 	bFirst.Title = `WARNING: b first`
 	bFirst.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(bFirst)
+	client.ReportCrash(context.Background(), bFirst)
 	bugToExtID[bFirst.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -328,26 +386,26 @@ func TestPeriodicSubsystemReminders(t *testing.T) {
 	bSecond := testCrash(build, 1)
 	bSecond.Title = `WARNING: b second`
 	bSecond.GuiltyFiles = []string{"b.c"}
-	client.ReportCrash(bSecond)
+	client.ReportCrash(context.Background(), bSecond)
 	bugToExtID[bSecond.Title] = client.pollEmailExtID()
 	for i := 0; i < 4; i++ {
-		client.ReportCrash(bSecond)
+		client.ReportCrash(context.Background(), bSecond)
 		c.advanceTime(time.Hour)
 	}
 
 	// Report bugs once more to pretend they're still valid.
 	c.advanceTime(time.Hour * 24 * 14)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(bFirst)
-	client.ReportCrash(aSecond)
-	client.ReportCrash(bSecond)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), bFirst)
+	client.ReportCrash(context.Background(), aSecond)
+	client.ReportCrash(context.Background(), bSecond)
 	c.advanceTime(time.Hour)
 
 	// Make sure we don't report crashes at other reporting stages.
 	crash := testCrash(build, 1)
 	crash.Title = `WARNING: a third, keep in moderation` // see the config in app_test.go
 	crash.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollBug()
 	c.advanceTime(time.Hour)
 
@@ -454,27 +512,27 @@ func TestSubsystemRemindersModeration(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 	bugToExtID := map[string]string{}
 
 	aFirst := testCrash(build, 1)
 	aFirst.Title = `WARNING: a first`
 	aFirst.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFirst)
+	client.ReportCrash(context.Background(), aFirst)
 	bugToExtID[aFirst.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
 	aSecond := testCrash(build, 1)
 	aSecond.Title = `WARNING: a second`
 	aSecond.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aSecond)
 	bugToExtID[aSecond.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
 	// Report them again.
 	c.advanceTime(time.Hour * 24 * 14)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), aSecond)
 
 	_, err := c.GET("/cron/subsystem_reports")
 	c.expectOK(err)
@@ -534,14 +592,14 @@ func TestSubsystemReportGeneration(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 	bugToExtID := map[string]string{}
 
 	// This crash will be too old.
 	crash := testCrash(build, 1)
 	crash.Title = `WARNING: old crash`
 	crash.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(crash)
+	client.ReportCrash(context.Background(), crash)
 	client.pollEmailBug()
 	c.advanceTime(time.Hour * 24 * 40)
 
@@ -549,16 +607,16 @@ func TestSubsystemReportGeneration(t *testing.T) {
 	aFixed := testCrash(build, 1)
 	aFixed.Title = `WARNING: fixed bug`
 	aFixed.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFixed)
+	client.ReportCrash(context.Background(), aFixed)
 	bugToExtID[aFixed.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
-	updReply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	updReply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:         bugToExtID[aFixed.Title],
 		Status:     dashapi.BugStatusOpen,
 		FixCommits: []string{"foo: fix1"},
 	})
 	c.expectEQ(updReply.OK, true)
-	c.expectOK(client.UploadCommits([]dashapi.Commit{
+	c.expectOK(client.UploadCommits(context.Background(), []dashapi.Commit{
 		{Hash: "hash1", Title: "foo: fix1", Date: timeNow(c.ctx)},
 	}))
 
@@ -570,18 +628,18 @@ func TestSubsystemReportGeneration(t *testing.T) {
 		crash := testCrash(build, 1)
 		crash.Title = fmt.Sprintf(`WARNING: has repro %d`, i+1)
 		crash.GuiltyFiles = []string{"a.c"}
-		client.ReportCrash(crash)
+		client.ReportCrash(context.Background(), crash)
 		bugToExtID[crash.Title] = client.pollEmailExtID()
 		c.advanceTime(time.Hour)
 
 		crash.ReproOpts = []byte("some opts")
 		crash.ReproSyz = []byte("getpid()")
-		client.ReportCrash(crash)
+		client.ReportCrash(context.Background(), crash)
 		client.pollEmailBug()
 		c.advanceTime(time.Hour)
 
 		for j := 3; j <= i; j++ {
-			client.ReportCrash(crash)
+			client.ReportCrash(context.Background(), crash)
 			c.advanceTime(time.Hour)
 		}
 		allCrashes = append(allCrashes, crash)
@@ -593,12 +651,12 @@ func TestSubsystemReportGeneration(t *testing.T) {
 		crash := testCrash(build, 1)
 		crash.Title = fmt.Sprintf(`WARNING: no repro %d`, i+1)
 		crash.GuiltyFiles = []string{"a.c"}
-		client.ReportCrash(crash)
+		client.ReportCrash(context.Background(), crash)
 		bugToExtID[crash.Title] = client.pollEmailExtID()
 		c.advanceTime(time.Hour)
 
 		for j := 2; j <= i; j++ {
-			client.ReportCrash(crash)
+			client.ReportCrash(context.Background(), crash)
 			c.advanceTime(time.Hour)
 		}
 		allCrashes = append(allCrashes, crash)
@@ -606,7 +664,7 @@ func TestSubsystemReportGeneration(t *testing.T) {
 
 	c.advanceTime(time.Hour * 24 * 14)
 	for _, crash := range allCrashes {
-		client.ReportCrash(crash)
+		client.ReportCrash(context.Background(), crash)
 		c.advanceTime(time.Hour)
 	}
 
@@ -673,7 +731,7 @@ You may send multiple commands in a single email message.
 	))
 
 	// Add one more crash and regenerate.
-	client.ReportCrash(biggestReproCrash)
+	client.ReportCrash(context.Background(), biggestReproCrash)
 	c.advanceTime(time.Hour)
 
 	c.incomingEmail(reply.Sender, "#syz regenerate\n")
@@ -694,26 +752,26 @@ func TestSubsystemRemindersNoReport(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	cFirst := testCrash(build, 1)
 	cFirst.Title = `WARNING: c first`
 	cFirst.GuiltyFiles = []string{"c.c"}
-	client.ReportCrash(cFirst)
+	client.ReportCrash(context.Background(), cFirst)
 	client.pollEmailBug()
 	c.advanceTime(time.Hour)
 
 	cSecond := testCrash(build, 1)
 	cSecond.Title = `WARNING: c second`
 	cSecond.GuiltyFiles = []string{"c.c"}
-	client.ReportCrash(cSecond)
+	client.ReportCrash(context.Background(), cSecond)
 	client.pollEmailBug()
 	c.advanceTime(time.Hour)
 
 	// Report them again.
 	c.advanceTime(time.Hour * 24 * 14)
-	client.ReportCrash(cFirst)
-	client.ReportCrash(cSecond)
+	client.ReportCrash(context.Background(), cFirst)
+	client.ReportCrash(context.Background(), cSecond)
 
 	_, err := c.GET("/cron/subsystem_reports")
 	c.expectOK(err)
@@ -729,7 +787,7 @@ func TestNoRemindersWithDiscussions(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	bugToExtID := map[string]string{}
 
@@ -737,7 +795,7 @@ func TestNoRemindersWithDiscussions(t *testing.T) {
 	aFirst := testCrash(build, 1)
 	aFirst.Title = `WARNING: a first`
 	aFirst.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFirst)
+	client.ReportCrash(context.Background(), aFirst)
 	bugToExtID[aFirst.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -745,7 +803,7 @@ func TestNoRemindersWithDiscussions(t *testing.T) {
 	aSecond := testCrash(build, 1)
 	aSecond.Title = `WARNING: a second`
 	aSecond.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aSecond)
 	bugToExtID[aSecond.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -753,18 +811,18 @@ func TestNoRemindersWithDiscussions(t *testing.T) {
 	aThird := testCrash(build, 1)
 	aThird.Title = `WARNING: a third`
 	aThird.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aThird)
+	client.ReportCrash(context.Background(), aThird)
 	bugToExtID[aThird.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
 	// Report bugs once more to pretend they're still valid.
 	c.advanceTime(time.Hour * 24 * 10)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(aSecond)
-	client.ReportCrash(aThird)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), aSecond)
+	client.ReportCrash(context.Background(), aThird)
 
 	// Add a recent discussion to the second bug.
-	c.expectOK(client.SaveDiscussion(&dashapi.SaveDiscussionReq{
+	c.expectOK(client.SaveDiscussion(context.Background(), &dashapi.SaveDiscussionReq{
 		Discussion: &dashapi.Discussion{
 			ID:      "123",
 			Source:  dashapi.DiscussionLore,
@@ -834,7 +892,7 @@ func TestSkipSubsystemReminders(t *testing.T) {
 
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	bugToExtID := map[string]string{}
 
@@ -842,7 +900,7 @@ func TestSkipSubsystemReminders(t *testing.T) {
 	aFirst := testCrash(build, 1)
 	aFirst.Title = `WARNING: a first`
 	aFirst.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFirst)
+	client.ReportCrash(context.Background(), aFirst)
 	bugToExtID[aFirst.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -850,7 +908,7 @@ func TestSkipSubsystemReminders(t *testing.T) {
 	aSecond := testCrash(build, 1)
 	aSecond.Title = `WARNING: a second`
 	aSecond.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aSecond)
 	bugToExtID[aSecond.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -858,7 +916,7 @@ func TestSkipSubsystemReminders(t *testing.T) {
 	aThird := testCrash(build, 1)
 	aThird.Title = `WARNING: a third`
 	aThird.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aThird)
+	client.ReportCrash(context.Background(), aThird)
 	bugToExtID[aThird.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -866,16 +924,16 @@ func TestSkipSubsystemReminders(t *testing.T) {
 	aFourth := testCrash(build, 1)
 	aFourth.Title = `WARNING: a fourth`
 	aFourth.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aFourth)
+	client.ReportCrash(context.Background(), aFourth)
 	bugToExtID[aFourth.Title] = client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
 	// Report bugs once more to pretend they're still valid.
 	c.advanceTime(time.Hour * 24 * 14)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(aSecond)
-	client.ReportCrash(aThird)
-	client.ReportCrash(aFourth)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), aSecond)
+	client.ReportCrash(context.Background(), aThird)
+	client.ReportCrash(context.Background(), aFourth)
 	c.advanceTime(time.Hour)
 
 	_, err := c.GET("/cron/subsystem_reports")
@@ -949,10 +1007,10 @@ You may send multiple commands in a single email message.
 
 	// Prepare for the next monthly report.
 	c.advanceTime(time.Hour * 24 * 31)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(aSecond)
-	client.ReportCrash(aThird)
-	client.ReportCrash(aFourth)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), aSecond)
+	client.ReportCrash(context.Background(), aThird)
+	client.ReportCrash(context.Background(), aFourth)
 	c.advanceTime(time.Hour)
 
 	_, err = c.GET("/cron/subsystem_reports")
@@ -1007,7 +1065,7 @@ func TestRemindersPriority(t *testing.T) {
 	client := c.makeClient(clientSubsystemRemind, keySubsystemRemind, true)
 	cc := EmailOptCC([]string{"bugs@syzkaller.com", "default@maintainers.com"})
 	build := testBuild(1)
-	client.UploadBuild(build)
+	client.UploadBuild(context.Background(), build)
 
 	// WARNING: a first, low prio, has repro
 	aFirst := testCrash(build, 1)
@@ -1015,7 +1073,7 @@ func TestRemindersPriority(t *testing.T) {
 	aFirst.GuiltyFiles = []string{"a.c"}
 	aFirst.ReproOpts = []byte("some opts")
 	aFirst.ReproSyz = []byte("getpid()")
-	client.ReportCrash(aFirst)
+	client.ReportCrash(context.Background(), aFirst)
 	sender, firstExtID := client.pollEmailAndExtID()
 	c.incomingEmail(sender, "#syz set prio: low\n",
 		EmailOptFrom("test@requester.com"), cc)
@@ -1025,7 +1083,7 @@ func TestRemindersPriority(t *testing.T) {
 	aSecond := testCrash(build, 1)
 	aSecond.Title = `WARNING: a second`
 	aSecond.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aSecond)
+	client.ReportCrash(context.Background(), aSecond)
 	secondExtID := client.pollEmailExtID()
 	c.advanceTime(time.Hour)
 
@@ -1033,7 +1091,7 @@ func TestRemindersPriority(t *testing.T) {
 	aThird := testCrash(build, 1)
 	aThird.Title = `WARNING: a third`
 	aThird.GuiltyFiles = []string{"a.c"}
-	client.ReportCrash(aThird)
+	client.ReportCrash(context.Background(), aThird)
 	sender, thirdExtID := client.pollEmailAndExtID()
 	c.incomingEmail(sender, "#syz set prio: high\n",
 		EmailOptFrom("test@requester.com"), cc)
@@ -1041,9 +1099,9 @@ func TestRemindersPriority(t *testing.T) {
 
 	// Report bugs once more to pretend they're still valid.
 	c.advanceTime(time.Hour * 24 * 10)
-	client.ReportCrash(aFirst)
-	client.ReportCrash(aSecond)
-	client.ReportCrash(aThird)
+	client.ReportCrash(context.Background(), aFirst)
+	client.ReportCrash(context.Background(), aSecond)
+	client.ReportCrash(context.Background(), aThird)
 
 	_, err := c.GET("/cron/subsystem_reports")
 	c.expectOK(err)
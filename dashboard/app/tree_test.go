@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -255,7 +256,7 @@ func TestTreeOriginLtsBisection(t *testing.T) {
 		Log:   []byte("bisect log"),
 		Error: []byte("bisect error"),
 	}
-	c.expectOK(ctx.client.JobDone(done))
+	c.expectOK(ctx.client.JobDone(context.Background(), done))
 	ctx.ctx.advanceTime(time.Hour)
 
 	// Ensure there are no new bisection requests.
@@ -294,7 +295,7 @@ func TestTreeOriginLtsBisection(t *testing.T) {
 	}
 	done.Build.ID = job.ID
 	ctx.ctx.advanceTime(time.Hour)
-	c.expectOK(ctx.client.JobDone(done))
+	c.expectOK(ctx.client.JobDone(context.Background(), done))
 
 	// Ensure the job is no longer created.
 	ctx.ctx.advanceTime(time.Hour)
@@ -355,7 +356,7 @@ For information about bisection process see: %URL%#bisection
 	upstreamBuild := testBuild(100)
 	upstreamBuild.KernelRepo = "https://upstream.repo/repo"
 	upstreamBuild.KernelBranch = "upstream-master"
-	ctx.ctx.publicClient.UploadBuild(upstreamBuild)
+	ctx.ctx.publicClient.UploadBuild(context.Background(), upstreamBuild)
 	reply, err = ctx.ctx.AuthGET(AccessAdmin, "/access-public-email/backports")
 	c.expectOK(err)
 	assert.Contains(t, string(reply), treeTestCrashTitle)
@@ -366,12 +367,12 @@ For information about bisection process see: %URL%#bisection
 	assert.NotContains(t, string(reply), treeTestCrashTitle)
 
 	// The bug must appear in commit poll.
-	commitPollResp, err := ctx.client.CommitPoll()
+	commitPollResp, err := ctx.client.CommitPoll(context.Background())
 	c.expectOK(err)
 	assert.Contains(t, commitPollResp.Commits, "kernel: fix a bug")
 
 	// Pretend that we have found a commit.
-	c.expectOK(ctx.client.UploadCommits([]dashapi.Commit{
+	c.expectOK(ctx.client.UploadCommits(context.Background(), []dashapi.Commit{
 		{
 			Hash:       "newhash",
 			Title:      "kernel: fix a bug",
@@ -1042,7 +1043,7 @@ func (ctx *treeTestCtx) uploadBuild(repo, branch string) *dashapi.Build {
 	build.KernelRepo = repo
 	build.KernelBranch = branch
 	build.KernelCommit = build.ID
-	ctx.client.UploadBuild(build)
+	ctx.client.UploadBuild(context.Background(), build)
 	return build
 }
 
@@ -1057,7 +1058,7 @@ func (ctx *treeTestCtx) uploadBuildCrash(build *dashapi.Build, lvl dashapi.Repro
 	if lvl == dashapi.ReproLevelC {
 		crash.ReproC = []byte("getpid()")
 	}
-	ctx.client.ReportCrash(crash)
+	ctx.client.ReportCrash(context.Background(), crash)
 	if ctx.bug == nil || ctx.bug.ReproLevel < lvl {
 		ctx.bugReport = ctx.client.pollBug()
 		if ctx.bug == nil {
@@ -1172,7 +1173,7 @@ func (ctx *treeTestCtx) doJob(resp *dashapi.JobPollResp, day int) {
 		jobDoneReq.Error = []byte("failed to apply patch")
 	}
 	found.jobsDone++
-	ctx.ctx.expectOK(ctx.client.JobDone(jobDoneReq))
+	ctx.ctx.expectOK(ctx.client.JobDone(context.Background(), jobDoneReq))
 }
 
 func (ctx *treeTestCtx) ensureLabels(labels ...string) {
@@ -1206,7 +1207,7 @@ func (ctx *treeTestCtx) reportToEmail() *aemail.Message {
 }
 
 func (ctx *treeTestCtx) fullBugInfo() *dashapi.FullBugInfo {
-	info, err := ctx.client.LoadFullBug(&dashapi.LoadFullBugReq{
+	info, err := ctx.client.LoadFullBug(context.Background(), &dashapi.LoadFullBugReq{
 		BugID: ctx.bugReport.ID,
 	})
 	ctx.ctx.expectOK(err)
@@ -0,0 +1,166 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"google.golang.org/appengine/v2"
+	db "google.golang.org/appengine/v2/datastore"
+	"google.golang.org/appengine/v2/log"
+)
+
+// This file implements chunked uploads of large crash logs and reports (see dashapi.ChunkedUpload).
+// Uploads are content-addressed, so they are not deleted after use (other managers may
+// upload the same payload concurrently), instead they are garbage collected after uploadExpiration.
+
+const (
+	maxUploadSize = 64 << 20
+	// Datastore entity limit is 1MB.
+	maxUploadChunk   = 1000 << 10
+	uploadExpiration = 24 * time.Hour
+)
+
+func apiUploadChunk(c context.Context, ns string, r *http.Request, payload []byte) (interface{}, error) {
+	req := new(dashapi.UploadChunkReq)
+	if err := unmarshalPayload(r, payload, req); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal request: %w", ErrClientBadRequest, err)
+	}
+	if req.UploadID == "" || req.Size <= 0 || req.Size > maxUploadSize {
+		return nil, fmt.Errorf("%w: bad upload %q of size %v", ErrClientBadRequest, req.UploadID, req.Size)
+	}
+	if len(req.Data) > maxUploadChunk {
+		return nil, fmt.Errorf("%w: too large chunk: %v, max %v", ErrClientTooLarge, len(req.Data), maxUploadChunk)
+	}
+	resp := new(dashapi.UploadChunkResp)
+	key := uploadKey(c, ns, req.UploadID)
+	tx := func(c context.Context) error {
+		upload := new(Upload)
+		if err := db.Get(c, key, upload); err != nil {
+			if !errors.Is(err, db.ErrNoSuchEntity) {
+				return fmt.Errorf("failed to get upload: %w", err)
+			}
+			upload = &Upload{
+				Namespace: ns,
+				Size:      req.Size,
+				Created:   timeNow(c),
+			}
+		}
+		if upload.Size != req.Size {
+			return fmt.Errorf("%w: upload %v has size %v, got %v",
+				ErrClientBadRequest, req.UploadID, upload.Size, req.Size)
+		}
+		resp.Received = upload.Received
+		if len(req.Data) == 0 || req.Offset != upload.Received {
+			// Either a state query, or a retry of a chunk that was already stored.
+			return nil
+		}
+		if req.Offset+int64(len(req.Data)) > upload.Size {
+			return fmt.Errorf("%w: chunk is out of upload bounds", ErrClientBadRequest)
+		}
+		upload.Chunks++
+		chunkKey := db.NewKey(c, "UploadChunk", "", upload.Chunks, key)
+		if _, err := db.Put(c, chunkKey, &UploadChunk{Data: req.Data}); err != nil {
+			return fmt.Errorf("failed to put upload chunk: %w", err)
+		}
+		upload.Received += int64(len(req.Data))
+		if _, err := db.Put(c, key, upload); err != nil {
+			return fmt.Errorf("failed to put upload: %w", err)
+		}
+		resp.Received = upload.Received
+		return nil
+	}
+	if err := db.RunInTransaction(c, tx, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// resolveChunkRefs fills in the crash fields that were uploaded in chunks.
+func resolveChunkRefs(c context.Context, ns string, crash *dashapi.Crash) error {
+	for _, ref := range crash.ChunkRefs {
+		var field *[]byte
+		switch ref.Field {
+		case "Log":
+			field = &crash.Log
+		case "Report":
+			field = &crash.Report
+		default:
+			return fmt.Errorf("%w: upload for unknown field %q", ErrClientBadRequest, ref.Field)
+		}
+		data, err := loadUpload(c, ns, ref.UploadID)
+		if err != nil {
+			return err
+		}
+		*field = data
+	}
+	crash.ChunkRefs = nil
+	return nil
+}
+
+func loadUpload(c context.Context, ns, id string) ([]byte, error) {
+	key := uploadKey(c, ns, id)
+	upload := new(Upload)
+	if err := db.Get(c, key, upload); err != nil {
+		if errors.Is(err, db.ErrNoSuchEntity) {
+			return nil, fmt.Errorf("%w: unknown upload %v", ErrClientBadRequest, id)
+		}
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	if upload.Received != upload.Size {
+		return nil, fmt.Errorf("%w: upload %v is incomplete: %v of %v",
+			ErrClientBadRequest, id, upload.Received, upload.Size)
+	}
+	var chunks []*UploadChunk
+	if _, err := db.NewQuery("UploadChunk").Ancestor(key).Order("__key__").GetAll(c, &chunks); err != nil {
+		return nil, fmt.Errorf("failed to query upload chunks: %w", err)
+	}
+	data := make([]byte, 0, upload.Size)
+	for _, chunk := range chunks {
+		data = append(data, chunk.Data...)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != id {
+		return nil, fmt.Errorf("upload %v has wrong checksum", id)
+	}
+	return data, nil
+}
+
+func uploadKey(c context.Context, ns, id string) *db.Key {
+	return db.NewKey(c, "Upload", ns+"|"+id, 0, nil)
+}
+
+func handleCleanUploads(w http.ResponseWriter, r *http.Request) {
+	c := appengine.NewContext(r)
+	if err := cleanUploads(c); err != nil {
+		log.Errorf(c, "failed to clean uploads: %v", err)
+	}
+}
+
+func cleanUploads(c context.Context) error {
+	keys, err := db.NewQuery("Upload").
+		Filter("Created<", timeNow(c).Add(-uploadExpiration)).
+		KeysOnly().
+		GetAll(c, nil)
+	if err != nil {
+		return fmt.Errorf("failed to query uploads: %w", err)
+	}
+	for _, key := range keys {
+		chunkKeys, err := db.NewQuery("UploadChunk").Ancestor(key).KeysOnly().GetAll(c, nil)
+		if err != nil {
+			return fmt.Errorf("failed to query upload chunks: %w", err)
+		}
+		if err := db.DeleteMulti(c, append(chunkKeys, key)); err != nil {
+			return fmt.Errorf("failed to delete upload: %w", err)
+		}
+	}
+	return nil
+}
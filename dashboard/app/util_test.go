@@ -197,7 +197,7 @@ func (c *Ctx) Close() {
 			c.t.Errorf("ERROR: leftover email: %v", (<-c.emailSink).Body)
 		}
 		// No pending external reports (tests need to consume them).
-		resp, _ := c.client.ReportingPollBugs("test")
+		resp, _ := c.client.ReportingPollBugs(context.Background(), "test")
 		for _, rep := range resp.Reports {
 			c.t.Errorf("ERROR: leftover external report:\n%#v", rep)
 		}
@@ -495,19 +495,33 @@ type apiClient struct {
 	*dashapi.Dashboard
 }
 
-func (c *Ctx) makeClient(client, key string, failOnErrors bool) *apiClient {
-	doer := func(r *http.Request) (*http.Response, error) {
-		r = registerRequest(r, c)
-		r = r.WithContext(c.transformContext(r.Context()))
-		w := httptest.NewRecorder()
-		http.DefaultServeMux.ServeHTTP(w, r)
-		res := &http.Response{
-			StatusCode: w.Code,
-			Status:     http.StatusText(w.Code),
-			Body:       io.NopCloser(w.Result().Body),
-		}
-		return res, nil
+// testTransport serves dashboard client requests in-process.
+type testTransport struct {
+	c *Ctx
+}
+
+// The aetest requests carry their own contexts, so the client context is not used.
+func (t *testTransport) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return t.c.inst.NewRequest(method, url, body)
+}
+
+func (t *testTransport) Do(r *http.Request) (*http.Response, error) {
+	c := t.c
+	r = registerRequest(r, c)
+	r = r.WithContext(c.transformContext(r.Context()))
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, r)
+	res := &http.Response{
+		StatusCode: w.Code,
+		Status:     http.StatusText(w.Code),
+		Header:     w.Result().Header,
+		Body:       io.NopCloser(w.Result().Body),
 	}
+	return res, nil
+}
+
+func (c *Ctx) makeClient(client, key string, failOnErrors bool) *apiClient {
+	transport := &testTransport{c}
 	logger := func(msg string, args ...interface{}) {
 		c.t.Logf("%v: "+msg, append([]interface{}{caller(3)}, args...)...)
 	}
@@ -516,7 +530,8 @@ func (c *Ctx) makeClient(client, key string, failOnErrors bool) *apiClient {
 			c.t.Fatalf("\n%v: %v", caller(2), err)
 		}
 	}
-	dash, err := dashapi.NewCustom(client, "", key, c.inst.NewRequest, doer, logger, errorHandler)
+	dash, err := dashapi.New(client, "", key, transport, dashapi.RequestLogger(logger),
+		dashapi.ErrorHandler(errorHandler), dashapi.RetryPolicy{}, dashapi.RateLimits{}, dashapi.RequestTimeout(0))
 	if err != nil {
 		panic(fmt.Sprintf("Impossible error: %v", err))
 	}
@@ -527,7 +542,7 @@ func (c *Ctx) makeClient(client, key string, failOnErrors bool) *apiClient {
 }
 
 func (client *apiClient) pollBugs(expect int) []*dashapi.BugReport {
-	resp, _ := client.ReportingPollBugs("test")
+	resp, _ := client.ReportingPollBugs(context.Background(), "test")
 	if len(resp.Reports) != expect {
 		client.t.Helper()
 		client.t.Fatalf("want %v reports, got %v", expect, len(resp.Reports))
@@ -539,7 +554,7 @@ func (client *apiClient) pollBugs(expect int) []*dashapi.BugReport {
 		} else if len(rep.ReproSyz) != 0 {
 			reproLevel = dashapi.ReproLevelSyz
 		}
-		reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+		reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 			ID:         rep.ID,
 			JobID:      rep.JobID,
 			Status:     dashapi.BugStatusOpen,
@@ -557,7 +572,7 @@ func (client *apiClient) pollBug() *dashapi.BugReport {
 }
 
 func (client *apiClient) pollNotifs(expect int) []*dashapi.BugNotification {
-	resp, _ := client.ReportingPollNotifications("test")
+	resp, _ := client.ReportingPollNotifications(context.Background(), "test")
 	if len(resp.Notifications) != expect {
 		client.t.Helper()
 		client.t.Fatalf("want %v notifs, got %v", expect, len(resp.Notifications))
@@ -566,7 +581,7 @@ func (client *apiClient) pollNotifs(expect int) []*dashapi.BugNotification {
 }
 
 func (client *apiClient) updateBug(extID string, status dashapi.BugStatus, dup string) {
-	reply, _ := client.ReportingUpdate(&dashapi.BugUpdate{
+	reply, _ := client.ReportingUpdate(context.Background(), &dashapi.BugUpdate{
 		ID:     extID,
 		Status: status,
 		DupOf:  dup,
@@ -580,7 +595,7 @@ func (client *apiClient) pollSpecificJobs(manager string, jobs dashapi.ManagerJo
 			manager: jobs,
 		},
 	}
-	resp, err := client.JobPoll(req)
+	resp, err := client.JobPoll(context.Background(), req)
 	client.expectOK(err)
 	return resp
 }
@@ -601,7 +616,7 @@ func (client *apiClient) pollAndFailBisectJob(manager string) {
 		ID:    resp.ID,
 		Error: []byte("pollAndFailBisectJob"),
 	}
-	client.expectOK(client.JobDone(done))
+	client.expectOK(client.JobDone(context.Background(), done))
 }
 
 type (
@@ -664,6 +679,10 @@ func initMocks() {
 		getRequestContext(c).emailSink <- msg
 		return nil
 	}
+	signAssetUploadURL = func(c context.Context, bucket, object, contentType string, expires time.Time) (
+		string, error) {
+		return fmt.Sprintf("https://upload.test/%v/%v?expires=%v", bucket, object, expires.Unix()), nil
+	}
 	maxCrashes = func() int {
 		// dev_appserver is very slow, so let's make tests smaller.
 		const maxCrashesDuringTest = 20
@@ -0,0 +1,76 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Large assets (disk images, kernel objects, images mounted in repros) can be uploaded to
+// the external storage of the dashboard: the dashboard returns signed upload URLs (AssetUploadURLs),
+// the client uploads the files there (UploadAsset) and then refers to them by DownloadURL
+// in Crash.Assets, Build.Assets or AddBuildAssets, as for assets the client stores itself.
+// The dashboard keeps only the references.
+
+type AssetUploadReq struct {
+	Assets []*AssetUpload
+}
+
+type AssetUpload struct {
+	Type AssetType
+	// Name is the file name, it becomes the last element of the download URL.
+	Name        string
+	ContentType string // optional
+}
+
+type AssetUploadResp struct {
+	URLs []*AssetUploadURL // in the same order as AssetUploadReq.Assets
+}
+
+type AssetUploadURL struct {
+	UploadURL   string
+	Headers     map[string]string // headers the upload request must have
+	Expires     time.Time
+	DownloadURL string // see NewAsset
+}
+
+// AssetUploadURLs returns signed URLs to upload the assets to.
+// It fails with ErrNotFound if the dashboard has no storage for assets configured.
+func (dash *Dashboard) AssetUploadURLs(ctx context.Context, req *AssetUploadReq) (*AssetUploadResp, error) {
+	resp := new(AssetUploadResp)
+	err := dash.Query(ctx, "asset_upload_urls", req, resp)
+	return resp, err
+}
+
+// UploadAsset uploads the asset contents to the URL returned by AssetUploadURLs.
+// The upload is not sent through the interceptors, retries and rate limits of API requests
+// and does not carry the client credentials, the URL signature authorizes it.
+func (dash *Dashboard) UploadAsset(ctx context.Context, url *AssetUploadURL, size int64, data io.Reader) error {
+	transport := dash.transport
+	if t, ok := transport.(*authTransport); ok {
+		transport = t.Transport
+	}
+	r, err := transport.NewRequest(ctx, "PUT", url.UploadURL, data)
+	if err != nil {
+		return err
+	}
+	r.ContentLength = size
+	for key, val := range url.Headers {
+		r.Header.Set(key, val)
+	}
+	resp, err := transport.Do(r)
+	if err != nil {
+		return fmt.Errorf("asset upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("asset upload failed with %v: %s", resp.Status, msg)
+	}
+	return nil
+}
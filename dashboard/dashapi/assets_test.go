@@ -0,0 +1,46 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadAsset(t *testing.T) {
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.Header.Get("Authorization") != "" ||
+			r.Header.Get("Content-Type") != "application/gzip" {
+			http.Error(w, "bad request", http.StatusForbidden)
+			return
+		}
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	dash, err := New("client", "http://dashboard", "", OAuth2(&countingTokenSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := &AssetUploadURL{
+		UploadURL: srv.URL + "/bucket/disk.raw.gz",
+		Headers:   map[string]string{"Content-Type": "application/gzip"},
+	}
+	data := []byte("disk image")
+	if err := dash.UploadAsset(context.Background(), url, int64(len(data)), bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(uploaded, data) {
+		t.Fatalf("uploaded %q, want %q", uploaded, data)
+	}
+	url.Headers = nil
+	if err := dash.UploadAsset(context.Background(), url, int64(len(data)), bytes.NewReader(data)); err == nil {
+		t.Fatalf("a failed upload succeeded")
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+// CrashAttachment is an additional named file of the crash besides Crash.Log and Crash.Report,
+// e.g. a netconsole or QEMU log, the executor log or the console output of other VMs of a multi-VM crash.
+type CrashAttachment struct {
+	Name     string // unique within the crash, e.g. "netconsole.log"
+	MIMEType string // empty means text/plain
+	Data     []byte
+}
+
+// CrashAttachmentLink refers to a CrashAttachment stored on the dashboard.
+type CrashAttachmentLink struct {
+	Name     string
+	MIMEType string
+	Link     string
+}
+
+const (
+	MaxCrashAttachments = 8
+	DefaultMIMEType     = "text/plain"
+)
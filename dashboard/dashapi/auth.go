@@ -0,0 +1,65 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/syzkaller/pkg/auth"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/option"
+)
+
+// AuthProvider attaches credentials to dashboard requests as an alternative to the static key.
+// It can be passed to New, in which case the key should be empty.
+type AuthProvider interface {
+	// Authorization returns the value of the Authorization header for a request.
+	// It's called before each request and is responsible for caching and refreshing the credentials.
+	Authorization(ctx context.Context) (string, error)
+}
+
+// OAuth2 returns an AuthProvider that attaches bearer tokens from the token source.
+// The tokens are reused until they expire.
+func OAuth2(ts oauth2.TokenSource) AuthProvider {
+	return &oauth2Auth{oauth2.ReuseTokenSource(nil, ts)}
+}
+
+// ServiceAccount returns an AuthProvider that authenticates as a GCP service account.
+// credentialsFile is a JSON service account key, if it's empty, application default
+// credentials are used. The tokens are ID tokens for the dashboard audience.
+func ServiceAccount(ctx context.Context, credentialsFile string) (AuthProvider, error) {
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+	ts, err := idtoken.NewTokenSource(ctx, auth.DashboardAudience, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account token source: %w", err)
+	}
+	return OAuth2(ts), nil
+}
+
+type oauth2Auth struct {
+	ts oauth2.TokenSource
+}
+
+func (a *oauth2Auth) Authorization(ctx context.Context) (string, error) {
+	token, err := a.ts.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get oauth2 token: %w", err)
+	}
+	return "Bearer " + token.AccessToken, nil
+}
+
+// metadataAuth uses the ambient GCE service account, see New.
+type metadataAuth struct {
+	cache *auth.TokenCache
+}
+
+func (a *metadataAuth) Authorization(ctx context.Context) (string, error) {
+	return a.cache.Get(time.Now())
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	tokens int
+}
+
+func (ts *countingTokenSource) Token() (*oauth2.Token, error) {
+	ts.tokens++
+	return &oauth2.Token{
+		AccessToken: fmt.Sprintf("token%v", ts.tokens),
+		Expiry:      time.Now().Add(time.Hour),
+	}, nil
+}
+
+func TestOAuth2(t *testing.T) {
+	var headers []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("Authorization"))
+		if r.FormValue("key") != "" {
+			t.Errorf("the key was sent with the request")
+		}
+	}))
+	defer srv.Close()
+	ts := new(countingTokenSource)
+	dash, err := New("client", srv.URL, "", OAuth2(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ts.tokens != 1 {
+		t.Fatalf("the token was fetched %v times, want 1", ts.tokens)
+	}
+	if len(headers) != 2 || headers[0] != "Bearer token1" || headers[1] != "Bearer token1" {
+		t.Fatalf("bad authorization headers %q", headers)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// PreferURLs asks the dashboard to return large blobs (logs, reproducers, kernel configs)
+// as short-lived download URLs instead of inline bytes. The blobs are then downloaded
+// and verified by the client, so the replies look the same to the callers.
+// It can be passed to New (applies to all methods that support it), to GetRepro and to LoadBug.
+type PreferURLs bool
+
+// BlobRef refers to a blob that the dashboard returned as a download URL.
+type BlobRef struct {
+	Field  string // name of the []byte field of the reply that the blob belongs to
+	URL    string // either absolute, or relative to the dashboard address
+	Size   int64
+	SHA256 string // hex-encoded
+}
+
+var errBlobExpired = errors.New("blob download URL has expired")
+
+// queryBlobs is similar to Query, but it also downloads blobs that the dashboard returned as URLs.
+// If the URLs have expired by the time we fetch them, the method is re-requested once.
+func (dash *Dashboard) queryBlobs(ctx context.Context, method string, req, reply interface{}) error {
+	for retry := false; ; retry = true {
+		reflect.ValueOf(reply).Elem().SetZero()
+		if err := dash.Query(ctx, method, req, reply); err != nil {
+			return err
+		}
+		err := dash.fetchBlobs(ctx, reply)
+		if err != errBlobExpired || retry {
+			return err
+		}
+	}
+}
+
+func (dash *Dashboard) fetchBlobs(ctx context.Context, reply interface{}) error {
+	v := reflect.ValueOf(reply).Elem()
+	refs := v.FieldByName("BlobRefs")
+	if !refs.IsValid() {
+		return nil
+	}
+	for _, ref := range refs.Interface().([]BlobRef) {
+		field := v.FieldByName(ref.Field)
+		if !field.IsValid() || field.Type() != reflect.TypeOf([]byte(nil)) {
+			return fmt.Errorf("blob for unknown field %q", ref.Field)
+		}
+		data, err := dash.fetchBlob(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if dash.payloadKeys != nil {
+			if data, err = dash.payloadKeys.Decrypt(data); err != nil {
+				return err
+			}
+		}
+		field.SetBytes(data)
+	}
+	refs.SetZero()
+	return nil
+}
+
+func (dash *Dashboard) fetchBlob(ctx context.Context, ref BlobRef) ([]byte, error) {
+	url := ref.URL
+	if strings.HasPrefix(url, "/") {
+		url = dash.baseURL + url
+	}
+	if dash.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dash.timeout)
+		defer cancel()
+	}
+	r, err := dash.transport.NewRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dash.transport.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("blob %v download failed: %w", ref.Field, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone, http.StatusForbidden:
+		return nil, errBlobExpired
+	default:
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("blob %v download failed: %v: %s", ref.Field, resp.Status, data)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ref.Size+1))
+	if err != nil {
+		return nil, fmt.Errorf("blob %v download failed: %w", ref.Field, err)
+	}
+	if int64(len(data)) != ref.Size {
+		return nil, fmt.Errorf("blob %v has wrong size: got %v, want %v", ref.Field, len(data), ref.Size)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != ref.SHA256 {
+		return nil, fmt.Errorf("blob %v has wrong checksum", ref.Field)
+	}
+	return data, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeBlobServer struct {
+	t       *testing.T
+	srv     *httptest.Server
+	blobs   map[string][]byte
+	expired int // the number of downloads to reject with 410
+	corrupt bool
+	queries int
+}
+
+func newFakeBlobServer(t *testing.T) *fakeBlobServer {
+	fake := &fakeBlobServer{
+		t: t,
+		blobs: map[string][]byte{
+			"Syz":          []byte("syz repro"),
+			"C":            []byte("C repro"),
+			"KernelConfig": []byte("CONFIG_KASAN=y"),
+		},
+	}
+	fake.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fake.expired != 0 {
+			fake.expired--
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		data := fake.blobs[strings.TrimPrefix(r.URL.Path, "/")]
+		if fake.corrupt {
+			data = []byte(strings.ToUpper(string(data)))
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(fake.srv.Close)
+	return fake
+}
+
+func (fake *fakeBlobServer) handle(method string, payload []byte) (interface{}, error) {
+	if method != "get_repro" {
+		return nil, fmt.Errorf("unknown method %v", method)
+	}
+	fake.queries++
+	req := new(GetReproReq)
+	if err := json.Unmarshal(payload, req); err != nil {
+		fake.t.Fatal(err)
+	}
+	resp := &Repro{
+		Level: ReproLevelC,
+		Opts:  []byte("opts"),
+	}
+	if !req.PreferURLs {
+		resp.Syz = fake.blobs["Syz"]
+		resp.C = fake.blobs["C"]
+		resp.KernelConfig = fake.blobs["KernelConfig"]
+		return resp, nil
+	}
+	for _, field := range []string{"Syz", "C", "KernelConfig"} {
+		data := fake.blobs[field]
+		sum := sha256.Sum256(data)
+		resp.BlobRefs = append(resp.BlobRefs, BlobRef{
+			Field:  field,
+			URL:    fake.srv.URL + "/" + field,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	return resp, nil
+}
+
+func TestBlobURLs(t *testing.T) {
+	fake := newFakeBlobServer(t)
+	dash := testDashboard(t, fake.handle)
+	want := &Repro{
+		Level:        ReproLevelC,
+		Syz:          fake.blobs["Syz"],
+		C:            fake.blobs["C"],
+		Opts:         []byte("opts"),
+		KernelConfig: fake.blobs["KernelConfig"],
+	}
+	for _, preferURLs := range []bool{false, true} {
+		repro, err := dash.GetRepro(context.Background(), "bug", PreferURLs(preferURLs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, repro); diff != "" {
+			t.Fatalf("PreferURLs=%v:\n%v", preferURLs, diff)
+		}
+	}
+	if fake.queries != 2 {
+		t.Fatalf("want 2 queries, got %v", fake.queries)
+	}
+}
+
+func TestBlobURLsExpired(t *testing.T) {
+	fake := newFakeBlobServer(t)
+	dash := testDashboard(t, fake.handle)
+	dash.preferURLs = true
+	fake.expired = 1
+	repro, err := dash.GetRepro(context.Background(), "bug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(repro.Syz) != "syz repro" || fake.queries != 2 {
+		t.Fatalf("the method was not re-requested: queries=%v, repro=%+v", fake.queries, repro)
+	}
+	// The method is re-requested only once.
+	fake.queries = 0
+	fake.expired = 2
+	if _, err := dash.GetRepro(context.Background(), "bug"); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if fake.queries != 2 {
+		t.Fatalf("want 2 queries, got %v", fake.queries)
+	}
+	// Unknown options are not silently ignored.
+	fake.queries = 0
+	if _, err := dash.GetRepro(context.Background(), "bug", Namespace("ns")); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if fake.queries != 0 {
+		t.Fatalf("want 0 queries, got %v", fake.queries)
+	}
+}
+
+func TestBlobURLsChecksum(t *testing.T) {
+	fake := newFakeBlobServer(t)
+	dash := testDashboard(t, fake.handle)
+	fake.corrupt = true
+	_, err := dash.GetRepro(context.Background(), "bug", PreferURLs(true))
+	if err == nil || !strings.Contains(err.Error(), "wrong checksum") {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+}
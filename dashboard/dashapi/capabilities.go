@@ -0,0 +1,78 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// APIVersion is the version of the dashboard API implemented by this package.
+// It's incremented with API changes that affect compatibility of clients and dashboards.
+const APIVersion = 1
+
+// Features of the dashboard API that are not tied to a single method, see Capabilities.
+const (
+	FeatureZstd         = "zstd"         // zstd-compressed payloads, see Compression
+	FeatureUncompressed = "uncompressed" // uncompressed small payloads, see CompressionThreshold
+	FeatureProto        = "proto"        // protobuf payloads, see ProtoEncoding
+	FeatureSignatures   = "signatures"   // signed requests, see SignRequests
+	FeatureIdempotency  = "idempotency"  // see Crash.IdempotencyKey
+	FeatureBlobDedup    = "blob_dedup"   // see BlobDedup
+	FeatureLongPoll     = "long_poll"    // see PollBugsRequest.Wait
+)
+
+type CapabilitiesReq struct {
+	Version int // APIVersion of the client
+}
+
+type CapabilitiesResp struct {
+	Version  int      // APIVersion of the dashboard, 0 for dashboards that predate the handshake
+	Features []string // see Feature* constants
+	Methods  []string // API methods the dashboard serves
+}
+
+func (caps *CapabilitiesResp) Supports(feature string) bool {
+	return slices.Contains(caps.Features, feature)
+}
+
+// HasMethod returns if the dashboard serves the method.
+// For dashboards that predate the handshake the list of methods is unknown, so all methods are assumed to exist.
+func (caps *CapabilitiesResp) HasMethod(method string) bool {
+	return caps.Version == 0 || slices.Contains(caps.Methods, method)
+}
+
+// Capabilities announces APIVersion to the dashboard and returns the version and features
+// of the dashboard. The reply is cached, so it's cheap to call before using optional features.
+// The negotiated features (zstd, uncompressed payloads, protobuf) are enabled right away, if they are configured.
+func (dash *Dashboard) Capabilities(ctx context.Context) (*CapabilitiesResp, error) {
+	dash.capsMu.Lock()
+	defer dash.capsMu.Unlock()
+	if dash.caps != nil {
+		return dash.caps, nil
+	}
+	// The handshake is not retried/spooled, the caller can repeat it (or go without the features).
+	caps := new(CapabilitiesResp)
+	err := dash.queryImpl(ctx, "capabilities", &CapabilitiesReq{Version: APIVersion}, caps)
+	var dashErr *Error
+	if errors.As(err, &dashErr) && strings.Contains(dashErr.Message, "unknown api method") {
+		caps, err = new(CapabilitiesResp), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if caps.Supports(FeatureZstd) {
+		dash.zstdServer.Store(true)
+	}
+	if caps.Supports(FeatureUncompressed) {
+		dash.plainServer.Store(true)
+	}
+	if caps.Supports(FeatureProto) {
+		dash.protoServer.Store(true)
+	}
+	dash.caps = caps
+	return caps, nil
+}
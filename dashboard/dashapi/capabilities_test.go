@@ -0,0 +1,153 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCapabilities(t *testing.T) {
+	var methods []string
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		methods = append(methods, method)
+		switch method {
+		case "capabilities":
+			req := new(CapabilitiesReq)
+			if err := json.Unmarshal(payload, req); err != nil {
+				t.Fatal(err)
+			}
+			if req.Version != APIVersion {
+				t.Errorf("client announced version %v, want %v", req.Version, APIVersion)
+			}
+			return &CapabilitiesResp{
+				Version:  APIVersion,
+				Features: []string{FeatureZstd},
+				Methods:  []string{"report_crashes"},
+			}, nil
+		case "report_crashes":
+			return &ReportCrashesResp{Results: []*ReportCrashResult{{}}}, nil
+		}
+		return nil, fmt.Errorf("unknown api method %q", method)
+	})
+	dash.compression = CompressionZstd
+	for i := 0; i < 2; i++ {
+		caps, err := dash.Capabilities(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !caps.Supports(FeatureZstd) || caps.Supports(FeatureProto) ||
+			!caps.HasMethod("report_crashes") || caps.HasMethod("report_crash") {
+			t.Fatalf("bad capabilities %+v", caps)
+		}
+	}
+	if !dash.zstdServer.Load() {
+		t.Fatalf("zstd was not enabled")
+	}
+	if _, err := dash.ReportCrashes(context.Background(), []*Crash{{}}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"capabilities", "report_crashes"}, methods); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCapabilitiesOldDashboard(t *testing.T) {
+	var methods []string
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		methods = append(methods, method)
+		if method == "report_crash" {
+			crash := new(Crash)
+			if err := json.Unmarshal(payload, crash); err != nil {
+				t.Fatal(err)
+			}
+			if crash.Title == "bad" {
+				return nil, fmt.Errorf("bad crash")
+			}
+			return &ReportCrashResp{NeedRepro: true}, nil
+		}
+		return nil, fmt.Errorf("unknown api method %q", method)
+	})
+	caps, err := dash.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Version != 0 || !caps.HasMethod("report_crash") {
+		t.Fatalf("bad capabilities %+v", caps)
+	}
+	// Pretend the dashboard doesn't support batching.
+	dash.caps = &CapabilitiesResp{Version: 1, Methods: []string{"report_crash"}}
+	results, err := dash.ReportCrashes(context.Background(), []*Crash{{Title: "good"}, {Title: "bad"}})
+	if err == nil {
+		t.Fatalf("the failed crash did not fail the request")
+	}
+	if len(results) != 1 || !results[0].NeedRepro {
+		t.Fatalf("bad results: %+v", results)
+	}
+	if diff := cmp.Diff([]string{"capabilities", "report_crash", "report_crash"}, methods); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestUpdateBugsOldDashboard(t *testing.T) {
+	var methods []string
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		methods = append(methods, method)
+		if method == "reporting_update" {
+			return &BugUpdateReply{OK: true}, nil
+		}
+		return nil, fmt.Errorf("unknown api method %q", method)
+	})
+	dash.caps = &CapabilitiesResp{Version: 1, Methods: []string{"reporting_update"}}
+	replies, err := dash.UpdateBugs(context.Background(), []*BugUpdate{{ID: "id1"}, {ID: "id2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 2 || !replies[0].OK || !replies[1].OK {
+		t.Fatalf("bad replies: %+v", replies)
+	}
+	if diff := cmp.Diff([]string{"reporting_update", "reporting_update"}, methods); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestReportingWaitBugs(t *testing.T) {
+	for _, longPoll := range []bool{false, true} {
+		var wait time.Duration
+		dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+			switch method {
+			case "capabilities":
+				caps := &CapabilitiesResp{Version: APIVersion}
+				if longPoll {
+					caps.Features = []string{FeatureLongPoll}
+				}
+				return caps, nil
+			case "reporting_poll_bugs":
+				req := new(PollBugsRequest)
+				if err := json.Unmarshal(payload, req); err != nil {
+					t.Fatal(err)
+				}
+				wait = req.Wait
+				return &PollBugsResponse{}, nil
+			}
+			return nil, fmt.Errorf("unknown api method %q", method)
+		})
+		_, err := dash.ReportingWaitBugs(context.Background(), &PollBugsRequest{Type: "test"}, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := time.Duration(0)
+		if longPoll {
+			want = time.Minute
+		}
+		if wait != want {
+			t.Errorf("long poll %v: sent wait %v, want %v", longPoll, wait, want)
+		}
+	}
+}
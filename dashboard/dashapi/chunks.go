@@ -0,0 +1,104 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// ChunkedUpload enables uploading of large crash logs and reports in several requests.
+// Crash.Log and Crash.Report that are larger than Threshold are uploaded in chunks of ChunkSize
+// before the crash itself, and the crash refers to the uploads with ChunkRefs.
+// Interrupted uploads are resumed from the last chunk the dashboard has received.
+type ChunkedUpload struct {
+	Threshold int // 0 means DefaultChunkThreshold
+	ChunkSize int // 0 means DefaultChunkSize
+}
+
+const (
+	DefaultChunkThreshold = 4 << 20
+	DefaultChunkSize      = 512 << 10
+)
+
+// ChunkRef refers to a payload that was uploaded with upload_chunk requests.
+type ChunkRef struct {
+	Field    string // name of the []byte field of the request that the upload belongs to
+	UploadID string // see UploadChunkReq
+}
+
+type UploadChunkReq struct {
+	UploadID string // hex-encoded SHA256 of the whole payload
+	Size     int64  // size of the whole payload
+	Offset   int64
+	Data     []byte // empty to query the upload state
+}
+
+type UploadChunkResp struct {
+	Received int64 // number of bytes the dashboard has, the next chunk must start at this offset
+}
+
+// uploadChunks uploads large payloads of the crash and returns a copy of the crash
+// that refers to the uploads.
+func (dash *Dashboard) uploadChunks(ctx context.Context, crash *Crash) (*Crash, error) {
+	if dash.chunks == nil {
+		return crash, nil
+	}
+	res := *crash
+	fields := []struct {
+		name string
+		data *[]byte
+	}{
+		{"Log", &res.Log},
+		{"Report", &res.Report},
+	}
+	for _, field := range fields {
+		if len(*field.data) <= dash.chunks.Threshold {
+			continue
+		}
+		data := *field.data
+		if dash.payloadKeys != nil {
+			// The chunks are not encrypted one by one, so encrypt the whole payload.
+			var err error
+			if data, err = dash.payloadKeys.Encrypt(data); err != nil {
+				return nil, err
+			}
+		}
+		id, err := dash.uploadPayload(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %v: %w", field.name, err)
+		}
+		res.ChunkRefs = append(res.ChunkRefs, ChunkRef{Field: field.name, UploadID: id})
+		*field.data = nil
+	}
+	return &res, nil
+}
+
+func (dash *Dashboard) uploadPayload(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	req := &UploadChunkReq{
+		UploadID: hex.EncodeToString(sum[:]),
+		Size:     int64(len(data)),
+	}
+	// The first request carries no data and returns the upload state,
+	// so that uploads interrupted by errors or restarts are resumed.
+	for {
+		resp := new(UploadChunkResp)
+		if err := dash.Query(ctx, "upload_chunk", req, resp); err != nil {
+			return "", err
+		}
+		if resp.Received == req.Size {
+			return req.UploadID, nil
+		}
+		if resp.Received < 0 || resp.Received > req.Size ||
+			len(req.Data) != 0 && resp.Received <= req.Offset {
+			return "", fmt.Errorf("upload %v made no progress: received %v of %v",
+				req.UploadID, resp.Received, req.Size)
+		}
+		req.Offset = resp.Received
+		req.Data = data[req.Offset:min(req.Offset+int64(dash.chunks.ChunkSize), req.Size)]
+	}
+}
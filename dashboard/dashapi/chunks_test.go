@@ -0,0 +1,89 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type fakeChunkServer struct {
+	t       *testing.T
+	uploads map[string][]byte
+	chunks  int
+	failAt  int // fail the chunk with this number once
+	crashes []*Crash
+}
+
+func (srv *fakeChunkServer) handle(method string, payload []byte) (interface{}, error) {
+	switch method {
+	case "upload_chunk":
+		req := new(UploadChunkReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			srv.t.Fatal(err)
+		}
+		data := srv.uploads[req.UploadID]
+		if len(req.Data) != 0 && req.Offset == int64(len(data)) {
+			srv.chunks++
+			if srv.chunks == srv.failAt {
+				return nil, errors.New("chunk failed")
+			}
+			data = append(data, req.Data...)
+			srv.uploads[req.UploadID] = data
+		}
+		return &UploadChunkResp{Received: int64(len(data))}, nil
+	case "report_crash":
+		crash := new(Crash)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			srv.t.Fatal(err)
+		}
+		for _, ref := range crash.ChunkRefs {
+			if ref.Field == "Log" {
+				crash.Log = srv.uploads[ref.UploadID]
+			}
+		}
+		srv.crashes = append(srv.crashes, crash)
+		return &ReportCrashResp{}, nil
+	}
+	return nil, fmt.Errorf("unknown method %v", method)
+}
+
+func TestChunkedUpload(t *testing.T) {
+	srv := &fakeChunkServer{t: t, uploads: make(map[string][]byte), failAt: 3}
+	dash := testDashboard(t, srv.handle)
+	dash.chunks = &ChunkedUpload{Threshold: 100, ChunkSize: 64}
+	crash := &Crash{
+		Title:  "title",
+		Log:    bytes.Repeat([]byte("0123456789"), 100),
+		Report: []byte("small report"),
+	}
+	// The first attempt fails in the middle of the upload.
+	if _, err := dash.ReportCrash(context.Background(), crash); err == nil {
+		t.Fatalf("the failed upload succeeded")
+	}
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	// The second attempt resumed the upload, so each chunk was sent once, plus the failed one.
+	if want := (len(crash.Log)+63)/64 + 1; srv.chunks != want {
+		t.Fatalf("uploaded %v chunks, want %v", srv.chunks, want)
+	}
+	if len(srv.crashes) != 1 {
+		t.Fatalf("got %v crashes, want 1", len(srv.crashes))
+	}
+	got := srv.crashes[0]
+	if !bytes.Equal(got.Log, crash.Log) || !bytes.Equal(got.Report, crash.Report) {
+		t.Fatalf("the crash was not reassembled")
+	}
+	if len(got.ChunkRefs) != 1 {
+		t.Fatalf("got %v chunk refs, want 1", len(got.ChunkRefs))
+	}
+	if len(crash.ChunkRefs) != 0 {
+		t.Fatalf("the caller's crash was modified")
+	}
+}
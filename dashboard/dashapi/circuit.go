@@ -0,0 +1,122 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker makes the client stop sending requests to the dashboard after Failures
+// consecutive requests failed with transient errors (after retries), for Cooldown.
+// While the circuit is open, requests fail immediately with an error that matches
+// ErrDashboardUnavailable (such requests are still spooled, see SpoolConfig).
+// After Cooldown one request is let through to probe the dashboard: if it succeeds,
+// the circuit is closed again, otherwise it stays open for another Cooldown.
+type CircuitBreaker struct {
+	Failures int           // 0 means 5
+	Cooldown time.Duration // 0 means 5 minutes
+}
+
+var ErrDashboardUnavailable = errors.New("dashboard is unavailable")
+
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // requests are sent as usual
+	CircuitOpen                         // requests fail immediately
+	CircuitHalfOpen                     // a probe request is in flight
+)
+
+func (s CircuitState) String() string {
+	return [...]string{"closed", "open", "half-open"}[s]
+}
+
+type circuit struct {
+	cfg      CircuitBreaker
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	until    time.Time
+	lastErr  error
+}
+
+func newCircuit(cfg *CircuitBreaker) *circuit {
+	c := &circuit{cfg: *cfg}
+	if c.cfg.Failures == 0 {
+		c.cfg.Failures = 5
+	}
+	if c.cfg.Cooldown == 0 {
+		c.cfg.Cooldown = 5 * time.Minute
+	}
+	return c
+}
+
+// CircuitState returns the state of the circuit breaker, CircuitClosed if there is none.
+func (dash *Dashboard) CircuitState() CircuitState {
+	if dash.circuit == nil {
+		return CircuitClosed
+	}
+	dash.circuit.mu.Lock()
+	defer dash.circuit.mu.Unlock()
+	return dash.circuit.state
+}
+
+func (dash *Dashboard) queryCircuit(ctx context.Context, method string, req, reply interface{}) error {
+	if dash.circuit == nil {
+		return dash.queryRetry(ctx, method, req, reply)
+	}
+	if err := dash.circuit.allow(method, time.Now()); err != nil {
+		return err
+	}
+	err := dash.queryRetry(ctx, method, req, reply)
+	if state, changed := dash.circuit.record(ctx, time.Now(), err); changed && dash.logger != nil {
+		dash.logger("API(%v): circuit breaker is %v", method, state)
+	}
+	return err
+}
+
+// allow returns an error if the request must not be sent.
+func (c *circuit) allow(method string, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CircuitOpen && !now.Before(c.until) {
+		c.state = CircuitHalfOpen
+		return nil
+	}
+	if c.state == CircuitClosed {
+		return nil
+	}
+	return temporaryError(method, fmt.Errorf("%w after %v failures (retry after %v): %w",
+		ErrDashboardUnavailable, c.failures, c.until.Format(time.DateTime), c.lastErr))
+}
+
+// record accounts the result of a request and returns the new state if it has changed.
+func (c *circuit) record(ctx context.Context, now time.Time, err error) (CircuitState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev := c.state
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up, this says nothing about the dashboard.
+		if c.state == CircuitHalfOpen {
+			c.state = CircuitOpen
+		}
+	case err == nil || !IsTemporary(err):
+		c.state = CircuitClosed
+		c.failures = 0
+		c.lastErr = nil
+	default:
+		c.failures++
+		c.lastErr = err
+		if c.state == CircuitHalfOpen || c.failures >= c.cfg.Failures {
+			c.state = CircuitOpen
+			c.until = now.Add(c.cfg.Cooldown)
+		}
+	}
+	return c.state, c.state != prev
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	var fail error = errors.New("maintenance")
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		calls++
+		return nil, fail
+	})
+	dash.circuit = newCircuit(&CircuitBreaker{Failures: 2, Cooldown: time.Hour})
+	query := func() error {
+		return dash.Query(context.Background(), "log_error", &LogEntry{}, nil)
+	}
+	for i := 0; i < 2; i++ {
+		if err := query(); err == nil || errors.Is(err, ErrDashboardUnavailable) {
+			t.Fatalf("query %v: got %v, want a dashboard error", i, err)
+		}
+	}
+	if state := dash.CircuitState(); state != CircuitOpen {
+		t.Fatalf("circuit is %v after failures", state)
+	}
+	if err := query(); !errors.Is(err, ErrDashboardUnavailable) {
+		t.Fatalf("got %v with open circuit", err)
+	}
+	if calls != 2 {
+		t.Fatalf("the dashboard got %v requests, want 2", calls)
+	}
+
+	// The failed probe opens the circuit again.
+	dash.circuit.until = time.Now()
+	if err := query(); err == nil || errors.Is(err, ErrDashboardUnavailable) {
+		t.Fatalf("probe: got %v, want a dashboard error", err)
+	}
+	if state := dash.CircuitState(); state != CircuitOpen || calls != 3 {
+		t.Fatalf("circuit is %v after failed probe, %v calls", state, calls)
+	}
+
+	// The successful probe closes the circuit.
+	dash.circuit.until = time.Now()
+	fail = nil
+	if err := query(); err != nil {
+		t.Fatal(err)
+	}
+	if state := dash.CircuitState(); state != CircuitClosed {
+		t.Fatalf("circuit is %v after successful probe", state)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects the compression of request payloads.
+// Kernel configs and crash logs compress better and faster with zstd than with gzip.
+// zstd is negotiated: the dashboard lists the compressions it accepts in the CompressionHeader
+// header of its replies, and the client switches to zstd only after it has seen zstd there,
+// so it works with old dashboards.
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip" // the default, accepted by all dashboards
+	CompressionZstd Compression = "zstd"
+	CompressionNone Compression = "none" // used only for small payloads, see CompressionThreshold
+)
+
+// CompressionThreshold is the payload size in bytes below which payloads are sent uncompressed,
+// as compressing them costs more CPU than it saves bytes. Like zstd, uncompressed payloads
+// are sent only after the dashboard has listed "none" in the CompressionHeader header
+// (or FeatureUncompressed in Capabilities). 0 means DefaultCompressionThreshold,
+// a negative value makes the client always compress payloads.
+type CompressionThreshold int
+
+const DefaultCompressionThreshold = CompressionThreshold(100)
+
+const CompressionHeader = "X-Syzkaller-Compression"
+
+// DecompressPayload decompresses a request payload, compression is the value of
+// the "compression" form field of the request (empty for gzip).
+func DecompressPayload(compression string, data []byte) ([]byte, error) {
+	switch Compression(compression) {
+	case "", CompressionGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
+		}
+		res, err := io.ReadAll(gr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
+		}
+		if err := gr.Close(); err != nil {
+			return nil, fmt.Errorf("failed to ungzip payload: %w", err)
+		}
+		return res, nil
+	case CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		res, err := zstdDecoder().DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unzstd payload: %w", err)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unsupported payload compression %q", compression)
+}
+
+// AcceptsCompression returns if the value of the CompressionHeader header lists the compression.
+func AcceptsCompression(header string, compression Compression) bool {
+	for _, c := range strings.Split(header, ",") {
+		if Compression(strings.TrimSpace(c)) == compression {
+			return true
+		}
+	}
+	return false
+}
+
+// newCompressor returns a writer that compresses data written to it into w.
+// Close must be called to flush the compressed data.
+func newCompressor(w io.Writer, compression Compression) io.WriteCloser {
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{w}
+	case CompressionZstd:
+		enc := zstdEncoders.Get().(*zstd.Encoder)
+		enc.Reset(w)
+		return zstdWriter{enc}
+	}
+	return gzip.NewWriter(w)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// zstd encoders are expensive to create, so they are reused across requests.
+var zstdEncoders = sync.Pool{
+	New: func() any {
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			panic(err)
+		}
+		return enc
+	},
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+}
+
+func (w zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.Encoder.Reset(nil)
+	zstdEncoders.Put(w.Encoder)
+	return err
+}
+
+var zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		panic(err)
+	}
+	return dec
+})
@@ -0,0 +1,167 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompressionNegotiation(t *testing.T) {
+	advertise := false
+	var compressions []string
+	var logs [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressions = append(compressions, r.PostFormValue("compression"))
+		payload, err := DecompressPayload(r.PostFormValue("compression"), []byte(r.PostFormValue("payload")))
+		if err != nil {
+			t.Errorf("bad payload: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		crash := new(Crash)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, crash.Log)
+		if advertise {
+			w.Header().Set(CompressionHeader, "gzip, zstd")
+		}
+		json.NewEncoder(w).Encode(&ReportCrashResp{})
+	}))
+	defer srv.Close()
+
+	dash, err := New("client", srv.URL, "key", CompressionZstd, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crash := &Crash{Log: bytes.Repeat([]byte("crash log\n"), 100)}
+	// Until the dashboard has advertised zstd, payloads are sent with gzip.
+	for i := 0; i < 2; i++ {
+		if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	advertise = true
+	for i := 0; i < 2; i++ {
+		if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"", "", "", "zstd"}
+	for i := range want {
+		if compressions[i] != want[i] {
+			t.Fatalf("request compressions are %q, want %q", compressions, want)
+		}
+		if !bytes.Equal(logs[i], crash.Log) {
+			t.Fatalf("request %v has a corrupted log", i)
+		}
+	}
+
+	if _, err := New("client", srv.URL, "key", Compression("lz4")); err == nil {
+		t.Fatalf("unsupported compression is accepted")
+	}
+}
+
+func TestAcceptsCompression(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", false},
+		{"gzip, zstd", true},
+		{"zstd,gzip", true},
+		{"zstd4", false},
+	} {
+		if got := AcceptsCompression(test.header, CompressionZstd); got != test.want {
+			t.Errorf("AcceptsCompression(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("the client did not ask for compression")
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		json.NewEncoder(gz).Encode(&LogEntry{Name: "compressed"})
+		gz.Close()
+	}))
+	defer srv.Close()
+	// Explicitly disabled compression in the transport must not break decompression.
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	for _, opts := range [][]DashboardOpts{nil, {client}} {
+		dash, err := New("client", srv.URL, "key", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply := new(LogEntry)
+		if err := dash.Query(context.Background(), "test", nil, reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Name != "compressed" {
+			t.Fatalf("got reply %+v", reply)
+		}
+	}
+}
+
+func TestCompressionThreshold(t *testing.T) {
+	var compressions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressions = append(compressions, r.PostFormValue("compression"))
+		payload, err := DecompressPayload(r.PostFormValue("compression"), []byte(r.PostFormValue("payload")))
+		if err != nil {
+			t.Errorf("bad payload: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(payload, new(Crash)); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		w.Header().Set(CompressionHeader, "gzip, none")
+		json.NewEncoder(w).Encode(&ReportCrashResp{})
+	}))
+	defer srv.Close()
+
+	small := &Crash{Title: "title"}
+	large := &Crash{Log: bytes.Repeat([]byte("crash log\n"), 100)}
+	for _, test := range []struct {
+		threshold CompressionThreshold
+		want      []string
+	}{
+		// The first request is compressed since the dashboard has not advertised "none" yet.
+		{1000, []string{"", "none", ""}},
+		{2000, []string{"", "none", "none"}},
+		{0, []string{"", "", ""}}, // the small crash is larger than DefaultCompressionThreshold
+		{-1, []string{"", "", ""}},
+	} {
+		compressions = nil
+		dash, err := New("client", srv.URL, "key", test.threshold, RateLimits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, crash := range []*Crash{small, small, large} {
+			if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(compressions) != len(test.want) {
+			t.Fatalf("threshold %v: got %v requests, want %v", test.threshold, len(compressions), len(test.want))
+		}
+		for i := range test.want {
+			if compressions[i] != test.want[i] {
+				t.Fatalf("threshold %v: request compressions are %q, want %q",
+					test.threshold, compressions, test.want)
+			}
+		}
+	}
+}
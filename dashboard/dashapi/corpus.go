@@ -0,0 +1,110 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+
+	"github.com/google/syzkaller/pkg/hash"
+)
+
+// Corpus synchronization lets the dashboard act as a lightweight syz-hub for small deployments:
+// managers upload programs added to and removed from their corpus (UploadCorpus) and download
+// programs added by other managers of the namespace (DownloadCorpus).
+// Programs are identified by content hashes, so that only programs the dashboard does not have yet
+// are transferred.
+
+// CorpusProg is a corpus program, Prog may be empty if the program is referred to by Hash only.
+type CorpusProg struct {
+	Hash      string // see CorpusProgHash
+	Prog      []byte
+	Minimized bool
+	Manager   string // the manager that has uploaded the program, set by the dashboard
+}
+
+// CorpusProgHash returns the hash that identifies the program.
+func CorpusProgHash(prog []byte) string {
+	return hash.String(prog)
+}
+
+// MaxCorpusUpload is the maximum number of programs added (and removed) by a single upload_corpus request.
+const MaxCorpusUpload = 100
+
+type UploadCorpusReq struct {
+	Manager string
+	// Programs added to the corpus since the last upload. Programs with empty Prog are added
+	// by hash, the dashboard replies with the hashes it does not have.
+	Add []*CorpusProg
+	// Hashes of programs removed from the corpus since the last upload.
+	Del []string
+}
+
+type UploadCorpusResp struct {
+	Missing []string // hashes of programs that need to be uploaded with the contents
+}
+
+// UploadCorpus adds programs to and removes programs from the manager corpus on the dashboard.
+// Programs are first sent by hash only, and only the programs the dashboard does not have are sent
+// with the contents. Large uploads are split into several requests.
+func (dash *Dashboard) UploadCorpus(ctx context.Context, req *UploadCorpusReq) error {
+	progs := make(map[string]*CorpusProg)
+	var hashes []*CorpusProg
+	for _, prog := range req.Add {
+		hash := prog.Hash
+		if hash == "" {
+			hash = CorpusProgHash(prog.Prog)
+		}
+		progs[hash] = prog
+		hashes = append(hashes, &CorpusProg{Hash: hash, Minimized: prog.Minimized})
+	}
+	var missing []*CorpusProg
+	del := req.Del
+	for len(hashes) != 0 || len(del) != 0 {
+		batch := &UploadCorpusReq{
+			Manager: req.Manager,
+			Add:     hashes[:min(len(hashes), MaxCorpusUpload)],
+			Del:     del[:min(len(del), MaxCorpusUpload)],
+		}
+		hashes, del = hashes[len(batch.Add):], del[len(batch.Del):]
+		resp := new(UploadCorpusResp)
+		if err := dash.Query(ctx, "upload_corpus", batch, resp); err != nil {
+			return err
+		}
+		for _, hash := range resp.Missing {
+			if prog := progs[hash]; prog != nil {
+				missing = append(missing, &CorpusProg{Hash: hash, Prog: prog.Prog, Minimized: prog.Minimized})
+			}
+		}
+	}
+	for len(missing) != 0 {
+		batch := &UploadCorpusReq{Manager: req.Manager, Add: missing[:min(len(missing), MaxCorpusUpload)]}
+		missing = missing[len(batch.Add):]
+		if err := dash.Query(ctx, "upload_corpus", batch, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type DownloadCorpusReq struct {
+	Manager string
+	// Cursor is the DownloadCorpusResp.Cursor of the previous call,
+	// empty to download the corpus from the beginning.
+	Cursor string
+	Max    int // the maximum number of programs to return, 0 means the dashboard default
+}
+
+type DownloadCorpusResp struct {
+	Progs  []*CorpusProg // programs uploaded by other managers
+	Cursor string        // pass to the next call to get the programs that follow
+	More   bool          // there may be more programs, call DownloadCorpus again right away
+}
+
+// DownloadCorpus returns programs of the namespace corpus uploaded by other managers after the cursor.
+// Managers are supposed to persist the cursor to download only new programs after restarts.
+func (dash *Dashboard) DownloadCorpus(ctx context.Context, req *DownloadCorpusReq) (*DownloadCorpusResp, error) {
+	resp := new(DownloadCorpusResp)
+	err := dash.Query(ctx, "download_corpus", req, resp)
+	return resp, err
+}
@@ -0,0 +1,270 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/pkg/hash"
+)
+
+// CrashIndexConfig enables a persistent on-disk index of crashes reported with ReportCrash.
+// While a crash title is in the index, repeated reports of the crash are converted into
+// CountCrash calls, so that the index survives manager restarts.
+// The index is periodically reconciled with the dashboard in the background and titles
+// of bugs that are no longer open are dropped from it.
+type CrashIndexConfig struct {
+	File string
+	// A crash is uploaded in full again if it was last uploaded longer than ReuploadPeriod ago,
+	// so that the dashboard still gets fresh crashes, logs and builds to save.
+	ReuploadPeriod time.Duration
+	// How often the index is reconciled against the bug status on the dashboard.
+	ReconcilePeriod time.Duration
+	// Maximum number of titles in the index, the least recently used titles are evicted first.
+	MaxEntries int
+}
+
+const (
+	// The dashboard saves a new crash of a bug at most once an hour anyway.
+	defaultCrashIndexReuploadPeriod  = time.Hour
+	defaultCrashIndexReconcilePeriod = 6 * time.Hour
+	defaultCrashIndexMaxEntries      = 10000
+	// BugStatus requests with more titles are rejected by the dashboard.
+	crashIndexReconcileBatch = 500
+)
+
+type crashIndex struct {
+	cfg     CrashIndexConfig
+	mu      sync.Mutex
+	saveMu  sync.Mutex // serializes writes of the index file
+	entries map[string]*crashIndexEntry
+	// The time of the last reconciliation with the dashboard.
+	reconciled  time.Time
+	reconciling bool
+	wg          sync.WaitGroup // background reconciliations
+}
+
+type crashIndexEntry struct {
+	Title      string
+	ReportHash string
+	LastUpload time.Time
+	LastUsed   time.Time
+	CrashID    int64
+}
+
+type crashIndexData struct {
+	Reconciled time.Time
+	Entries    []*crashIndexEntry
+}
+
+func openCrashIndex(cfg *CrashIndexConfig) *crashIndex {
+	idx := &crashIndex{
+		cfg:     *cfg,
+		entries: make(map[string]*crashIndexEntry),
+	}
+	if idx.cfg.ReuploadPeriod == 0 {
+		idx.cfg.ReuploadPeriod = defaultCrashIndexReuploadPeriod
+	}
+	if idx.cfg.ReconcilePeriod == 0 {
+		idx.cfg.ReconcilePeriod = defaultCrashIndexReconcilePeriod
+	}
+	if idx.cfg.MaxEntries == 0 {
+		idx.cfg.MaxEntries = defaultCrashIndexMaxEntries
+	}
+	data, err := os.ReadFile(idx.cfg.File)
+	if err != nil {
+		// The index does not exist yet or is unreadable, start from scratch.
+		return idx
+	}
+	var file crashIndexData
+	if err := json.Unmarshal(data, &file); err != nil {
+		// The index is corrupted, rebuild it from scratch.
+		return idx
+	}
+	idx.reconciled = file.Reconciled
+	for _, ent := range file.Entries {
+		if ent != nil && ent.Title != "" {
+			idx.entries[ent.Title] = ent
+		}
+	}
+	idx.evict()
+	return idx
+}
+
+// countKnown converts the report of an already known crash into a CountCrash call.
+// It returns false if the crash needs to be uploaded in full.
+func (idx *crashIndex) countKnown(ctx context.Context, dash *Dashboard, crash *Crash) (*ReportCrashResp, bool) {
+	if len(crash.ReproSyz) != 0 || len(crash.ReproC) != 0 {
+		return nil, false
+	}
+	idx.reconcileAsync(dash)
+	now := time.Now()
+	idx.mu.Lock()
+	ent := idx.entries[crash.Title]
+	known := ent != nil && now.Sub(ent.LastUpload) < idx.cfg.ReuploadPeriod
+	if known {
+		ent.LastUsed = now
+	}
+	idx.mu.Unlock()
+	if !known {
+		return nil, false
+	}
+	resp, err := dash.CountCrash(ctx, &CrashID{
+		BuildID:    crash.BuildID,
+		Title:      crash.Title,
+		Corrupted:  crash.Corrupted,
+		Suppressed: crash.Suppressed,
+	})
+	if err != nil {
+		// The full upload will most likely fail as well and report the error.
+		return nil, false
+	}
+	if !resp.Found {
+		idx.mu.Lock()
+		delete(idx.entries, crash.Title)
+		idx.mu.Unlock()
+		idx.save()
+		return nil, false
+	}
+	return &ReportCrashResp{NeedRepro: resp.NeedRepro, ReproLevel: resp.ReproLevel}, true
+}
+
+func (idx *crashIndex) add(crash *Crash, resp *ReportCrashResp) {
+	now := time.Now()
+	idx.mu.Lock()
+	ent := idx.entries[crash.Title]
+	if ent == nil {
+		ent = &crashIndexEntry{Title: crash.Title}
+		idx.entries[crash.Title] = ent
+	}
+	ent.ReportHash = hash.String(crash.Report)
+	ent.LastUpload = now
+	ent.LastUsed = now
+	if resp.CrashID != 0 {
+		ent.CrashID = resp.CrashID
+	}
+	idx.evict()
+	idx.mu.Unlock()
+	idx.save()
+}
+
+// reconcileAsync starts a reconciliation in the background if it's due,
+// so that ReportCrash does not wait for the bug status queries.
+// Failures are ignored, the next reconciliation is attempted after ReconcilePeriod.
+func (idx *crashIndex) reconcileAsync(dash *Dashboard) {
+	idx.mu.Lock()
+	due := !idx.reconciling && time.Since(idx.reconciled) >= idx.cfg.ReconcilePeriod
+	if due {
+		idx.reconciling = true
+		idx.wg.Add(1)
+	}
+	idx.mu.Unlock()
+	if !due {
+		return
+	}
+	go func() {
+		defer idx.wg.Done()
+		idx.reconcile(context.Background(), dash, false)
+		idx.mu.Lock()
+		idx.reconciling = false
+		idx.mu.Unlock()
+	}()
+}
+
+// reconcile drops titles of bugs that are no longer open on the dashboard.
+// Unless force is set, it does nothing if the last reconciliation was recent enough.
+func (idx *crashIndex) reconcile(ctx context.Context, dash *Dashboard, force bool) error {
+	now := time.Now()
+	idx.mu.Lock()
+	if !force && now.Sub(idx.reconciled) < idx.cfg.ReconcilePeriod {
+		idx.mu.Unlock()
+		return nil
+	}
+	// Even if the reconciliation fails, don't retry it on every crash.
+	idx.reconciled = now
+	var titles []string
+	for title := range idx.entries {
+		titles = append(titles, title)
+	}
+	idx.mu.Unlock()
+	var inactive []string
+	for len(titles) != 0 {
+		batch := titles
+		if len(batch) > crashIndexReconcileBatch {
+			batch = batch[:crashIndexReconcileBatch]
+		}
+		titles = titles[len(batch):]
+		resp, err := dash.BugStatus(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, info := range resp.Bugs {
+			if !info.Active {
+				inactive = append(inactive, info.Title)
+			}
+		}
+	}
+	idx.mu.Lock()
+	for _, title := range inactive {
+		delete(idx.entries, title)
+	}
+	idx.mu.Unlock()
+	idx.save()
+	return nil
+}
+
+// forget drops the titles from the index.
+func (idx *crashIndex) forget(titles []string) {
+	if len(titles) == 0 {
+		return
+	}
+	idx.mu.Lock()
+	for _, title := range titles {
+		delete(idx.entries, title)
+	}
+	idx.mu.Unlock()
+	idx.save()
+}
+
+// evict drops the least recently used entries above the limit, idx.mu must be held.
+func (idx *crashIndex) evict() {
+	for len(idx.entries) > idx.cfg.MaxEntries {
+		var oldest *crashIndexEntry
+		for _, ent := range idx.entries {
+			if oldest == nil || ent.LastUsed.Before(oldest.LastUsed) {
+				oldest = ent
+			}
+		}
+		delete(idx.entries, oldest.Title)
+	}
+}
+
+func (idx *crashIndex) save() {
+	idx.mu.Lock()
+	file := crashIndexData{
+		Reconciled: idx.reconciled,
+	}
+	for _, ent := range idx.entries {
+		entCopy := *ent
+		file.Entries = append(file.Entries, &entCopy)
+	}
+	idx.mu.Unlock()
+	data, err := json.Marshal(file)
+	if err != nil {
+		return
+	}
+	// Write and rename, so that a crash in the middle does not leave a partially written index.
+	// Failures are not fatal: the index is just an optimization.
+	idx.saveMu.Lock()
+	defer idx.saveMu.Unlock()
+	tmp := idx.cfg.File + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, idx.cfg.File)
+}
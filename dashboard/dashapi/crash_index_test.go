@@ -0,0 +1,217 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeCrashServer struct {
+	t       *testing.T
+	mu      sync.Mutex // reconciliations run in the background
+	calls   []string
+	active  map[string]bool
+	crashID int64
+	// If set, bug_status waits for it to be closed.
+	statusBlock chan struct{}
+}
+
+func (srv *fakeCrashServer) handle(method string, payload []byte) (interface{}, error) {
+	if method == "bug_status" && srv.statusBlock != nil {
+		<-srv.statusBlock
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.calls = append(srv.calls, method)
+	switch method {
+	case "report_crash":
+		crash := new(Crash)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			srv.t.Fatal(err)
+		}
+		srv.active[crash.Title] = true
+		srv.crashID++
+		return &ReportCrashResp{NeedRepro: true, CrashID: srv.crashID}, nil
+	case "count_crash":
+		crash := new(CrashID)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			srv.t.Fatal(err)
+		}
+		return &CountCrashResp{Found: srv.active[crash.Title], NeedRepro: false}, nil
+	case "bug_status":
+		req := new(BugStatusReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			srv.t.Fatal(err)
+		}
+		resp := new(BugStatusResp)
+		for _, title := range req.Titles {
+			resp.Bugs = append(resp.Bugs, &BugStatusInfo{
+				Title:  title,
+				Found:  true,
+				Active: srv.active[title],
+			})
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("unknown method %v", method)
+}
+
+func testCrashIndexDashboard(t *testing.T, srv *fakeCrashServer, cfg CrashIndexConfig) *Dashboard {
+	dash := testDashboard(t, srv.handle)
+	dash.crashIndex = openCrashIndex(&cfg)
+	// Don't reconcile in the background unless a test asks for it.
+	dash.crashIndex.reconciled = time.Now()
+	return dash
+}
+
+func TestCrashIndex(t *testing.T) {
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool)}
+	cfg := CrashIndexConfig{
+		File: filepath.Join(t.TempDir(), "index"),
+	}
+	dash := testCrashIndexDashboard(t, srv, cfg)
+	crash := &Crash{
+		BuildID: "build",
+		Title:   "title1",
+		Report:  []byte("report1"),
+	}
+	resp, err := dash.ReportCrash(context.Background(), crash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.NeedRepro {
+		t.Fatalf("first report: want NeedRepro")
+	}
+	resp, err = dash.ReportCrash(context.Background(), crash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NeedRepro {
+		t.Fatalf("counted report: want !NeedRepro")
+	}
+	// Crashes with repros are always uploaded.
+	withRepro := *crash
+	withRepro.ReproSyz = []byte("repro")
+	if _, err := dash.ReportCrash(context.Background(), &withRepro); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index must survive restarts.
+	dash = testCrashIndexDashboard(t, srv, cfg)
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bug got fixed, but the index does not know about it yet.
+	delete(srv.active, crash.Title)
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"report_crash", "count_crash", "report_crash", "count_crash",
+		"count_crash", "report_crash"}
+	if diff := cmp.Diff(want, srv.calls); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The crash is uploaded in full after ReuploadPeriod even if the report is the same,
+	// so that the dashboard can save it.
+	srv.calls = nil
+	dash.crashIndex.entries[crash.Title].LastUpload = time.Now().Add(-2 * defaultCrashIndexReuploadPeriod)
+	for i := 0; i < 2; i++ {
+		if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff([]string{"report_crash", "count_crash"}, srv.calls); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCrashIndexReconcileAsync(t *testing.T) {
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool), statusBlock: make(chan struct{})}
+	dash := testCrashIndexDashboard(t, srv, CrashIndexConfig{
+		File: filepath.Join(t.TempDir(), "index"),
+	})
+	crash := &Crash{BuildID: "build", Title: "title"}
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	// The reconciliation is due, but bug_status hangs: crashes are still counted
+	// (otherwise the test deadlocks).
+	dash.crashIndex.reconciled = time.Time{}
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	close(srv.statusBlock)
+	dash.crashIndex.wg.Wait()
+	want := []string{"report_crash", "count_crash", "bug_status"}
+	if diff := cmp.Diff(want, srv.calls); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCrashIndexReconcile(t *testing.T) {
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool)}
+	dash := testCrashIndexDashboard(t, srv, CrashIndexConfig{
+		File: filepath.Join(t.TempDir(), "index"),
+	})
+	crash1 := &Crash{BuildID: "build", Title: "title1"}
+	crash2 := &Crash{BuildID: "build", Title: "title2"}
+	for _, crash := range []*Crash{crash1, crash2} {
+		if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delete(srv.active, crash1.Title)
+	if err := dash.crashIndex.reconcile(context.Background(), dash, true); err != nil {
+		t.Fatal(err)
+	}
+	if dash.crashIndex.entries[crash1.Title] != nil {
+		t.Fatalf("inactive title was not dropped")
+	}
+	if dash.crashIndex.entries[crash2.Title] == nil {
+		t.Fatalf("active title was dropped")
+	}
+}
+
+func TestCrashIndexEviction(t *testing.T) {
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool)}
+	dash := testCrashIndexDashboard(t, srv, CrashIndexConfig{
+		File:       filepath.Join(t.TempDir(), "index"),
+		MaxEntries: 2,
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := dash.ReportCrash(context.Background(), &Crash{Title: fmt.Sprintf("title%v", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(dash.crashIndex.entries) != 2 || dash.crashIndex.entries["title0"] != nil {
+		t.Fatalf("the least recently used title was not evicted: %+v", dash.crashIndex.entries)
+	}
+}
+
+func TestCrashIndexCorrupted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index")
+	if err := os.WriteFile(file, []byte("{garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool)}
+	dash := testCrashIndexDashboard(t, srv, CrashIndexConfig{File: file})
+	if _, err := dash.ReportCrash(context.Background(), &Crash{Title: "title"}); err != nil {
+		t.Fatal(err)
+	}
+	idx := openCrashIndex(&CrashIndexConfig{File: file})
+	if len(idx.entries) != 1 {
+		t.Fatalf("the index was not rebuilt: %+v", idx.entries)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// Crash titles include things like line numbers and pointer values that differ between crashes
+// of the same bug. Crash.Signature is computed from the stack frames of the report without such noise,
+// and the dashboard merges crashes with the same signature into one bug even if the titles differ.
+
+// Number of the top frames that make up the signature.
+const crashSignatureFrames = 8
+
+var (
+	// func+0x1a/0x2b, optionally preceded by the frame address and the RIP/PC prefix.
+	linuxFrameRe = regexp.MustCompile(`^(?:\[<[0-9a-f]+>\]\s+)?(?:(?:RIP|PC|pc|ip):\s*(?:[0-9a-f]{4}:)?)?` +
+		`([a-zA-Z_][a-zA-Z0-9_.$]*)\+0x[0-9a-f]+/0x[0-9a-f]+`)
+	// #1 0x4a3b2c in func file.c:12
+	userFrameRe = regexp.MustCompile(`^#[0-9]+\s+0x[0-9a-f]+\s+in\s+([a-zA-Z_][a-zA-Z0-9_.:$<>]*)`)
+)
+
+// CrashSignature returns the signature of the crash report, or "" if the report has too few frames
+// for a meaningful signature. Unreliable frames ("? func+0x1/0x2") are skipped.
+func CrashSignature(report []byte) string {
+	var frames []string
+	for _, line := range bytes.Split(report, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("? ")) {
+			continue
+		}
+		match := linuxFrameRe.FindSubmatch(line)
+		if match == nil {
+			match = userFrameRe.FindSubmatch(line)
+		}
+		if match == nil {
+			continue
+		}
+		// The RIP/PC line is usually repeated as the first frame of the trace.
+		if frame := string(match[1]); len(frames) == 0 || frames[len(frames)-1] != frame {
+			frames = append(frames, frame)
+		}
+		if len(frames) == crashSignatureFrames {
+			break
+		}
+	}
+	if len(frames) < 2 {
+		return ""
+	}
+	return framesSignature(frames)
+}
+
+func framesSignature(frames []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(frames, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
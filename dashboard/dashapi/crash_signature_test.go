@@ -0,0 +1,57 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"testing"
+)
+
+func TestCrashSignature(t *testing.T) {
+	const report1 = `BUG: KASAN: use-after-free in foo_read+0x1a/0x60 fs/foo.c:120
+Read of size 8 at addr ffff88801c2b4a40 by task syz-executor.0/5123
+
+Call Trace:
+ <TASK>
+ __dump_stack lib/dump_stack.c:88 [inline]
+ dump_stack_lvl+0x125/0x1b0 lib/dump_stack.c:106
+ print_report+0xcb/0x620 mm/kasan/report.c:475
+ ? foo_helper+0x10/0x20
+ kasan_report+0xd9/0x110 mm/kasan/report.c:588
+ foo_read+0x1a/0x60 fs/foo.c:120
+ vfs_read+0x1ce/0x8f0 fs/read_write.c:468
+ ksys_read+0x12f/0x250 fs/read_write.c:613
+`
+	// The same crash with other addresses, offsets and line numbers.
+	const report2 = `BUG: KASAN: use-after-free in foo_read+0x2b/0x70 fs/foo.c:125
+Read of size 8 at addr ffff88802d3c5b50 by task syz-executor.3/6234
+
+Call Trace:
+ <TASK>
+ dump_stack_lvl+0x135/0x1c0 lib/dump_stack.c:107
+ print_report+0xcc/0x630 mm/kasan/report.c:476
+ kasan_report+0xda/0x120 mm/kasan/report.c:590
+ ? foo_other+0x10/0x20
+ foo_read+0x2b/0x70 fs/foo.c:125
+ vfs_read+0x1cf/0x900 fs/read_write.c:470
+ ksys_read+0x130/0x260 fs/read_write.c:615
+`
+	const report3 = `BUG: KASAN: use-after-free in foo_write+0x1a/0x60 fs/foo.c:140
+Call Trace:
+ dump_stack_lvl+0x125/0x1b0 lib/dump_stack.c:106
+ print_report+0xcb/0x620 mm/kasan/report.c:475
+ kasan_report+0xd9/0x110 mm/kasan/report.c:588
+ foo_write+0x1a/0x60 fs/foo.c:140
+ vfs_write+0x1ce/0x8f0 fs/read_write.c:568
+`
+	sig1, sig2, sig3 := CrashSignature([]byte(report1)), CrashSignature([]byte(report2)), CrashSignature([]byte(report3))
+	if sig1 == "" || sig1 != sig2 {
+		t.Errorf("same crashes got signatures %q and %q", sig1, sig2)
+	}
+	if sig3 == "" || sig3 == sig1 {
+		t.Errorf("different crashes got signatures %q and %q", sig1, sig3)
+	}
+	if sig := CrashSignature([]byte("no output from test machine")); sig != "" {
+		t.Errorf("report without frames got signature %q", sig)
+	}
+}
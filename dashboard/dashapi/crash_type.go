@@ -0,0 +1,41 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+// CrashType is the class of the crash as determined by the report parser,
+// it allows to filter, prioritize and route bugs without matching their titles.
+// The values are the same as the pkg/report/crash types.
+type CrashType string
+
+const (
+	CrashTypeUnknown     CrashType = ""
+	CrashTypeHang        CrashType = "HANG"
+	CrashTypeLeak        CrashType = "LEAK"
+	CrashTypeDataRace    CrashType = "DATARACE"
+	CrashTypeReboot      CrashType = "REBOOT"
+	CrashTypeUBSAN       CrashType = "UBSAN"
+	CrashTypeBug         CrashType = "BUG"
+	CrashTypeWarning     CrashType = "WARNING"
+	CrashTypeKASAN       CrashType = "KASAN"
+	CrashTypeLockdep     CrashType = "LOCKDEP"
+	CrashTypeAtomicSleep CrashType = "ATOMIC_SLEEP"
+	CrashTypeKMSAN       CrashType = "KMSAN"
+	CrashTypeSyzFailure  CrashType = "SYZ_FAILURE"
+)
+
+// Known says if the type is one of the CrashType constants.
+func (typ CrashType) Known() bool {
+	switch typ {
+	case CrashTypeUnknown, CrashTypeHang, CrashTypeLeak, CrashTypeDataRace, CrashTypeReboot,
+		CrashTypeUBSAN, CrashTypeBug, CrashTypeWarning, CrashTypeKASAN, CrashTypeLockdep,
+		CrashTypeAtomicSleep, CrashTypeKMSAN, CrashTypeSyzFailure:
+		return true
+	}
+	return false
+}
+
+// MemorySafety says if the crash is a memory safety violation (e.g. use-after-free or uninit use).
+func (typ CrashType) MemorySafety() bool {
+	return typ == CrashTypeKASAN || typ == CrashTypeKMSAN
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"testing"
+
+	"github.com/google/syzkaller/pkg/report/crash"
+)
+
+func TestCrashTypes(t *testing.T) {
+	// Managers send report types as is, so the values must match.
+	for _, typ := range []crash.Type{crash.UnknownType, crash.Hang, crash.MemoryLeak, crash.DataRace,
+		crash.UnexpectedReboot, crash.UBSAN, crash.Bug, crash.Warning, crash.KASAN, crash.LockdepBug,
+		crash.AtomicSleep, crash.KMSAN, crash.SyzFailure} {
+		if !CrashType(typ).Known() {
+			t.Errorf("crash type %q is not known to dashapi", typ)
+		}
+	}
+	if CrashType("FOO").Known() {
+		t.Errorf("unknown crash type is known")
+	}
+}
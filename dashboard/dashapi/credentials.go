@@ -0,0 +1,18 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+// Client credentials are sent in the ClientHeader and KeyHeader headers rather than in the request
+// (request URLs and bodies end up in logs of load balancers and proxies more often than headers).
+// Dashboards that accept header credentials say so in the CredentialsHeader header of every reply.
+// Until the client has seen such a reply, the credentials are also sent in the "client" and "key"
+// form fields, which is what older dashboards read.
+// With SignRequests the key is not sent at all.
+const (
+	ClientHeader      = "X-Syzkaller-Client"
+	KeyHeader         = "X-Syzkaller-Key"
+	CredentialsHeader = "X-Syzkaller-Credentials"
+	// The value of CredentialsHeader.
+	HeaderCredentials = "header"
+)
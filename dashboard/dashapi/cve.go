@@ -0,0 +1,19 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import "regexp"
+
+// CVE IDs are attached to bugs with BugUpdate.CVEs (e.g. by distro security teams)
+// and are returned in BugReport.CVEs and in the bug lists.
+
+// MaxBugCVEs is the maximum number of CVE IDs attached to one bug.
+const MaxBugCVEs = 16
+
+var cveRe = regexp.MustCompile(`^CVE-[0-9]{4}-[0-9]{4,}$`)
+
+// ValidCVE checks that id looks like a CVE ID, e.g. CVE-2024-12345.
+func ValidCVE(id string) bool {
+	return cveRe.MatchString(id)
+}
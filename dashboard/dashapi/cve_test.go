@@ -0,0 +1,23 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import "testing"
+
+func TestValidCVE(t *testing.T) {
+	tests := map[string]bool{
+		"CVE-2024-1234":    true,
+		"CVE-2024-1234567": true,
+		"CVE-2024-123":     false,
+		"cve-2024-1234":    false,
+		"CVE-24-1234":      false,
+		"CVE-2024-1234 ":   false,
+		"":                 false,
+	}
+	for id, valid := range tests {
+		if got := ValidCVE(id); got != valid {
+			t.Errorf("ValidCVE(%q) = %v, want %v", id, got, valid)
+		}
+	}
+}
@@ -8,86 +8,338 @@ package dashapi
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/mail"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/civil"
 	"github.com/google/syzkaller/pkg/auth"
 	"github.com/google/syzkaller/pkg/coveragedb"
+	"google.golang.org/grpc"
 )
 
 type Dashboard struct {
 	Client       string
 	Addr         string
 	Key          string
-	ctor         RequestCtor
-	doer         RequestDoer
+	Namespace    string // see Namespace
+	baseURL      string // Addr, or a placeholder URL for unix sockets
+	transport    Transport
+	auth         AuthProvider
 	logger       RequestLogger
 	errorHandler func(error)
-}
-
+	crashIndex   *crashIndex
+	preferURLs   bool
+	dryRun       bool
+	chunks       *ChunkedUpload
+	dedup        *blobDedup
+	truncation   *TruncationPolicy
+	payloadKeys  *PayloadKeys
+	toolBugs     toolBugDedup
+	failedRepros *failedReproDedup
+	logQueue     *logQueue
+	retry        RetryPolicy
+	timeout      time.Duration
+	spool        *spool
+	circuit      *circuit
+	limiter      *rateLimiter
+	interceptors []Interceptor
+	metrics      *Metrics
+	tracing      *tracing
+	grpcConn     *grpc.ClientConn
+	proto        bool
+	sign         bool
+	compression  Compression
+	threshold    int
+	protoServer  atomic.Bool // the dashboard has replied in protobuf
+	zstdServer   atomic.Bool // the dashboard has advertised zstd support
+	plainServer  atomic.Bool // the dashboard has advertised support for uncompressed payloads
+	headerCreds  atomic.Bool // the dashboard has advertised header credentials
+	reposMu      sync.Mutex
+	repos        *ReposResp
+	capsMu       sync.Mutex
+	caps         *CapabilitiesResp
+	etags        etagCache
+}
+
+// DashboardOpts are options for New: UserAgent, RequestTimeout, *http.Client, Proxy, ClientTLS,
+// Transport, AuthProvider, Interceptor, *Metrics, Tracing, RequestLogger, ErrorHandler, GRPC, ProtoEncoding, Compression,
+// CompressionThreshold, SignRequests, RetryPolicy, CircuitBreaker,
+// RateLimits, PreferURLs, DryRun, LogErrorQueue, TruncationPolicy, ChunkedUpload, BlobDedup,
+// CrashIndexConfig, SpoolConfig, FailedReproDedup, Namespace and *PayloadKeys.
 type DashboardOpts any
 type UserAgent string
 
+// Namespace is the dashboard namespace the requests are sent to. Without it the dashboard uses
+// the namespace of the client, so it's required for clients that work in several namespaces
+// (e.g. a manager that tests both mainline and stable trees).
+type Namespace string
+
+// RequestTimeout bounds the duration of a single dashboard request, each retry gets a new timeout.
+// 0 disables the timeout.
+type RequestTimeout time.Duration
+
+const DefaultRequestTimeout = RequestTimeout(5 * time.Minute)
+
+// addr is the URL of the dashboard, or unix:///path/to/socket for a co-located dashboard
+// that serves HTTP on a unix domain socket.
+// key == "" indicates that the ambient GCE service account authority
+// should be used as a bearer token (unless an AuthProvider is passed).
 func New(client, addr, key string, opts ...DashboardOpts) (*Dashboard, error) {
-	ctor := http.NewRequest
+	var userAgent UserAgent
+	var namespace Namespace
+	var transport Transport
+	var logger RequestLogger
+	var errorHandler ErrorHandler
+	var interceptors []Interceptor
+	var metrics *Metrics
+	var tracingCfg *Tracing
+	var indexCfg *CrashIndexConfig
+	var spoolCfg *SpoolConfig
+	var circuitCfg *CircuitBreaker
+	var grpcCfg *GRPC
+	var tlsCfg *ClientTLS
+	var proxy Proxy
+	var provider AuthProvider
+	var chunks *ChunkedUpload
+	var dedupCfg *BlobDedup
+	var failedReproCfg *FailedReproDedup
+	logQueueSize := DefaultLogErrorQueue
+	var truncation *TruncationPolicy
+	preferURLs := false
+	dryRun := false
+	proto := false
+	sign := false
+	compression := CompressionGzip
+	threshold := DefaultCompressionThreshold
+	var payloadKeys *PayloadKeys
+	retry := DefaultRetryPolicy
+	limits := DefaultRateLimits
+	timeout := DefaultRequestTimeout
+	var httpClient *http.Client
 	for _, o := range opts {
 		switch opt := o.(type) {
-		case UserAgent:
-			ctor = func(method, url string, body io.Reader) (*http.Request, error) {
-				req, err := http.NewRequest(method, url, body)
-				if err != nil {
-					return nil, err
-				}
-				req.Header.Add("User-Agent", string(opt))
-				return req, nil
+		case CrashIndexConfig:
+			indexCfg = &opt
+		case SpoolConfig:
+			spoolCfg = &opt
+		case CircuitBreaker:
+			circuitCfg = &opt
+		case GRPC:
+			grpcCfg = &opt
+		case PreferURLs:
+			preferURLs = bool(opt)
+		case DryRun:
+			dryRun = bool(opt)
+		case LogErrorQueue:
+			logQueueSize = opt
+		case TruncationPolicy:
+			truncation = &opt
+		case ChunkedUpload:
+			if opt.Threshold == 0 {
+				opt.Threshold = DefaultChunkThreshold
+			}
+			if opt.ChunkSize == 0 {
+				opt.ChunkSize = DefaultChunkSize
 			}
+			chunks = &opt
+		case BlobDedup:
+			dedupCfg = &opt
+		case FailedReproDedup:
+			failedReproCfg = &opt
+		case ProtoEncoding:
+			proto = bool(opt)
+		case SignRequests:
+			sign = bool(opt)
+		case Compression:
+			if opt != CompressionGzip && opt != CompressionZstd {
+				return nil, fmt.Errorf("unsupported compression %q", opt)
+			}
+			compression = opt
+		case CompressionThreshold:
+			if opt != 0 {
+				threshold = opt
+			}
+		case RetryPolicy:
+			retry = opt
+		case RateLimits:
+			limits = opt
+		case RequestTimeout:
+			timeout = opt
+		case *http.Client:
+			httpClient = opt
+		case Proxy:
+			proxy = opt
+		case ClientTLS:
+			tlsCfg = &opt
+		case Transport:
+			transport = opt
+		case AuthProvider:
+			provider = opt
+		case Interceptor:
+			interceptors = append(interceptors, opt)
+		case *Metrics:
+			metrics = opt
+		case Tracing:
+			tracingCfg = &opt
+		case RequestLogger:
+			logger = opt
+		case ErrorHandler:
+			errorHandler = opt
+		case *PayloadKeys:
+			if err := opt.check(); err != nil {
+				return nil, err
+			}
+			payloadKeys = opt
+		case UserAgent:
+			userAgent = opt
+		case Namespace:
+			namespace = opt
 		}
 	}
-	return NewCustom(client, addr, key, ctor, http.DefaultClient.Do, nil, nil)
-}
-
-type (
-	RequestCtor   func(method, url string, body io.Reader) (*http.Request, error)
-	RequestDoer   func(req *http.Request) (*http.Response, error)
-	RequestLogger func(msg string, args ...interface{})
-)
-
-// key == "" indicates that the ambient GCE service account authority
-// should be used as a bearer token.
-func NewCustom(client, addr, key string, ctor RequestCtor, doer RequestDoer,
-	logger RequestLogger, errorHandler func(error)) (*Dashboard, error) {
-	wrappedDoer := doer
-	if key == "" {
-		tokenCache, err := auth.MakeCache(ctor, doer)
+	socket, isUnix := strings.CutPrefix(addr, unixScheme)
+	if transport != nil && (httpClient != nil || proxy != "" || tlsCfg != nil || isUnix) {
+		return nil, fmt.Errorf("*http.Client, Proxy, ClientTLS and unix sockets can't be used with a custom Transport")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if proxy != "" {
+		proxyURL, err := proxy.parse()
 		if err != nil {
 			return nil, err
 		}
-		wrappedDoer = func(req *http.Request) (*http.Response, error) {
-			token, err := tokenCache.Get(time.Now())
-			if err != nil {
-				return nil, err
+		if httpClient, err = clientWithTransport(httpClient, func(t *http.Transport) {
+			t.Proxy = http.ProxyURL(proxyURL)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	var tlsConf *tls.Config
+	if tlsCfg != nil {
+		var err error
+		if tlsConf, err = tlsCfg.config(); err != nil {
+			return nil, err
+		}
+		if httpClient, err = clientWithTransport(httpClient, func(t *http.Transport) {
+			t.TLSClientConfig = tlsConf
+		}); err != nil {
+			return nil, err
+		}
+	}
+	baseURL := addr
+	if isUnix {
+		if socket == "" || proxy != "" {
+			return nil, fmt.Errorf("bad dashboard address %q: want unix:///path/to/socket without a proxy", addr)
+		}
+		var err error
+		if httpClient, err = clientWithTransport(httpClient, func(t *http.Transport) {
+			t.Proxy = nil
+			t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, "unix", socket)
 			}
-			req.Header.Add("Authorization", token)
-			return doer(req)
+		}); err != nil {
+			return nil, err
 		}
+		baseURL = unixBaseURL
+	}
+	if transport == nil {
+		transport = &httpTransport{httpClient}
 	}
-	return &Dashboard{
+	if userAgent != "" {
+		transport = &userAgentTransport{transport, string(userAgent)}
+	}
+	if provider == nil && key == "" && !dryRun {
+		tokenCache, err := auth.MakeCache(func(method, url string, body io.Reader) (*http.Request, error) {
+			return transport.NewRequest(context.Background(), method, url, body)
+		}, transport.Do)
+		if err != nil {
+			return nil, err
+		}
+		provider = &metadataAuth{tokenCache}
+	}
+	if provider != nil {
+		transport = &authTransport{transport, provider}
+	}
+	dash := &Dashboard{
 		Client:       client,
 		Addr:         addr,
 		Key:          key,
-		ctor:         ctor,
-		doer:         wrappedDoer,
+		Namespace:    string(namespace),
+		baseURL:      baseURL,
+		transport:    transport,
+		auth:         provider,
 		logger:       logger,
 		errorHandler: errorHandler,
-	}, nil
-}
+	}
+	if dryRun {
+		// Nothing is sent, so there is nothing to remember, spool, split or deduplicate.
+		indexCfg, spoolCfg, chunks, dedupCfg = nil, nil, nil, nil
+	}
+	if dedupCfg != nil && payloadKeys == nil {
+		dash.dedup = newBlobDedup(dedupCfg)
+	}
+	if failedReproCfg != nil {
+		dash.failedRepros = newFailedReproDedup(failedReproCfg)
+	}
+	var err error
+	if indexCfg != nil {
+		dash.crashIndex = openCrashIndex(indexCfg)
+	}
+	if grpcCfg != nil {
+		if dash.grpcConn, err = dialGRPC(grpcCfg, tlsConf); err != nil {
+			return nil, err
+		}
+	}
+	if spoolCfg != nil {
+		if dash.spool, err = openSpool(spoolCfg); err != nil {
+			return nil, err
+		}
+	}
+	if circuitCfg != nil {
+		dash.circuit = newCircuit(circuitCfg)
+	}
+	if len(limits) != 0 {
+		dash.limiter = newRateLimiter(limits)
+	}
+	if tracingCfg != nil {
+		dash.tracing = newTracing(tracingCfg)
+		interceptors = append([]Interceptor{dash.tracing.interceptor()}, interceptors...)
+	}
+	dash.interceptors = interceptors
+	dash.metrics = metrics
+	dash.preferURLs = preferURLs
+	dash.dryRun = dryRun
+	dash.logQueue = newLogQueue(logQueueSize)
+	dash.truncation = truncation
+	dash.chunks = chunks
+	dash.proto = proto
+	dash.sign = sign && key != ""
+	dash.compression = compression
+	dash.threshold = int(threshold)
+	dash.retry = retry
+	dash.timeout = time.Duration(timeout)
+	dash.payloadKeys = payloadKeys
+	return dash, nil
+}
+
+// RequestLogger logs dashboard requests, it can be passed to New.
+type RequestLogger func(msg string, args ...interface{})
+
+// ErrorHandler is called with errors of failed dashboard requests, it can be passed to New.
+type ErrorHandler func(error)
 
 // Build describes all aspects of a kernel build.
 type Build struct {
@@ -108,8 +360,21 @@ type Build struct {
 	Commits             []string // see BuilderPoll
 	FixCommits          []Commit
 	Assets              []NewAsset
+	// KernelConfig that the dashboard already has, see BlobDedup.
+	BlobHashes []BlobHash `json:",omitempty"`
+	Purpose    BuildPurpose
 }
 
+// BuildPurpose says what the build is used for.
+// Only BuildPurposeManager builds become the current build of the manager.
+type BuildPurpose string
+
+const (
+	BuildPurposeManager   BuildPurpose = "" // the build the manager fuzzes
+	BuildPurposeBisection BuildPurpose = "bisection"
+	BuildPurposePatchTest BuildPurpose = "patch_test"
+)
+
 type Commit struct {
 	Hash       string
 	Title      string
@@ -122,8 +387,13 @@ type Commit struct {
 	Link       string // set if the commit is a part of a reply
 }
 
-func (dash *Dashboard) UploadBuild(build *Build) error {
-	return dash.Query("upload_build", build, nil)
+func (dash *Dashboard) UploadBuild(ctx context.Context, build *Build) error {
+	build = dash.truncation.build(build)
+	return dash.queryDedup(ctx, "upload_build", func(dedup dedupBlob) interface{} {
+		req := *build
+		dedup("KernelConfig", &req.KernelConfig, &req.BlobHashes)
+		return &req
+	})
 }
 
 // BuilderPoll request is done by kernel builder before uploading a new build
@@ -143,15 +413,31 @@ type BuilderPollResp struct {
 	ReportEmail    string
 }
 
-func (dash *Dashboard) BuilderPoll(manager string) (*BuilderPollResp, error) {
+func (dash *Dashboard) BuilderPoll(ctx context.Context, manager string) (*BuilderPollResp, error) {
 	req := &BuilderPollReq{
 		Manager: manager,
 	}
 	resp := new(BuilderPollResp)
-	err := dash.Query("builder_poll", req, resp)
+	err := dash.Query(ctx, "builder_poll", req, resp)
 	return resp, err
 }
 
+// BuildCommitsReq reports commits present in an already uploaded build.
+// Fix commits are added to bugs after builds are uploaded, so builders periodically
+// check BuilderPollResp.PendingCommits against the current build and report the present ones,
+// this lets the dashboard close bugs whose fixes have reached the tested tree without waiting
+// for the next build.
+type BuildCommitsReq struct {
+	Manager    string
+	BuildID    string
+	Commits    []string // titles of BuilderPollResp.PendingCommits present in the build
+	FixCommits []Commit // see Build.FixCommits
+}
+
+func (dash *Dashboard) UploadBuildCommits(ctx context.Context, req *BuildCommitsReq) error {
+	return dash.Query(ctx, "build_commits", req, nil)
+}
+
 // Jobs workflow:
 //   - syz-ci sends JobResetReq to indicate that no previously started jobs
 //     are any longer in progress.
@@ -219,6 +505,9 @@ type JobDoneReq struct {
 	// If there are more than 1: suspected commits due to skips (broken build/boot).
 	Commits []Commit
 	Flags   JobDoneFlags
+	// Confidence is the estimated probability that the bisection result is correct
+	// (the product of the confidence in every bisection step).
+	Confidence float64
 }
 
 type JobType int
@@ -262,18 +551,52 @@ func (flags JobDoneFlags) String() string {
 	return "[" + res + "commit]"
 }
 
-func (dash *Dashboard) JobPoll(req *JobPollReq) (*JobPollResp, error) {
+func (dash *Dashboard) JobPoll(ctx context.Context, req *JobPollReq) (*JobPollResp, error) {
 	resp := new(JobPollResp)
-	err := dash.Query("job_poll", req, resp)
+	err := dash.Query(ctx, "job_poll", req, resp)
 	return resp, err
 }
 
-func (dash *Dashboard) JobDone(req *JobDoneReq) error {
-	return dash.Query("job_done", req, nil)
+func (dash *Dashboard) JobDone(ctx context.Context, req *JobDoneReq) error {
+	return dash.queryDedup(ctx, "job_done", func(dedup dedupBlob) interface{} {
+		res := *req
+		dedup("KernelConfig", &res.Build.KernelConfig, &res.Build.BlobHashes)
+		return &res
+	})
+}
+
+func (dash *Dashboard) JobReset(ctx context.Context, req *JobResetReq) error {
+	return dash.Query(ctx, "job_reset", req, nil)
 }
 
-func (dash *Dashboard) JobReset(req *JobResetReq) error {
-	return dash.Query("job_reset", req, nil)
+// QueueBisectReq asks the dashboard to create a bisection job for the bug right away,
+// bypassing the usual bisection scheduling heuristics.
+type QueueBisectReq struct {
+	BugID   string  // the same ID as used by LoadBug
+	Type    JobType // JobBisectCause or JobBisectFix
+	Manager string  // if set, bisect only crashes that happened on this manager
+}
+
+type BisectRefusal string
+
+const (
+	BisectRefusedNoRepro  BisectRefusal = "no_repro" // the bug has no suitable reproducer
+	BisectRefusedDone     BisectRefusal = "done"     // the bisection has already been done or is pending
+	BisectRefusedQuota    BisectRefusal = "quota"    // too many bisections are already queued
+	BisectRefusedDisabled BisectRefusal = "disabled" // the bisection is disabled for the bug/manager
+)
+
+type QueueBisectResp struct {
+	JobID string
+	// If the bisection was not queued, Refusal says why and Text contains a human-readable explanation.
+	Refusal BisectRefusal
+	Text    string
+}
+
+func (dash *Dashboard) QueueBisect(ctx context.Context, req *QueueBisectReq) (*QueueBisectResp, error) {
+	resp := new(QueueBisectResp)
+	err := dash.Query(ctx, "queue_bisect", req, resp)
+	return resp, err
 }
 
 type BuildErrorReq struct {
@@ -281,8 +604,19 @@ type BuildErrorReq struct {
 	Crash Crash
 }
 
-func (dash *Dashboard) ReportBuildError(req *BuildErrorReq) error {
-	return dash.Query("report_build_error", req, nil)
+func (dash *Dashboard) ReportBuildError(ctx context.Context, req *BuildErrorReq) error {
+	if dash.truncation != nil {
+		req = &BuildErrorReq{
+			Build: *dash.truncation.build(&req.Build),
+			Crash: *dash.truncation.crash(&req.Crash),
+		}
+	}
+	return dash.queryDedup(ctx, "report_build_error", func(dedup dedupBlob) interface{} {
+		res := *req
+		dedup("KernelConfig", &res.Build.KernelConfig, &res.Build.BlobHashes)
+		dedup("Log", &res.Crash.Log, &res.Crash.BlobHashes)
+		return &res
+	})
 }
 
 type CommitPollResp struct {
@@ -300,23 +634,39 @@ type Repo struct {
 	Branch string
 }
 
-func (dash *Dashboard) CommitPoll() (*CommitPollResp, error) {
+func (dash *Dashboard) CommitPoll(ctx context.Context) (*CommitPollResp, error) {
 	resp := new(CommitPollResp)
-	err := dash.Query("commit_poll", nil, resp)
+	err := dash.Query(ctx, "commit_poll", nil, resp)
 	return resp, err
 }
 
-func (dash *Dashboard) UploadCommits(commits []Commit) error {
+// ReportFixCommitsReq sets the commits that fix the open bug with the title.
+// Once a build contains all of the commits, the bug is closed as fixed. Builders learn
+// which commit titles to look for in new builds with BuilderPoll.
+type ReportFixCommitsReq struct {
+	Title string // the bug title, as reported in Crash.Title
+	// Only titles are required, hashes and the rest are filled in later from the kernel tree otherwise.
+	Commits []Commit
+}
+
+func (dash *Dashboard) ReportFixCommits(ctx context.Context, req *ReportFixCommitsReq) error {
+	return dash.Query(ctx, "report_fix_commits", req, nil)
+}
+
+func (dash *Dashboard) UploadCommits(ctx context.Context, commits []Commit) error {
 	if len(commits) == 0 {
 		return nil
 	}
-	return dash.Query("upload_commits", &CommitPollResultReq{commits}, nil)
+	return dash.Query(ctx, "upload_commits", &CommitPollResultReq{commits}, nil)
 }
 
 type CrashFlags int64
 
 const (
 	CrashUnderStrace CrashFlags = 1 << iota
+	// The crash was reproduced from a program received from syz-hub, i.e. it was originally
+	// found by a different manager.
+	CrashFromHub
 )
 
 // Crash describes a single kernel crash (potentially with repro).
@@ -340,15 +690,178 @@ type Crash struct {
 	ReproC        []byte
 	ReproLog      []byte
 	OriginalTitle string // Title before we began bug reproduction.
+	// Log and Report that were uploaded in chunks, see ChunkedUpload.
+	ChunkRefs []ChunkRef `json:",omitempty"`
+	// Repeated requests with the same key save the crash only once, see NewIdempotencyKey.
+	IdempotencyKey string `json:",omitempty"`
+	// Log that the dashboard already has, see BlobDedup.
+	BlobHashes []BlobHash `json:",omitempty"`
+	// Names of the subsystems the manager attributes the crash to (see pkg/subsystem).
+	// The dashboard uses them if it can't infer the bug subsystems itself.
+	Subsystems []string
+	// Structured description of the machine, in addition to the free-form MachineInfo.
+	Machine *Machine
+	// CrashSignature of the Report, crashes with the same signature are merged into one bug.
+	Signature string `json:",omitempty"`
+	// How the dashboard handles the crash, Leak describes the leaked object of CrashLeak crashes.
+	Kind CrashKind `json:",omitempty"`
+	Leak *Leak     `json:",omitempty"`
+	Type CrashType `json:",omitempty"` // the class of the crash, e.g. CrashTypeKASAN
+	// Up to MaxCrashAttachments additional files of the crash.
+	Attachments []CrashAttachment `json:",omitempty"`
+	// If set, the bug is embargoed (e.g. a security bug) until the time: it's not reported
+	// to public reportings and not listed publicly. Clients can't re-embargo a bug whose
+	// embargo has passed or was cleared with BugUpdate.ClearEmbargo.
+	EmbargoUntil time.Time
+}
+
+// Machine describes the (virtual) machine the crash happened on.
+// All fields are optional, managers fill in what they know.
+type Machine struct {
+	Arch     string
+	CPUModel string
+	NumCPUs  int
+	MemoryMB int
+	VMType   string // VM type from the manager config, e.g. qemu or gce
+	QemuArgs string
+	Cmdline  string // kernel command line
 }
 
 type ReportCrashResp struct {
-	NeedRepro bool
+	NeedRepro  bool
+	CrashID    int64      // ID of the saved crash, 0 if the crash was not saved
+	ReproLevel ReproLevel // the best repro the bug has, including the one reported with the crash
 }
 
-func (dash *Dashboard) ReportCrash(crash *Crash) (*ReportCrashResp, error) {
+func (dash *Dashboard) ReportCrash(ctx context.Context, crash *Crash) (*ReportCrashResp, error) {
+	if dash.crashIndex != nil {
+		if resp, ok := dash.crashIndex.countKnown(ctx, dash, crash); ok {
+			return resp, nil
+		}
+	}
 	resp := new(ReportCrashResp)
-	err := dash.Query("report_crash", crash, resp)
+	upload, err := dash.uploadChunks(ctx, withIdempotencyKey(dash.truncation.crash(crash)))
+	if err != nil {
+		return resp, err
+	}
+	err = dash.Query(ctx, "report_crash", upload, resp)
+	if err == nil && dash.crashIndex != nil {
+		dash.crashIndex.add(crash, resp)
+	}
+	return resp, err
+}
+
+// UpdateCrashReq attaches a reproducer that was found later to the crash
+// that was previously saved by ReportCrash with the CrashID.
+type UpdateCrashReq struct {
+	BuildID   string
+	Title     string // title the crash was reported with
+	CrashID   int64  // ReportCrashResp.CrashID
+	ReproOpts []byte
+	ReproSyz  []byte
+	ReproC    []byte
+	ReproLog  []byte
+	Assets    []NewAsset
+}
+
+// UpdateCrash returns ErrNotFound if the crash is not known to the dashboard
+// (e.g. it was already purged), then the repro needs to be sent with ReportCrash instead.
+func (dash *Dashboard) UpdateCrash(ctx context.Context, req *UpdateCrashReq) error {
+	return dash.Query(ctx, "update_crash", req, nil)
+}
+
+type ReportCrashesReq struct {
+	Crashes []*Crash
+}
+
+type ReportCrashesResp struct {
+	// Results are in the order of ReportCrashesReq.Crashes.
+	Results []*ReportCrashResult
+}
+
+type ReportCrashResult struct {
+	ReportCrashResp
+	Error string // set if the crash was not saved
+}
+
+// MaxCrashBatch is the maximum number of crashes in a single report_crashes request.
+const MaxCrashBatch = 50
+
+// ReportCrashes reports several crashes with a single request (or several requests
+// if there are more than MaxCrashBatch crashes). A failure to save one crash does not
+// affect the rest, it is returned in the Error field of the corresponding result.
+// Unlike ReportCrash, crashes are always uploaded in full.
+// Dashboards that don't support batching get the crashes one-by-one.
+func (dash *Dashboard) ReportCrashes(ctx context.Context, crashes []*Crash) ([]*ReportCrashResult, error) {
+	if caps, err := dash.Capabilities(ctx); err == nil && !caps.HasMethod("report_crashes") {
+		return dash.reportCrashesOneByOne(ctx, crashes)
+	}
+	var results []*ReportCrashResult
+	for len(crashes) != 0 {
+		batch := crashes[:min(len(crashes), MaxCrashBatch)]
+		crashes = crashes[len(batch):]
+		req := &ReportCrashesReq{}
+		for _, crash := range batch {
+			upload, err := dash.uploadChunks(ctx, withIdempotencyKey(dash.truncation.crash(crash)))
+			if err != nil {
+				return results, err
+			}
+			req.Crashes = append(req.Crashes, upload)
+		}
+		resp := new(ReportCrashesResp)
+		if err := dash.Query(ctx, "report_crashes", req, resp); err != nil {
+			return results, err
+		}
+		if len(resp.Results) != len(batch) {
+			return results, fmt.Errorf("got %v results for %v crashes", len(resp.Results), len(batch))
+		}
+		for i, res := range resp.Results {
+			if res.Error == "" && dash.crashIndex != nil {
+				dash.crashIndex.add(batch[i], &res.ReportCrashResp)
+			}
+		}
+		results = append(results, resp.Results...)
+	}
+	return results, nil
+}
+
+func (dash *Dashboard) reportCrashesOneByOne(ctx context.Context, crashes []*Crash) ([]*ReportCrashResult, error) {
+	var results []*ReportCrashResult
+	for _, crash := range crashes {
+		res := new(ReportCrashResult)
+		resp := new(ReportCrashResp)
+		upload, err := dash.uploadChunks(ctx, withIdempotencyKey(dash.truncation.crash(crash)))
+		if err == nil {
+			err = dash.Query(ctx, "report_crash", upload, resp)
+		}
+		var dashErr *Error
+		if err != nil && (!errors.As(err, &dashErr) || dashErr.Temporary) {
+			return results, err
+		}
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.ReportCrashResp = *resp
+			if dash.crashIndex != nil {
+				dash.crashIndex.add(crash, resp)
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+type CountCrashResp struct {
+	Found      bool // if not set, there is no active bug for the crash and it needs to be reported in full
+	NeedRepro  bool
+	ReproLevel ReproLevel
+}
+
+// CountCrash notifies dashboard about one more occurrence of an already reported crash
+// without uploading the crash itself.
+func (dash *Dashboard) CountCrash(ctx context.Context, crash *CrashID) (*CountCrashResp, error) {
+	resp := new(CountCrashResp)
+	err := dash.Query(ctx, "count_crash", crash, resp)
 	return resp, err
 }
 
@@ -360,22 +873,140 @@ type CrashID struct {
 	Suppressed   bool
 	MayBeMissing bool
 	ReproLog     []byte
+	// Set only for ReportFailedRepro.
+	FailedRepro *FailedRepro `json:",omitempty"`
+}
+
+// FailedRepro describes how a failed repro attempt went.
+type FailedRepro struct {
+	Attempts   int             // number of times the candidate programs were run in VMs
+	Strategies []ReproStrategy // the stages the attempt went through
+	Duration   time.Duration   // total time spent on the attempt
 }
 
+type ReproStrategy string
+
+const (
+	ReproStrategyBisection    ReproStrategy = "log_bisection"
+	ReproStrategyMinimization ReproStrategy = "minimization"
+	ReproStrategyCConversion  ReproStrategy = "c_conversion"
+)
+
+// MaxFailedReproLog is the size failed repro logs are truncated to (in addition to TruncationPolicy),
+// the dashboard needs only the beginning and the end of the log to tell how the attempt went.
+const MaxFailedReproLog = 1 << 20
+
 type NeedReproResp struct {
-	NeedRepro bool
+	NeedRepro  bool
+	ReproLevel ReproLevel // the best repro the bug already has, if the bug exists
 }
 
 // NeedRepro checks if dashboard needs a repro for this crash or not.
-func (dash *Dashboard) NeedRepro(crash *CrashID) (bool, error) {
-	resp := new(NeedReproResp)
-	err := dash.Query("need_repro", crash, resp)
+func (dash *Dashboard) NeedRepro(ctx context.Context, crash *CrashID) (bool, error) {
+	resp, err := dash.ReproStatus(ctx, crash)
 	return resp.NeedRepro, err
 }
 
+// ReproStatus is like NeedRepro, but also returns the repro level the bug already has,
+// e.g. a dashboard may still want a C repro for a bug that has only a syz repro.
+func (dash *Dashboard) ReproStatus(ctx context.Context, crash *CrashID) (*NeedReproResp, error) {
+	resp := new(NeedReproResp)
+	err := dash.Query(ctx, "need_repro", crash, resp)
+	return resp, err
+}
+
 // ReportFailedRepro notifies dashboard about a failed repro attempt for the crash.
-func (dash *Dashboard) ReportFailedRepro(crash *CrashID) error {
-	return dash.Query("report_failed_repro", crash, nil)
+// With FailedReproDedup repeated failed repros of the same title are silently dropped.
+func (dash *Dashboard) ReportFailedRepro(ctx context.Context, crash *CrashID) error {
+	if dash.failedRepros != nil && !dash.failedRepros.claim(crash.Title, time.Now()) {
+		return nil
+	}
+	req := *dash.truncation.crashID(crash)
+	req.ReproLog = Truncation{MaxFailedReproLog, KeepHeadAndTail}.Truncate(req.ReproLog)
+	err := dash.Query(ctx, "report_failed_repro", &req, nil)
+	if err != nil && dash.failedRepros != nil {
+		// Let the next failed repro of the title through.
+		dash.failedRepros.release(crash.Title)
+	}
+	return err
+}
+
+type BugStatusReq struct {
+	Titles []string
+}
+
+type BugStatusInfo struct {
+	Title      string
+	Found      bool // there is a bug with this title
+	Active     bool // the bug (or its canonical bug, if it's a dup) is open
+	Status     BugStatus
+	ReproLevel ReproLevel
+	Link       string // the bug page on the dashboard
+	ExtLink    string // where the bug was last reported, e.g. a mailing list thread
+}
+
+type BugStatusResp struct {
+	Bugs []*BugStatusInfo // in the same order as BugStatusReq.Titles
+}
+
+// BugStatus queries status of the bugs with the given crash titles.
+func (dash *Dashboard) BugStatus(ctx context.Context, titles []string) (*BugStatusResp, error) {
+	req := &BugStatusReq{
+		Titles: titles,
+	}
+	resp := new(BugStatusResp)
+	err := dash.Query(ctx, "bug_status", req, resp)
+	return resp, err
+}
+
+// BugStatusByTitle is a shortcut for BugStatus for a single title.
+// Found is false in the returned info if there is no such bug in the namespace.
+func (dash *Dashboard) BugStatusByTitle(ctx context.Context, title string) (*BugStatusInfo, error) {
+	resp, err := dash.BugStatus(ctx, []string{title})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Bugs) != 1 {
+		return nil, fmt.Errorf("bug_status returned %v bugs for 1 title", len(resp.Bugs))
+	}
+	return resp.Bugs[0], nil
+}
+
+type OpenBug struct {
+	ID         string // the bug ID as returned by BugList and accepted by LoadBug
+	Title      string
+	Namespace  string
+	ReproLevel ReproLevel
+	FixCommits []string // titles of commits that are supposed to fix the bug, if any
+	Subsystems []string
+}
+
+type OpenBugsReq struct {
+	Subsystem string // if set, only bugs of the subsystem are returned
+}
+
+type OpenBugsResp struct {
+	Bugs []*OpenBug
+}
+
+type OpenBugsOpts any
+
+// OnlySubsystem limits the OpenBugs reply to the bugs of the given subsystem.
+type OnlySubsystem string
+
+// OpenBugs returns the open bugs of the client's namespace sorted by title.
+// It accepts OnlySubsystem as an option.
+func (dash *Dashboard) OpenBugs(ctx context.Context, opts ...OpenBugsOpts) (*OpenBugsResp, error) {
+	req := new(OpenBugsReq)
+	for _, o := range opts {
+		switch opt := o.(type) {
+		case OnlySubsystem:
+			req.Subsystem = string(opt)
+		}
+	}
+	resp := new(OpenBugsResp)
+	err := dash.Query(ctx, "open_bugs", req, resp)
+	return resp, err
 }
 
 type LogToReproReq struct {
@@ -397,24 +1028,123 @@ type LogToReproResp struct {
 
 // LogToRepro are crash logs for older bugs that need to be reproduced on the
 // querying instance.
-func (dash *Dashboard) LogToRepro(req *LogToReproReq) (*LogToReproResp, error) {
+func (dash *Dashboard) LogToRepro(ctx context.Context, req *LogToReproReq) (*LogToReproResp, error) {
 	resp := new(LogToReproResp)
-	err := dash.Query("log_to_repro", req, resp)
+	err := dash.Query(ctx, "log_to_repro", req, resp)
 	return resp, err
 }
 
+type ReproTaskPollReq struct {
+	Manager string
+}
+
+type ReproTaskPollResp struct {
+	Task *ReproTask
+}
+
+// ReproTask is a reproduction task leased to a manager by the dashboard.
+// While the lease is active, the bug is not given to other managers.
+type ReproTask struct {
+	TaskID   string
+	Title    string
+	CrashLog []byte
+	Priority int       // tasks with higher priority are handed out first
+	Deadline time.Time // after the deadline the lease expires and the task may be given to another manager
+}
+
+type ReproTaskStatus string
+
+const (
+	ReproTaskSucceeded ReproTaskStatus = "succeeded"
+	ReproTaskFailed    ReproTaskStatus = "failed"
+	ReproTaskAbandoned ReproTaskStatus = "abandoned"
+)
+
+type ReproTaskResult struct {
+	Status ReproTaskStatus
+	// For ReproTaskSucceeded: the crash with the reproducer, it's processed as if it was sent with ReportCrash.
+	Crash *Crash
+	// For ReproTaskFailed: the build the reproduction was attempted on, the log and why it failed.
+	BuildID  string
+	ReproLog []byte
+	Reasons  []string
+}
+
+type ReproTaskDoneReq struct {
+	TaskID  string
+	Result  *ReproTaskResult
+	Manager string // the manager the task was leased to
+}
+
+// ReproTaskPoll leases the most important reproduction task for the manager.
+// It returns nil if there is nothing to reproduce at the moment.
+func (dash *Dashboard) ReproTaskPoll(ctx context.Context, manager string) (*ReproTask, error) {
+	req := &ReproTaskPollReq{
+		Manager: manager,
+	}
+	resp := new(ReproTaskPollResp)
+	if err := dash.Query(ctx, "repro_task_poll", req, resp); err != nil {
+		return nil, err
+	}
+	return resp.Task, nil
+}
+
+// ReproTaskDone completes a task returned by ReproTaskPoll for the manager and releases the lease.
+func (dash *Dashboard) ReproTaskDone(ctx context.Context, manager, taskID string, result *ReproTaskResult) error {
+	req := &ReproTaskDoneReq{
+		TaskID:  taskID,
+		Result:  dash.truncation.reproResult(result),
+		Manager: manager,
+	}
+	return dash.Query(ctx, "repro_task_done", req, nil)
+}
+
 type LogEntry struct {
-	Name string
-	Text string
+	Name   string
+	Text   string
+	Level  LogLevel          // empty means LogLevelError
+	Time   time.Time         // when the message was logged by the client
+	Fields map[string]string // structured data, e.g. the manager or the bug title
 }
 
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+)
+
 // Centralized logging on dashboard.
-func (dash *Dashboard) LogError(name, msg string, args ...interface{}) {
-	req := &LogEntry{
-		Name: name,
-		Text: fmt.Sprintf(msg, args...),
+// The message is sent in the background, see LogErrorQueue.
+func (dash *Dashboard) LogError(ctx context.Context, name, msg string, args ...interface{}) {
+	dash.logf(ctx, LogLevelError, name, msg, args...)
+}
+
+func (dash *Dashboard) LogWarn(ctx context.Context, name, msg string, args ...interface{}) {
+	dash.logf(ctx, LogLevelWarn, name, msg, args...)
+}
+
+func (dash *Dashboard) LogInfo(ctx context.Context, name, msg string, args ...interface{}) {
+	dash.logf(ctx, LogLevelInfo, name, msg, args...)
+}
+
+// Log sends the entry in the background as LogError does.
+// If entry.Time is not set, it's set to the current time.
+func (dash *Dashboard) Log(ctx context.Context, entry *LogEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
 	}
-	dash.Query("log_error", req, nil)
+	dash.logQueue.push(ctx, dash, entry)
+}
+
+func (dash *Dashboard) logf(ctx context.Context, level LogLevel, name, msg string, args ...interface{}) {
+	dash.Log(ctx, &LogEntry{
+		Name:  name,
+		Text:  fmt.Sprintf(msg, args...),
+		Level: level,
+	})
 }
 
 // BugReport describes a single bug.
@@ -481,10 +1211,28 @@ type BugReport struct {
 	Subsystems     []BugSubsystem
 	ReportElements *ReportElements
 	LabelMessages  map[string]string // notification messages for bug labels
+	BlobRefs       []BlobRef         `json:",omitempty"` // see PreferURLs
+	Notes          []BugNote         // notes attached with AddBugNote
+	Machine        *Machine          // see Crash.Machine
+	ReproLogLink   string            // log of the reproduction that produced ReproSyz/ReproC
+	ReproOptions   *ReproOptions     // ReproOpts in a structured form, if the dashboard can parse them
+	CrashType      CrashType         // see Crash.Type
+	Severity       BugSeverity       // set with BugUpdate.Severity or derived from the crash
+
+	Attachments []CrashAttachmentLink // see Crash.Attachments
+
+	EmbargoUntil time.Time // set while the bug is embargoed, see Crash.EmbargoUntil
+	CVEs         []string  // attached with BugUpdate.CVEs
+
+	// The namespace encrypts the payloads (see PayloadKeys): Log, Report and KernelConfig
+	// are not sent, ReproSyz/ReproC are the ciphertexts that only tell the repro level,
+	// the reports must link them rather than attach.
+	EncryptedPayloads bool
 }
 
 type ReportElements struct {
 	GuiltyFiles []string
+	Subsystems  []string // see Crash.Subsystems
 }
 
 type BugSubsystem struct {
@@ -497,6 +1245,8 @@ type Asset struct {
 	Title       string
 	DownloadURL string
 	Type        AssetType
+	Size        int64  // see NewAsset
+	SHA256      string // see NewAsset
 }
 
 type AssetType string
@@ -520,6 +1270,7 @@ type BisectResult struct {
 	CrashReportLink string
 	Fix             bool
 	CrossTree       bool
+	Confidence      float64 // see JobDoneReq.Confidence, 0 if unknown
 	// In case a missing backport was backported.
 	Backported *Commit
 }
@@ -591,6 +1342,23 @@ type BugUpdate struct {
 	// This is not relevant for emails, but may be important for external reportings.
 	ReportCrashIDs   []int64
 	UnreportCrashIDs []int64
+
+	// The tree FixCommits are pending in if they are not in the main tree yet (e.g. a maintainer tree).
+	// Empty FixRepo means the main tree.
+	FixRepo   string
+	FixBranch string
+
+	// If set, overrides the bug severity (SeverityUnknown leaves it intact).
+	Severity BugSeverity
+
+	// EmbargoUntil embargoes the bug or extends the embargo, ClearEmbargo lifts it
+	// (see Crash.EmbargoUntil).
+	EmbargoUntil time.Time
+	ClearEmbargo bool
+
+	// CVE IDs to attach to the bug, the already attached ones are kept unless ResetCVEs is set.
+	CVEs      []string
+	ResetCVEs bool
 }
 
 type BugUpdateReply struct {
@@ -604,12 +1372,32 @@ type BugUpdateReply struct {
 
 type PollBugsRequest struct {
 	Type string
+
+	// Optional filters that allow to shard reporting across several clients, empty means any.
+	Namespace string
+	Manager   string // only bugs that happened on the manager
+	// The maximum number of bug reports in the response (0 means the dashboard default),
+	// the dashboard caps it at MaxPollReports.
+	MaxReports int
+	// Cursor from the previous PollBugsResponse, continues after the bugs it returned.
+	Cursor string
+	// If there is nothing to report, the dashboard waits up to Wait (capped at MaxPollWait)
+	// for new bugs before replying (long polling). RequestTimeout must be larger than Wait.
+	Wait time.Duration
 }
 
 type PollBugsResponse struct {
 	Reports []*BugReport
+	// Set if there may be more bugs to report, pass it in PollBugsRequest.Cursor to get them.
+	Cursor string
 }
 
+const (
+	MaxPollReports = 10
+	// AppEngine limits the request duration, so long polls are relatively short.
+	MaxPollWait = 50 * time.Second
+)
+
 type BugNotification struct {
 	Type        BugNotif
 	Namespace   string
@@ -684,8 +1472,8 @@ type SaveDiscussionReq struct {
 	Discussion *Discussion
 }
 
-func (dash *Dashboard) SaveDiscussion(req *SaveDiscussionReq) error {
-	return dash.Query("save_discussion", req, nil)
+func (dash *Dashboard) SaveDiscussion(ctx context.Context, req *SaveDiscussionReq) error {
+	return dash.Query(ctx, "save_discussion", req, nil)
 }
 
 type MergedCoverage struct {
@@ -702,8 +1490,24 @@ type SaveCoverageReq struct {
 	Coverage *MergedCoverage
 }
 
-func (dash *Dashboard) SaveCoverage(req *SaveCoverageReq) error {
-	return dash.Query("save_coverage", req, nil)
+func (dash *Dashboard) SaveCoverage(ctx context.Context, req *SaveCoverageReq) error {
+	return dash.Query(ctx, "save_coverage", req, nil)
+}
+
+// UploadCoverageReq is the merged coverage a manager has collected on a kernel build.
+// Managers upload it periodically, the dashboard keeps the latest upload per manager per day.
+type UploadCoverageReq struct {
+	Manager      string
+	BuildID      string
+	KernelRepo   string
+	KernelBranch string
+	KernelCommit string
+	// Instrumented and covered lines by file path.
+	Files map[string]*coveragedb.Coverage
+}
+
+func (dash *Dashboard) UploadCoverage(ctx context.Context, req *UploadCoverageReq) error {
+	return dash.Query(ctx, "upload_coverage", req, nil)
 }
 
 type TestPatchRequest struct {
@@ -719,50 +1523,122 @@ type TestPatchReply struct {
 	ErrorText string
 }
 
-func (dash *Dashboard) ReportingPollBugs(typ string) (*PollBugsResponse, error) {
-	req := &PollBugsRequest{
+func (dash *Dashboard) ReportingPollBugs(ctx context.Context, typ string) (*PollBugsResponse, error) {
+	return dash.ReportingPollBugsPage(ctx, &PollBugsRequest{
 		Type: typ,
+	})
+}
+
+// ReportingWaitBugs is ReportingPollBugsPage that waits up to wait for new bugs
+// if there is nothing to report. Dashboards without FeatureLongPoll reply right away.
+func (dash *Dashboard) ReportingWaitBugs(ctx context.Context, req *PollBugsRequest, wait time.Duration) (
+	*PollBugsResponse, error) {
+	caps, err := dash.Capabilities(ctx)
+	if err != nil {
+		return nil, err
 	}
+	req.Wait = 0
+	if caps.Supports(FeatureLongPoll) {
+		req.Wait = wait
+	}
+	return dash.ReportingPollBugsPage(ctx, req)
+}
+
+// ReportingPollBugsPage is ReportingPollBugs with filtering and pagination.
+func (dash *Dashboard) ReportingPollBugsPage(ctx context.Context, req *PollBugsRequest) (*PollBugsResponse, error) {
 	resp := new(PollBugsResponse)
-	if err := dash.Query("reporting_poll_bugs", req, resp); err != nil {
+	if err := dash.Query(ctx, "reporting_poll_bugs", req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (dash *Dashboard) ReportingPollNotifications(typ string) (*PollNotificationsResponse, error) {
+func (dash *Dashboard) ReportingPollNotifications(ctx context.Context, typ string) (
+	*PollNotificationsResponse, error) {
 	req := &PollNotificationsRequest{
 		Type: typ,
 	}
 	resp := new(PollNotificationsResponse)
-	if err := dash.Query("reporting_poll_notifs", req, resp); err != nil {
+	if err := dash.Query(ctx, "reporting_poll_notifs", req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (dash *Dashboard) ReportingPollClosed(ids []string) ([]string, error) {
+func (dash *Dashboard) ReportingPollClosed(ctx context.Context, ids []string) ([]string, error) {
 	req := &PollClosedRequest{
 		IDs: ids,
 	}
 	resp := new(PollClosedResponse)
-	if err := dash.Query("reporting_poll_closed", req, resp); err != nil {
+	if err := dash.Query(ctx, "reporting_poll_closed", req, resp); err != nil {
 		return nil, err
 	}
 	return resp.IDs, nil
 }
 
-func (dash *Dashboard) ReportingUpdate(upd *BugUpdate) (*BugUpdateReply, error) {
+func (dash *Dashboard) ReportingUpdate(ctx context.Context, upd *BugUpdate) (*BugUpdateReply, error) {
 	resp := new(BugUpdateReply)
-	if err := dash.Query("reporting_update", upd, resp); err != nil {
+	if err := dash.Query(ctx, "reporting_update", upd, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (dash *Dashboard) NewTestJob(upd *TestPatchRequest) (*TestPatchReply, error) {
+type UpdateBugsReq struct {
+	Updates []*BugUpdate
+}
+
+type UpdateBugsResp struct {
+	// Replies are in the order of UpdateBugsReq.Updates.
+	Replies []*BugUpdateReply
+}
+
+// MaxBugUpdateBatch is the maximum number of updates in a single update_bugs request.
+const MaxBugUpdateBatch = 50
+
+// UpdateBugs applies several bug updates with a single request (or several requests
+// if there are more than MaxBugUpdateBatch updates). Updates are applied independently,
+// the reply for each of them is returned in the same order.
+// Dashboards that don't support batching get the updates one-by-one.
+func (dash *Dashboard) UpdateBugs(ctx context.Context, updates []*BugUpdate) ([]*BugUpdateReply, error) {
+	if caps, err := dash.Capabilities(ctx); err == nil && !caps.HasMethod("update_bugs") {
+		return dash.updateBugsOneByOne(ctx, updates)
+	}
+	var replies []*BugUpdateReply
+	for len(updates) != 0 {
+		batch := updates[:min(len(updates), MaxBugUpdateBatch)]
+		updates = updates[len(batch):]
+		resp := new(UpdateBugsResp)
+		if err := dash.Query(ctx, "update_bugs", &UpdateBugsReq{Updates: batch}, resp); err != nil {
+			return replies, err
+		}
+		if len(resp.Replies) != len(batch) {
+			return replies, fmt.Errorf("got %v replies for %v updates", len(resp.Replies), len(batch))
+		}
+		replies = append(replies, resp.Replies...)
+	}
+	return replies, nil
+}
+
+func (dash *Dashboard) updateBugsOneByOne(ctx context.Context, updates []*BugUpdate) ([]*BugUpdateReply, error) {
+	var replies []*BugUpdateReply
+	for _, upd := range updates {
+		reply, err := dash.ReportingUpdate(ctx, upd)
+		var dashErr *Error
+		if err != nil && (!errors.As(err, &dashErr) || dashErr.Temporary) {
+			return replies, err
+		}
+		if err != nil {
+			reply = &BugUpdateReply{Error: true, Text: err.Error()}
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (dash *Dashboard) NewTestJob(ctx context.Context, upd *TestPatchRequest) (*TestPatchReply, error) {
 	resp := new(TestPatchReply)
-	if err := dash.Query("new_test_job", upd, resp); err != nil {
+	if err := dash.Query(ctx, "new_test_job", upd, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -778,6 +1654,8 @@ type ManagerStatsReq struct {
 	PCs        uint64 // coverage
 	Cover      uint64 // what we call feedback signal everywhere else
 	CrashTypes uint64
+	VMs        uint64 // VMs that are fuzzing or reproducing at the moment
+	TotalVMs   uint64
 
 	// Delta since last sync:
 	FuzzingTime       time.Duration
@@ -788,10 +1666,90 @@ type ManagerStatsReq struct {
 	// Non-zero only when set.
 	TriagedCoverage uint64
 	TriagedPCs      uint64
+
+	// Version of the last applied ManagerConfigResp.
+	ConfigVersion string
+}
+
+func (dash *Dashboard) UploadManagerStats(ctx context.Context, req *ManagerStatsReq) error {
+	return dash.Query(ctx, "manager_stats", req, nil)
+}
+
+type ManagerConfigReq struct {
+	Manager        string
+	CurrentVersion string
+}
+
+// ManagerConfigResp contains manager settings overridden on the dashboard.
+// New settings may be added over time, managers apply the ones they support and ignore the rest.
+type ManagerConfigResp struct {
+	Version string
+	// If set, the settings did not change since CurrentVersion and the rest of the fields are empty.
+	Unchanged        bool
+	DisabledSyscalls []string
+	SuppressedTitles []string // regexps
+	MaxReproAttempts int      // 0 means the manager default
+	ReportingPaused  bool     // don't report crashes to the dashboard
+}
+
+// PollManagerConfig returns the manager setting overrides.
+// currentVersion is the Version of the last received reply.
+func (dash *Dashboard) PollManagerConfig(ctx context.Context, manager, currentVersion string) (
+	*ManagerConfigResp, error) {
+	req := &ManagerConfigReq{
+		Manager:        manager,
+		CurrentVersion: currentVersion,
+	}
+	resp := new(ManagerConfigResp)
+	err := dash.Query(ctx, "manager_config", req, resp)
+	return resp, err
+}
+
+// KernelTree describes a kernel tree known to the dashboard.
+type KernelTree struct {
+	Alias    string
+	URL      string
+	Branch   string
+	OS       string // empty if the tree is not specific to an OS
+	Arch     string // empty if the tree is not specific to an arch
+	Priority int
+	NoPoll   bool
+}
+
+type ReposPollReq struct {
+	CurrentVersion string
+}
+
+// ReposResp is the authoritative list of kernel trees of the namespace.
+type ReposResp struct {
+	Version string
+	// If set, the list did not change since CurrentVersion and Repos is empty.
+	Unchanged bool
+	Repos     []*KernelTree
 }
 
-func (dash *Dashboard) UploadManagerStats(req *ManagerStatsReq) error {
-	return dash.Query("manager_stats", req, nil)
+// ReposPoll returns the list of kernel trees configured on the dashboard.
+// The last reply is cached, so repeated calls only transfer the list if it has changed.
+// The method can be called with the key of a read-only client.
+func (dash *Dashboard) ReposPoll(ctx context.Context) (*ReposResp, error) {
+	dash.reposMu.Lock()
+	cached := dash.repos
+	dash.reposMu.Unlock()
+	req := new(ReposPollReq)
+	if cached != nil {
+		req.CurrentVersion = cached.Version
+	}
+	resp := new(ReposResp)
+	if err := dash.Query(ctx, "repos_poll", req, resp); err != nil {
+		return nil, err
+	}
+	if resp.Unchanged && cached != nil {
+		return cached, nil
+	}
+	dash.reposMu.Lock()
+	dash.repos = resp
+	dash.reposMu.Unlock()
+	return resp, nil
 }
 
 // Asset lifetime:
@@ -802,6 +1760,8 @@ func (dash *Dashboard) UploadManagerStats(req *ManagerStatsReq) error {
 type NewAsset struct {
 	DownloadURL string
 	Type        AssetType
+	Size        int64  // of the uncompressed file, 0 if unknown
+	SHA256      string // hex-encoded hash of the uncompressed file, empty if unknown
 }
 
 type AddBuildAssetsReq struct {
@@ -809,17 +1769,17 @@ type AddBuildAssetsReq struct {
 	Assets  []NewAsset
 }
 
-func (dash *Dashboard) AddBuildAssets(req *AddBuildAssetsReq) error {
-	return dash.Query("add_build_assets", req, nil)
+func (dash *Dashboard) AddBuildAssets(ctx context.Context, req *AddBuildAssetsReq) error {
+	return dash.Query(ctx, "add_build_assets", req, nil)
 }
 
 type NeededAssetsResp struct {
 	DownloadURLs []string
 }
 
-func (dash *Dashboard) NeededAssetsList() (*NeededAssetsResp, error) {
+func (dash *Dashboard) NeededAssetsList(ctx context.Context) (*NeededAssetsResp, error) {
 	resp := new(NeededAssetsResp)
-	err := dash.Query("needed_assets", nil, resp)
+	err := dash.Query(ctx, "needed_assets", nil, resp)
 	return resp, err
 }
 
@@ -827,23 +1787,129 @@ type BugListResp struct {
 	List []string
 }
 
-func (dash *Dashboard) BugList() (*BugListResp, error) {
+func (dash *Dashboard) BugList(ctx context.Context) (*BugListResp, error) {
 	resp := new(BugListResp)
-	err := dash.Query("bug_list", nil, resp)
+	err := dash.Query(ctx, "bug_list", nil, resp)
 	return resp, err
 }
 
 type LoadBugReq struct {
-	ID string
+	ID         string
+	PreferURLs bool
 }
 
-func (dash *Dashboard) LoadBug(id string) (*BugReport, error) {
-	req := LoadBugReq{id}
+type LoadBugOpts any
+
+// LoadBug accepts PreferURLs as an option.
+func (dash *Dashboard) LoadBug(ctx context.Context, id string, opts ...LoadBugOpts) (*BugReport, error) {
+	req := LoadBugReq{
+		ID:         id,
+		PreferURLs: dash.preferURLs,
+	}
+	for _, o := range opts {
+		switch opt := o.(type) {
+		case PreferURLs:
+			req.PreferURLs = bool(opt)
+		}
+	}
 	resp := new(BugReport)
-	err := dash.Query("load_bug", req, resp)
+	err := dash.queryBlobs(ctx, "load_bug", req, resp)
 	return resp, err
 }
 
+type GetReproReq struct {
+	BugID          string
+	NoKernelConfig bool
+	PreferURLs     bool
+}
+
+// Repro is the best-known reproducer for a bug.
+type Repro struct {
+	Level        ReproLevel
+	Syz          []byte
+	C            []byte
+	Opts         []byte
+	KernelConfig []byte
+	BuildID      string    // the build on which the repro was found
+	BlobRefs     []BlobRef `json:",omitempty"` // see PreferURLs
+	// Opts in a structured form, if the dashboard can parse them.
+	Options *ReproOptions
+}
+
+// ReproOptions are the options a reproducer needs to be run with.
+// The field names follow the syz-execprog flags.
+type ReproOptions struct {
+	Threaded    bool
+	Repeat      bool
+	RepeatTimes int // if non-0, the program is repeated that many times
+	Procs       int
+	Slowdown    int
+	Sandbox     string
+	SandboxArg  int
+	Leak        bool // leak checking
+	Fault       bool // fault injection into FaultCall
+	FaultCall   int
+	FaultNth    int
+	Features    []string // enabled sandbox setup features, e.g. tun or cgroups
+}
+
+// ErrReproNotFound is returned by GetRepro if the bug has no reproducer.
+var ErrReproNotFound = errors.New("the bug has no reproducer")
+
+type GetReproOpts any
+
+// NoKernelConfig omits the kernel config from the GetRepro reply.
+type NoKernelConfig bool
+
+// GetRepro downloads the best-known reproducer for the bug (bugID is the same ID as used by LoadBug).
+// It accepts NoKernelConfig and PreferURLs as options, other options are rejected.
+func (dash *Dashboard) GetRepro(ctx context.Context, bugID string, opts ...GetReproOpts) (*Repro, error) {
+	req := &GetReproReq{
+		BugID:      bugID,
+		PreferURLs: dash.preferURLs,
+	}
+	for _, o := range opts {
+		switch opt := o.(type) {
+		case NoKernelConfig:
+			req.NoKernelConfig = bool(opt)
+		case PreferURLs:
+			req.PreferURLs = bool(opt)
+		default:
+			return nil, fmt.Errorf("unknown GetRepro option %T", o)
+		}
+	}
+	resp := new(Repro)
+	if err := dash.queryBlobs(ctx, "get_repro", req, resp); err != nil {
+		return nil, err
+	}
+	if resp.Level == ReproLevelNone {
+		return nil, ErrReproNotFound
+	}
+	return resp, nil
+}
+
+// AddBugNoteReq attaches a free-form note (e.g. an analysis or a decoded stack) to the bug.
+type AddBugNoteReq struct {
+	BugID string // the same ID as used by LoadBug
+	Title string
+	Text  []byte
+}
+
+// BugNote is a note attached to the bug with AddBugNote.
+type BugNote struct {
+	Time   time.Time
+	Author string // name of the client that added the note
+	Title  string
+	Link   string
+}
+
+// MaxBugNotes is the maximum number of notes the dashboard accepts for a single bug.
+const MaxBugNotes = 20
+
+func (dash *Dashboard) AddBugNote(ctx context.Context, req *AddBugNoteReq) error {
+	return dash.Query(ctx, "add_bug_note", req, nil)
+}
+
 type LoadFullBugReq struct {
 	BugID string
 }
@@ -867,9 +1933,9 @@ type SimilarBugInfo struct {
 	ReproLevel ReproLevel
 }
 
-func (dash *Dashboard) LoadFullBug(req *LoadFullBugReq) (*FullBugInfo, error) {
+func (dash *Dashboard) LoadFullBug(ctx context.Context, req *LoadFullBugReq) (*FullBugInfo, error) {
 	resp := new(FullBugInfo)
-	err := dash.Query("load_full_bug", req, resp)
+	err := dash.Query(ctx, "load_full_bug", req, resp)
 	return resp, err
 }
 
@@ -879,8 +1945,8 @@ type UpdateReportReq struct {
 	GuiltyFiles *[]string
 }
 
-func (dash *Dashboard) UpdateReport(req *UpdateReportReq) error {
-	return dash.Query("update_report", req, nil)
+func (dash *Dashboard) UpdateReport(ctx context.Context, req *UpdateReportReq) error {
+	return dash.Query(ctx, "update_report", req, nil)
 }
 
 type (
@@ -973,11 +2039,39 @@ type JobInfo struct {
 	OnMergeBase      bool
 }
 
-func (dash *Dashboard) Query(method string, req, reply interface{}) error {
+func (dash *Dashboard) Query(ctx context.Context, method string, req, reply interface{}) error {
+	if dash.tracing != nil {
+		return dash.tracing.query(ctx, dash, method, req, reply)
+	}
+	return dash.query(ctx, method, req, reply)
+}
+
+func (dash *Dashboard) query(ctx context.Context, method string, req, reply interface{}) error {
 	if dash.logger != nil {
 		dash.logger("API(%v): %#v", method, req)
 	}
-	err := dash.queryImpl(method, req, reply)
+	var err error
+	if dash.limiter != nil {
+		err = dash.limiter.wait(ctx, method)
+	}
+	if err == nil {
+		err = dash.queryCircuit(ctx, method, req, reply)
+	}
+	if dash.spool != nil {
+		if err == nil {
+			dash.spool.kick(dash)
+		} else if dash.spool.accepts(method, err) {
+			if spoolErr := dash.spool.add(dash, method, req); spoolErr == nil {
+				if dash.logger != nil {
+					dash.logger("API(%v): spooled: %v", method, err)
+				}
+				if reply != nil {
+					return fmt.Errorf("%w: %w", ErrSpooled, err)
+				}
+				return nil
+			}
+		}
+	}
 	if err != nil {
 		if dash.logger != nil {
 			dash.logger("API(%v): ERROR: %v", method, err)
@@ -993,7 +2087,7 @@ func (dash *Dashboard) Query(method string, req, reply interface{}) error {
 	return nil
 }
 
-func (dash *Dashboard) queryImpl(method string, req, reply interface{}) error {
+func (dash *Dashboard) queryImpl(ctx context.Context, method string, req, reply interface{}) error {
 	if reply != nil {
 		// json decoding behavior is somewhat surprising
 		// (see // https://github.com/golang/go/issues/21092).
@@ -1004,55 +2098,179 @@ func (dash *Dashboard) queryImpl(method string, req, reply interface{}) error {
 		}
 		reflect.ValueOf(reply).Elem().Set(reflect.New(typ.Elem()).Elem())
 	}
-	body := &bytes.Buffer{}
-	mWriter := multipart.NewWriter(body)
-	err := mWriter.WriteField("client", dash.Client)
-	if err != nil {
-		return err
+	if dash.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dash.timeout)
+		defer cancel()
 	}
-	err = mWriter.WriteField("key", dash.Key)
-	if err != nil {
-		return err
+	return dash.queryIntercepted(ctx, method, req, reply)
+}
+
+// querySend sends a request, header contains additional headers (may be nil).
+func (dash *Dashboard) querySend(ctx context.Context, method string, req, reply interface{},
+	header http.Header) error {
+	if dash.dryRun {
+		return dash.queryDryRun(method, req)
+	}
+	var err error
+	if req != nil && dash.payloadKeys != nil {
+		if req, err = dash.payloadKeys.encryptRequest(req); err != nil {
+			return err
+		}
+	}
+	if dash.grpcConn != nil {
+		err = dash.queryGRPC(ctx, method, req, reply, header)
+	} else {
+		err = dash.queryHTTP(ctx, method, req, reply, header)
 	}
-	err = mWriter.WriteField("method", method)
 	if err != nil {
 		return err
 	}
-	if req != nil {
-		w, err := mWriter.CreateFormField("payload")
+	if reply != nil && dash.payloadKeys != nil {
+		return dash.payloadKeys.decryptReply(reply)
+	}
+	return nil
+}
+
+// payloadCompression returns the compression for a payload of the given size
+// among the ones the dashboard is known to accept.
+func (dash *Dashboard) payloadCompression(size int) Compression {
+	switch {
+	case size < dash.threshold && dash.plainServer.Load():
+		return CompressionNone
+	case dash.compression == CompressionZstd && dash.zstdServer.Load():
+		return CompressionZstd
+	}
+	return CompressionGzip
+}
+
+func (dash *Dashboard) queryHTTP(ctx context.Context, method string, req, reply interface{},
+	header http.Header) error {
+	// Requests are sent in protobuf only after the dashboard has shown that it understands it.
+	protoReq := dash.proto && dash.protoServer.Load() && isProtoMessage(req)
+	var body io.Reader
+	var contentType, sig string
+	var wait func() (int, int, error)
+	if dash.sign {
+		// The signature covers the whole body, so signed requests are not streamed.
+		buf := new(bytes.Buffer)
+		form := multipart.NewWriter(buf)
+		size, compressed, err := dash.writeForm(form, method, req, protoReq)
 		if err != nil {
 			return err
 		}
-		data, err := json.Marshal(req)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request: %w", err)
+		sig = Signature(dash.Key, method, time.Now(), buf.Bytes())
+		body, contentType = buf, form.FormDataContentType()
+		wait = func() (int, int, error) { return size, compressed, nil }
+	} else {
+		body, contentType, wait = dash.streamForm(method, req, protoReq)
+	}
+	r, err := dash.transport.NewRequest(ctx, "POST", fmt.Sprintf("%v/api", dash.baseURL), body)
+	if err != nil {
+		wait()
+		return err
+	}
+	for name, vals := range header {
+		for _, val := range vals {
+			r.Header.Add(name, val)
 		}
-		gz := gzip.NewWriter(w)
-		if _, err := gz.Write(data); err != nil {
-			return err
+	}
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set(ClientHeader, dash.Client)
+	if !dash.sign && dash.Key != "" {
+		r.Header.Set(KeyHeader, dash.Key)
+	}
+	if sig != "" {
+		r.Header.Set(SignatureHeader, sig)
+	}
+	if dash.proto {
+		r.Header.Set("Accept", ProtoContentType)
+	}
+	// Set explicitly since custom doers don't necessarily ask for compression.
+	// This disables transparent decompression in http.Transport, so replies are decompressed below.
+	r.Header.Set("Accept-Encoding", "gzip")
+	var etagReq string
+	var cached *cachedReply
+	if HasETag(method) {
+		etagReq = etagKey(method, req)
+		if cached = dash.etags.get(etagReq); cached != nil {
+			r.Header.Set("If-None-Match", cached.etag)
 		}
-		if err := gz.Close(); err != nil {
-			return err
+	}
+	resp, err := dash.transport.Do(r)
+	size, compressed, werr := wait()
+	if werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+		if resp != nil {
+			resp.Body.Close()
 		}
+		return werr
 	}
-	mWriter.Close()
-	r, err := dash.ctor("POST", fmt.Sprintf("%v/api", dash.Addr), body)
-	if err != nil {
-		return err
+	if werr == nil {
+		dash.metrics.recordPayload(method, size, compressed)
+		tracePayload(ctx, size, compressed)
 	}
-	r.Header.Set("Content-Type", mWriter.FormDataContentType())
-	resp, err := dash.doer(r)
 	if err != nil {
-		return fmt.Errorf("http request failed: %w", err)
+		return temporaryError(method, fmt.Errorf("http request failed: %w", err))
 	}
 	defer resp.Body.Close()
+	if resp.Header.Get(CredentialsHeader) == HeaderCredentials {
+		dash.headerCreds.Store(true)
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return decodeReply(method, resp.StatusCode, cached.contentType, bytes.NewReader(cached.data), reply)
+	}
 	if resp.StatusCode != http.StatusOK {
 		data, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with %v: %s", resp.Status, data)
+		return statusError(method, resp, data)
+	}
+	if accepts := resp.Header.Get(CompressionHeader); accepts != "" {
+		if AcceptsCompression(accepts, CompressionZstd) {
+			dash.zstdServer.Store(true)
+		}
+		if AcceptsCompression(accepts, CompressionNone) {
+			dash.plainServer.Store(true)
+		}
+	}
+	respBody := io.Reader(resp.Body)
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return temporaryError(method, fmt.Errorf("failed to ungzip response: %w", err))
+		}
+		defer gr.Close()
+		respBody = gr
+	}
+	replyType := resp.Header.Get("Content-Type")
+	if replyType == ProtoContentType {
+		dash.protoServer.Store(true)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && etagReq != "" {
+		data, err := io.ReadAll(respBody)
+		if err != nil {
+			return temporaryError(method, fmt.Errorf("failed to read response: %w", err))
+		}
+		dash.etags.put(etagReq, &cachedReply{etag: etag, contentType: replyType, data: data})
+		respBody = bytes.NewReader(data)
+	}
+	return decodeReply(method, resp.StatusCode, replyType, respBody, reply)
+}
+
+func decodeReply(method string, status int, contentType string, body io.Reader, reply interface{}) error {
+	if contentType == ProtoContentType {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return temporaryError(method, fmt.Errorf("failed to read response: %w", err))
+		}
+		if reply != nil {
+			if err := UnmarshalProto(data, reply); err != nil {
+				return &Error{Method: method, Status: status, Err: fmt.Errorf("failed to unmarshal response: %w", err)}
+			}
+		}
+		return nil
 	}
 	if reply != nil {
-		if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+		if err := json.NewDecoder(body).Decode(reply); err != nil {
+			return &Error{Method: method, Status: status, Err: fmt.Errorf("failed to unmarshal response: %w", err)}
 		}
 	}
 	return nil
@@ -5,7 +5,19 @@ package dashapi
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestNewOpts(t *testing.T) {
@@ -33,9 +45,10 @@ func TestNewOpts(t *testing.T) {
 				t.Fatalf("call to New() returned unexpected error, got: %v, want: nil", err)
 			}
 
-			req, err := dash.ctor("GET", "http://www.example.com", bytes.NewBuffer([]byte("body")))
+			req, err := dash.transport.NewRequest(context.Background(), "GET", "http://www.example.com",
+				bytes.NewBuffer([]byte("body")))
 			if err != nil {
-				t.Errorf("ctor() returned unexpected error, got: %v, want: nil", err)
+				t.Errorf("NewRequest() returned unexpected error, got: %v, want: nil", err)
 			}
 
 			got := req.Header.Get("User-Agent")
@@ -45,3 +58,199 @@ func TestNewOpts(t *testing.T) {
 		})
 	}
 }
+
+// testTransport serves requests with a function instead of sending them.
+type testTransport func(r *http.Request) (*http.Response, error)
+
+func (fn testTransport) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+func (fn testTransport) Do(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// testHandler serves a single API request in tests, payload is the raw JSON request.
+type testHandler func(method string, payload []byte) (interface{}, error)
+
+func testDashboard(t *testing.T, handler testHandler) *Dashboard {
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodGet {
+			// Blob downloads go to real test servers.
+			return http.DefaultClient.Do(r)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		var payload []byte
+		if str := r.PostFormValue("payload"); str != "" {
+			var err error
+			if payload, err = DecompressPayload(r.PostFormValue("compression"), []byte(str)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		reply, err := handler(r.PostFormValue("method"), payload)
+		if err != nil {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Status:     http.StatusText(http.StatusInternalServerError),
+				Body:       io.NopCloser(strings.NewReader(err.Error())),
+			}, nil
+		}
+		data, err := json.Marshal(reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader(data)),
+		}, nil
+	})
+	dash, err := New("client", "http://dashboard", "key", transport, RequestLogger(t.Logf),
+		RetryPolicy{}, RateLimits{}, RequestTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dash
+}
+
+func TestQueryContext(t *testing.T) {
+	// A stuck dashboard must not block the caller once the context is cancelled.
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer srv.Close()
+	defer close(unblock)
+	dash, err := New("client", srv.URL, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = dash.UploadBuild(ctx, &Build{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-unblock
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	defer close(unblock)
+	dash, err := New("client", srv.URL, "key", RequestTimeout(100*time.Millisecond),
+		RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first attempt times out, but the retry succeeds.
+	if err := dash.UploadBuild(context.Background(), &Build{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("got %v calls, want 2", calls.Load())
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (tr *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tr.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	transport := new(countingTransport)
+	dash, err := New("client", srv.URL, "key", &http.Client{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.UploadBuild(context.Background(), &Build{}); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Fatalf("the custom client was not used")
+	}
+}
+
+func TestReportCrashesBatches(t *testing.T) {
+	var batches []int
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if method != "report_crashes" {
+			return nil, fmt.Errorf("unknown api method %q", method)
+		}
+		req := new(ReportCrashesReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, len(req.Crashes))
+		resp := new(ReportCrashesResp)
+		for range req.Crashes {
+			resp.Results = append(resp.Results, &ReportCrashResult{})
+		}
+		return resp, nil
+	})
+	var crashes []*Crash
+	for i := 0; i < MaxCrashBatch+1; i++ {
+		crashes = append(crashes, &Crash{Title: fmt.Sprintf("title%v", i)})
+	}
+	results, err := dash.ReportCrashes(context.Background(), crashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(crashes) {
+		t.Fatalf("got %v results, want %v", len(results), len(crashes))
+	}
+	if diff := cmp.Diff([]int{MaxCrashBatch, 1}, batches); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestUpdateBugsBatches(t *testing.T) {
+	var batches []int
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if method != "update_bugs" {
+			return nil, fmt.Errorf("unknown api method %q", method)
+		}
+		req := new(UpdateBugsReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, len(req.Updates))
+		resp := new(UpdateBugsResp)
+		for _, upd := range req.Updates {
+			resp.Replies = append(resp.Replies, &BugUpdateReply{OK: upd.ID != "bad", Text: upd.ID})
+		}
+		return resp, nil
+	})
+	var updates []*BugUpdate
+	for i := 0; i < MaxBugUpdateBatch+1; i++ {
+		updates = append(updates, &BugUpdate{ID: fmt.Sprintf("id%v", i)})
+	}
+	updates[1].ID = "bad"
+	replies, err := dash.UpdateBugs(context.Background(), updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != len(updates) || !replies[0].OK || replies[1].OK ||
+		replies[MaxBugUpdateBatch].Text != updates[MaxBugUpdateBatch].ID {
+		t.Fatalf("bad replies: %+v", replies)
+	}
+	if diff := cmp.Diff([]int{MaxBugUpdateBatch, 1}, batches); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,791 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package dashapitest provides an in-process fake dashboard for tests of dashapi clients
+// (syz-manager, syz-ci, external reporting).
+// The fake serves all API methods over HTTP with in-memory state, and records all requests,
+// so that tests can assert on exactly which requests were made.
+package dashapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+const (
+	Client = "test-client"
+	Key    = "test-key-test-key-test-key"
+)
+
+// Server is a fake dashboard.
+type Server struct {
+	*httptest.Server
+	t        testing.TB
+	mu       sync.Mutex
+	requests []Request
+	handlers map[string]Handler
+	builds   map[string]*dashapi.Build
+	bugs     map[string]*Bug
+	errors   []*dashapi.BuildErrorReq
+	commits  []dashapi.Commit
+	logs     []*dashapi.LogEntry
+	toolBugs []*dashapi.ToolBugReq
+	stats    []*dashapi.ManagerStatsReq
+	coverage []*dashapi.UploadCoverageReq
+	jobs     []*dashapi.JobPollResp
+	running  []string // IDs of jobs handed out, but not done yet
+	jobsDone []*dashapi.JobDoneReq
+	jobID    int
+	corpus   []*corpusProg // in the order of upload
+	uploads  map[string][]byte
+	replies  map[string]*dashapi.ReportCrashResp // by idempotency key
+	crashID  int64
+}
+
+// Request is an API request received by the fake.
+type Request struct {
+	Method  string
+	Payload []byte // JSON-encoded request, nil for requests without payload
+}
+
+// Decode unmarshals the payload of the request into v.
+func (r *Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Payload, v)
+}
+
+// Bug is the state of a bug on the fake dashboard, bugs are keyed by the crash title.
+type Bug struct {
+	Title        string
+	Status       dashapi.BugStatus
+	ReproLevel   dashapi.ReproLevel
+	NumCrashes   int // including crashes that were only counted
+	FailedRepros int
+	FixCommits   []dashapi.Commit // see ReportFixCommits
+	Crashes      []*dashapi.Crash
+}
+
+// Handler overrides the handling of an API method. The payload is the JSON-encoded request.
+// The reply is encoded as JSON, an error fails the request (see HTTPError).
+// Handlers may call methods of the Server.
+type Handler func(payload []byte) (interface{}, error)
+
+// HTTPError fails a request with the given status code.
+// Other errors returned by Handlers fail requests with http.StatusInternalServerError.
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+func (err *HTTPError) Error() string {
+	return fmt.Sprintf("%v: %v", http.StatusText(err.Code), err.Message)
+}
+
+// MaxFailedRepros is the number of failed repro attempts after which the fake stops asking for repros.
+const MaxFailedRepros = 3
+
+// NewServer starts a fake dashboard, it's stopped at the end of the test.
+func NewServer(t testing.TB) *Server {
+	srv := &Server{
+		t:        t,
+		handlers: make(map[string]Handler),
+		builds:   make(map[string]*dashapi.Build),
+		bugs:     make(map[string]*Bug),
+		uploads:  make(map[string][]byte),
+		replies:  make(map[string]*dashapi.ReportCrashResp),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api", srv.serveAPI)
+	srv.Server = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// NewClient returns a client of the fake dashboard. Retries and rate limits are disabled,
+// opts are applied on top of that.
+func (srv *Server) NewClient(opts ...dashapi.DashboardOpts) *dashapi.Dashboard {
+	opts = append([]dashapi.DashboardOpts{dashapi.RetryPolicy{}, dashapi.RateLimits{}}, opts...)
+	dash, err := dashapi.New(Client, srv.URL, Key, opts...)
+	if err != nil {
+		srv.t.Fatalf("failed to create dashboard client: %v", err)
+	}
+	return dash
+}
+
+// Handle overrides handling of the API method, e.g. to inject errors or unusual replies.
+// A nil handler restores the default handling.
+func (srv *Server) Handle(method string, handler Handler) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if handler == nil {
+		delete(srv.handlers, method)
+	} else {
+		srv.handlers[method] = handler
+	}
+}
+
+// Requests returns the requests received so far, only of the given methods if any are passed.
+func (srv *Server) Requests(methods ...string) []Request {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var res []Request
+	for _, req := range srv.requests {
+		if len(methods) == 0 || slices.Contains(methods, req.Method) {
+			res = append(res, req)
+		}
+	}
+	return res
+}
+
+// Methods returns the methods of the requests received so far, in order.
+func (srv *Server) Methods() []string {
+	var res []string
+	for _, req := range srv.Requests() {
+		res = append(res, req.Method)
+	}
+	return res
+}
+
+// Build returns the uploaded build with the given ID, or nil.
+func (srv *Server) Build(id string) *dashapi.Build {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.builds[id]
+}
+
+// Bug returns a copy of the bug with the given title, or nil.
+func (srv *Server) Bug(title string) *Bug {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	bug := srv.bugs[title]
+	if bug == nil {
+		return nil
+	}
+	res := *bug
+	res.Crashes = append([]*dashapi.Crash(nil), bug.Crashes...)
+	return &res
+}
+
+// Bugs returns the titles of all bugs, sorted.
+func (srv *Server) Bugs() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var titles []string
+	for title := range srv.bugs {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+// UpdateBug changes the bug with the given title, the bug is created if it does not exist.
+func (srv *Server) UpdateBug(title string, fn func(*Bug)) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	fn(srv.bug(title))
+}
+
+// BuildErrors returns the reported build errors.
+func (srv *Server) BuildErrors() []*dashapi.BuildErrorReq {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.BuildErrorReq(nil), srv.errors...)
+}
+
+// Commits returns the uploaded commits.
+func (srv *Server) Commits() []dashapi.Commit {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]dashapi.Commit(nil), srv.commits...)
+}
+
+// Logs returns the entries sent with LogError.
+func (srv *Server) Logs() []*dashapi.LogEntry {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.LogEntry(nil), srv.logs...)
+}
+
+// ToolBugs returns the reported tool bugs.
+func (srv *Server) ToolBugs() []*dashapi.ToolBugReq {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.ToolBugReq(nil), srv.toolBugs...)
+}
+
+// ManagerStats returns the uploaded manager stats.
+func (srv *Server) ManagerStats() []*dashapi.ManagerStatsReq {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.ManagerStatsReq(nil), srv.stats...)
+}
+
+// Coverage returns the uploaded manager coverage.
+func (srv *Server) Coverage() []*dashapi.UploadCoverageReq {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.UploadCoverageReq(nil), srv.coverage...)
+}
+
+// QueueJob adds a job (patch testing or bisection) that is handed out by JobPoll
+// to a manager with the job's Manager name. A job without ID gets a unique one, which is returned.
+func (srv *Server) QueueJob(orig *dashapi.JobPollResp) string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	job := new(dashapi.JobPollResp)
+	*job = *orig
+	if job.ID == "" {
+		srv.jobID++
+		job.ID = fmt.Sprintf("job%v", srv.jobID)
+	}
+	srv.jobs = append(srv.jobs, job)
+	return job.ID
+}
+
+// JobResults returns the results of the jobs reported with JobDone.
+func (srv *Server) JobResults() []*dashapi.JobDoneReq {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]*dashapi.JobDoneReq(nil), srv.jobsDone...)
+}
+
+// Corpus returns the programs of the corpus uploaded by all managers, in the order of upload.
+func (srv *Server) Corpus() []*dashapi.CorpusProg {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var res []*dashapi.CorpusProg
+	for _, prog := range srv.corpus {
+		if len(prog.managers) != 0 {
+			progCopy := prog.CorpusProg
+			res = append(res, &progCopy)
+		}
+	}
+	return res
+}
+
+type corpusProg struct {
+	dashapi.CorpusProg
+	managers []string // programs removed by all managers are kept, but are not returned
+}
+
+func (srv *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(dashapi.CompressionHeader, "gzip, zstd, none")
+	w.Header().Set(dashapi.CredentialsHeader, dashapi.HeaderCredentials)
+	reply, err := srv.handleAPI(r)
+	if err != nil {
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			httpErr = &HTTPError{http.StatusInternalServerError, err.Error()}
+		}
+		http.Error(w, httpErr.Message, httpErr.Code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reply); err != nil {
+		srv.t.Errorf("failed to encode reply: %v", err)
+	}
+}
+
+func (srv *Server) handleAPI(r *http.Request) (interface{}, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := r.ParseMultipartForm(64 << 20); err != nil {
+		return nil, &HTTPError{http.StatusBadRequest, err.Error()}
+	}
+	method := r.PostFormValue("method")
+	var payload []byte
+	if str := r.PostFormValue("payload"); str != "" {
+		if payload, err = dashapi.DecompressPayload(r.PostFormValue("compression"), []byte(str)); err != nil {
+			return nil, &HTTPError{http.StatusBadRequest, err.Error()}
+		}
+	}
+	srv.mu.Lock()
+	srv.requests = append(srv.requests, Request{Method: method, Payload: payload})
+	handler := srv.handlers[method]
+	srv.mu.Unlock()
+	if credential(r, dashapi.ClientHeader, "client") != Client {
+		return nil, &HTTPError{http.StatusForbidden, "unknown api client"}
+	}
+	if sig := r.Header.Get(dashapi.SignatureHeader); sig != "" {
+		err = dashapi.CheckSignature(sig, Key, method, time.Now(), body)
+	} else if credential(r, dashapi.KeyHeader, "key") != Key {
+		err = errors.New("wrong api key")
+	}
+	if err != nil {
+		return nil, &HTTPError{http.StatusForbidden, err.Error()}
+	}
+	if handler != nil {
+		return handler(payload)
+	}
+	if apiHandlers[method] == nil {
+		return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("unknown api method %q", method)}
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return apiHandlers[method](srv, payload)
+}
+
+func credential(r *http.Request, header, field string) string {
+	if val := r.Header.Get(header); val != "" {
+		return val
+	}
+	return r.PostFormValue(field)
+}
+
+type apiHandler func(srv *Server, payload []byte) (interface{}, error)
+
+// apiHandlers are called with srv.mu held.
+var apiHandlers = map[string]apiHandler{
+	"upload_build":          typed(apiUploadBuild),
+	"builder_poll":          typed(apiBuilderPoll),
+	"build_commits":         typed(apiBuildCommits),
+	"report_build_error":    typed(apiReportBuildError),
+	"report_crash":          typed(apiReportCrash),
+	"report_crashes":        typed(apiReportCrashes),
+	"update_crash":          notFound,
+	"upload_chunk":          typed(apiUploadChunk),
+	"count_crash":           typed(apiCountCrash),
+	"need_repro":            typed(apiNeedRepro),
+	"report_failed_repro":   typed(apiReportFailedRepro),
+	"bug_status":            typed(apiBugStatus),
+	"report_fix_commits":    typed(apiReportFixCommits),
+	"bug_list":              apiBugList,
+	"open_bugs":             apiOpenBugs,
+	"manager_notifs":        empty(&dashapi.ManagerNotifsResp{}),
+	"manager_commands":      empty(&dashapi.ManagerCommandsResp{}),
+	"ack_manager_command":   notFound,
+	"retest_result":         notFound,
+	"report_tool_bug":       typed(apiReportToolBug),
+	"log_error":             typed(apiLogError),
+	"manager_stats":         typed(apiManagerStats),
+	"manager_syscalls":      empty(nil),
+	"repro_progress":        empty(nil),
+	"upload_stats_series":   empty(nil),
+	"stats_series":          empty(&dashapi.StatsSeriesResp{}),
+	"upload_coverage":       typed(apiUploadCoverage),
+	"upload_corpus":         typed(apiUploadCorpus),
+	"download_corpus":       typed(apiDownloadCorpus),
+	"asset_upload_urls":     apiAssetUploadURLs,
+	"commit_poll":           empty(&dashapi.CommitPollResp{}),
+	"upload_commits":        typed(apiUploadCommits),
+	"repos_poll":            empty(&dashapi.ReposResp{}),
+	"manager_config":        empty(&dashapi.ManagerConfigResp{}),
+	"log_to_repro":          empty(&dashapi.LogToReproResp{}),
+	"repro_task_poll":       empty(&dashapi.ReproTaskPollResp{}),
+	"repro_task_done":       empty(nil),
+	"claim_repro":           empty(&dashapi.ReproClaimResp{Claimed: true}),
+	"release_repro_claim":   empty(nil),
+	"job_poll":              typed(apiJobPoll),
+	"job_done":              typed(apiJobDone),
+	"job_reset":             empty(nil),
+	"queue_bisect":          empty(nil),
+	"add_build_assets":      empty(nil),
+	"needed_assets":         empty(&dashapi.NeededAssetsResp{}),
+	"update_report":         empty(nil),
+	"save_discussion":       empty(nil),
+	"incoming_email":        empty(nil),
+	"save_coverage":         empty(nil),
+	"reporting_poll_bugs":   empty(&dashapi.PollBugsResponse{}),
+	"reporting_poll_notifs": empty(&dashapi.PollNotificationsResponse{}),
+	"reporting_poll_closed": empty(&dashapi.PollClosedResponse{}),
+	"reporting_update":      empty(&dashapi.BugUpdateReply{OK: true}),
+	"update_bugs":           typed(apiUpdateBugs),
+	"new_test_job":          empty(&dashapi.TestPatchReply{}),
+	"load_bug":              notFound,
+	"load_full_bug":         notFound,
+	"get_repro":             notFound,
+	"add_bug_note":          notFound,
+	"set_ext_id":            notFound,
+	"lookup_ext_id":         empty(&dashapi.LookupExtIDResp{}),
+}
+
+func init() {
+	// Registered here because the handler lists the other handlers.
+	apiHandlers["capabilities"] = apiCapabilities
+}
+
+func typed[Req any](fn func(srv *Server, req *Req) (interface{}, error)) apiHandler {
+	return func(srv *Server, payload []byte) (interface{}, error) {
+		req := new(Req)
+		if err := json.Unmarshal(payload, req); err != nil {
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("failed to unmarshal request: %v", err)}
+		}
+		return fn(srv, req)
+	}
+}
+
+// empty handles methods that the fake does not keep state for.
+func empty(reply interface{}) apiHandler {
+	return func(srv *Server, payload []byte) (interface{}, error) {
+		return reply, nil
+	}
+}
+
+func notFound(srv *Server, payload []byte) (interface{}, error) {
+	return nil, &HTTPError{http.StatusNotFound, "the bug does not exist"}
+}
+
+func apiCapabilities(srv *Server, payload []byte) (interface{}, error) {
+	resp := &dashapi.CapabilitiesResp{
+		Version:  dashapi.APIVersion,
+		Features: []string{dashapi.FeatureZstd, dashapi.FeatureUncompressed, dashapi.FeatureIdempotency},
+	}
+	for method := range apiHandlers {
+		resp.Methods = append(resp.Methods, method)
+	}
+	sort.Strings(resp.Methods)
+	return resp, nil
+}
+
+func apiUploadBuild(srv *Server, req *dashapi.Build) (interface{}, error) {
+	srv.builds[req.ID] = req
+	return nil, nil
+}
+
+func apiBuilderPoll(srv *Server, req *dashapi.BuilderPollReq) (interface{}, error) {
+	return &dashapi.BuilderPollResp{}, nil
+}
+
+func apiBuildCommits(srv *Server, req *dashapi.BuildCommitsReq) (interface{}, error) {
+	build := srv.builds[req.BuildID]
+	if build == nil {
+		return nil, &HTTPError{http.StatusNotFound, fmt.Sprintf("unknown build %q", req.BuildID)}
+	}
+	// Builds returned by Build may be used concurrently, so update a copy.
+	updated := *build
+	updated.Commits = append(append([]string{}, build.Commits...), req.Commits...)
+	updated.FixCommits = append(append([]dashapi.Commit{}, build.FixCommits...), req.FixCommits...)
+	srv.builds[req.BuildID] = &updated
+	return nil, nil
+}
+
+func apiReportBuildError(srv *Server, req *dashapi.BuildErrorReq) (interface{}, error) {
+	srv.builds[req.Build.ID] = &req.Build
+	srv.errors = append(srv.errors, req)
+	return nil, nil
+}
+
+func apiReportCrash(srv *Server, req *dashapi.Crash) (interface{}, error) {
+	return srv.reportCrash(req)
+}
+
+func apiReportCrashes(srv *Server, req *dashapi.ReportCrashesReq) (interface{}, error) {
+	resp := &dashapi.ReportCrashesResp{}
+	for _, crash := range req.Crashes {
+		res := new(dashapi.ReportCrashResult)
+		if crashResp, err := srv.reportCrash(crash); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.ReportCrashResp = *crashResp
+		}
+		resp.Results = append(resp.Results, res)
+	}
+	return resp, nil
+}
+
+func (srv *Server) reportCrash(crash *dashapi.Crash) (*dashapi.ReportCrashResp, error) {
+	if resp := srv.replies[crash.IdempotencyKey]; resp != nil {
+		return resp, nil
+	}
+	if srv.builds[crash.BuildID] == nil {
+		return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("unknown build %q", crash.BuildID)}
+	}
+	for _, ref := range crash.ChunkRefs {
+		data, ok := srv.uploads[ref.UploadID]
+		if !ok {
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("unknown upload %v", ref.UploadID)}
+		}
+		switch ref.Field {
+		case "Log":
+			crash.Log = data
+		case "Report":
+			crash.Report = data
+		default:
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("bad chunk field %q", ref.Field)}
+		}
+	}
+	crash.ChunkRefs = nil
+	bug := srv.bug(crash.Title)
+	bug.NumCrashes++
+	bug.Crashes = append(bug.Crashes, crash)
+	switch {
+	case len(crash.ReproC) != 0:
+		bug.ReproLevel = max(bug.ReproLevel, dashapi.ReproLevelC)
+	case len(crash.ReproSyz) != 0:
+		bug.ReproLevel = max(bug.ReproLevel, dashapi.ReproLevelSyz)
+	}
+	srv.crashID++
+	resp := &dashapi.ReportCrashResp{
+		NeedRepro:  bug.needRepro(),
+		CrashID:    srv.crashID,
+		ReproLevel: bug.ReproLevel,
+	}
+	if crash.IdempotencyKey != "" {
+		srv.replies[crash.IdempotencyKey] = resp
+	}
+	return resp, nil
+}
+
+func apiUploadChunk(srv *Server, req *dashapi.UploadChunkReq) (interface{}, error) {
+	data := srv.uploads[req.UploadID]
+	if len(req.Data) != 0 {
+		if req.Offset != int64(len(data)) {
+			return nil, &HTTPError{http.StatusBadRequest,
+				fmt.Sprintf("chunk offset %v, want %v", req.Offset, len(data))}
+		}
+		if int64(len(data)+len(req.Data)) > req.Size {
+			return nil, &HTTPError{http.StatusRequestEntityTooLarge, "the upload is larger than its size"}
+		}
+		data = append(data, req.Data...)
+	}
+	srv.uploads[req.UploadID] = data
+	return &dashapi.UploadChunkResp{Received: int64(len(data))}, nil
+}
+
+func apiCountCrash(srv *Server, req *dashapi.CrashID) (interface{}, error) {
+	bug := srv.bugs[req.Title]
+	if bug == nil || bug.Status != dashapi.BugStatusOpen {
+		return &dashapi.CountCrashResp{}, nil
+	}
+	bug.NumCrashes++
+	return &dashapi.CountCrashResp{Found: true, NeedRepro: bug.needRepro(), ReproLevel: bug.ReproLevel}, nil
+}
+
+func apiNeedRepro(srv *Server, req *dashapi.CrashID) (interface{}, error) {
+	bug := srv.bugs[req.Title]
+	if bug == nil {
+		return &dashapi.NeedReproResp{NeedRepro: !req.MayBeMissing}, nil
+	}
+	return &dashapi.NeedReproResp{NeedRepro: bug.needRepro(), ReproLevel: bug.ReproLevel}, nil
+}
+
+func apiReportFailedRepro(srv *Server, req *dashapi.CrashID) (interface{}, error) {
+	srv.bug(req.Title).FailedRepros++
+	return nil, nil
+}
+
+func apiReportFixCommits(srv *Server, req *dashapi.ReportFixCommitsReq) (interface{}, error) {
+	bug := srv.bugs[req.Title]
+	if bug == nil || bug.Status != dashapi.BugStatusOpen {
+		return nil, &HTTPError{http.StatusNotFound, "no open bug"}
+	}
+	bug.FixCommits = req.Commits
+	return nil, nil
+}
+
+func apiBugStatus(srv *Server, req *dashapi.BugStatusReq) (interface{}, error) {
+	resp := &dashapi.BugStatusResp{}
+	for _, title := range req.Titles {
+		info := &dashapi.BugStatusInfo{Title: title}
+		if bug := srv.bugs[title]; bug != nil {
+			info.Found = true
+			info.Active = bug.Status == dashapi.BugStatusOpen
+			info.Status = bug.Status
+			info.ReproLevel = bug.ReproLevel
+		}
+		resp.Bugs = append(resp.Bugs, info)
+	}
+	return resp, nil
+}
+
+func apiBugList(srv *Server, payload []byte) (interface{}, error) {
+	resp := &dashapi.BugListResp{}
+	for title := range srv.bugs {
+		resp.List = append(resp.List, title)
+	}
+	sort.Strings(resp.List)
+	return resp, nil
+}
+
+func apiOpenBugs(srv *Server, payload []byte) (interface{}, error) {
+	req := new(dashapi.OpenBugsReq)
+	if len(payload) != 0 {
+		if err := json.Unmarshal(payload, req); err != nil {
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("failed to unmarshal request: %v", err)}
+		}
+	}
+	resp := &dashapi.OpenBugsResp{}
+	for title, bug := range srv.bugs {
+		if bug.Status != dashapi.BugStatusOpen {
+			continue
+		}
+		info := &dashapi.OpenBug{
+			ID:         title,
+			Title:      title,
+			ReproLevel: bug.ReproLevel,
+			Subsystems: bug.subsystems(),
+		}
+		if req.Subsystem != "" && !slices.Contains(info.Subsystems, req.Subsystem) {
+			continue
+		}
+		for _, com := range bug.FixCommits {
+			info.FixCommits = append(info.FixCommits, com.Title)
+		}
+		resp.Bugs = append(resp.Bugs, info)
+	}
+	sort.Slice(resp.Bugs, func(i, j int) bool {
+		return resp.Bugs[i].Title < resp.Bugs[j].Title
+	})
+	return resp, nil
+}
+
+func apiJobPoll(srv *Server, req *dashapi.JobPollReq) (interface{}, error) {
+	for i, job := range srv.jobs {
+		mgr, ok := req.Managers[job.Manager]
+		if !ok || !mgr.TestPatches && job.Type == dashapi.JobTestPatch ||
+			!mgr.BisectCause && job.Type == dashapi.JobBisectCause ||
+			!mgr.BisectFix && job.Type == dashapi.JobBisectFix {
+			continue
+		}
+		srv.jobs = slices.Delete(srv.jobs, i, i+1)
+		srv.running = append(srv.running, job.ID)
+		return job, nil
+	}
+	return &dashapi.JobPollResp{}, nil
+}
+
+func apiJobDone(srv *Server, req *dashapi.JobDoneReq) (interface{}, error) {
+	i := slices.Index(srv.running, req.ID)
+	if i == -1 {
+		return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("unknown job %q", req.ID)}
+	}
+	srv.running = slices.Delete(srv.running, i, i+1)
+	srv.jobsDone = append(srv.jobsDone, req)
+	return nil, nil
+}
+
+func apiUploadCorpus(srv *Server, req *dashapi.UploadCorpusReq) (interface{}, error) {
+	resp := &dashapi.UploadCorpusResp{}
+	for _, add := range req.Add {
+		i := slices.IndexFunc(srv.corpus, func(prog *corpusProg) bool { return prog.Hash == add.Hash })
+		switch {
+		case i != -1:
+			prog := srv.corpus[i]
+			if !slices.Contains(prog.managers, req.Manager) {
+				prog.managers = append(prog.managers, req.Manager)
+			}
+			prog.Minimized = prog.Minimized || add.Minimized
+		case len(add.Prog) == 0:
+			resp.Missing = append(resp.Missing, add.Hash)
+		case dashapi.CorpusProgHash(add.Prog) != add.Hash:
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("wrong program hash %q", add.Hash)}
+		default:
+			prog := &corpusProg{CorpusProg: *add, managers: []string{req.Manager}}
+			prog.Manager = req.Manager
+			srv.corpus = append(srv.corpus, prog)
+		}
+	}
+	for _, prog := range srv.corpus {
+		if slices.Contains(req.Del, prog.Hash) {
+			prog.managers = slices.DeleteFunc(prog.managers, func(mgr string) bool { return mgr == req.Manager })
+		}
+	}
+	return resp, nil
+}
+
+// apiDownloadCorpus uses indices in srv.corpus as cursors.
+func apiDownloadCorpus(srv *Server, req *dashapi.DownloadCorpusReq) (interface{}, error) {
+	start := 0
+	if req.Cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(req.Cursor); err != nil || start > len(srv.corpus) {
+			return nil, &HTTPError{http.StatusBadRequest, fmt.Sprintf("bad cursor %q", req.Cursor)}
+		}
+	}
+	limit := req.Max
+	if limit <= 0 {
+		limit = 100
+	}
+	end := min(start+limit, len(srv.corpus))
+	resp := &dashapi.DownloadCorpusResp{
+		Cursor: strconv.Itoa(end),
+		More:   end < len(srv.corpus),
+	}
+	for _, prog := range srv.corpus[start:end] {
+		if len(prog.managers) != 0 && !slices.Contains(prog.managers, req.Manager) {
+			progCopy := prog.CorpusProg
+			resp.Progs = append(resp.Progs, &progCopy)
+		}
+	}
+	return resp, nil
+}
+
+// The fake has no asset storage, tests that need it can Handle asset_upload_urls.
+func apiAssetUploadURLs(srv *Server, payload []byte) (interface{}, error) {
+	return nil, &HTTPError{http.StatusNotFound, "asset uploads are not configured"}
+}
+
+func apiReportToolBug(srv *Server, req *dashapi.ToolBugReq) (interface{}, error) {
+	srv.toolBugs = append(srv.toolBugs, req)
+	return nil, nil
+}
+
+func apiLogError(srv *Server, req *dashapi.LogEntry) (interface{}, error) {
+	srv.logs = append(srv.logs, req)
+	return nil, nil
+}
+
+func apiManagerStats(srv *Server, req *dashapi.ManagerStatsReq) (interface{}, error) {
+	srv.stats = append(srv.stats, req)
+	return nil, nil
+}
+
+func apiUploadCoverage(srv *Server, req *dashapi.UploadCoverageReq) (interface{}, error) {
+	srv.coverage = append(srv.coverage, req)
+	return nil, nil
+}
+
+func apiUploadCommits(srv *Server, req *dashapi.CommitPollResultReq) (interface{}, error) {
+	srv.commits = append(srv.commits, req.Commits...)
+	return nil, nil
+}
+
+func apiUpdateBugs(srv *Server, req *dashapi.UpdateBugsReq) (interface{}, error) {
+	resp := new(dashapi.UpdateBugsResp)
+	for range req.Updates {
+		resp.Replies = append(resp.Replies, &dashapi.BugUpdateReply{OK: true})
+	}
+	return resp, nil
+}
+
+func (srv *Server) bug(title string) *Bug {
+	bug := srv.bugs[title]
+	if bug == nil {
+		bug = &Bug{Title: title}
+		srv.bugs[title] = bug
+	}
+	return bug
+}
+
+func (bug *Bug) needRepro() bool {
+	return bug.Status == dashapi.BugStatusOpen && bug.ReproLevel != dashapi.ReproLevelC &&
+		bug.FailedRepros < MaxFailedRepros
+}
+
+// subsystems returns the subsystems suggested by the bug crashes.
+func (bug *Bug) subsystems() []string {
+	var ret []string
+	for _, crash := range bug.Crashes {
+		for _, name := range crash.Subsystems {
+			if !slices.Contains(ret, name) {
+				ret = append(ret, name)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,235 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapitest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t)
+	dash := srv.NewClient(dashapi.ChunkedUpload{Threshold: 100, ChunkSize: 64})
+	ctx := context.Background()
+	if err := dash.UploadBuild(ctx, &dashapi.Build{ID: "build1", Manager: "manager"}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Build("build1") == nil {
+		t.Fatalf("the build was not saved")
+	}
+	err := dash.UploadBuildCommits(ctx, &dashapi.BuildCommitsReq{
+		Manager: "manager",
+		BuildID: "build1",
+		Commits: []string{"fix title"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commits := srv.Build("build1").Commits; !slices.Equal(commits, []string{"fix title"}) {
+		t.Fatalf("bad build commits: %q", commits)
+	}
+	err = dash.UploadBuildCommits(ctx, &dashapi.BuildCommitsReq{Manager: "manager", BuildID: "unknown"})
+	if !errors.Is(err, dashapi.ErrNotFound) {
+		t.Fatalf("commits of an unknown build: %v", err)
+	}
+	log := bytes.Repeat([]byte("log line\n"), 100)
+	resp, err := dash.ReportCrash(ctx, &dashapi.Crash{
+		BuildID:    "build1",
+		Title:      "crash",
+		Log:        log,
+		Subsystems: []string{"net"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.NeedRepro || resp.CrashID == 0 {
+		t.Fatalf("bad reply: %+v", resp)
+	}
+	bug := srv.Bug("crash")
+	if bug == nil || len(bug.Crashes) != 1 || !bytes.Equal(bug.Crashes[0].Log, log) {
+		t.Fatalf("the crash was not saved: %+v", bug)
+	}
+	if _, err := dash.ReportCrash(ctx, &dashapi.Crash{BuildID: "unknown", Title: "crash"}); err == nil {
+		t.Fatalf("a crash on an unknown build was accepted")
+	}
+
+	crashID := &dashapi.CrashID{BuildID: "build1", Title: "crash"}
+	for i := 0; i < MaxFailedRepros; i++ {
+		if err := dash.ReportFailedRepro(ctx, crashID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, err := dash.CountCrash(ctx, crashID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !count.Found || count.NeedRepro {
+		t.Fatalf("bad count_crash reply: %+v", count)
+	}
+	open, err := dash.OpenBugs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open.Bugs) != 1 || open.Bugs[0].Title != "crash" {
+		t.Fatalf("bad open_bugs reply: %+v", open.Bugs)
+	}
+	if open, err := dash.OpenBugs(ctx, dashapi.OnlySubsystem("net")); err != nil || len(open.Bugs) != 1 {
+		t.Fatalf("bad open_bugs reply for the bug subsystem: %+v, %v", open, err)
+	}
+	if open, err := dash.OpenBugs(ctx, dashapi.OnlySubsystem("fs")); err != nil || len(open.Bugs) != 0 {
+		t.Fatalf("bad open_bugs reply for another subsystem: %+v, %v", open, err)
+	}
+	srv.UpdateBug("crash", func(bug *Bug) { bug.Status = dashapi.BugStatusFixed })
+	status, err := dash.BugStatus(ctx, []string{"crash", "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Bugs[0].Found || status.Bugs[0].Active || status.Bugs[1].Found {
+		t.Fatalf("bad bug_status reply: %+v %+v", status.Bugs[0], status.Bugs[1])
+	}
+	if srv.Bug("crash").NumCrashes != 2 {
+		t.Fatalf("bad number of crashes: %+v", srv.Bug("crash"))
+	}
+
+	// Repeated requests (chunks, failed repros) are collapsed.
+	want := []string{"upload_build", "build_commits", "upload_chunk", "report_crash", "report_failed_repro",
+		"count_crash", "open_bugs", "bug_status"}
+	if got := slices.Compact(srv.Methods()); !slices.Equal(got, want) {
+		t.Fatalf("got requests %q, want %q", got, want)
+	}
+}
+
+func TestServerCustomHandler(t *testing.T) {
+	srv := NewServer(t)
+	dash := srv.NewClient(dashapi.SignRequests(true))
+	srv.Handle("upload_build", func(payload []byte) (interface{}, error) {
+		return nil, &HTTPError{http.StatusGone, "the manager is decommissioned"}
+	})
+	err := dash.UploadBuild(context.Background(), &dashapi.Build{ID: "build"})
+	if !errors.Is(err, dashapi.ErrClientRetired) {
+		t.Fatalf("got %v, want ErrClientRetired", err)
+	}
+	srv.Handle("upload_build", nil)
+	if err := dash.UploadBuild(context.Background(), &dashapi.Build{ID: "build"}); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.Requests("upload_build")
+	var build dashapi.Build
+	if len(reqs) != 2 || reqs[1].Decode(&build) != nil || build.ID != "build" {
+		t.Fatalf("bad requests: %+v", reqs)
+	}
+
+	other, err := dashapi.New("client", srv.URL, "wrong key", dashapi.RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.UploadBuild(context.Background(), &dashapi.Build{}); !errors.Is(err, dashapi.ErrAccessDenied) {
+		t.Fatalf("got %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestServerJobs(t *testing.T) {
+	srv := NewServer(t)
+	dash := srv.NewClient()
+	ctx := context.Background()
+	id := srv.QueueJob(&dashapi.JobPollResp{
+		Type:         dashapi.JobTestPatch,
+		Manager:      "manager",
+		KernelCommit: "commit",
+		Patch:        []byte("patch"),
+	})
+	poll := func(jobs dashapi.ManagerJobs) *dashapi.JobPollResp {
+		t.Helper()
+		job, err := dash.JobPoll(ctx, &dashapi.JobPollReq{
+			Managers: map[string]dashapi.ManagerJobs{"manager": jobs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+	if job := poll(dashapi.ManagerJobs{BisectCause: true}); job.ID != "" {
+		t.Fatalf("got a job the manager does not do: %+v", job)
+	}
+	job := poll(dashapi.ManagerJobs{TestPatches: true})
+	if job.ID != id || job.KernelCommit != "commit" || string(job.Patch) != "patch" {
+		t.Fatalf("bad job: %+v", job)
+	}
+	if job := poll(dashapi.ManagerJobs{TestPatches: true}); job.ID != "" {
+		t.Fatalf("the job was handed out twice: %+v", job)
+	}
+	if err := dash.JobDone(ctx, &dashapi.JobDoneReq{ID: id, Error: []byte("build failed")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.JobDone(ctx, &dashapi.JobDoneReq{ID: id}); !errors.Is(err, dashapi.ErrBadRequest) {
+		t.Fatalf("got %v, want ErrBadRequest", err)
+	}
+	res := srv.JobResults()
+	if len(res) != 1 || res[0].ID != id || string(res[0].Error) != "build failed" {
+		t.Fatalf("bad job results: %+v", res)
+	}
+}
+
+func TestServerCorpus(t *testing.T) {
+	srv := NewServer(t)
+	dash := srv.NewClient()
+	ctx := context.Background()
+	var progs []*dashapi.CorpusProg
+	for i := 0; i < dashapi.MaxCorpusUpload+10; i++ {
+		progs = append(progs, &dashapi.CorpusProg{Prog: []byte(fmt.Sprintf("getpid%v()", i))})
+	}
+	if err := dash.UploadCorpus(ctx, &dashapi.UploadCorpusReq{Manager: "mgr1", Add: progs}); err != nil {
+		t.Fatal(err)
+	}
+	// The programs are sent by hash in 2 batches, and then with the contents in 2 batches.
+	if reqs := srv.Requests("upload_corpus"); len(reqs) != 4 {
+		t.Fatalf("got %v upload_corpus requests, want 4", len(reqs))
+	}
+	// The second manager already has some of the programs, they are not uploaded again.
+	err := dash.UploadCorpus(ctx, &dashapi.UploadCorpusReq{
+		Manager: "mgr2",
+		Add:     append(progs[:1:1], &dashapi.CorpusProg{Prog: []byte("getuid()")}),
+		Del:     []string{dashapi.CorpusProgHash([]byte("unknown()"))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req dashapi.UploadCorpusReq
+	if reqs := srv.Requests("upload_corpus"); len(reqs) != 6 || reqs[5].Decode(&req) != nil ||
+		len(req.Add) != 1 || string(req.Add[0].Prog) != "getuid()" {
+		t.Fatalf("bad upload_corpus requests: %+v", reqs)
+	}
+	if len(srv.Corpus()) != len(progs)+1 {
+		t.Fatalf("got %v corpus programs, want %v", len(srv.Corpus()), len(progs)+1)
+	}
+
+	// The second manager gets all programs, except for the ones it has uploaded.
+	var got []string
+	for cursor := ""; ; {
+		resp, err := dash.DownloadCorpus(ctx, &dashapi.DownloadCorpusReq{Manager: "mgr2", Cursor: cursor, Max: 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, prog := range resp.Progs {
+			if prog.Manager != "mgr1" {
+				t.Fatalf("bad program manager: %+v", prog)
+			}
+			got = append(got, string(prog.Prog))
+		}
+		if !resp.More {
+			break
+		}
+		cursor = resp.Cursor
+	}
+	if len(got) != len(progs)-1 || got[0] != "getpid1()" {
+		t.Fatalf("got programs %q", got)
+	}
+}
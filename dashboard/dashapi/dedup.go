@@ -0,0 +1,143 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// BlobDedup makes the client skip uploading large blobs that the dashboard already has:
+// kernel configs of builds (UploadBuild, ReportBuildError and JobDone) and logs of build errors.
+// Before such a request the client asks the dashboard (HasBlobs) whether it knows the blob hashes,
+// and the known blobs are replaced with BlobHashes. Hashes of blobs that were uploaded are remembered,
+// so repeated uploads of the same kernel config don't need the extra request.
+// Blobs are deduplicated only for dashboards that support FeatureBlobDedup and only
+// without PayloadKeys (encrypted blobs never repeat).
+type BlobDedup struct {
+	MinSize int // smaller blobs are always sent, 0 means DefaultBlobDedupSize
+}
+
+const DefaultBlobDedupSize = 64 << 10
+
+// BlobHash refers to a blob that the dashboard already has.
+type BlobHash struct {
+	Field  string // name of the []byte field of the request that the blob belongs to
+	SHA256 string // hex-encoded
+}
+
+type HasBlobsReq struct {
+	Hashes []string // hex-encoded SHA256 of the blobs
+}
+
+type HasBlobsResp struct {
+	Known []string // the hashes of blobs the dashboard has
+}
+
+// HasBlobs returns the hashes the dashboard has blobs for.
+func (dash *Dashboard) HasBlobs(ctx context.Context, hashes []string) ([]string, error) {
+	resp := new(HasBlobsResp)
+	err := dash.Query(ctx, "has_blobs", &HasBlobsReq{Hashes: hashes}, resp)
+	return resp.Known, err
+}
+
+// maxKnownBlobs bounds the number of remembered blob hashes.
+const maxKnownBlobs = 1000
+
+type blobDedup struct {
+	minSize int
+	mu      sync.Mutex
+	known   map[string]bool
+}
+
+func newBlobDedup(cfg *BlobDedup) *blobDedup {
+	dd := &blobDedup{
+		minSize: cfg.MinSize,
+		known:   make(map[string]bool),
+	}
+	if dd.minSize == 0 {
+		dd.minSize = DefaultBlobDedupSize
+	}
+	return dd
+}
+
+// dedupBlob is called by request constructors passed to queryDedup for each blob field of the request.
+// It may replace the blob with a BlobHash.
+type dedupBlob func(field string, data *[]byte, hashes *[]BlobHash)
+
+// queryDedup sends the request returned by makeReq with the blobs the dashboard already has replaced
+// with hashes. If the dashboard has lost some of the blobs in the meantime, the request is repeated
+// with all blobs.
+func (dash *Dashboard) queryDedup(ctx context.Context, method string, makeReq func(dedupBlob) interface{}) error {
+	dd := dash.dedup
+	if dd == nil {
+		return dash.Query(ctx, method, makeReq(func(string, *[]byte, *[]BlobHash) {}), nil)
+	}
+	if caps, err := dash.Capabilities(ctx); err != nil || !caps.Supports(FeatureBlobDedup) {
+		return dash.Query(ctx, method, makeReq(func(string, *[]byte, *[]BlobHash) {}), nil)
+	}
+	var sent, stripped []string
+	req := makeReq(func(field string, data *[]byte, hashes *[]BlobHash) {
+		if len(*data) < dd.minSize {
+			return
+		}
+		sum := sha256.Sum256(*data)
+		hash := hex.EncodeToString(sum[:])
+		if !dd.has(ctx, dash, hash) {
+			sent = append(sent, hash)
+			return
+		}
+		stripped = append(stripped, hash)
+		*hashes = append(*hashes, BlobHash{Field: field, SHA256: hash})
+		*data = nil
+	})
+	err := dash.Query(ctx, method, req, nil)
+	if len(stripped) != 0 && errors.Is(err, ErrNotFound) {
+		dd.forget(stripped)
+		sent = append(sent, stripped...)
+		err = dash.Query(ctx, method, makeReq(func(string, *[]byte, *[]BlobHash) {}), nil)
+	}
+	if err == nil {
+		dd.add(sent)
+	}
+	return err
+}
+
+// has returns if the dashboard has the blob, errors are treated as "no".
+func (dd *blobDedup) has(ctx context.Context, dash *Dashboard, hash string) bool {
+	dd.mu.Lock()
+	known := dd.known[hash]
+	dd.mu.Unlock()
+	if known {
+		return true
+	}
+	hashes, err := dash.HasBlobs(ctx, []string{hash})
+	if err != nil || len(hashes) != 1 || hashes[0] != hash {
+		return false
+	}
+	dd.add(hashes)
+	return true
+}
+
+func (dd *blobDedup) add(hashes []string) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	for _, hash := range hashes {
+		if len(dd.known) >= maxKnownBlobs {
+			clear(dd.known)
+		}
+		dd.known[hash] = true
+	}
+}
+
+func (dd *blobDedup) forget(hashes []string) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	for _, hash := range hashes {
+		delete(dd.known, hash)
+	}
+}
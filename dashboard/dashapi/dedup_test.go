@@ -0,0 +1,126 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlobDedup(t *testing.T) {
+	blobs := make(map[string][]byte)
+	var methods []string
+	var configs [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.PostFormValue("method")
+		methods = append(methods, method)
+		payload, err := DecompressPayload(r.PostFormValue("compression"), []byte(r.PostFormValue("payload")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reply interface{}
+		switch method {
+		case "capabilities":
+			reply = &CapabilitiesResp{Version: APIVersion, Features: []string{FeatureBlobDedup}}
+		case "has_blobs":
+			req := new(HasBlobsReq)
+			if err := json.Unmarshal(payload, req); err != nil {
+				t.Fatal(err)
+			}
+			resp := new(HasBlobsResp)
+			for _, hash := range req.Hashes {
+				if blobs[hash] != nil {
+					resp.Known = append(resp.Known, hash)
+				}
+			}
+			reply = resp
+		case "upload_build", "job_done":
+			build := new(Build)
+			if method == "job_done" {
+				req := new(JobDoneReq)
+				if err := json.Unmarshal(payload, req); err != nil {
+					t.Fatal(err)
+				}
+				build = &req.Build
+			} else if err := json.Unmarshal(payload, build); err != nil {
+				t.Fatal(err)
+			}
+			for _, hash := range build.BlobHashes {
+				if hash.Field != "KernelConfig" || blobs[hash.SHA256] == nil {
+					http.Error(w, "unknown blob", http.StatusNotFound)
+					return
+				}
+				build.KernelConfig = blobs[hash.SHA256]
+			}
+			sum := sha256.Sum256(build.KernelConfig)
+			blobs[hex.EncodeToString(sum[:])] = build.KernelConfig
+			configs = append(configs, build.KernelConfig)
+		default:
+			t.Fatalf("unexpected method %v", method)
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+	defer srv.Close()
+
+	config := bytes.Repeat([]byte("CONFIG_KASAN=y\n"), 10000)
+	build := &Build{ID: "build", KernelConfig: config}
+	upload := func(dash *Dashboard) {
+		t.Helper()
+		if err := dash.UploadBuild(context.Background(), build); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(configs[len(configs)-1], config) {
+			t.Fatalf("the dashboard got a wrong kernel config")
+		}
+	}
+	dash, err := New("client", srv.URL, "key", BlobDedup{}, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first upload sends the config, the second one refers to it without asking the dashboard.
+	upload(dash)
+	upload(dash)
+	small := &Build{ID: "small", KernelConfig: []byte("CONFIG_KASAN=y\n")}
+	if err := dash.UploadBuild(context.Background(), small); err != nil {
+		t.Fatal(err)
+	}
+	// A new client has to ask whether the dashboard has the config.
+	dash2, err := New("client", srv.URL, "key", BlobDedup{}, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload(dash2)
+	// If the dashboard has lost the config, it's sent again.
+	clear(blobs)
+	upload(dash2)
+	// Job builds usually have the same config as the manager builds.
+	if err := dash2.JobDone(context.Background(), &JobDoneReq{ID: "job", Build: *build}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(configs[len(configs)-1], config) {
+		t.Fatalf("the dashboard got a wrong kernel config")
+	}
+	want := []string{
+		"capabilities", "has_blobs", "upload_build",
+		"upload_build",
+		"upload_build",
+		"capabilities", "has_blobs", "upload_build",
+		"upload_build", "upload_build",
+		"job_done",
+	}
+	if len(methods) != len(want) {
+		t.Fatalf("got requests %q, want %q", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Fatalf("got requests %q, want %q", methods, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// DryRun makes the client validate and marshal requests and log them instead of sending them
+// to the dashboard. All requests succeed with zero replies. It's useful to bring up a new manager
+// config against a production dashboard without polluting it with test builds and crashes.
+// Requests are logged with the RequestLogger, or with the standard logger if there is none.
+// In dry-run mode CrashIndexConfig, SpoolConfig and ChunkedUpload have no effect,
+// and no credentials are requested from the GCE metadata server.
+type DryRun bool
+
+// dryRunMaxLog is the max size of the request printed in dry-run mode.
+const dryRunMaxLog = 1 << 10
+
+func (dash *Dashboard) queryDryRun(method string, req interface{}) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if req != nil && dash.payloadKeys != nil {
+		if _, err := dash.payloadKeys.encryptRequest(req); err != nil {
+			return err
+		}
+	}
+	logf := log.Printf
+	if dash.logger != nil {
+		logf = dash.logger
+	}
+	msg := string(data)
+	if len(msg) > dryRunMaxLog {
+		msg = fmt.Sprintf("%s... (%v bytes)", msg[:dryRunMaxLog], len(data))
+	}
+	logf("API(%v): dry run, not sent: %s", method, msg)
+	return nil
+}
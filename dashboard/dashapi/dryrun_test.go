@@ -0,0 +1,53 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		t.Fatalf("dry run sent a request to %v", r.URL)
+		return nil, nil
+	})
+	var logs []string
+	logger := RequestLogger(func(msg string, args ...interface{}) {
+		if line := fmt.Sprintf(msg, args...); strings.Contains(line, "dry run") {
+			logs = append(logs, line)
+		}
+	})
+	dir := t.TempDir()
+	dash, err := New("client", "", "", transport, logger, DryRun(true), ChunkedUpload{Threshold: 10},
+		CrashIndexConfig{File: filepath.Join(dir, "index")}, SpoolConfig{Dir: filepath.Join(dir, "spool")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := dash.ReportCrash(context.Background(), &Crash{
+		Title: "crash title",
+		Log:   []byte(strings.Repeat("log", 1000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NeedRepro {
+		t.Fatalf("dry run returned non-zero reply: %+v", resp)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "report_crash") ||
+		!strings.Contains(logs[0], "crash title") || !strings.Contains(logs[0], "bytes)") {
+		t.Fatalf("unexpected logs: %q", logs)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Fatalf("dry run created files: %v %v", entries, err)
+	}
+	if err := dash.Query(context.Background(), "test", func() {}, nil); err == nil {
+		t.Fatalf("unmarshallable request did not fail")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// IncomingEmail is an email received by an external mail receiver and forwarded to the dashboard.
+// The dashboard handles it as an email sent to its own address (see EmailConfig): the commands
+// are applied to the bugs and the replies are sent by the dashboard.
+// Use email.Email.Incoming to fill it from an email parsed with the dashboard own addresses.
+type IncomingEmail struct {
+	// Receiver is the dashboard address the email was sent to, it selects the discussion source
+	// for DiscussionEmails addresses. Empty for the main dashboard address.
+	Receiver    string
+	MessageID   string
+	InReplyTo   string
+	Date        time.Time
+	Subject     string
+	Author      string   // the sender
+	MailingList string   // set if the email came through one of the dashboard mailing lists
+	BugIDs      []string // extracted from the dashboard addresses in To/Cc and from the body
+	Cc          []string
+	Link        string
+	Body        string
+	Patch       string
+	Commands    []EmailCommand
+}
+
+// EmailCommand is a "#syz" command of an IncomingEmail.
+type EmailCommand struct {
+	Command string // as written after #syz, e.g. "dup:" or "invalid"
+	Args    string
+}
+
+func (dash *Dashboard) IncomingEmail(ctx context.Context, req *IncomingEmail) error {
+	return dash.Query(ctx, "incoming_email", req, nil)
+}
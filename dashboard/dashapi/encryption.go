@@ -0,0 +1,218 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// PayloadKeys enables client-side encryption of sensitive payloads (crash logs and reports,
+// reproducers and kernel configs) for private namespaces. The dashboard only stores
+// the ciphertexts and does not look into them, the namespace needs EncryptedPayloads
+// in the dashboard config for that (see dashboard/app/config.go).
+// Each ciphertext is prefixed with an EncryptedMarker and the ID of the key it was encrypted with,
+// so keys can be rotated: new payloads are encrypted with Current, while the old keys
+// are still used to decrypt the payloads that were uploaded before the rotation.
+type PayloadKeys struct {
+	Current string
+	// AES-256 keys by key ID.
+	Keys map[string][]byte
+}
+
+// EncryptedMarker is the prefix of all encrypted payloads.
+const EncryptedMarker = "SYZENC1:"
+
+// Fields of the request types that are encrypted before upload, the rest are sent as is.
+// Structs are matched wherever they are nested (e.g. ReproTaskResult.Crash).
+var encryptedFields = map[reflect.Type][]string{
+	reflect.TypeOf(Build{}):           {"KernelConfig"},
+	reflect.TypeOf(Crash{}):           {"Log", "Report", "ReproSyz", "ReproC", "ReproLog"},
+	reflect.TypeOf(UpdateCrashReq{}):  {"ReproSyz", "ReproC", "ReproLog"},
+	reflect.TypeOf(CrashID{}):         {"ReproLog"},
+	reflect.TypeOf(ReproTaskResult{}): {"ReproLog"},
+	reflect.TypeOf(ReproProgress{}):   {"ReproSyz"},
+	reflect.TypeOf(JobDoneReq{}):      {"Log", "CrashLog", "CrashReport"},
+}
+
+// LoadPayloadKeys loads keys from a JSON file of the form:
+// {"Current": "key-id", "Keys": {"key-id": "hex-encoded key"}}.
+func LoadPayloadKeys(file string) (*PayloadKeys, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		Current string
+		Keys    map[string]string
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %w", file, err)
+	}
+	keys := &PayloadKeys{
+		Current: cfg.Current,
+		Keys:    make(map[string][]byte),
+	}
+	for id, str := range cfg.Keys {
+		if keys.Keys[id], err = hex.DecodeString(str); err != nil {
+			return nil, fmt.Errorf("failed to parse key %q: %w", id, err)
+		}
+	}
+	if err := keys.check(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (keys *PayloadKeys) check() error {
+	if keys.Keys[keys.Current] == nil {
+		return fmt.Errorf("no current key %q", keys.Current)
+	}
+	for id, key := range keys.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return fmt.Errorf("bad key ID %q", id)
+		}
+		if len(key) != 32 {
+			return fmt.Errorf("key %q has wrong size %v, want 32", id, len(key))
+		}
+	}
+	return nil
+}
+
+// IsEncrypted says if the payload was encrypted with PayloadKeys.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(EncryptedMarker))
+}
+
+// Encrypt encrypts data with the current key.
+func (keys *PayloadKeys) Encrypt(data []byte) ([]byte, error) {
+	aead, err := keys.aead(keys.Current)
+	if err != nil {
+		return nil, err
+	}
+	res := []byte(EncryptedMarker + keys.Current + ":")
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	res = append(res, nonce...)
+	return aead.Seal(res, nonce, data, []byte(keys.Current)), nil
+}
+
+// Decrypt decrypts data encrypted with any of the keys.
+// Data that is not encrypted is returned as is.
+func (keys *PayloadKeys) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	id, rest, ok := bytes.Cut(data[len(EncryptedMarker):], []byte(":"))
+	if !ok {
+		return nil, fmt.Errorf("malformed encrypted payload")
+	}
+	aead, err := keys.aead(string(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted payload")
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	res, err := aead.Open(nil, nonce, ciphertext, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload with key %q: %w", id, err)
+	}
+	return res, nil
+}
+
+func (keys *PayloadKeys) aead(id string) (cipher.AEAD, error) {
+	key := keys.Keys[id]
+	if key == nil {
+		return nil, fmt.Errorf("unknown payload key %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptRequest returns a copy of the request with all sensitive fields encrypted.
+func (keys *PayloadKeys) encryptRequest(req interface{}) (interface{}, error) {
+	// Round-trip via JSON to get a deep copy, we must not modify the caller's data.
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	res := reflect.New(reflect.TypeOf(req))
+	if err := json.Unmarshal(data, res.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to copy request: %w", err)
+	}
+	err = walkPayloads(res.Elem(), func(typ reflect.Type, name string, data *[]byte) error {
+		if !slices.Contains(encryptedFields[typ], name) || len(*data) == 0 || IsEncrypted(*data) {
+			return nil
+		}
+		var err error
+		*data, err = keys.Encrypt(*data)
+		return err
+	})
+	return res.Elem().Interface(), err
+}
+
+// decryptReply decrypts all encrypted payloads in the reply in place.
+func (keys *PayloadKeys) decryptReply(reply interface{}) error {
+	return walkPayloads(reflect.ValueOf(reply), func(typ reflect.Type, name string, data *[]byte) error {
+		var err error
+		*data, err = keys.Decrypt(*data)
+		return err
+	})
+}
+
+// walkPayloads calls fn for all []byte struct fields reachable from v.
+func walkPayloads(v reflect.Value, fn func(typ reflect.Type, name string, data *[]byte) error) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walkPayloads(v.Elem(), fn)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walkPayloads(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if field.Type() == reflect.TypeOf([]byte(nil)) {
+				if !field.CanSet() {
+					continue
+				}
+				if err := fn(v.Type(), v.Type().Field(i).Name, field.Addr().Interface().(*[]byte)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walkPayloads(field, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
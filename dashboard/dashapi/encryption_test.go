@@ -0,0 +1,158 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testPayloadKeys(current string) *PayloadKeys {
+	return &PayloadKeys{
+		Current: current,
+		Keys: map[string][]byte{
+			"old": bytes.Repeat([]byte{1}, 32),
+			"new": bytes.Repeat([]byte{2}, 32),
+		},
+	}
+}
+
+func TestPayloadEncryption(t *testing.T) {
+	oldKeys := testPayloadKeys("old")
+	data := []byte("BUG: KASAN: use-after-free")
+	enc, err := oldKeys.Encrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || bytes.Contains(enc, data) {
+		t.Fatalf("the payload is not encrypted: %q", enc)
+	}
+	// Payloads encrypted before the rotation can still be decrypted.
+	newKeys := testPayloadKeys("new")
+	dec, err := newKeys.Decrypt(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Fatalf("got %q, want %q", dec, data)
+	}
+	// Unknown keys and tampered ciphertexts are detected.
+	delete(newKeys.Keys, "old")
+	if _, err := newKeys.Decrypt(enc); err == nil {
+		t.Fatalf("decrypted with an unknown key")
+	}
+	enc[len(enc)-1]++
+	if _, err := oldKeys.Decrypt(enc); err == nil {
+		t.Fatalf("decrypted a tampered payload")
+	}
+	// Plain payloads are returned as is.
+	if dec, err := oldKeys.Decrypt(data); err != nil || !bytes.Equal(dec, data) {
+		t.Fatalf("plain payload: got %q/%v", dec, err)
+	}
+}
+
+func TestPayloadEncryptionQuery(t *testing.T) {
+	keys := testPayloadKeys("new")
+	var uploaded *Crash
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		switch method {
+		case "report_crash":
+			uploaded = new(Crash)
+			if err := json.Unmarshal(payload, uploaded); err != nil {
+				t.Fatal(err)
+			}
+			return &ReportCrashResp{}, nil
+		case "get_repro":
+			return &Repro{Level: ReproLevelSyz, Syz: uploaded.ReproSyz, Opts: []byte("opts")}, nil
+		}
+		return nil, fmt.Errorf("unknown method %v", method)
+	})
+	dash.payloadKeys = keys
+	crash := &Crash{
+		Title:       "title",
+		Log:         []byte("log"),
+		Report:      []byte("report"),
+		ReproSyz:    []byte("repro"),
+		MachineInfo: []byte("machine info"),
+	}
+	orig := *crash
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&orig, crash); diff != "" {
+		t.Fatalf("the caller's crash was modified:\n%v", diff)
+	}
+	if uploaded.Title != crash.Title || !bytes.Equal(uploaded.MachineInfo, crash.MachineInfo) {
+		t.Fatalf("non-sensitive fields were encrypted: %+v", uploaded)
+	}
+	for _, data := range [][]byte{uploaded.Log, uploaded.Report, uploaded.ReproSyz} {
+		if !IsEncrypted(data) {
+			t.Fatalf("sensitive field was not encrypted: %q", data)
+		}
+	}
+	repro, err := dash.GetRepro(context.Background(), "bug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(repro.Syz, crash.ReproSyz) {
+		t.Fatalf("the reply was not decrypted: %q", repro.Syz)
+	}
+}
+
+func TestPayloadEncryptionFields(t *testing.T) {
+	keys := testPayloadKeys("new")
+	res, err := keys.encryptRequest(&ReproTaskDoneReq{
+		TaskID: "task",
+		Result: &ReproTaskResult{
+			Crash:    &Crash{Log: []byte("log"), MachineInfo: []byte("machine info")},
+			ReproLog: []byte("repro log"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := res.(*ReproTaskDoneReq).Result
+	if !IsEncrypted(result.ReproLog) || !IsEncrypted(result.Crash.Log) {
+		t.Fatalf("nested payloads were not encrypted: %+v", result)
+	}
+	if !bytes.Equal(result.Crash.MachineInfo, []byte("machine info")) {
+		t.Fatalf("the machine info was encrypted: %q", result.Crash.MachineInfo)
+	}
+	// Tool bugs are not about the kernel, they are not encrypted.
+	res, err = keys.encryptRequest(&ToolBugReq{Log: []byte("log"), Report: []byte("report")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := res.(*ToolBugReq); IsEncrypted(req.Log) || IsEncrypted(req.Report) {
+		t.Fatalf("the tool bug was encrypted: %+v", req)
+	}
+}
+
+func TestLoadPayloadKeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys.json")
+	data := fmt.Sprintf(`{"Current": "k1", "Keys": {"k1": "%x"}}`, bytes.Repeat([]byte{1}, 32))
+	if err := os.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadPayloadKeys(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Current != "k1" || len(keys.Keys["k1"]) != 32 {
+		t.Fatalf("wrong keys: %+v", keys)
+	}
+	if err := os.WriteFile(file, []byte(`{"Current": "k2", "Keys": {"k1": "0102"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPayloadKeys(file); err == nil {
+		t.Fatalf("loaded bad keys")
+	}
+}
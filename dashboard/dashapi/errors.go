@@ -0,0 +1,83 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by the dashboard for common failures, use errors.Is to check for them.
+var (
+	// The client name or key is wrong, or the client is not allowed to use the method.
+	ErrAccessDenied = errors.New("access denied")
+	// The requested object (bug, build, upload, etc) does not exist.
+	ErrNotFound = errors.New("not found")
+	// The manager or namespace is decommissioned, it should stop reporting.
+	ErrClientRetired = errors.New("client is retired")
+	// The request or some of its fields are too large, it may be accepted after truncation.
+	ErrTooLarge = errors.New("request is too large")
+	// The request is malformed and will fail again.
+	ErrBadRequest = errors.New("bad request")
+)
+
+// Error is returned for requests that failed because of the dashboard or the connection to it.
+// Use errors.As to get it from errors returned by the client, or IsTemporary to classify them.
+type Error struct {
+	Method string
+	// HTTP status of the reply, 0 if there was no reply (e.g. connection failures and gRPC requests).
+	Status int
+	// Error message returned by the dashboard, if any.
+	Message string
+	// Temporary errors (connection failures, timeouts, overloaded dashboard) may go away
+	// if the request is repeated later. Other errors (e.g. bad requests) will fail again,
+	// so such requests should be dropped.
+	Temporary bool
+	Err       error
+	// One of the Err* sentinel errors above, if the failure is one of the common ones.
+	kind error
+}
+
+func (err *Error) Error() string {
+	return err.Err.Error()
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+func (err *Error) Is(target error) bool {
+	return err.kind != nil && err.kind == target
+}
+
+// IsTemporary returns if the request may succeed if it's repeated later.
+func IsTemporary(err error) bool {
+	var dashErr *Error
+	return errors.As(err, &dashErr) && dashErr.Temporary
+}
+
+func temporaryError(method string, err error) *Error {
+	return &Error{Method: method, Temporary: true, Err: err}
+}
+
+func statusError(method string, resp *http.Response, message []byte) *Error {
+	return &Error{
+		Method:    method,
+		Status:    resp.StatusCode,
+		Message:   string(message),
+		Temporary: resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests,
+		Err:       fmt.Errorf("request failed with %v: %s", resp.Status, message),
+		kind:      statusErrors[resp.StatusCode],
+	}
+}
+
+var statusErrors = map[int]error{
+	http.StatusUnauthorized:          ErrAccessDenied,
+	http.StatusForbidden:             ErrAccessDenied,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusGone:                  ErrClientRetired,
+	http.StatusRequestEntityTooLarge: ErrTooLarge,
+	http.StatusBadRequest:            ErrBadRequest,
+}
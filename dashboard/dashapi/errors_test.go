@@ -0,0 +1,59 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	for _, test := range []struct {
+		status    int
+		temporary bool
+		kind      error
+	}{
+		{http.StatusBadRequest, false, ErrBadRequest},
+		{http.StatusForbidden, false, ErrAccessDenied},
+		{http.StatusNotFound, false, ErrNotFound},
+		{http.StatusGone, false, ErrClientRetired},
+		{http.StatusRequestEntityTooLarge, false, ErrTooLarge},
+		{http.StatusTooManyRequests, true, nil},
+		{http.StatusServiceUnavailable, true, nil},
+	} {
+		dash, _ := testRetryDashboard(t, test.status, test.status, test.status)
+		err := dash.UploadBuild(context.Background(), &Build{})
+		var dashErr *Error
+		if !errors.As(err, &dashErr) {
+			t.Fatalf("status %v: got %T error %v", test.status, err, err)
+		}
+		if dashErr.Method != "upload_build" || dashErr.Status != test.status ||
+			dashErr.Message != "{}" || dashErr.Temporary != test.temporary {
+			t.Errorf("status %v: got %+v", test.status, dashErr)
+		}
+		if IsTemporary(err) != test.temporary {
+			t.Errorf("status %v: IsTemporary = %v", test.status, !test.temporary)
+		}
+		for _, kind := range []error{ErrBadRequest, ErrAccessDenied, ErrNotFound, ErrClientRetired, ErrTooLarge} {
+			if errors.Is(err, kind) != (kind == test.kind) {
+				t.Errorf("status %v: errors.Is(%v) = %v", test.status, kind, !(kind == test.kind))
+			}
+		}
+	}
+
+	dash, err := New("client", "http://127.0.0.1:1", "key", RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dash.UploadBuild(context.Background(), &Build{})
+	var dashErr *Error
+	if !errors.As(err, &dashErr) || dashErr.Status != 0 || !dashErr.Temporary {
+		t.Fatalf("connection failure: got %#v", err)
+	}
+	if IsTemporary(errors.New("other error")) {
+		t.Fatalf("unrelated errors are temporary")
+	}
+}
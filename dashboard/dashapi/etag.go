@@ -0,0 +1,69 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// Replies of the reporting poll methods rarely change between polls, so the dashboard tags them
+// with an ETag. The client remembers the last reply and sends its ETag in If-None-Match,
+// if the reply has not changed, the dashboard replies with 304 Not Modified without the body.
+var etagMethods = map[string]bool{
+	"reporting_poll_bugs":   true,
+	"reporting_poll_notifs": true,
+	"reporting_poll_closed": true,
+}
+
+// HasETag says if replies to the method are tagged with an ETag.
+func HasETag(method string) bool {
+	return etagMethods[method]
+}
+
+// The cache is keyed by the request, clients normally poll with few distinct requests.
+const maxCachedReplies = 16
+
+type etagCache struct {
+	mu      sync.Mutex
+	replies map[string]*cachedReply
+}
+
+type cachedReply struct {
+	etag        string
+	contentType string
+	data        []byte // uncompressed
+}
+
+func etagKey(method string, req interface{}) string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(append([]byte(method+"\x00"), data...))
+	return hex.EncodeToString(hash[:])
+}
+
+func (cache *etagCache) get(key string) *cachedReply {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.replies[key]
+}
+
+func (cache *etagCache) put(key string, reply *cachedReply) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.replies == nil {
+		cache.replies = make(map[string]*cachedReply)
+	}
+	if _, ok := cache.replies[key]; !ok && len(cache.replies) >= maxCachedReplies {
+		for old := range cache.replies {
+			delete(cache.replies, old)
+			break
+		}
+	}
+	cache.replies[key] = reply
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	var requests, notModified int
+	reply := &PollBugsResponse{Reports: []*BugReport{{ID: "id1"}}}
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		requests++
+		data, err := json.Marshal(reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		etag := `"` + reply.Reports[0].ID + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			return &http.Response{
+				StatusCode: http.StatusNotModified,
+				Header:     http.Header{"Etag": []string{etag}},
+				Body:       io.NopCloser(new(bytes.Buffer)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": []string{etag}},
+			Body:       io.NopCloser(bytes.NewReader(data)),
+		}, nil
+	})
+	dash, err := New("client", "http://dashboard", "key", transport, RetryPolicy{}, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"id1", "id1", "id2", "id2"} {
+		if i == 2 {
+			reply = &PollBugsResponse{Reports: []*BugReport{{ID: "id2"}}}
+		}
+		resp, err := dash.ReportingPollBugs(context.Background(), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Reports) != 1 || resp.Reports[0].ID != want {
+			t.Fatalf("poll %v: got %+v, want %v", i, resp.Reports, want)
+		}
+	}
+	if requests != 4 || notModified != 2 {
+		t.Fatalf("requests %v, not modified %v", requests, notModified)
+	}
+}
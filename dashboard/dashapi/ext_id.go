@@ -0,0 +1,46 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+)
+
+// Reporting integrations record the identifier of the external object they created for a bug
+// (a Bugzilla bug ID, a GitHub issue number, an email Message-ID) with SetExtID and later find
+// the bug with LookupExtID. The identifier is passed back in BugReport.ExtID and BugNotification.ExtID.
+
+type SetExtIDReq struct {
+	ID    string // BugReport.ID
+	ExtID string // replaces the previous identifier, if any
+}
+
+type LookupExtIDReq struct {
+	ExtID string
+}
+
+type LookupExtIDResp struct {
+	Bugs []*ExtIDBug // several reportings may use the same identifier
+}
+
+type ExtIDBug struct {
+	ID        string // BugReport.ID
+	ExtID     string
+	Namespace string
+	Title     string
+	Link      string
+	Status    BugStatus // BugStatusOpen if the bug is open or was moved to the next reporting
+	DupOf     string    // ExtID of the canonical bug in the same reporting, set for BugStatusDup
+}
+
+func (dash *Dashboard) SetExtID(ctx context.Context, req *SetExtIDReq) error {
+	return dash.Query(ctx, "set_ext_id", req, nil)
+}
+
+// LookupExtID returns the bugs the identifier was recorded for.
+func (dash *Dashboard) LookupExtID(ctx context.Context, extID string) ([]*ExtIDBug, error) {
+	resp := new(LookupExtIDResp)
+	err := dash.Query(ctx, "lookup_ext_id", &LookupExtIDReq{ExtID: extID}, resp)
+	return resp.Bugs, err
+}
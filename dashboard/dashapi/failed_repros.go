@@ -0,0 +1,58 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"sync"
+	"time"
+)
+
+// FailedReproDedup can be passed to New to throttle ReportFailedRepro on the client side.
+// Flaky crashes are reproduced over and over again, so after a failed repro of a title
+// was reported, further failed repros of the title within Window are not uploaded.
+type FailedReproDedup struct {
+	Window time.Duration // DefaultFailedReproWindow if 0
+}
+
+const DefaultFailedReproWindow = 6 * time.Hour
+
+type failedReproDedup struct {
+	window   time.Duration
+	mu       sync.Mutex
+	reported map[string]time.Time // title -> time of the last upload
+}
+
+func newFailedReproDedup(cfg *FailedReproDedup) *failedReproDedup {
+	window := cfg.Window
+	if window == 0 {
+		window = DefaultFailedReproWindow
+	}
+	return &failedReproDedup{
+		window:   window,
+		reported: make(map[string]time.Time),
+	}
+}
+
+// claim returns false if a failed repro of the title was reported within the window.
+// Otherwise the caller is supposed to upload the failed repro, and to call release if that fails.
+func (d *failedReproDedup) claim(title string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.reported[title]; ok && now.Sub(last) < d.window {
+		return false
+	}
+	for t, last := range d.reported {
+		if now.Sub(last) >= d.window {
+			delete(d.reported, t)
+		}
+	}
+	d.reported[title] = now
+	return true
+}
+
+func (d *failedReproDedup) release(title string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.reported, title)
+}
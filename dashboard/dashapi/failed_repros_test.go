@@ -0,0 +1,64 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestFailedReproDedup(t *testing.T) {
+	var uploads []string
+	fail := false
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if fail {
+			return nil, fmt.Errorf("injected failure")
+		}
+		req := new(CrashID)
+		if err := json.Unmarshal(payload, req); err != nil {
+			t.Fatal(err)
+		}
+		uploads = append(uploads, req.Title)
+		return nil, nil
+	})
+	dash.failedRepros = newFailedReproDedup(&FailedReproDedup{})
+	report := func(title string) error {
+		return dash.ReportFailedRepro(context.Background(), &CrashID{BuildID: "build", Title: title})
+	}
+	for i := 0; i < 10; i++ {
+		if err := report("flaky"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := report("other"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(uploads) != "[flaky other]" {
+		t.Fatalf("got uploads %q", uploads)
+	}
+	// A failed upload does not suppress the next report.
+	fail = true
+	if err := report("third"); err == nil {
+		t.Fatalf("expected an error")
+	}
+	fail = false
+	if err := report("third"); err != nil {
+		t.Fatal(err)
+	}
+	// The title is reported again once the window has passed.
+	for title, last := range dash.failedRepros.reported {
+		dash.failedRepros.reported[title] = last.Add(-DefaultFailedReproWindow)
+	}
+	if err := report("flaky"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(uploads) != "[flaky other third flaky]" {
+		t.Fatalf("got uploads %q", uploads)
+	}
+	if len(dash.failedRepros.reported) != 1 {
+		t.Fatalf("expired titles were not dropped: %v", dash.failedRepros.reported)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC makes the client talk to the dashboard over gRPC instead of HTTP/JSON.
+// This is meant for self-hosted dashboards (see NewGRPCServer), AppEngine dashboards only support HTTP.
+//
+// The gRPC service mirrors the HTTP API: each API method is a unary RPC of the GRPCService
+// service with the same name (e.g. /syzkaller.dashapi.Dashboard/report_crash).
+// Requests and replies are the same structs as for HTTP, they are encoded with the JSON codec.
+// The client name and key are passed in the "client" and "key" metadata,
+// credentials of an AuthProvider are passed in the "authorization" metadata.
+type GRPC struct {
+	Addr     string // host:port
+	Insecure bool   // don't use TLS
+}
+
+const GRPCService = "syzkaller.dashapi.Dashboard"
+
+// GRPCHandler serves API requests received over gRPC, payload is the JSON-encoded request.
+// Returned errors that are not gRPC statuses are passed to the client as codes.Internal.
+type GRPCHandler func(ctx context.Context, client, key, method string, payload []byte) (interface{}, error)
+
+// NewGRPCServer creates a gRPC server that serves the dashboard API with the handler.
+func NewGRPCServer(handler GRPCHandler, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnknownServiceHandler(func(srv any, stream grpc.ServerStream) error {
+			return serveGRPC(handler, stream)
+		}),
+	)
+	return grpc.NewServer(opts...)
+}
+
+func serveGRPC(handler GRPCHandler, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	method, ok := strings.CutPrefix(fullMethod, "/"+GRPCService+"/")
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %v", fullMethod)
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	var payload json.RawMessage
+	if err := stream.RecvMsg(&payload); err != nil {
+		return err
+	}
+	reply, err := handler(stream.Context(), grpcMetadata(md, "client"), grpcMetadata(md, "key"), method, payload)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(reply)
+}
+
+func grpcMetadata(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) != 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func dialGRPC(cfg *GRPC, tlsConf *tls.Config) (*grpc.ClientConn, error) {
+	creds := credentials.NewTLS(tlsConf)
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(cfg.Addr, grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %w", cfg.Addr, err)
+	}
+	return conn, nil
+}
+
+func (dash *Dashboard) queryGRPC(ctx context.Context, method string, req, reply interface{},
+	header http.Header) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "client", dash.Client, "key", dash.Key)
+	if dash.Namespace != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "namespace", dash.Namespace)
+	}
+	for name, vals := range header {
+		for _, val := range vals {
+			ctx = metadata.AppendToOutgoingContext(ctx, name, val)
+		}
+	}
+	if dash.auth != nil {
+		token, err := dash.auth.Authorization(ctx)
+		if err != nil {
+			return temporaryError(method, err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", token)
+	}
+	if reply == nil {
+		reply = new(json.RawMessage)
+	}
+	err := dash.grpcConn.Invoke(ctx, "/"+GRPCService+"/"+method, req, reply)
+	if err != nil {
+		res := &Error{
+			Method:  method,
+			Message: status.Convert(err).Message(),
+			Err:     fmt.Errorf("grpc request failed: %w", err),
+		}
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+			res.Temporary = true
+		case codes.Unauthenticated, codes.PermissionDenied:
+			res.kind = ErrAccessDenied
+		case codes.NotFound:
+			res.kind = ErrNotFound
+		case codes.FailedPrecondition:
+			res.kind = ErrClientRetired
+		case codes.InvalidArgument:
+			res.kind = ErrBadRequest
+		}
+		return res
+	}
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	if len(data) == 0 {
+		// Empty messages are sent for nil requests and replies.
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPC(t *testing.T) {
+	var builds []string
+	handler := func(ctx context.Context, client, key, method string, payload []byte) (interface{}, error) {
+		if client != "client" || key != "key" {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		switch method {
+		case "upload_build":
+			build := new(Build)
+			if err := json.Unmarshal(payload, build); err != nil {
+				return nil, err
+			}
+			builds = append(builds, build.ID)
+			return nil, nil
+		case "bug_list":
+			if payload != nil {
+				return nil, fmt.Errorf("unexpected payload %q", payload)
+			}
+			return &BugListResp{List: []string{"bug1", "bug2"}}, nil
+		}
+		return nil, fmt.Errorf("unknown method %v", method)
+	}
+	srv := NewGRPCServer(handler)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	cfg := GRPC{Addr: ln.Addr().String(), Insecure: true}
+	dash, err := New("client", "", "key", cfg, RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := dash.UploadBuild(ctx, &Build{ID: "build1"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"build1"}, builds); diff != "" {
+		t.Fatal(diff)
+	}
+	resp, err := dash.BugList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"bug1", "bug2"}, resp.List); diff != "" {
+		t.Fatal(diff)
+	}
+	if err := dash.Query(ctx, "unknown", nil, nil); status.Code(err) != codes.Internal {
+		t.Fatalf("unknown method: got %v", err)
+	}
+
+	badDash, err := New("client", "", "wrong", cfg, RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := badDash.BugList(ctx); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("wrong key: got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Crash reports carry an idempotency key (Crash.IdempotencyKey), so that the dashboard
+// saves a crash only once even if the request is repeated (retries, spool replays,
+// or explicit ReportCrash calls with the same key). ReportCrash and ReportCrashes
+// generate a key for crashes that don't have one.
+// UploadBuild does not need keys: builds are deduplicated by Build.ID.
+
+func withIdempotencyKey(crash *Crash) *Crash {
+	if crash.IdempotencyKey != "" {
+		return crash
+	}
+	res := *crash
+	res.IdempotencyKey = NewIdempotencyKey()
+	return &res
+}
+
+// NewIdempotencyKey returns a new random idempotency key.
+func NewIdempotencyKey() string {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(key[:])
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		crash := new(Crash)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, crash.IdempotencyKey)
+		if len(keys) == 1 {
+			return nil, errors.New("transient failure")
+		}
+		return &ReportCrashResp{}, nil
+	})
+	dash.retry = RetryPolicy{Attempts: 2, Backoff: time.Millisecond}
+	crash := &Crash{Title: "title"}
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("retries used keys %q, want the same key", keys)
+	}
+	if crash.IdempotencyKey != "" {
+		t.Fatalf("the caller's crash was modified")
+	}
+	// Explicit keys are preserved, other requests get new keys.
+	crash.IdempotencyKey = "explicit"
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	crash.IdempotencyKey = ""
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	if keys[2] != "explicit" || keys[3] == "" || keys[3] == keys[0] {
+		t.Fatalf("got keys %q", keys)
+	}
+}
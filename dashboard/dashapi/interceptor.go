@@ -0,0 +1,67 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Interceptor hooks into dashboard requests, e.g. to log or scrub payloads, add headers
+// or collect metrics. Any number of interceptors can be passed to New, the hooks are called
+// in the order the interceptors were passed. Any of the hooks may be nil.
+// The hooks are called for each attempt of a request (including retries and spool replays),
+// requests are seen before encryption with PayloadKeys and replies after decryption.
+type Interceptor struct {
+	// OnRequest is called before a request is sent. It may change the request or add headers.
+	// If it returns an error, the request is not sent and fails with the error.
+	OnRequest func(ctx context.Context, call *Call) error
+	// OnResponse is called after a request has succeeded.
+	OnResponse func(ctx context.Context, call *Call)
+	// OnError is called after a request has failed.
+	OnError func(ctx context.Context, call *Call, err error)
+}
+
+// Call describes a single attempt of a dashboard request.
+type Call struct {
+	Method string
+	// The request may be replaced by OnRequest, e.g. with a scrubbed copy
+	// (it must not be modified in place since it belongs to the caller).
+	Request interface{}
+	Reply   interface{}
+	// Additional headers of the HTTP request (gRPC metadata for gRPC requests).
+	Header http.Header
+	// Duration of the request, set for OnResponse and OnError.
+	Duration time.Duration
+}
+
+func (dash *Dashboard) queryIntercepted(ctx context.Context, method string, req, reply interface{}) error {
+	call := &Call{
+		Method:  method,
+		Request: req,
+		Reply:   reply,
+		Header:  make(http.Header),
+	}
+	for _, ic := range dash.interceptors {
+		if ic.OnRequest == nil {
+			continue
+		}
+		if err := ic.OnRequest(ctx, call); err != nil {
+			return err
+		}
+	}
+	start := time.Now()
+	err := dash.querySend(ctx, call.Method, call.Request, call.Reply, call.Header)
+	call.Duration = time.Since(start)
+	dash.metrics.recordAttempt(method, call.Duration, err)
+	for _, ic := range dash.interceptors {
+		if err != nil && ic.OnError != nil {
+			ic.OnError(ctx, call, err)
+		} else if err == nil && ic.OnResponse != nil {
+			ic.OnResponse(ctx, call)
+		}
+	}
+	return err
+}
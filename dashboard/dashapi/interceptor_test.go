@@ -0,0 +1,100 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInterceptor(t *testing.T) {
+	var sent []string
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		if got := r.Header.Get("X-Test"); got != "test" {
+			t.Errorf("got header %q, want %q", got, "test")
+		}
+		entry := new(LogEntry)
+		payload, err := DecompressPayload(r.FormValue("compression"), []byte(r.FormValue("payload")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(payload, entry); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, entry.Text)
+		if entry.Text == "fail" {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Status:     http.StatusText(http.StatusBadRequest),
+				Body:       io.NopCloser(strings.NewReader("failed")),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"Name": "reply"}`)),
+		}, nil
+	})
+	var events []string
+	errDenied := errors.New("denied")
+	scrub := Interceptor{
+		OnRequest: func(ctx context.Context, call *Call) error {
+			entry := *call.Request.(*LogEntry)
+			if entry.Text == "deny" {
+				return errDenied
+			}
+			entry.Text = strings.ReplaceAll(entry.Text, "secret", "***")
+			call.Request = &entry
+			call.Header.Set("X-Test", "test")
+			return nil
+		},
+	}
+	record := Interceptor{
+		OnRequest: func(ctx context.Context, call *Call) error {
+			events = append(events, "request "+call.Request.(*LogEntry).Text)
+			return nil
+		},
+		OnResponse: func(ctx context.Context, call *Call) {
+			events = append(events, "reply "+call.Reply.(*LogEntry).Name)
+		},
+		OnError: func(ctx context.Context, call *Call, err error) {
+			events = append(events, fmt.Sprintf("error %v", err))
+		},
+	}
+	dash, err := New("client", "http://dashboard", "key", transport, scrub, record, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &LogEntry{Text: "the secret"}
+	if err := dash.Query(context.Background(), "test", req, new(LogEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if req.Text != "the secret" {
+		t.Fatalf("the caller's request was modified")
+	}
+	if err := dash.Query(context.Background(), "test", &LogEntry{Text: "fail"}, new(LogEntry)); err == nil {
+		t.Fatalf("the failed request succeeded")
+	}
+	if err := dash.Query(context.Background(), "test", &LogEntry{Text: "deny"}, nil); !errors.Is(err, errDenied) {
+		t.Fatalf("got %v, want %v", err, errDenied)
+	}
+	sentWant := []string{"the ***", "fail"}
+	if fmt.Sprint(sent) != fmt.Sprint(sentWant) {
+		t.Fatalf("sent %q, want %q", sent, sentWant)
+	}
+	eventsWant := []string{
+		"request the ***",
+		"reply reply",
+		"request fail",
+		"error request failed with Bad Request: failed",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(eventsWant) {
+		t.Fatalf("got events %q, want %q", events, eventsWant)
+	}
+}
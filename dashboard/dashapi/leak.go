@@ -0,0 +1,92 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+// Memory leak reports (e.g. KMEMLEAK) are not oopses: they are produced long after the leaking code
+// has run, so the stack of the reporting task says nothing about the bug, and many of them are
+// false positives. Crashes of the CrashLeak kind describe the leaked object in Crash.Leak.
+// The dashboard deduplicates leaks by the allocation stack and does not report them until
+// they are reproduced.
+
+type CrashKind string
+
+const (
+	CrashOops CrashKind = ""
+	CrashLeak CrashKind = "leak"
+)
+
+type Leak struct {
+	Size  int      // of the leaked object in bytes
+	Comm  string   // the task that allocated the object
+	Stack []string // functions of the allocation stack, innermost first
+}
+
+// Allocation stacks are cut at this depth, the outermost frames are syscall entry boilerplate.
+const maxLeakFrames = 32
+
+var (
+	leakObjectRe = regexp.MustCompile(`^unreferenced object 0x[0-9a-f]+ \(size ([0-9]+)\):`)
+	leakCommRe   = regexp.MustCompile(`^comm "([^"]*)"`)
+	// [<ffffffff81234567>] func+0x1a/0x2b file.c:12, the address and the offsets are optional.
+	leakFrameRe = regexp.MustCompile(`^(?:\[<[0-9a-f]+>\]\s+)?([a-zA-Z_][a-zA-Z0-9_.$]*)(?:\+0x[0-9a-f]+/0x[0-9a-f]+)?(?:\s|$)`)
+)
+
+// ParseLeak extracts the first leaked object from a kmemleak report, it returns nil
+// if the report does not describe a leaked object.
+func ParseLeak(report []byte) *Leak {
+	var leak *Leak
+	inStack := false
+	for _, line := range bytes.Split(report, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if match := leakObjectRe.FindSubmatch(line); match != nil {
+			if leak != nil {
+				break
+			}
+			size, _ := strconv.Atoi(string(match[1]))
+			leak = &Leak{Size: size}
+			continue
+		}
+		if leak == nil {
+			continue
+		}
+		if match := leakCommRe.FindSubmatch(line); match != nil && leak.Comm == "" {
+			leak.Comm = string(match[1])
+			continue
+		}
+		// "backtrace:" or "backtrace (crc 7b4a3f6c):" in newer kernels.
+		if bytes.HasPrefix(line, []byte("backtrace")) {
+			inStack = true
+			continue
+		}
+		if !inStack {
+			continue
+		}
+		match := leakFrameRe.FindSubmatch(line)
+		if match == nil {
+			if len(leak.Stack) != 0 {
+				inStack = false
+			}
+			continue
+		}
+		if len(leak.Stack) < maxLeakFrames {
+			leak.Stack = append(leak.Stack, string(match[1]))
+		}
+	}
+	return leak
+}
+
+// Signature returns the crash signature of the leak (see Crash.Signature),
+// leaks of objects allocated at the same place are the same bug.
+func (leak *Leak) Signature() string {
+	if leak == nil || len(leak.Stack) == 0 {
+		return ""
+	}
+	return framesSignature(leak.Stack)
+}
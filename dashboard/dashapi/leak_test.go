@@ -0,0 +1,58 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLeak(t *testing.T) {
+	const report = `BUG: memory leak
+unreferenced object 0xffff888107f8b700 (size 64):
+  comm "syz-executor.0", pid 8046, jiffies 4294954789 (age 13.850s)
+  hex dump (first 32 bytes):
+    00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00  ................
+  backtrace:
+    [<00000000d2a5ac8e>] kmemleak_alloc_recursive include/linux/kmemleak.h:43 [inline]
+    [<00000000d2a5ac8e>] slab_post_alloc_hook+0x7a/0x3c0 mm/slab.h:522
+    [<0000000051b4e7e0>] foo_alloc+0x1a/0x60 fs/foo.c:120
+    [<00000000a8e5b3b1>] do_syscall_64+0x2d/0x70 arch/x86/entry/common.c:46
+
+unreferenced object 0xffff888107f8b800 (size 32):
+  comm "syz-executor.0", pid 8046, jiffies 4294954789 (age 13.850s)
+  backtrace:
+    [<0000000051b4e7e0>] bar_alloc+0x1a/0x60 fs/bar.c:12
+`
+	// Newer kernels don't print frame addresses.
+	const report2 = `BUG: memory leak
+unreferenced object 0xffff888107f8b900 (size 128):
+  comm "syz-executor.1", pid 8047, jiffies 4294954790
+  backtrace (crc 7b4a3f6c):
+    kmemleak_alloc_recursive include/linux/kmemleak.h:42 [inline]
+    slab_post_alloc_hook mm/slub.c:3817 [inline]
+    foo_alloc fs/foo.c:125 [inline]
+    do_syscall_64+0xcd/0x250 arch/x86/entry/common.c:83
+`
+	leak := ParseLeak([]byte(report))
+	want := &Leak{
+		Size:  64,
+		Comm:  "syz-executor.0",
+		Stack: []string{"kmemleak_alloc_recursive", "slab_post_alloc_hook", "foo_alloc", "do_syscall_64"},
+	}
+	if !reflect.DeepEqual(leak, want) {
+		t.Fatalf("got %+v, want %+v", leak, want)
+	}
+	leak2 := ParseLeak([]byte(report2))
+	if leak2 == nil || leak2.Size != 128 || !reflect.DeepEqual(leak2.Stack, want.Stack) {
+		t.Fatalf("got %+v", leak2)
+	}
+	// The same allocation stack is the same bug regardless of line numbers and offsets.
+	if leak.Signature() == "" || leak.Signature() != leak2.Signature() {
+		t.Errorf("got signatures %q and %q", leak.Signature(), leak2.Signature())
+	}
+	if leak := ParseLeak([]byte("BUG: KASAN: use-after-free in foo")); leak != nil {
+		t.Errorf("got leak %+v for an oops", leak)
+	}
+}
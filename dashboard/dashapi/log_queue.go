@@ -0,0 +1,93 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// LogErrorQueue is the max number of LogError (and LogWarn, LogInfo, Log) messages waiting
+// to be sent to the dashboard.
+// LogError doesn't wait for the dashboard, messages are queued and sent in the background.
+// Messages that don't fit into the queue are dropped (see DroppedLogErrors).
+type LogErrorQueue int
+
+const DefaultLogErrorQueue = LogErrorQueue(100)
+
+type logQueue struct {
+	size    int
+	mu      sync.Mutex
+	entries []queuedLog
+	done    chan struct{} // non-nil while the sender is running, closed when it exits
+	dropped atomic.Uint64
+}
+
+type queuedLog struct {
+	ctx   context.Context
+	entry *LogEntry
+}
+
+func newLogQueue(size LogErrorQueue) *logQueue {
+	if size <= 0 {
+		size = DefaultLogErrorQueue
+	}
+	return &logQueue{size: int(size)}
+}
+
+// push queues the entry and starts the sender if it's not running.
+// The sender exits once the queue is empty, so idle clients have no background goroutines.
+func (q *logQueue) push(ctx context.Context, dash *Dashboard, entry *LogEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.entries) >= q.size {
+		q.dropped.Add(1)
+		dash.metrics.recordDroppedLog()
+		return
+	}
+	// The caller may cancel the context as soon as LogError returns.
+	q.entries = append(q.entries, queuedLog{context.WithoutCancel(ctx), entry})
+	if q.done == nil {
+		q.done = make(chan struct{})
+		go q.send(dash, q.done)
+	}
+}
+
+func (q *logQueue) send(dash *Dashboard, done chan struct{}) {
+	for {
+		q.mu.Lock()
+		if len(q.entries) == 0 {
+			q.done = nil
+			q.mu.Unlock()
+			close(done)
+			return
+		}
+		next := q.entries[0]
+		q.entries = q.entries[1:]
+		q.mu.Unlock()
+		dash.Query(next.ctx, "log_error", next.entry, nil)
+	}
+}
+
+// FlushLogErrors waits until all queued LogError messages are sent (or fail).
+func (dash *Dashboard) FlushLogErrors(ctx context.Context) error {
+	dash.logQueue.mu.Lock()
+	done := dash.logQueue.done
+	dash.logQueue.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DroppedLogErrors returns the number of LogError messages dropped because the queue was full.
+func (dash *Dashboard) DroppedLogErrors() uint64 {
+	return dash.logQueue.dropped.Load()
+}
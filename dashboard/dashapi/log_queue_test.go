@@ -0,0 +1,105 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLogErrorQueue(t *testing.T) {
+	var mu sync.Mutex
+	var logged []string
+	unblock := make(chan struct{})
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		<-unblock
+		entry := new(LogEntry)
+		if err := json.Unmarshal(payload, entry); err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		logged = append(logged, entry.Text)
+		mu.Unlock()
+		return nil, nil
+	})
+	dash.logQueue = newLogQueue(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	// The first message is picked by the sender (or stays in the queue), and two fit into the queue.
+	// LogError must not block while the dashboard doesn't reply.
+	for i := 0; i < 10; i++ {
+		dash.LogError(ctx, "name", "msg %v", i)
+	}
+	cancel()
+	if dropped := dash.DroppedLogErrors(); dropped < 7 || dropped > 8 {
+		t.Fatalf("dropped %v messages, want 7 or 8", dropped)
+	}
+	close(unblock)
+	if err := dash.FlushLogErrors(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if len(logged) != 10-int(dash.DroppedLogErrors()) || logged[0] != "msg 0" {
+		t.Fatalf("bad logged messages: %q", logged)
+	}
+	for i := 1; i < len(logged); i++ {
+		if logged[i] <= logged[i-1] {
+			t.Fatalf("messages are out of order: %q", logged)
+		}
+	}
+	mu.Unlock()
+	// The sender has exited, the next message starts a new one.
+	dash.LogError(context.Background(), "name", "msg %v", 10)
+	if err := dash.FlushLogErrors(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last := logged[len(logged)-1]; last != fmt.Sprintf("msg %v", 10) {
+		t.Fatalf("the last message is %q", last)
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	var mu sync.Mutex
+	var logged []*LogEntry
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		entry := new(LogEntry)
+		if err := json.Unmarshal(payload, entry); err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		logged = append(logged, entry)
+		mu.Unlock()
+		return nil, nil
+	})
+	dash.LogError(context.Background(), "name", "error %v", 1)
+	dash.LogWarn(context.Background(), "name", "warn %v", 2)
+	dash.LogInfo(context.Background(), "name", "info %v", 3)
+	dash.Log(context.Background(), &LogEntry{
+		Name:   "name",
+		Text:   "debug",
+		Level:  LogLevelDebug,
+		Fields: map[string]string{"manager": "ci-upstream"},
+	})
+	if err := dash.FlushLogErrors(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []LogLevel{LogLevelError, LogLevelWarn, LogLevelInfo, LogLevelDebug}
+	if len(logged) != len(want) {
+		t.Fatalf("got %v messages, want %v", len(logged), len(want))
+	}
+	for i, entry := range logged {
+		if entry.Level != want[i] || entry.Time.IsZero() {
+			t.Fatalf("bad message #%v: %+v", i, entry)
+		}
+	}
+	if logged[1].Text != "warn 2" || logged[3].Fields["manager"] != "ci-upstream" {
+		t.Fatalf("bad messages: %+v %+v", logged[1], logged[3])
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/email"
+)
+
+// Command is a "#syz" command of a reply converted to a dashboard request.
+type Command struct {
+	*email.SingleCommand // nil for emails without commands
+	// Update is passed to Dashboard.ReportingUpdate, it's set for all commands but #syz test.
+	Update *dashapi.BugUpdate
+	// Test is passed to Dashboard.NewTestJob for #syz test.
+	Test *dashapi.TestPatchRequest
+	// Error is set instead of the requests if the command is malformed or is not supported,
+	// it's meant to be replied to the author.
+	Error string
+}
+
+// Commands converts the commands of the email to requests for the bug with the reporting ID
+// (normally msg.BugIDs[0], email.Parse extracts it from the address context added by Report).
+// If there are no commands, the only update records the email, e.g. its Message-ID and CC.
+// The commands copied from the report emails (e.g. "#syz fix: exact-commit-title") are skipped.
+func Commands(msg *email.Email, bugID string, cfg *Config) []*Command {
+	var ret []*Command
+	cmds := ExcludeSampleCommands(msg.Commands)
+	for _, cmd := range cmds {
+		ret = append(ret, command(msg, bugID, cfg, cmd))
+	}
+	if len(cmds) == 0 {
+		ret = append(ret, command(msg, bugID, cfg, nil))
+	}
+	return ret
+}
+
+func command(msg *email.Email, bugID string, cfg *Config, cmd *email.SingleCommand) *Command {
+	ret := &Command{SingleCommand: cmd}
+	upd := &dashapi.BugUpdate{
+		Status: dashapi.BugStatusUpdate,
+		ID:     bugID,
+		ExtID:  msg.MessageID,
+		Link:   msg.Link,
+		CC:     msg.Cc,
+	}
+	if cmd == nil {
+		ret.Update = upd
+		return ret
+	}
+	upd.Status, _ = CommandStatus(cmd.Command)
+	switch cmd.Command {
+	case email.CmdTest:
+		args := strings.Fields(cmd.Args)
+		if len(args) != 0 && len(args) != 2 {
+			ret.Error = fmt.Sprintf("want either no args or 2 args (repo, branch), got %v", len(args))
+			return ret
+		}
+		ret.Test = &dashapi.TestPatchRequest{
+			BugID: bugID,
+			Link:  msg.Link,
+			User:  msg.Author,
+			Patch: []byte(msg.Patch),
+		}
+		if len(args) == 2 {
+			ret.Test.Repo, ret.Test.Branch = args[0], args[1]
+		}
+		return ret
+	case email.CmdUpstream, email.CmdInvalid, email.CmdUnDup:
+	case email.CmdFix:
+		if cmd.Args == "" {
+			ret.Error = "no commit title"
+			return ret
+		}
+		upd.FixCommits = []string{cmd.Args}
+	case email.CmdUnFix:
+		upd.ResetFixCommits = true
+	case email.CmdDup:
+		if cmd.Args == "" {
+			ret.Error = "no dup title"
+			return ret
+		}
+		var err error
+		upd.DupOf, err = NewTitleParser(cfg.SubjectPrefix).Parse(cmd.Args)
+		if err != nil {
+			ret.Error = "failed to parse the dup title"
+			return ret
+		}
+	case email.CmdUnCC:
+		upd.CC = []string{msg.Author}
+	default:
+		ret.Error = fmt.Sprintf("unknown command %q", cmd.Str)
+		return ret
+	}
+	ret.Update = upd
+	return ret
+}
+
+var commandStatus = map[email.Command]dashapi.BugStatus{
+	email.CmdUpstream: dashapi.BugStatusUpstream,
+	email.CmdInvalid:  dashapi.BugStatusInvalid,
+	email.CmdUnDup:    dashapi.BugStatusOpen,
+	email.CmdFix:      dashapi.BugStatusOpen,
+	email.CmdUnFix:    dashapi.BugStatusUpdate,
+	email.CmdDup:      dashapi.BugStatusDup,
+	email.CmdUnCC:     dashapi.BugStatusUnCC,
+}
+
+// CommandStatus returns the status of the BugUpdate for the command,
+// it returns false for the commands that are not bug updates (e.g. #syz test).
+func CommandStatus(cmd email.Command) (dashapi.BugStatus, bool) {
+	status, ok := commandStatus[cmd]
+	return status, ok
+}
+
+// ExcludeSampleCommands removes the commands that repeat the examples of the report emails.
+// Sometimes it happens that somebody sends us our own text back.
+func ExcludeSampleCommands(cmds []*email.SingleCommand) []*email.SingleCommand {
+	var ret []*email.SingleCommand
+	for _, cmd := range cmds {
+		ok := true
+		switch cmd.Command {
+		case email.CmdFix:
+			ok = cmd.Args != "exact-commit-title"
+		case email.CmdTest:
+			ok = cmd.Args != "git://repo/address.git branch-or-commit-hash"
+		case email.CmdSet:
+			ok = cmd.Args != "subsystems: new-subsystem"
+		case email.CmdUnset:
+			ok = cmd.Args != "some-label"
+		case email.CmdDup:
+			ok = cmd.Args != "exact-subject-of-another-report"
+		}
+		if ok {
+			ret = append(ret, cmd)
+		}
+	}
+	return ret
+}
+
+// TitleParser extracts bug titles from email subjects (e.g. of #syz dup commands).
+type TitleParser struct {
+	pattern *regexp.Regexp
+}
+
+// NewTitleParser creates a parser that strips the reply prefixes, the subject prefixes
+// and the subsystem tags (see Subject and Title).
+func NewTitleParser(subjectPrefixes ...string) *TitleParser {
+	stripPrefixes := []string{`R[eE]:`}
+	for _, prefix := range subjectPrefixes {
+		if prefix != "" {
+			stripPrefixes = append(stripPrefixes, regexp.QuoteMeta(prefix))
+		}
+	}
+	rePrefixes := `^(?:(?:` + strings.Join(stripPrefixes, "|") + `)\s*)*`
+	pattern := regexp.MustCompile(rePrefixes + `(?:\[[^\]]+\]\s*)*\s*(.*)$`)
+	return &TitleParser{pattern}
+}
+
+// Parse returns the full bug title, i.e. with the "(N)" suffix for the repeated bug titles.
+func (p *TitleParser) Parse(subject string) (string, error) {
+	subject = strings.TrimSpace(subject)
+	parts := p.pattern.FindStringSubmatch(subject)
+	if parts == nil || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("failed to extract the title")
+	}
+	return parts[len(parts)-1], nil
+}
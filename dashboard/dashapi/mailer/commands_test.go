@@ -0,0 +1,107 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/email"
+)
+
+func TestCommands(t *testing.T) {
+	const reply = `Date: Sun, 7 May 2017 19:54:00 -0700
+Message-ID: <123>
+Subject: Re: [syzbot] WARNING in foo
+From: Developer <dev@kernel.org>
+To: syzbot <syzbot+abcdef@example.com>
+Cc: other@kernel.org
+
+#syz fix: net: fix foo
+#syz dup: Re: [syzbot] [net?] WARNING in bar (2)
+#syz test: git://git.kernel.org/linux.git master
+#syz fix: exact-commit-title
+#syz set subsystems: net
+`
+	msg, err := email.Parse(strings.NewReader(reply), []string{"syzbot@example.com"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.BugIDs) != 1 || msg.BugIDs[0] != "abcdef" {
+		t.Fatalf("bad bug IDs %q", msg.BugIDs)
+	}
+	got := Commands(msg, msg.BugIDs[0], &Config{SubjectPrefix: "[syzbot]"})
+	want := []*Command{
+		{
+			Update: &dashapi.BugUpdate{
+				ID:         "abcdef",
+				ExtID:      "<123>",
+				Status:     dashapi.BugStatusOpen,
+				FixCommits: []string{"net: fix foo"},
+				CC:         []string{"dev@kernel.org", "other@kernel.org"},
+			},
+		},
+		{
+			Update: &dashapi.BugUpdate{
+				ID:     "abcdef",
+				ExtID:  "<123>",
+				Status: dashapi.BugStatusDup,
+				DupOf:  "WARNING in bar (2)",
+				CC:     []string{"dev@kernel.org", "other@kernel.org"},
+			},
+		},
+		{
+			Test: &dashapi.TestPatchRequest{
+				BugID:  "abcdef",
+				User:   "dev@kernel.org",
+				Repo:   "git://git.kernel.org/linux.git",
+				Branch: "master",
+				Patch:  []byte{},
+			},
+		},
+		{
+			Error: `unknown command "set"`,
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Command{}, "SingleCommand")); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// An email without commands only records the email.
+	msg.Commands = nil
+	got = Commands(msg, "abcdef", &Config{})
+	want = []*Command{{
+		Update: &dashapi.BugUpdate{
+			ID:     "abcdef",
+			ExtID:  "<123>",
+			Status: dashapi.BugStatusUpdate,
+			CC:     []string{"dev@kernel.org", "other@kernel.org"},
+		},
+	}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Command{}, "SingleCommand")); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTitleParser(t *testing.T) {
+	p := NewTitleParser("[syzbot]", "")
+	for subject, want := range map[string]string{
+		"WARNING in foo":                          "WARNING in foo",
+		"Re: [syzbot] WARNING in foo (3)":         "WARNING in foo (3)",
+		"RE: Re: [syzbot] [net?] [fs] KASAN: bug": "KASAN: bug",
+		"Re: [syzbot] [net?]":                     "",
+	} {
+		got, err := p.Parse(subject)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q: expected error, got %q", subject, got)
+			}
+		} else if got != want {
+			t.Errorf("%q: got %q, want %q", subject, got, want)
+		}
+	}
+}
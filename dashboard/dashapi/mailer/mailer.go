@@ -0,0 +1,266 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package mailer implements the email reporting of the dashboard for self-hosted mailers:
+// it renders dashapi.BugReport into the same emails the dashboard sends, threads them
+// by Message-ID and converts "#syz" commands of the replies into dashapi requests.
+// A mailer polls the bugs of an external reporting, sends Report messages and acknowledges
+// them with ReportUpdate, then handles the replies parsed with email.Parse with Commands.
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/email"
+	"github.com/google/syzkaller/pkg/html"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// Config has the same JSON form as the relevant part of EmailConfig of the dashboard
+// (see dashboard/app/reporting_email.go), so it can be unmarshalled from BugReport.Config.
+type Config struct {
+	Email              string // the mailing list
+	MailMaintainers    bool
+	DefaultMaintainers []string
+	SubjectPrefix      string
+}
+
+type Message struct {
+	From      string
+	To        []string
+	Subject   string
+	MessageID string
+	InReplyTo string // the Message-ID of the first report of the bug, if it's not the first one
+	Date      time.Time
+	Body      string
+	// The kernel config, the console log and the reproducers of the crash.
+	// The body links them anyway, so callers may drop the attachments.
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+//go:embed templates/*.txt
+var templatesFS embed.FS
+
+var templates = texttemplate.Must(texttemplate.New("").Funcs(html.Funcs).ParseFS(templatesFS, "templates/*.txt"))
+
+// ReplySubjectPrefix is added to the subjects of all emails in bug threads but the first one.
+// This plays an important role at least for job replies. If we CC a kernel mailing list
+// and it uses Patchwork, then any emails with a patch attached create a new patch entry
+// pending for review. The prefix makes Patchwork treat it as a comment for a previous patch.
+const ReplySubjectPrefix = "Re: "
+
+// Report renders the email for rep. The bug reporting ID is added to the from address as the context
+// (e.g. syzbot+ID@example.com), so that email.Parse extracts it from the replies.
+func Report(rep *dashapi.BugReport, cfg *Config, from string) (*Message, error) {
+	body, err := Body(rep)
+	if err != nil {
+		return nil, err
+	}
+	from, err = email.AddAddrContext(from, rep.ID)
+	if err != nil {
+		return nil, err
+	}
+	to := email.MergeEmailLists([]string{cfg.Email}, rep.CC)
+	if cfg.MailMaintainers && rep.Type != dashapi.ReportTestPatch {
+		to = email.MergeEmailLists(to, rep.Maintainers, cfg.DefaultMaintainers)
+	}
+	msg := &Message{
+		From:      from,
+		To:        to,
+		Subject:   Subject(Title(rep), cfg.SubjectPrefix, rep.ExtID != ""),
+		InReplyTo: rep.ExtID,
+		Date:      time.Now(),
+		Body:      body,
+	}
+	msg.MessageID, err = MessageID(from, msg.Date)
+	if err != nil {
+		return nil, err
+	}
+	for _, att := range []Attachment{
+		{"config.txt", rep.KernelConfig},
+		{"raw.log.txt", rep.Log},
+		{"repro.syz.txt", rep.ReproSyz},
+		{"repro.c.txt", rep.ReproC},
+	} {
+		if len(att.Data) != 0 && !rep.EncryptedPayloads {
+			msg.Attachments = append(msg.Attachments, att)
+		}
+	}
+	return msg, nil
+}
+
+// Body renders the text of the email for the report type.
+func Body(rep *dashapi.BugReport) (string, error) {
+	if rep.UserSpaceArch == targets.AMD64 {
+		// This is default, so don't include the info.
+		rep.UserSpaceArch = ""
+	}
+	templ := ""
+	switch rep.Type {
+	case dashapi.ReportNew, dashapi.ReportRepro:
+		templ = "mail_bug.txt"
+	case dashapi.ReportTestPatch:
+		templ = "mail_test_result.txt"
+	case dashapi.ReportBisectCause:
+		templ = "mail_bisect_result.txt"
+	case dashapi.ReportBisectFix:
+		if rep.BisectFix.CrossTree {
+			templ = "mail_fix_candidate.txt"
+			if rep.BisectFix.Commit == nil {
+				return "", fmt.Errorf("reporting failed fix candidate bisection for %s", rep.ID)
+			}
+		} else {
+			templ = "mail_bisect_result.txt"
+		}
+	default:
+		return "", fmt.Errorf("unknown report type %v", rep.Type)
+	}
+	body := new(bytes.Buffer)
+	if err := templates.ExecuteTemplate(body, templ, rep); err != nil {
+		return "", fmt.Errorf("failed to execute %v template: %w", templ, err)
+	}
+	return body.String(), nil
+}
+
+// Title returns the bug title with the bug subsystems, e.g. "[net?] WARNING in foo".
+func Title(rep *dashapi.BugReport) string {
+	title := ""
+	for i := len(rep.Subsystems) - 1; i >= 0; i-- {
+		question := ""
+		if rep.Subsystems[i].SetBy == "" {
+			// Include the question mark for automatically created tags.
+			question = "?"
+		}
+		title = fmt.Sprintf("[%s%s] %s", rep.Subsystems[i].Name, question, title)
+	}
+	return title + rep.Title
+}
+
+// Subject adds the subject prefix and, for replies, ReplySubjectPrefix to the title.
+func Subject(title, prefix string, reply bool) string {
+	if prefix != "" {
+		title = prefix + " " + title
+	}
+	if reply && !strings.HasPrefix(title, ReplySubjectPrefix) {
+		title = ReplySubjectPrefix + title
+	}
+	return title
+}
+
+// MessageID generates a unique Message-ID in the domain of the from address.
+func MessageID(from string, date time.Time) (string, error) {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q as email: %w", from, err)
+	}
+	local, domain, ok := strings.Cut(addr.Address, "@")
+	if !ok {
+		return "", fmt.Errorf("failed to parse %q as email: no @", from)
+	}
+	// The address context is the bug reporting ID, so together with the time the ID is unique.
+	_, context, _ := strings.Cut(local, "+")
+	return fmt.Sprintf("<%x.%v@%v>", date.UnixNano(), context, domain), nil
+}
+
+// ReportUpdate acknowledges the report sent with the message, messageID becomes the ExtID of the bug
+// that the next reports are threaded on (the dashboard keeps the ExtID of the first report).
+func ReportUpdate(rep *dashapi.BugReport, messageID string) *dashapi.BugUpdate {
+	upd := &dashapi.BugUpdate{
+		ID:         rep.ID,
+		ExtID:      messageID,
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: dashapi.ReproLevelNone,
+		CrashID:    rep.CrashID,
+	}
+	if len(rep.ReproC) != 0 {
+		upd.ReproLevel = dashapi.ReproLevelC
+	} else if len(rep.ReproSyz) != 0 {
+		upd.ReproLevel = dashapi.ReproLevelSyz
+	}
+	for label := range rep.LabelMessages {
+		upd.Labels = append(upd.Labels, label)
+	}
+	sort.Strings(upd.Labels)
+	return upd
+}
+
+// Bytes formats the message as MIME, the body and the attachments are sent as separate parts.
+func (msg *Message) Bytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %v\r\n", msg.From)
+	fmt.Fprintf(buf, "To: %v\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(buf, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(buf, "Date: %v\r\n", msg.Date.Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "Message-ID: %v\r\n", msg.MessageID)
+	if msg.InReplyTo != "" {
+		fmt.Fprintf(buf, "In-Reply-To: %v\r\n", msg.InReplyTo)
+		fmt.Fprintf(buf, "References: %v\r\n", msg.InReplyTo)
+	}
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	if len(msg.Attachments) == 0 {
+		fmt.Fprintf(buf, "Content-Type: %v\r\n", textContentType)
+		fmt.Fprintf(buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(buf, []byte(msg.Body)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	mw := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	if err := writePart(mw, "", []byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	for _, att := range msg.Attachments {
+		if err := writePart(mw, att.Name, att.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const textContentType = `text/plain; charset="utf-8"`
+
+// writePart writes a text part, it's an attachment if the file name is set.
+func writePart(mw *multipart.Writer, fileName string, data []byte) error {
+	header := textproto.MIMEHeader{
+		"Content-Type":              {textContentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+	if fileName != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, data)
+}
+
+func writeQuotedPrintable(w io.Writer, data []byte) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write(data); err != nil {
+		return err
+	}
+	return qp.Close()
+}
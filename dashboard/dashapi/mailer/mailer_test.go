@@ -0,0 +1,208 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+func testReport() *dashapi.BugReport {
+	return &dashapi.BugReport{
+		Type:              dashapi.ReportNew,
+		ID:                "abcdef",
+		First:             true,
+		Title:             "WARNING in foo",
+		Link:              "https://syzbot/bug?extid=abcdef",
+		CreditEmail:       "syzbot+abcdef@example.com",
+		Maintainers:       []string{"maintainer@kernel.org"},
+		CC:                []string{"cc@kernel.org"},
+		UserSpaceArch:     "amd64",
+		CompilerID:        "gcc",
+		KernelRepoAlias:   "upstream",
+		KernelCommit:      "1111111111111111111111111111111111111111",
+		KernelCommitTitle: "Linux 6.8",
+		KernelConfig:      []byte("CONFIG_KASAN=y\n"),
+		KernelConfigLink:  "https://syzbot/config",
+		Log:               []byte("console log\n"),
+		LogLink:           "https://syzbot/log",
+		Report:            []byte("WARNING: foo\n"),
+		ReproSyz:          []byte("foo()\n"),
+		ReproSyzLink:      "https://syzbot/repro.syz",
+		Subsystems:        []dashapi.BugSubsystem{{Name: "net"}, {Name: "fs", SetBy: "user"}},
+		LabelMessages:     map[string]string{"prio:low": "", "no-reminders": ""},
+		CrashID:           1,
+	}
+}
+
+func TestReport(t *testing.T) {
+	rep := testReport()
+	cfg := &Config{
+		Email:              "bugs@lists.example.com",
+		MailMaintainers:    true,
+		DefaultMaintainers: []string{"lkml@kernel.org"},
+		SubjectPrefix:      "[syzbot]",
+	}
+	msg, err := Report(rep, cfg, `"syzbot" <syzbot@example.com>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.From != `"syzbot" <syzbot+abcdef@example.com>` {
+		t.Errorf("bad from %q", msg.From)
+	}
+	wantTo := []string{"bugs@lists.example.com", "cc@kernel.org", "lkml@kernel.org", "maintainer@kernel.org"}
+	if diff := cmp.Diff(wantTo, msg.To); diff != "" {
+		t.Errorf("bad to: %v", diff)
+	}
+	if msg.Subject != "[syzbot] [net?] [fs] WARNING in foo" || msg.InReplyTo != "" {
+		t.Errorf("bad subject %q, in-reply-to %q", msg.Subject, msg.InReplyTo)
+	}
+	if !strings.HasPrefix(msg.MessageID, "<") || !strings.HasSuffix(msg.MessageID, ".abcdef@example.com>") {
+		t.Errorf("bad message id %q", msg.MessageID)
+	}
+	for _, line := range []string{
+		"syzbot found the following issue on:",
+		"HEAD commit:    111111111111 Linux 6.8",
+		"kernel config:  https://syzbot/config",
+		"syz repro:      https://syzbot/repro.syz",
+		"Reported-by: syzbot+abcdef@example.com",
+		"#syz fix: exact-commit-title",
+	} {
+		if !strings.Contains(msg.Body, line+"\n") {
+			t.Errorf("no %q in the body:\n%s", line, msg.Body)
+		}
+	}
+	if strings.Contains(msg.Body, "userspace arch") {
+		t.Errorf("the default userspace arch is in the body:\n%s", msg.Body)
+	}
+	var names []string
+	for _, att := range msg.Attachments {
+		names = append(names, att.Name)
+	}
+	if diff := cmp.Diff([]string{"config.txt", "raw.log.txt", "repro.syz.txt"}, names); diff != "" {
+		t.Errorf("bad attachments: %v", diff)
+	}
+
+	// The next reports are sent to the same thread.
+	rep = testReport()
+	rep.Type, rep.First, rep.ExtID = dashapi.ReportRepro, false, msg.MessageID
+	next, err := Report(rep, cfg, "syzbot@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Subject != "Re: [syzbot] [net?] [fs] WARNING in foo" || next.InReplyTo != msg.MessageID {
+		t.Errorf("bad subject %q, in-reply-to %q", next.Subject, next.InReplyTo)
+	}
+	if !strings.Contains(next.Body, "syzbot has found a reproducer for the following issue on:\n") {
+		t.Errorf("bad body:\n%s", next.Body)
+	}
+
+	// Maintainers are not mailed with patch testing results.
+	rep = testReport()
+	rep.Type = dashapi.ReportTestPatch
+	job, err := Report(rep, cfg, "syzbot@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"bugs@lists.example.com", "cc@kernel.org"}, job.To); diff != "" {
+		t.Errorf("bad to: %v", diff)
+	}
+}
+
+func TestReportEncrypted(t *testing.T) {
+	rep := testReport()
+	rep.EncryptedPayloads = true
+	rep.Report, rep.Log, rep.KernelConfig = nil, nil, nil
+	msg, err := Report(rep, &Config{Email: "bugs@lists.example.com"}, "syzbot@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.Body, "The report is encrypted, see the dashboard link.\n") {
+		t.Errorf("no encryption note in the body:\n%s", msg.Body)
+	}
+	if len(msg.Attachments) != 0 {
+		t.Errorf("encrypted payloads are attached: %+v", msg.Attachments)
+	}
+}
+
+func TestReportUpdate(t *testing.T) {
+	got := ReportUpdate(testReport(), "<1.abcdef@example.com>")
+	want := &dashapi.BugUpdate{
+		ID:         "abcdef",
+		ExtID:      "<1.abcdef@example.com>",
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: dashapi.ReproLevelSyz,
+		CrashID:    1,
+		Labels:     []string{"no-reminders", "prio:low"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	msg, err := Report(testReport(), &Config{Email: "bugs@lists.example.com"}, "syzbot@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.InReplyTo = "<0.abcdef@example.com>"
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"From":        "syzbot+abcdef@example.com",
+		"To":          "bugs@lists.example.com, cc@kernel.org",
+		"Subject":     "[net?] [fs] WARNING in foo",
+		"Message-Id":  msg.MessageID,
+		"In-Reply-To": "<0.abcdef@example.com>",
+		"References":  "<0.abcdef@example.com>",
+	} {
+		if got := parsed.Header.Get(key); got != want {
+			t.Errorf("%v: got %q, want %q", key, got, want)
+		}
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("bad content type %q: %v", mediaType, err)
+	}
+	var parts []string
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Text parts are in the canonical form with CRLF line endings.
+		parts = append(parts, part.FileName()+":"+strings.ReplaceAll(string(data), "\r\n", "\n"))
+	}
+	want := []string{
+		":" + msg.Body,
+		"config.txt:CONFIG_KASAN=y\n",
+		"raw.log.txt:console log\n",
+		"repro.syz.txt:foo()\n",
+	}
+	if diff := cmp.Diff(want, parts); diff != "" {
+		t.Fatal(diff)
+	}
+}
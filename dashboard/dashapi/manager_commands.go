@@ -0,0 +1,80 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// Admins issue commands to managers on the manager page of the dashboard. Managers poll
+// ManagerCommands and acknowledge every command with AckManagerCommand once it's executed,
+// after that the command is not returned anymore.
+
+type ManagerCommandType string
+
+const (
+	// The manager shuts down.
+	ManagerCommandStopFuzzing ManagerCommandType = "stop_fuzzing"
+	// The manager reproduces the bug with ManagerCommand.Arg title again,
+	// ManagerCommand.CrashLog is the log of the last crash of the bug.
+	ManagerCommandRerunRepro ManagerCommandType = "rerun_repro"
+	// The manager runs ManagerCommand.ReproSyz of the bug with ManagerCommand.Arg ID on its current build
+	// and uploads the result with UploadRetestResult. The dashboard queues it once the build of the manager
+	// contains the fixing commits, so that there is a positive confirmation that the bug is fixed.
+	ManagerCommandRetest ManagerCommandType = "retest"
+)
+
+type ManagerCommandsReq struct {
+	Manager string
+}
+
+type ManagerCommandsResp struct {
+	Commands []*ManagerCommand // in the order they were issued
+}
+
+type ManagerCommand struct {
+	ID       int64
+	Type     ManagerCommandType
+	Arg      string
+	CrashLog []byte
+	Author   string
+	Time     time.Time
+
+	// The reproducer for ManagerCommandRetest.
+	ReproSyz  []byte
+	ReproOpts []byte
+}
+
+type AckManagerCommandReq struct {
+	Manager string
+	ID      int64
+	Error   string // empty if the command was executed successfully
+}
+
+// ManagerCommands returns the commands the manager has not acknowledged yet.
+func (dash *Dashboard) ManagerCommands(ctx context.Context, manager string) ([]*ManagerCommand, error) {
+	resp := new(ManagerCommandsResp)
+	err := dash.Query(ctx, "manager_commands", &ManagerCommandsReq{Manager: manager}, resp)
+	return resp.Commands, err
+}
+
+func (dash *Dashboard) AckManagerCommand(ctx context.Context, req *AckManagerCommandReq) error {
+	return dash.Query(ctx, "ack_manager_command", req, nil)
+}
+
+// RetestResult is the result of ManagerCommandRetest. Managers acknowledge the command once
+// the retest is started and upload the result when it's done.
+type RetestResult struct {
+	Manager    string
+	CommandID  int64
+	BuildID    string // the build the reproducer was run on
+	Crashed    bool
+	CrashTitle string
+	Error      string // set if the reproducer could not be run
+}
+
+func (dash *Dashboard) UploadRetestResult(ctx context.Context, req *RetestResult) error {
+	return dash.Query(ctx, "retest_result", req, nil)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestManagerCommands(t *testing.T) {
+	var acked []*AckManagerCommandReq
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		switch method {
+		case "manager_commands":
+			req := new(ManagerCommandsReq)
+			if err := json.Unmarshal(payload, req); err != nil {
+				t.Fatal(err)
+			}
+			if req.Manager != "manager" {
+				t.Fatalf("bad request: %+v", req)
+			}
+			return &ManagerCommandsResp{
+				Commands: []*ManagerCommand{
+					{ID: 1, Type: ManagerCommandRerunRepro, Arg: "title", CrashLog: []byte("log")},
+					{ID: 2, Type: ManagerCommandStopFuzzing},
+				},
+			}, nil
+		case "ack_manager_command":
+			req := new(AckManagerCommandReq)
+			if err := json.Unmarshal(payload, req); err != nil {
+				t.Fatal(err)
+			}
+			acked = append(acked, req)
+			return nil, nil
+		}
+		t.Fatalf("unexpected method %v", method)
+		return nil, nil
+	})
+	cmds, err := dash.ManagerCommands(context.Background(), "manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 2 || cmds[0].Type != ManagerCommandRerunRepro || string(cmds[0].CrashLog) != "log" {
+		t.Fatalf("bad reply: %+v", cmds)
+	}
+	err = dash.AckManagerCommand(context.Background(), &AckManagerCommandReq{
+		Manager: "manager",
+		ID:      cmds[0].ID,
+		Error:   "failed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acked) != 1 || acked[0].ID != 1 || acked[0].Error != "failed" {
+		t.Fatalf("bad acks: %+v", acked)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// Managers poll ManagerNotifs to learn about changes of the bugs they have hit, e.g. to stop
+// reproducing bugs that were closed and to upload crashes of such bugs in full again.
+
+type ManagerNotifType string
+
+const (
+	// The bug was closed as invalid, new crashes with the title create a new bug.
+	ManagerNotifInvalid ManagerNotifType = "invalid"
+	// The bug was marked as a duplicate of ManagerNotif.DupOf.
+	ManagerNotifDup ManagerNotifType = "dup"
+	// The bug got fixing commits, but they have not reached the manager build yet.
+	ManagerNotifFixPending ManagerNotifType = "fix_pending"
+	// The fixing commits have reached the manager build, new crashes with the title
+	// mean that the fix does not work and need to be reproduced again.
+	ManagerNotifRetest ManagerNotifType = "retest"
+	// The fixing commits have reached builds of all managers and the bug was closed,
+	// new crashes with the title create a new bug.
+	ManagerNotifFixed ManagerNotifType = "fixed"
+)
+
+type ManagerNotifsReq struct {
+	Manager string
+	// Since is ManagerNotifsResp.Until of the previous poll, only notifications about later
+	// changes are returned. The dashboard limits how far back in the past the changes are looked up.
+	Since time.Time
+}
+
+type ManagerNotifsResp struct {
+	Notifs []*ManagerNotif // in the order of changes
+	Until  time.Time
+}
+
+type ManagerNotif struct {
+	Type       ManagerNotifType
+	Title      string
+	Time       time.Time
+	DupOf      string   // the title of the canonical bug for ManagerNotifDup
+	FixCommits []string // titles of the fixing commits
+}
+
+// ManagerNotifs returns notifications about the bugs that happened on the manager.
+// If the crash index is enabled, titles of closed bugs and bugs to retest are dropped from it,
+// so that the next crash with the title is uploaded in full.
+func (dash *Dashboard) ManagerNotifs(ctx context.Context, req *ManagerNotifsReq) (*ManagerNotifsResp, error) {
+	resp := new(ManagerNotifsResp)
+	if err := dash.Query(ctx, "manager_notifs", req, resp); err != nil {
+		return nil, err
+	}
+	if dash.crashIndex != nil {
+		var titles []string
+		for _, notif := range resp.Notifs {
+			if notif.Type != ManagerNotifFixPending {
+				titles = append(titles, notif.Title)
+			}
+		}
+		dash.crashIndex.forget(titles)
+	}
+	return resp, nil
+}
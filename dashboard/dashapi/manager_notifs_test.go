@@ -0,0 +1,56 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManagerNotifsCrashIndex(t *testing.T) {
+	srv := &fakeCrashServer{t: t, active: make(map[string]bool)}
+	until := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if method != "manager_notifs" {
+			return srv.handle(method, payload)
+		}
+		req := new(ManagerNotifsReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Manager != "manager" {
+			t.Fatalf("bad request: %+v", req)
+		}
+		return &ManagerNotifsResp{
+			Notifs: []*ManagerNotif{
+				{Type: ManagerNotifInvalid, Title: "title1"},
+				{Type: ManagerNotifFixPending, Title: "title2"},
+			},
+			Until: until,
+		}, nil
+	})
+	dash.crashIndex = openCrashIndex(&CrashIndexConfig{File: filepath.Join(t.TempDir(), "index")})
+	for _, title := range []string{"title1", "title2"} {
+		if _, err := dash.ReportCrash(context.Background(), &Crash{BuildID: "build", Title: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := dash.ManagerNotifs(context.Background(), &ManagerNotifsReq{Manager: "manager"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Notifs) != 2 || !resp.Until.Equal(until) {
+		t.Fatalf("bad reply: %+v", resp)
+	}
+	// Crashes of the closed bug are uploaded in full again.
+	if dash.crashIndex.entries["title1"] != nil {
+		t.Fatalf("the closed bug was not dropped from the index")
+	}
+	if dash.crashIndex.entries["title2"] == nil {
+		t.Fatalf("the open bug was dropped from the index")
+	}
+}
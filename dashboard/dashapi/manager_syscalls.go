@@ -0,0 +1,25 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import "context"
+
+// ManagerSyscallsReq describes the syscalls the manager fuzzes. Managers upload it once
+// after the machine check, the set does not change until the manager restarts.
+type ManagerSyscallsReq struct {
+	Manager string
+	BuildID string
+	Enabled []string
+	// Syscalls that were detected as unsupported on the target (or that depend on such syscalls),
+	// mapped to the reason.
+	Unsupported map[string]string
+}
+
+// MaxManagerSyscalls is the number of syscalls UploadManagerSyscalls accepts,
+// it's several times more than any OS has.
+const MaxManagerSyscalls = 20000
+
+func (dash *Dashboard) UploadManagerSyscalls(ctx context.Context, req *ManagerSyscallsReq) error {
+	return dash.Query(ctx, "manager_syscalls", req, nil)
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics instruments dashboard requests with per-method prometheus metrics.
+// It can be passed to New and is a prometheus.Collector that needs to be registered by the caller.
+// Attempts, errors and latency are accounted per attempt (retries are separate attempts).
+type Metrics struct {
+	attempts *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	payload  *prometheus.HistogramVec
+	ratio    *prometheus.HistogramVec
+	dropped  prometheus.Counter
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "syz_dashapi_attempts_total",
+			Help: "Number of dashboard request attempts.",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "syz_dashapi_errors_total",
+			Help: "Number of failed dashboard request attempts.",
+		}, []string{"method"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "syz_dashapi_latency_seconds",
+			Help:    "Latency of dashboard request attempts.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 16),
+		}, []string{"method"}),
+		payload: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "syz_dashapi_payload_bytes",
+			Help:    "Uncompressed size of dashboard request payloads.",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+		}, []string{"method"}),
+		ratio: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "syz_dashapi_compression_ratio",
+			Help:    "Ratio of compressed to uncompressed size of dashboard request payloads.",
+			Buckets: prometheus.LinearBuckets(0.05, 0.05, 20),
+		}, []string{"method"}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "syz_dashapi_log_errors_dropped_total",
+			Help: "Number of LogError messages dropped because the queue was full.",
+		}),
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.attempts, m.errors, m.latency, m.payload, m.ratio, m.dropped}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) recordAttempt(method string, latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.attempts.WithLabelValues(method).Inc()
+	if err != nil {
+		m.errors.WithLabelValues(method).Inc()
+	}
+	m.latency.WithLabelValues(method).Observe(latency.Seconds())
+}
+
+func (m *Metrics) recordPayload(method string, size, compressed int) {
+	if m == nil || size == 0 {
+		return
+	}
+	m.payload.WithLabelValues(method).Observe(float64(size))
+	m.ratio.WithLabelValues(method).Observe(float64(compressed) / float64(size))
+}
+
+func (m *Metrics) recordDroppedLog() {
+	if m == nil {
+		return
+	}
+	m.dropped.Inc()
+}
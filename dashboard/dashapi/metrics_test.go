@@ -0,0 +1,69 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics(t *testing.T) {
+	fail := false
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if fail {
+			return nil, errors.New("failed")
+		}
+		return &ReportCrashResp{}, nil
+	})
+	dash.metrics = NewMetrics()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(dash.metrics)
+
+	crash := &Crash{Log: bytes.Repeat([]byte("crash log\n"), 1000)}
+	for i := 0; i < 3; i++ {
+		if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fail = true
+	if _, err := dash.ReportCrash(context.Background(), crash); err == nil {
+		t.Fatalf("the failed request succeeded")
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetValue() != "report_crash" {
+					t.Errorf("%v has label %v=%v", family.GetName(), label.GetName(), label.GetValue())
+				}
+			}
+			if hist := metric.GetHistogram(); hist != nil {
+				got[family.GetName()] = hist.GetSampleSum() / float64(hist.GetSampleCount())
+			} else {
+				got[family.GetName()] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	if got["syz_dashapi_attempts_total"] != 4 || got["syz_dashapi_errors_total"] != 1 {
+		t.Errorf("bad request counters: %v", got)
+	}
+	if size := got["syz_dashapi_payload_bytes"]; size < float64(len(crash.Log)) {
+		t.Errorf("average payload is %v bytes, want at least %v", size, len(crash.Log))
+	}
+	if ratio := got["syz_dashapi_compression_ratio"]; ratio <= 0 || ratio > 0.1 {
+		t.Errorf("bad compression ratio %v", ratio)
+	}
+	if _, ok := got["syz_dashapi_latency_seconds"]; !ok {
+		t.Errorf("no latency metric")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtoEncoding asks the client to use the Protocol Buffers wire format instead of JSON
+// for request and reply bodies. Large []byte fields (logs, reports, kernel configs) are sent
+// as is, instead of being base64-inflated by encoding/json.
+// The encoding is negotiated: the client asks for protobuf replies, and switches to protobuf
+// requests only after the dashboard has replied in protobuf, so it works with old dashboards.
+type ProtoEncoding bool
+
+// ProtoContentType is the content type of protobuf-encoded requests and replies.
+const ProtoContentType = "application/x-protobuf"
+
+// There are no .proto files for the API types: messages are derived from the Go structs,
+// field N of a struct has field number N+1 (so fields must only be appended to the structs).
+// Scalars are encoded as zigzag varints/fixed64/bytes, slices as repeated fields,
+// maps as repeated key/value entries, nested structs as embedded messages
+// and time.Time as its binary marshaling. Fields excluded from JSON are not encoded.
+
+var errProtoUnsupported = errors.New("type is not supported by the protobuf encoding")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MarshalProto encodes a struct, or a pointer to a struct, in the protobuf wire format.
+func MarshalProto(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct || rv.Type() == timeType {
+		return nil, fmt.Errorf("%w: %v", errProtoUnsupported, rv.Type())
+	}
+	return appendProtoMessage(nil, rv)
+}
+
+// UnmarshalProto decodes data encoded with MarshalProto into the struct v points to.
+// Unknown fields are ignored.
+func UnmarshalProto(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: %T", errProtoUnsupported, v)
+	}
+	rv = rv.Elem()
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct || rv.Type() == timeType {
+		return fmt.Errorf("%w: %v", errProtoUnsupported, rv.Type())
+	}
+	return unmarshalProtoMessage(data, rv)
+}
+
+// isProtoMessage says if v can be encoded with MarshalProto.
+func isProtoMessage(v interface{}) bool {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ != nil && typ.Kind() == reflect.Struct && typ != timeType
+}
+
+func protoField(typ reflect.Type, i int) bool {
+	field := typ.Field(i)
+	return field.IsExported() && field.Tag.Get("json") != "-"
+}
+
+func appendProtoMessage(b []byte, v reflect.Value) ([]byte, error) {
+	for i := 0; i < v.NumField(); i++ {
+		if !protoField(v.Type(), i) {
+			continue
+		}
+		var err error
+		b, err = appendProtoField(b, protowire.Number(i+1), v.Field(i), false)
+		if err != nil {
+			return nil, fmt.Errorf("%v.%v: %w", v.Type(), v.Type().Field(i).Name, err)
+		}
+	}
+	return b, nil
+}
+
+// appendProtoField appends field num with value v. Zero values are omitted
+// unless always is set (elements of repeated fields and map entries).
+func appendProtoField(b []byte, num protowire.Number, v reflect.Value, always bool) ([]byte, error) {
+	if !always && v.Kind() != reflect.Ptr && v.IsZero() {
+		return b, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v.Float()))
+	case reflect.String:
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, v.Bytes())
+			break
+		}
+		if always {
+			return nil, fmt.Errorf("%w: nested %v", errProtoUnsupported, v.Type())
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if b, err = appendProtoField(b, num, v.Index(i), true); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Map:
+		if always {
+			return nil, fmt.Errorf("%w: nested %v", errProtoUnsupported, v.Type())
+		}
+		for iter := v.MapRange(); iter.Next(); {
+			entry, err := appendProtoField(nil, 1, iter.Key(), true)
+			if err != nil {
+				return nil, err
+			}
+			if entry, err = appendProtoField(entry, 2, iter.Value(), true); err != nil {
+				return nil, err
+			}
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, entry)
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return b, nil
+		}
+		return appendProtoField(b, num, v.Elem(), true)
+	case reflect.Struct:
+		var msg []byte
+		var err error
+		if v.Type() == timeType {
+			msg, err = v.Interface().(time.Time).MarshalBinary()
+		} else {
+			msg, err = appendProtoMessage(nil, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg)
+	default:
+		return nil, fmt.Errorf("%w: %v", errProtoUnsupported, v.Type())
+	}
+	return b, nil
+}
+
+func unmarshalProtoMessage(data []byte, v reflect.Value) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		i := int(num) - 1
+		if i < 0 || i >= v.NumField() || !protoField(v.Type(), i) {
+			if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		n, err := unmarshalProtoField(data, typ, v.Field(i))
+		if err != nil {
+			return fmt.Errorf("%v.%v: %w", v.Type(), v.Type().Field(i).Name, err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// unmarshalProtoField decodes a single field value of wire type typ into v
+// and returns the number of consumed bytes.
+func unmarshalProtoField(data []byte, typ protowire.Type, v reflect.Value) (int, error) {
+	want := protowire.BytesType
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		want = protowire.VarintType
+	case reflect.Float32, reflect.Float64:
+		want = protowire.Fixed64Type
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			n, err := unmarshalProtoField(data, typ, elem)
+			if err != nil {
+				return 0, err
+			}
+			v.Set(reflect.Append(v, elem))
+			return n, nil
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalProtoField(data, typ, v.Elem())
+	}
+	if typ != want {
+		return 0, fmt.Errorf("wrong wire type %v for %v", typ, v.Type())
+	}
+	switch want {
+	case protowire.VarintType:
+		val, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(protowire.DecodeBool(val))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(protowire.DecodeZigZag(val))
+		default:
+			v.SetUint(val)
+		}
+		return n, nil
+	case protowire.Fixed64Type:
+		val, n := protowire.ConsumeFixed64(data)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		v.SetFloat(math.Float64frombits(val))
+		return n, nil
+	}
+	val, n := protowire.ConsumeBytes(data)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(val))
+	case reflect.Slice:
+		v.SetBytes(append([]byte(nil), val...))
+	case reflect.Map:
+		if err := unmarshalProtoEntry(val, v); err != nil {
+			return 0, err
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if err := v.Addr().Interface().(*time.Time).UnmarshalBinary(val); err != nil {
+				return 0, err
+			}
+		} else if err := unmarshalProtoMessage(val, v); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("%w: %v", errProtoUnsupported, v.Type())
+	}
+	return n, nil
+}
+
+func unmarshalProtoEntry(data []byte, m reflect.Value) error {
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	key := reflect.New(m.Type().Key()).Elem()
+	val := reflect.New(m.Type().Elem()).Elem()
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch num {
+		case 1:
+			n, err := unmarshalProtoField(data, typ, key)
+			if err != nil {
+				return err
+			}
+			data = data[n:]
+		case 2:
+			n, err := unmarshalProtoField(data, typ, val)
+			if err != nil {
+				return err
+			}
+			data = data[n:]
+		default:
+			if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+	m.SetMapIndex(key, val)
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	type nested struct {
+		Name  string
+		Count int
+	}
+	type message struct {
+		Bool       bool
+		Int        int
+		Negative   int64
+		Uint       uint32
+		Float      float64
+		String     string
+		Bytes      []byte
+		Strings    []string
+		Blobs      [][]byte
+		Nested     nested
+		Ptr        *nested
+		NilPtr     *nested
+		Ptrs       []*nested
+		Map        map[string]*nested
+		Time       time.Time
+		Duration   time.Duration
+		Ignored    string `json:"-"`
+		unexported int
+	}
+	msg := &message{
+		Bool:     true,
+		Int:      42,
+		Negative: -1,
+		Uint:     7,
+		Float:    0.5,
+		String:   "string",
+		Bytes:    []byte{0, 1, 2, 255},
+		Strings:  []string{"a", "", "c"},
+		Blobs:    [][]byte{[]byte("blob1"), []byte("blob2")},
+		Nested:   nested{Name: "nested", Count: 1},
+		Ptr:      &nested{},
+		Ptrs:     []*nested{{Name: "ptr1"}, {Name: "ptr2", Count: 2}},
+		Map:      map[string]*nested{"key1": {Count: 3}, "key2": {Name: "value2"}},
+		Time:     time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC),
+		Duration: time.Minute,
+		Ignored:  "ignored",
+	}
+	data, err := MarshalProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(message)
+	if err := UnmarshalProto(data, got); err != nil {
+		t.Fatal(err)
+	}
+	want := *msg
+	want.Ignored = ""
+	if diff := cmp.Diff(&want, got, cmp.AllowUnexported(message{})); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProtoSmaller(t *testing.T) {
+	crash := &Crash{
+		BuildID: "build",
+		Title:   "title",
+		Log:     make([]byte, 1<<20),
+		Report:  []byte("report"),
+	}
+	for i := range crash.Log {
+		crash.Log[i] = byte(i * 13)
+	}
+	jsonData, err := json.Marshal(crash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoData, err := MarshalProto(crash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(protoData) > len(crash.Log)+len(crash.Log)/100 || len(protoData) >= len(jsonData) {
+		t.Fatalf("protobuf payload is too large: %v, json %v", len(protoData), len(jsonData))
+	}
+	got := new(Crash)
+	if err := UnmarshalProto(protoData, got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(crash, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProtoUnsupported(t *testing.T) {
+	if _, err := MarshalProto(json.RawMessage("{}")); err == nil {
+		t.Fatalf("marshaling of a non-struct succeeded")
+	}
+	if _, err := MarshalProto(&struct{ Any interface{} }{Any: 1}); err == nil {
+		t.Fatalf("marshaling of an interface field succeeded")
+	}
+}
+
+func TestProtoNegotiation(t *testing.T) {
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gr, err := gzip.NewReader(strings.NewReader(r.FormValue("payload")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := io.ReadAll(gr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := new(Crash)
+		if r.FormValue("encoding") == "proto" {
+			encodings = append(encodings, "proto")
+			err = UnmarshalProto(payload, req)
+		} else {
+			encodings = append(encodings, "json")
+			err = json.Unmarshal(payload, req)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply := &ReportCrashResp{CrashID: 1, NeedRepro: req.Title == "title"}
+		if r.Header.Get("Accept") != ProtoContentType {
+			json.NewEncoder(w).Encode(reply)
+			return
+		}
+		data, err := MarshalProto(reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", ProtoContentType)
+		w.Write(data)
+	}))
+	defer srv.Close()
+	dash, err := New("client", srv.URL, "key", ProtoEncoding(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := dash.ReportCrash(context.Background(), &Crash{Title: "title", Log: []byte("log")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (&ReportCrashResp{CrashID: 1, NeedRepro: true}); !cmp.Equal(resp, want) {
+			t.Fatalf("got reply %+v, want %+v", resp, want)
+		}
+	}
+	if diff := cmp.Diff([]string{"json", "proto"}, encodings); diff != "" {
+		t.Fatal(diff)
+	}
+}
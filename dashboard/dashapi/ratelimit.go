@@ -0,0 +1,73 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimits limits the rate of requests per API method with token buckets, so that a crash storm
+// on one manager can't exhaust the dashboard quotas of the whole namespace.
+// The "" entry applies to all methods that don't have their own limit (they share one bucket).
+// New uses DefaultRateLimits unless different limits are given, empty RateLimits disable limiting.
+type RateLimits map[string]RateLimit
+
+type RateLimit struct {
+	Rate  float64 // requests per second, 0 means no limit
+	Burst int     // 0 means 1
+	// Drop makes requests over the limit fail with ErrRateLimited, by default they wait.
+	Drop bool
+}
+
+var DefaultRateLimits = RateLimits{
+	"log_error": {Rate: 1, Burst: 10, Drop: true},
+}
+
+var ErrRateLimited = errors.New("dashboard request rate limit exceeded")
+
+type rateLimiter struct {
+	methods map[string]*rateBucket
+}
+
+type rateBucket struct {
+	limiter *rate.Limiter
+	drop    bool
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	rl := &rateLimiter{methods: make(map[string]*rateBucket)}
+	for method, limit := range limits {
+		r := rate.Limit(limit.Rate)
+		if limit.Rate <= 0 {
+			r = rate.Inf
+		}
+		rl.methods[method] = &rateBucket{
+			limiter: rate.NewLimiter(r, max(limit.Burst, 1)),
+			drop:    limit.Drop,
+		}
+	}
+	return rl
+}
+
+// wait blocks until the request can be sent, or returns an error if it must not be sent.
+func (rl *rateLimiter) wait(ctx context.Context, method string) error {
+	bucket := rl.methods[method]
+	if bucket == nil {
+		bucket = rl.methods[""]
+	}
+	if bucket == nil {
+		return nil
+	}
+	if bucket.drop {
+		if !bucket.limiter.Allow() {
+			return fmt.Errorf("%w for %v", ErrRateLimited, method)
+		}
+		return nil
+	}
+	return bucket.limiter.Wait(ctx)
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimits(t *testing.T) {
+	calls := make(map[string]int)
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		calls[method]++
+		return nil, nil
+	})
+	dash.limiter = newRateLimiter(RateLimits{
+		"log_error": {Rate: 0.001, Burst: 2, Drop: true},
+		"":          {Rate: 1000, Burst: 1},
+	})
+	for i := 0; i < 5; i++ {
+		err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil)
+		if i < 2 && err != nil || i >= 2 && !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("log_error %v: got %v", i, err)
+		}
+	}
+	// Methods without their own limit wait instead of failing.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := dash.Query(context.Background(), "manager_stats", &ManagerStatsReq{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) < 5*time.Millisecond {
+		t.Fatalf("requests were not rate limited")
+	}
+	if calls["log_error"] != 2 || calls["manager_stats"] != 10 {
+		t.Fatalf("bad calls: %v", calls)
+	}
+
+	// Waiting requests respect the context.
+	dash.limiter = newRateLimiter(RateLimits{"": {Rate: 0.001}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	dash.Query(ctx, "manager_stats", &ManagerStatsReq{}, nil)
+	if err := dash.Query(ctx, "manager_stats", &ManagerStatsReq{}, nil); err == nil {
+		t.Fatalf("rate limited request succeeded")
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Exchange is a recorded API request and the dashboard reply to it.
+// Payloads that are JSON are stored as is to keep golden files readable,
+// other payloads (e.g. protobuf) are stored in Data fields.
+// Credentials are not recorded.
+type Exchange struct {
+	Method      string
+	Request     json.RawMessage `json:",omitempty"`
+	RequestData []byte          `json:",omitempty"`
+	Status      int
+	ContentType string          `json:",omitempty"`
+	Compression string          `json:",omitempty"` // value of CompressionHeader
+	Reply       json.RawMessage `json:",omitempty"`
+	ReplyData   []byte          `json:",omitempty"`
+}
+
+// Recorder is a Transport that sends API requests with the underlying transport
+// and records them along with the replies. Save writes the exchanges to a golden file
+// that can be replayed in tests with Replayer. Other requests (blob downloads) are passed through.
+type Recorder struct {
+	base      Transport
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder returns a Recorder on top of base, nil means the default HTTP transport.
+func NewRecorder(base Transport) *Recorder {
+	if base == nil {
+		base = &httpTransport{http.DefaultClient}
+	}
+	return &Recorder{base: base}
+}
+
+func (rec *Recorder) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return rec.base.NewRequest(ctx, method, url, body)
+}
+
+func (rec *Recorder) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return rec.base.Do(req)
+	}
+	ex, err := parseExchange(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rec.base.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body := io.Reader(resp.Body)
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ungzip response: %w", err)
+		}
+		defer gr.Close()
+		body = gr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	ex.Status = resp.StatusCode
+	ex.ContentType = resp.Header.Get("Content-Type")
+	ex.Compression = resp.Header.Get(CompressionHeader)
+	ex.Reply, ex.ReplyData = splitJSON(data)
+	rec.mu.Lock()
+	rec.exchanges = append(rec.exchanges, *ex)
+	rec.mu.Unlock()
+	return ex.response(), nil
+}
+
+// Exchanges returns the exchanges recorded so far.
+func (rec *Recorder) Exchanges() []Exchange {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Exchange(nil), rec.exchanges...)
+}
+
+// Save writes the recorded exchanges to the golden file.
+func (rec *Recorder) Save(file string) error {
+	data, err := json.MarshalIndent(rec.Exchanges(), "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write golden file: %w", err)
+	}
+	return nil
+}
+
+// Replayer is a Transport that serves API requests from a golden file written by Recorder.
+// Requests must come in the recorded order and match the recorded requests,
+// except for the fields passed to Ignore. Mismatching requests fail with http.StatusBadRequest,
+// Done returns all mismatches.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	ignore    map[string]bool
+	errs      []error
+}
+
+// LoadReplayer reads exchanges from the golden file.
+func LoadReplayer(file string) (*Replayer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file: %w", err)
+	}
+	rep := &Replayer{ignore: make(map[string]bool)}
+	if err := json.Unmarshal(data, &rep.exchanges); err != nil {
+		return nil, fmt.Errorf("failed to parse golden file %v: %w", file, err)
+	}
+	return rep, nil
+}
+
+// Ignore excludes top-level request fields that differ from run to run
+// (e.g. IdempotencyKey or timestamps) from request matching.
+func (rep *Replayer) Ignore(fields ...string) {
+	for _, field := range fields {
+		rep.ignore[field] = true
+	}
+}
+
+func (rep *Replayer) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+func (rep *Replayer) Do(req *http.Request) (*http.Response, error) {
+	got, err := parseExchange(req)
+	if err != nil {
+		return nil, err
+	}
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if len(rep.exchanges) == 0 {
+		return rep.fail(fmt.Errorf("unexpected %v request %s", got.Method, got.Request)), nil
+	}
+	want := rep.exchanges[0]
+	if got.Method != want.Method {
+		return rep.fail(fmt.Errorf("got %v request, want %v", got.Method, want.Method)), nil
+	}
+	if err := rep.match(got, &want); err != nil {
+		return rep.fail(fmt.Errorf("%v request mismatch: %w", got.Method, err)), nil
+	}
+	rep.exchanges = rep.exchanges[1:]
+	return want.response(), nil
+}
+
+// Done returns an error if requests did not match or not all recorded requests were made.
+func (rep *Replayer) Done() error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	errs := rep.errs
+	if len(rep.exchanges) != 0 {
+		errs = append(errs, fmt.Errorf("%v recorded requests were not made, next is %v",
+			len(rep.exchanges), rep.exchanges[0].Method))
+	}
+	return errors.Join(errs...)
+}
+
+func (rep *Replayer) fail(err error) *http.Response {
+	rep.errs = append(rep.errs, err)
+	return &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Status:     http.StatusText(http.StatusBadRequest),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(err.Error())),
+	}
+}
+
+func (rep *Replayer) match(got, want *Exchange) error {
+	if !bytes.Equal(got.RequestData, want.RequestData) {
+		return fmt.Errorf("binary payloads differ")
+	}
+	if want.Request == nil && got.Request == nil {
+		return nil
+	}
+	var gotReq, wantReq interface{}
+	if err := json.Unmarshal(got.Request, &gotReq); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(want.Request, &wantReq); err != nil {
+		return err
+	}
+	for _, req := range []interface{}{gotReq, wantReq} {
+		if fields, ok := req.(map[string]interface{}); ok {
+			for field := range rep.ignore {
+				delete(fields, field)
+			}
+		}
+	}
+	if !reflect.DeepEqual(gotReq, wantReq) {
+		return fmt.Errorf("got %s\nwant %s", got.Request, want.Request)
+	}
+	return nil
+}
+
+// parseExchange extracts the API method and the request payload from an API request.
+// The request body is restored, so that the request can still be sent.
+func parseExchange(req *http.Request) (*Exchange, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	form := req.Clone(req.Context())
+	form.Body = io.NopCloser(bytes.NewReader(body))
+	if err := form.ParseMultipartForm(int64(len(body)) + 1<<10); err != nil {
+		return nil, fmt.Errorf("failed to parse API request: %w", err)
+	}
+	ex := &Exchange{Method: form.PostFormValue("method")}
+	if payload := form.PostFormValue("payload"); payload != "" {
+		data, err := DecompressPayload(form.PostFormValue("compression"), []byte(payload))
+		if err != nil {
+			return nil, err
+		}
+		ex.Request, ex.RequestData = splitJSON(data)
+	}
+	return ex, nil
+}
+
+func (ex *Exchange) response() *http.Response {
+	resp := &http.Response{
+		StatusCode: ex.Status,
+		Status:     http.StatusText(ex.Status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(append(ex.Reply, ex.ReplyData...))),
+	}
+	if ex.ContentType != "" {
+		resp.Header.Set("Content-Type", ex.ContentType)
+	}
+	if ex.Compression != "" {
+		resp.Header.Set(CompressionHeader, ex.Compression)
+	}
+	return resp
+}
+
+func splitJSON(data []byte) (json.RawMessage, []byte) {
+	switch {
+	case len(data) == 0:
+		return nil, nil
+	case json.Valid(data):
+		return json.RawMessage(bytes.TrimSpace(data)), nil
+	default:
+		return nil, data
+	}
+}
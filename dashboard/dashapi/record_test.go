@@ -0,0 +1,88 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		var req Crash
+		payload, err := DecompressPayload(r.PostFormValue("compression"), []byte(r.PostFormValue("payload")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(payload, &req); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(&ReportCrashResp{NeedRepro: req.Title == "repro"})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{CompressionHeader: {"gzip, zstd"}},
+			Body:       io.NopCloser(strings.NewReader(string(data))),
+		}, nil
+	})
+	report := func(dash *Dashboard, title string) (*ReportCrashResp, error) {
+		return dash.ReportCrash(context.Background(), &Crash{Title: title, Log: []byte("log")})
+	}
+	recorder := NewRecorder(transport)
+	dash, err := New("client", "http://dashboard", "secret-key", recorder, RetryPolicy{}, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"repro", "no repro"} {
+		if _, err := report(dash, title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden := filepath.Join(t.TempDir(), "golden.json")
+	if err := recorder.Save(golden); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := func(titles ...string) error {
+		replayer, err := LoadReplayer(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replayer.Ignore("IdempotencyKey")
+		dash, err := New("client", "http://dashboard", "other-key", replayer, RetryPolicy{}, RateLimits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, title := range titles {
+			resp, err := report(dash, title)
+			if err != nil {
+				break
+			}
+			if resp.NeedRepro != (title == "repro") {
+				return fmt.Errorf("wrong reply for %q: %+v", title, resp)
+			}
+		}
+		return replayer.Done()
+	}
+	if err := replay("repro", "no repro"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replay("repro"); err == nil || !strings.Contains(err.Error(), "were not made") {
+		t.Fatalf("missing request was not detected: %v", err)
+	}
+	if err := replay("repro", "other"); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("changed request was not detected: %v", err)
+	}
+	if err := replay("repro", "no repro", "extra"); err == nil || !strings.Contains(err.Error(), "unexpected") {
+		t.Fatalf("extra request was not detected: %v", err)
+	}
+}
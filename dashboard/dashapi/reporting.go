@@ -0,0 +1,44 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// Helpers for external reporting integrations.
+
+// NotificationUpdate acknowledges the notification and takes the action it asks for
+// the way the email reporting does: the bug is sent to the next reporting for BugNotifUpstream,
+// closed as invalid for BugNotifObsoleted, and the label is recorded as reported for BugNotifLabel.
+func NotificationUpdate(notif *BugNotification) *BugUpdate {
+	upd := &BugUpdate{
+		ID:           notif.ID,
+		Status:       BugStatusOpen,
+		Notification: true,
+	}
+	switch notif.Type {
+	case BugNotifUpstream:
+		upd.Status = BugStatusUpstream
+	case BugNotifObsoleted:
+		upd.Status = BugStatusInvalid
+		upd.StatusReason = BugStatusReason(notif.Text)
+	case BugNotifLabel:
+		upd.Labels = []string{notif.Label}
+	}
+	return upd
+}
+
+// ReportingUpdateOK is ReportingUpdate that also fails if the dashboard rejects the update.
+func (dash *Dashboard) ReportingUpdateOK(ctx context.Context, upd *BugUpdate) error {
+	reply, err := dash.ReportingUpdate(ctx, upd)
+	if err != nil {
+		return err
+	}
+	if !reply.OK {
+		return fmt.Errorf("the dashboard rejected the update: %v", reply.Text)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNotificationUpdate(t *testing.T) {
+	tests := []struct {
+		notif *BugNotification
+		want  *BugUpdate
+	}{
+		{
+			notif: &BugNotification{Type: BugNotifUpstream, ID: "id1"},
+			want:  &BugUpdate{ID: "id1", Status: BugStatusUpstream, Notification: true},
+		},
+		{
+			notif: &BugNotification{Type: BugNotifObsoleted, ID: "id2", Text: string(InvalidatedByRevokedRepro)},
+			want: &BugUpdate{ID: "id2", Status: BugStatusInvalid, Notification: true,
+				StatusReason: InvalidatedByRevokedRepro},
+		},
+		{
+			notif: &BugNotification{Type: BugNotifLabel, ID: "id3", Label: "prio:low"},
+			want:  &BugUpdate{ID: "id3", Status: BugStatusOpen, Notification: true, Labels: []string{"prio:low"}},
+		},
+		{
+			notif: &BugNotification{Type: BugNotifBadCommit, ID: "id4"},
+			want:  &BugUpdate{ID: "id4", Status: BugStatusOpen, Notification: true},
+		},
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, NotificationUpdate(test.notif)); diff != "" {
+			t.Errorf("notification %v: %v", test.notif.Type, diff)
+		}
+	}
+}
+
+func TestReportingUpdateOK(t *testing.T) {
+	reply := &BugUpdateReply{OK: true}
+	var got *BugUpdate
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if method != "reporting_update" {
+			t.Fatalf("unexpected method %q", method)
+		}
+		got = new(BugUpdate)
+		if err := json.Unmarshal(payload, got); err != nil {
+			t.Fatal(err)
+		}
+		return reply, nil
+	})
+	upd := &BugUpdate{ID: "id1", Status: BugStatusOpen}
+	if err := dash.ReportingUpdateOK(context.Background(), upd); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != "id1" {
+		t.Fatalf("bad update %+v", got)
+	}
+	reply = &BugUpdateReply{OK: false, Text: "unknown bug"}
+	err := dash.ReportingUpdateOK(context.Background(), upd)
+	if err == nil || !strings.Contains(err.Error(), "unknown bug") {
+		t.Fatalf("bad error: %v", err)
+	}
+}
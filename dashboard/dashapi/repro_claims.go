@@ -0,0 +1,46 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// Managers claim the reproduction of a crash with ClaimRepro before starting it, so that
+// the managers that hit the same crash don't all reproduce it at the same time.
+// Repeated claims of the holder renew the claim, ReleaseReproClaim hands the reproduction over
+// to other managers once it's done. Claims share the per-bug leases with ReproTaskPoll tasks.
+type ReproClaimReq struct {
+	Manager string
+	Title   string
+	Lease   time.Duration // 0 means DefaultReproClaimLease, capped at MaxReproClaimLease
+}
+
+type ReproClaimResp struct {
+	Claimed  bool
+	Manager  string    // the manager that holds the claim
+	Deadline time.Time // the claim expires after the deadline unless it's renewed
+}
+
+const (
+	DefaultReproClaimLease = time.Hour
+	MaxReproClaimLease     = 6 * time.Hour
+)
+
+func (dash *Dashboard) ClaimRepro(ctx context.Context, req *ReproClaimReq) (*ReproClaimResp, error) {
+	resp := new(ReproClaimResp)
+	err := dash.Query(ctx, "claim_repro", req, resp)
+	return resp, err
+}
+
+type ReproClaimReleaseReq struct {
+	Manager string
+	Title   string
+}
+
+// ReleaseReproClaim does nothing if the manager does not hold the claim (e.g. it has expired).
+func (dash *Dashboard) ReleaseReproClaim(ctx context.Context, manager, title string) error {
+	return dash.Query(ctx, "release_repro_claim", &ReproClaimReleaseReq{Manager: manager, Title: title}, nil)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// ReproPhase is the stage of the reproduction, the values match pkg/repro phases.
+type ReproPhase string
+
+const (
+	ReproPhaseExtractProg  ReproPhase = "extract_prog"
+	ReproPhaseMinimizeProg ReproPhase = "minimize_prog"
+	ReproPhaseExtractC     ReproPhase = "extract_c"
+	ReproPhaseSimplifyProg ReproPhase = "simplify_prog"
+	ReproPhaseSimplifyC    ReproPhase = "simplify_c"
+)
+
+// ReproProgress is the intermediate state of the reproduction of the crash with Title.
+// Managers report it when the phase changes and periodically in between (see ReproProgressPeriod),
+// the dashboard shows the reproduction as in progress until the reports stop.
+type ReproProgress struct {
+	Manager string
+	Title   string
+	Phase   ReproPhase
+	Elapsed time.Duration // since the start of the reproduction
+	// The current (partially minimized) program, empty until the program is extracted.
+	ReproSyz []byte
+}
+
+const (
+	ReproProgressPeriod = 10 * time.Minute
+	// Larger programs are not shown on the dashboard.
+	MaxReproProgressProg = 64 << 10
+)
+
+func (dash *Dashboard) ReportReproProgress(ctx context.Context, req *ReproProgress) error {
+	return dash.Query(ctx, "repro_progress", req, nil)
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy makes Query transparently retry transient failures (connection errors, timeouts,
+// 5xx and 429 responses) with jittered exponential backoff. It can be passed to New.
+// New uses DefaultRetryPolicy unless a different policy is given.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one. 0 or 1 disables retries.
+	Attempts int
+	// Delay before the first retry, each next retry doubles the delay.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   5,
+	Backoff:    time.Second,
+	MaxBackoff: time.Minute,
+}
+
+func (dash *Dashboard) queryRetry(ctx context.Context, method string, req, reply interface{}) error {
+	err := dash.queryImpl(ctx, method, req, reply)
+	for attempt := 1; attempt < dash.retry.Attempts; attempt++ {
+		if !IsTemporary(err) || ctx.Err() != nil {
+			break
+		}
+		delay := dash.retry.delay(attempt)
+		if dash.logger != nil {
+			dash.logger("API(%v): retrying in %v: %v", method, delay, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		err = dash.queryImpl(ctx, method, req, reply)
+	}
+	return err
+}
+
+// delay returns the backoff before the given retry (1-based) with a random jitter of up to a half.
+func (policy *RetryPolicy) delay(retry int) time.Duration {
+	delay := policy.Backoff
+	for i := 1; i < retry && (policy.MaxBackoff == 0 || delay < policy.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff != 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryDashboard(t *testing.T, statuses ...int) (*Dashboard, *int) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		w.WriteHeader(status)
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+	dash, err := New("client", srv.URL, "key", RetryPolicy{
+		Attempts: 3,
+		Backoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dash, &calls
+}
+
+func TestRetryTransient(t *testing.T) {
+	dash, calls := testRetryDashboard(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	if err := dash.UploadBuild(context.Background(), &Build{}); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("got %v calls, want 3", *calls)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	dash, calls := testRetryDashboard(t, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError)
+	if err := dash.UploadBuild(context.Background(), &Build{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if *calls != 3 {
+		t.Fatalf("got %v calls, want 3", *calls)
+	}
+}
+
+func TestRetryPermanent(t *testing.T) {
+	dash, calls := testRetryDashboard(t, http.StatusBadRequest)
+	if err := dash.UploadBuild(context.Background(), &Build{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if *calls != 1 {
+		t.Fatalf("got %v calls, want 1", *calls)
+	}
+}
+
+func TestRetryCancel(t *testing.T) {
+	dash, calls := testRetryDashboard(t, http.StatusServiceUnavailable)
+	dash.retry.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := dash.UploadBuild(ctx, &Build{})
+	if !IsTemporary(err) {
+		t.Fatalf("got %v, want the last transient error", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("got %v calls, want 1", *calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range want {
+		retry := i + 1
+		got := policy.delay(retry)
+		if got < want/2 || got > want {
+			t.Errorf("retry %v: delay %v, want [%v, %v]", retry, got, want/2, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import "fmt"
+
+// BugSeverity is set by external reporters or humans with BugUpdate.Severity (e.g. after a security triage).
+// Bugs without a set severity get one derived from the crash type and the reproducer.
+type BugSeverity int
+
+const (
+	SeverityUnknown BugSeverity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (sev BugSeverity) Known() bool {
+	return sev >= SeverityUnknown && sev <= SeverityCritical
+}
+
+func (sev BugSeverity) String() string {
+	switch sev {
+	case SeverityUnknown:
+		return "unknown"
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("BugSeverity(%d)", int(sev))
+}
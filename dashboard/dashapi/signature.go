@@ -0,0 +1,65 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignRequests makes the client authenticate HTTP requests with an HMAC-SHA256 signature
+// instead of sending the key with the request. The signature covers the API method,
+// the request body and the time of the request, and is sent in the SignatureHeader header.
+// The dashboard rejects signatures older than MaxSignatureAge and signatures it has already seen.
+type SignRequests bool
+
+const (
+	SignatureHeader = "X-Syzkaller-Signature"
+	MaxSignatureAge = 5 * time.Minute
+)
+
+// Signature returns the value of the SignatureHeader header for a request.
+func Signature(key, method string, timestamp time.Time, body []byte) string {
+	return fmt.Sprintf("t=%v,sig=%v", timestamp.Unix(), signature(key, method, timestamp.Unix(), body))
+}
+
+// CheckSignature verifies the value of the SignatureHeader header.
+func CheckSignature(header, key, method string, now time.Time, body []byte) error {
+	var timestamp int64
+	var sig string
+	for _, part := range strings.Split(header, ",") {
+		name, val, _ := strings.Cut(part, "=")
+		switch name {
+		case "t":
+			var err error
+			if timestamp, err = strconv.ParseInt(val, 10, 64); err != nil {
+				return fmt.Errorf("bad signature timestamp: %w", err)
+			}
+		case "sig":
+			sig = val
+		}
+	}
+	if sig == "" {
+		return fmt.Errorf("malformed signature %q", header)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return fmt.Errorf("signature has expired (age %v)", age)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(key, method, timestamp, body))) {
+		return fmt.Errorf("wrong signature")
+	}
+	return nil
+}
+
+func signature(key, method string, timestamp int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%v\n%v\n%x", method, timestamp, sum)
+	return hex.EncodeToString(mac.Sum(nil))
+}
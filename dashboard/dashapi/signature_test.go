@@ -0,0 +1,45 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := r.Header.Get(SignatureHeader)
+		if err := CheckSignature(sig, "key", "log_error", time.Now(), body); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err := CheckSignature(sig, "key", "log_error", time.Now().Add(time.Hour), body); err == nil {
+			t.Errorf("expired signature was accepted")
+		}
+		if err := CheckSignature(sig, "key2", "log_error", time.Now(), body); err == nil {
+			t.Errorf("signature with a wrong key was accepted")
+		}
+		if bytes.Contains(body, []byte(`name="key"`)) {
+			t.Errorf("the key was sent with the signed request")
+		}
+	}))
+	defer srv.Close()
+	dash, err := New("client", srv.URL, "key", SignRequests(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.Query(context.Background(), "log_error", &LogEntry{Name: "name"}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
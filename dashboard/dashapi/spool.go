@@ -0,0 +1,196 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpoolConfig enables at-least-once delivery of requests that only push data to the dashboard
+// (builds, build errors, crashes, failed repros and commits). If such a request fails with
+// a transient error (after all retries), it's saved to Dir and replayed later, once the dashboard
+// is reachable again. Spooled requests survive restarts.
+// The caller gets a nil error for a spooled request, or ErrSpooled if the request has a reply
+// (e.g. ReportCrash), since the reply is not known until the request is replayed.
+type SpoolConfig struct {
+	Dir string
+	// Maximum number of spooled requests, new requests are not spooled once the spool is full.
+	MaxEntries int
+	// A spooled request is dropped after it failed to replay MaxAttempts times,
+	// so that a request that keeps failing does not block the ones after it.
+	MaxAttempts int
+}
+
+// ErrSpooled is returned for spooled requests with replies, the request will be sent later,
+// but the reply is lost.
+var ErrSpooled = errors.New("the request is spooled")
+
+const (
+	defaultSpoolMaxEntries  = 10000
+	defaultSpoolMaxAttempts = 10
+)
+
+var spooledMethods = map[string]bool{
+	"upload_build":        true,
+	"report_build_error":  true,
+	"report_crash":        true,
+	"report_failed_repro": true,
+	"upload_commits":      true,
+}
+
+type spool struct {
+	cfg       SpoolConfig
+	mu        sync.Mutex
+	seq       int
+	replaying bool
+}
+
+type spoolEntry struct {
+	Method   string
+	Request  json.RawMessage
+	Attempts int // failed replays
+}
+
+func openSpool(cfg *SpoolConfig) (*spool, error) {
+	sp := &spool{
+		cfg: *cfg,
+	}
+	if sp.cfg.MaxEntries == 0 {
+		sp.cfg.MaxEntries = defaultSpoolMaxEntries
+	}
+	if sp.cfg.MaxAttempts == 0 {
+		sp.cfg.MaxAttempts = defaultSpoolMaxAttempts
+	}
+	if err := os.MkdirAll(sp.cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+	return sp, nil
+}
+
+func (sp *spool) accepts(method string, err error) bool {
+	return spooledMethods[method] && IsTemporary(err)
+}
+
+// add saves the request to the spool. The request is encrypted before it's saved,
+// so that sensitive data is not stored on disk in plain text.
+func (sp *spool) add(dash *Dashboard, method string, req interface{}) error {
+	var err error
+	if req != nil && dash.payloadKeys != nil {
+		if req, err = dash.payloadKeys.encryptRequest(req); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	data, err = json.Marshal(&spoolEntry{
+		Method:  method,
+		Request: data,
+	})
+	if err != nil {
+		return err
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	files, err := sp.files()
+	if err != nil {
+		return err
+	}
+	if len(files) >= sp.cfg.MaxEntries {
+		return fmt.Errorf("the spool is full")
+	}
+	sp.seq++
+	// Names are ordered by time, so that the requests are replayed in the original order.
+	file := filepath.Join(sp.cfg.Dir, fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), sp.seq))
+	return writeSpoolFile(file, data)
+}
+
+// writeSpoolFile writes and renames, so that a crash in the middle does not leave
+// a partially written request.
+func writeSpoolFile(file string, data []byte) error {
+	if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func (sp *spool) files() ([]string, error) {
+	entries, err := os.ReadDir(sp.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, ent := range entries {
+		if strings.HasSuffix(ent.Name(), ".json") {
+			files = append(files, filepath.Join(sp.cfg.Dir, ent.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// kick starts replay of the spooled requests in the background, if there are any.
+// It's called after successful requests, i.e. when the dashboard is known to be reachable.
+func (sp *spool) kick(dash *Dashboard) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.replaying {
+		return
+	}
+	if files, err := sp.files(); err != nil || len(files) == 0 {
+		return
+	}
+	sp.replaying = true
+	go func() {
+		sp.replay(dash)
+		sp.mu.Lock()
+		sp.replaying = false
+		sp.mu.Unlock()
+	}()
+}
+
+// replay sends the spooled requests in order and stops on the first transient failure.
+// Requests that fail permanently are dropped, since they will never succeed,
+// as well as the requests that failed transiently MaxAttempts times.
+func (sp *spool) replay(dash *Dashboard) error {
+	sp.mu.Lock()
+	files, err := sp.files()
+	sp.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		ent := new(spoolEntry)
+		if err = json.Unmarshal(data, ent); err == nil {
+			err = dash.queryImpl(context.Background(), ent.Method, ent.Request, nil)
+			if sp.accepts(ent.Method, err) {
+				if ent.Attempts++; ent.Attempts < sp.cfg.MaxAttempts {
+					if data, err1 := json.Marshal(ent); err1 == nil {
+						writeSpoolFile(file, data)
+					}
+					return err
+				}
+			}
+		}
+		if err != nil && dash.logger != nil {
+			dash.logger("API: dropping spooled request %v: %v", file, err)
+		}
+		os.Remove(file)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeSpoolServer struct {
+	down   bool
+	failOn string // the method always fails
+	calls  []string
+}
+
+func (srv *fakeSpoolServer) handle(method string, payload []byte) (interface{}, error) {
+	if srv.down || method == srv.failOn {
+		return nil, fmt.Errorf("dashboard is down")
+	}
+	srv.calls = append(srv.calls, method)
+	return nil, nil
+}
+
+func testSpoolDashboard(t *testing.T, srv *fakeSpoolServer, cfg SpoolConfig) *Dashboard {
+	dash := testDashboard(t, srv.handle)
+	var err error
+	if dash.spool, err = openSpool(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	return dash
+}
+
+func TestSpool(t *testing.T) {
+	srv := &fakeSpoolServer{down: true}
+	cfg := SpoolConfig{Dir: filepath.Join(t.TempDir(), "spool")}
+	dash := testSpoolDashboard(t, srv, cfg)
+	ctx := context.Background()
+	if err := dash.UploadBuild(ctx, &Build{ID: "build1"}); err != nil {
+		t.Fatal(err)
+	}
+	// The reply of a spooled ReportCrash is not known.
+	if _, err := dash.ReportCrash(ctx, &Crash{BuildID: "build1", Title: "title1"}); !errors.Is(err, ErrSpooled) {
+		t.Fatalf("ReportCrash: %v", err)
+	}
+	// Queries with replies can't be spooled.
+	if _, err := dash.BugList(ctx); err == nil {
+		t.Fatalf("BugList did not fail")
+	}
+
+	// The spool survives restarts.
+	dash = testSpoolDashboard(t, srv, cfg)
+	files, err := dash.spool.files()
+	if err != nil || len(files) != 2 {
+		t.Fatalf("spooled files: %v, %v", files, err)
+	}
+	srv.down = false
+	if err := dash.spool.replay(dash); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"upload_build", "report_crash"}, srv.calls); diff != "" {
+		t.Fatal(diff)
+	}
+	if files, err := dash.spool.files(); err != nil || len(files) != 0 {
+		t.Fatalf("spooled files after replay: %v, %v", files, err)
+	}
+}
+
+func TestSpoolFull(t *testing.T) {
+	srv := &fakeSpoolServer{down: true}
+	dash := testSpoolDashboard(t, srv, SpoolConfig{Dir: t.TempDir(), MaxEntries: 1})
+	ctx := context.Background()
+	if err := dash.UploadBuild(ctx, &Build{ID: "build1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.UploadBuild(ctx, &Build{ID: "build2"}); err == nil {
+		t.Fatalf("request was spooled into a full spool")
+	}
+}
+
+func TestSpoolMaxAttempts(t *testing.T) {
+	srv := &fakeSpoolServer{down: true}
+	dash := testSpoolDashboard(t, srv, SpoolConfig{Dir: t.TempDir(), MaxAttempts: 2})
+	ctx := context.Background()
+	if err := dash.UploadBuild(ctx, &Build{ID: "build1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.UploadCommits(ctx, []Commit{{Hash: "hash1"}}); err != nil {
+		t.Fatal(err)
+	}
+	srv.down = false
+	srv.failOn = "upload_build"
+	// The first replay stops on the failing request, the second one drops it.
+	if err := dash.spool.replay(dash); err == nil {
+		t.Fatalf("replay did not fail")
+	}
+	if files, err := dash.spool.files(); err != nil || len(files) != 2 {
+		t.Fatalf("spooled files: %v, %v", files, err)
+	}
+	if err := dash.spool.replay(dash); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"upload_commits"}, srv.calls); diff != "" {
+		t.Fatal(diff)
+	}
+	if files, err := dash.spool.files(); err != nil || len(files) != 0 {
+		t.Fatalf("spooled files after replay: %v, %v", files, err)
+	}
+}
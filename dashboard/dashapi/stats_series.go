@@ -0,0 +1,64 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"time"
+)
+
+// Managers periodically upload points of their fuzzing stats with UploadStatsSeries, and fleet
+// dashboards query them with StatsSeries. Unlike UploadManagerStats, the values are not aggregated
+// per day, instead the dashboard keeps the last point per period: 10 minutes for recent points,
+// then hours and then days for older points.
+
+// Names of the stats managers upload. All of them are totals since the manager start
+// (e.g. StatExecs) or current levels (e.g. StatCorpus).
+const (
+	StatExecs          = "execs"
+	StatCover          = "cover" // PCs
+	StatSignal         = "signal"
+	StatCorpus         = "corpus"
+	StatCrashes        = "crashes"
+	StatFuzzerRestarts = "fuzzer_restarts" // VM restarts
+)
+
+type StatsPoint struct {
+	Time   time.Time
+	Values map[string]int64
+}
+
+type UploadStatsSeriesReq struct {
+	Manager string
+	Points  []StatsPoint // several points if previous uploads failed
+}
+
+// MaxStatsPoints is the maximum number of points in a single upload.
+const MaxStatsPoints = 100
+
+func (dash *Dashboard) UploadStatsSeries(ctx context.Context, req *UploadStatsSeriesReq) error {
+	return dash.Query(ctx, "upload_stats_series", req, nil)
+}
+
+type StatsSeriesReq struct {
+	Manager string // all managers of the namespace if empty
+	From    time.Time
+	To      time.Time // now if zero
+}
+
+type StatsSeriesResp struct {
+	Period time.Duration // the dashboard picks the finest period it still has for the range
+	Series []*StatsSeries
+}
+
+type StatsSeries struct {
+	Manager string
+	Points  []StatsPoint // in the time order, one per period
+}
+
+func (dash *Dashboard) StatsSeries(ctx context.Context, req *StatsSeriesReq) (*StatsSeriesResp, error) {
+	resp := new(StatsSeriesResp)
+	err := dash.Query(ctx, "stats_series", req, resp)
+	return resp, err
+}
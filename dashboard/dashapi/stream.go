@@ -0,0 +1,175 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+)
+
+// API requests are streamed: the payload is encoded and compressed straight into the request body,
+// so that multi-MB crash logs are not kept in memory both as JSON and as the compressed form.
+// Signed requests are the exception, the signature covers the whole body, so they are buffered.
+
+// writeForm writes the API request form and returns the size of the payload before and after compression.
+func (dash *Dashboard) writeForm(form *multipart.Writer, method string, req interface{}, protoReq bool) (
+	int, int, error) {
+	// Older dashboards read the credentials only from the form, see ClientHeader.
+	if !dash.headerCreds.Load() {
+		if err := form.WriteField("client", dash.Client); err != nil {
+			return 0, 0, err
+		}
+		if !dash.sign {
+			if err := form.WriteField("key", dash.Key); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if err := form.WriteField("method", method); err != nil {
+		return 0, 0, err
+	}
+	// Unlike the credentials, the namespace is in the form to be covered by the signature.
+	if dash.Namespace != "" {
+		if err := form.WriteField("namespace", dash.Namespace); err != nil {
+			return 0, 0, err
+		}
+	}
+	if protoReq {
+		if err := form.WriteField("encoding", "proto"); err != nil {
+			return 0, 0, err
+		}
+	}
+	if req != nil {
+		pw := &payloadWriter{dash: dash, form: form}
+		if err := pw.encode(req, protoReq); err != nil {
+			return 0, 0, err
+		}
+		if err := pw.Close(); err != nil {
+			return 0, 0, err
+		}
+		if err := form.Close(); err != nil {
+			return 0, 0, err
+		}
+		return pw.size, pw.compressed.n, nil
+	}
+	return 0, 0, form.Close()
+}
+
+// payloadWriter compresses the payload into the "payload" form field as it's being encoded.
+// The compression is chosen once the payload has exceeded the compression threshold
+// (or has been fully encoded), until then the payload is buffered.
+type payloadWriter struct {
+	dash       *Dashboard
+	form       *multipart.Writer
+	buf        []byte
+	size       int
+	out        io.WriteCloser
+	compressed countingWriter
+	werr       error
+}
+
+func (pw *payloadWriter) encode(req interface{}, protoReq bool) error {
+	if protoReq {
+		data, err := MarshalProto(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		_, err = pw.Write(data)
+		return err
+	}
+	if err := json.NewEncoder(pw).Encode(req); err != nil {
+		if pw.werr != nil {
+			return pw.werr
+		}
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return nil
+}
+
+func (pw *payloadWriter) Write(p []byte) (int, error) {
+	pw.size += len(p)
+	if pw.out == nil {
+		if pw.size < pw.dash.threshold && pw.dash.plainServer.Load() {
+			pw.buf = append(pw.buf, p...)
+			return len(p), nil
+		}
+		if err := pw.start(pw.dash.payloadCompression(pw.size)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := pw.out.Write(p)
+	if err != nil {
+		pw.werr = err
+	}
+	return n, err
+}
+
+func (pw *payloadWriter) Close() error {
+	if pw.out == nil {
+		if err := pw.start(pw.dash.payloadCompression(pw.size)); err != nil {
+			return err
+		}
+	}
+	return pw.out.Close()
+}
+
+func (pw *payloadWriter) start(compression Compression) error {
+	if compression != CompressionGzip {
+		if err := pw.form.WriteField("compression", string(compression)); err != nil {
+			pw.werr = err
+			return err
+		}
+	}
+	w, err := pw.form.CreateFormField("payload")
+	if err != nil {
+		pw.werr = err
+		return err
+	}
+	pw.compressed.w = w
+	pw.out = newCompressor(&pw.compressed, compression)
+	if _, err := pw.out.Write(pw.buf); err != nil {
+		pw.werr = err
+		return err
+	}
+	pw.buf = nil
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// streamForm returns the body of the request form that is written in a goroutine as the body is read.
+// wait must be called once the request is done, it returns the payload sizes and the error
+// of writing the form, if any.
+func (dash *Dashboard) streamForm(method string, req interface{}, protoReq bool) (
+	body io.Reader, contentType string, wait func() (int, int, error)) {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	type result struct {
+		size, compressed int
+		err              error
+	}
+	done := make(chan result, 1)
+	go func() {
+		size, compressed, err := dash.writeForm(form, method, req, protoReq)
+		pw.CloseWithError(err)
+		done <- result{size, compressed, err}
+	}()
+	return pr, form.FormDataContentType(), func() (int, int, error) {
+		// Unblock the writer if the transport has not read the whole body.
+		pr.Close()
+		res := <-done
+		return res.size, res.compressed, res.err
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamedRequests(t *testing.T) {
+	var lengths []int64
+	var logs [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lengths = append(lengths, r.ContentLength)
+		if err := r.ParseMultipartForm(64 << 20); err != nil {
+			t.Fatal(err)
+		}
+		payload, err := DecompressPayload(r.PostFormValue("compression"), []byte(r.PostFormValue("payload")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		crash := new(Crash)
+		if err := json.Unmarshal(payload, crash); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, crash.Log)
+		w.Header().Set(CompressionHeader, "gzip, zstd, none")
+		json.NewEncoder(w).Encode(&ReportCrashResp{})
+	}))
+	defer srv.Close()
+
+	crash := &Crash{Title: "title", Log: bytes.Repeat([]byte("crash log line\n"), 1<<18)}
+	for _, opts := range [][]DashboardOpts{
+		{CompressionGzip},
+		{CompressionZstd},
+		{SignRequests(true)},
+	} {
+		lengths, logs = nil, nil
+		dash, err := New("client", srv.URL, "key", append(opts, RateLimits{})...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The second request is sent after the dashboard has advertised zstd and uncompressed payloads,
+		// the third one is small enough to be sent uncompressed.
+		for _, crash := range []*Crash{crash, crash, {Title: "small"}} {
+			if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !bytes.Equal(logs[0], crash.Log) || !bytes.Equal(logs[1], crash.Log) || len(logs[2]) != 0 {
+			t.Fatalf("%v: the dashboard got corrupted logs", opts)
+		}
+		// Streamed requests are chunked, signed requests are buffered to be signed.
+		for _, length := range lengths {
+			if signed := opts[0] == SignRequests(true); signed != (length > 0) {
+				t.Fatalf("%v: request length is %v", opts, length)
+			}
+		}
+	}
+}
+
+func TestStreamedMarshalError(t *testing.T) {
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		_, err := io.ReadAll(r.Body)
+		return nil, err
+	})
+	dash, err := New("client", "http://dashboard", "key", transport, RateLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dash.Query(context.Background(), "method", map[string]interface{}{"ch": make(chan int)}, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to marshal request") || IsTemporary(err) {
+		t.Fatalf("got error %v, want a non-temporary marshal error", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// ClientTLS configures TLS for self-hosted dashboards behind a terminator that authenticates
+// clients with certificates (mutual TLS) or uses a private CA.
+// It applies to both HTTP and gRPC connections.
+type ClientTLS struct {
+	CertFile string // PEM-encoded client certificate
+	KeyFile  string // PEM-encoded private key of the client certificate
+	CAFile   string // PEM-encoded CA bundle to verify the dashboard with, system roots if empty
+}
+
+func (cfg *ClientTLS) config() (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAFile != "" {
+		data, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in CA bundle %v", cfg.CAFile)
+		}
+	}
+	return conf, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func makeTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, key.Public(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert, key}
+}
+
+func (cert *testCert) write(t *testing.T, dir string) (certFile, keyFile string) {
+	keyDER, err := x509.MarshalECPrivateKey(cert.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, cert.cert.Subject.CommonName+".crt")
+	keyFile = filepath.Join(dir, cert.cert.Subject.CommonName+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestClientTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := makeTestCert(t, "ca", nil)
+	caFile, _ := ca.write(t, dir)
+	serverCert := makeTestCert(t, "server", ca)
+	clientCert, clientKey := makeTestCert(t, "client", ca).write(t, dir)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&LogEntry{Name: r.TLS.PeerCertificates[0].Subject.CommonName})
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{serverCert.cert.Raw},
+			PrivateKey:  serverCert.key,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dash, err := New("client", srv.URL, "key", RetryPolicy{Attempts: 1}, ClientTLS{
+		CertFile: clientCert,
+		KeyFile:  clientKey,
+		CAFile:   caFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := new(LogEntry)
+	if err := dash.Query(context.Background(), "test", nil, reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Name != "client" {
+		t.Fatalf("the dashboard got wrong client certificate %q", reply.Name)
+	}
+
+	// Without the client certificate the dashboard rejects the connection.
+	dash, err = New("client", srv.URL, "key", RetryPolicy{Attempts: 1}, ClientTLS{CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.Query(context.Background(), "test", nil, nil); err == nil {
+		t.Fatalf("the request without a client certificate succeeded")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ToolBugReq describes a bug in syzkaller itself (e.g. an executor crash or a fuzzer panic).
+// Tool bugs are tracked separately from kernel bugs and are deduplicated by title and commit.
+type ToolBugReq struct {
+	Component       string // e.g. "executor", "fuzzer", "repro"
+	Title           string
+	SyzkallerCommit string
+	Log             []byte
+	Report          []byte
+	Count           int // number of occurrences, 0 is treated as 1
+}
+
+// Tool bugs tend to come in bursts of thousands, so after a tool bug was uploaded,
+// further occurrences within the window are only counted and the total count
+// is sent at the end of the window.
+const toolBugDedupWindow = time.Hour
+
+type toolBugDedup struct {
+	mu      sync.Mutex
+	entries map[toolBugKey]*toolBugEntry
+}
+
+type toolBugKey struct {
+	component string
+	title     string
+	commit    string
+}
+
+type toolBugEntry struct {
+	lastUpload time.Time
+	pending    int
+	timer      *time.Timer // sends the pending occurrences at the end of the window
+}
+
+// ReportToolBug reports a syzkaller bug.
+// Repeated reports of the same bug are aggregated on the client side.
+func (dash *Dashboard) ReportToolBug(ctx context.Context, req *ToolBugReq) error {
+	key := toolBugKey{req.Component, req.Title, req.SyzkallerCommit}
+	count := max(req.Count, 1)
+	now := time.Now()
+	dedup := &dash.toolBugs
+	dedup.mu.Lock()
+	if dedup.entries == nil {
+		dedup.entries = make(map[toolBugKey]*toolBugEntry)
+	}
+	ent := dedup.entries[key]
+	if ent == nil {
+		ent = new(toolBugEntry)
+		dedup.entries[key] = ent
+	}
+	if now.Sub(ent.lastUpload) < toolBugDedupWindow {
+		ent.pending += count
+		if ent.timer == nil {
+			ent.timer = time.AfterFunc(ent.lastUpload.Add(toolBugDedupWindow).Sub(now), func() {
+				dash.flushToolBug(key)
+			})
+		}
+		dedup.mu.Unlock()
+		return nil
+	}
+	count += ent.pending
+	ent.pending = 0
+	ent.lastUpload = now
+	if ent.timer != nil {
+		ent.timer.Stop()
+		ent.timer = nil
+	}
+	dedup.mu.Unlock()
+
+	upload := *req
+	upload.Count = count
+	if err := dash.Query(ctx, "report_tool_bug", &upload, nil); err != nil {
+		// Don't lose the occurrences, they will be sent with the next upload.
+		dedup.mu.Lock()
+		ent.pending += count
+		ent.lastUpload = time.Time{}
+		dedup.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// flushToolBug sends the occurrences counted within the window, unless they were already sent with a new report.
+func (dash *Dashboard) flushToolBug(key toolBugKey) {
+	dedup := &dash.toolBugs
+	dedup.mu.Lock()
+	ent := dedup.entries[key]
+	ent.timer = nil
+	count := ent.pending
+	if count == 0 {
+		dedup.mu.Unlock()
+		return
+	}
+	ent.pending = 0
+	ent.lastUpload = time.Now()
+	dedup.mu.Unlock()
+
+	req := &ToolBugReq{
+		Component:       key.component,
+		Title:           key.title,
+		SyzkallerCommit: key.commit,
+		Count:           count,
+	}
+	if err := dash.Query(context.Background(), "report_tool_bug", req, nil); err != nil {
+		// The occurrences will be sent with the next report.
+		dedup.mu.Lock()
+		ent.pending += count
+		ent.lastUpload = time.Time{}
+		dedup.mu.Unlock()
+	}
+}
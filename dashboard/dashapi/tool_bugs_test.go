@@ -0,0 +1,87 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestToolBugDedup(t *testing.T) {
+	var uploads []*ToolBugReq
+	fail := false
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		if fail {
+			return nil, fmt.Errorf("injected failure")
+		}
+		req := new(ToolBugReq)
+		if err := json.Unmarshal(payload, req); err != nil {
+			t.Fatal(err)
+		}
+		uploads = append(uploads, req)
+		return nil, nil
+	})
+	report := func() error {
+		return dash.ReportToolBug(context.Background(), &ToolBugReq{
+			Component:       "executor",
+			Title:           "SYZFAIL: executor failed",
+			SyzkallerCommit: "commit",
+		})
+	}
+	expire := func() {
+		for _, ent := range dash.toolBugs.entries {
+			ent.lastUpload = ent.lastUpload.Add(-toolBugDedupWindow)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if err := report(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(uploads) != 1 || uploads[0].Count != 1 {
+		t.Fatalf("want a single upload with count 1, got %+v", uploads)
+	}
+	// The failed upload is retried with the next report.
+	expire()
+	fail = true
+	if err := report(); err == nil {
+		t.Fatalf("expected an error")
+	}
+	fail = false
+	if err := report(); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 2 || uploads[1].Count != 1001 {
+		t.Fatalf("want the aggregated count 1001, got %+v", uploads[len(uploads)-1])
+	}
+	// Other commits are reported separately.
+	if err := dash.ReportToolBug(context.Background(), &ToolBugReq{
+		Component:       "executor",
+		Title:           "SYZFAIL: executor failed",
+		SyzkallerCommit: "commit2",
+		Count:           5,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 3 || uploads[2].Count != 5 {
+		t.Fatalf("want a separate upload for a new commit, got %+v", uploads)
+	}
+	// The pending occurrences are sent at the end of the window even without new reports.
+	for i := 0; i < 10; i++ {
+		if err := report(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := toolBugKey{"executor", "SYZFAIL: executor failed", "commit"}
+	ent := dash.toolBugs.entries[key]
+	if ent.timer == nil || !ent.timer.Stop() {
+		t.Fatalf("the pending occurrences are not scheduled for upload")
+	}
+	dash.flushToolBug(key)
+	if len(uploads) != 4 || uploads[3].Count != 10 || ent.timer != nil {
+		t.Fatalf("want the pending count 10, got %+v", uploads[len(uploads)-1])
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing makes the client emit an OpenTelemetry client span per dashboard request
+// (attributes: method, payload size, number of attempts) and propagate the trace context
+// to the dashboard in request headers, so that slow requests can be correlated
+// with the dashboard-side traces.
+type Tracing struct {
+	TracerProvider trace.TracerProvider          // otel.GetTracerProvider() if nil
+	Propagator     propagation.TextMapPropagator // W3C trace context if nil
+}
+
+const tracerName = "github.com/google/syzkaller/dashboard/dashapi"
+
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+type traceAttemptsKey struct{}
+
+func newTracing(cfg *Tracing) *tracing {
+	provider := cfg.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := cfg.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &tracing{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagator,
+	}
+}
+
+func (t *tracing) query(ctx context.Context, dash *Dashboard, method string, req, reply interface{}) error {
+	ctx, span := t.tracer.Start(ctx, "dashapi."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("dashapi.method", method)))
+	defer span.End()
+	attempts := new(int)
+	err := dash.query(context.WithValue(ctx, traceAttemptsKey{}, attempts), method, req, reply)
+	span.SetAttributes(attribute.Int("dashapi.attempts", *attempts))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return err
+}
+
+// interceptor accounts attempts and propagates the trace context to the dashboard.
+func (t *tracing) interceptor() Interceptor {
+	return Interceptor{
+		OnRequest: func(ctx context.Context, call *Call) error {
+			if attempts, ok := ctx.Value(traceAttemptsKey{}).(*int); ok {
+				*attempts++
+			}
+			t.propagator.Inject(ctx, propagation.HeaderCarrier(call.Header))
+			return nil
+		},
+	}
+}
+
+func tracePayload(ctx context.Context, size, compressed int) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("dashapi.payload_bytes", size),
+		attribute.Int("dashapi.compressed_payload_bytes", compressed))
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+type testTracerProvider struct {
+	embedded.TracerProvider
+	spans []*testSpan
+}
+
+type testTracer struct {
+	embedded.Tracer
+	provider *testTracerProvider
+}
+
+type testSpan struct {
+	trace.Span // non-recording span for the methods that are not overridden
+	name       string
+	ctx        trace.SpanContext
+	attrs      map[attribute.Key]attribute.Value
+	status     codes.Code
+	ended      bool
+}
+
+func (p *testTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return &testTracer{provider: p}
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (
+	context.Context, trace.Span) {
+	p := tr.provider
+	span := &testSpan{
+		Span: trace.SpanFromContext(context.Background()),
+		name: name,
+		ctx: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1, 2, 3},
+			SpanID:     trace.SpanID{byte(len(p.spans) + 1)},
+			TraceFlags: trace.FlagsSampled,
+		}),
+		attrs: make(map[attribute.Key]attribute.Value),
+	}
+	cfg := trace.NewSpanStartConfig(opts...)
+	for _, attr := range cfg.Attributes() {
+		span.attrs[attr.Key] = attr.Value
+	}
+	p.spans = append(p.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *testSpan) SpanContext() trace.SpanContext { return s.ctx }
+func (s *testSpan) IsRecording() bool              { return true }
+func (s *testSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+func (s *testSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *testSpan) End(...trace.SpanEndOption) { s.ended = true }
+
+func TestTracing(t *testing.T) {
+	var traceparents []string
+	fail := 1
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		// Requests are streamed, so the payload size is known only once the body is read.
+		io.Copy(io.Discard, r.Body)
+		if fail > 0 {
+			fail--
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	provider := new(testTracerProvider)
+	dash, err := New("client", "http://dashboard", "key", transport, Tracing{TracerProvider: provider},
+		RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.Query(context.Background(), "log_error", &LogEntry{Text: "error"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.spans) != 1 {
+		t.Fatalf("got %v spans, want 1", len(provider.spans))
+	}
+	span := provider.spans[0]
+	if span.name != "dashapi.log_error" || !span.ended || span.status != codes.Ok {
+		t.Fatalf("bad span %+v", span)
+	}
+	if got := span.attrs["dashapi.method"].AsString(); got != "log_error" {
+		t.Errorf("method attribute is %q", got)
+	}
+	if got := span.attrs["dashapi.attempts"].AsInt64(); got != 2 {
+		t.Errorf("attempts attribute is %v, want 2", got)
+	}
+	if got := span.attrs["dashapi.payload_bytes"].AsInt64(); got == 0 {
+		t.Errorf("no payload size attribute")
+	}
+	want := "00-01020300000000000000000000000000-0100000000000000-01"
+	if len(traceparents) != 2 || traceparents[0] != want || traceparents[1] != want {
+		t.Errorf("got traceparent headers %q, want %q", traceparents, want)
+	}
+
+	fail = 1
+	dash.retry = RetryPolicy{}
+	if err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil); err == nil {
+		t.Fatalf("the failed request succeeded")
+	}
+	if span := provider.spans[1]; span.status != codes.Error {
+		t.Errorf("the failed request has span status %v", span.status)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:password"))
+		if got := r.Header.Get("Proxy-Authorization"); got != want {
+			http.Error(w, "bad proxy credentials "+got, http.StatusProxyAuthRequired)
+			return
+		}
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+	proxyURL := "http://user:password@" + proxy.Listener.Addr().String()
+	dash, err := New("client", "http://dashboard.invalid", "key", Proxy(proxyURL), RetryPolicy{Attempts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://dashboard.invalid/api" {
+		t.Fatalf("bad proxied requests: %q", proxied)
+	}
+	if _, err := New("client", "http://dashboard.invalid", "key", Proxy("proxy:3128")); err == nil {
+		t.Fatalf("bad proxy URL was accepted")
+	}
+}
+
+type staticAuth string
+
+func (a staticAuth) Authorization(ctx context.Context) (string, error) {
+	return string(a), nil
+}
+
+func TestCustomTransport(t *testing.T) {
+	var headers []http.Header
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		headers = append(headers, r.Header)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	dash, err := New("client", "http://dashboard", "", transport, UserAgent("agent"), staticAuth("Bearer token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dash.Query(context.Background(), "test", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 1 || headers[0].Get("User-Agent") != "agent" ||
+		headers[0].Get("Authorization") != "Bearer token" {
+		t.Fatalf("bad request headers: %v", headers)
+	}
+	if _, err := New("client", "http://dashboard", "key", transport, Proxy("http://proxy:3128")); err == nil {
+		t.Fatalf("Proxy with a custom Transport was accepted")
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	// Socket paths are limited to ~100 bytes, t.TempDir() may be too long.
+	dir, err := os.MkdirTemp("", "dashapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "dash.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&LogEntry{Name: r.URL.Path, Text: r.FormValue("method")})
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	dash, err := New("client", "unix://"+socket, "key", RetryPolicy{Attempts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := new(LogEntry)
+	if err := dash.Query(context.Background(), "test", nil, reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Name != "/api" || reply.Text != "test" {
+		t.Fatalf("bad request: %+v", reply)
+	}
+	for _, opt := range []DashboardOpts{Proxy("http://proxy:3128"), testTransport(nil)} {
+		if _, err := New("client", "unix://"+socket, "key", opt); err == nil {
+			t.Fatalf("unix socket with %T was accepted", opt)
+		}
+	}
+	if _, err := New("client", "unix://", "key"); err == nil {
+		t.Fatalf("empty socket path was accepted")
+	}
+}
+
+func TestHeaderCredentials(t *testing.T) {
+	type request struct {
+		header, form string
+	}
+	var requests []request
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, request{
+			header: r.Header.Get(ClientHeader) + ":" + r.Header.Get(KeyHeader),
+			form:   r.PostFormValue("client") + ":" + r.PostFormValue("key"),
+		})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{CredentialsHeader: {HeaderCredentials}},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	dash, err := New("client", "http://dashboard", "key", transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first request also has the credentials in the form, since the dashboard may be old.
+	want := []request{
+		{"client:key", "client:key"},
+		{"client:key", ":"},
+	}
+	if len(requests) != len(want) || requests[0] != want[0] || requests[1] != want[1] {
+		t.Fatalf("got requests %+v, want %+v", requests, want)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var namespaces []string
+	transport := testTransport(func(r *http.Request) (*http.Response, error) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		namespaces = append(namespaces, r.PostFormValue("namespace"))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	for _, opts := range [][]DashboardOpts{{transport}, {transport, Namespace("ns")}} {
+		dash, err := New("client", "http://dashboard", "key", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dash.Query(context.Background(), "log_error", &LogEntry{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(namespaces) != 2 || namespaces[0] != "" || namespaces[1] != "ns" {
+		t.Fatalf("got namespaces %q", namespaces)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// TruncationPolicy limits sizes of large payloads before they are uploaded to the dashboard.
+// It can be passed to New. Fields with a zero Truncation are uploaded intact,
+// in particular reports are kept intact unless Report is set. Reproducers (ReproSyz, ReproC,
+// ReproOpts) are never truncated, since a truncated reproducer is useless.
+type TruncationPolicy struct {
+	Log          Truncation // Crash.Log
+	Report       Truncation // Crash.Report
+	ReproLog     Truncation // Crash.ReproLog, CrashID.ReproLog and ReproTaskResult.ReproLog
+	KernelConfig Truncation // Build.KernelConfig
+}
+
+// Truncation says how to truncate a payload larger than Limit bytes.
+type Truncation struct {
+	Limit int // number of bytes to keep (not counting the cut marker), 0 means no limit
+	Keep  TruncateMode
+}
+
+type TruncateMode int
+
+const (
+	KeepTail        TruncateMode = iota // cut out the beginning, e.g. for console logs that end with the crash
+	KeepHead                            // cut out the end
+	KeepHeadAndTail                     // cut out the middle, keep Limit/2 bytes on both sides
+)
+
+// Truncate returns data truncated according to tr. The cut part is replaced with a marker
+// that says how many bytes were cut out.
+func (tr Truncation) Truncate(data []byte) []byte {
+	if tr.Limit <= 0 || len(data) <= tr.Limit {
+		return data
+	}
+	begin, end := 0, 0
+	switch tr.Keep {
+	case KeepTail:
+		end = tr.Limit
+	case KeepHead:
+		begin = tr.Limit
+	case KeepHeadAndTail:
+		begin = tr.Limit / 2
+		end = tr.Limit - begin
+	}
+	var b bytes.Buffer
+	b.Write(data[:begin])
+	if begin > 0 {
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "<<cut %d bytes out>>", len(data)-begin-end)
+	if end > 0 {
+		b.WriteString("\n\n")
+	}
+	b.Write(data[len(data)-end:])
+	return b.Bytes()
+}
+
+func (policy *TruncationPolicy) crash(crash *Crash) *Crash {
+	if policy == nil || crash == nil {
+		return crash
+	}
+	res := *crash
+	res.Log = policy.Log.Truncate(res.Log)
+	res.Report = policy.Report.Truncate(res.Report)
+	res.ReproLog = policy.ReproLog.Truncate(res.ReproLog)
+	return &res
+}
+
+func (policy *TruncationPolicy) build(build *Build) *Build {
+	if policy == nil {
+		return build
+	}
+	res := *build
+	res.KernelConfig = policy.KernelConfig.Truncate(res.KernelConfig)
+	return &res
+}
+
+func (policy *TruncationPolicy) crashID(crash *CrashID) *CrashID {
+	if policy == nil {
+		return crash
+	}
+	res := *crash
+	res.ReproLog = policy.ReproLog.Truncate(res.ReproLog)
+	return &res
+}
+
+func (policy *TruncationPolicy) reproResult(result *ReproTaskResult) *ReproTaskResult {
+	if policy == nil || result == nil {
+		return result
+	}
+	res := *result
+	res.Crash = policy.crash(res.Crash)
+	res.ReproLog = policy.ReproLog.Truncate(res.ReproLog)
+	return &res
+}
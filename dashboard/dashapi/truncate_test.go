@@ -0,0 +1,100 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	data := []byte("0123456789")
+	tests := []struct {
+		tr  Truncation
+		res string
+	}{
+		{Truncation{}, "0123456789"},
+		{Truncation{Limit: 10}, "0123456789"},
+		{Truncation{Limit: 4}, "<<cut 6 bytes out>>\n\n6789"},
+		{Truncation{Limit: 4, Keep: KeepHead}, "0123\n\n<<cut 6 bytes out>>"},
+		{Truncation{Limit: 5, Keep: KeepHeadAndTail}, "01\n\n<<cut 5 bytes out>>\n\n789"},
+	}
+	for _, test := range tests {
+		if res := string(test.tr.Truncate(data)); res != test.res {
+			t.Errorf("%+v: got %q, want %q", test.tr, res, test.res)
+		}
+	}
+}
+
+func TestTruncationPolicy(t *testing.T) {
+	var got *Crash
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		got = new(Crash)
+		if err := json.Unmarshal(payload, got); err != nil {
+			t.Fatal(err)
+		}
+		return nil, nil
+	})
+	dash.truncation = &TruncationPolicy{
+		Log:      Truncation{Limit: 10},
+		ReproLog: Truncation{Limit: 10, Keep: KeepHead},
+	}
+	long := []byte(strings.Repeat("x", 100) + "tail")
+	crash := &Crash{
+		Log:      long,
+		Report:   long,
+		ReproLog: long,
+		ReproSyz: long,
+	}
+	if _, err := dash.ReportCrash(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(got.Log), "tail") || len(got.Log) > 40 {
+		t.Errorf("bad truncated log: %q", got.Log)
+	}
+	if len(got.ReproLog) > 40 || strings.HasSuffix(string(got.ReproLog), "tail") {
+		t.Errorf("bad truncated repro log: %q", got.ReproLog)
+	}
+	if string(got.Report) != string(long) || string(got.ReproSyz) != string(long) {
+		t.Errorf("the report or the reproducer was truncated")
+	}
+	if len(crash.Log) != len(long) {
+		t.Errorf("the original crash was changed")
+	}
+}
+
+func TestFailedReproLogTruncation(t *testing.T) {
+	var got *CrashID
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		got = new(CrashID)
+		if err := json.Unmarshal(payload, got); err != nil {
+			t.Fatal(err)
+		}
+		return nil, nil
+	})
+	long := []byte("head" + strings.Repeat("x", 2*MaxFailedReproLog) + "tail")
+	crash := &CrashID{
+		Title:    "title",
+		ReproLog: long,
+		FailedRepro: &FailedRepro{
+			Attempts:   10,
+			Strategies: []ReproStrategy{ReproStrategyBisection},
+		},
+	}
+	if err := dash.ReportFailedRepro(context.Background(), crash); err != nil {
+		t.Fatal(err)
+	}
+	log := string(got.ReproLog)
+	if len(log) > MaxFailedReproLog+100 || !strings.HasPrefix(log, "head") || !strings.HasSuffix(log, "tail") {
+		t.Errorf("bad truncated repro log: %v bytes", len(log))
+	}
+	if got.FailedRepro.Attempts != 10 || len(got.FailedRepro.Strategies) != 1 {
+		t.Errorf("bad failed repro info: %+v", got.FailedRepro)
+	}
+	if len(crash.ReproLog) != len(long) {
+		t.Errorf("the request was modified")
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Uploader uploads builds, crashes and failed repros in the background, so that the caller
+// is not blocked by the dashboard. Queued uploads are prioritized: builds go first, then crashes
+// with reproducers, then plain crashes, then failed repros. Crashes wait until their build
+// is uploaded. Duplicate uploads that are still queued are coalesced: the latest build/crash/failed
+// repro for the same build and title replaces the queued one, and callbacks of both are called
+// with the result of the single upload.
+type Uploader struct {
+	dash    *Dashboard
+	cfg     UploaderConfig
+	ctx     context.Context
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*upload
+	keys    map[string]*upload
+	builds  map[string]int // number of queued and in-flight builds by ID
+	active  int            // number of in-flight uploads
+	seq     uint64
+	closed  bool
+	workers sync.WaitGroup
+}
+
+type UploaderConfig struct {
+	Workers  int // number of concurrent uploads, 0 means 4
+	MaxQueue int // max number of queued uploads, 0 means 1000
+}
+
+// ErrUploadQueueFull is passed to callbacks of uploads that were dropped because the queue was full.
+var ErrUploadQueueFull = errors.New("dashboard upload queue is full")
+
+// ErrUploaderClosed is passed to callbacks of uploads that were queued after Close.
+var ErrUploaderClosed = errors.New("dashboard uploader is closed")
+
+type uploadPriority int
+
+const (
+	priorityBuild uploadPriority = iota
+	priorityReproCrash
+	priorityCrash
+	priorityFailedRepro
+)
+
+type upload struct {
+	prio    uploadPriority
+	seq     uint64
+	key     string
+	buildID string // the build itself for builds, otherwise the build that must be uploaded first
+	send    func(ctx context.Context) (*ReportCrashResp, error)
+	done    []func(*ReportCrashResp, error)
+}
+
+// NewUploader starts upload workers. The uploads use ctx, the workers exit once Close is called
+// and all queued uploads finish.
+func (dash *Dashboard) NewUploader(ctx context.Context, cfg UploaderConfig) *Uploader {
+	if cfg.Workers == 0 {
+		cfg.Workers = 4
+	}
+	if cfg.MaxQueue == 0 {
+		cfg.MaxQueue = 1000
+	}
+	u := &Uploader{
+		dash:   dash,
+		cfg:    cfg,
+		ctx:    ctx,
+		keys:   make(map[string]*upload),
+		builds: make(map[string]int),
+	}
+	u.cond = sync.NewCond(&u.mu)
+	for i := 0; i < cfg.Workers; i++ {
+		u.workers.Add(1)
+		go u.worker()
+	}
+	return u
+}
+
+// UploadBuild queues the build, done (if not nil) is called with the result.
+func (u *Uploader) UploadBuild(build *Build, done func(error)) {
+	u.add(&upload{
+		prio:    priorityBuild,
+		key:     "build|" + build.ID,
+		buildID: build.ID,
+		send: func(ctx context.Context) (*ReportCrashResp, error) {
+			return nil, u.dash.UploadBuild(ctx, build)
+		},
+	}, func(_ *ReportCrashResp, err error) {
+		if done != nil {
+			done(err)
+		}
+	})
+}
+
+// ReportCrash queues the crash, done (if not nil) is called with the result.
+func (u *Uploader) ReportCrash(crash *Crash, done func(*ReportCrashResp, error)) {
+	prio := priorityCrash
+	if len(crash.ReproSyz) != 0 || len(crash.ReproC) != 0 {
+		prio = priorityReproCrash
+	}
+	u.add(&upload{
+		prio:    prio,
+		key:     fmt.Sprintf("crash|%v|%v|%v", prio, crash.BuildID, crash.Title),
+		buildID: crash.BuildID,
+		send: func(ctx context.Context) (*ReportCrashResp, error) {
+			return u.dash.ReportCrash(ctx, crash)
+		},
+	}, done)
+}
+
+// ReportFailedRepro queues the failed repro, done (if not nil) is called with the result.
+func (u *Uploader) ReportFailedRepro(crash *CrashID, done func(error)) {
+	u.add(&upload{
+		prio:    priorityFailedRepro,
+		key:     fmt.Sprintf("failed_repro|%v|%v", crash.BuildID, crash.Title),
+		buildID: crash.BuildID,
+		send: func(ctx context.Context) (*ReportCrashResp, error) {
+			return nil, u.dash.ReportFailedRepro(ctx, crash)
+		},
+	}, func(_ *ReportCrashResp, err error) {
+		if done != nil {
+			done(err)
+		}
+	})
+}
+
+// Flush waits until all queued uploads finish.
+func (u *Uploader) Flush(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		u.mu.Lock()
+		u.cond.Broadcast()
+		u.mu.Unlock()
+	})
+	defer stop()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for len(u.queue) != 0 || u.active != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		u.cond.Wait()
+	}
+	return nil
+}
+
+// Close stops accepting uploads and waits until the queued uploads finish.
+func (u *Uploader) Close() {
+	u.mu.Lock()
+	u.closed = true
+	u.cond.Broadcast()
+	u.mu.Unlock()
+	u.workers.Wait()
+}
+
+func (u *Uploader) add(up *upload, done func(*ReportCrashResp, error)) {
+	if done == nil {
+		done = func(*ReportCrashResp, error) {}
+	}
+	u.mu.Lock()
+	if u.closed {
+		u.mu.Unlock()
+		done(nil, ErrUploaderClosed)
+		return
+	}
+	if prev := u.keys[up.key]; prev != nil {
+		// The queued upload is replaced, but keeps its place in the queue.
+		prev.send = up.send
+		prev.done = append(prev.done, done)
+		u.mu.Unlock()
+		return
+	}
+	var dropped *upload
+	if len(u.queue) >= u.cfg.MaxQueue {
+		last := u.queue[0]
+		for _, queued := range u.queue {
+			if queued.prio > last.prio || queued.prio == last.prio && queued.seq > last.seq {
+				last = queued
+			}
+		}
+		if last.prio <= up.prio {
+			u.mu.Unlock()
+			done(nil, ErrUploadQueueFull)
+			return
+		}
+		u.remove(last)
+		u.buildDone(last)
+		dropped = last
+	}
+	u.seq++
+	up.seq = u.seq
+	up.done = []func(*ReportCrashResp, error){done}
+	if up.prio == priorityBuild {
+		u.builds[up.buildID]++
+	}
+	u.queue = append(u.queue, up)
+	u.keys[up.key] = up
+	u.cond.Broadcast()
+	u.mu.Unlock()
+	if dropped != nil {
+		for _, fn := range dropped.done {
+			fn(nil, ErrUploadQueueFull)
+		}
+	}
+}
+
+func (u *Uploader) worker() {
+	defer u.workers.Done()
+	for {
+		u.mu.Lock()
+		up := u.next()
+		for up == nil && !(u.closed && len(u.queue) == 0) {
+			u.cond.Wait()
+			up = u.next()
+		}
+		if up == nil {
+			u.mu.Unlock()
+			return
+		}
+		u.remove(up)
+		u.active++
+		u.mu.Unlock()
+
+		resp, err := up.send(u.ctx)
+		for _, fn := range up.done {
+			fn(resp, err)
+		}
+
+		u.mu.Lock()
+		u.active--
+		u.buildDone(up)
+		u.cond.Broadcast()
+		u.mu.Unlock()
+	}
+}
+
+// next returns the upload with the highest priority that can be sent now.
+func (u *Uploader) next() *upload {
+	var best *upload
+	for _, up := range u.queue {
+		if up.prio != priorityBuild && u.builds[up.buildID] != 0 {
+			continue
+		}
+		if best == nil || up.prio < best.prio || up.prio == best.prio && up.seq < best.seq {
+			best = up
+		}
+	}
+	return best
+}
+
+func (u *Uploader) remove(up *upload) {
+	for i, queued := range u.queue {
+		if queued == up {
+			u.queue = append(u.queue[:i], u.queue[i+1:]...)
+			break
+		}
+	}
+	delete(u.keys, up.key)
+}
+
+// buildDone lets crashes for the build go once there are no more queued/in-flight uploads of the build.
+func (u *Uploader) buildDone(up *upload) {
+	if up.prio != priorityBuild {
+		return
+	}
+	if u.builds[up.buildID]--; u.builds[up.buildID] <= 0 {
+		delete(u.builds, up.buildID)
+	}
+}
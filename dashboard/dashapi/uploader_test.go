@@ -0,0 +1,99 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dashapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestUploader(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	unblock := make(chan struct{})
+	dash := testDashboard(t, func(method string, payload []byte) (interface{}, error) {
+		var req struct {
+			ID    string
+			Title string
+		}
+		if err := json.Unmarshal(payload, &req); err != nil {
+			t.Fatal(err)
+		}
+		if req.ID == "blocker" {
+			<-unblock
+		}
+		mu.Lock()
+		sent = append(sent, method+" "+req.ID+req.Title)
+		mu.Unlock()
+		return &ReportCrashResp{NeedRepro: req.Title == "repro"}, nil
+	})
+	u := dash.NewUploader(context.Background(), UploaderConfig{Workers: 1, MaxQueue: 4})
+	u.UploadBuild(&Build{ID: "blocker"}, nil)
+	// Wait until the worker is blocked on the first build.
+	for {
+		u.mu.Lock()
+		active := u.active
+		u.mu.Unlock()
+		if active == 1 {
+			break
+		}
+		runtime.Gosched()
+	}
+	var results []string
+	var resultsMu sync.Mutex
+	report := func(name string) func(*ReportCrashResp, error) {
+		return func(resp *ReportCrashResp, err error) {
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			switch {
+			case errors.Is(err, ErrUploadQueueFull):
+				results = append(results, name+" dropped")
+			case err != nil:
+				t.Errorf("%v failed: %v", name, err)
+			default:
+				results = append(results, name)
+			}
+		}
+	}
+	u.ReportFailedRepro(&CrashID{BuildID: "build", Title: "failed"}, nil)
+	u.ReportCrash(&Crash{BuildID: "build", Title: "plain"}, report("plain1"))
+	u.ReportCrash(&Crash{BuildID: "build", Title: "repro", ReproSyz: []byte("prog")}, report("repro"))
+	u.ReportCrash(&Crash{BuildID: "build", Title: "plain"}, report("plain2"))
+	u.UploadBuild(&Build{ID: "build"}, nil)
+	// The queue is full, the lowest priority upload (the failed repro) is dropped for the crash,
+	// and the next plain crash is dropped itself.
+	u.ReportCrash(&Crash{BuildID: "build", Title: "another"}, report("another"))
+	u.ReportCrash(&Crash{BuildID: "build", Title: "one more"}, report("one more"))
+	close(unblock)
+	if err := u.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	u.Close()
+	u.UploadBuild(&Build{ID: "late"}, func(err error) {
+		if !errors.Is(err, ErrUploaderClosed) {
+			t.Errorf("upload after Close returned %v", err)
+		}
+	})
+
+	want := []string{
+		"upload_build blocker",
+		"upload_build build",
+		"report_crash repro",
+		"report_crash plain",
+		"report_crash another",
+	}
+	if !slices.Equal(sent, want) {
+		t.Fatalf("got uploads\n%q\nwant\n%q", sent, want)
+	}
+	slices.Sort(results)
+	wantResults := []string{"another", "one more dropped", "plain1", "plain2", "repro"}
+	if !slices.Equal(results, wantResults) {
+		t.Fatalf("got results %q, want %q", results, wantResults)
+	}
+}
@@ -5,7 +5,9 @@ package asset
 
 import (
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -30,8 +32,8 @@ type Storage struct {
 }
 
 type Dashboard interface {
-	AddBuildAssets(req *dashapi.AddBuildAssetsReq) error
-	NeededAssetsList() (*dashapi.NeededAssetsResp, error)
+	AddBuildAssets(ctx context.Context, req *dashapi.AddBuildAssetsReq) error
+	NeededAssetsList(ctx context.Context) (*dashapi.NeededAssetsResp, error)
 }
 
 func StorageFromConfig(cfg *Config, dash Dashboard) (*Storage, error) {
@@ -101,17 +103,19 @@ func (storage *Storage) assetPath(name string, extra *ExtraUploadArg) string {
 	return fmt.Sprintf("%s/%s", folderName, name)
 }
 
+// uploadFileStream uploads the file and returns the asset for it.
+// Size and SHA256 of the asset are only known if the file was actually uploaded.
 func (storage *Storage) uploadFileStream(reader io.Reader, assetType dashapi.AssetType,
-	name string, extra *ExtraUploadArg) (string, error) {
+	name string, extra *ExtraUploadArg) (dashapi.NewAsset, error) {
 	if name == "" {
-		return "", fmt.Errorf("file name is not specified")
+		return dashapi.NewAsset{}, fmt.Errorf("file name is not specified")
 	}
 	typeDescr := GetTypeDescription(assetType)
 	if typeDescr == nil {
-		return "", fmt.Errorf("asset type %s is unknown", assetType)
+		return dashapi.NewAsset{}, fmt.Errorf("asset type %s is unknown", assetType)
 	}
 	if !storage.AssetTypeEnabled(assetType) {
-		return "", fmt.Errorf("not allowed to upload an asset of type %s: %w",
+		return dashapi.NewAsset{}, fmt.Errorf("not allowed to upload an asset of type %s: %w",
 			assetType, ErrAssetTypeDisabled)
 	}
 	path := storage.assetPath(name, extra)
@@ -133,30 +137,43 @@ func (storage *Storage) uploadFileStream(reader io.Reader, assetType dashapi.Ass
 	if errors.As(err, &existsErr) {
 		storage.tracer.Log("asset %s already exists", path)
 		if extra == nil || !extra.SkipIfExists {
-			return "", err
+			return dashapi.NewAsset{}, err
 		}
 		// Let's just return the download URL.
-		return storage.backend.downloadURL(existsErr.Path, storage.cfg.PublicAccess)
-	} else if err != nil {
-		return "", fmt.Errorf("failed to query writer: %w", err)
-	} else {
-		written, err := io.Copy(res.writer, reader)
+		url, err := storage.backend.downloadURL(existsErr.Path, storage.cfg.PublicAccess)
 		if err != nil {
-			more := ""
-			closeErr := res.writer.Close()
-			var exiterr *exec.ExitError
-			if errors.As(closeErr, &exiterr) {
-				more = fmt.Sprintf(", process state '%s'", exiterr.ProcessState)
-			}
-			return "", fmt.Errorf("failed to redirect byte stream: copied %d bytes, error %w%s",
-				written, err, more)
+			return dashapi.NewAsset{}, err
 		}
-		err = res.writer.Close()
-		if err != nil {
-			return "", fmt.Errorf("failed to close writer: %w", err)
+		return dashapi.NewAsset{Type: assetType, DownloadURL: url}, nil
+	} else if err != nil {
+		return dashapi.NewAsset{}, fmt.Errorf("failed to query writer: %w", err)
+	}
+	hasher := sha256.New()
+	written, err := io.Copy(res.writer, io.TeeReader(reader, hasher))
+	if err != nil {
+		more := ""
+		closeErr := res.writer.Close()
+		var exiterr *exec.ExitError
+		if errors.As(closeErr, &exiterr) {
+			more = fmt.Sprintf(", process state '%s'", exiterr.ProcessState)
 		}
+		return dashapi.NewAsset{}, fmt.Errorf("failed to redirect byte stream: copied %d bytes, error %w%s",
+			written, err, more)
+	}
+	err = res.writer.Close()
+	if err != nil {
+		return dashapi.NewAsset{}, fmt.Errorf("failed to close writer: %w", err)
 	}
-	return storage.backend.downloadURL(res.path, storage.cfg.PublicAccess)
+	url, err := storage.backend.downloadURL(res.path, storage.cfg.PublicAccess)
+	if err != nil {
+		return dashapi.NewAsset{}, err
+	}
+	return dashapi.NewAsset{
+		Type:        assetType,
+		DownloadURL: url,
+		Size:        written,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+	}, nil
 }
 
 func (storage *Storage) UploadBuildAsset(reader io.Reader, fileName string, assetType dashapi.AssetType,
@@ -172,19 +189,12 @@ func (storage *Storage) UploadBuildAsset(reader io.Reader, fileName string, asse
 		strings.TrimSuffix(baseName, fileExt),
 		commit,
 		fileExt)
-	url, err := storage.uploadFileStream(reader, assetType, name, extra)
-	if err != nil {
-		return dashapi.NewAsset{}, err
-	}
-	return dashapi.NewAsset{
-		Type:        assetType,
-		DownloadURL: url,
-	}, nil
+	return storage.uploadFileStream(reader, assetType, name, extra)
 }
 func (storage *Storage) ReportBuildAssets(build *dashapi.Build, assets ...dashapi.NewAsset) error {
 	// If the server denies the reques, we'll delete the orphaned file during deprecated files
 	// deletion later.
-	return storage.dash.AddBuildAssets(&dashapi.AddBuildAssetsReq{
+	return storage.dash.AddBuildAssets(context.Background(), &dashapi.AddBuildAssetsReq{
 		BuildID: build.ID,
 		Assets:  assets,
 	})
@@ -192,14 +202,7 @@ func (storage *Storage) ReportBuildAssets(build *dashapi.Build, assets ...dashap
 
 func (storage *Storage) UploadCrashAsset(reader io.Reader, fileName string, assetType dashapi.AssetType,
 	extra *ExtraUploadArg) (dashapi.NewAsset, error) {
-	url, err := storage.uploadFileStream(reader, assetType, fileName, extra)
-	if err != nil {
-		return dashapi.NewAsset{}, err
-	}
-	return dashapi.NewAsset{
-		Type:        assetType,
-		DownloadURL: url,
-	}, nil
+	return storage.uploadFileStream(reader, assetType, fileName, extra)
 }
 
 var ErrAssetDoesNotExist = errors.New("the asset did not exist")
@@ -220,7 +223,7 @@ const deletionEmbargo = time.Hour * 24 * 7
 // Best way: convert download URLs to paths.
 // We don't want to risk killing all assets after a slight domain change.
 func (storage *Storage) DeprecateAssets() error {
-	resp, err := storage.dash.NeededAssetsList()
+	resp, err := storage.dash.NeededAssetsList(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to query needed assets: %w", err)
 	}
@@ -6,6 +6,9 @@ package asset
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -31,7 +34,7 @@ func newDashMock() *dashMock {
 	return &dashMock{downloadURLs: map[string]bool{}}
 }
 
-func (dm *dashMock) AddBuildAssets(req *dashapi.AddBuildAssetsReq) error {
+func (dm *dashMock) AddBuildAssets(ctx context.Context, req *dashapi.AddBuildAssetsReq) error {
 	for _, obj := range req.Assets {
 		if dm.addBuildAsset != nil {
 			if err := dm.addBuildAsset(obj); err != nil {
@@ -43,7 +46,7 @@ func (dm *dashMock) AddBuildAssets(req *dashapi.AddBuildAssetsReq) error {
 	return nil
 }
 
-func (dm *dashMock) NeededAssetsList() (*dashapi.NeededAssetsResp, error) {
+func (dm *dashMock) NeededAssetsList(ctx context.Context) (*dashapi.NeededAssetsResp, error) {
 	resp := &dashapi.NeededAssetsResp{}
 	for url := range dm.downloadURLs {
 		resp.DownloadURLs = append(resp.DownloadURLs, url)
@@ -129,6 +132,10 @@ func TestUploadBuildAsset(t *testing.T) {
 		if !strings.Contains(newAsset.DownloadURL, "vmlinux") {
 			t.Fatalf("%#v was expected to mention vmlinux", newAsset.DownloadURL)
 		}
+		sum := sha256.Sum256(vmLinuxContent)
+		if newAsset.Size != int64(len(vmLinuxContent)) || newAsset.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Fatalf("bad vmlinux size/hash: %v %v", newAsset.Size, newAsset.SHA256)
+		}
 		return nil
 	}
 	var file *uploadedFile
@@ -0,0 +1,235 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package bugzilla is a minimal client for the Bugzilla REST API
+// (https://bugzilla.readthedocs.io/en/latest/api/).
+package bugzilla
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewClient creates a client for the Bugzilla instance at addr (e.g. "https://bugzilla.kernel.org").
+// APIKey is used for authentication, it's generated on the user preferences page of the instance.
+func NewClient(addr, apiKey string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(addr, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Bug statuses and resolutions of the default Bugzilla workflow.
+const (
+	StatusResolved = "RESOLVED"
+
+	ResolutionFixed      = "FIXED"
+	ResolutionInvalid    = "INVALID"
+	ResolutionWontFix    = "WONTFIX"
+	ResolutionDuplicate  = "DUPLICATE"
+	ResolutionWorksForMe = "WORKSFORME"
+)
+
+type Bug struct {
+	ID             int       `json:"id"`
+	Summary        string    `json:"summary"`
+	Whiteboard     string    `json:"whiteboard"`
+	Status         string    `json:"status"`
+	Resolution     string    `json:"resolution"` // empty for open bugs
+	DupeOf         int       `json:"dupe_of"`    // set for ResolutionDuplicate
+	LastChangeTime time.Time `json:"last_change_time"`
+}
+
+const bugFields = "id,summary,whiteboard,status,resolution,dupe_of,last_change_time"
+
+type NewBug struct {
+	Product     string   `json:"product"`
+	Component   string   `json:"component"`
+	Version     string   `json:"version"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Whiteboard  string   `json:"whiteboard,omitempty"`
+	CC          []string `json:"cc,omitempty"`
+}
+
+// CreateBug files a new bug and returns its ID.
+func (c *Client) CreateBug(ctx context.Context, bug *NewBug) (int, error) {
+	resp := new(struct {
+		ID int `json:"id"`
+	})
+	if err := c.query(ctx, http.MethodPost, "/rest/bug", nil, bug, resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+type Attachment struct {
+	FileName    string
+	Summary     string
+	ContentType string // "text/plain" if empty
+	Data        []byte
+}
+
+func (c *Client) AddAttachment(ctx context.Context, id int, att *Attachment) error {
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	req := map[string]interface{}{
+		"ids":          []int{id},
+		"file_name":    att.FileName,
+		"summary":      att.Summary,
+		"content_type": contentType,
+		"data":         base64.StdEncoding.EncodeToString(att.Data),
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/rest/bug/%v/attachment", id), nil, req, nil)
+}
+
+func (c *Client) AddComment(ctx context.Context, id int, text string) error {
+	req := map[string]interface{}{
+		"comment": text,
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/rest/bug/%v/comment", id), nil, req, nil)
+}
+
+type BugUpdate struct {
+	Status     string // left intact if empty
+	Resolution string
+	DupeOf     int    // for ResolutionDuplicate
+	Comment    string // added to the bug along with the update, if not empty
+}
+
+func (c *Client) UpdateBug(ctx context.Context, id int, upd *BugUpdate) error {
+	req := make(map[string]interface{})
+	if upd.Status != "" {
+		req["status"] = upd.Status
+	}
+	if upd.Resolution != "" {
+		req["resolution"] = upd.Resolution
+	}
+	if upd.DupeOf != 0 {
+		req["dupe_of"] = upd.DupeOf
+	}
+	if upd.Comment != "" {
+		req["comment"] = map[string]interface{}{"body": upd.Comment}
+	}
+	return c.query(ctx, http.MethodPut, fmt.Sprintf("/rest/bug/%v", id), nil, req, nil)
+}
+
+func (c *Client) GetBug(ctx context.Context, id int) (*Bug, error) {
+	args := url.Values{"include_fields": {bugFields}}
+	resp := new(struct {
+		Bugs []*Bug `json:"bugs"`
+	})
+	if err := c.query(ctx, http.MethodGet, fmt.Sprintf("/rest/bug/%v", id), args, nil, resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Bugs) != 1 {
+		return nil, fmt.Errorf("bugzilla returned %v bugs for bug %v", len(resp.Bugs), id)
+	}
+	return resp.Bugs[0], nil
+}
+
+type Search struct {
+	Whiteboard   string    // a substring of the whiteboard
+	Open         bool      // only bugs without a resolution
+	ChangedSince time.Time // only bugs changed at this time or later, if not zero
+}
+
+func (c *Client) SearchBugs(ctx context.Context, search *Search) ([]*Bug, error) {
+	args := url.Values{"include_fields": {bugFields}}
+	if search.Whiteboard != "" {
+		args.Set("whiteboard", search.Whiteboard)
+	}
+	if search.Open {
+		args.Set("resolution", "---")
+	}
+	if !search.ChangedSince.IsZero() {
+		args.Set("last_change_time", search.ChangedSince.UTC().Format(time.RFC3339))
+	}
+	resp := new(struct {
+		Bugs []*Bug `json:"bugs"`
+	})
+	if err := c.query(ctx, http.MethodGet, "/rest/bug", args, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Bugs, nil
+}
+
+// Error is an error reported by Bugzilla.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("bugzilla error %v: %v", err.Code, err.Message)
+}
+
+func (c *Client) query(ctx context.Context, method, path string, args url.Values, req, reply interface{}) error {
+	addr := c.url + path
+	if len(args) != 0 {
+		addr += "?" + args.Encode()
+	}
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, addr, body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", "application/json")
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		r.Header.Set("X-BUGZILLA-API-KEY", c.apiKey)
+	}
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("bugzilla %v %v failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read bugzilla reply: %w", err)
+	}
+	// Bugzilla reports errors with an error object, the HTTP status is not always set accordingly.
+	bzErr := new(struct {
+		Error   bool   `json:"error"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	})
+	if json.Unmarshal(data, bzErr) == nil && bzErr.Error {
+		return &Error{Code: bzErr.Code, Message: bzErr.Message}
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("bugzilla %v %v failed: %v (%q)", method, path, resp.Status, data)
+	}
+	if reply != nil {
+		if err := json.Unmarshal(data, reply); err != nil {
+			return fmt.Errorf("failed to unmarshal bugzilla reply: %w", err)
+		}
+	}
+	return nil
+}
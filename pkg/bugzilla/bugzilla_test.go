@@ -0,0 +1,142 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  string
+		Body   map[string]interface{}
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("X-BUGZILLA-API-KEY"); key != "key" {
+			t.Errorf("bad api key %q", key)
+		}
+		req := request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+		if data, _ := io.ReadAll(r.Body); len(data) != 0 {
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				t.Errorf("bad request body: %v", err)
+			}
+		}
+		requests = append(requests, req)
+		switch r.URL.Path {
+		case "/rest/bug":
+			if r.Method == http.MethodPost {
+				fmt.Fprint(w, `{"id": 10}`)
+				return
+			}
+			fmt.Fprint(w, `{"bugs": [{"id": 10, "whiteboard": "syzbot:123", "status": "RESOLVED",
+				"resolution": "DUPLICATE", "dupe_of": 9, "last_change_time": "2024-05-01T10:00:00Z"},
+				{"id": 11, "status": "NEW", "resolution": "", "dupe_of": null}]}`)
+		case "/rest/bug/12":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": true, "code": 101, "message": "Bug #12 does not exist."}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewClient(srv.URL+"/", "key")
+	id, err := client.CreateBug(ctx, &NewBug{
+		Product:   "Product",
+		Component: "Component",
+		Summary:   "summary",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 10 {
+		t.Fatalf("bad bug id %v", id)
+	}
+	if err := client.AddAttachment(ctx, id, &Attachment{FileName: "repro.syz", Data: []byte("data")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpdateBug(ctx, id, &BugUpdate{
+		Status:     StatusResolved,
+		Resolution: ResolutionDuplicate,
+		DupeOf:     9,
+		Comment:    "comment",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bugs, err := client.SearchBugs(ctx, &Search{Whiteboard: "syzbot:", Open: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bugs) != 2 || bugs[0].DupeOf != 9 || bugs[0].LastChangeTime.Hour() != 10 ||
+		bugs[1].ID != 11 || bugs[1].Resolution != "" {
+		t.Fatalf("bad bugs: %+v %+v", bugs[0], bugs[1])
+	}
+	_, err = client.GetBug(ctx, 12)
+	var bzErr *Error
+	if !errors.As(err, &bzErr) || bzErr.Code != 101 {
+		t.Fatalf("bad error: %v", err)
+	}
+
+	fields := "id%2Csummary%2Cwhiteboard%2Cstatus%2Cresolution%2Cdupe_of%2Clast_change_time"
+	want := []request{
+		{
+			Method: "POST",
+			Path:   "/rest/bug",
+			Body: map[string]interface{}{
+				"product":     "Product",
+				"component":   "Component",
+				"version":     "",
+				"summary":     "summary",
+				"description": "",
+			},
+		},
+		{
+			Method: "POST",
+			Path:   "/rest/bug/10/attachment",
+			Body: map[string]interface{}{
+				"ids":          []interface{}{10.0},
+				"file_name":    "repro.syz",
+				"summary":      "",
+				"content_type": "text/plain",
+				"data":         "ZGF0YQ==",
+			},
+		},
+		{
+			Method: "PUT",
+			Path:   "/rest/bug/10",
+			Body: map[string]interface{}{
+				"status":     "RESOLVED",
+				"resolution": "DUPLICATE",
+				"dupe_of":    9.0,
+				"comment":    map[string]interface{}{"body": "comment"},
+			},
+		},
+		{
+			Method: "GET",
+			Path:   "/rest/bug",
+			Query:  "include_fields=" + fields + "&resolution=---&whiteboard=syzbot%3A",
+		},
+		{
+			Method: "GET",
+			Path:   "/rest/bug/12",
+			Query:  "include_fields=" + fields,
+		},
+	}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Fatal(diff)
+	}
+}
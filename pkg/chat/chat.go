@@ -0,0 +1,189 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package chat posts short formatted messages to Slack channels and Matrix rooms.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Message is formatted by every client in the native markup of the chat.
+type Message struct {
+	// ID makes posting idempotent where the chat supports it (Matrix), retries must use the same ID.
+	ID     string
+	Title  string
+	Link   string // the link of the title, optional
+	Fields []Field
+}
+
+type Field struct {
+	Name  string
+	Value string
+	Link  string // the link of the value, optional
+}
+
+type Client interface {
+	Post(ctx context.Context, channel string, msg *Message) error
+}
+
+var httpClient = &http.Client{Timeout: time.Minute}
+
+// Slack posts messages with a bot token (https://api.slack.com/methods/chat.postMessage).
+type Slack struct {
+	url   string
+	token string
+}
+
+const SlackURL = "https://slack.com/api"
+
+// NewSlack creates a client for the Slack API at addr (SlackURL if empty).
+func NewSlack(addr, token string) *Slack {
+	if addr == "" {
+		addr = SlackURL
+	}
+	return &Slack{
+		url:   strings.TrimSuffix(addr, "/"),
+		token: token,
+	}
+}
+
+// Post posts the message to the channel, the channel is an ID (e.g. "C1234567890") or a name.
+func (s *Slack) Post(ctx context.Context, channel string, msg *Message) error {
+	req := map[string]interface{}{
+		"channel":      channel,
+		"text":         slackFormat(msg),
+		"unfurl_links": false,
+	}
+	resp := new(struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	})
+	err := query(ctx, http.MethodPost, s.url+"/chat.postMessage", "Bearer "+s.token, req, resp)
+	if err != nil {
+		return err
+	}
+	if !resp.OK {
+		return fmt.Errorf("slack error: %v", resp.Error)
+	}
+	return nil
+}
+
+func slackFormat(msg *Message) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "*%v*\n", slackLink(msg.Link, msg.Title))
+	for _, field := range msg.Fields {
+		fmt.Fprintf(buf, "%v: %v\n", slackEscape(field.Name), slackLink(field.Link, field.Value))
+	}
+	return buf.String()
+}
+
+func slackLink(link, text string) string {
+	if link == "" {
+		return slackEscape(text)
+	}
+	return fmt.Sprintf("<%v|%v>", link, slackEscape(text))
+}
+
+// slackEscape escapes the characters that have a special meaning in Slack messages.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// Matrix posts messages with an access token
+// (https://spec.matrix.org/latest/client-server-api/#sending-events-to-a-room).
+type Matrix struct {
+	url   string
+	token string
+	seq   int
+}
+
+// NewMatrix creates a client for the homeserver at addr (e.g. "https://matrix.org").
+func NewMatrix(addr, token string) *Matrix {
+	return &Matrix{
+		url:   strings.TrimSuffix(addr, "/"),
+		token: token,
+	}
+}
+
+// Post posts the message to the room, the room is an ID (e.g. "!abcdef:matrix.org").
+// The user of the token must have joined the room.
+func (m *Matrix) Post(ctx context.Context, room string, msg *Message) error {
+	txnID := msg.ID
+	if txnID == "" {
+		m.seq++
+		txnID = fmt.Sprintf("syzbot-%v-%v", time.Now().UnixNano(), m.seq)
+	}
+	plain, formatted := matrixFormat(msg)
+	req := map[string]string{
+		"msgtype":        "m.text",
+		"body":           plain,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	}
+	addr := fmt.Sprintf("%v/_matrix/client/v3/rooms/%v/send/m.room.message/%v",
+		m.url, url.PathEscape(room), url.PathEscape(txnID))
+	return query(ctx, http.MethodPut, addr, "Bearer "+m.token, req, nil)
+}
+
+func matrixFormat(msg *Message) (string, string) {
+	plain, formatted := new(bytes.Buffer), new(bytes.Buffer)
+	fmt.Fprintf(plain, "%v\n", msg.Title)
+	fmt.Fprintf(formatted, "<b>%v</b>", matrixLink(msg.Link, msg.Title))
+	for _, field := range msg.Fields {
+		value := field.Value
+		if field.Link != "" {
+			value = fmt.Sprintf("%v (%v)", field.Value, field.Link)
+		}
+		fmt.Fprintf(plain, "%v: %v\n", field.Name, value)
+		fmt.Fprintf(formatted, "<br>%v: %v", html.EscapeString(field.Name), matrixLink(field.Link, field.Value))
+	}
+	return plain.String(), formatted.String()
+}
+
+func matrixLink(link, text string) string {
+	if link == "" {
+		return html.EscapeString(text)
+	}
+	return fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(link), html.EscapeString(text))
+}
+
+func query(ctx context.Context, method, addr, auth string, req, reply interface{}) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, addr, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Authorization", auth)
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return fmt.Errorf("%v %v failed: %w", method, r.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read reply: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%v %v failed: %v (%q)", method, r.URL.Path, resp.Status, body)
+	}
+	if reply != nil {
+		if err := json.Unmarshal(body, reply); err != nil {
+			return fmt.Errorf("failed to unmarshal reply: %w", err)
+		}
+	}
+	return nil
+}
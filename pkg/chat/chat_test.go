@@ -0,0 +1,100 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testMessage = &Message{
+	ID:    "id1",
+	Title: "KASAN: use-after-free in <foo>",
+	Link:  "https://syzbot/bug?id=1",
+	Fields: []Field{
+		{Name: "Maintainers", Value: "a@b.c"},
+		{Name: "Reproducer", Value: "C", Link: "https://syzbot/repro.c"},
+	},
+}
+
+func TestSlack(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("bad request %v %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Error(err)
+		}
+		if got["channel"] == "bad" {
+			fmt.Fprint(w, `{"ok": false, "error": "channel_not_found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok": true}`)
+	}))
+	defer srv.Close()
+
+	client := NewSlack(srv.URL, "token")
+	if err := client.Post(context.Background(), "C1", testMessage); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"channel": "C1",
+		"text": "*<https://syzbot/bug?id=1|KASAN: use-after-free in &lt;foo&gt;>*\n" +
+			"Maintainers: a@b.c\n" +
+			"Reproducer: <https://syzbot/repro.c|C>\n",
+		"unfurl_links": false,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+	if err := client.Post(context.Background(), "bad", testMessage); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	var path string
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("bad request %v %q", r.Method, r.Header.Get("Authorization"))
+		}
+		path = r.URL.EscapedPath()
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Error(err)
+		}
+		fmt.Fprint(w, `{"event_id": "$1"}`)
+	}))
+	defer srv.Close()
+
+	client := NewMatrix(srv.URL+"/", "token")
+	if err := client.Post(context.Background(), "!room:matrix.org", testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/_matrix/client/v3/rooms/%21room:matrix.org/send/m.room.message/id1" {
+		t.Fatalf("bad path %q", path)
+	}
+	want := map[string]interface{}{
+		"msgtype": "m.text",
+		"body": "KASAN: use-after-free in <foo>\n" +
+			"Maintainers: a@b.c\n" +
+			"Reproducer: C (https://syzbot/repro.c)\n",
+		"format": "org.matrix.custom.html",
+		"formatted_body": `<b><a href="https://syzbot/bug?id=1">KASAN: use-after-free in &lt;foo&gt;</a></b>` +
+			`<br>Maintainers: a@b.c<br>Reproducer: <a href="https://syzbot/repro.c">C</a>`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
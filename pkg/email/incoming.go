@@ -0,0 +1,57 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package email
+
+import "github.com/google/syzkaller/dashboard/dashapi"
+
+// Incoming converts the email for forwarding to the dashboard.
+// The email must be parsed with the dashboard own emails and mailing lists.
+func (msg *Email) Incoming() *dashapi.IncomingEmail {
+	in := &dashapi.IncomingEmail{
+		MessageID:   msg.MessageID,
+		InReplyTo:   msg.InReplyTo,
+		Date:        msg.Date,
+		Subject:     msg.Subject,
+		Author:      msg.Author,
+		MailingList: msg.MailingList,
+		BugIDs:      msg.BugIDs,
+		Cc:          msg.Cc,
+		Link:        msg.Link,
+		Body:        msg.Body,
+		Patch:       msg.Patch,
+	}
+	for _, cmd := range msg.Commands {
+		in.Commands = append(in.Commands, dashapi.EmailCommand{
+			Command: cmd.Str,
+			Args:    cmd.Args,
+		})
+	}
+	return in
+}
+
+// FromIncoming is the reverse of Email.Incoming.
+// OwnEmail is not transferred and needs to be set by the caller.
+func FromIncoming(in *dashapi.IncomingEmail) *Email {
+	msg := &Email{
+		BugIDs:      dedupBugIDs(in.BugIDs),
+		MessageID:   in.MessageID,
+		InReplyTo:   in.InReplyTo,
+		Date:        in.Date,
+		Link:        in.Link,
+		Subject:     in.Subject,
+		MailingList: CanonicalEmail(in.MailingList),
+		Author:      CanonicalEmail(in.Author),
+		Cc:          MergeEmailLists(in.Cc),
+		Body:        in.Body,
+		Patch:       in.Patch,
+	}
+	for _, cmd := range in.Commands {
+		msg.Commands = append(msg.Commands, &SingleCommand{
+			Command: strToCmd(cmd.Command),
+			Str:     cmd.Command,
+			Args:    cmd.Args,
+		})
+	}
+	return msg
+}
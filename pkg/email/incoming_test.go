@@ -0,0 +1,38 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package email
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIncoming(t *testing.T) {
+	msg, err := Parse(strings.NewReader(`Date: Sun, 7 May 2017 19:54:00 -0700
+Message-ID: <123>
+In-Reply-To: <456>
+Subject: Re: BUG: unable to handle kernel NULL pointer dereference
+From: Bob <bob@example.com>
+To: syzbot <foo+4564456@bar.com>
+Cc: linux-kernel@vger.kernel.org
+
+#syz dup: BUG: another crash
+#syz invalid
+`), []string{"foo@bar.com"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Commands) != 2 || len(msg.BugIDs) != 1 {
+		t.Fatalf("bad parsed email: %+v", msg)
+	}
+	in := msg.Incoming()
+	if in.Commands[0].Command != "dup:" || in.Commands[0].Args != "BUG: another crash" {
+		t.Fatalf("bad commands: %+v", in.Commands)
+	}
+	if diff := cmp.Diff(msg, FromIncoming(in)); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package github is a minimal client for the GitHub REST API (https://docs.github.com/en/rest)
+// that covers issues and gists.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the API address of github.com, GitHub Enterprise instances have their own addresses.
+const DefaultURL = "https://api.github.com"
+
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a client for the API at addr (DefaultURL if empty).
+// Token is a personal access token or an app token with access to issues (and gists, if they are used).
+func NewClient(addr, token string) *Client {
+	if addr == "" {
+		addr = DefaultURL
+	}
+	return &Client{
+		url:    strings.TrimSuffix(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Issue states and the reasons issues are closed with.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateAll    = "all"
+
+	ReasonCompleted  = "completed"
+	ReasonNotPlanned = "not_planned"
+	ReasonReopened   = "reopened"
+)
+
+type Issue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	StateReason string    `json:"state_reason"`
+	HTMLURL     string    `json:"html_url"`
+	Labels      []Label   `json:"labels"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Set if the issue is a pull request, issue listings return them as well.
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+type Label struct {
+	Name string `json:"name"`
+}
+
+type NewIssue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+func (c *Client) CreateIssue(ctx context.Context, owner, repo string, issue *NewIssue) (*Issue, error) {
+	resp := new(Issue)
+	err := c.query(ctx, http.MethodPost, fmt.Sprintf("/repos/%v/%v/issues", owner, repo), nil, issue, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
+	resp := new(Issue)
+	err := c.query(ctx, http.MethodGet, fmt.Sprintf("/repos/%v/%v/issues/%v", owner, repo, number), nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type IssueUpdate struct {
+	State       string `json:"state,omitempty"`
+	StateReason string `json:"state_reason,omitempty"`
+}
+
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, number int, upd *IssueUpdate) error {
+	return c.query(ctx, http.MethodPatch, fmt.Sprintf("/repos/%v/%v/issues/%v", owner, repo, number), nil, upd, nil)
+}
+
+func (c *Client) AddComment(ctx context.Context, owner, repo string, number int, body string) error {
+	req := map[string]string{
+		"body": body,
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/repos/%v/%v/issues/%v/comments", owner, repo, number),
+		nil, req, nil)
+}
+
+// AddLabels adds the labels to the issue, the labels that don't exist in the repository are created.
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, number int, labels []string) error {
+	req := map[string][]string{
+		"labels": labels,
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/repos/%v/%v/issues/%v/labels", owner, repo, number),
+		nil, req, nil)
+}
+
+type IssueQuery struct {
+	Labels []string  // only issues with all of the labels
+	State  string    // StateOpen by default
+	Since  time.Time // only issues updated at this time or later, if not zero
+}
+
+const issuesPerPage = 100
+
+// ListIssues returns the issues of the repository, pull requests are skipped.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string, query *IssueQuery) ([]*Issue, error) {
+	args := url.Values{"per_page": {strconv.Itoa(issuesPerPage)}}
+	if len(query.Labels) != 0 {
+		args.Set("labels", strings.Join(query.Labels, ","))
+	}
+	if query.State != "" {
+		args.Set("state", query.State)
+	}
+	if !query.Since.IsZero() {
+		args.Set("since", query.Since.UTC().Format(time.RFC3339))
+	}
+	var ret []*Issue
+	for page := 1; ; page++ {
+		args.Set("page", strconv.Itoa(page))
+		var issues []*Issue
+		err := c.query(ctx, http.MethodGet, fmt.Sprintf("/repos/%v/%v/issues", owner, repo), args, nil, &issues)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.PullRequest == nil {
+				ret = append(ret, issue)
+			}
+		}
+		if len(issues) < issuesPerPage {
+			return ret, nil
+		}
+	}
+}
+
+type Gist struct {
+	Description string
+	Public      bool
+	Files       map[string][]byte // file contents by file name
+}
+
+// CreateGist uploads the files as a gist and returns the link to it.
+func (c *Client) CreateGist(ctx context.Context, gist *Gist) (string, error) {
+	files := make(map[string]interface{})
+	for name, data := range gist.Files {
+		files[name] = map[string]string{"content": string(data)}
+	}
+	req := map[string]interface{}{
+		"description": gist.Description,
+		"public":      gist.Public,
+		"files":       files,
+	}
+	resp := new(struct {
+		HTMLURL string `json:"html_url"`
+	})
+	if err := c.query(ctx, http.MethodPost, "/gists", nil, req, resp); err != nil {
+		return "", err
+	}
+	return resp.HTMLURL, nil
+}
+
+// Error is an error reported by GitHub.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("github error %v: %v", err.StatusCode, err.Message)
+}
+
+func (c *Client) query(ctx context.Context, method, path string, args url.Values, req, reply interface{}) error {
+	addr := c.url + path
+	if len(args) != 0 {
+		addr += "?" + args.Encode()
+	}
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, addr, body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", "application/vnd.github+json")
+	r.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("github %v %v failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read github reply: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		ghErr := &Error{StatusCode: resp.StatusCode}
+		msg := new(struct {
+			Message string `json:"message"`
+		})
+		if json.Unmarshal(data, msg) == nil && msg.Message != "" {
+			ghErr.Message = msg.Message
+		} else {
+			ghErr.Message = fmt.Sprintf("%q", data)
+		}
+		return ghErr
+	}
+	if reply != nil {
+		if err := json.Unmarshal(data, reply); err != nil {
+			return fmt.Errorf("failed to unmarshal github reply: %w", err)
+		}
+	}
+	return nil
+}
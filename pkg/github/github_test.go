@@ -0,0 +1,145 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  string
+		Body   map[string]interface{}
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("bad authorization %q", auth)
+		}
+		req := request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+		if data, _ := io.ReadAll(r.Body); len(data) != 0 {
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				t.Errorf("bad request body: %v", err)
+			}
+		}
+		requests = append(requests, req)
+		switch {
+		case r.URL.Path == "/repos/owner/repo/issues" && r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 5, "html_url": "https://github.com/owner/repo/issues/5", "state": "open"}`)
+		case r.URL.Path == "/repos/owner/repo/issues" && r.URL.Query().Get("page") == "1":
+			// A full page, the client must request the next one.
+			var issues []string
+			for i := 0; i < issuesPerPage; i++ {
+				issues = append(issues, fmt.Sprintf(`{"number": %v}`, i+1))
+			}
+			issues[1] = `{"number": 2, "pull_request": {}}`
+			fmt.Fprintf(w, "[%v]", strings.Join(issues, ","))
+		case r.URL.Path == "/repos/owner/repo/issues":
+			fmt.Fprint(w, `[{"number": 200, "state": "closed", "state_reason": "not_planned",
+				"updated_at": "2024-05-01T10:00:00Z"}]`)
+		case r.URL.Path == "/gists":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"html_url": "https://gist.github.com/1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewClient(srv.URL, "token")
+	issue, err := client.CreateIssue(ctx, "owner", "repo", &NewIssue{
+		Title:  "title",
+		Body:   "body",
+		Labels: []string{"syzbot"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Number != 5 || issue.HTMLURL != "https://github.com/owner/repo/issues/5" {
+		t.Fatalf("bad issue: %+v", issue)
+	}
+	issues, err := client.ListIssues(ctx, "owner", "repo", &IssueQuery{
+		Labels: []string{"syzbot", "bug"},
+		State:  StateAll,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := issues[len(issues)-1]
+	if len(issues) != issuesPerPage || issues[1].Number != 3 ||
+		last.Number != 200 || last.StateReason != ReasonNotPlanned || last.UpdatedAt.Hour() != 10 {
+		t.Fatalf("bad issues: %v, %+v", len(issues), last)
+	}
+	link, err := client.CreateGist(ctx, &Gist{Files: map[string][]byte{"repro.c": []byte("int main() {}")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "https://gist.github.com/1" {
+		t.Fatalf("bad gist link %q", link)
+	}
+	err = client.UpdateIssue(ctx, "owner", "repo", 1, &IssueUpdate{State: StateClosed, StateReason: ReasonCompleted})
+	var ghErr *Error
+	if !errors.As(err, &ghErr) || ghErr.StatusCode != http.StatusNotFound || ghErr.Message != "Not Found" {
+		t.Fatalf("bad error: %v", err)
+	}
+
+	want := []request{
+		{
+			Method: "POST",
+			Path:   "/repos/owner/repo/issues",
+			Body: map[string]interface{}{
+				"title":  "title",
+				"body":   "body",
+				"labels": []interface{}{"syzbot"},
+			},
+		},
+		{
+			Method: "GET",
+			Path:   "/repos/owner/repo/issues",
+			Query:  "labels=syzbot%2Cbug&page=1&per_page=100&state=all",
+		},
+		{
+			Method: "GET",
+			Path:   "/repos/owner/repo/issues",
+			Query:  "labels=syzbot%2Cbug&page=2&per_page=100&state=all",
+		},
+		{
+			Method: "POST",
+			Path:   "/gists",
+			Body: map[string]interface{}{
+				"description": "",
+				"public":      false,
+				"files": map[string]interface{}{
+					"repro.c": map[string]interface{}{"content": "int main() {}"},
+				},
+			},
+		},
+		{
+			Method: "PATCH",
+			Path:   "/repos/owner/repo/issues/1",
+			Body: map[string]interface{}{
+				"state":        "closed",
+				"state_reason": "completed",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Fatal(diff)
+	}
+}
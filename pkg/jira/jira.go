@@ -0,0 +1,282 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package jira is a minimal client for the Jira REST API
+// (https://developer.atlassian.com/cloud/jira/platform/rest/v2/), it works with Jira Cloud and Data Center.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	url    string
+	user   string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a client for the Jira instance at addr (e.g. "https://example.atlassian.net").
+// Jira Cloud authenticates with the user email and an API token, Data Center with a personal
+// access token and an empty user.
+func NewClient(addr, user, token string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(addr, "/"),
+		user:   user,
+		token:  token,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// StatusCategoryDone is the key of the status category of resolved issues.
+const StatusCategoryDone = "done"
+
+type Issue struct {
+	Key    string      `json:"key"`
+	Fields IssueFields `json:"fields"`
+}
+
+type IssueFields struct {
+	Summary    string       `json:"summary"`
+	Labels     []string     `json:"labels"`
+	Status     *Status      `json:"status"`
+	Resolution *Resolution  `json:"resolution"` // nil for unresolved issues
+	IssueLinks []*IssueLink `json:"issuelinks"`
+}
+
+type Status struct {
+	Name     string `json:"name"`
+	Category struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+type Resolution struct {
+	Name string `json:"name"`
+}
+
+type IssueLink struct {
+	Type struct {
+		Name string `json:"name"` // e.g. "Duplicate"
+	} `json:"type"`
+	InwardIssue  *Issue `json:"inwardIssue"`
+	OutwardIssue *Issue `json:"outwardIssue"`
+}
+
+const issueFields = "summary,labels,status,resolution,issuelinks"
+
+// CreateIssue creates an issue with the fields (e.g. "project", "issuetype", "summary")
+// and returns its key.
+func (c *Client) CreateIssue(ctx context.Context, fields map[string]interface{}) (string, error) {
+	req := map[string]interface{}{
+		"fields": fields,
+	}
+	resp := new(struct {
+		Key string `json:"key"`
+	})
+	if err := c.query(ctx, http.MethodPost, "/rest/api/2/issue", nil, req, resp); err != nil {
+		return "", err
+	}
+	return resp.Key, nil
+}
+
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	args := url.Values{"fields": {issueFields}}
+	resp := new(Issue)
+	if err := c.query(ctx, http.MethodGet, "/rest/api/2/issue/"+key, args, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) AddComment(ctx context.Context, key, body string) error {
+	req := map[string]string{
+		"body": body,
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/rest/api/2/issue/%v/comment", key), nil, req, nil)
+}
+
+// AddLabels adds the labels to the issue, the existing labels are kept.
+func (c *Client) AddLabels(ctx context.Context, key string, labels []string) error {
+	var ops []map[string]string
+	for _, label := range labels {
+		ops = append(ops, map[string]string{"add": label})
+	}
+	req := map[string]interface{}{
+		"update": map[string]interface{}{"labels": ops},
+	}
+	return c.query(ctx, http.MethodPut, "/rest/api/2/issue/"+key, nil, req, nil)
+}
+
+func (c *Client) AddAttachment(ctx context.Context, key, fileName string, data []byte) error {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%v/rest/api/2/issue/%v/attachments", c.url, key), body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	// Jira requires the header for multipart uploads as an XSRF protection.
+	r.Header.Set("X-Atlassian-Token", "no-check")
+	return c.do(r, nil)
+}
+
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Transitions returns the workflow transitions available for the issue in its current status.
+func (c *Client) Transitions(ctx context.Context, key string) ([]*Transition, error) {
+	resp := new(struct {
+		Transitions []*Transition `json:"transitions"`
+	})
+	err := c.query(ctx, http.MethodGet, fmt.Sprintf("/rest/api/2/issue/%v/transitions", key), nil, nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transitions, nil
+}
+
+// DoTransition moves the issue along the workflow transition, resolution and comment are optional.
+func (c *Client) DoTransition(ctx context.Context, key, transitionID, resolution, comment string) error {
+	req := map[string]interface{}{
+		"transition": map[string]string{"id": transitionID},
+	}
+	if resolution != "" {
+		req["fields"] = map[string]interface{}{
+			"resolution": map[string]string{"name": resolution},
+		}
+	}
+	if comment != "" {
+		req["update"] = map[string]interface{}{
+			"comment": []interface{}{map[string]interface{}{"add": map[string]string{"body": comment}}},
+		}
+	}
+	return c.query(ctx, http.MethodPost, fmt.Sprintf("/rest/api/2/issue/%v/transitions", key), nil, req, nil)
+}
+
+const searchPageSize = 100
+
+// Search returns all issues matching the JQL query.
+func (c *Client) Search(ctx context.Context, jql string) ([]*Issue, error) {
+	args := url.Values{
+		"jql":        {jql},
+		"fields":     {issueFields},
+		"maxResults": {strconv.Itoa(searchPageSize)},
+	}
+	var ret []*Issue
+	for {
+		args.Set("startAt", strconv.Itoa(len(ret)))
+		resp := new(struct {
+			Total  int      `json:"total"`
+			Issues []*Issue `json:"issues"`
+		})
+		if err := c.query(ctx, http.MethodGet, "/rest/api/2/search", args, nil, resp); err != nil {
+			return nil, err
+		}
+		ret = append(ret, resp.Issues...)
+		if len(resp.Issues) == 0 || len(ret) >= resp.Total {
+			return ret, nil
+		}
+	}
+}
+
+// Error is an error reported by Jira.
+type Error struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("jira error %v: %v", err.StatusCode, strings.Join(err.Messages, "; "))
+}
+
+func (c *Client) query(ctx context.Context, method, path string, args url.Values, req, reply interface{}) error {
+	addr := c.url + path
+	if len(args) != 0 {
+		addr += "?" + args.Encode()
+	}
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, addr, body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(r, reply)
+}
+
+func (c *Client) do(r *http.Request, reply interface{}) error {
+	r.Header.Set("Accept", "application/json")
+	if c.user != "" {
+		r.SetBasicAuth(c.user, c.token)
+	} else if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("jira %v %v failed: %w", r.Method, r.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read jira reply: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		jiraErr := &Error{StatusCode: resp.StatusCode}
+		msg := new(struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		})
+		if json.Unmarshal(data, msg) == nil {
+			jiraErr.Messages = msg.ErrorMessages
+			var fields []string
+			for field, text := range msg.Errors {
+				fields = append(fields, fmt.Sprintf("%v: %v", field, text))
+			}
+			sort.Strings(fields)
+			jiraErr.Messages = append(jiraErr.Messages, fields...)
+		}
+		if len(jiraErr.Messages) == 0 {
+			jiraErr.Messages = []string{fmt.Sprintf("%q", data)}
+		}
+		return jiraErr
+	}
+	if reply != nil && len(data) != 0 {
+		if err := json.Unmarshal(data, reply); err != nil {
+			return fmt.Errorf("failed to unmarshal jira reply: %w", err)
+		}
+	}
+	return nil
+}
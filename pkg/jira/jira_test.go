@@ -0,0 +1,161 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  string
+		Body   map[string]interface{}
+	}
+	var requests []request
+	var attachment string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "token" {
+			t.Errorf("bad authorization %q", r.Header.Get("Authorization"))
+		}
+		req := request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query().Get("startAt")}
+		if r.URL.Path == "/rest/api/2/issue/KERN-1/attachments" {
+			if r.Header.Get("X-Atlassian-Token") != "no-check" {
+				t.Errorf("no XSRF header")
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("bad attachment: %v", err)
+			} else {
+				data, _ := io.ReadAll(file)
+				attachment = string(data)
+			}
+		} else if data, _ := io.ReadAll(r.Body); len(data) != 0 {
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				t.Errorf("bad request body: %v", err)
+			}
+		}
+		requests = append(requests, req)
+		switch r.URL.Path {
+		case "/rest/api/2/issue":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": "10000", "key": "KERN-1"}`)
+		case "/rest/api/2/issue/KERN-1/attachments":
+			fmt.Fprint(w, `[{"id": "1"}]`)
+		case "/rest/api/2/search":
+			if r.URL.Query().Get("startAt") == "0" {
+				fmt.Fprint(w, `{"total": 2, "issues": [{"key": "KERN-1", "fields": {"labels": ["syzbot"],
+					"status": {"name": "Closed", "statusCategory": {"key": "done"}},
+					"resolution": {"name": "Duplicate"},
+					"issuelinks": [{"type": {"name": "Duplicate"}, "outwardIssue": {"key": "KERN-2"}}]}}]}`)
+			} else {
+				fmt.Fprint(w, `{"total": 2, "issues": [{"key": "KERN-2", "fields": {"resolution": null}}]}`)
+			}
+		case "/rest/api/2/issue/KERN-1/transitions":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errorMessages": ["bad"], "errors": {"summary": "required"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewClient(srv.URL, "user", "token")
+	key, err := client.CreateIssue(ctx, map[string]interface{}{
+		"project": map[string]string{"key": "KERN"},
+		"summary": "summary",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "KERN-1" {
+		t.Fatalf("bad issue key %q", key)
+	}
+	if err := client.AddAttachment(ctx, key, "repro.c", []byte("int main() {}")); err != nil {
+		t.Fatal(err)
+	}
+	if attachment != "int main() {}" {
+		t.Fatalf("bad attachment %q", attachment)
+	}
+	issues, err := client.Search(ctx, "labels = syzbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || issues[0].Fields.Status.Category.Key != StatusCategoryDone ||
+		issues[0].Fields.Resolution.Name != "Duplicate" ||
+		issues[0].Fields.IssueLinks[0].OutwardIssue.Key != "KERN-2" ||
+		issues[1].Fields.Resolution != nil {
+		t.Fatalf("bad issues: %+v %+v", issues[0], issues[1])
+	}
+	if err := client.DoTransition(ctx, key, "31", "Won't Do", "comment"); err != nil {
+		t.Fatal(err)
+	}
+	err = client.AddComment(ctx, "KERN-3", "comment")
+	var jiraErr *Error
+	if !errors.As(err, &jiraErr) || jiraErr.StatusCode != http.StatusBadRequest ||
+		jiraErr.Error() != "jira error 400: bad; summary: required" {
+		t.Fatalf("bad error: %v", err)
+	}
+
+	want := []request{
+		{
+			Method: "POST",
+			Path:   "/rest/api/2/issue",
+			Body: map[string]interface{}{
+				"fields": map[string]interface{}{
+					"project": map[string]interface{}{"key": "KERN"},
+					"summary": "summary",
+				},
+			},
+		},
+		{
+			Method: "POST",
+			Path:   "/rest/api/2/issue/KERN-1/attachments",
+		},
+		{
+			Method: "GET",
+			Path:   "/rest/api/2/search",
+			Query:  "0",
+		},
+		{
+			Method: "GET",
+			Path:   "/rest/api/2/search",
+			Query:  "1",
+		},
+		{
+			Method: "POST",
+			Path:   "/rest/api/2/issue/KERN-1/transitions",
+			Body: map[string]interface{}{
+				"transition": map[string]interface{}{"id": "31"},
+				"fields": map[string]interface{}{
+					"resolution": map[string]interface{}{"name": "Won't Do"},
+				},
+				"update": map[string]interface{}{
+					"comment": []interface{}{
+						map[string]interface{}{"add": map[string]interface{}{"body": "comment"}},
+					},
+				},
+			},
+		},
+		{
+			Method: "POST",
+			Path:   "/rest/api/2/issue/KERN-3/comment",
+			Body:   map[string]interface{}{"body": "comment"},
+		},
+	}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Fatal(diff)
+	}
+}
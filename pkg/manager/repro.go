@@ -29,6 +29,8 @@ type Crash struct {
 	FromHub       bool // this crash was created based on a repro from syz-hub
 	FromDashboard bool // .. or from dashboard
 	Manual        bool
+	ReproTaskID   string // set for tasks leased with dashapi.ReproTaskPoll
+	DashboardID   int64  // set if the crash was saved on the dashboard, see dashapi.UpdateCrash
 	*report.Report
 }
 
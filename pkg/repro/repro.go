@@ -42,6 +42,8 @@ type Stats struct {
 	SimplifyProgTime time.Duration
 	ExtractCTime     time.Duration
 	SimplifyCTime    time.Duration
+	TotalTime        time.Duration
+	Runs             int // number of times programs were run in VMs
 }
 
 type reproContext struct {
@@ -59,8 +61,22 @@ type reproContext struct {
 	report         *report.Report
 	timeouts       targets.Timeouts
 	observedTitles map[string]bool
+	progress       ProgressFunc
 }
 
+// Reproduction phases passed to ProgressFunc.
+const (
+	PhaseExtractProg  = "extract_prog"
+	PhaseMinimizeProg = "minimize_prog"
+	PhaseExtractC     = "extract_c"
+	PhaseSimplifyProg = "simplify_prog"
+	PhaseSimplifyC    = "simplify_c"
+)
+
+// ProgressFunc is called when the reproduction enters the next phase,
+// p is the current program (nil during PhaseExtractProg).
+type ProgressFunc func(phase string, p *prog.Prog)
+
 // execInterface describes the interfaces needed by pkg/repro.
 type execInterface interface {
 	RunCProg(p *prog.Prog, duration time.Duration, opts csource.Options) (*instance.RunResult, error)
@@ -70,6 +86,12 @@ type execInterface interface {
 
 func Run(crashLog []byte, cfg *mgrconfig.Config, features flatrpc.Feature, reporter *report.Reporter,
 	pool *dispatcher.Pool[*vm.Instance]) (*Result, *Stats, error) {
+	return RunWithProgress(crashLog, cfg, features, reporter, pool, nil)
+}
+
+// RunWithProgress is like Run, but also reports the reproduction phases to progress.
+func RunWithProgress(crashLog []byte, cfg *mgrconfig.Config, features flatrpc.Feature, reporter *report.Reporter,
+	pool *dispatcher.Pool[*vm.Instance], progress ProgressFunc) (*Result, *Stats, error) {
 	exec := &poolWrapper{
 		cfg:      cfg,
 		reporter: reporter,
@@ -80,6 +102,7 @@ func Run(crashLog []byte, cfg *mgrconfig.Config, features flatrpc.Feature, repor
 		return nil, nil, err
 	}
 	exec.logf = ctx.reproLogf
+	ctx.progress = progress
 	return ctx.run()
 }
 
@@ -212,9 +235,11 @@ func (ctx *reproContext) repro() (*Result, error) {
 
 	reproStart := time.Now()
 	defer func() {
-		ctx.reproLogf(3, "reproducing took %s", time.Since(reproStart))
+		ctx.stats.TotalTime = time.Since(reproStart)
+		ctx.reproLogf(3, "reproducing took %s", ctx.stats.TotalTime)
 	}()
 
+	ctx.reportProgress(PhaseExtractProg, nil)
 	res, err := ctx.extractProg(ctx.entries)
 	if err != nil {
 		return nil, err
@@ -222,12 +247,14 @@ func (ctx *reproContext) repro() (*Result, error) {
 	if res == nil {
 		return nil, nil
 	}
+	ctx.reportProgress(PhaseMinimizeProg, res)
 	res, err = ctx.minimizeProg(res)
 	if err != nil {
 		return nil, err
 	}
 
 	// Try extracting C repro without simplifying options first.
+	ctx.reportProgress(PhaseExtractC, res)
 	res, err = ctx.extractC(res)
 	if err != nil {
 		return nil, err
@@ -235,6 +262,7 @@ func (ctx *reproContext) repro() (*Result, error) {
 
 	// Simplify options and try extracting C repro.
 	if !res.CRepro {
+		ctx.reportProgress(PhaseSimplifyProg, res)
 		res, err = ctx.simplifyProg(res)
 		if err != nil {
 			return nil, err
@@ -243,6 +271,7 @@ func (ctx *reproContext) repro() (*Result, error) {
 
 	// Simplify C related options.
 	if res.CRepro {
+		ctx.reportProgress(PhaseSimplifyC, res)
 		res, err = ctx.simplifyC(res)
 		if err != nil {
 			return nil, err
@@ -252,6 +281,17 @@ func (ctx *reproContext) repro() (*Result, error) {
 	return res, nil
 }
 
+func (ctx *reproContext) reportProgress(phase string, res *Result) {
+	if ctx.progress == nil {
+		return
+	}
+	var p *prog.Prog
+	if res != nil {
+		p = res.Prog
+	}
+	ctx.progress(phase, p)
+}
+
 func (ctx *reproContext) extractProg(entries []*prog.LogEntry) (*Result, error) {
 	ctx.reproLogf(2, "extracting reproducer from %v programs", len(entries))
 	start := time.Now()
@@ -598,6 +638,7 @@ func (ctx *reproContext) getVerdict(callback func() (rep *instance.RunResult, er
 		// If the problem is permanent, it will just be the same.
 		result, err = callback()
 		if err == nil {
+			ctx.stats.Runs++
 			break
 		}
 	}
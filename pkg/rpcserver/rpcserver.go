@@ -66,6 +66,9 @@ type Server interface {
 	ShutdownInstance(id int, crashed bool, extraExecs ...report.ExecutorInfo) ([]ExecRecord, []byte)
 	StopFuzzing(id int)
 	DistributeSignalDelta(plus signal.Signal)
+	// DisabledSyscalls returns syscalls disabled by the machine check along with the reasons,
+	// it can be called once Manager.MachineChecked was called.
+	DisabledSyscalls() map[*prog.Syscall]string
 }
 
 type server struct {
@@ -82,6 +85,7 @@ type server struct {
 	checkFailures    int
 	baseSource       *queue.DynamicSourceCtl
 	setupFeatures    flatrpc.Feature
+	disabledCalls    map[*prog.Syscall]string
 	canonicalModules *cover.Canonicalizer
 	coverFilter      []uint64
 
@@ -346,6 +350,13 @@ func (serv *server) runCheck(checkFilesInfo []*flatrpc.FileInfo, checkFeatureInf
 	}
 	enabledFeatures := features.Enabled()
 	serv.setupFeatures = features.NeedSetup()
+	serv.disabledCalls = make(map[*prog.Syscall]string)
+	// Calls that are disabled directly get the direct reason.
+	for _, calls := range []map[*prog.Syscall]string{transitivelyDisabled, disabledCalls} {
+		for call, reason := range calls {
+			serv.disabledCalls[call] = reason
+		}
+	}
 	newSource := serv.mgr.MachineChecked(enabledFeatures, enabledCalls)
 	serv.baseSource.Store(newSource)
 	serv.checkDone.Store(true)
@@ -460,6 +471,10 @@ func (serv *server) DistributeSignalDelta(plus signal.Signal) {
 	})
 }
 
+func (serv *server) DisabledSyscalls() map[*prog.Syscall]string {
+	return serv.disabledCalls
+}
+
 func (serv *server) TriagedCorpus() {
 	serv.triagedCorpus.Store(true)
 	serv.foreachRunnerAsync(func(runner *Runner) {
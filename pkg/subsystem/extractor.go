@@ -127,6 +127,16 @@ func (e *Extractor) TracedExtract(crashes []*Crash, tracer debugtracer.DebugTrac
 	return removeParents(afterVoting)
 }
 
+// PathMaintainers returns the emails of the most specific subsystems the source file belongs to,
+// similar to what get_maintainer.pl reports for the file.
+func (e *Extractor) PathMaintainers(path string) []string {
+	var ret []string
+	for _, item := range removeParents(e.raw.FromPath(path)) {
+		ret = append(ret, item.Emails()...)
+	}
+	return ret
+}
+
 func (e *Extractor) readableSubsystems(list []*Subsystem) string {
 	var names []string
 	for _, item := range list {
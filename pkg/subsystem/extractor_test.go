@@ -122,6 +122,30 @@ func TestExtractor(t *testing.T) {
 	}
 }
 
+func TestPathMaintainers(t *testing.T) {
+	fs := &Subsystem{Name: "fs", Lists: []string{"fs@list.com"}, Maintainers: []string{"fs@person.com"}}
+	ext := &Subsystem{
+		Name:        "ext",
+		Parents:     []*Subsystem{fs},
+		Lists:       []string{"ext@list.com"},
+		Maintainers: []string{"ext@person.com"},
+	}
+	extractor := &Extractor{
+		raw: &testRawExtractor{
+			perPath: map[string][]*Subsystem{
+				"fs/ext4/inode.c": {fs, ext},
+				"fs/inode.c":      {fs},
+			},
+		},
+	}
+	// Parents only contribute their lists.
+	assert.ElementsMatch(t, extractor.PathMaintainers("fs/ext4/inode.c"),
+		[]string{"ext@list.com", "ext@person.com", "fs@list.com"})
+	assert.ElementsMatch(t, extractor.PathMaintainers("fs/inode.c"),
+		[]string{"fs@list.com", "fs@person.com"})
+	assert.Empty(t, extractor.PathMaintainers("mm/memory.c"))
+}
+
 type testRawExtractor struct {
 	perPath map[string][]*Subsystem
 	perProg []progSubsystems
@@ -0,0 +1,112 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package webhook delivers JSON events to webhooks.
+// Every request has the event type in EventHeader, a unique delivery ID in DeliveryHeader
+// (retried deliveries have the same ID) and the HMAC-SHA256 of the body in SignatureHeader,
+// receivers check the signature with Verify.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Syzbot-Event"
+	DeliveryHeader  = "X-Syzbot-Delivery"
+	SignatureHeader = "X-Syzbot-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+type Sender struct {
+	url    string
+	secret []byte
+	client *http.Client
+	// Failed deliveries are retried Retries times, the delay starts at Backoff and doubles every time.
+	// Only network errors, 5xx and 429 replies are retried.
+	Retries int
+	Backoff time.Duration
+}
+
+func NewSender(url string, secret []byte) *Sender {
+	return &Sender{
+		url:     url,
+		secret:  secret,
+		client:  &http.Client{Timeout: time.Minute},
+		Retries: 5,
+		Backoff: 10 * time.Second,
+	}
+}
+
+// Send delivers the payload marshaled as JSON.
+// The delivery ID is meant to let receivers deduplicate events.
+func (s *Sender) Send(ctx context.Context, event, delivery string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	backoff := s.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.send(ctx, event, delivery, body)
+		if err == nil || !retry || attempt == s.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (s *Sender) send(ctx context.Context, event, delivery string, body []byte) (bool, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(EventHeader, event)
+	r.Header.Set(DeliveryHeader, delivery)
+	r.Header.Set(SignatureHeader, Sign(s.secret, body))
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook replied %v: %q", resp.Status, reply)
+}
+
+// Sign returns the value of SignatureHeader for the body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the signature (SignatureHeader) matches the body.
+func Verify(secret, body []byte, signature string) bool {
+	sum, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	secret := []byte("secret")
+	var statuses []int
+	var deliveries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(secret, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("bad signature %q", r.Header.Get(SignatureHeader))
+		}
+		if string(body) != `{"a":1}` || r.Header.Get(EventHeader) != "event" {
+			t.Errorf("bad request: %q %q", body, r.Header.Get(EventHeader))
+		}
+		deliveries = append(deliveries, r.Header.Get(DeliveryHeader))
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer srv.Close()
+
+	sender := NewSender(srv.URL, secret)
+	sender.Backoff = time.Millisecond
+	sender.Retries = 2
+	payload := map[string]int{"a": 1}
+
+	// Server errors are retried.
+	statuses = []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK}
+	if err := sender.Send(context.Background(), "event", "id1", payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 3 || deliveries[0] != "id1" || deliveries[2] != "id1" {
+		t.Fatalf("bad deliveries: %q", deliveries)
+	}
+	// Until the retries run out.
+	statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}
+	if err := sender.Send(context.Background(), "event", "id2", payload); err == nil {
+		t.Fatal("no error")
+	}
+	if len(statuses) != 0 {
+		t.Fatalf("not all retries were made")
+	}
+	// Client errors are not.
+	deliveries = nil
+	statuses = []int{http.StatusBadRequest}
+	if err := sender.Send(context.Background(), "event", "id3", payload); err == nil {
+		t.Fatal("no error")
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("bad deliveries: %q", deliveries)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret, body := []byte("secret"), []byte("body")
+	sig := Sign(secret, body)
+	if !Verify(secret, body, sig) {
+		t.Fatalf("signature %q does not verify", sig)
+	}
+	for _, bad := range []string{"", sig[len(signaturePrefix):], sig[:len(sig)-1] + "0", "sha256=zz"} {
+		if Verify(secret, body, bad) {
+			t.Errorf("signature %q verifies", bad)
+		}
+	}
+	if Verify([]byte("other"), body, sig) || Verify(secret, []byte("other"), sig) {
+		t.Errorf("signature verifies with other data")
+	}
+}
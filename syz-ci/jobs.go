@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -47,7 +48,8 @@ type JobProcessor struct {
 }
 
 func newJobManager(cfg *Config, managers []*Manager, shutdownPending chan struct{}) (*JobManager, error) {
-	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	// Job builds mostly use the same kernel configs as the manager builds.
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey, dashapi.BlobDedup{})
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +83,7 @@ func (jm *JobManager) startLoop(wg *sync.WaitGroup) func() {
 func (jm *JobManager) loop(stop chan struct{}) {
 	if err := jm.resetJobs(); err != nil {
 		if jm.dash != nil {
-			jm.dash.LogError("syz-ci", "reset jobs failed: %v", err)
+			jm.dash.LogError(context.Background(), "syz-ci", "reset jobs failed: %v", err)
 		}
 		return
 	}
@@ -138,7 +140,7 @@ func (jm *JobManager) resetJobs() error {
 		}
 	}
 	if len(managerNames) > 0 {
-		return jm.dash.JobReset(&dashapi.JobResetReq{Managers: managerNames})
+		return jm.dash.JobReset(context.Background(), &dashapi.JobResetReq{Managers: managerNames})
 	}
 	return nil
 }
@@ -191,7 +193,7 @@ func brokenRepo(url string) bool {
 }
 
 func (jp *JobProcessor) pollManagerCommits(mgr *Manager) error {
-	resp, err := mgr.dash.CommitPoll()
+	resp, err := mgr.dash.CommitPoll(context.Background())
 	if err != nil {
 		return err
 	}
@@ -249,7 +251,7 @@ func (jp *JobProcessor) pollManagerCommits(mgr *Manager) error {
 			Date:   com.Date,
 		})
 	}
-	return mgr.dash.UploadCommits(results)
+	return mgr.dash.UploadCommits(context.Background(), results)
 }
 
 func (jp *JobProcessor) pollRepo(mgr *Manager, URL, branch, reportEmail string) ([]*vcs.Commit, error) {
@@ -304,7 +306,7 @@ func (jp *JobProcessor) pollJobs() {
 	if len(poll.Managers) == 0 {
 		return
 	}
-	req, err := jp.dash.JobPoll(poll)
+	req, err := jp.dash.JobPoll(context.Background(), poll)
 	if err != nil {
 		jp.Errorf("failed to poll jobs: %v", err)
 		return
@@ -346,7 +348,7 @@ func (jp *JobProcessor) processJob(job *Job) {
 		}
 	default:
 	}
-	if err := jp.dash.JobDone(resp); err != nil {
+	if err := jp.dash.JobDone(context.Background(), resp); err != nil {
 		jp.Errorf("failed to mark job as done: %v", err)
 		return
 	}
@@ -540,6 +542,7 @@ func (jp *JobProcessor) bisect(job *Job, mgrcfg *mgrconfig.Config) error {
 			Date:       com.Date,
 		})
 	}
+	resp.Confidence = res.Confidence
 	if len(res.Commits) == 1 {
 		if len(res.Commits[0].Parents) > 1 {
 			resp.Flags |= dashapi.BisectResultMerge
@@ -811,6 +814,6 @@ func (jp *JobProcessor) Logf(level int, msg string, args ...interface{}) {
 func (jp *JobProcessor) Errorf(msg string, args ...interface{}) {
 	log.Errorf("job: "+msg, args...)
 	if jp.dash != nil {
-		jp.dash.LogError(jp.name, msg, args...)
+		jp.dash.LogError(context.Background(), jp.name, msg, args...)
 	}
 }
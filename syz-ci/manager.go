@@ -96,12 +96,13 @@ type Manager struct {
 }
 
 type ManagerDashapi interface {
-	ReportBuildError(req *dashapi.BuildErrorReq) error
-	UploadBuild(build *dashapi.Build) error
-	BuilderPoll(manager string) (*dashapi.BuilderPollResp, error)
-	LogError(name, msg string, args ...interface{})
-	CommitPoll() (*dashapi.CommitPollResp, error)
-	UploadCommits(commits []dashapi.Commit) error
+	ReportBuildError(ctx context.Context, req *dashapi.BuildErrorReq) error
+	UploadBuild(ctx context.Context, build *dashapi.Build) error
+	BuilderPoll(ctx context.Context, manager string) (*dashapi.BuilderPollResp, error)
+	UploadBuildCommits(ctx context.Context, req *dashapi.BuildCommitsReq) error
+	LogError(ctx context.Context, name, msg string, args ...interface{})
+	CommitPoll(ctx context.Context) (*dashapi.CommitPollResp, error)
+	UploadCommits(ctx context.Context, commits []dashapi.Commit) error
 }
 
 func createManager(cfg *Config, mgrcfg *ManagerConfig, stop chan struct{},
@@ -117,7 +118,8 @@ func createManager(cfg *Config, mgrcfg *ManagerConfig, stop chan struct{},
 
 	var dash *dashapi.Dashboard
 	if cfg.DashboardAddr != "" && mgrcfg.DashboardClient != "" {
-		dash, err = dashapi.New(mgrcfg.DashboardClient, cfg.DashboardAddr, mgrcfg.DashboardKey)
+		dash, err = dashapi.New(mgrcfg.DashboardClient, cfg.DashboardAddr, mgrcfg.DashboardKey,
+			dashapi.BlobDedup{})
 		if err != nil {
 			return nil, err
 		}
@@ -269,6 +271,9 @@ func (mgr *Manager) pollAndBuild(lastCommit string, latestInfo *BuildInfo) (
 			commit.Hash != latestInfo.KernelCommit ||
 			mgr.configTag != latestInfo.KernelConfigTag)
 		mgr.buildFailed = needsUpdate
+		if !needsUpdate {
+			mgr.uploadBuildCommits()
+		}
 		if commit.Hash != lastCommit && needsUpdate {
 			lastCommit = commit.Hash
 			select {
@@ -556,7 +561,7 @@ func (mgr *Manager) reportBuildError(rep *report.Report, info *BuildInfo, imageD
 	if rep.GuiltyFile != "" {
 		req.Crash.GuiltyFiles = []string{rep.GuiltyFile}
 	}
-	if err := mgr.dash.ReportBuildError(req); err != nil {
+	if err := mgr.dash.ReportBuildError(context.Background(), req); err != nil {
 		return err
 	}
 	return nil
@@ -660,12 +665,37 @@ func (mgr *Manager) uploadBuild(info *BuildInfo, imageDir string) (string, error
 		}
 		build.Assets = assets
 	}
-	if err := mgr.dash.UploadBuild(build); err != nil {
+	if err := mgr.dash.UploadBuild(context.Background(), build); err != nil {
 		return "", err
 	}
 	return build.ID, nil
 }
 
+// uploadBuildCommits reports fixing commits that are present in the current build,
+// but were not known to the dashboard when the build was uploaded.
+func (mgr *Manager) uploadBuildCommits() {
+	if mgr.dash == nil || mgr.lastBuild == nil {
+		return
+	}
+	commitTitles, fixCommits, err := mgr.pollCommits(mgr.lastBuild.KernelCommit)
+	if err != nil {
+		mgr.Errorf("failed to poll commits: %v", err)
+		return
+	}
+	if len(commitTitles) == 0 && len(fixCommits) == 0 {
+		return
+	}
+	err = mgr.dash.UploadBuildCommits(context.Background(), &dashapi.BuildCommitsReq{
+		Manager:    mgr.name,
+		BuildID:    mgr.lastBuild.ID,
+		Commits:    commitTitles,
+		FixCommits: fixCommits,
+	})
+	if err != nil {
+		mgr.Errorf("failed to upload build commits: %v", err)
+	}
+}
+
 func (mgr *Manager) createDashboardBuild(info *BuildInfo, imageDir, typ string) (*dashapi.Build, error) {
 	var kernelConfig []byte
 	if kernelConfigFile := filepath.Join(imageDir, "kernel.config"); osutil.IsExist(kernelConfigFile) {
@@ -703,7 +733,7 @@ func (mgr *Manager) createDashboardBuild(info *BuildInfo, imageDir, typ string)
 // open bugs) and returns subset of these commits that are present in a build
 // on commit buildCommit.
 func (mgr *Manager) pollCommits(buildCommit string) ([]string, []dashapi.Commit, error) {
-	resp, err := mgr.dash.BuilderPoll(mgr.name)
+	resp, err := mgr.dash.BuilderPoll(context.Background(), mgr.name)
 	if err != nil || len(resp.PendingCommits) == 0 && resp.ReportEmail == "" {
 		return nil, nil, err
 	}
@@ -818,7 +848,8 @@ func (mgr *Manager) uploadBuildAssets(buildInfo *dashapi.Build, assetFolder stri
 		}
 		extra := &asset.ExtraUploadArg{SkipIfExists: true}
 		hash := sha256.New()
-		if _, err := io.Copy(hash, file); err != nil {
+		size, err := io.Copy(hash, file)
+		if err != nil {
 			log.Logf(0, "failed calculate hash for the asset %s: %s", pendingAsset.path, err)
 			continue
 		}
@@ -837,6 +868,10 @@ func (mgr *Manager) uploadBuildAssets(buildInfo *dashapi.Build, assetFolder stri
 		} else if mgr.debugStorage {
 			log.Logf(0, "uploaded an asset: %#v", info)
 		}
+		if info.SHA256 == "" {
+			// The file already existed and was not uploaded again.
+			info.Size, info.SHA256 = size, extra.UniqueTag
+		}
 		ret = append(ret, info)
 	}
 	return ret, nil
@@ -1053,7 +1088,7 @@ func uploadFileHTTPPut(URL string, file io.Reader) error {
 func (mgr *Manager) Errorf(msg string, args ...interface{}) {
 	log.Errorf(mgr.name+": "+msg, args...)
 	if mgr.dash != nil {
-		mgr.dash.LogError(mgr.name, msg, args...)
+		mgr.dash.LogError(context.Background(), mgr.name, msg, args...)
 	}
 }
 
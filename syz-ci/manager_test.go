@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -18,17 +19,24 @@ type dashapiMock struct {
 	mock.Mock
 }
 
-func (dm *dashapiMock) BuilderPoll(manager string) (*dashapi.BuilderPollResp, error) {
+func (dm *dashapiMock) BuilderPoll(ctx context.Context, manager string) (*dashapi.BuilderPollResp, error) {
 	args := dm.Called(manager)
 	return args.Get(0).(*dashapi.BuilderPollResp), args.Error(1)
 }
 
 // We don't care about the methods below for now.
-func (dm *dashapiMock) ReportBuildError(req *dashapi.BuildErrorReq) error { return nil }
-func (dm *dashapiMock) UploadBuild(build *dashapi.Build) error            { return nil }
-func (dm *dashapiMock) LogError(name, msg string, args ...interface{})    {}
-func (dm *dashapiMock) CommitPoll() (*dashapi.CommitPollResp, error)      { return nil, nil }
-func (dm *dashapiMock) UploadCommits(commits []dashapi.Commit) error      { return nil }
+func (dm *dashapiMock) ReportBuildError(ctx context.Context, req *dashapi.BuildErrorReq) error {
+	return nil
+}
+func (dm *dashapiMock) UploadBuild(ctx context.Context, build *dashapi.Build) error         { return nil }
+func (dm *dashapiMock) LogError(ctx context.Context, name, msg string, args ...interface{}) {}
+func (dm *dashapiMock) UploadBuildCommits(ctx context.Context, req *dashapi.BuildCommitsReq) error {
+	return nil
+}
+func (dm *dashapiMock) CommitPoll(ctx context.Context) (*dashapi.CommitPollResp, error) {
+	return nil, nil
+}
+func (dm *dashapiMock) UploadCommits(ctx context.Context, commits []dashapi.Commit) error { return nil }
 
 func TestManagerPollCommits(t *testing.T) {
 	// Mock a repository.
@@ -0,0 +1,115 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+// The dashboard has the authoritative list of kernel trees, while syz-ci configs duplicate it.
+// Here we periodically compare the local manager configs with the dashboard list
+// and warn about mismatches (or adopt the dashboard repos/branches if adopt_dashboard_repos is set).
+
+type repoChecker struct {
+	cfg *Config
+	// Managers grouped by the dashboard client, since repos are per-namespace.
+	dashes   map[string]*dashapi.Dashboard
+	managers map[string][]*ManagerConfig
+}
+
+func newRepoChecker(cfg *Config) *repoChecker {
+	rc := &repoChecker{
+		cfg:      cfg,
+		dashes:   make(map[string]*dashapi.Dashboard),
+		managers: make(map[string][]*ManagerConfig),
+	}
+	if cfg.DashboardAddr == "" {
+		return rc
+	}
+	for _, mgrcfg := range cfg.Managers {
+		if mgrcfg.DashboardClient == "" {
+			continue
+		}
+		if rc.dashes[mgrcfg.DashboardClient] == nil {
+			dash, err := dashapi.New(mgrcfg.DashboardClient, cfg.DashboardAddr, mgrcfg.DashboardKey)
+			if err != nil {
+				log.Errorf("failed to create dashapi connection for %v: %v", mgrcfg.Name, err)
+				continue
+			}
+			rc.dashes[mgrcfg.DashboardClient] = dash
+		}
+		rc.managers[mgrcfg.DashboardClient] = append(rc.managers[mgrcfg.DashboardClient], mgrcfg)
+	}
+	return rc
+}
+
+// check polls the dashboard repos and reconciles them with the manager configs.
+// Repos are adopted only if adopt is set, managers must not be running at that point.
+func (rc *repoChecker) check(adopt bool) {
+	for client, dash := range rc.dashes {
+		resp, err := dash.ReposPoll(context.Background())
+		if err != nil {
+			log.Errorf("failed to poll dashboard repos for %v: %v", client, err)
+			continue
+		}
+		for _, mgrcfg := range rc.managers[client] {
+			checkManagerRepo(mgrcfg, resp.Repos, adopt)
+		}
+	}
+}
+
+func (rc *repoChecker) loop(stop chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-time.After(24 * time.Hour):
+			rc.check(false)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func checkManagerRepo(mgrcfg *ManagerConfig, trees []*dashapi.KernelTree, adopt bool) {
+	var tree *dashapi.KernelTree
+	for _, t := range trees {
+		if mgrcfg.RepoAlias != "" && t.Alias == mgrcfg.RepoAlias ||
+			mgrcfg.RepoAlias == "" && t.URL == mgrcfg.Repo && t.Branch == mgrcfg.Branch {
+			tree = t
+			break
+		}
+	}
+	if tree == nil {
+		log.Logf(0, "%v: repo %v %v (alias %q) is not known to the dashboard",
+			mgrcfg.Name, mgrcfg.Repo, mgrcfg.Branch, mgrcfg.RepoAlias)
+		return
+	}
+	if tree.URL != mgrcfg.Repo || tree.Branch != mgrcfg.Branch {
+		if adopt {
+			log.Logf(0, "%v: adopting dashboard repo %v %v instead of %v %v",
+				mgrcfg.Name, tree.URL, tree.Branch, mgrcfg.Repo, mgrcfg.Branch)
+			mgrcfg.Repo, mgrcfg.Branch = tree.URL, tree.Branch
+		} else {
+			log.Logf(0, "%v: repo %v %v differs from the dashboard repo %v %v for %v",
+				mgrcfg.Name, mgrcfg.Repo, mgrcfg.Branch, tree.URL, tree.Branch, tree.Alias)
+		}
+	}
+	if mgrcfg.RepoAlias == "" && adopt {
+		mgrcfg.RepoAlias = tree.Alias
+	}
+	if mgrcfg.managercfg == nil {
+		return
+	}
+	if tree.OS != "" && tree.OS != mgrcfg.managercfg.TargetOS ||
+		tree.Arch != "" && tree.Arch != mgrcfg.managercfg.TargetArch {
+		log.Logf(0, "%v: target %v/%v does not match the dashboard tree %v target %v/%v",
+			mgrcfg.Name, mgrcfg.managercfg.TargetOS, mgrcfg.managercfg.TargetArch,
+			tree.Alias, tree.OS, tree.Arch)
+	}
+}
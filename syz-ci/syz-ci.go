@@ -139,6 +139,9 @@ type Config struct {
 	ParallelJobs bool `json:"parallel_jobs"`
 	// Poll period for commits in seconds (optional, defaults to 3600 seconds)
 	CommitPollPeriod int `json:"commit_poll_period"`
+	// Take manager repos and branches from the dashboard list of kernel trees (matched by repo_alias).
+	// Otherwise, mismatches with the dashboard list are only logged.
+	AdoptDashboardRepos bool `json:"adopt_dashboard_repos"`
 	// Asset Storage config.
 	AssetStorage *asset.Config `json:"asset_storage"`
 	// Per-vm type JSON diffs that will be applied to every instace of the
@@ -272,6 +275,9 @@ func main() {
 		}()
 	}
 
+	repos := newRepoChecker(cfg)
+	repos.check(cfg.AdoptDashboardRepos)
+
 	stop := make(chan struct{})
 	var managers []*Manager
 	for _, mgrcfg := range cfg.Managers {
@@ -315,6 +321,8 @@ func main() {
 
 	wg.Add(1)
 	go deprecateAssets(cfg, stop, &wg)
+	wg.Add(1)
+	go repos.loop(stop, &wg)
 
 	select {
 	case <-shutdownPending:
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -333,7 +334,7 @@ func (upd *SyzUpdater) uploadBuildError(commit *vcs.Commit, buildErr error) {
 				Log:   output,
 			},
 		}
-		if err := dash.ReportBuildError(req); err != nil {
+		if err := dash.ReportBuildError(context.Background(), req); err != nil {
 			// TODO: log ReportBuildError error to dashboard.
 			log.Logf(0, "failed to report build error for %v: %v", mgrcfg.Name, err)
 		}
@@ -11,11 +11,14 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"math/rand"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -32,6 +35,7 @@ import (
 	"github.com/google/syzkaller/pkg/fuzzer/queue"
 	"github.com/google/syzkaller/pkg/gce"
 	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/instance"
 	"github.com/google/syzkaller/pkg/log"
 	"github.com/google/syzkaller/pkg/manager"
 	"github.com/google/syzkaller/pkg/mgrconfig"
@@ -43,11 +47,14 @@ import (
 	"github.com/google/syzkaller/pkg/runtest"
 	"github.com/google/syzkaller/pkg/signal"
 	"github.com/google/syzkaller/pkg/stat"
+	"github.com/google/syzkaller/pkg/subsystem"
+	_ "github.com/google/syzkaller/pkg/subsystem/lists"
 	"github.com/google/syzkaller/pkg/vminfo"
 	"github.com/google/syzkaller/prog"
 	"github.com/google/syzkaller/sys/targets"
 	"github.com/google/syzkaller/vm"
 	"github.com/google/syzkaller/vm/dispatcher"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -99,6 +106,12 @@ type Manager struct {
 	// This is specifically separated from dash, so that we can keep dash = nil when
 	// cfg.DashboardOnlyRepro is set, so that we don't accidentially use dash for anything.
 	dashRepro *dashapi.Dashboard
+	// Context for dashboard calls, it's cancelled on shutdown so that a stuck dashboard does not block it.
+	dashCtx context.Context
+	// Settings pushed from the dashboard, nil until the first successful poll.
+	dashOverrides atomic.Pointer[dashOverrides]
+	// Attributes crashes uploaded to the dashboard to subsystems, nil if cfg.DashboardSubsystems is not set.
+	subsystems *subsystem.Extractor
 
 	mu                    sync.Mutex
 	fuzzer                atomic.Pointer[fuzzer.Fuzzer]
@@ -112,6 +125,8 @@ type Manager struct {
 	memoryLeakFrames map[string]bool
 	dataRaceFrames   map[string]bool
 	saturatedCalls   map[string]bool
+	// Crashes are queued while the dashboard reporting is paused (see dashOverrides).
+	pausedCrashes []*dashapi.Crash
 
 	externalReproQueue chan *manager.Crash
 	crashes            chan *manager.Crash
@@ -218,6 +233,7 @@ func RunManager(mode Mode, cfg *mgrconfig.Config) {
 		mode:               mode,
 		vmPool:             vmPool,
 		corpus:             corpus.NewMonitoredCorpus(context.Background(), corpusUpdates),
+		dashCtx:            vm.ShutdownCtx(),
 		corpusPreload:      make(chan []fuzzer.Candidate),
 		target:             cfg.Target,
 		sysTarget:          cfg.SysTarget,
@@ -259,10 +275,70 @@ func RunManager(mode Mode, cfg *mgrconfig.Config) {
 	log.Logf(0, "serving rpc on tcp://%v", mgr.serv.Port())
 
 	if cfg.DashboardAddr != "" {
-		opts := []dashapi.DashboardOpts{}
+		opts := []dashapi.DashboardOpts{
+			dashapi.SpoolConfig{
+				Dir: filepath.Join(cfg.Workdir, "dashboard-spool"),
+			},
+			dashapi.ChunkedUpload{},
+			dashapi.BlobDedup{},
+			dashapi.CircuitBreaker{},
+			dashapi.FailedReproDedup{},
+			// Repro logs can get quite large and we have trouble sending large API requests (see #4495).
+			// Let's truncate the log to a 512KB prefix and 512KB suffix.
+			dashapi.TruncationPolicy{
+				ReproLog: dashapi.Truncation{Limit: 1024000, Keep: dashapi.KeepHeadAndTail},
+			},
+		}
+		metrics := dashapi.NewMetrics()
+		prometheus.MustRegister(metrics)
+		opts = append(opts, metrics)
 		if cfg.DashboardUserAgent != "" {
 			opts = append(opts, dashapi.UserAgent(cfg.DashboardUserAgent))
 		}
+		if cfg.DashboardNamespace != "" {
+			opts = append(opts, dashapi.Namespace(cfg.DashboardNamespace))
+		}
+		if cfg.DashboardGRPC != "" {
+			opts = append(opts, dashapi.GRPC{Addr: cfg.DashboardGRPC})
+		}
+		if cfg.DashboardProxy != "" {
+			opts = append(opts, dashapi.Proxy(cfg.DashboardProxy))
+		}
+		if cfg.DashboardSignRequests {
+			opts = append(opts, dashapi.SignRequests(true))
+		}
+		if cfg.DashboardDryRun {
+			opts = append(opts, dashapi.DryRun(true))
+		}
+		if cfg.DashboardCrashIndex {
+			opts = append(opts, dashapi.CrashIndexConfig{
+				File: filepath.Join(cfg.Workdir, "dashboard-crashes.json"),
+			})
+		}
+		if cfg.DashboardCompression != "" {
+			opts = append(opts, dashapi.Compression(cfg.DashboardCompression))
+		}
+		if cfg.DashboardClientCert != "" || cfg.DashboardCA != "" {
+			opts = append(opts, dashapi.ClientTLS{
+				CertFile: cfg.DashboardClientCert,
+				KeyFile:  cfg.DashboardClientKey,
+				CAFile:   cfg.DashboardCA,
+			})
+		}
+		if cfg.DashboardServiceAccount != "" {
+			provider, err := dashapi.ServiceAccount(context.Background(), cfg.DashboardServiceAccount)
+			if err != nil {
+				log.Fatalf("failed to load dashboard service account: %v", err)
+			}
+			opts = append(opts, provider)
+		}
+		if cfg.DashboardPayloadKeys != "" {
+			keys, err := dashapi.LoadPayloadKeys(cfg.DashboardPayloadKeys)
+			if err != nil {
+				log.Fatalf("failed to load dashboard payload keys: %v", err)
+			}
+			opts = append(opts, keys)
+		}
 		dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey, opts...)
 		if err != nil {
 			log.Fatalf("failed to create dashapi connection: %v", err)
@@ -272,6 +348,13 @@ func RunManager(mode Mode, cfg *mgrconfig.Config) {
 			mgr.dash = dash
 		}
 	}
+	if cfg.DashboardSubsystems != "" {
+		list := subsystem.GetList(cfg.DashboardSubsystems)
+		if list == nil {
+			log.Fatalf("unknown subsystem list %q", cfg.DashboardSubsystems)
+		}
+		mgr.subsystems = subsystem.MakeExtractor(list)
+	}
 
 	if !cfg.AssetStorage.IsEmpty() {
 		mgr.assetStorage, err = asset.StorageFromConfig(cfg.AssetStorage, mgr.dash)
@@ -283,6 +366,14 @@ func RunManager(mode Mode, cfg *mgrconfig.Config) {
 	if *flagBench != "" {
 		mgr.initBench()
 	}
+	if mgr.dash != nil && mgr.mode == ModeFuzzing {
+		// Syscalls can only be disabled before fuzzing starts, so the config is polled before the VMs start.
+		// The poll is bounded, so that a stuck dashboard does not delay fuzzing, the config is then
+		// applied by the periodic polls.
+		ctx, cancel := context.WithTimeout(mgr.dashCtx, dashConfigStartupTimeout)
+		mgr.pollDashboardConfig(ctx)
+		cancel()
+	}
 
 	go mgr.heartbeatLoop()
 	if mgr.mode != ModeSmokeTest {
@@ -428,7 +519,15 @@ func reportReproError(err error) {
 }
 
 func (mgr *Manager) RunRepro(crash *manager.Crash) *manager.ReproResult {
-	res, stats, err := repro.Run(crash.Output, mgr.cfg, mgr.enabledFeatures, mgr.reporter, mgr.pool)
+	claimed, release := mgr.claimRepro(crash)
+	if !claimed {
+		return &manager.ReproResult{Crash: crash}
+	}
+	defer release()
+	progress, stop := mgr.reproProgressReporter(crash)
+	res, stats, err := repro.RunWithProgress(crash.Output, mgr.cfg, mgr.enabledFeatures,
+		mgr.reporter, mgr.pool, progress)
+	stop()
 	ret := &manager.ReproResult{
 		Crash: crash,
 		Repro: res,
@@ -456,6 +555,96 @@ func (mgr *Manager) RunRepro(crash *manager.Crash) *manager.ReproResult {
 	return ret
 }
 
+// claimRepro claims the reproduction of the crash on the dashboard, so that other managers
+// don't reproduce the same crash at the same time. The claim is renewed until it's released.
+func (mgr *Manager) claimRepro(crash *manager.Crash) (bool, func()) {
+	if mgr.dash == nil || crash.FromDashboard || crash.FromHub || crash.Title == "" {
+		return true, func() {}
+	}
+	req := &dashapi.ReproClaimReq{
+		Manager: mgr.cfg.Name,
+		Title:   crash.Title,
+		Lease:   dashapi.DefaultReproClaimLease,
+	}
+	resp, err := mgr.dash.ClaimRepro(mgr.dashCtx, req)
+	if err != nil {
+		// E.g. the dashboard does not support claims yet.
+		log.Logf(1, "failed to claim repro of %q: %v", crash.Title, err)
+		return true, func() {}
+	}
+	if !resp.Claimed {
+		log.Logf(0, "repro of %q is claimed by %v until %v", crash.Title, resp.Manager, resp.Deadline)
+		return false, nil
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(req.Lease / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := mgr.dash.ClaimRepro(mgr.dashCtx, req); err != nil {
+					log.Logf(1, "failed to renew repro claim of %q: %v", crash.Title, err)
+				}
+			}
+		}
+	}()
+	return true, func() {
+		close(done)
+		if err := mgr.dash.ReleaseReproClaim(mgr.dashCtx, mgr.cfg.Name, crash.Title); err != nil {
+			log.Logf(1, "failed to release repro claim of %q: %v", crash.Title, err)
+		}
+	}
+}
+
+// reproProgressReporter reports the reproduction phases to the dashboard, and repeats
+// the last report periodically so that the dashboard does not consider the reproduction finished.
+func (mgr *Manager) reproProgressReporter(crash *manager.Crash) (repro.ProgressFunc, func()) {
+	if mgr.dash == nil || crash.Title == "" {
+		return nil, func() {}
+	}
+	start := time.Now()
+	var mu sync.Mutex
+	req := &dashapi.ReproProgress{
+		Manager: mgr.cfg.Name,
+		Title:   crash.Title,
+	}
+	send := func() {
+		mu.Lock()
+		req.Elapsed = time.Since(start)
+		report := *req
+		mu.Unlock()
+		if err := mgr.dash.ReportReproProgress(mgr.dashCtx, &report); err != nil {
+			log.Logf(1, "failed to report repro progress: %v", err)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(dashapi.ReproProgressPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				send()
+			}
+		}
+	}()
+	progress := func(phase string, p *prog.Prog) {
+		mu.Lock()
+		req.Phase = dashapi.ReproPhase(phase)
+		if p != nil {
+			req.ReproSyz = p.Serialize()
+		}
+		mu.Unlock()
+		send()
+	}
+	return progress, func() { close(done) }
+}
+
 func (mgr *Manager) processRepro(res *manager.ReproResult) {
 	if res.Err != nil {
 		reportReproError(res.Err)
@@ -466,7 +655,7 @@ func (mgr *Manager) processRepro(res *manager.ReproResult) {
 				res.Crash.FullTitle())
 		} else {
 			log.Logf(1, "report repro failure of '%v'", res.Crash.Title)
-			mgr.saveFailedRepro(res.Crash.Report, res.Stats)
+			mgr.saveFailedRepro(res.Crash, res.Stats)
 		}
 	} else {
 		mgr.saveRepro(res)
@@ -634,6 +823,9 @@ func (mgr *Manager) saveCrash(crash *manager.Crash) bool {
 		log.Fatalf("kernel crashed in smoke testing mode, exiting")
 	}
 
+	if !crash.Suppressed && mgr.dashOverrides.Load().suppressed(crash.Title) {
+		crash.Suppressed = true
+	}
 	if crash.Suppressed {
 		// Collect all of them into a single bucket so that it's possible to control and assess them,
 		// e.g. if there are some spikes in suppressed reports.
@@ -649,26 +841,32 @@ func (mgr *Manager) saveCrash(crash *manager.Crash) bool {
 	}
 	mgr.mu.Unlock()
 
-	if mgr.dash != nil {
-		if crash.Type == crash_pkg.MemoryLeak {
-			return true
-		}
-		dc := &dashapi.Crash{
-			BuildID:     mgr.cfg.Tag,
-			Title:       crash.Title,
-			AltTitles:   crash.AltTitles,
-			Corrupted:   crash.Corrupted,
-			Suppressed:  crash.Suppressed,
-			Recipients:  crash.Recipients.ToDash(),
-			Log:         crash.Output,
-			Report:      crash.Report.Report,
-			MachineInfo: crash.MachineInfo,
-		}
-		setGuiltyFiles(dc, crash.Report)
-		resp, err := mgr.dash.ReportCrash(dc)
-		if err != nil {
+	if mgr.dash != nil && mgr.dashOverrides.Load().paused() {
+		// The crash is reported once the reporting is resumed, until then it's stored locally as well.
+		mgr.queuePausedCrash(mgr.dashCrash(crash))
+	} else if mgr.dash != nil {
+		if executorFailureRe.MatchString(crash.Title) {
+			err := mgr.dash.ReportToolBug(mgr.dashCtx, &dashapi.ToolBugReq{
+				Component:       "executor",
+				Title:           crash.Title,
+				SyzkallerCommit: prog.GitRevision,
+				Log:             crash.Output,
+				Report:          crash.Report.Report,
+			})
+			if err == nil {
+				return false
+			}
+			log.Logf(0, "failed to report tool bug to dashboard: %v", err)
+		}
+		resp, err := mgr.dash.ReportCrash(mgr.dashCtx, mgr.dashCrash(crash))
+		if errors.Is(err, dashapi.ErrSpooled) {
+			// The crash will be uploaded later, but we don't know if it needs a repro,
+			// so store it locally as well.
+			log.Logf(0, "crash is spooled for the dashboard: %v", err)
+		} else if err != nil {
 			log.Logf(0, "failed to report crash to dashboard: %v", err)
 		} else {
+			crash.DashboardID = resp.CrashID
 			// Don't store the crash locally, if we've successfully
 			// uploaded it to the dashboard. These will just eat disk space.
 			return mgr.cfg.Reproduce && resp.NeedRepro
@@ -717,8 +915,38 @@ func (mgr *Manager) saveCrash(crash *manager.Crash) bool {
 	return mgr.NeedRepro(crash)
 }
 
+func (mgr *Manager) dashCrash(crash *manager.Crash) *dashapi.Crash {
+	dc := &dashapi.Crash{
+		BuildID:     mgr.cfg.Tag,
+		Title:       crash.Title,
+		AltTitles:   crash.AltTitles,
+		Corrupted:   crash.Corrupted,
+		Suppressed:  crash.Suppressed,
+		Recipients:  crash.Recipients.ToDash(),
+		Log:         crash.Output,
+		Report:      crash.Report.Report,
+		MachineInfo: crash.MachineInfo,
+	}
+	setGuiltyFiles(dc, crash.Report)
+	dc.Signature = dashapi.CrashSignature(dc.Report)
+	setCrashType(dc, crash.Report)
+	mgr.setSubsystems(dc)
+	dc.Machine = mgr.machineDesc(crash.MachineInfo)
+	return dc
+}
+
+// Executor failures are bugs in syzkaller, they are reported separately from kernel bugs.
+var executorFailureRe = regexp.MustCompile(`^SYZFAIL:|^SYZFATAL:`)
+
 const maxReproAttempts = 3
 
+func (mgr *Manager) reproAttempts() int {
+	if o := mgr.dashOverrides.Load(); o != nil && o.maxReproAttempts != 0 {
+		return o.maxReproAttempts
+	}
+	return maxReproAttempts
+}
+
 func (mgr *Manager) needLocalRepro(crash *manager.Crash) bool {
 	if !mgr.cfg.Reproduce || crash.Corrupted || crash.Suppressed {
 		return false
@@ -728,7 +956,7 @@ func (mgr *Manager) needLocalRepro(crash *manager.Crash) bool {
 	if osutil.IsExist(filepath.Join(dir, "repro.prog")) {
 		return false
 	}
-	for i := 0; i < maxReproAttempts; i++ {
+	for i := 0; i < mgr.reproAttempts(); i++ {
 		if !osutil.IsExist(filepath.Join(dir, fmt.Sprintf("repro%v", i))) {
 			return true
 		}
@@ -757,40 +985,40 @@ func (mgr *Manager) NeedRepro(crash *manager.Crash) bool {
 		Corrupted:  crash.Corrupted,
 		Suppressed: crash.Suppressed,
 		// When cfg.DashboardOnlyRepro is enabled, we don't sent any reports to dashboard.
-		// We also don't send leak reports w/o reproducers to dashboard, so they may be missing.
-		MayBeMissing: mgr.dash == nil || crash.Type == crash_pkg.MemoryLeak,
+		MayBeMissing: mgr.dash == nil,
 	}
-	needRepro, err := mgr.dashRepro.NeedRepro(cid)
+	needRepro, err := mgr.dashRepro.NeedRepro(mgr.dashCtx, cid)
 	if err != nil {
 		log.Logf(0, "dashboard.NeedRepro failed: %v", err)
 	}
 	return needRepro
 }
 
-func truncateReproLog(log []byte) []byte {
-	// Repro logs can get quite large and we have trouble sending large API requests (see #4495).
-	// Let's truncate the log to a 512KB prefix and 512KB suffix.
-	return report.Truncate(log, 512000, 512000)
-}
-
-func (mgr *Manager) saveFailedRepro(rep *report.Report, stats *repro.Stats) {
+func (mgr *Manager) saveFailedRepro(crash *manager.Crash, stats *repro.Stats) {
+	rep := crash.Report
 	reproLog := stats.FullLog()
-	if mgr.dash != nil {
-		if rep.Type == crash_pkg.MemoryLeak {
-			// Don't send failed leak repro attempts to dashboard
-			// as we did not send the crash itself.
-			log.Logf(1, "failed repro of '%v': not sending because of the memleak type", rep.Title)
-			return
+	if crash.ReproTaskID != "" {
+		res := &dashapi.ReproTaskResult{
+			Status:   dashapi.ReproTaskFailed,
+			BuildID:  mgr.cfg.Tag,
+			ReproLog: reproLog,
 		}
+		if err := mgr.dash.ReproTaskDone(mgr.dashCtx, mgr.cfg.Name, crash.ReproTaskID, res); err != nil {
+			log.Logf(0, "failed to report failed repro task to dashboard: %v", err)
+		}
+		return
+	}
+	if mgr.dash != nil {
 		cid := &dashapi.CrashID{
 			BuildID:      mgr.cfg.Tag,
 			Title:        rep.Title,
 			Corrupted:    rep.Corrupted,
 			Suppressed:   rep.Suppressed,
-			MayBeMissing: rep.Type == crash_pkg.MemoryLeak,
-			ReproLog:     truncateReproLog(reproLog),
+			MayBeMissing: mgr.dashOverrides.Load().paused(), // crashes are queued while paused
+			ReproLog:     reproLog,
+			FailedRepro:  failedReproInfo(stats),
 		}
-		if err := mgr.dash.ReportFailedRepro(cid); err != nil {
+		if err := mgr.dash.ReportFailedRepro(mgr.dashCtx, cid); err != nil {
 			log.Logf(0, "failed to report failed repro to dashboard (log size %d): %v",
 				len(reproLog), err)
 		} else {
@@ -799,7 +1027,7 @@ func (mgr *Manager) saveFailedRepro(rep *report.Report, stats *repro.Stats) {
 	}
 	dir := filepath.Join(mgr.crashdir, hash.String([]byte(rep.Title)))
 	osutil.MkdirAll(dir)
-	for i := 0; i < maxReproAttempts; i++ {
+	for i := 0; i < mgr.reproAttempts(); i++ {
 		name := filepath.Join(dir, fmt.Sprintf("repro%v", i))
 		if !osutil.IsExist(name) && len(reproLog) > 0 {
 			osutil.WriteFile(name, reproLog)
@@ -808,6 +1036,26 @@ func (mgr *Manager) saveFailedRepro(rep *report.Report, stats *repro.Stats) {
 	}
 }
 
+func failedReproInfo(stats *repro.Stats) *dashapi.FailedRepro {
+	if stats == nil {
+		return nil
+	}
+	info := &dashapi.FailedRepro{
+		Attempts: stats.Runs,
+		Duration: stats.TotalTime,
+	}
+	if stats.ExtractProgTime != 0 {
+		info.Strategies = append(info.Strategies, dashapi.ReproStrategyBisection)
+	}
+	if stats.MinimizeProgTime != 0 || stats.SimplifyProgTime != 0 {
+		info.Strategies = append(info.Strategies, dashapi.ReproStrategyMinimization)
+	}
+	if stats.ExtractCTime != 0 || stats.SimplifyCTime != 0 {
+		info.Strategies = append(info.Strategies, dashapi.ReproStrategyCConversion)
+	}
+	return info
+}
+
 func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 	repro := res.Repro
 	opts := fmt.Sprintf("# %+v\n", repro.Opts)
@@ -854,6 +1102,9 @@ func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 			output = res.Strace.Output
 			crashFlags = dashapi.CrashUnderStrace
 		}
+		if res.Crash.FromHub {
+			crashFlags |= dashapi.CrashFromHub
+		}
 
 		dc := &dashapi.Crash{
 			BuildID:       mgr.cfg.Tag,
@@ -867,12 +1118,31 @@ func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 			ReproOpts:     repro.Opts.Serialize(),
 			ReproSyz:      progText,
 			ReproC:        cprogText,
-			ReproLog:      truncateReproLog(res.Stats.FullLog()),
+			ReproLog:      res.Stats.FullLog(),
 			Assets:        mgr.uploadReproAssets(repro),
 			OriginalTitle: res.Crash.Title,
 		}
 		setGuiltyFiles(dc, report)
-		if _, err := mgr.dash.ReportCrash(dc); err != nil {
+		dc.Signature = dashapi.CrashSignature(dc.Report)
+		setCrashType(dc, report)
+		mgr.setSubsystems(dc)
+		dc.Machine = mgr.machineDesc(nil)
+		if mgr.dashOverrides.Load().paused() {
+			// The repro is reported once the reporting is resumed, until then it's stored locally as well.
+			mgr.queuePausedCrash(dc)
+		} else if taskID := res.Crash.ReproTaskID; taskID != "" {
+			err := mgr.dash.ReproTaskDone(mgr.dashCtx, mgr.cfg.Name, taskID, &dashapi.ReproTaskResult{
+				Status: dashapi.ReproTaskSucceeded,
+				Crash:  dc,
+			})
+			if err != nil {
+				log.Logf(0, "failed to report repro task to dashboard: %v", err)
+			} else {
+				return
+			}
+		} else if mgr.updateReproCrash(res, dc) {
+			return
+		} else if _, err := mgr.dash.ReportCrash(mgr.dashCtx, dc); err != nil && !errors.Is(err, dashapi.ErrSpooled) {
 			log.Logf(0, "failed to report repro to dashboard: %v", err)
 		} else {
 			// Don't store the crash locally, if we've successfully
@@ -926,6 +1196,30 @@ func (mgr *Manager) ResizeReproPool(size int) {
 	mgr.pool.ReserveForRun(size)
 }
 
+// updateReproCrash attaches the repro to the crash it was started from, if that crash
+// is already on the dashboard, so that the dashboard does not get a duplicate crash.
+func (mgr *Manager) updateReproCrash(res *manager.ReproResult, dc *dashapi.Crash) bool {
+	// Under strace the repro comes with its own report and log that are worth saving.
+	if res.Crash.DashboardID == 0 || res.Strace != nil || dc.Title != res.Crash.Title {
+		return false
+	}
+	err := mgr.dash.UpdateCrash(mgr.dashCtx, &dashapi.UpdateCrashReq{
+		BuildID:   dc.BuildID,
+		Title:     res.Crash.Title,
+		CrashID:   res.Crash.DashboardID,
+		ReproOpts: dc.ReproOpts,
+		ReproSyz:  dc.ReproSyz,
+		ReproC:    dc.ReproC,
+		ReproLog:  dc.ReproLog,
+		Assets:    dc.Assets,
+	})
+	if err != nil {
+		log.Logf(0, "failed to attach repro to the dashboard crash: %v", err)
+		return false
+	}
+	return true
+}
+
 func (mgr *Manager) uploadReproAssets(repro *repro.Result) []dashapi.NewAsset {
 	if mgr.assetStorage == nil {
 		return nil
@@ -1074,6 +1368,56 @@ func setGuiltyFiles(crash *dashapi.Crash, report *report.Report) {
 	}
 }
 
+// setCrashType classifies the crash, memory leaks are also reported as dashapi.CrashLeak.
+func setCrashType(crash *dashapi.Crash, report *report.Report) {
+	crash.Type = dashapi.CrashType(report.Type)
+	if report.Type == crash_pkg.MemoryLeak {
+		crash.Kind = dashapi.CrashLeak
+		crash.Leak = dashapi.ParseLeak(report.Report)
+	}
+}
+
+// setSubsystems attributes the crash to subsystems based on its guilty file and reproducer.
+func (mgr *Manager) setSubsystems(crash *dashapi.Crash) {
+	if mgr.subsystems == nil {
+		return
+	}
+	sc := &subsystem.Crash{SyzRepro: crash.ReproSyz}
+	if len(crash.GuiltyFiles) != 0 {
+		sc.GuiltyPath = crash.GuiltyFiles[0]
+	}
+	for _, item := range mgr.subsystems.Extract([]*subsystem.Crash{sc}) {
+		crash.Subsystems = append(crash.Subsystems, item.Name)
+	}
+}
+
+var cpuModelRe = regexp.MustCompile(`(?m)^model name\s*:\s*(.+)$`)
+
+// machineDesc describes the VMs for the dashboard based on the VM config and the machine info.
+func (mgr *Manager) machineDesc(machineInfo []byte) *dashapi.Machine {
+	desc := &dashapi.Machine{
+		Arch:   mgr.cfg.TargetVMArch,
+		VMType: mgr.cfg.Type,
+	}
+	// These are the qemu config names, other VM types use the same names for similar options.
+	var vmCfg struct {
+		CPU      int    `json:"cpu"`
+		Mem      int    `json:"mem"`
+		QemuArgs string `json:"qemu_args"`
+		Cmdline  string `json:"cmdline"`
+	}
+	if err := json.Unmarshal(mgr.cfg.VM, &vmCfg); err == nil {
+		desc.NumCPUs = vmCfg.CPU
+		desc.MemoryMB = vmCfg.Mem
+		desc.QemuArgs = vmCfg.QemuArgs
+		desc.Cmdline = vmCfg.Cmdline
+	}
+	if match := cpuModelRe.FindSubmatch(machineInfo); match != nil {
+		desc.CPUModel = string(bytes.TrimSpace(match[1]))
+	}
+	return desc
+}
+
 func (mgr *Manager) collectSyscallInfo() map[string]*corpus.CallCov {
 	mgr.mu.Lock()
 	enabledSyscalls := mgr.targetEnabledSyscalls
@@ -1121,6 +1465,18 @@ func (mgr *Manager) MachineChecked(features flatrpc.Feature, enabledSyscalls map
 		panic("MachineChecked called twice")
 	}
 	mgr.enabledFeatures = features
+	if disabled := mgr.dashOverrides.Load().disabled(); len(disabled) != 0 {
+		enabledSyscalls = maps.Clone(enabledSyscalls)
+		for call := range enabledSyscalls {
+			if slices.Contains(disabled, call.Name) {
+				delete(enabledSyscalls, call)
+			}
+		}
+		log.Logf(0, "dashboard: disabled syscalls %v", disabled)
+		if len(enabledSyscalls) == 0 {
+			log.Fatalf("all system calls are disabled")
+		}
+	}
 	mgr.targetEnabledSyscalls = enabledSyscalls
 	mgr.firstConnect.Store(time.Now().Unix())
 	statSyscalls := stat.New("syscalls", "Number of enabled syscalls",
@@ -1160,7 +1516,11 @@ func (mgr *Manager) MachineChecked(features flatrpc.Feature, enabledSyscalls map
 		go mgr.corpusMinimization()
 		go mgr.fuzzerLoop(fuzzerObj)
 		if mgr.dash != nil {
+			go mgr.uploadSyscalls(enabledSyscalls, mgr.serv.DisabledSyscalls())
 			go mgr.dashboardReporter()
+			go mgr.dashboardConfigPoller()
+			go mgr.dashboardNotifsPoller()
+			go mgr.dashboardCommandsPoller()
 			if mgr.cfg.Reproduce {
 				go mgr.dashboardReproTasks()
 			}
@@ -1338,7 +1698,7 @@ func (mgr *Manager) hubIsUnreachable() {
 	}
 	mgr.mu.Unlock()
 	if dash != nil {
-		mgr.dash.LogError(mgr.cfg.Name, "did not manage to connect to syz-hub")
+		mgr.dash.LogError(mgr.dashCtx, mgr.cfg.Name, "did not manage to connect to syz-hub")
 	}
 }
 
@@ -1382,11 +1742,29 @@ func (mgr *Manager) trackUsedFiles() {
 	}
 }
 
+func (mgr *Manager) uploadSyscalls(enabled map[*prog.Syscall]bool, disabled map[*prog.Syscall]string) {
+	req := &dashapi.ManagerSyscallsReq{
+		Manager:     mgr.cfg.Name,
+		BuildID:     mgr.cfg.Tag,
+		Unsupported: make(map[string]string),
+	}
+	for call := range enabled {
+		req.Enabled = append(req.Enabled, call.Name)
+	}
+	for call, reason := range disabled {
+		req.Unsupported[call.Name] = reason
+	}
+	if err := mgr.dash.UploadManagerSyscalls(mgr.dashCtx, req); err != nil {
+		log.Logf(0, "failed to upload syscalls to dashboard: %v", err)
+	}
+}
+
 func (mgr *Manager) dashboardReporter() {
 	webAddr := publicWebAddr(mgr.cfg.HTTP)
 	triageInfoSent := false
 	var lastFuzzingTime time.Duration
 	var lastCrashes, lastSuppressedCrashes, lastExecs uint64
+	var points []dashapi.StatsPoint
 	for range time.NewTicker(time.Minute).C {
 		mgr.mu.Lock()
 		req := &dashapi.ManagerStatsReq{
@@ -1401,6 +1779,15 @@ func (mgr *Manager) dashboardReporter() {
 			Crashes:           uint64(mgr.statCrashes.Val()) - lastCrashes,
 			SuppressedCrashes: uint64(mgr.statSuppressed.Val()) - lastSuppressedCrashes,
 			Execs:             uint64(queue.StatExecs.Val()) - lastExecs,
+			ConfigVersion:     mgr.dashOverrides.Load().applied(),
+		}
+		if mgr.pool != nil {
+			for _, state := range mgr.pool.State() {
+				if state.State == dispatcher.StateRunning {
+					req.VMs++
+				}
+			}
+			req.TotalVMs = uint64(mgr.pool.Total())
 		}
 		if mgr.phase >= phaseTriagedCorpus && !triageInfoSent {
 			triageInfoSent = true
@@ -1409,7 +1796,22 @@ func (mgr *Manager) dashboardReporter() {
 		}
 		mgr.mu.Unlock()
 
-		if err := mgr.dash.UploadManagerStats(req); err != nil {
+		// Points that failed to upload are retried with the next ones.
+		points = append(points, mgr.statsPoint())
+		if len(points) > dashapi.MaxStatsPoints {
+			points = points[len(points)-dashapi.MaxStatsPoints:]
+		}
+		err := mgr.dash.UploadStatsSeries(mgr.dashCtx, &dashapi.UploadStatsSeriesReq{
+			Manager: mgr.cfg.Name,
+			Points:  points,
+		})
+		if err != nil {
+			log.Logf(0, "failed to upload dashboard stats series: %v", err)
+		} else {
+			points = nil
+		}
+
+		if err := mgr.dash.UploadManagerStats(mgr.dashCtx, req); err != nil {
 			log.Logf(0, "failed to upload dashboard stats: %v", err)
 			continue
 		}
@@ -1422,18 +1824,347 @@ func (mgr *Manager) dashboardReporter() {
 	}
 }
 
+func (mgr *Manager) statsPoint() dashapi.StatsPoint {
+	point := dashapi.StatsPoint{
+		Time: time.Now(),
+		Values: map[string]int64{
+			dashapi.StatExecs:   int64(queue.StatExecs.Val()),
+			dashapi.StatCover:   int64(mgr.corpus.StatCover.Val()),
+			dashapi.StatSignal:  int64(mgr.corpus.StatSignal.Val()),
+			dashapi.StatCorpus:  int64(mgr.corpus.StatProgs.Val()),
+			dashapi.StatCrashes: int64(mgr.statCrashes.Val()),
+		},
+	}
+	for _, stat := range stat.Collect(stat.All) {
+		if stat.Name == "vm restarts" {
+			point.Values[dashapi.StatFuzzerRestarts] = int64(stat.V)
+		}
+	}
+	return point
+}
+
+// dashOverrides are the manager settings pushed from the dashboard (see dashapi.PollManagerConfig).
+type dashOverrides struct {
+	version string // the polled version
+	// The last version that is applied in full, it's reported to the dashboard. Changes of the disabled
+	// syscalls need a restart, until then the versions with such changes are applied only in part.
+	appliedVersion   string
+	disabledSyscalls []string // sorted, the ones in effect
+	suppressedTitles []*regexp.Regexp
+	maxReproAttempts int
+	reportingPaused  bool
+}
+
+func (o *dashOverrides) configVersion() string {
+	if o == nil {
+		return ""
+	}
+	return o.version
+}
+
+func (o *dashOverrides) applied() string {
+	if o == nil {
+		return ""
+	}
+	return o.appliedVersion
+}
+
+func (o *dashOverrides) disabled() []string {
+	if o == nil {
+		return nil
+	}
+	return o.disabledSyscalls
+}
+
+func (o *dashOverrides) suppressed(title string) bool {
+	if o == nil {
+		return false
+	}
+	for _, re := range o.suppressedTitles {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *dashOverrides) paused() bool {
+	return o != nil && o.reportingPaused
+}
+
+const (
+	dashConfigStartupTimeout = time.Minute
+	// Only the latest crashes are queued while the reporting is paused.
+	maxPausedCrashes = 1000
+)
+
+func (mgr *Manager) dashboardConfigPoller() {
+	for ; ; time.Sleep(5 * time.Minute) {
+		mgr.pollDashboardConfig(mgr.dashCtx)
+		if !mgr.dashOverrides.Load().paused() {
+			mgr.reportPausedCrashes()
+		}
+	}
+}
+
+func (mgr *Manager) pollDashboardConfig(ctx context.Context) {
+	current := mgr.dashOverrides.Load()
+	resp, err := mgr.dash.PollManagerConfig(ctx, mgr.cfg.Name, current.configVersion())
+	if err != nil {
+		log.Logf(0, "failed to poll manager config: %v", err)
+		return
+	}
+	if resp.Unchanged {
+		return
+	}
+	o := &dashOverrides{
+		version:          resp.Version,
+		appliedVersion:   resp.Version,
+		disabledSyscalls: slices.Clone(resp.DisabledSyscalls),
+		maxReproAttempts: resp.MaxReproAttempts,
+		reportingPaused:  resp.ReportingPaused,
+	}
+	sort.Strings(o.disabledSyscalls)
+	for _, str := range resp.SuppressedTitles {
+		re, err := regexp.Compile(str)
+		if err != nil {
+			log.Logf(0, "dashboard: bad suppressed title %q: %v", str, err)
+			continue
+		}
+		o.suppressedTitles = append(o.suppressedTitles, re)
+	}
+	if mgr.checkDone.Load() && !slices.Equal(o.disabledSyscalls, current.disabled()) {
+		log.Logf(0, "dashboard: disabling syscalls %v requires a restart, applied manager config %v in part",
+			o.disabledSyscalls, resp.Version)
+		o.disabledSyscalls = current.disabled()
+		o.appliedVersion = current.applied()
+	} else {
+		log.Logf(0, "dashboard: applied manager config %v", resp.Version)
+	}
+	mgr.dashOverrides.Store(o)
+}
+
+func (mgr *Manager) queuePausedCrash(dc *dashapi.Crash) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	mgr.pausedCrashes = append(mgr.pausedCrashes, dc)
+	if len(mgr.pausedCrashes) > maxPausedCrashes {
+		mgr.pausedCrashes = mgr.pausedCrashes[1:]
+	}
+}
+
+// reportPausedCrashes reports the crashes queued while the reporting was paused.
+func (mgr *Manager) reportPausedCrashes() {
+	mgr.mu.Lock()
+	crashes := mgr.pausedCrashes
+	mgr.pausedCrashes = nil
+	mgr.mu.Unlock()
+	for _, dc := range crashes {
+		var err error
+		if executorFailureRe.MatchString(dc.Title) && len(dc.ReproSyz) == 0 {
+			err = mgr.dash.ReportToolBug(mgr.dashCtx, &dashapi.ToolBugReq{
+				Component:       "executor",
+				Title:           dc.Title,
+				SyzkallerCommit: prog.GitRevision,
+				Log:             dc.Log,
+				Report:          dc.Report,
+			})
+		} else {
+			_, err = mgr.dash.ReportCrash(mgr.dashCtx, dc)
+		}
+		if err != nil && !errors.Is(err, dashapi.ErrSpooled) {
+			log.Logf(0, "failed to report a queued crash to dashboard: %v", err)
+		}
+	}
+	if len(crashes) != 0 {
+		log.Logf(0, "dashboard: reported %v crashes queued while the reporting was paused", len(crashes))
+	}
+}
+
+// dashboardNotifsPoller polls changes of the bugs the manager has hit. The dashboard client drops
+// closed bugs from the crash index, and queued reproductions are re-checked before they start,
+// so here we only need to keep the polls going.
+func (mgr *Manager) dashboardNotifsPoller() {
+	caps, err := mgr.dash.Capabilities(mgr.dashCtx)
+	if err == nil && !caps.HasMethod("manager_notifs") {
+		return
+	}
+	var since time.Time
+	for ; ; time.Sleep(10 * time.Minute) {
+		resp, err := mgr.dash.ManagerNotifs(mgr.dashCtx, &dashapi.ManagerNotifsReq{
+			Manager: mgr.cfg.Name,
+			Since:   since,
+		})
+		if err != nil {
+			log.Logf(0, "failed to poll bug notifications: %v", err)
+			continue
+		}
+		since = resp.Until
+		for _, notif := range resp.Notifs {
+			log.Logf(1, "dashboard: bug %q: %v", notif.Title, notif.Type)
+		}
+	}
+}
+
+// dashboardCommandsPoller executes the commands that admins issue on the dashboard manager page.
+func (mgr *Manager) dashboardCommandsPoller() {
+	caps, err := mgr.dash.Capabilities(mgr.dashCtx)
+	if err == nil && !caps.HasMethod("manager_commands") {
+		return
+	}
+	for ; ; time.Sleep(time.Minute) {
+		cmds, err := mgr.dash.ManagerCommands(mgr.dashCtx, mgr.cfg.Name)
+		if err != nil {
+			log.Logf(0, "failed to poll manager commands: %v", err)
+			continue
+		}
+		for _, cmd := range cmds {
+			log.Logf(0, "dashboard: command %v %q from %v", cmd.Type, cmd.Arg, cmd.Author)
+			stop := false
+			var cmdErr error
+			switch cmd.Type {
+			case dashapi.ManagerCommandStopFuzzing:
+				stop = true
+			case dashapi.ManagerCommandRerunRepro:
+				cmdErr = mgr.rerunRepro(cmd)
+			case dashapi.ManagerCommandRetest:
+				cmdErr = mgr.startRetest(cmd)
+			default:
+				cmdErr = fmt.Errorf("unsupported command")
+			}
+			req := &dashapi.AckManagerCommandReq{
+				Manager: mgr.cfg.Name,
+				ID:      cmd.ID,
+			}
+			if cmdErr != nil {
+				log.Logf(0, "dashboard: command %v failed: %v", cmd.Type, cmdErr)
+				req.Error = cmdErr.Error()
+			}
+			if err := mgr.dash.AckManagerCommand(mgr.dashCtx, req); err != nil {
+				// Don't stop, otherwise the command will stop the manager again after a restart.
+				log.Logf(0, "failed to acknowledge manager command: %v", err)
+				break
+			}
+			if stop {
+				mgr.exit("dashboard stop command")
+			}
+		}
+	}
+}
+
+func (mgr *Manager) rerunRepro(cmd *dashapi.ManagerCommand) error {
+	if !mgr.cfg.Reproduce {
+		return fmt.Errorf("reproduction is disabled in the config")
+	}
+	if len(cmd.CrashLog) == 0 {
+		return fmt.Errorf("no crash log")
+	}
+	crash := &manager.Crash{
+		FromDashboard: true,
+		Manual:        true,
+		Report: &report.Report{
+			Title:  cmd.Arg,
+			Output: cmd.CrashLog,
+		},
+	}
+	select {
+	case mgr.externalReproQueue <- crash:
+		return nil
+	default:
+		return fmt.Errorf("too many queued reproductions")
+	}
+}
+
+// startRetest runs the reproducer of a fixed bug in background and uploads the result,
+// the command itself is acknowledged right away.
+func (mgr *Manager) startRetest(cmd *dashapi.ManagerCommand) error {
+	if !mgr.cfg.Reproduce {
+		return fmt.Errorf("reproduction is disabled in the config")
+	}
+	if len(cmd.ReproSyz) == 0 {
+		return fmt.Errorf("no reproducer")
+	}
+	go func() {
+		res := &dashapi.RetestResult{
+			Manager:   mgr.cfg.Name,
+			CommandID: cmd.ID,
+			BuildID:   mgr.cfg.Tag,
+		}
+		rep, err := mgr.runRetest(cmd.ReproSyz, cmd.ReproOpts)
+		if err != nil {
+			res.Error = err.Error()
+		} else if rep != nil {
+			res.Crashed = true
+			res.CrashTitle = rep.Title
+		}
+		log.Logf(0, "dashboard: retest of bug %v: crashed=%v %q, error %q",
+			cmd.Arg, res.Crashed, res.CrashTitle, res.Error)
+		if err := mgr.dash.UploadRetestResult(mgr.dashCtx, res); err != nil {
+			log.Logf(0, "failed to upload retest result: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (mgr *Manager) runRetest(reproSyz, reproOpts []byte) (*report.Report, error) {
+	opts, err := csource.DeserializeOptions(reproOpts)
+	if err != nil {
+		return nil, err
+	}
+	// Same as pkg/instance does for patch testing to increase the chances to reproduce the crash.
+	if opts.Sandbox == "" {
+		opts.Sandbox = "none"
+	}
+	opts.Repeat, opts.Threaded = true, true
+	var rep *report.Report
+	mgr.pool.Run(func(ctx context.Context, inst *vm.Instance, updInfo dispatcher.UpdateInfo) {
+		updInfo(func(info *dispatcher.Info) {
+			info.Status = "retesting a fixed bug"
+		})
+		execProg, setupErr := instance.SetupExecProg(inst, mgr.cfg, mgr.reporter, nil)
+		if setupErr != nil {
+			err = fmt.Errorf("failed to set up instance: %w", setupErr)
+			return
+		}
+		var res *instance.RunResult
+		res, err = execProg.RunSyzProg(reproSyz, mgr.cfg.Timeouts.NoOutputRunningTime,
+			opts, instance.SyzExitConditions)
+		if err == nil {
+			rep = res.Report
+		}
+	})
+	return rep, err
+}
+
 func (mgr *Manager) dashboardReproTasks() {
 	for range time.NewTicker(20 * time.Minute).C {
 		if !mgr.reproLoop.CanReproMore() {
 			// We don't need reproducers at the moment.
 			continue
 		}
-		resp, err := mgr.dash.LogToRepro(&dashapi.LogToReproReq{BuildID: mgr.cfg.Tag})
+		task, taskErr := mgr.dash.ReproTaskPoll(mgr.dashCtx, mgr.cfg.Name)
+		if taskErr != nil {
+			log.Logf(0, "failed to poll repro tasks: %v", taskErr)
+		}
+		if task != nil {
+			mgr.externalReproQueue <- &manager.Crash{
+				FromDashboard: true,
+				ReproTaskID:   task.TaskID,
+				Report: &report.Report{
+					Title:  task.Title,
+					Output: task.CrashLog,
+				},
+			}
+			continue
+		}
+		resp, err := mgr.dash.LogToRepro(mgr.dashCtx, &dashapi.LogToReproReq{BuildID: mgr.cfg.Tag})
 		if err != nil {
 			log.Logf(0, "failed to query logs to reproduce: %v", err)
 			continue
 		}
-		if len(resp.CrashLog) > 0 {
+		// Unless the dashboard is unable to schedule repro tasks, it hands out
+		// the bugs via repro tasks, so here we only take manual requests.
+		if len(resp.CrashLog) > 0 && (taskErr != nil || resp.Type == dashapi.ManualLog) {
 			mgr.externalReproQueue <- &manager.Crash{
 				FromDashboard: true,
 				Manual:        resp.Type == dashapi.ManualLog,
@@ -0,0 +1,436 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-bugzilla is an external reporting for the dashboard that files bugs in Bugzilla.
+// It polls new bug reports of the reporting type and creates Bugzilla bugs for them (later reports
+// of the same bug, e.g. with a reproducer, are added as comments), resolves Bugzilla bugs once they
+// are closed on the dashboard, and closes dashboard bugs that were resolved in Bugzilla as invalid
+// or as duplicates of other filed bugs. The reporting on the dashboard needs BugzillaConfig
+// (see dashboard/app/reporting_bugzilla.go), the Bugzilla bugs are tagged with the reporting ID
+// in the whiteboard.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/bugzilla"
+	"github.com/google/syzkaller/pkg/config"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+type Config struct {
+	DashboardAddr   string `json:"dashboard_addr"`
+	DashboardClient string `json:"dashboard_client"`
+	DashboardKey    string `json:"dashboard_key"`
+	ReportingType   string `json:"reporting_type"` // "bugzilla" by default
+	BugzillaURL     string `json:"bugzilla_url"`
+	BugzillaAPIKey  string `json:"bugzilla_api_key"`
+	PollPeriod      int    `json:"poll_period"` // in seconds, 5 minutes by default
+}
+
+// reportingConfig is BugzillaConfig of the dashboard passed in dashapi.BugReport.Config.
+type reportingConfig struct {
+	Product   string
+	Component string
+	Version   string
+	CC        []string
+}
+
+const (
+	whiteboardPrefix = "syzbot:"
+	extIDPrefix      = "bugzilla-"
+)
+
+func main() {
+	var (
+		flagConfig = flag.String("config", "", "config file")
+		flagOnce   = flag.Bool("once", false, "sync once and exit")
+	)
+	flag.Parse()
+	cfg := &Config{
+		ReportingType: "bugzilla",
+		PollPeriod:    300,
+	}
+	if err := config.LoadFile(*flagConfig, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.BugzillaURL == "" {
+		log.Fatalf("bugzilla_url is not set")
+	}
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &syncer{
+		cfg:  cfg,
+		dash: dash,
+		bz:   bugzilla.NewClient(cfg.BugzillaURL, cfg.BugzillaAPIKey),
+	}
+	ctx := context.Background()
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("sync failed: %v", err)
+		}
+		if *flagOnce {
+			return
+		}
+		time.Sleep(time.Duration(cfg.PollPeriod) * time.Second)
+	}
+}
+
+type syncer struct {
+	cfg  *Config
+	dash *dashapi.Dashboard
+	bz   *bugzilla.Client
+	// Bugs resolved in Bugzilla since the last successful sync are closed on the dashboard.
+	lastSync time.Time
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	start := time.Now()
+	if err := s.reportNew(ctx); err != nil {
+		return fmt.Errorf("failed to report new bugs: %w", err)
+	}
+	if err := s.notify(ctx); err != nil {
+		return fmt.Errorf("failed to handle notifications: %w", err)
+	}
+	if err := s.syncClosed(ctx); err != nil {
+		return fmt.Errorf("failed to sync closed bugs: %w", err)
+	}
+	if err := s.syncResolved(ctx); err != nil {
+		return fmt.Errorf("failed to sync resolved bugs: %w", err)
+	}
+	s.lastSync = start
+	return nil
+}
+
+func (s *syncer) reportNew(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollBugs(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, rep := range resp.Reports {
+		// A failure to report one bug should not prevent reporting of the rest.
+		if err := s.report(ctx, rep); err != nil {
+			log.Errorf("failed to report %q: %v", rep.Title, err)
+		}
+	}
+	return nil
+}
+
+// notify takes the actions the notifications ask for. Nothing is posted to Bugzilla,
+// the bugs that end up closed or sent to the next reporting are resolved by syncClosed.
+func (s *syncer) notify(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollNotifications(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, notif := range resp.Notifications {
+		if err := s.dash.ReportingUpdateOK(ctx, dashapi.NotificationUpdate(notif)); err != nil {
+			log.Errorf("failed to handle notification for %q: %v", notif.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) report(ctx context.Context, rep *dashapi.BugReport) error {
+	id, _ := parseExtID(rep.ExtID)
+	if rep.JobID != "" {
+		// Job results are posted to the bug the job was started for, if it was filed by us.
+		if id != 0 {
+			if err := s.bz.AddComment(ctx, id, jobComment(rep)); err != nil {
+				return err
+			}
+		}
+		return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{JobID: rep.JobID})
+	}
+	if id == 0 {
+		repCfg := new(reportingConfig)
+		if err := json.Unmarshal(rep.Config, repCfg); err != nil {
+			return fmt.Errorf("failed to unmarshal reporting config: %w", err)
+		}
+		version := repCfg.Version
+		if version == "" {
+			version = "unspecified"
+		}
+		var err error
+		id, err = s.bz.CreateBug(ctx, &bugzilla.NewBug{
+			Product:     repCfg.Product,
+			Component:   repCfg.Component,
+			Version:     version,
+			Summary:     rep.Title,
+			Description: bugDescription(rep),
+			Whiteboard:  whiteboardPrefix + rep.ID,
+			CC:          repCfg.CC,
+		})
+		if err != nil {
+			return err
+		}
+		// Record the bug right away, otherwise it's filed again if something below fails.
+		err = s.dash.SetExtID(ctx, &dashapi.SetExtIDReq{ID: rep.ID, ExtID: formatExtID(id)})
+		if err != nil {
+			return err
+		}
+	} else if err := s.bz.AddComment(ctx, id, bugDescription(rep)); err != nil {
+		return err
+	}
+	reproLevel := dashapi.ReproLevelNone
+	var attachments []*bugzilla.Attachment
+	if len(rep.ReproSyz) != 0 {
+		reproLevel = dashapi.ReproLevelSyz
+		attachments = append(attachments, &bugzilla.Attachment{
+			FileName: "repro.syz",
+			Summary:  "syz reproducer",
+			Data:     rep.ReproSyz,
+		})
+	}
+	if len(rep.ReproC) != 0 {
+		reproLevel = dashapi.ReproLevelC
+		attachments = append(attachments, &bugzilla.Attachment{
+			FileName: "repro.c",
+			Summary:  "C reproducer",
+			Data:     rep.ReproC,
+		})
+	}
+	if rep.EncryptedPayloads {
+		// The reproducers are only linked from the dashboard.
+		attachments = nil
+	}
+	for _, att := range attachments {
+		if err := s.bz.AddAttachment(ctx, id, att); err != nil {
+			return err
+		}
+	}
+	return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{
+		ID:         rep.ID,
+		ExtID:      formatExtID(id),
+		Link:       s.bugLink(id),
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: reproLevel,
+		CrashID:    rep.CrashID,
+	})
+}
+
+// syncClosed resolves Bugzilla bugs that were closed on the dashboard.
+func (s *syncer) syncClosed(ctx context.Context) error {
+	bugs, err := s.bz.SearchBugs(ctx, &bugzilla.Search{
+		Whiteboard: whiteboardPrefix,
+		Open:       true,
+	})
+	if err != nil {
+		return err
+	}
+	closed, byID, err := s.pollClosed(ctx, bugs)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		bug := byID[id]
+		upd, err := s.resolution(ctx, bug, id)
+		if err != nil {
+			log.Errorf("failed to resolve bug %v: %v", bug.ID, err)
+			continue
+		}
+		if err := s.bz.UpdateBug(ctx, bug.ID, upd); err != nil {
+			log.Errorf("failed to resolve bug %v: %v", bug.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) resolution(ctx context.Context, bug *bugzilla.Bug, id string) (*bugzilla.BugUpdate, error) {
+	extBug, err := s.lookup(ctx, bug.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	upd := &bugzilla.BugUpdate{
+		Status: bugzilla.StatusResolved,
+	}
+	switch extBug.Status {
+	case dashapi.BugStatusFixed:
+		upd.Resolution = bugzilla.ResolutionFixed
+		upd.Comment = fmt.Sprintf("The bug is fixed: %v", extBug.Link)
+	case dashapi.BugStatusInvalid:
+		upd.Resolution = bugzilla.ResolutionInvalid
+		upd.Comment = fmt.Sprintf("The bug is closed as invalid: %v", extBug.Link)
+	case dashapi.BugStatusDup:
+		upd.Comment = fmt.Sprintf("The bug is closed as a duplicate: %v", extBug.Link)
+		if dupOf, ok := parseExtID(extBug.DupOf); ok {
+			upd.Resolution = bugzilla.ResolutionDuplicate
+			upd.DupeOf = dupOf
+		} else {
+			// The canonical bug was never filed in Bugzilla.
+			upd.Resolution = bugzilla.ResolutionInvalid
+		}
+	default:
+		// The bug is still open, but it's not reported in this reporting anymore.
+		upd.Resolution = bugzilla.ResolutionWontFix
+		upd.Comment = fmt.Sprintf("The bug is not tracked here anymore: %v", extBug.Link)
+	}
+	return upd, nil
+}
+
+// syncResolved closes dashboard bugs that were resolved in Bugzilla.
+// Only the resolutions that don't need more information from the developers are synced,
+// e.g. a fixed bug needs the title of the fixing commit.
+func (s *syncer) syncResolved(ctx context.Context) error {
+	bugs, err := s.bz.SearchBugs(ctx, &bugzilla.Search{
+		Whiteboard:   whiteboardPrefix,
+		ChangedSince: s.lastSync,
+	})
+	if err != nil {
+		return err
+	}
+	var resolved []*bugzilla.Bug
+	for _, bug := range bugs {
+		if bug.Resolution != "" {
+			resolved = append(resolved, bug)
+		}
+	}
+	// Closed bugs were resolved by syncClosed.
+	closed, byID, err := s.pollClosed(ctx, resolved)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		delete(byID, id)
+	}
+	for id, bug := range byID {
+		upd := &dashapi.BugUpdate{
+			ID: id,
+		}
+		switch bug.Resolution {
+		case bugzilla.ResolutionInvalid, bugzilla.ResolutionWontFix, bugzilla.ResolutionWorksForMe:
+			upd.Status = dashapi.BugStatusInvalid
+		case bugzilla.ResolutionDuplicate:
+			dup, err := s.bz.GetBug(ctx, bug.DupeOf)
+			if err != nil {
+				log.Errorf("failed to get bug %v: %v", bug.DupeOf, err)
+				continue
+			}
+			dupID := reportingID(dup)
+			if dupID == "" {
+				log.Logf(0, "bug %v is a duplicate of bug %v that was not filed by us", bug.ID, dup.ID)
+				continue
+			}
+			upd.Status = dashapi.BugStatusDup
+			upd.DupOf = dupID
+		default:
+			log.Logf(0, "bug %v is resolved as %v, close it on the dashboard", bug.ID, bug.Resolution)
+			continue
+		}
+		if err := s.dash.ReportingUpdateOK(ctx, upd); err != nil {
+			log.Errorf("failed to close bug %v: %v", bug.ID, err)
+		}
+	}
+	return nil
+}
+
+// pollClosed returns the reporting IDs of the bugs that are closed on the dashboard
+// and all the bugs filed by us by their reporting IDs.
+func (s *syncer) pollClosed(ctx context.Context, bugs []*bugzilla.Bug) ([]string, map[string]*bugzilla.Bug, error) {
+	byID := make(map[string]*bugzilla.Bug)
+	var ids []string
+	for _, bug := range bugs {
+		if id := reportingID(bug); id != "" {
+			byID[id] = bug
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, byID, nil
+	}
+	closed, err := s.dash.ReportingPollClosed(ctx, ids)
+	return closed, byID, err
+}
+
+func (s *syncer) lookup(ctx context.Context, bzID int, id string) (*dashapi.ExtIDBug, error) {
+	extBugs, err := s.dash.LookupExtID(ctx, formatExtID(bzID))
+	if err != nil {
+		return nil, err
+	}
+	for _, extBug := range extBugs {
+		if extBug.ID == id {
+			return extBug, nil
+		}
+	}
+	return nil, fmt.Errorf("the dashboard has no bug %v for reporting %v", bzID, id)
+}
+
+func (s *syncer) bugLink(id int) string {
+	return fmt.Sprintf("%v/show_bug.cgi?id=%v", strings.TrimSuffix(s.cfg.BugzillaURL, "/"), id)
+}
+
+func reportingID(bug *bugzilla.Bug) string {
+	for _, tag := range strings.Fields(bug.Whiteboard) {
+		if id, ok := strings.CutPrefix(tag, whiteboardPrefix); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func formatExtID(id int) string {
+	return extIDPrefix + strconv.Itoa(id)
+}
+
+func parseExtID(extID string) (int, bool) {
+	str, ok := strings.CutPrefix(extID, extIDPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(str)
+	return id, err == nil && id > 0
+}
+
+func bugDescription(rep *dashapi.BugReport) string {
+	buf := new(bytes.Buffer)
+	if rep.Type == dashapi.ReportRepro {
+		fmt.Fprintf(buf, "syzbot has found a reproducer for the bug.\n\n")
+	} else {
+		fmt.Fprintf(buf, "syzbot has found the following issue on:\n\n")
+	}
+	fmt.Fprintf(buf, "HEAD commit:    %v %v\n", rep.KernelCommit, rep.KernelCommitTitle)
+	fmt.Fprintf(buf, "git tree:       %v\n", rep.KernelRepoAlias)
+	fmt.Fprintf(buf, "dashboard link: %v\n", rep.Link)
+	if rep.LogLink != "" {
+		fmt.Fprintf(buf, "console output: %v\n", rep.LogLink)
+	}
+	if rep.KernelConfigLink != "" {
+		fmt.Fprintf(buf, "kernel config:  %v\n", rep.KernelConfigLink)
+	}
+	if rep.EncryptedPayloads {
+		fmt.Fprintf(buf, "The report is encrypted, see the dashboard link.\n")
+	} else if len(rep.ReproSyz) != 0 || len(rep.ReproC) != 0 {
+		fmt.Fprintf(buf, "The reproducers are attached to the bug.\n")
+	}
+	fmt.Fprintf(buf, "\n%s", rep.Report)
+	return buf.String()
+}
+
+func jobComment(rep *dashapi.BugReport) string {
+	buf := new(bytes.Buffer)
+	switch {
+	case len(rep.Error) != 0:
+		fmt.Fprintf(buf, "syzbot failed to run the job:\n\n%s\n", rep.Error)
+	case rep.CrashTitle != "" && rep.EncryptedPayloads:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed:\n\n%v\n\n"+
+			"The report is encrypted, see the dashboard link.\n", rep.CrashTitle)
+	case rep.CrashTitle != "":
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed:\n\n%v\n\n%s\n", rep.CrashTitle, rep.Report)
+	default:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel did not crash.\n")
+	}
+	if rep.Link != "" {
+		fmt.Fprintf(buf, "\ndashboard link: %v\n", rep.Link)
+	}
+	return buf.String()
+}
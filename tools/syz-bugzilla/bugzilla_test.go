@@ -0,0 +1,65 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/dashboard/dashapi/dashapitest"
+	"github.com/google/syzkaller/pkg/bugzilla"
+)
+
+func TestNotify(t *testing.T) {
+	dash := dashapitest.NewServer(t)
+	dash.Handle("reporting_poll_notifs", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollNotificationsResponse{Notifications: []*dashapi.BugNotification{
+			{Type: dashapi.BugNotifObsoleted, ID: "id1", Text: string(dashapi.InvalidatedByRevokedRepro)},
+			{Type: dashapi.BugNotifUpstream, ID: "id2"},
+		}}, nil
+	})
+	dash.Handle("reporting_update", func(payload []byte) (interface{}, error) {
+		return &dashapi.BugUpdateReply{OK: true}, nil
+	})
+	s := &syncer{
+		cfg:  &Config{ReportingType: "bugzilla"},
+		dash: dash.NewClient(),
+	}
+	if err := s.notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var statuses []dashapi.BugStatus
+	for _, req := range dash.Requests("reporting_update") {
+		upd := new(dashapi.BugUpdate)
+		if err := req.Decode(upd); err != nil {
+			t.Fatal(err)
+		}
+		if !upd.Notification {
+			t.Errorf("update %v is not a notification reply", upd.ID)
+		}
+		statuses = append(statuses, upd.Status)
+	}
+	if len(statuses) != 2 || statuses[0] != dashapi.BugStatusInvalid || statuses[1] != dashapi.BugStatusUpstream {
+		t.Fatalf("bad updates: %v", statuses)
+	}
+}
+
+func TestExtID(t *testing.T) {
+	if id, ok := parseExtID(formatExtID(123)); !ok || id != 123 {
+		t.Errorf("bad round trip: %v %v", id, ok)
+	}
+	for _, extID := range []string{"", "123", "bugzilla-", "bugzilla-0", "bugzilla-x", "github-1"} {
+		if _, ok := parseExtID(extID); ok {
+			t.Errorf("%q is parsed", extID)
+		}
+	}
+	bug := &bugzilla.Bug{Whiteboard: "triaged syzbot:abcdef other"}
+	if id := reportingID(bug); id != "abcdef" {
+		t.Errorf("bad reporting ID %q", id)
+	}
+	if id := reportingID(&bugzilla.Bug{Whiteboard: "triaged"}); id != "" {
+		t.Errorf("bad reporting ID %q", id)
+	}
+}
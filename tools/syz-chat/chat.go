@@ -0,0 +1,209 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-chat is an external reporting for the dashboard that posts new bugs and found reproducers
+// to Slack channels or Matrix rooms. The channels are selected by the bug subsystems as configured
+// by ChatConfig of the reporting on the dashboard (see dashboard/app/reporting_chat.go).
+// Chats are only informed about the bugs, so notifications (e.g. about obsoleted bugs)
+// are acknowledged right away.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/chat"
+	"github.com/google/syzkaller/pkg/config"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+type Config struct {
+	DashboardAddr   string `json:"dashboard_addr"`
+	DashboardClient string `json:"dashboard_client"`
+	DashboardKey    string `json:"dashboard_key"`
+	ReportingType   string `json:"reporting_type"` // "chat" by default
+	SlackURL        string `json:"slack_url"`      // chat.SlackURL by default
+	SlackToken      string `json:"slack_token"`
+	MatrixURL       string `json:"matrix_url"` // the homeserver
+	MatrixToken     string `json:"matrix_token"`
+	PollPeriod      int    `json:"poll_period"` // in seconds, 1 minute by default
+}
+
+// reportingConfig is ChatConfig of the dashboard passed in dashapi.BugReport.Config.
+type reportingConfig struct {
+	Channel    string
+	Subsystems map[string]string
+}
+
+func main() {
+	var (
+		flagConfig = flag.String("config", "", "config file")
+		flagOnce   = flag.Bool("once", false, "sync once and exit")
+	)
+	flag.Parse()
+	cfg := &Config{
+		ReportingType: "chat",
+		PollPeriod:    60,
+	}
+	if err := config.LoadFile(*flagConfig, cfg); err != nil {
+		log.Fatal(err)
+	}
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &syncer{
+		cfg:     cfg,
+		dash:    dash,
+		clients: make(map[string]chat.Client),
+	}
+	if cfg.SlackToken != "" {
+		s.clients["slack"] = chat.NewSlack(cfg.SlackURL, cfg.SlackToken)
+	}
+	if cfg.MatrixURL != "" {
+		s.clients["matrix"] = chat.NewMatrix(cfg.MatrixURL, cfg.MatrixToken)
+	}
+	ctx := context.Background()
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("sync failed: %v", err)
+		}
+		if *flagOnce {
+			return
+		}
+		time.Sleep(time.Duration(cfg.PollPeriod) * time.Second)
+	}
+}
+
+type syncer struct {
+	cfg     *Config
+	dash    *dashapi.Dashboard
+	clients map[string]chat.Client // by the channel type
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollBugs(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return fmt.Errorf("failed to poll bugs: %w", err)
+	}
+	for _, rep := range resp.Reports {
+		// A failure to post one bug should not prevent posting of the rest.
+		if err := s.report(ctx, rep); err != nil {
+			log.Errorf("failed to report %q: %v", rep.Title, err)
+		}
+	}
+	notifs, err := s.dash.ReportingPollNotifications(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return fmt.Errorf("failed to poll notifications: %w", err)
+	}
+	for _, notif := range notifs.Notifications {
+		if err := s.dash.ReportingUpdateOK(ctx, dashapi.NotificationUpdate(notif)); err != nil {
+			log.Errorf("failed to handle notification for %q: %v", notif.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) report(ctx context.Context, rep *dashapi.BugReport) error {
+	if rep.JobID != "" {
+		// Job results are of no interest for chats.
+		return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{JobID: rep.JobID})
+	}
+	repCfg := new(reportingConfig)
+	if err := json.Unmarshal(rep.Config, repCfg); err != nil {
+		return fmt.Errorf("failed to unmarshal reporting config: %w", err)
+	}
+	msg := message(rep)
+	for _, channel := range channels(repCfg, rep) {
+		typ, name, _ := strings.Cut(channel, ":")
+		client := s.clients[typ]
+		if client == nil {
+			return fmt.Errorf("no %v credentials for channel %v", typ, channel)
+		}
+		if err := client.Post(ctx, name, msg); err != nil {
+			return fmt.Errorf("failed to post to %v: %w", channel, err)
+		}
+	}
+	reproLevel := dashapi.ReproLevelNone
+	if len(rep.ReproC) != 0 {
+		reproLevel = dashapi.ReproLevelC
+	} else if len(rep.ReproSyz) != 0 {
+		reproLevel = dashapi.ReproLevelSyz
+	}
+	return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{
+		ID:         rep.ID,
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: reproLevel,
+		CrashID:    rep.CrashID,
+	})
+}
+
+// channels returns the channels of the bug subsystems, or the default channel if there are none.
+func channels(repCfg *reportingConfig, rep *dashapi.BugReport) []string {
+	var ret []string
+	dedup := make(map[string]bool)
+	for _, subsystem := range rep.Subsystems {
+		if channel := repCfg.Subsystems[subsystem.Name]; channel != "" && !dedup[channel] {
+			dedup[channel] = true
+			ret = append(ret, channel)
+		}
+	}
+	if len(ret) == 0 {
+		ret = append(ret, repCfg.Channel)
+	}
+	return ret
+}
+
+func message(rep *dashapi.BugReport) *chat.Message {
+	title := "New bug: " + rep.Title
+	if rep.Type == dashapi.ReportRepro {
+		title = "Reproducer found: " + rep.Title
+	}
+	msg := &chat.Message{
+		ID:    fmt.Sprintf("syzbot-%v-%v", rep.ID, rep.CrashID),
+		Title: title,
+		Link:  rep.Link,
+	}
+	var subsystems []string
+	for _, subsystem := range rep.Subsystems {
+		subsystems = append(subsystems, subsystem.Name)
+	}
+	if len(subsystems) != 0 {
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Subsystems", Value: strings.Join(subsystems, ", ")})
+	}
+	var maintainers []string
+	for _, recipient := range rep.Recipients {
+		if recipient.Type == dashapi.To {
+			maintainers = append(maintainers, recipient.Address.Address)
+		}
+	}
+	if len(maintainers) == 0 {
+		maintainers = rep.Maintainers
+	}
+	if len(maintainers) != 0 {
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Maintainers", Value: strings.Join(maintainers, ", ")})
+	}
+	if rep.KernelCommit != "" {
+		msg.Fields = append(msg.Fields, chat.Field{
+			Name:  "Kernel",
+			Value: fmt.Sprintf("%v %.12v %v", rep.KernelRepoAlias, rep.KernelCommit, rep.KernelCommitTitle),
+		})
+	}
+	switch {
+	case len(rep.ReproC) != 0:
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Reproducer", Value: "C", Link: rep.ReproCLink})
+	case len(rep.ReproSyz) != 0:
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Reproducer", Value: "syz", Link: rep.ReproSyzLink})
+	default:
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Reproducer", Value: "none"})
+	}
+	if rep.LogLink != "" {
+		msg.Fields = append(msg.Fields, chat.Field{Name: "Console output", Value: "log", Link: rep.LogLink})
+	}
+	return msg
+}
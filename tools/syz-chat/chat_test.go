@@ -0,0 +1,88 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/dashboard/dashapi/dashapitest"
+	"github.com/google/syzkaller/pkg/chat"
+)
+
+type testClient struct {
+	posts []string
+}
+
+func (c *testClient) Post(ctx context.Context, channel string, msg *chat.Message) error {
+	c.posts = append(c.posts, channel+": "+msg.Title)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	dash := dashapitest.NewServer(t)
+	dash.Handle("reporting_poll_bugs", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollBugsResponse{Reports: []*dashapi.BugReport{
+			{
+				Type:       dashapi.ReportNew,
+				ID:         "id1",
+				Title:      "WARNING in foo",
+				Config:     []byte(`{"Channel": "slack:bugs", "Subsystems": {"net": "matrix:!net", "fs": "slack:fs"}}`),
+				Subsystems: []dashapi.BugSubsystem{{Name: "net"}, {Name: "mm"}, {Name: "fs"}},
+				CrashID:    1,
+			},
+			{
+				Type:     dashapi.ReportRepro,
+				ID:       "id2",
+				Title:    "KASAN: use-after-free in bar",
+				Config:   []byte(`{"Channel": "slack:bugs"}`),
+				ReproC:   []byte("int main() {}"),
+				ReproSyz: []byte("bar()"),
+				CrashID:  2,
+			},
+		}}, nil
+	})
+	dash.Handle("reporting_poll_notifs", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollNotificationsResponse{Notifications: []*dashapi.BugNotification{
+			{Type: dashapi.BugNotifLabel, ID: "id3", Label: "prio:low"},
+		}}, nil
+	})
+	dash.Handle("reporting_update", func(payload []byte) (interface{}, error) {
+		return &dashapi.BugUpdateReply{OK: true}, nil
+	})
+	slack, matrix := new(testClient), new(testClient)
+	s := &syncer{
+		cfg:     &Config{ReportingType: "chat"},
+		dash:    dash.NewClient(),
+		clients: map[string]chat.Client{"slack": slack, "matrix": matrix},
+	}
+	if err := s.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wantSlack := []string{"fs: New bug: WARNING in foo", "bugs: Reproducer found: KASAN: use-after-free in bar"}
+	if diff := cmp.Diff(wantSlack, slack.posts); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"!net: New bug: WARNING in foo"}, matrix.posts); diff != "" {
+		t.Error(diff)
+	}
+	var updates []*dashapi.BugUpdate
+	for _, req := range dash.Requests("reporting_update") {
+		upd := new(dashapi.BugUpdate)
+		if err := req.Decode(upd); err != nil {
+			t.Fatal(err)
+		}
+		updates = append(updates, upd)
+	}
+	wantUpdates := []*dashapi.BugUpdate{
+		{ID: "id1", Status: dashapi.BugStatusOpen, ReproLevel: dashapi.ReproLevelNone, CrashID: 1},
+		{ID: "id2", Status: dashapi.BugStatusOpen, ReproLevel: dashapi.ReproLevelC, CrashID: 2},
+		{ID: "id3", Status: dashapi.BugStatusOpen, Labels: []string{"prio:low"}, Notification: true},
+	}
+	if diff := cmp.Diff(wantUpdates, updates); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -105,7 +105,7 @@ func saveCoverage(dashboard, clientName string, d *dashapi.MergedCoverage) error
 	if err != nil {
 		log.Fatalf("failed dashapi.New(): %v", err)
 	}
-	return dash.SaveCoverage(&dashapi.SaveCoverageReq{
+	return dash.SaveCoverage(context.Background(), &dashapi.SaveCoverageReq{
 		Coverage: d,
 	})
 }
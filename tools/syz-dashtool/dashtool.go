@@ -0,0 +1,161 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-dashtool provides command line access to the dashboard API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/pkg/tool"
+)
+
+var (
+	flagDashboard = flag.String("dashboard", "https://syzkaller.appspot.com", "dashboard address")
+	flagAPIClient = flag.String("client", "", "the name of the API client")
+	flagAPIKey    = flag.String("key", "", "api key")
+	flagKeys      = flag.String("payload-keys", "", "JSON file with keys for encrypted payloads")
+)
+
+func main() {
+	var (
+		flagOutput   = flag.String("output", ".", "output directory for downloaded files")
+		flagNoConfig = flag.Bool("no-config", false, "don't download kernel config")
+	)
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+	}
+	var opts []dashapi.DashboardOpts
+	var keys *dashapi.PayloadKeys
+	if *flagKeys != "" {
+		var err error
+		if keys, err = dashapi.LoadPayloadKeys(*flagKeys); err != nil {
+			tool.Fail(err)
+		}
+		opts = append(opts, keys)
+	}
+	if args[0] == "decrypt" {
+		if len(args) != 3 || keys == nil {
+			usage()
+		}
+		decrypt(keys, args[1], args[2])
+		return
+	}
+	dash, err := dashapi.New(*flagAPIClient, *flagDashboard, *flagAPIKey, opts...)
+	if err != nil {
+		tool.Failf("dashapi failed: %v", err)
+	}
+	switch args[0] {
+	case "get-repro":
+		if len(args) != 2 {
+			usage()
+		}
+		getRepro(dash, args[1], *flagOutput, *flagNoConfig)
+	case "bisect":
+		if len(args) != 3 && len(args) != 4 {
+			usage()
+		}
+		manager := ""
+		if len(args) == 4 {
+			manager = args[3]
+		}
+		bisect(dash, args[1], args[2], manager)
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: syz-dashtool [flags] command args...
+  -dashboard string
+  -client string
+  -key string
+  -payload-keys string
+  -output string
+  -no-config
+
+  commands:
+  downloading the best reproducer for a bug into the output directory:
+    syz-dashtool get-repro bug-id
+  queueing a cause or fix bisection for a bug (optionally only on the given manager):
+    syz-dashtool bisect bug-id cause|fix [manager]
+  decrypting a payload downloaded from the dashboard (requires -payload-keys):
+    syz-dashtool decrypt input-file output-file
+`)
+	os.Exit(1)
+}
+
+func getRepro(dash *dashapi.Dashboard, bugID, dir string, noConfig bool) {
+	repro, err := dash.GetRepro(context.Background(), bugID, dashapi.NoKernelConfig(noConfig))
+	if errors.Is(err, dashapi.ErrReproNotFound) {
+		tool.Failf("bug %v has no reproducer", bugID)
+	}
+	if err != nil {
+		tool.Failf("failed to get repro: %v", err)
+	}
+	if err := osutil.MkdirAll(dir); err != nil {
+		tool.Fail(err)
+	}
+	files := map[string][]byte{
+		"repro.syz":     repro.Syz,
+		"repro.c":       repro.C,
+		"repro.opts":    repro.Opts,
+		"kernel.config": repro.KernelConfig,
+	}
+	for name, data := range files {
+		if len(data) == 0 {
+			continue
+		}
+		file := filepath.Join(dir, name)
+		if err := osutil.WriteFile(file, data); err != nil {
+			tool.Fail(err)
+		}
+		fmt.Printf("written %v\n", file)
+	}
+	fmt.Printf("build: %v\n", repro.BuildID)
+}
+
+func bisect(dash *dashapi.Dashboard, bugID, typ, manager string) {
+	req := &dashapi.QueueBisectReq{
+		BugID:   bugID,
+		Manager: manager,
+	}
+	switch typ {
+	case "cause":
+		req.Type = dashapi.JobBisectCause
+	case "fix":
+		req.Type = dashapi.JobBisectFix
+	default:
+		usage()
+	}
+	resp, err := dash.QueueBisect(context.Background(), req)
+	if err != nil {
+		tool.Failf("failed to queue bisection: %v", err)
+	}
+	if resp.Refusal != "" {
+		tool.Failf("bisection refused (%v): %v", resp.Refusal, resp.Text)
+	}
+	fmt.Printf("queued job %v\n", resp.JobID)
+}
+
+func decrypt(keys *dashapi.PayloadKeys, input, output string) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		tool.Fail(err)
+	}
+	if data, err = keys.Decrypt(data); err != nil {
+		tool.Fail(err)
+	}
+	if err := osutil.WriteFile(output, data); err != nil {
+		tool.Fail(err)
+	}
+}
@@ -7,6 +7,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"sync"
@@ -31,7 +32,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("dashapi failed: %v", err)
 	}
-	resp, err := dash.BugList()
+	resp, err := dash.BugList(context.Background())
 	if err != nil {
 		log.Fatalf("bug list query failed: %v", err)
 	}
@@ -72,7 +73,7 @@ func processReport(dash *dashapi.Dashboard, bugReport *dashapi.BugReport, bugID
 		log.Printf("%v: no guilty files extracted", bugReport.ID)
 		return
 	}
-	err = dash.UpdateReport(&dashapi.UpdateReportReq{
+	err = dash.UpdateReport(context.Background(), &dashapi.UpdateReportReq{
 		BugID:       bugID,
 		CrashID:     bugReport.CrashID,
 		GuiltyFiles: &[]string{guiltyFile},
@@ -101,7 +102,7 @@ func loadBugReports(dash *dashapi.Dashboard, IDs []string) <-chan *workItem {
 		go func() {
 			defer wg.Done()
 			for id := range ids {
-				resp, err := dash.LoadBug(id)
+				resp, err := dash.LoadBug(context.Background(), id)
 				if err != nil {
 					log.Printf("%v: failed to load bug: %v", id, err)
 					continue
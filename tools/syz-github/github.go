@@ -0,0 +1,441 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-github is an external reporting for the dashboard that mirrors bugs into GitHub issues.
+// It's meant for targets whose communities live on GitHub rather than on mailing lists.
+// It polls new bug reports of the reporting type and files issues for them (later reports
+// of the same bug are added as comments), labels the issues with the bug subsystems and severity,
+// closes the issues once the bugs are closed on the dashboard and closes the dashboard bugs whose
+// issues were closed as not planned. The reporting on the dashboard needs GitHubConfig
+// (see dashboard/app/reporting_github.go), the issues carry the reporting ID in a hidden marker.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/config"
+	"github.com/google/syzkaller/pkg/github"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+type Config struct {
+	DashboardAddr   string `json:"dashboard_addr"`
+	DashboardClient string `json:"dashboard_client"`
+	DashboardKey    string `json:"dashboard_key"`
+	ReportingType   string `json:"reporting_type"` // "github" by default
+	GitHubURL       string `json:"github_url"`     // github.DefaultURL by default
+	GitHubToken     string `json:"github_token"`
+	// Repositories ("owner/repo") the issues are synced in, the same as in the reporting configs.
+	Repos []string `json:"repos"`
+	// The label all filed issues have, "syzbot" by default.
+	Label      string `json:"label"`
+	PollPeriod int    `json:"poll_period"` // in seconds, 5 minutes by default
+}
+
+// reportingConfig is GitHubConfig of the dashboard passed in dashapi.BugReport.Config.
+type reportingConfig struct {
+	Owner       string
+	Repo        string
+	Labels      []string
+	UploadGists bool
+}
+
+const extIDPrefix = "github-"
+
+var markerRe = regexp.MustCompile(`<!-- syzbot:([^ ]+) -->`)
+
+func main() {
+	var (
+		flagConfig = flag.String("config", "", "config file")
+		flagOnce   = flag.Bool("once", false, "sync once and exit")
+	)
+	flag.Parse()
+	cfg := &Config{
+		ReportingType: "github",
+		Label:         "syzbot",
+		PollPeriod:    300,
+	}
+	if err := config.LoadFile(*flagConfig, cfg); err != nil {
+		log.Fatal(err)
+	}
+	for _, repo := range cfg.Repos {
+		if _, _, ok := strings.Cut(repo, "/"); !ok {
+			log.Fatalf("bad repository %q, expected owner/repo", repo)
+		}
+	}
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &syncer{
+		cfg:  cfg,
+		dash: dash,
+		gh:   github.NewClient(cfg.GitHubURL, cfg.GitHubToken),
+	}
+	ctx := context.Background()
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("sync failed: %v", err)
+		}
+		if *flagOnce {
+			return
+		}
+		time.Sleep(time.Duration(cfg.PollPeriod) * time.Second)
+	}
+}
+
+type syncer struct {
+	cfg  *Config
+	dash *dashapi.Dashboard
+	gh   *github.Client
+	// Issues closed since the last successful sync are closed on the dashboard.
+	lastSync time.Time
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	start := time.Now()
+	if err := s.reportNew(ctx); err != nil {
+		return fmt.Errorf("failed to report new bugs: %w", err)
+	}
+	if err := s.notify(ctx); err != nil {
+		return fmt.Errorf("failed to handle notifications: %w", err)
+	}
+	for _, repo := range s.cfg.Repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		if err := s.syncClosed(ctx, owner, name); err != nil {
+			return fmt.Errorf("failed to sync closed bugs in %v: %w", repo, err)
+		}
+		if err := s.syncResolved(ctx, owner, name); err != nil {
+			return fmt.Errorf("failed to sync closed issues in %v: %w", repo, err)
+		}
+	}
+	s.lastSync = start
+	return nil
+}
+
+func (s *syncer) reportNew(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollBugs(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, rep := range resp.Reports {
+		// A failure to report one bug should not prevent reporting of the rest.
+		if err := s.report(ctx, rep); err != nil {
+			log.Errorf("failed to report %q: %v", rep.Title, err)
+		}
+	}
+	return nil
+}
+
+// notify handles the notifications without commenting on the issues:
+// the issues of the bugs closed or upstreamed as the result get closed by syncClosed.
+func (s *syncer) notify(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollNotifications(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, notif := range resp.Notifications {
+		if err := s.dash.ReportingUpdateOK(ctx, dashapi.NotificationUpdate(notif)); err != nil {
+			log.Errorf("failed to handle notification for %q: %v", notif.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) report(ctx context.Context, rep *dashapi.BugReport) error {
+	repCfg := new(reportingConfig)
+	if err := json.Unmarshal(rep.Config, repCfg); err != nil {
+		return fmt.Errorf("failed to unmarshal reporting config: %w", err)
+	}
+	owner, repo, number, _ := parseExtID(rep.ExtID)
+	if rep.JobID != "" {
+		// Job results are posted to the issue the job was started for, if it was filed by us.
+		if number != 0 {
+			if err := s.gh.AddComment(ctx, owner, repo, number, jobComment(rep)); err != nil {
+				return err
+			}
+		}
+		return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{JobID: rep.JobID})
+	}
+	reproLevel := dashapi.ReproLevelNone
+	if len(rep.ReproC) != 0 {
+		reproLevel = dashapi.ReproLevelC
+	} else if len(rep.ReproSyz) != 0 {
+		reproLevel = dashapi.ReproLevelSyz
+	}
+	gist, err := s.uploadGist(ctx, repCfg, rep)
+	if err != nil {
+		return err
+	}
+	body := issueBody(rep, gist)
+	labels := s.labels(repCfg, rep)
+	link := ""
+	if number == 0 {
+		owner, repo = repCfg.Owner, repCfg.Repo
+		issue, err := s.gh.CreateIssue(ctx, owner, repo, &github.NewIssue{
+			Title:  rep.Title,
+			Body:   fmt.Sprintf("%v\n<!-- syzbot:%v -->\n", body, rep.ID),
+			Labels: labels,
+		})
+		if err != nil {
+			return err
+		}
+		number, link = issue.Number, issue.HTMLURL
+		// Record the issue right away, otherwise it's filed again if something below fails.
+		err = s.dash.SetExtID(ctx, &dashapi.SetExtIDReq{ID: rep.ID, ExtID: formatExtID(owner, repo, number)})
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := s.gh.AddComment(ctx, owner, repo, number, body); err != nil {
+			return err
+		}
+		// Subsystems and severity may have changed since the issue was filed.
+		if err := s.gh.AddLabels(ctx, owner, repo, number, labels); err != nil {
+			return err
+		}
+	}
+	return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{
+		ID:         rep.ID,
+		ExtID:      formatExtID(owner, repo, number),
+		Link:       link,
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: reproLevel,
+		CrashID:    rep.CrashID,
+	})
+}
+
+func (s *syncer) labels(repCfg *reportingConfig, rep *dashapi.BugReport) []string {
+	labels := append([]string{s.cfg.Label}, repCfg.Labels...)
+	for _, subsystem := range rep.Subsystems {
+		labels = append(labels, "subsystem:"+subsystem.Name)
+	}
+	if rep.Severity != dashapi.SeverityUnknown && rep.Severity.Known() {
+		labels = append(labels, "severity:"+rep.Severity.String())
+	}
+	return labels
+}
+
+// uploadGist uploads the reproducers as a secret gist and returns the link to it.
+func (s *syncer) uploadGist(ctx context.Context, repCfg *reportingConfig, rep *dashapi.BugReport) (string, error) {
+	if !repCfg.UploadGists || rep.EncryptedPayloads || len(rep.ReproSyz) == 0 && len(rep.ReproC) == 0 {
+		return "", nil
+	}
+	gist := &github.Gist{
+		Description: rep.Title,
+		Files:       make(map[string][]byte),
+	}
+	if len(rep.ReproSyz) != 0 {
+		gist.Files["repro.syz"] = rep.ReproSyz
+	}
+	if len(rep.ReproC) != 0 {
+		gist.Files["repro.c"] = rep.ReproC
+	}
+	return s.gh.CreateGist(ctx, gist)
+}
+
+// syncClosed closes the issues whose bugs were closed on the dashboard.
+func (s *syncer) syncClosed(ctx context.Context, owner, repo string) error {
+	issues, err := s.gh.ListIssues(ctx, owner, repo, &github.IssueQuery{
+		Labels: []string{s.cfg.Label},
+		State:  github.StateOpen,
+	})
+	if err != nil {
+		return err
+	}
+	closed, byID, err := s.pollClosed(ctx, issues)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		issue := byID[id]
+		if err := s.closeIssue(ctx, owner, repo, issue, id); err != nil {
+			log.Errorf("failed to close issue %v/%v#%v: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) closeIssue(ctx context.Context, owner, repo string, issue *github.Issue, id string) error {
+	extBug, err := s.lookup(ctx, formatExtID(owner, repo, issue.Number), id)
+	if err != nil {
+		return err
+	}
+	upd := &github.IssueUpdate{
+		State:       github.StateClosed,
+		StateReason: github.ReasonNotPlanned,
+	}
+	var comment string
+	switch extBug.Status {
+	case dashapi.BugStatusFixed:
+		upd.StateReason = github.ReasonCompleted
+		comment = fmt.Sprintf("The bug is fixed: %v", extBug.Link)
+	case dashapi.BugStatusInvalid:
+		comment = fmt.Sprintf("The bug is closed as invalid: %v", extBug.Link)
+	case dashapi.BugStatusDup:
+		comment = fmt.Sprintf("The bug is closed as a duplicate: %v", extBug.Link)
+		// GitHub marks the issues as duplicates if the comment has the reference.
+		if dupOwner, dupRepo, dupNumber, ok := parseExtID(extBug.DupOf); ok &&
+			dupOwner == owner && dupRepo == repo {
+			comment = fmt.Sprintf("Duplicate of #%v\n\n%v", dupNumber, comment)
+		}
+	default:
+		// The bug is still open, but it's not reported in this reporting anymore.
+		comment = fmt.Sprintf("The bug is not tracked here anymore: %v", extBug.Link)
+	}
+	if err := s.gh.AddComment(ctx, owner, repo, issue.Number, comment); err != nil {
+		return err
+	}
+	return s.gh.UpdateIssue(ctx, owner, repo, issue.Number, upd)
+}
+
+// syncResolved closes the dashboard bugs whose issues were closed as not planned.
+// Issues closed as completed are left alone, the dashboard needs the title of the fixing commit.
+func (s *syncer) syncResolved(ctx context.Context, owner, repo string) error {
+	issues, err := s.gh.ListIssues(ctx, owner, repo, &github.IssueQuery{
+		Labels: []string{s.cfg.Label},
+		State:  github.StateClosed,
+		Since:  s.lastSync,
+	})
+	if err != nil {
+		return err
+	}
+	// Closed bugs were closed on GitHub by syncClosed.
+	closed, byID, err := s.pollClosed(ctx, issues)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		delete(byID, id)
+	}
+	for id, issue := range byID {
+		if issue.StateReason != github.ReasonNotPlanned {
+			log.Logf(0, "issue %v/%v#%v is closed as %q, close the bug on the dashboard",
+				owner, repo, issue.Number, issue.StateReason)
+			continue
+		}
+		if err := s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{ID: id, Status: dashapi.BugStatusInvalid}); err != nil {
+			log.Errorf("failed to close the bug of issue %v/%v#%v: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
+
+// pollClosed returns the reporting IDs of the bugs that are closed on the dashboard
+// and all the issues filed by us by their reporting IDs.
+func (s *syncer) pollClosed(ctx context.Context, issues []*github.Issue) (
+	[]string, map[string]*github.Issue, error) {
+	byID := make(map[string]*github.Issue)
+	var ids []string
+	for _, issue := range issues {
+		if match := markerRe.FindStringSubmatch(issue.Body); match != nil {
+			byID[match[1]] = issue
+			ids = append(ids, match[1])
+		}
+	}
+	if len(ids) == 0 {
+		return nil, byID, nil
+	}
+	closed, err := s.dash.ReportingPollClosed(ctx, ids)
+	return closed, byID, err
+}
+
+func (s *syncer) lookup(ctx context.Context, extID, id string) (*dashapi.ExtIDBug, error) {
+	extBugs, err := s.dash.LookupExtID(ctx, extID)
+	if err != nil {
+		return nil, err
+	}
+	for _, extBug := range extBugs {
+		if extBug.ID == id {
+			return extBug, nil
+		}
+	}
+	return nil, fmt.Errorf("the dashboard has no bug %v for reporting %v", extID, id)
+}
+
+func formatExtID(owner, repo string, number int) string {
+	return fmt.Sprintf("%v%v/%v#%v", extIDPrefix, owner, repo, number)
+}
+
+func parseExtID(extID string) (string, string, int, bool) {
+	str, ok := strings.CutPrefix(extID, extIDPrefix)
+	if !ok {
+		return "", "", 0, false
+	}
+	repo, num, ok := strings.Cut(str, "#")
+	if !ok {
+		return "", "", 0, false
+	}
+	owner, repo, ok := strings.Cut(repo, "/")
+	if !ok {
+		return "", "", 0, false
+	}
+	number, err := strconv.Atoi(num)
+	if err != nil || number <= 0 {
+		return "", "", 0, false
+	}
+	return owner, repo, number, true
+}
+
+const encryptedReportNote = "The report is encrypted, see the dashboard link."
+
+func issueBody(rep *dashapi.BugReport, gist string) string {
+	buf := new(bytes.Buffer)
+	if rep.Type == dashapi.ReportRepro {
+		fmt.Fprintf(buf, "syzbot has found a reproducer for the bug.\n\n")
+	} else {
+		fmt.Fprintf(buf, "syzbot has found the following issue on:\n\n")
+	}
+	fmt.Fprintf(buf, "HEAD commit:    %v %v\n", rep.KernelCommit, rep.KernelCommitTitle)
+	fmt.Fprintf(buf, "git tree:       %v\n", rep.KernelRepoAlias)
+	fmt.Fprintf(buf, "dashboard link: %v\n", rep.Link)
+	if rep.LogLink != "" {
+		fmt.Fprintf(buf, "console output: %v\n", rep.LogLink)
+	}
+	if rep.KernelConfigLink != "" {
+		fmt.Fprintf(buf, "kernel config:  %v\n", rep.KernelConfigLink)
+	}
+	if gist != "" {
+		fmt.Fprintf(buf, "reproducers:    %v\n", gist)
+	} else {
+		if rep.ReproSyzLink != "" {
+			fmt.Fprintf(buf, "syz repro:      %v\n", rep.ReproSyzLink)
+		}
+		if rep.ReproCLink != "" {
+			fmt.Fprintf(buf, "C reproducer:   %v\n", rep.ReproCLink)
+		}
+	}
+	if rep.EncryptedPayloads {
+		fmt.Fprintf(buf, "\n%v\n", encryptedReportNote)
+	} else {
+		fmt.Fprintf(buf, "\n```\n%s```\n", rep.Report)
+	}
+	return buf.String()
+}
+
+func jobComment(rep *dashapi.BugReport) string {
+	buf := new(bytes.Buffer)
+	switch {
+	case len(rep.Error) != 0:
+		fmt.Fprintf(buf, "syzbot failed to run the job:\n\n```\n%s```\n", rep.Error)
+	case rep.CrashTitle != "" && rep.EncryptedPayloads:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed: %v\n\n%v\n", rep.CrashTitle, encryptedReportNote)
+	case rep.CrashTitle != "":
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed: %v\n\n```\n%s```\n", rep.CrashTitle, rep.Report)
+	default:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel did not crash.\n")
+	}
+	if rep.Link != "" {
+		fmt.Fprintf(buf, "\ndashboard link: %v\n", rep.Link)
+	}
+	return buf.String()
+}
@@ -0,0 +1,25 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestExtID(t *testing.T) {
+	extID := formatExtID("google", "syzkaller", 42)
+	if extID != "github-google/syzkaller#42" {
+		t.Fatalf("bad ext ID %q", extID)
+	}
+	owner, repo, number, ok := parseExtID(extID)
+	if !ok || owner != "google" || repo != "syzkaller" || number != 42 {
+		t.Fatalf("bad round trip: %v %v %v %v", owner, repo, number, ok)
+	}
+	for _, bad := range []string{"", "google/syzkaller#42", "github-google#42", "github-google/syzkaller",
+		"github-google/syzkaller#0", "github-google/syzkaller#x", "bugzilla-1"} {
+		if _, _, _, ok := parseExtID(bad); ok {
+			t.Errorf("%q is parsed", bad)
+		}
+	}
+}
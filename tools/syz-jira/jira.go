@@ -0,0 +1,502 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-jira is an external reporting for the dashboard that files bugs in Jira.
+// It polls new bug reports of the reporting type and creates issues for them in the project
+// selected by the reporting config (later reports of the same bug are added as comments),
+// resolves the issues once the bugs are closed on the dashboard and closes the dashboard bugs
+// whose issues were resolved in Jira with one of the configured resolutions.
+// The issue fields are rendered from templates, so that the issues fit into the existing
+// triage workflow of the team. The reporting on the dashboard needs JiraConfig
+// (see dashboard/app/reporting_jira.go), the issues are labeled with the reporting ID.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/config"
+	"github.com/google/syzkaller/pkg/jira"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+type Config struct {
+	DashboardAddr   string `json:"dashboard_addr"`
+	DashboardClient string `json:"dashboard_client"`
+	DashboardKey    string `json:"dashboard_key"`
+	ReportingType   string `json:"reporting_type"` // "jira" by default
+	JiraURL         string `json:"jira_url"`
+	JiraUser        string `json:"jira_user"` // empty for personal access tokens
+	JiraToken       string `json:"jira_token"`
+	// The label all filed issues have, "syzbot" by default.
+	// The issues also have the label-<reporting ID> label.
+	Label string `json:"label"`
+	// Templates of the issue fields by field ID (e.g. "summary", "priority", "customfield_10010")
+	// executed with the dashapi.BugReport. Rendered JSON objects and arrays are sent as is
+	// (e.g. {"name": "High"} for "priority"), the rest as strings.
+	// "summary" and "description" have defaults.
+	Fields map[string]string `json:"fields"`
+	// The workflow transition that resolves issues, "Done" by default.
+	CloseTransition string `json:"close_transition"`
+	// Resolutions for the issues whose bugs were closed on the dashboard by the status of the bug
+	// ("fixed", "invalid", "dup" or "moved" if the bug is not reported here anymore).
+	// The transition sets its default resolution for the missing statuses.
+	CloseResolutions map[string]string `json:"close_resolutions"`
+	// The dashboard statuses ("invalid" or "dup") for the issues resolved in Jira by resolution name,
+	// issues resolved with other resolutions are left alone. See defaultResolutions.
+	Resolutions map[string]string `json:"resolutions"`
+	PollPeriod  int               `json:"poll_period"` // in seconds, 5 minutes by default
+}
+
+// reportingConfig is JiraConfig of the dashboard passed in dashapi.BugReport.Config.
+type reportingConfig struct {
+	Project    string
+	IssueType  string
+	Subsystems map[string]struct {
+		Project   string
+		IssueType string
+	}
+}
+
+const (
+	statusFixed   = "fixed"
+	statusInvalid = "invalid"
+	statusDup     = "dup"
+	statusMoved   = "moved"
+
+	extIDPrefix = "jira-"
+)
+
+var defaultResolutions = map[string]string{
+	"Won't Do":         statusInvalid,
+	"Won't Fix":        statusInvalid,
+	"Cannot Reproduce": statusInvalid,
+	"Not a Bug":        statusInvalid,
+	"Duplicate":        statusDup,
+}
+
+var defaultFields = map[string]string{
+	"summary": "{{.Title}}",
+	"description": `syzbot has found the following issue on:
+
+HEAD commit:    {{.KernelCommit}} {{.KernelCommitTitle}}
+git tree:       {{.KernelRepoAlias}}
+dashboard link: {{.Link}}
+{{- if .LogLink}}
+console output: {{.LogLink}}{{end}}
+{{- if .KernelConfigLink}}
+kernel config:  {{.KernelConfigLink}}{{end}}
+{{- if or .ReproSyz .ReproC}}
+The reproducers are attached to the issue.{{end}}
+
+{noformat}
+{{printf "%s" .Report}}{noformat}`,
+}
+
+func main() {
+	var (
+		flagConfig = flag.String("config", "", "config file")
+		flagOnce   = flag.Bool("once", false, "sync once and exit")
+	)
+	flag.Parse()
+	cfg := &Config{
+		ReportingType:   "jira",
+		Label:           "syzbot",
+		CloseTransition: "Done",
+		PollPeriod:      300,
+	}
+	if err := config.LoadFile(*flagConfig, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Resolutions == nil {
+		cfg.Resolutions = defaultResolutions
+	}
+	templates, err := parseFields(cfg.Fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := &syncer{
+		cfg:       cfg,
+		dash:      dash,
+		jira:      jira.NewClient(cfg.JiraURL, cfg.JiraUser, cfg.JiraToken),
+		templates: templates,
+	}
+	ctx := context.Background()
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("sync failed: %v", err)
+		}
+		if *flagOnce {
+			return
+		}
+		time.Sleep(time.Duration(cfg.PollPeriod) * time.Second)
+	}
+}
+
+func parseFields(fields map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+	for _, set := range []map[string]string{defaultFields, fields} {
+		for field, text := range set {
+			tmpl, err := template.New(field).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("bad template for field %v: %w", field, err)
+			}
+			templates[field] = tmpl
+		}
+	}
+	return templates, nil
+}
+
+type syncer struct {
+	cfg       *Config
+	dash      *dashapi.Dashboard
+	jira      *jira.Client
+	templates map[string]*template.Template
+	// Issues resolved since the last successful sync are closed on the dashboard.
+	lastSync time.Time
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	start := time.Now()
+	if err := s.reportNew(ctx); err != nil {
+		return fmt.Errorf("failed to report new bugs: %w", err)
+	}
+	if err := s.notify(ctx); err != nil {
+		return fmt.Errorf("failed to handle notifications: %w", err)
+	}
+	if err := s.syncClosed(ctx); err != nil {
+		return fmt.Errorf("failed to sync closed bugs: %w", err)
+	}
+	if err := s.syncResolved(ctx); err != nil {
+		return fmt.Errorf("failed to sync resolved issues: %w", err)
+	}
+	s.lastSync = start
+	return nil
+}
+
+func (s *syncer) reportNew(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollBugs(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, rep := range resp.Reports {
+		// A failure to report one bug should not prevent reporting of the rest.
+		if err := s.report(ctx, rep); err != nil {
+			log.Errorf("failed to report %q: %v", rep.Title, err)
+		}
+	}
+	return nil
+}
+
+// notify accepts the notifications right away; if a bug gets closed as obsolete
+// or moves on to the next reporting, its issue is transitioned in syncClosed.
+func (s *syncer) notify(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollNotifications(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, notif := range resp.Notifications {
+		if err := s.dash.ReportingUpdateOK(ctx, dashapi.NotificationUpdate(notif)); err != nil {
+			log.Errorf("failed to handle notification for %q: %v", notif.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) report(ctx context.Context, rep *dashapi.BugReport) error {
+	key, ok := strings.CutPrefix(rep.ExtID, extIDPrefix)
+	if !ok {
+		key = ""
+	}
+	if rep.JobID != "" {
+		// Job results are posted to the issue the job was started for, if it was filed by us.
+		if key != "" {
+			if err := s.jira.AddComment(ctx, key, jobComment(rep)); err != nil {
+				return err
+			}
+		}
+		return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{JobID: rep.JobID})
+	}
+	if key == "" {
+		fields, err := s.issueFields(rep)
+		if err != nil {
+			return err
+		}
+		key, err = s.jira.CreateIssue(ctx, fields)
+		if err != nil {
+			return err
+		}
+		// Record the issue right away, otherwise it's filed again if something below fails.
+		err = s.dash.SetExtID(ctx, &dashapi.SetExtIDReq{ID: rep.ID, ExtID: extIDPrefix + key})
+		if err != nil {
+			return err
+		}
+	} else {
+		description, err := s.render("description", rep)
+		if err != nil {
+			return err
+		}
+		comment, ok := description.(string)
+		if !ok {
+			comment = fmt.Sprintf("syzbot has a new report for the bug: %v", rep.Link)
+		}
+		if err := s.jira.AddComment(ctx, key, comment); err != nil {
+			return err
+		}
+	}
+	reproLevel := dashapi.ReproLevelNone
+	// Encrypted reproducers are only linked from the dashboard.
+	if len(rep.ReproSyz) != 0 {
+		reproLevel = dashapi.ReproLevelSyz
+		if !rep.EncryptedPayloads {
+			if err := s.jira.AddAttachment(ctx, key, "repro.syz", rep.ReproSyz); err != nil {
+				return err
+			}
+		}
+	}
+	if len(rep.ReproC) != 0 {
+		reproLevel = dashapi.ReproLevelC
+		if !rep.EncryptedPayloads {
+			if err := s.jira.AddAttachment(ctx, key, "repro.c", rep.ReproC); err != nil {
+				return err
+			}
+		}
+	}
+	return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{
+		ID:         rep.ID,
+		ExtID:      extIDPrefix + key,
+		Link:       fmt.Sprintf("%v/browse/%v", strings.TrimSuffix(s.cfg.JiraURL, "/"), key),
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: reproLevel,
+		CrashID:    rep.CrashID,
+	})
+}
+
+func (s *syncer) issueFields(rep *dashapi.BugReport) (map[string]interface{}, error) {
+	repCfg := new(reportingConfig)
+	if err := json.Unmarshal(rep.Config, repCfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reporting config: %w", err)
+	}
+	project, issueType := repCfg.Project, repCfg.IssueType
+	for _, subsystem := range rep.Subsystems {
+		if override, ok := repCfg.Subsystems[subsystem.Name]; ok {
+			project = override.Project
+			if override.IssueType != "" {
+				issueType = override.IssueType
+			}
+			break
+		}
+	}
+	if issueType == "" {
+		issueType = "Bug"
+	}
+	fields := map[string]interface{}{
+		"project":   map[string]string{"key": project},
+		"issuetype": map[string]string{"name": issueType},
+		"labels":    []string{s.cfg.Label, s.cfg.Label + "-" + rep.ID},
+	}
+	for field := range s.templates {
+		val, err := s.render(field, rep)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = val
+	}
+	return fields, nil
+}
+
+// render executes the template of the field, the result is either a string or a json.RawMessage.
+func (s *syncer) render(field string, rep *dashapi.BugReport) (interface{}, error) {
+	buf := new(bytes.Buffer)
+	if err := s.templates[field].Execute(buf, rep); err != nil {
+		return nil, fmt.Errorf("failed to render field %v: %w", field, err)
+	}
+	text := strings.TrimSpace(buf.String())
+	if (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Valid([]byte(text)) {
+		return json.RawMessage(text), nil
+	}
+	return text, nil
+}
+
+// syncClosed resolves the issues whose bugs were closed on the dashboard.
+func (s *syncer) syncClosed(ctx context.Context) error {
+	issues, err := s.jira.Search(ctx, fmt.Sprintf(`labels = %q AND statusCategory != Done`, s.cfg.Label))
+	if err != nil {
+		return err
+	}
+	closed, byID, err := s.pollClosed(ctx, issues)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		issue := byID[id]
+		if err := s.resolve(ctx, issue, id); err != nil {
+			log.Errorf("failed to resolve issue %v: %v", issue.Key, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) resolve(ctx context.Context, issue *jira.Issue, id string) error {
+	extBug, err := s.lookup(ctx, issue.Key, id)
+	if err != nil {
+		return err
+	}
+	status, comment := statusMoved, fmt.Sprintf("The bug is not tracked here anymore: %v", extBug.Link)
+	switch extBug.Status {
+	case dashapi.BugStatusFixed:
+		status, comment = statusFixed, fmt.Sprintf("The bug is fixed: %v", extBug.Link)
+	case dashapi.BugStatusInvalid:
+		status, comment = statusInvalid, fmt.Sprintf("The bug is closed as invalid: %v", extBug.Link)
+	case dashapi.BugStatusDup:
+		status, comment = statusDup, fmt.Sprintf("The bug is closed as a duplicate: %v", extBug.Link)
+		if dupKey, ok := strings.CutPrefix(extBug.DupOf, extIDPrefix); ok {
+			comment = fmt.Sprintf("The bug is closed as a duplicate of %v: %v", dupKey, extBug.Link)
+		}
+	}
+	transitions, err := s.jira.Transitions(ctx, issue.Key)
+	if err != nil {
+		return err
+	}
+	for _, tr := range transitions {
+		if strings.EqualFold(tr.Name, s.cfg.CloseTransition) {
+			return s.jira.DoTransition(ctx, issue.Key, tr.ID, s.cfg.CloseResolutions[status], comment)
+		}
+	}
+	return fmt.Errorf("no transition %q", s.cfg.CloseTransition)
+}
+
+// syncResolved closes the dashboard bugs whose issues were resolved in Jira.
+func (s *syncer) syncResolved(ctx context.Context) error {
+	jql := fmt.Sprintf(`labels = %q AND statusCategory = Done`, s.cfg.Label)
+	if !s.lastSync.IsZero() {
+		// Relative time does not depend on the time zone of the Jira user.
+		minutes := int(time.Since(s.lastSync)/time.Minute) + 1
+		jql += fmt.Sprintf(" AND updated >= -%vm", minutes)
+	}
+	issues, err := s.jira.Search(ctx, jql)
+	if err != nil {
+		return err
+	}
+	// Closed bugs were resolved in Jira by syncClosed.
+	closed, byID, err := s.pollClosed(ctx, issues)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		delete(byID, id)
+	}
+	for id, issue := range byID {
+		upd, err := s.resolvedUpdate(ctx, issue, id)
+		if err != nil {
+			log.Errorf("failed to sync issue %v: %v", issue.Key, err)
+			continue
+		}
+		if upd == nil {
+			continue
+		}
+		if err := s.dash.ReportingUpdateOK(ctx, upd); err != nil {
+			log.Errorf("failed to close the bug of issue %v: %v", issue.Key, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) resolvedUpdate(ctx context.Context, issue *jira.Issue, id string) (*dashapi.BugUpdate, error) {
+	resolution := ""
+	if issue.Fields.Resolution != nil {
+		resolution = issue.Fields.Resolution.Name
+	}
+	switch s.cfg.Resolutions[resolution] {
+	case statusInvalid:
+		return &dashapi.BugUpdate{ID: id, Status: dashapi.BugStatusInvalid}, nil
+	case statusDup:
+		for _, link := range issue.Fields.IssueLinks {
+			if link.Type.Name != "Duplicate" || link.OutwardIssue == nil {
+				continue
+			}
+			canon, err := s.jira.GetIssue(ctx, link.OutwardIssue.Key)
+			if err != nil {
+				return nil, err
+			}
+			if dupID := s.reportingID(canon); dupID != "" {
+				return &dashapi.BugUpdate{ID: id, Status: dashapi.BugStatusDup, DupOf: dupID}, nil
+			}
+		}
+		log.Logf(0, "issue %v is a duplicate of an issue that was not filed by us", issue.Key)
+	default:
+		log.Logf(0, "issue %v is resolved as %q, close the bug on the dashboard", issue.Key, resolution)
+	}
+	return nil, nil
+}
+
+// pollClosed returns the reporting IDs of the bugs that are closed on the dashboard
+// and all the issues filed by us by their reporting IDs.
+func (s *syncer) pollClosed(ctx context.Context, issues []*jira.Issue) ([]string, map[string]*jira.Issue, error) {
+	byID := make(map[string]*jira.Issue)
+	var ids []string
+	for _, issue := range issues {
+		if id := s.reportingID(issue); id != "" {
+			byID[id] = issue
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, byID, nil
+	}
+	closed, err := s.dash.ReportingPollClosed(ctx, ids)
+	return closed, byID, err
+}
+
+func (s *syncer) reportingID(issue *jira.Issue) string {
+	for _, label := range issue.Fields.Labels {
+		if id, ok := strings.CutPrefix(label, s.cfg.Label+"-"); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func (s *syncer) lookup(ctx context.Context, key, id string) (*dashapi.ExtIDBug, error) {
+	extBugs, err := s.dash.LookupExtID(ctx, extIDPrefix+key)
+	if err != nil {
+		return nil, err
+	}
+	for _, extBug := range extBugs {
+		if extBug.ID == id {
+			return extBug, nil
+		}
+	}
+	return nil, fmt.Errorf("the dashboard has no bug %v for reporting %v", key, id)
+}
+
+func jobComment(rep *dashapi.BugReport) string {
+	buf := new(bytes.Buffer)
+	switch {
+	case len(rep.Error) != 0:
+		fmt.Fprintf(buf, "syzbot failed to run the job:\n\n{noformat}\n%s{noformat}\n", rep.Error)
+	case rep.CrashTitle != "" && rep.EncryptedPayloads:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed: %v\n\n"+
+			"The report is encrypted, see the dashboard link.\n", rep.CrashTitle)
+	case rep.CrashTitle != "":
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel crashed: %v\n\n{noformat}\n%s{noformat}\n",
+			rep.CrashTitle, rep.Report)
+	default:
+		fmt.Fprintf(buf, "syzbot has run the job, the kernel did not crash.\n")
+	}
+	if rep.Link != "" {
+		fmt.Fprintf(buf, "\ndashboard link: %v\n", rep.Link)
+	}
+	return buf.String()
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/syzkaller/dashboard/dashapi"
+)
+
+func TestIssueFields(t *testing.T) {
+	templates, err := parseFields(map[string]string{
+		"summary":  "[syzbot] {{.Title}}",
+		"priority": `{"name": "{{if .ReproC}}High{{else}}Low{{end}}"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &syncer{
+		cfg:       &Config{Label: "syzbot"},
+		templates: templates,
+	}
+	repCfg, err := json.Marshal(map[string]interface{}{
+		"Project":    "KERN",
+		"Subsystems": map[string]interface{}{"net": map[string]string{"Project": "NET", "IssueType": "Task"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := &dashapi.BugReport{
+		ID:         "abcdef",
+		Title:      "WARNING in foo",
+		Config:     repCfg,
+		ReproC:     []byte("int main() {}"),
+		Subsystems: []dashapi.BugSubsystem{{Name: "fs"}, {Name: "net"}},
+	}
+	fields, err := s.issueFields(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(fields, "description")
+	want := map[string]interface{}{
+		"project":   map[string]string{"key": "NET"},
+		"issuetype": map[string]string{"name": "Task"},
+		"labels":    []string{"syzbot", "syzbot-abcdef"},
+		"summary":   "[syzbot] WARNING in foo",
+		"priority":  json.RawMessage(`{"name": "High"}`),
+	}
+	if diff := cmp.Diff(want, fields); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Without subsystem overrides the default project and issue type are used.
+	rep.Subsystems = nil
+	fields, err = s.issueFields(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"key": "KERN"}, fields["project"]); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(map[string]string{"name": "Bug"}, fields["issuetype"]); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestParseFieldsError(t *testing.T) {
+	if _, err := parseFields(map[string]string{"summary": "{{.Title"}); err == nil {
+		t.Fatal("no error")
+	}
+}
@@ -32,7 +32,7 @@ func main() {
 	if err != nil {
 		tool.Fail(err)
 	}
-	bug, err := dash.LoadBug(*flagBug)
+	bug, err := dash.LoadBug(context.Background(), *flagBug)
 	if err != nil {
 		tool.Fail(err)
 	}
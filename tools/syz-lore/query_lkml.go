@@ -93,7 +93,7 @@ func saveDiscussion(d *dashapi.Discussion) error {
 		}
 	}
 	if dash != nil {
-		return dash.SaveDiscussion(&dashapi.SaveDiscussionReq{
+		return dash.SaveDiscussion(context.Background(), &dashapi.SaveDiscussionReq{
 			Discussion: d,
 		})
 	}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -45,7 +46,7 @@ func main() {
 		if err != nil {
 			log.Fatalf("dashapi failed: %v", err)
 		}
-		resp, err := dash.BugList()
+		resp, err := dash.BugList(context.Background())
 		if err != nil {
 			log.Fatalf("api call failed: %v", err)
 		}
@@ -77,7 +78,7 @@ func main() {
 			go func() {
 				defer wg.Done()
 				for id := range idchan {
-					resp, err := dash.LoadBug(id)
+					resp, err := dash.LoadBug(context.Background(), id)
 					if err != nil {
 						log.Printf("%v: failed to load bug: %v", id, err)
 						continue
@@ -0,0 +1,271 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-webhook is an external reporting for the dashboard that delivers bugs to a webhook.
+// It's a cheap way to wire bugs into internal systems without writing a full integration.
+// Every new bug report, bug notification and closed bug is POSTed as an Event in JSON
+// signed with the shared secret (see pkg/webhook for the headers and the signature check).
+// Failed deliveries are retried, and the bugs are acknowledged on the dashboard only after
+// the webhook has accepted them, so nothing is lost if the webhook is down for a while.
+// The reporting on the dashboard needs WebhookConfig (see dashboard/app/reporting_webhook.go).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/pkg/config"
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/pkg/webhook"
+)
+
+type Config struct {
+	DashboardAddr   string `json:"dashboard_addr"`
+	DashboardClient string `json:"dashboard_client"`
+	DashboardKey    string `json:"dashboard_key"`
+	ReportingType   string `json:"reporting_type"` // "webhook" by default
+	URL             string `json:"url"`
+	Secret          string `json:"secret"`
+	// The IDs of the reported bugs are stored there to deliver the events when they are closed.
+	StateFile  string `json:"state_file"`
+	Retries    int    `json:"retries"`     // 5 by default
+	PollPeriod int    `json:"poll_period"` // in seconds, 1 minute by default
+}
+
+// Event is the body of the webhook requests, the type is also passed in webhook.EventHeader.
+type Event struct {
+	Type         EventType                `json:"type"`
+	Time         time.Time                `json:"time"`
+	Report       *dashapi.BugReport       `json:"report,omitempty"`
+	Notification *dashapi.BugNotification `json:"notification,omitempty"`
+	// The status of the bug is open if the bug was not closed but moved to the next reporting.
+	Bug *dashapi.ExtIDBug `json:"bug,omitempty"`
+}
+
+type EventType string
+
+const (
+	// A new bug, a new report for an already reported bug (e.g. with a reproducer) or a job result.
+	EventReport EventType = "report"
+	// A notification about the bug (e.g. it's going to be closed as obsolete).
+	EventNotification EventType = "notification"
+	EventClosed       EventType = "closed"
+)
+
+const extIDPrefix = "webhook-"
+
+func main() {
+	var (
+		flagConfig = flag.String("config", "", "config file")
+		flagOnce   = flag.Bool("once", false, "sync once and exit")
+	)
+	flag.Parse()
+	cfg := &Config{
+		ReportingType: "webhook",
+		Retries:       5,
+		PollPeriod:    60,
+	}
+	if err := config.LoadFile(*flagConfig, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.URL == "" || cfg.Secret == "" || cfg.StateFile == "" {
+		log.Fatalf("url, secret and state_file must be set")
+	}
+	dash, err := dashapi.New(cfg.DashboardClient, cfg.DashboardAddr, cfg.DashboardKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sender := webhook.NewSender(cfg.URL, []byte(cfg.Secret))
+	sender.Retries = cfg.Retries
+	s := &syncer{
+		cfg:    cfg,
+		dash:   dash,
+		sender: sender,
+		open:   make(map[string]bool),
+	}
+	if err := s.loadState(); err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("sync failed: %v", err)
+		}
+		if *flagOnce {
+			return
+		}
+		time.Sleep(time.Duration(cfg.PollPeriod) * time.Second)
+	}
+}
+
+type syncer struct {
+	cfg    *Config
+	dash   *dashapi.Dashboard
+	sender *webhook.Sender
+	open   map[string]bool // reporting IDs of the reported bugs that are not closed yet
+}
+
+type state struct {
+	Open []string
+}
+
+func (s *syncer) loadState() error {
+	data, err := os.ReadFile(s.cfg.StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	st := new(state)
+	if err := json.Unmarshal(data, st); err != nil {
+		return fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	for _, id := range st.Open {
+		s.open[id] = true
+	}
+	return nil
+}
+
+func (s *syncer) saveState() error {
+	st := new(state)
+	for id := range s.open {
+		st.Open = append(st.Open, id)
+	}
+	sort.Strings(st.Open)
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return osutil.WriteFile(s.cfg.StateFile, data)
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	if err := s.reportNew(ctx); err != nil {
+		return fmt.Errorf("failed to report new bugs: %w", err)
+	}
+	if err := s.notify(ctx); err != nil {
+		return fmt.Errorf("failed to send notifications: %w", err)
+	}
+	if err := s.syncClosed(ctx); err != nil {
+		return fmt.Errorf("failed to sync closed bugs: %w", err)
+	}
+	return nil
+}
+
+func (s *syncer) reportNew(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollBugs(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, rep := range resp.Reports {
+		// A failure to report one bug should not prevent reporting of the rest.
+		if err := s.report(ctx, rep); err != nil {
+			log.Errorf("failed to report %q: %v", rep.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) report(ctx context.Context, rep *dashapi.BugReport) error {
+	if rep.JobID != "" {
+		if err := s.send(ctx, "job-"+rep.JobID, &Event{Type: EventReport, Report: rep}); err != nil {
+			return err
+		}
+		return s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{JobID: rep.JobID})
+	}
+	delivery := fmt.Sprintf("report-%v-%v", rep.ID, rep.CrashID)
+	if err := s.send(ctx, delivery, &Event{Type: EventReport, Report: rep}); err != nil {
+		return err
+	}
+	reproLevel := dashapi.ReproLevelNone
+	if len(rep.ReproC) != 0 {
+		reproLevel = dashapi.ReproLevelC
+	} else if len(rep.ReproSyz) != 0 {
+		reproLevel = dashapi.ReproLevelSyz
+	}
+	err := s.dash.ReportingUpdateOK(ctx, &dashapi.BugUpdate{
+		ID:         rep.ID,
+		ExtID:      extIDPrefix + rep.ID,
+		Status:     dashapi.BugStatusOpen,
+		ReproLevel: reproLevel,
+		CrashID:    rep.CrashID,
+	})
+	if err != nil {
+		return err
+	}
+	s.open[rep.ID] = true
+	return s.saveState()
+}
+
+func (s *syncer) notify(ctx context.Context) error {
+	resp, err := s.dash.ReportingPollNotifications(ctx, s.cfg.ReportingType)
+	if err != nil {
+		return err
+	}
+	for _, notif := range resp.Notifications {
+		if err := s.notifyOne(ctx, notif); err != nil {
+			log.Errorf("failed to send notification for %q: %v", notif.Title, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) notifyOne(ctx context.Context, notif *dashapi.BugNotification) error {
+	delivery := fmt.Sprintf("notification-%v-%v-%v", notif.ID, notif.Type, notif.Label)
+	if err := s.send(ctx, delivery, &Event{Type: EventNotification, Notification: notif}); err != nil {
+		return err
+	}
+	// The webhook is only informed, the actions the notifications ask for are taken right away.
+	return s.dash.ReportingUpdateOK(ctx, dashapi.NotificationUpdate(notif))
+}
+
+func (s *syncer) syncClosed(ctx context.Context) error {
+	if len(s.open) == 0 {
+		return nil
+	}
+	var ids []string
+	for id := range s.open {
+		ids = append(ids, id)
+	}
+	closed, err := s.dash.ReportingPollClosed(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range closed {
+		if err := s.close(ctx, id); err != nil {
+			log.Errorf("failed to report closed bug %v: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) close(ctx context.Context, id string) error {
+	extBugs, err := s.dash.LookupExtID(ctx, extIDPrefix+id)
+	if err != nil {
+		return err
+	}
+	bug := &dashapi.ExtIDBug{ID: id, ExtID: extIDPrefix + id}
+	for _, extBug := range extBugs {
+		if extBug.ID == id {
+			bug = extBug
+		}
+	}
+	if err := s.send(ctx, "closed-"+id, &Event{Type: EventClosed, Bug: bug}); err != nil {
+		return err
+	}
+	delete(s.open, id)
+	return s.saveState()
+}
+
+func (s *syncer) send(ctx context.Context, delivery string, event *Event) error {
+	event.Time = time.Now()
+	return s.sender.Send(ctx, string(event.Type), delivery, event)
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/syzkaller/dashboard/dashapi"
+	"github.com/google/syzkaller/dashboard/dashapi/dashapitest"
+	"github.com/google/syzkaller/pkg/webhook"
+)
+
+func TestSync(t *testing.T) {
+	secret := []byte("secret")
+	var events []EventType
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if !webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader)) {
+			t.Errorf("bad signature")
+		}
+		event := new(Event)
+		if err := json.Unmarshal(body, event); err != nil {
+			t.Error(err)
+		}
+		events = append(events, event.Type)
+	}))
+	defer hook.Close()
+
+	dash := dashapitest.NewServer(t)
+	dash.Handle("reporting_poll_bugs", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollBugsResponse{Reports: []*dashapi.BugReport{
+			{ID: "id1", Title: "WARNING in foo", CrashID: 1, ReproSyz: []byte("foo()")},
+		}}, nil
+	})
+	dash.Handle("reporting_poll_notifs", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollNotificationsResponse{Notifications: []*dashapi.BugNotification{
+			{Type: dashapi.BugNotifObsoleted, ID: "id0", Text: string(dashapi.InvalidatedByNoActivity)},
+		}}, nil
+	})
+	dash.Handle("reporting_update", func(payload []byte) (interface{}, error) {
+		return &dashapi.BugUpdateReply{OK: true}, nil
+	})
+	dash.Handle("reporting_poll_closed", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollClosedResponse{}, nil
+	})
+
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	newSyncer := func() *syncer {
+		s := &syncer{
+			cfg:    &Config{ReportingType: "webhook", StateFile: stateFile},
+			dash:   dash.NewClient(),
+			sender: webhook.NewSender(hook.URL, secret),
+			open:   make(map[string]bool),
+		}
+		if err := s.loadState(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := newSyncer()
+	if err := s.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]EventType{EventReport, EventNotification}, events); diff != "" {
+		t.Fatal(diff)
+	}
+	var updates []*dashapi.BugUpdate
+	for _, req := range dash.Requests("reporting_update") {
+		upd := new(dashapi.BugUpdate)
+		if err := req.Decode(upd); err != nil {
+			t.Fatal(err)
+		}
+		updates = append(updates, upd)
+	}
+	wantUpdates := []*dashapi.BugUpdate{
+		{
+			ID:         "id1",
+			ExtID:      "webhook-id1",
+			Status:     dashapi.BugStatusOpen,
+			ReproLevel: dashapi.ReproLevelSyz,
+			CrashID:    1,
+		},
+		{
+			ID:           "id0",
+			Status:       dashapi.BugStatusInvalid,
+			StatusReason: dashapi.InvalidatedByNoActivity,
+			Notification: true,
+		},
+	}
+	if diff := cmp.Diff(wantUpdates, updates); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The reported bug survives a restart and its closing is delivered.
+	s = newSyncer()
+	if diff := cmp.Diff(map[string]bool{"id1": true}, s.open); diff != "" {
+		t.Fatal(diff)
+	}
+	dash.Handle("reporting_poll_closed", func(payload []byte) (interface{}, error) {
+		return &dashapi.PollClosedResponse{IDs: []string{"id1"}}, nil
+	})
+	dash.Handle("lookup_ext_id", func(payload []byte) (interface{}, error) {
+		return &dashapi.LookupExtIDResp{}, nil
+	})
+	events = nil
+	if err := s.syncClosed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]EventType{EventClosed}, events); diff != "" {
+		t.Fatal(diff)
+	}
+	if s = newSyncer(); len(s.open) != 0 {
+		t.Fatalf("closed bugs are left in the state: %v", s.open)
+	}
+}